@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"fulcrum/lib/parser"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// configCmd groups commands that inspect fulcrum.yml
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the project configuration",
+}
+
+// configCheckCmd validates the configuration without starting the server
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate fulcrum.yml, routes and templates",
+	Long: `Check fulcrum.yml for an unknown database driver, missing connection
+settings, a root that matches no route, invalid domain names and unknown
+keys, then validate every route and its template.
+
+All problems are reported together. Exits non-zero if any is found.`,
+	Run: runConfigCheck,
+}
+
+var configCheckEnv string
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configCheckCmd)
+
+	configCheckCmd.Flags().StringVar(&configCheckEnv, "env", "", "Merge fulcrum.<env>.yml over fulcrum.yml (defaults to FULCRUM_ENV)")
+}
+
+// configEnv returns the environment selected by an --env flag, falling back to FULCRUM_ENV
+func configEnv(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	return os.Getenv("FULCRUM_ENV")
+}
+
+func runConfigCheck(cmd *cobra.Command, args []string) {
+	appPath, err := os.Getwd()
+	if err != nil {
+		log.Fatalf("Failed to get current directory: %v", err)
+	}
+
+	appConfig, err := parser.GetAppConfigForEnv(appPath, configEnv(configCheckEnv))
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, warning := range appConfig.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+
+	failed := false
+	for _, check := range []func() error{appConfig.Validate, appConfig.ValidateMessageRoutes, appConfig.ValidateRoutes} {
+		if err := check(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+	fmt.Println("✅ Configuration is valid")
+}
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"fulcrum/lib/database"
+	"fulcrum/lib/database/interfaces"
+	"fulcrum/lib/database/migration"
+	"fulcrum/lib/database/seeder"
+	"io"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// dbCmd groups the database commands
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Database management",
+	Long: `Manage your Fulcrum application's database.
+
+Available subcommands:
+  create  - Create the database
+  drop    - Drop the database
+  reset   - Drop, create, migrate and seed the database
+  seed    - Load seed data
+
+create, drop and reset refuse to run when the environment is
+production unless --force is given.`,
+}
+
+// dbCreateCmd creates the configured database
+var dbCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create the database",
+	Long: `Create the database named in fulcrum.yml.
+
+PostgreSQL and MySQL databases are created from the server's
+maintenance database; SQLite creates the file at file_path.`,
+	Run: runDBCreate,
+}
+
+// dbDropCmd drops the configured database
+var dbDropCmd = &cobra.Command{
+	Use:   "drop",
+	Short: "Drop the database",
+	Long: `Drop the database named in fulcrum.yml, or remove the SQLite file.
+
+All data is lost, so --confirm is required:
+  fulcrum db drop --confirm`,
+	Run: runDBDrop,
+}
+
+// dbResetCmd rebuilds the configured database from scratch
+var dbResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Drop, create, migrate and seed the database",
+	Long: `Rebuild the database for local development: drop it, create it,
+apply every migration and load the seeds, as db drop, db create,
+migrate up and db seed would.`,
+	Run: runDBReset,
+}
+
+// dbSeedCmd loads seed data
+var dbSeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Load seed data",
+	Long: `Load the rows declared in each domain's seeds.yml and seeds/*.yml.
+
+Numbered files in domains/<name>/seeds/ run once, like migrations.
+domains/<name>/seeds.yml runs every time, so its records need
+unique_by, which updates the row with the same values in those
+columns instead of inserting another, or skip_if_exists.
+
+A row's _key names it, and "@name" stores that row's id in another
+row, even one in another domain. "bcrypt:<password>" stores the
+password hashed, so seeded users can log in:
+
+  records:
+    - table: users
+      unique_by: [email]
+      rows:
+        - _key: admin
+          email: admin@example.com
+          password_hash: bcrypt:secret
+    - table: posts
+      unique_by: [title]
+      rows:
+        - title: Welcome
+          user_id: "@admin"
+
+  fulcrum db seed
+  fulcrum db seed --domain users --env dev`,
+	Run: runDBSeed,
+}
+
+var (
+	dbSeedDomain  string
+	dbEnv         string
+	dbForce       bool
+	dbDropConfirm bool
+)
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbCreateCmd)
+	dbCmd.AddCommand(dbDropCmd)
+	dbCmd.AddCommand(dbResetCmd)
+	dbCmd.AddCommand(dbSeedCmd)
+
+	dbSeedCmd.Flags().StringVar(&dbSeedDomain, "domain", "", "Only load the seeds of this domain")
+	dbCmd.PersistentFlags().StringVar(&dbEnv, "env", "", "Merge fulcrum.<env>.yml over fulcrum.yml (defaults to FULCRUM_ENV)")
+	dbDropCmd.Flags().BoolVar(&dbDropConfirm, "confirm", false, "Confirm that all data should be deleted")
+	for _, cmd := range []*cobra.Command{dbCreateCmd, dbDropCmd, dbResetCmd} {
+		cmd.Flags().BoolVar(&dbForce, "force", false, "Run even when the environment is production")
+	}
+}
+
+// refuseProduction stops create, drop and reset from touching a production database
+// unless forced
+func refuseProduction(env string, force bool) error {
+	if env == "production" && !force {
+		return fmt.Errorf("refusing to run against the production environment; pass --force to run anyway")
+	}
+	return nil
+}
+
+// loadDBConfigForAdmin loads the database config once the environment is allowed
+func loadDBConfigForAdmin() (interfaces.Config, string) {
+	env := configEnv(dbEnv)
+	if err := refuseProduction(env, dbForce); err != nil {
+		log.Fatal(err)
+	}
+
+	dbConfig, appPath, err := loadDBConfig(env)
+	if err != nil {
+		log.Fatalf("Failed to load database config: %v", err)
+	}
+	return dbConfig, appPath
+}
+
+func runDBCreate(cmd *cobra.Command, args []string) {
+	dbConfig, _ := loadDBConfigForAdmin()
+
+	created, err := database.CreateDatabase(context.Background(), dbConfig)
+	if err != nil {
+		log.Fatalf("Failed to create database: %v", err)
+	}
+	if created {
+		fmt.Printf("✅ Created database %s\n", databaseName(dbConfig))
+	} else {
+		fmt.Printf("Database %s already exists\n", databaseName(dbConfig))
+	}
+}
+
+func runDBDrop(cmd *cobra.Command, args []string) {
+	if !dbDropConfirm {
+		log.Fatal("Dropping the database deletes all of its data; pass --confirm to drop it")
+	}
+	dbConfig, _ := loadDBConfigForAdmin()
+
+	dropped, err := database.DropDatabase(context.Background(), dbConfig)
+	if err != nil {
+		log.Fatalf("Failed to drop database: %v", err)
+	}
+	if dropped {
+		fmt.Printf("🗑️  Dropped database %s\n", databaseName(dbConfig))
+	} else {
+		fmt.Printf("Database %s does not exist\n", databaseName(dbConfig))
+	}
+}
+
+func runDBReset(cmd *cobra.Command, args []string) {
+	dbConfig, appPath := loadDBConfigForAdmin()
+
+	if err := resetDatabase(context.Background(), dbConfig, appPath, os.Stdout); err != nil {
+		log.Fatalf("Failed to reset database: %v", err)
+	}
+}
+
+// resetDatabase drops, creates, migrates and seeds the database, writing a line per step
+func resetDatabase(ctx context.Context, dbConfig interfaces.Config, appPath string, out io.Writer) error {
+	name := databaseName(dbConfig)
+
+	dropped, err := database.DropDatabase(ctx, dbConfig)
+	if err != nil {
+		return err
+	}
+	if dropped {
+		fmt.Fprintf(out, "1/4 🗑️  Dropped database %s\n", name)
+	} else {
+		fmt.Fprintf(out, "1/4 🗑️  Database %s did not exist\n", name)
+	}
+
+	if _, err := database.CreateDatabase(ctx, dbConfig); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "2/4 🆕 Created database %s\n", name)
+
+	dbManager, err := connectDatabase(ctx, dbConfig)
+	if err != nil {
+		return err
+	}
+	defer dbManager.Close()
+	db := dbManager.GetDatabase()
+
+	runner := migration.NewRunner(db, appPath)
+	if err := runner.Initialize(ctx); err != nil {
+		return fmt.Errorf("failed to initialize migration system: %w", err)
+	}
+	if err := runner.MigrateUp(ctx); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	statuses, err := runner.GetStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get migration status: %w", err)
+	}
+	applied := 0
+	for _, status := range statuses {
+		applied += len(status.AppliedMigrations)
+	}
+	fmt.Fprintf(out, "3/4 🔄 Applied %d migrations across %d domains\n", applied, len(statuses))
+
+	seeded, err := seedIfPresent(ctx, db, appPath)
+	if err != nil {
+		return fmt.Errorf("failed to run seeds: %w", err)
+	}
+	if seeded {
+		fmt.Fprintln(out, "4/4 🌱 Loaded seeds")
+	} else {
+		fmt.Fprintln(out, "4/4 🌱 No seeds to load")
+	}
+
+	fmt.Fprintln(out, "✅ Database reset complete!")
+	return nil
+}
+
+// databaseName names the database of a config for messages
+func databaseName(dbConfig interfaces.Config) string {
+	if dbConfig.Driver == interfaces.DriverSQLite {
+		return dbConfig.FilePath
+	}
+	return dbConfig.Database
+}
+
+func runDBSeed(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	dbManager, appPath, err := setupDatabaseForEnv(ctx, configEnv(dbEnv))
+	if err != nil {
+		log.Fatalf("Failed to setup database: %v", err)
+	}
+	defer dbManager.Close()
+
+	s := seeder.NewSeeder(dbManager.GetDatabase(), appPath)
+	if dbSeedDomain != "" {
+		err = s.SeedDomain(ctx, dbSeedDomain)
+	} else {
+		err = s.SeedAll(ctx)
+	}
+	if err != nil {
+		log.Fatalf("Failed to run seeds: %v", err)
+	}
+}
+
+// seedIfPresent loads the app's seeds after migrations when it has any, reporting
+// whether it did
+func seedIfPresent(ctx context.Context, db interfaces.Database, appPath string) (bool, error) {
+	seeds, err := seeder.LoadAllSeeds(appPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to load seeds: %w", err)
+	}
+	if len(seeds) == 0 {
+		return false, nil
+	}
+	return true, seeder.NewSeeder(db, appPath).SeedAll(ctx)
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fulcrum/lib/database"
+	"fulcrum/lib/database/interfaces"
+)
+
+func TestResetDatabase(t *testing.T) {
+	ctx := context.Background()
+	appPath := t.TempDir()
+	writeAppFile(t, appPath, "domains/notes/migrations/001_create_notes.yml", `version: 1
+name: create_notes
+up:
+  - create_table:
+      name: notes
+      columns:
+        - name: id
+          type: integer
+          primary_key: true
+        - name: title
+          type: varchar
+          length: 255
+down:
+  - drop_table:
+      name: notes
+`)
+	writeAppFile(t, appPath, "domains/notes/seeds.yml", `records:
+  - table: notes
+    unique_by: [title]
+    rows:
+      - title: Welcome
+`)
+	dbConfig := interfaces.Config{Driver: interfaces.DriverSQLite, FilePath: filepath.Join(appPath, "db", "app.db")}
+
+	countNotes := func() int {
+		t.Helper()
+		manager, err := connectDatabase(ctx, dbConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer manager.Close()
+		var count int
+		if err := manager.GetDatabase().QueryRow(ctx, "SELECT COUNT(*) FROM notes").Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	var out bytes.Buffer
+	if err := resetDatabase(ctx, dbConfig, appPath, &out); err != nil {
+		t.Fatalf("First reset failed: %v\n%s", err, out.String())
+	}
+	for _, step := range []string{"1/4 🗑️  Database", "did not exist", "2/4 🆕 Created", "3/4 🔄 Applied 1 migrations across 1 domains", "4/4 🌱 Loaded seeds"} {
+		if !strings.Contains(out.String(), step) {
+			t.Errorf("Expected the summary to contain %q, got:\n%s", step, out.String())
+		}
+	}
+	if count := countNotes(); count != 1 {
+		t.Fatalf("Expected the seeded note, got %d notes", count)
+	}
+
+	// Data added since is gone after another reset
+	manager, err := connectDatabase(ctx, dbConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.GetDatabase().Exec(ctx, "INSERT INTO notes (title) VALUES ('Scratch')"); err != nil {
+		t.Fatal(err)
+	}
+	manager.Close()
+
+	out.Reset()
+	if err := resetDatabase(ctx, dbConfig, appPath, &out); err != nil {
+		t.Fatalf("Second reset failed: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "1/4 🗑️  Dropped database") {
+		t.Errorf("Expected the database to be dropped, got:\n%s", out.String())
+	}
+	if count := countNotes(); count != 1 {
+		t.Errorf("Expected only the seeded note after the reset, got %d notes", count)
+	}
+
+	if dropped, err := database.DropDatabase(ctx, dbConfig); err != nil || !dropped {
+		t.Fatalf("Expected the database to be dropped, got %v, %v", dropped, err)
+	}
+	if _, err := os.Stat(dbConfig.FilePath); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed, got %v", dbConfig.FilePath, err)
+	}
+}
+
+func TestRefuseProduction(t *testing.T) {
+	tests := []struct {
+		env       string
+		force     bool
+		expectErr bool
+	}{
+		{env: "", expectErr: false},
+		{env: "dev", expectErr: false},
+		{env: "production", expectErr: true},
+		{env: "production", force: true, expectErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			err := refuseProduction(tt.env, tt.force)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Expected error %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func writeAppFile(t *testing.T, appPath, name, content string) {
+	t.Helper()
+	path := filepath.Join(appPath, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,6 +5,7 @@ package cmd
 
 import (
 	"fmt"
+	"log"
 
 	parser "fulcrum/lib/parser"
 
@@ -25,13 +26,17 @@ This application is a tool to generate the needed files
 to quickly create a Cobra application.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("dev called")
-		appConfig, err := parser.GetAppConfig("/home/djtouchette/Documents/fulcrum/example")
+		appConfig, err := parser.GetAppConfigForEnv("/home/djtouchette/Documents/fulcrum/example", configEnv(devEnv))
 		appConfig.Mode = "develop"
 
 		if err != nil {
 			fmt.Println("Error getting app config:", err)
 		}
 
+		if err := appConfig.Validate(); err != nil {
+			log.Fatalf("❌ Invalid configuration:\n%v", err)
+		}
+
 		fmt.Println("=== Application Configuration ===")
 		appConfig.PrintYAML()
 		fmt.Println("================================")
@@ -52,9 +57,13 @@ to quickly create a Cobra application.`,
 	},
 }
 
+var devEnv string
+
 func init() {
 	rootCmd.AddCommand(devCmd)
 
+	devCmd.Flags().StringVar(&devEnv, "env", "", "Merge fulcrum.<env>.yml over fulcrum.yml (defaults to FULCRUM_ENV)")
+
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"fulcrum/lib/naming"
+)
+
+// Field is a column given to generate domain as name:type
+type Field struct {
+	Name    string
+	Type    string
+	Options []string // Allowed values of an enum field
+}
+
+// enumOptionPattern matches the values allowed in enum[...]
+var enumOptionPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// parseField parses name:type, where type may be references or enum[a,b,c]
+func parseField(arg string) (Field, error) {
+	parts := strings.SplitN(arg, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return Field{}, fmt.Errorf("invalid field format: %s. Expected format: name:type", arg)
+	}
+	field := Field{Name: parts[0], Type: parts[1]}
+
+	if !strings.HasPrefix(field.Type, "enum") {
+		return field, nil
+	}
+
+	// enum[draft,published,archived]
+	options, ok := strings.CutPrefix(field.Type, "enum[")
+	if ok {
+		options, ok = strings.CutSuffix(options, "]")
+	}
+	if !ok || options == "" {
+		return Field{}, fmt.Errorf("invalid enum field %s: expected %s:enum[value,value,...]", arg, field.Name)
+	}
+
+	seen := make(map[string]bool)
+	for _, option := range strings.Split(options, ",") {
+		option = strings.TrimSpace(option)
+		if !enumOptionPattern.MatchString(option) {
+			return Field{}, fmt.Errorf("invalid enum field %s: value %q must be letters, digits, '_' or '-'", arg, option)
+		}
+		if seen[option] {
+			return Field{}, fmt.Errorf("invalid enum field %s: value %q is listed twice", arg, option)
+		}
+		seen[option] = true
+		field.Options = append(field.Options, option)
+	}
+	field.Type = "enum"
+	return field, nil
+}
+
+// Column returns the field's column name; references are stored in <name>_id
+func (f Field) Column() string {
+	if f.Type == "references" && !strings.HasSuffix(f.Name, "_id") {
+		return f.Name + "_id"
+	}
+	return f.Name
+}
+
+// ReferencedTable returns the table a references field points at: user -> users
+func (f Field) ReferencedTable() string {
+	return naming.Pluralize(strings.TrimSuffix(f.Name, "_id"))
+}
+
+// columnType returns the migration column type for the field
+func (f Field) columnType() string {
+	switch f.Type {
+	case "string", "enum", "image":
+		// Images are stored as the path the upload was saved under
+		return "varchar(255)"
+	case "references":
+		return "integer"
+	}
+	return f.Type
+}
+
+// modelType returns the type the field is declared with in the domain's fulcrum.yml
+func (f Field) modelType() string {
+	switch f.Type {
+	case "enum":
+		return "string"
+	case "references":
+		return "integer"
+	}
+	return f.Type
+}
+
+// searchable reports whether ?q= on the index page should match the field
+func (f Field) searchable() bool {
+	return f.Type == "string" || f.Type == "text" || f.Type == "enum"
+}
+
+// check returns the CHECK constraint that limits an enum field to its options
+func (f Field) check() string {
+	if f.Type != "enum" {
+		return ""
+	}
+	quoted := make([]string, len(f.Options))
+	for i, option := range f.Options {
+		quoted[i] = "'" + option + "'"
+	}
+	return fmt.Sprintf("%s IN (%s)", f.Name, strings.Join(quoted, ", "))
+}
+
+// hasImage reports whether any field is an image, which needs a multipart form
+func hasImage(fields []Field) bool {
+	for _, field := range fields {
+		if field.Type == "image" {
+			return true
+		}
+	}
+	return false
+}
+
+// generateLookupSQL selects the options for every references field as lookup, value and
+// label rows, or returns "" when there are none
+func generateLookupSQL(fields []Field) string {
+	var selects []string
+	for _, field := range fields {
+		if field.Type == "references" {
+			selects = append(selects, fmt.Sprintf("SELECT '%s' AS lookup, id AS value, id AS label FROM %s", field.Column(), field.ReferencedTable()))
+		}
+	}
+	if len(selects) == 0 {
+		return ""
+	}
+	return "-- Options for the reference selects; change label to a readable column\n" + strings.Join(selects, "\nUNION ALL\n") + ";\n"
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -23,16 +24,24 @@ Available subcommands:
 
 // generateMigrationCmd generates new migration files
 var generateMigrationCmd = &cobra.Command{
-	Use:   "migration [name]",
+	Use:   "migration <domain> <name> [add_column:table.column:type | drop_column:table.column[:type]...]",
 	Short: "Generate a new migration file",
-	Long: `Generate a new YAML migration file in the specified domain.
+	Long: `Generate the next numbered YAML migration file in a domain's migrations/ directory.
 
 Usage:
-  fulcrum generate migration create_users --domain=users
-  fulcrum generate migration add_email_index --domain=users
+  fulcrum generate migration users create_users
+  fulcrum generate migration users add_email_index
+  fulcrum generate migration users rename_name_to_full_name_in_users
+  fulcrum generate migration users rename_people_to_users
+  fulcrum generate migration users add_phone_to_users add_column:users.phone:varchar
+  fulcrum generate migration users drop_legacy_id drop_column:users.legacy_id:integer
+
+add_column and drop_column arguments fill in the up operations and their
+reversal in down; drop_column takes the type so down can re-add the column.
+The older form, fulcrum generate migration <name> --domain=<domain>, still works.
 
 The migration name should describe what the migration does.`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.MinimumNArgs(1),
 	Run:  runGenerateMigration,
 }
 
@@ -46,11 +55,17 @@ func init() {
 	generateCmd.AddCommand(generateActionCmd)
 
 	// Flags for generate migration
-	generateMigrationCmd.Flags().StringVar(&generateDomain, "domain", "", "Domain to create the migration in (required)")
-	generateMigrationCmd.MarkFlagRequired("domain")
+	generateMigrationCmd.Flags().StringVar(&generateDomain, "domain", "", "Domain to create the migration in, instead of the first argument")
 }
 
 func runGenerateMigration(cmd *cobra.Command, args []string) {
+	domain := generateDomain
+	if domain == "" {
+		if len(args) < 2 {
+			log.Fatalf("Usage: fulcrum generate migration <domain> <name>")
+		}
+		domain, args = args[0], args[1:]
+	}
 	migrationName := args[0]
 
 	// Get current working directory as app path
@@ -59,56 +74,133 @@ func runGenerateMigration(cmd *cobra.Command, args []string) {
 		log.Fatalf("Failed to get current directory: %v", err)
 	}
 
+	filePath, err := generateMigration(appPath, domain, migrationName, args[1:], time.Now())
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	fmt.Printf("✅ Created migration: %s\n", filePath)
+	fmt.Printf("📝 Edit the file to add your migration operations\n")
+	fmt.Printf("\nNext steps:\n")
+	fmt.Printf("  1. Edit %s\n", filePath)
+	fmt.Printf("  2. Add your up and down operations\n")
+	fmt.Printf("  3. Run: fulcrum migrate up\n")
+}
+
+// generateMigration writes the domain's next migration file and returns its path
+func generateMigration(appPath, domain, migrationName string, columnOps []string, now time.Time) (string, error) {
 	// Validate domain exists
-	domainPath := filepath.Join(appPath, "domains", generateDomain)
+	domainPath := filepath.Join(appPath, "domains", domain)
 	if _, err := os.Stat(domainPath); os.IsNotExist(err) {
-		log.Fatalf("Domain '%s' does not exist. Create the domain directory first.", generateDomain)
+		return "", fmt.Errorf("domain '%s' does not exist. Create the domain directory first", domain)
 	}
 
 	// Create migrations directory if it doesn't exist
 	migrationsDir := filepath.Join(domainPath, "migrations")
 	if err := os.MkdirAll(migrationsDir, 0755); err != nil {
-		log.Fatalf("Failed to create migrations directory: %v", err)
+		return "", fmt.Errorf("failed to create migrations directory: %w", err)
 	}
 
-	// Get next version number
-	parser := migration.NewParser(appPath)
-	existingMigrations, err := parser.LoadDomainMigrations(generateDomain)
+	nextVersion, err := nextMigrationVersion(appPath, domain)
 	if err != nil {
-		log.Fatalf("Failed to load existing migrations: %v", err)
-	}
-
-	nextVersion := 1
-	for _, existing := range existingMigrations {
-		if existing.Version >= nextVersion {
-			nextVersion = existing.Version + 1
-		}
+		return "", err
 	}
 
 	// Generate filename
-	versionStr := fmt.Sprintf("%03d", nextVersion)
-	fileName := fmt.Sprintf("%s_%s.yml", versionStr, migrationName)
+	fileName := fmt.Sprintf("%03d_%s.yml", nextVersion, migrationName)
 	filePath := filepath.Join(migrationsDir, fileName)
 
 	// Check if file already exists
 	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
-		log.Fatalf("Migration file already exists: %s", filePath)
+		return "", fmt.Errorf("migration file already exists: %s", filePath)
 	}
 
 	// Generate migration template
 	template := generateMigrationTemplate(nextVersion, migrationName)
+	if len(columnOps) > 0 {
+		template, err = generateColumnMigration(nextVersion, migrationName, columnOps)
+		if err != nil {
+			return "", err
+		}
+	}
+	template = fmt.Sprintf("# Generated by fulcrum generate migration on %s\n", now.Format(time.RFC3339)) + template
 
 	// Write the file
 	if err := os.WriteFile(filePath, []byte(template), 0644); err != nil {
-		log.Fatalf("Failed to write migration file: %v", err)
+		return "", fmt.Errorf("failed to write migration file: %w", err)
 	}
 
-	fmt.Printf("✅ Created migration: %s\n", filePath)
-	fmt.Printf("📝 Edit the file to add your migration operations\n")
-	fmt.Printf("\nNext steps:\n")
-	fmt.Printf("  1. Edit %s\n", filePath)
-	fmt.Printf("  2. Add your up and down operations\n")
-	fmt.Printf("  3. Run: fulcrum migrate up\n")
+	return filePath, nil
+}
+
+// nextMigrationVersion returns one past the highest migration version in a domain
+func nextMigrationVersion(appPath, domain string) (int, error) {
+	existingMigrations, err := migration.NewParser(appPath).LoadDomainMigrations(domain)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load existing migrations: %w", err)
+	}
+
+	nextVersion := 1
+	for _, existing := range existingMigrations {
+		if existing.Version >= nextVersion {
+			nextVersion = existing.Version + 1
+		}
+	}
+	return nextVersion, nil
+}
+
+// generateColumnMigration builds a migration from add_column:table.column:type and
+// drop_column:table.column[:type] arguments, with down undoing them in reverse order
+func generateColumnMigration(version int, name string, columnOps []string) (string, error) {
+	var up, down []string
+	for _, arg := range columnOps {
+		op, spec, _ := strings.Cut(arg, ":")
+		target, columnType, _ := strings.Cut(spec, ":")
+		table, column, ok := strings.Cut(target, ".")
+		if !ok || table == "" || column == "" {
+			return "", fmt.Errorf("invalid column argument %q: expected %s:table.column:type", arg, op)
+		}
+
+		// down re-adds a dropped column, which needs its type
+		typeComment := ""
+		if op == "drop_column" && columnType == "" {
+			columnType, typeComment = "text", "  # TODO: Set the dropped column's type"
+		}
+
+		addColumn := fmt.Sprintf(`  - add_column:
+      table: %s
+      name: %s
+      type: %s%s
+      nullable: true
+`, table, column, columnType, typeComment)
+		dropColumn := fmt.Sprintf(`  - drop_column:
+      table: %s
+      name: %s
+`, table, column)
+
+		switch op {
+		case "add_column":
+			if columnType == "" {
+				return "", fmt.Errorf("invalid column argument %q: add_column needs a type", arg)
+			}
+			up = append(up, addColumn)
+			down = append([]string{dropColumn}, down...)
+		case "drop_column":
+			up = append(up, dropColumn)
+			down = append([]string{addColumn}, down...)
+		default:
+			return "", fmt.Errorf("invalid column argument %q: expected add_column or drop_column", arg)
+		}
+	}
+
+	return fmt.Sprintf(`version: %d
+name: %s
+description: "TODO: Add description of what this migration does"
+
+up:
+%s
+down:
+%s`, version, name, strings.Join(up, ""), strings.Join(down, "")), nil
 }
 
 func generateMigrationTemplate(version int, name string) string {
@@ -137,6 +229,15 @@ up:
   #     type: text
   #     nullable: true
   #
+  # - rename_table:
+  #     from: old_table
+  #     to: new_table
+  #
+  # - rename_column:
+  #     table: existing_table
+  #     from: old_column
+  #     to: new_column
+  #
   # - add_index:
   #     table: table_name
   #     columns: [column1, column2]
@@ -204,6 +305,44 @@ down:
       name: %s
 `, version, name, columnName, tableName, tableName, columnName, tableName, columnName)
 		}
+	} else if strings.HasPrefix(lowerName, "rename_") && strings.Contains(lowerName, "_to_") && strings.Contains(lowerName, "_in_") {
+		// Pattern: rename_old_to_new_in_table
+		rest := strings.TrimPrefix(lowerName, "rename_")
+		columns, tableName, _ := strings.Cut(rest, "_in_")
+		fromColumn, toColumn, _ := strings.Cut(columns, "_to_")
+		template = fmt.Sprintf(`version: %d
+name: %s
+description: "Rename %s column to %s in %s table"
+
+up:
+  - rename_column:
+      table: %s
+      from: %s
+      to: %s
+
+down:
+  - rename_column:
+      table: %s
+      from: %s
+      to: %s
+`, version, name, fromColumn, toColumn, tableName, tableName, fromColumn, toColumn, tableName, toColumn, fromColumn)
+	} else if strings.HasPrefix(lowerName, "rename_") && strings.Contains(lowerName, "_to_") {
+		// Pattern: rename_old_table_to_new_table
+		fromTable, toTable, _ := strings.Cut(strings.TrimPrefix(lowerName, "rename_"), "_to_")
+		template = fmt.Sprintf(`version: %d
+name: %s
+description: "Rename %s table to %s"
+
+up:
+  - rename_table:
+      from: %s
+      to: %s
+
+down:
+  - rename_table:
+      from: %s
+      to: %s
+`, version, name, fromTable, toTable, fromTable, toTable, toTable, fromTable)
 	} else if strings.HasPrefix(lowerName, "add_index") {
 		template = fmt.Sprintf(`version: %d
 name: %s
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// generateAuditCmd writes the migration creating the audit_log table
+var generateAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Add the audit_log table migration to domains/audit",
+	Long: `Write the migration that creates the audit_log table, which records who
+changed what in the tables listed under audit in fulcrum.yml:
+
+  fulcrum generate audit
+  fulcrum migrate up
+
+  # fulcrum.yml
+  audit: [posts, invoices]
+
+Each create, update, delete and restore of an audited table is recorded with
+the signed-in user, the request id and the row before and after the change.`,
+	Args: cobra.NoArgs,
+	Run:  runGenerateAudit,
+}
+
+var auditPath string
+
+func init() {
+	generateCmd.AddCommand(generateAuditCmd)
+	generateAuditCmd.Flags().StringVar(&auditPath, "path", "", "Project to add the migration to (defaults to the current directory)")
+}
+
+func runGenerateAudit(cmd *cobra.Command, args []string) {
+	projectPath := auditPath
+	if projectPath == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			log.Fatalf("Failed to get current directory: %v", err)
+		}
+		projectPath = cwd
+	}
+
+	path, created, err := writeAuditMigration(projectPath)
+	if err != nil {
+		log.Fatalf("Failed to write the audit migration: %v", err)
+	}
+	if !created {
+		fmt.Printf("⏭️  Skipped %s (already exists)\n", path)
+		return
+	}
+	fmt.Printf("✅ Created %s\n", path)
+	fmt.Println("   Run fulcrum migrate up, then list the tables to audit under audit: in fulcrum.yml")
+}
+
+// writeAuditMigration writes the audit_log migration into projectPath, returning its
+// path relative to the project and whether it was written; an existing one is kept
+func writeAuditMigration(projectPath string) (string, bool, error) {
+	path := filepath.Join("domains", "audit", "migrations", "001_create_audit_log_table.yml")
+	dst := filepath.Join(projectPath, path)
+	if _, err := os.Stat(dst); err == nil {
+		return path, false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return path, false, err
+	}
+	if err := os.WriteFile(dst, []byte(auditMigrationContent), 0644); err != nil {
+		return path, false, err
+	}
+	return path, true, nil
+}
+
+// auditMigrationContent creates the table DatabaseExecutor records audited changes in
+const auditMigrationContent = `version: 1
+name: create_audit_log_table
+description: "Create the audit_log table recording changes to the tables listed under audit in fulcrum.yml"
+
+up:
+  - create_table:
+      name: audit_log
+      columns:
+        - name: id
+          type: serial
+          primary_key: true
+        - name: actor_id
+          type: varchar
+          length: 255
+          nullable: true
+        - name: table_name
+          type: varchar
+          length: 255
+          nullable: false
+        - name: record_id
+          type: varchar
+          length: 255
+          nullable: true
+        - name: action
+          type: varchar
+          length: 32
+          nullable: false
+        - name: before_data
+          type: text
+          nullable: true
+        - name: after_data
+          type: text
+          nullable: true
+        - name: request_id
+          type: varchar
+          length: 64
+          nullable: true
+        - name: created_at
+          type: timestamp
+          nullable: false
+  - add_index:
+      table: audit_log
+      columns: [table_name, record_id]
+
+down:
+  - drop_table:
+      name: audit_log
+`
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"fulcrum/lib/database"
+	"fulcrum/lib/database/interfaces"
+	"fulcrum/lib/database/migration"
+)
+
+func TestGenerateAuditMigration(t *testing.T) {
+	ctx := context.Background()
+	appPath := t.TempDir()
+	writeAppFile(t, appPath, "domains/notes/migrations/001_create_notes.yml", `version: 1
+name: create_notes
+up:
+  - create_table:
+      name: notes
+      columns:
+        - name: id
+          type: serial
+          primary_key: true
+        - name: title
+          type: varchar
+          length: 255
+down:
+  - drop_table:
+      name: notes
+`)
+
+	if _, created, err := writeAuditMigration(appPath); err != nil || !created {
+		t.Fatalf("Expected the migration to be written, got created %v and %v", created, err)
+	}
+	if _, created, err := writeAuditMigration(appPath); err != nil || created {
+		t.Fatalf("Expected the existing migration to be kept, got created %v and %v", created, err)
+	}
+
+	manager, err := connectDatabase(ctx, interfaces.Config{Driver: interfaces.DriverSQLite, FilePath: filepath.Join(appPath, "app.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	runner := migration.NewRunner(manager.GetDatabase(), appPath)
+	if err := runner.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.MigrateUp(ctx); err != nil {
+		t.Fatalf("Migrations failed: %v", err)
+	}
+
+	executor := database.NewDatabaseExecutor(manager.GetDatabase())
+	if err := executor.EnableAudit(ctx, []string{"notes"}); err != nil {
+		t.Fatalf("Expected auditing to work against the migrated table: %v", err)
+	}
+	if _, err := executor.CreateRecord(database.WithAuditActor(ctx, "1", ""), "notes", map[string]any{"title": "a"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := executor.AuditEntries(ctx, database.AuditQuery{Table: "notes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].After["title"] != "a" || entries[0].ActorID != "1" {
+		t.Errorf("Expected the create recorded, got %+v", entries)
+	}
+}
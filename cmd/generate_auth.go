@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fulcrum/lib/views"
+
+	"github.com/spf13/cobra"
+)
+
+// generateAuthCmd copies the default auth page templates into a project
+var generateAuthCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Copy the default auth templates into domains/auth",
+	Long: `Copy the built-in login, register and dashboard templates into the project's
+domains/auth directory so they can be customized. The server renders a
+project's copy in place of the built-in page.
+
+  fulcrum generate auth
+  fulcrum generate auth --force
+
+Existing templates are left alone unless --force is given.`,
+	Args: cobra.NoArgs,
+	Run:  runGenerateAuth,
+}
+
+var (
+	authPath  string
+	authForce bool
+)
+
+func init() {
+	generateCmd.AddCommand(generateAuthCmd)
+	generateAuthCmd.Flags().StringVar(&authPath, "path", "", "Project to copy the templates into (defaults to the current directory)")
+	generateAuthCmd.Flags().BoolVar(&authForce, "force", false, "Overwrite templates that already exist")
+}
+
+func runGenerateAuth(cmd *cobra.Command, args []string) {
+	projectPath := authPath
+	if projectPath == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			log.Fatalf("Failed to get current directory: %v", err)
+		}
+		projectPath = cwd
+	}
+
+	copied, err := copyAuthTemplates(projectPath, authForce)
+	if err != nil {
+		log.Fatalf("Failed to copy auth templates: %v", err)
+	}
+
+	for _, page := range views.AuthPages {
+		dst := filepath.Join("domains", "auth", page.Template)
+		if copied[page.Template] {
+			fmt.Printf("✅ Created %s\n", dst)
+		} else {
+			fmt.Printf("⏭️  Skipped %s (already exists, use --force to overwrite)\n", dst)
+		}
+
+		variables := make([]string, len(page.Variables))
+		for i, name := range page.Variables {
+			variables[i] = "{{" + name + "}}"
+		}
+		fmt.Printf("   Variables: %s\n", strings.Join(variables, ", "))
+	}
+}
+
+// copyAuthTemplates writes the default auth page templates to projectPath/domains/auth,
+// returning which templates were written; existing files are kept unless force is set
+func copyAuthTemplates(projectPath string, force bool) (map[string]bool, error) {
+	copied := make(map[string]bool)
+	for _, page := range views.AuthPages {
+		dst := filepath.Join(projectPath, "domains", "auth", page.Template)
+		if _, err := os.Stat(dst); err == nil && !force {
+			continue
+		}
+		if err := copyEmbeddedFile(views.AuthFiles(), page.Template, dst); err != nil {
+			return copied, fmt.Errorf("failed to copy %s: %w", page.Template, err)
+		}
+		copied[page.Template] = true
+	}
+	return copied, nil
+}
+
+// copyEmbeddedFile copies name from files to dst, creating dst's directory
+func copyEmbeddedFile(files fs.FS, name, dst string) error {
+	content, err := fs.ReadFile(files, name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, content, 0644)
+}
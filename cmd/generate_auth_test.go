@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fulcrum/lib/views"
+)
+
+func TestCopyAuthTemplates(t *testing.T) {
+	projectPath := t.TempDir()
+	customLogin := filepath.Join(projectPath, "domains", "auth", "login", "get.html.hbs")
+	if err := os.MkdirAll(filepath.Dir(customLogin), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(customLogin, []byte("custom"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		force     bool
+		wantLogin string
+	}{
+		{name: "keeps existing templates", wantLogin: "custom"},
+		{name: "force overwrites", force: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			copied, err := copyAuthTemplates(projectPath, tt.force)
+			if err != nil {
+				t.Fatalf("copyAuthTemplates failed: %v", err)
+			}
+			if copied["login/get.html.hbs"] != tt.force {
+				t.Errorf("Expected login copied: %v, got %v", tt.force, copied["login/get.html.hbs"])
+			}
+
+			for _, page := range views.AuthPages {
+				if _, err := os.Stat(filepath.Join(projectPath, "domains", "auth", page.Template)); err != nil {
+					t.Errorf("Expected %s in the project: %v", page.Template, err)
+				}
+			}
+
+			login, err := os.ReadFile(customLogin)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantLogin != "" && string(login) != tt.wantLogin {
+				t.Errorf("Expected the existing login template to be kept, got %q", login)
+			}
+			if tt.force && string(login) == "custom" {
+				t.Error("Expected --force to overwrite the login template")
+			}
+		})
+	}
+}
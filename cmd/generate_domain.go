@@ -1,16 +1,48 @@
 package cmd
 
 import (
+	"embed"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
+	"fulcrum/lib/naming"
+	"fulcrum/lib/parser"
+
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
 )
 
+// templateFiles are the generator templates, embedded so the binary works outside the source tree
+//
+//go:embed templates/*
+var templateFiles embed.FS
+
+// domainTemplates returns the generator templates, e.g. "index.html.hbs"
+func domainTemplates() fs.FS {
+	templates, err := fs.Sub(templateFiles, "templates")
+	if err != nil {
+		panic(err) // The embedded directory always exists
+	}
+	return templates
+}
+
 var domainPath string
+var apiOnly bool
+var autoTimestamps bool
+var withHandler bool
+var withSearch bool
+var softDelete bool
+var lockVersion bool
+var quoteStyle string
+var domainForce bool
+var domainOnly []string
+var domainSkip []string
 
 // generateDomainCmd generates a new domain
 var generateDomainCmd = &cobra.Command{
@@ -21,7 +53,56 @@ var generateDomainCmd = &cobra.Command{
 Usage:
   fulcrum generate domain users name:string email:string
 
-This will create a new directory under 'domains/' with the specified name and populate it with the basic CRUD structure and fields.`,
+This will create a new directory under 'domains/' with the specified name and populate it with the basic CRUD structure and fields.
+
+Field types are string, text, integer, boolean, decimal, date, references and
+enum[value,...]:
+
+  fulcrum generate domain posts title:string user:references price:decimal \
+    published_on:date status:enum[draft,published,archived]
+
+user:references adds a user_id column with a foreign key to users, and a select on
+the new form filled by the lookup query in new/get.sql.hbs. Enum columns are limited
+to their values by a CHECK constraint and edited with a select.
+
+The domain's fulcrum.yml declares the model, so ?filter[column]=value narrows the
+index to one value of a model field, and the index table headers sort by their
+column with ?sort=column&dir=asc|desc.
+
+Use --with-search to list the string, text and enum columns under search in the
+domain's fulcrum.yml and add a search box to the index page. ?q= matches those
+columns, and the box fetches index/get.htmx.hbs over HTMX as you type, which
+renders just the table rows.
+
+Use --api (or --api-only) for domains that serve only a JSON API: no HTML templates,
+redirects or [id] routes are generated, only index and create SQL and JSON route files.
+
+Re-running the generator on an existing domain keeps every file that already exists,
+including the table's create migration, and prints what it created and skipped; pass
+--force to overwrite them. --only index,show generates just those actions and
+--skip edit,update leaves actions out (index, new, create, show, edit, update).
+
+The migration adds a PostgreSQL trigger that keeps updated_at current on every
+UPDATE; pass --auto-timestamps=false to leave it out (e.g. on MySQL or SQLite).
+
+Use --with-handler to also write domains/<name>/handler.js, a JavaScript handler
+with index, show, create, update and delete functions that receive each route's SQL
+data and request data and return what the template renders. If the project has no
+package.json, one is created along with the index.js that starts the handler service.
+
+Column names in the generated SQL are quoted so fields named after SQL keywords
+(from, where, order) work. Use --quote-style=backtick for MySQL; the default is
+ANSI double quotes.
+
+Use --soft-delete to add a deleted_at column to the migration: the generated queries
+leave deleted rows out, and [id]/delete and [id]/restore routes set and clear
+deleted_at instead of removing the row. The show page gets a delete button.
+
+Use --lock-version for optimistic locking: the migration adds a lock_version column,
+the edit form submits the version it was loaded with, and the update only applies
+while the record is still at that version, incrementing it. An update that lost to
+someone else's is answered with 409 Conflict and the edit form showing the latest
+version beside the submitted changes.`,
 	Args: cobra.MinimumNArgs(1),
 	Run:  runGenerateDomain,
 }
@@ -29,34 +110,40 @@ This will create a new directory under 'domains/' with the specified name and po
 func init() {
 	generateCmd.AddCommand(generateDomainCmd)
 	generateDomainCmd.Flags().StringVar(&domainPath, "path", "", "Path to generate the domain in")
-}
-
-func pluralize(s string) string {
-	if strings.HasSuffix(s, "y") {
-		return s[:len(s)-1] + "ies"
-	}
-	return s + "s"
-}
-
-func titleize(s string) string {
-	return strings.Title(s)
-}
-
-type Field struct {
-	Name string
-	Type string
+	generateDomainCmd.Flags().BoolVar(&apiOnly, "api-only", false, "Generate only SQL and JSON route files (no HTML templates)")
+	generateDomainCmd.Flags().BoolVar(&apiOnly, "api", false, "Same as --api-only")
+	generateDomainCmd.Flags().BoolVar(&autoTimestamps, "auto-timestamps", true, "Add a PostgreSQL trigger that sets updated_at on every UPDATE")
+	generateDomainCmd.Flags().BoolVar(&withHandler, "with-handler", false, "Scaffold a JavaScript handler.js for the domain's routes")
+	generateDomainCmd.Flags().BoolVar(&withSearch, "with-search", false, "Add a search box to the index page that filters the list over HTMX")
+	generateDomainCmd.Flags().BoolVar(&softDelete, "soft-delete", false, "Add a deleted_at column and delete/restore routes that set and clear it")
+	generateDomainCmd.Flags().BoolVar(&lockVersion, "lock-version", false, "Add a lock_version column so concurrent edits conflict instead of overwriting each other")
+	generateDomainCmd.Flags().StringVar(&quoteStyle, "quote-style", "double", "How to quote column names in generated SQL: double or backtick")
+	generateDomainCmd.Flags().BoolVar(&domainForce, "force", false, "Overwrite files that already exist")
+	generateDomainCmd.Flags().StringSliceVar(&domainOnly, "only", nil, "Generate only these actions, e.g. index,show")
+	generateDomainCmd.Flags().StringSliceVar(&domainSkip, "skip", nil, "Leave out these actions, e.g. edit,update")
 }
 
 func runGenerateDomain(cmd *cobra.Command, args []string) {
-	domainName := args[0]
-	var fields []Field
+	opts := domainOptions{
+		name:           args[0],
+		apiOnly:        apiOnly,
+		autoTimestamps: autoTimestamps,
+		withHandler:    withHandler,
+		withSearch:     withSearch,
+		softDelete:     softDelete,
+		lockVersion:    lockVersion,
+		force:          domainForce,
+		quoteStyle:     quoteStyle,
+		only:           domainOnly,
+		skip:           domainSkip,
+	}
 
 	for _, arg := range args[1:] {
-		parts := strings.SplitN(arg, ":", 2)
-		if len(parts) != 2 {
-			log.Fatalf("Invalid field format: %s. Expected format: name:type", arg)
+		field, err := parseField(arg)
+		if err != nil {
+			log.Fatalf("%v", err)
 		}
-		fields = append(fields, Field{Name: parts[0], Type: parts[1]})
+		opts.fields = append(opts.fields, field)
 	}
 
 	// Get current working directory
@@ -64,159 +151,400 @@ func runGenerateDomain(cmd *cobra.Command, args []string) {
 	if err != nil {
 		log.Fatalf("Failed to get current directory: %v", err)
 	}
+	opts.templates = domainTemplates()
 
 	// Use the provided path or the current working directory
-	basePath := cwd
+	opts.basePath = cwd
 	if domainPath != "" {
-		basePath = domainPath
+		opts.basePath = domainPath
 	}
 
-	// Create the domain directory
-	domainAbsPath := filepath.Join(basePath, "domains", domainName)
-	if err := os.MkdirAll(domainAbsPath, 0755); err != nil {
-		log.Fatalf("Failed to create domain directory: %v", err)
+	files, err := generateDomainFiles(opts)
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
+	files.printSummary(os.Stdout, opts.basePath)
 
-	// Create the fulcrum.yml file
-	fulcrumYmlPath := filepath.Join(domainAbsPath, "fulcrum.yml")
-	if err := os.WriteFile(fulcrumYmlPath, []byte("# Domain configuration for "+domainName), 0644); err != nil {
-		log.Fatalf("Failed to create fulcrum.yml: %v", err)
+	fmt.Printf("✅ Generated domain: %s in %s\n", opts.name, filepath.Join(opts.basePath, "domains", opts.name))
+	if files.skipped() > 0 {
+		fmt.Println("💡 Pass --force to overwrite the skipped files")
 	}
-
-	// Generate migration
-	migrationsDir := filepath.Join(domainAbsPath, "migrations")
-	if err := os.MkdirAll(migrationsDir, 0755); err != nil {
-		log.Fatalf("Failed to create migrations directory: %v", err)
+	if !withHandler {
+		fmt.Println("💡 Pass --with-handler to add a JavaScript handler for this domain")
 	}
+}
 
-	// Get next version number (simplified for now)
-	nextVersion := 1
-	// TODO: Implement proper versioning based on existing migrations
+// domainOptions are the settings generate domain runs with
+type domainOptions struct {
+	basePath       string
+	templates      fs.FS // Generator templates; see domainTemplates
+	name           string
+	fields         []Field
+	apiOnly        bool
+	autoTimestamps bool
+	withHandler    bool
+	withSearch     bool // Search box, search columns and an HTMX results partial
+	softDelete     bool // deleted_at column, queries that leave deleted rows out and delete/restore routes
+	lockVersion    bool // lock_version column checked and incremented by updates
+	force          bool
+	quoteStyle     string
+	only           []string // Actions to generate; empty for all
+	skip           []string // Actions to leave out
+}
 
-	migrationFileName := fmt.Sprintf("%03d_create_%s_table.yml", nextVersion, pluralize(domainName))
-	migrationFilePath := filepath.Join(migrationsDir, migrationFileName)
-	migrationContent := generateMigrationContent(domainName, fields)
-	if err := os.WriteFile(migrationFilePath, []byte(migrationContent), 0644); err != nil {
-		log.Fatalf("Failed to write migration file: %v", err)
-	}
-	fmt.Printf("✅ Created migration: %s\n", migrationFilePath)
+// domainActions are the routes generate domain writes, in order, with their HTTP method
+var domainActions = []struct {
+	name   string
+	method string
+}{
+	{"index", "get"},
+	{"new", "get"},
+	{"create", "post"},
+	{"show", "get"},
+	{"edit", "get"},
+	{"update", "post"},
+	{"delete", "post"},
+	{"restore", "post"},
+}
 
-	// Create the action directories and files
-	actions := map[string]string{
-		"index":  "get",
-		"new":    "get",
-		"create": "post",
-		"show":   "get",
-		"edit":   "get",
-		"update": "post",
-	}
+// softDeleteActions are the actions only soft delete domains get
+var softDeleteActions = map[string]bool{"delete": true, "restore": true}
 
-	for action, method := range actions {
-		var actionPath string
-		var htmlTemplateFileName string
-		var sqlTemplateFileName string
-		var redirectTemplateFileName string
+// recordActions are the actions routed under [name_id]
+var recordActions = map[string]bool{"show": true, "edit": true, "update": true, "delete": true, "restore": true}
 
-		if action == "show" || action == "edit" || action == "update" {
-			actionPath = filepath.Join(domainAbsPath, fmt.Sprintf("[%s_id]", domainName), action)
-		} else {
-			actionPath = filepath.Join(domainAbsPath, action)
+// sharedHTMLTemplates are actions that render another action's HTML template: update
+// shows the edit form again when the submission fails, and restore the page delete
+// shows when the record is missing
+var sharedHTMLTemplates = map[string]string{"update": "edit", "restore": "delete"}
+
+// apiActions are the actions API-only domains expose as JSON routes
+var apiActions = map[string]bool{"index": true, "create": true}
+
+// selectActions returns the actions to generate after --api, --soft-delete, --only and --skip
+func selectActions(apiOnly, softDelete bool, only, skip []string) (map[string]bool, error) {
+	known := make(map[string]bool, len(domainActions))
+	for _, action := range domainActions {
+		known[action.name] = true
+	}
+	for _, name := range append(append([]string{}, only...), skip...) {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown action %q: expected index, new, create, show, edit, update, delete or restore", name)
 		}
+	}
 
-		if err := os.MkdirAll(actionPath, 0755); err != nil {
-			log.Fatalf("Failed to create action directory: %v", err)
+	selected := make(map[string]bool)
+	for _, action := range domainActions {
+		if apiOnly && !apiActions[action.name] {
+			continue
+		}
+		if !softDelete && softDeleteActions[action.name] {
+			continue
 		}
+		if len(only) > 0 && !slices.Contains(only, action.name) {
+			continue
+		}
+		if slices.Contains(skip, action.name) {
+			continue
+		}
+		selected[action.name] = true
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no actions left to generate")
+	}
+	return selected, nil
+}
+
+// generateDomainFiles writes a domain's config, migration and action routes, keeping files
+// that already exist unless opts.force is set
+func generateDomainFiles(opts domainOptions) (*generatedFiles, error) {
+	if _, ok := identifierQuotes[opts.quoteStyle]; !ok {
+		return nil, fmt.Errorf("invalid --quote-style %q: expected double or backtick", opts.quoteStyle)
+	}
+	actions, err := selectActions(opts.apiOnly, opts.softDelete, opts.only, opts.skip)
+	if err != nil {
+		return nil, err
+	}
+	if opts.withSearch && opts.apiOnly {
+		return nil, fmt.Errorf("--with-search adds a search box to the index page, so it cannot be combined with --api")
+	}
+	if opts.withSearch && !slices.ContainsFunc(opts.fields, Field.searchable) {
+		return nil, fmt.Errorf("--with-search needs a string, text or enum field to search")
+	}
+
+	domainName := opts.name
+	files := &generatedFiles{force: opts.force}
+
+	// Create the domain directory
+	domainAbsPath := filepath.Join(opts.basePath, "domains", domainName)
+	if err := os.MkdirAll(domainAbsPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create domain directory: %w", err)
+	}
+
+	// Create the fulcrum.yml file
+	if err := files.write(filepath.Join(domainAbsPath, "fulcrum.yml"), []byte(generateDomainConfig(domainName, opts.fields, opts.withSearch))); err != nil {
+		return nil, err
+	}
 
-		htmlTemplateFileName = fmt.Sprintf("%s.html.hbs", action)
-		sqlTemplateFileName = fmt.Sprintf("%s.sql.hbs", action)
-		redirectTemplateFileName = "redirect.yaml.hbs"
+	if err := writeDomainMigration(files, opts); err != nil {
+		return nil, err
+	}
 
-		htmlHbsPath := filepath.Join(actionPath, fmt.Sprintf("%s.html.hbs", method))
-		sqlHbsPath := filepath.Join(actionPath, fmt.Sprintf("%s.sql.hbs", method))
-		redirectYamlPath := filepath.Join(actionPath, "redirect.yaml")
+	// The HTML templates look their text up in locales/en.yml
+	if !opts.apiOnly {
+		locale, err := generateDomainLocale(domainName, opts.fields)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Join(domainAbsPath, "locales"), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create locales directory: %w", err)
+		}
+		if err := files.write(filepath.Join(domainAbsPath, "locales", "en.yml"), locale); err != nil {
+			return nil, err
+		}
+	}
 
-		// Read HTML template content
-		htmlContent, err := os.ReadFile(filepath.Join(cwd, "cmd", "templates", htmlTemplateFileName))
+	// template reads a generator template and fills in the domain's names
+	template := func(fileName string) (string, error) {
+		content, err := fs.ReadFile(opts.templates, fileName)
 		if err != nil {
-			log.Fatalf("Failed to read HTML template: %v", err)
+			return "", fmt.Errorf("failed to read template %s: %w", fileName, err)
+		}
+		processed := strings.ReplaceAll(string(content), "{{pluralize .DomainName}}", naming.Pluralize(domainName))
+		processed = strings.ReplaceAll(processed, "{{.DomainName}}", domainName)
+		return strings.ReplaceAll(processed, "{{titleize .DomainName}}", naming.Titleize(domainName)), nil
+	}
+
+	for _, action := range domainActions {
+		if !actions[action.name] {
+			continue
 		}
-		processedHtmlContent := strings.ReplaceAll(string(htmlContent), "{{pluralize .DomainName}}", pluralize(domainName))
-		processedHtmlContent = strings.ReplaceAll(processedHtmlContent, "{{titleize .DomainName}}", titleize(domainName))
 
-		// Dynamically generate form fields for new and edit actions
-		if action == "new" || action == "edit" {
-			formFields := generateFormFields(fields)
-			processedHtmlContent = strings.ReplaceAll(processedHtmlContent, "<!-- FORM_FIELDS_PLACEHOLDER -->", formFields)
+		actionPath := filepath.Join(domainAbsPath, action.name)
+		if recordActions[action.name] {
+			actionPath = filepath.Join(domainAbsPath, fmt.Sprintf("[%s_id]", domainName), action.name)
+		}
+		if err := os.MkdirAll(actionPath, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create action directory: %w", err)
 		}
 
-		// Write HTML file
-		if err := os.WriteFile(htmlHbsPath, []byte(processedHtmlContent), 0644); err != nil {
-			log.Fatalf("Failed to write HTML file: %v", err)
+		if opts.apiOnly {
+			// JSON routes pass the SQL result straight through
+			jsonContent, err := fs.ReadFile(opts.templates, action.name+".json.hbs")
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JSON template: %w", err)
+			}
+			if err := files.write(filepath.Join(actionPath, action.method+".json.hbs"), jsonContent); err != nil {
+				return nil, err
+			}
+		} else {
+			htmlName := action.name
+			if shared, ok := sharedHTMLTemplates[action.name]; ok {
+				htmlName = shared
+			}
+			htmlContent, err := template(htmlName + ".html.hbs")
+			if err != nil {
+				return nil, err
+			}
+
+			// Dynamically generate form fields for new and edit actions; the new
+			// action's SQL returns the reference select options
+			if (htmlName == "new" || htmlName == "edit") && hasImage(opts.fields) {
+				htmlContent = multipartForm(htmlContent)
+			}
+			switch htmlName {
+			case "new":
+				lookupRows := ""
+				if generateLookupSQL(opts.fields) != "" {
+					lookupRows = "vm." + naming.Pluralize(domainName)
+				}
+				htmlContent = strings.ReplaceAll(htmlContent, "<!-- FORM_FIELDS_PLACEHOLDER -->", generateFormFields(domainName, opts.fields, lookupRows))
+			case "edit":
+				htmlContent = strings.ReplaceAll(htmlContent, "<!-- FORM_FIELDS_PLACEHOLDER -->", generateFormFields(domainName, opts.fields, ""))
+				htmlContent = strings.ReplaceAll(htmlContent, "<!-- LOCK_VERSION_PLACEHOLDER -->", generateLockVersionField(domainName, opts.lockVersion))
+				htmlContent = strings.ReplaceAll(htmlContent, "<!-- CONFLICT_PLACEHOLDER -->", generateConflictNotice(domainName, opts.fields, opts.lockVersion))
+			case "index", "show":
+				htmlContent = strings.ReplaceAll(htmlContent, "<!-- DELETE_BUTTON_PLACEHOLDER -->", generateDeleteButton(domainName, actions["delete"]))
+				htmlContent = strings.ReplaceAll(htmlContent, "<!-- REFERENCE_LINKS_PLACEHOLDER -->", generateReferenceLinks(domainName, opts.fields))
+				htmlContent = strings.ReplaceAll(htmlContent, "<!-- SEARCH_PLACEHOLDER -->", generateSearchBox(domainName, opts.withSearch))
+				htmlContent = strings.ReplaceAll(htmlContent, "<!-- TABLE_HEADERS_PLACEHOLDER -->", generateTableHeaders(domainName, opts.fields))
+				htmlContent = strings.ReplaceAll(htmlContent, "<!-- TABLE_CELLS_PLACEHOLDER -->", generateTableCells(opts.fields))
+			}
+			if err := files.write(filepath.Join(actionPath, action.method+".html.hbs"), []byte(htmlContent)); err != nil {
+				return nil, err
+			}
+
+			// HTMX searches get just the table rows
+			if action.name == "index" && opts.withSearch {
+				rowsContent, err := template("index.htmx.hbs")
+				if err != nil {
+					return nil, err
+				}
+				rowsContent = strings.ReplaceAll(rowsContent, "<!-- TABLE_CELLS_PLACEHOLDER -->", generateTableCells(opts.fields))
+				rowsContent = strings.ReplaceAll(rowsContent, "<!-- REFERENCE_LINKS_PLACEHOLDER -->", generateReferenceLinks(domainName, opts.fields))
+				rowsContent = strings.ReplaceAll(rowsContent, "<!-- COLUMN_COUNT -->", strconv.Itoa(len(tableColumns(opts.fields))+1))
+				if err := files.write(filepath.Join(actionPath, action.method+".htmx.hbs"), []byte(rowsContent)); err != nil {
+					return nil, err
+				}
+			}
 		}
 
-		// Read SQL template content
-		sqlContent, err := os.ReadFile(filepath.Join(cwd, "cmd", "templates", sqlTemplateFileName))
+		sqlContent, err := template(action.name + ".sql.hbs")
 		if err != nil {
-			log.Fatalf("Failed to read SQL template: %v", err)
+			return nil, err
 		}
-		processedSqlContent := strings.ReplaceAll(string(sqlContent), "{{pluralize .DomainName}}", pluralize(domainName))
-		processedSqlContent = strings.ReplaceAll(processedSqlContent, "{{titleize .DomainName}}", titleize(domainName))
 
 		// Dynamically generate SQL columns/values/setters for create and update actions
-		if action == "create" {
-			columns := generateSqlColumns(fields)
-			values := generateSqlValues(fields)
-			processedSqlContent = strings.ReplaceAll(processedSqlContent, "{{columns}}", columns)
-			processedSqlContent = strings.ReplaceAll(processedSqlContent, "{{values}}", values)
-		} else if action == "update" {
-			processedSqlContent = strings.ReplaceAll(processedSqlContent, "{{setters}}", generateSqlSetters(fields))
+		if action.name == "create" {
+			sqlContent = strings.ReplaceAll(sqlContent, "{{columns}}", generateSqlColumns(opts.fields, opts.quoteStyle))
+			sqlContent = strings.ReplaceAll(sqlContent, "{{values}}", generateSqlValues(opts.fields))
+		} else if action.name == "update" {
+			sqlContent = strings.ReplaceAll(sqlContent, "{{setters}}", generateSqlSetters(opts.fields, opts.quoteStyle))
+		} else if action.name == "new" {
+			if lookupSQL := generateLookupSQL(opts.fields); lookupSQL != "" {
+				sqlContent = lookupSQL
+			}
 		}
-
-		// Write SQL file
-		if err := os.WriteFile(sqlHbsPath, []byte(processedSqlContent), 0644); err != nil {
-			log.Fatalf("Failed to write SQL file: %v", err)
+		if opts.softDelete {
+			sqlContent = excludeDeleted(action.name, sqlContent)
+		}
+		if opts.lockVersion && action.name == "update" {
+			sqlContent = lockUpdate(sqlContent)
+		}
+		if err := files.write(filepath.Join(actionPath, action.method+".sql.hbs"), []byte(sqlContent)); err != nil {
+			return nil, err
 		}
 
-		// Execute Redirect YAML template for create action
-		if action == "create" {
-			redirectContent, err := os.ReadFile(filepath.Join(cwd, "cmd", "templates", redirectTemplateFileName))
+		// Redirect to the record after create, update and restore, and to the list after delete
+		if (action.name == "create" || action.name == "update" || softDeleteActions[action.name]) && !opts.apiOnly {
+			redirectTemplate := "redirect.yaml.hbs"
+			if action.name == "delete" {
+				redirectTemplate = "redirect_index.yaml.hbs"
+			}
+			redirectContent, err := template(redirectTemplate)
 			if err != nil {
-				log.Fatalf("Failed to read redirect YAML template: %v", err)
+				return nil, err
 			}
-			processedRedirectContent := strings.ReplaceAll(string(redirectContent), "{{pluralize .DomainName}}", pluralize(domainName))
-			processedRedirectContent = strings.ReplaceAll(processedRedirectContent, "{{id}}", "{{id}}")
+			if err := files.write(filepath.Join(actionPath, "redirect.yaml"), []byte(redirectContent)); err != nil {
+				return nil, err
+			}
+		}
+	}
 
-			if err := os.WriteFile(redirectYamlPath, []byte(processedRedirectContent), 0644); err != nil {
-				log.Fatalf("Failed to write redirect YAML file: %v", err)
+	// Scaffold a JavaScript handler for the domain's routes
+	if opts.withHandler {
+		handlerPath := filepath.Join(domainAbsPath, "handler.js")
+		if _, err := os.Stat(handlerPath); err == nil && !opts.force {
+			files.record(handlerPath, fileSkipped)
+		} else {
+			if err == nil {
+				if err := os.Remove(handlerPath); err != nil {
+					return nil, fmt.Errorf("failed to replace handler.js: %w", err)
+				}
+			}
+			if err := writeDomainHandler(opts.basePath, domainName); err != nil {
+				return nil, fmt.Errorf("failed to scaffold handler: %w", err)
 			}
 		}
 	}
 
-	fmt.Printf("✅ Created domain: %s in %s\n", domainName, domainAbsPath)
+	return files, nil
 }
 
-func generateMigrationContent(domainName string, fields []Field) string {
-	pluralDomainName := pluralize(domainName)
+// writeDomainMigration writes the migration that creates the domain's table, unless the
+// domain already has one; with force the existing migration is rewritten in place
+func writeDomainMigration(files *generatedFiles, opts domainOptions) error {
+	migrationsDir := filepath.Join(opts.basePath, "domains", opts.name, "migrations")
+	if err := os.MkdirAll(migrationsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create migrations directory: %w", err)
+	}
 
-	columnsYaml := ""
-	for _, field := range fields {
-		columnType := field.Type
-		if field.Type == "string" {
-			columnType = "varchar(255)"
-		} else if field.Type == "text" {
-			columnType = "text"
-		} else if field.Type == "integer" {
-			columnType = "integer"
-		} else if field.Type == "boolean" {
-			columnType = "boolean"
+	table := naming.Pluralize(opts.name)
+	existing, err := filepath.Glob(filepath.Join(migrationsDir, "*_create_"+table+"_table.yml"))
+	if err != nil {
+		return fmt.Errorf("failed to look for an existing migration: %w", err)
+	}
+
+	if len(existing) > 0 {
+		if !opts.force {
+			files.record(existing[0], fileSkipped)
+			return nil
+		}
+		version, err := strconv.Atoi(strings.SplitN(filepath.Base(existing[0]), "_", 2)[0])
+		if err != nil {
+			return fmt.Errorf("failed to read the version of %s: %w", existing[0], err)
 		}
+		return files.write(existing[0], []byte(generateMigrationContent(version, opts.name, opts.fields, opts.autoTimestamps, opts.softDelete, opts.lockVersion)))
+	}
+
+	// Number after any migrations the domain already has
+	nextVersion, err := nextMigrationVersion(opts.basePath, opts.name)
+	if err != nil {
+		return err
+	}
+
+	migrationFilePath := filepath.Join(migrationsDir, fmt.Sprintf("%03d_create_%s_table.yml", nextVersion, table))
+	return files.write(migrationFilePath, []byte(generateMigrationContent(nextVersion, opts.name, opts.fields, opts.autoTimestamps, opts.softDelete, opts.lockVersion)))
+}
+
+func generateMigrationContent(version int, domainName string, fields []Field, autoTimestamps, softDelete, lockVersion bool) string {
+	pluralDomainName := naming.Pluralize(domainName)
+
+	columnsYaml, foreignKeysYaml := "", ""
+	if softDelete {
+		columnsYaml = `
+        - name: deleted_at
+          type: timestamp
+          nullable: true`
+	}
+	if lockVersion {
+		columnsYaml += fmt.Sprintf(`
+        - name: %s
+          type: integer
+          nullable: false
+          default: 0`, parser.DefaultLockColumn)
+	}
+	for _, field := range fields {
 		columnsYaml += fmt.Sprintf(`
         - name: %s
           type: %s
-          nullable: true`, field.Name, columnType)
+          nullable: true`, field.Column(), field.columnType())
+		if check := field.check(); check != "" {
+			columnsYaml += fmt.Sprintf(`
+          check: "%s"`, check)
+		}
+
+		if field.Type == "references" {
+			foreignKeysYaml += fmt.Sprintf(`
+  - add_foreign_key:
+      table: %s
+      column: %s
+      referenced_table: %s
+      referenced_column: id`, pluralDomainName, field.Column(), field.ReferencedTable())
+		}
+	}
+
+	// PostgreSQL only updates updated_at on UPDATE through a trigger; the function is
+	// shared by every table, so down drops only this table's trigger
+	triggerUp, triggerDown := "", ""
+	if autoTimestamps {
+		triggerUp = fmt.Sprintf(`
+  - execute:
+      sql: |
+        CREATE OR REPLACE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+        BEGIN
+          NEW.updated_at = NOW();
+          RETURN NEW;
+        END;
+        $$ LANGUAGE plpgsql;
+        CREATE TRIGGER set_%[1]s_updated_at BEFORE UPDATE ON %[1]s
+          FOR EACH ROW EXECUTE FUNCTION set_updated_at();`, pluralDomainName)
+		triggerDown = fmt.Sprintf(`
+  - execute:
+      sql: DROP TRIGGER IF EXISTS set_%[1]s_updated_at ON %[1]s`, pluralDomainName)
 	}
 
-	return fmt.Sprintf(`version: 1
+	return fmt.Sprintf(`version: %d
 name: create_%s_table
 description: "Create %s table"
 
@@ -234,43 +562,319 @@ up:
         - name: updated_at
           type: timestamp
           nullable: false
-          default: "NOW()"%s
+          default: "NOW()"%s%s%s
 
-down:
+down:%s
   - drop_table:
       name: %s
-`, pluralDomainName, pluralDomainName, pluralDomainName, columnsYaml, pluralDomainName)
+`, version, pluralDomainName, pluralDomainName, pluralDomainName, columnsYaml, foreignKeysYaml, triggerUp, triggerDown, pluralDomainName)
 }
 
-func generateFormFields(fields []Field) string {
+// inputClass styles the generated text, number, date and select inputs
+const inputClass = "mt-1 block w-full rounded-md border-gray-300 shadow-sm focus:border-indigo-300 focus:ring focus:ring-indigo-200 focus:ring-opacity-50"
+
+// generateFormFields writes a labelled input per field. lookupRows is the template path
+// of the lookup rows that fill reference selects; without it references take an id.
+func generateFormFields(domainName string, fields []Field, lookupRows string) string {
+	plural := naming.Pluralize(domainName)
 	formFieldsHtml := ""
 	for _, field := range fields {
+		name := field.Column()
 		inputTag := ""
 		switch field.Type {
 		case "string":
-			inputTag = fmt.Sprintf(`<input type="text" name="%s" id="%s" class="mt-1 block w-full rounded-md border-gray-300 shadow-sm focus:border-indigo-300 focus:ring focus:ring-indigo-200 focus:ring-opacity-50">`, field.Name, field.Name)
+			inputTag = fmt.Sprintf(`<input type="text" name="%s" id="%s" class="%s">`, name, name, inputClass)
 		case "text":
-			inputTag = fmt.Sprintf(`<textarea name="%s" id="%s" rows="3" class="mt-1 block w-full rounded-md border-gray-300 shadow-sm focus:border-indigo-300 focus:ring focus:ring-indigo-200 focus:ring-opacity-50"></textarea>`, field.Name, field.Name)
+			inputTag = fmt.Sprintf(`<textarea name="%s" id="%s" rows="3" class="%s"></textarea>`, name, name, inputClass)
 		case "integer":
-			inputTag = fmt.Sprintf(`<input type="number" name="%s" id="%s" class="mt-1 block w-full rounded-md border-gray-300 shadow-sm focus:border-indigo-300 focus:ring focus:ring-indigo-200 focus:ring-opacity-50">`, field.Name, field.Name)
+			inputTag = fmt.Sprintf(`<input type="number" name="%s" id="%s" class="%s">`, name, name, inputClass)
+		case "decimal":
+			inputTag = fmt.Sprintf(`<input type="number" step="any" name="%s" id="%s" class="%s">`, name, name, inputClass)
+		case "date":
+			inputTag = fmt.Sprintf(`<input type="date" name="%s" id="%s" class="%s">`, name, name, inputClass)
+		case "image":
+			inputTag = fmt.Sprintf(`<input type="file" name="%s" id="%s" accept="image/png,image/jpeg,image/webp" class="%s">`, name, name, inputClass)
 		case "boolean":
-			inputTag = fmt.Sprintf(`<input type="checkbox" name="%s" id="%s" class="rounded border-gray-300 text-indigo-600 shadow-sm focus:border-indigo-300 focus:ring focus:ring-indigo-200 focus:ring-opacity-50">`, field.Name, field.Name)
+			inputTag = fmt.Sprintf(`<input type="checkbox" name="%s" id="%s" class="rounded border-gray-300 text-indigo-600 shadow-sm focus:border-indigo-300 focus:ring focus:ring-indigo-200 focus:ring-opacity-50">`, name, name)
+		case "enum":
+			options := ""
+			for _, option := range field.Options {
+				options += fmt.Sprintf(`
+                    <option value="%s">%s</option>`, option, naming.Titleize(option))
+			}
+			inputTag = fmt.Sprintf(`<select name="%s" id="%s" class="%s">
+                    <option value="">{{t "%s.prompts.%s"}}</option>%s
+                </select>`, name, name, inputClass, plural, name, options)
+		case "references":
+			if lookupRows == "" {
+				inputTag = fmt.Sprintf(`<input type="number" name="%s" id="%s" class="%s">`, name, name, inputClass)
+				break
+			}
+			inputTag = fmt.Sprintf(`<select name="%s" id="%s" class="%s">
+                    <option value="">{{t "%s.prompts.%s"}}</option>
+                    {{#each %s}}{{#if_eq lookup "%s"}}<option value="{{value}}">{{label}}</option>{{/if_eq}}{{/each}}
+                </select>`, name, name, inputClass, plural, name, lookupRows, name)
 		default:
-			inputTag = fmt.Sprintf(`<input type="text" name="%s" id="%s" class="mt-1 block w-full rounded-md border-gray-300 shadow-sm focus:border-indigo-300 focus:ring focus:ring-indigo-200 focus:ring-opacity-50">`, field.Name, field.Name)
+			inputTag = fmt.Sprintf(`<input type="text" name="%s" id="%s" class="%s">`, name, name, inputClass)
 		}
 		formFieldsHtml += fmt.Sprintf(`
             <div>
-                <label for="%s" class="block text-sm font-medium text-gray-700">%s</label>
+                <label for="%s" class="block text-sm font-medium text-gray-700">{{t "%s.fields.%s"}}</label>
                 %s
-            </div>`, field.Name, strings.Title(field.Name), inputTag)
+            </div>`, name, plural, name, inputTag)
 	}
 	return formFieldsHtml
 }
 
-func generateSqlColumns(fields []Field) string {
+// multipartForm makes the form in an HTML template post multipart/form-data, which
+// file inputs need
+func multipartForm(html string) string {
+	return strings.Replace(html, "<form ", `<form enctype="multipart/form-data" `, 1)
+}
+
+// generateDomainConfig declares the domain's model and, with search, lists its text
+// fields as the columns ?q= matches
+func generateDomainConfig(domainName string, fields []Field, withSearch bool) string {
+	config := "# Domain configuration for " + domainName
+	if len(fields) == 0 {
+		return config
+	}
+
+	config += "\nmodels:\n  - " + naming.SnakeCase(naming.Singularize(domainName)) + ":\n"
+	var searchColumns string
+	for _, field := range fields {
+		config += fmt.Sprintf("      %s:\n        type: %s\n", field.Column(), field.modelType())
+		if field.searchable() {
+			searchColumns += "    - " + field.Column() + "\n"
+		}
+	}
+	if withSearch {
+		config += "search:\n  columns:\n" + searchColumns
+	}
+	return config
+}
+
+// generateDomainLocale writes the English text of the domain's HTML templates, keyed
+// under the plural domain name: field labels, select prompts and each page's strings
+func generateDomainLocale(domainName string, fields []Field) ([]byte, error) {
+	plural := naming.Pluralize(domainName)
+	singular := naming.Titleize(domainName)
+
+	labels := yaml.MapSlice{{Key: "id", Value: "Id"}}
+	var prompts yaml.MapSlice
+	for _, field := range fields {
+		label := naming.Titleize(strings.TrimSuffix(field.Name, "_id"))
+		labels = append(labels, yaml.MapItem{Key: field.Column(), Value: label})
+		if field.Type == "enum" || field.Type == "references" {
+			prompts = append(prompts, yaml.MapItem{Key: field.Column(), Value: "Select " + label})
+		}
+	}
+
+	page := func(items ...string) yaml.MapSlice {
+		var slice yaml.MapSlice
+		for i := 0; i < len(items); i += 2 {
+			slice = append(slice, yaml.MapItem{Key: items[i], Value: items[i+1]})
+		}
+		return slice
+	}
+	text := yaml.MapSlice{{Key: "fields", Value: labels}}
+	if len(prompts) > 0 {
+		text = append(text, yaml.MapItem{Key: "prompts", Value: prompts})
+	}
+	text = append(text, yaml.MapSlice{
+		{Key: "actions", Value: page("view", "View", "edit", "Edit", "cancel", "Cancel", "back", "Back to List")},
+		{Key: "index", Value: page(
+			"found", plural+" found",
+			"add", "Add "+singular,
+			"add_first", "Add First "+singular,
+			"empty_title", "No "+plural+" Found",
+			"empty_body", "Get started by adding your first "+singular+" to the system.",
+			"search", "Search "+naming.Titleize(plural)+"...",
+			"no_matches", "No "+plural+" match your search.",
+		)},
+		{Key: "new", Value: page("title", "New "+singular, "submit", "Create "+singular)},
+		{Key: "create", Value: page("submit", "Create")},
+		{Key: "show", Value: page("title", singular+" Details", "edit", "Edit "+singular, "delete", "Delete "+singular)},
+		{Key: "edit", Value: page("title", "Edit "+singular, "submit", "Update "+singular)},
+		{Key: "update", Value: page("title", "Edit "+singular+" #%{id}", "submit", "Update")},
+		{Key: "conflict", Value: page("title", "Someone else changed this "+singular, "yours", "Your changes", "current", "Current version")},
+		{Key: "form", Value: page("pending", "Form fields will be generated here based on the columns in your migration.")},
+		{Key: "not_found", Value: page(
+			"title", singular+" Not Found",
+			"view", "The "+singular+" you're trying to view could not be found.",
+			"edit", "The "+singular+" you're trying to edit could not be found.",
+			"back", "Back to "+plural+" List",
+		)},
+	}...)
+
+	content, err := yaml.Marshal(yaml.MapSlice{{Key: plural, Value: text}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write translations: %w", err)
+	}
+	header := "# English text for the " + plural + " pages; add e.g. fr.yml beside it to translate them\n"
+	return append([]byte(header), content...), nil
+}
+
+// generateDeleteButton renders a form that posts to the record's delete route, or
+// nothing when the domain has none
+func generateDeleteButton(domainName string, hasDelete bool) string {
+	if !hasDelete {
+		return ""
+	}
+	plural := naming.Pluralize(domainName)
+	return fmt.Sprintf(`<form action="/%[1]s/{{vm.%[1]s.[0].id}}/delete" method="post" class="flex-1">
+                    <button type="submit" class="w-full bg-gradient-to-r from-red-500 to-red-600 hover:from-red-600 hover:to-red-700 text-white px-8 py-3 rounded-xl font-semibold shadow-lg hover:shadow-xl transform hover:-translate-y-0.5 transition-all duration-200 text-center">
+                        {{t "%[1]s.show.delete"}}
+                    </button>
+                </form>`, plural)
+}
+
+// excludeDeleted leaves soft deleted rows out of the queries that read and update records
+func excludeDeleted(action, sql string) string {
+	switch action {
+	case "index":
+		return strings.Replace(sql, "{{search_where}}", ` WHERE {{not_deleted}}{{search_where prefix="AND"}}`, 1)
+	case "show", "edit", "update":
+		return strings.Replace(sql, `{{tenant_scope prefix="AND"}}`, `{{not_deleted prefix="AND"}}{{tenant_scope prefix="AND"}}`, 1)
+	}
+	return sql
+}
+
+// lockUpdate makes the update query apply only to the version the edit form was loaded
+// with, incrementing it
+func lockUpdate(sql string) string {
+	column := parser.DefaultLockColumn
+	sql = strings.Replace(sql, " WHERE id = ", fmt.Sprintf(", %[1]s = %[1]s + 1 WHERE id = ", column), 1)
+	return strings.Replace(sql, `{{tenant_scope prefix="AND"}}`, fmt.Sprintf(` AND %[1]s = :%[1]s{{tenant_scope prefix="AND"}}`, column), 1)
+}
+
+// generateLockVersionField renders the hidden input that submits the version the edit
+// form was loaded with, or nothing without locking
+func generateLockVersionField(domainName string, lockVersion bool) string {
+	if !lockVersion {
+		return ""
+	}
+	return fmt.Sprintf(`<input type="hidden" name="%[1]s" value="{{vm.%[2]s.[0].%[1]s}}">`, parser.DefaultLockColumn, naming.Pluralize(domainName))
+}
+
+// generateConflictNotice renders what an update that lost to someone else's shows above
+// the form: each submitted value beside the record's latest one
+func generateConflictNotice(domainName string, fields []Field, lockVersion bool) string {
+	if !lockVersion {
+		return ""
+	}
+	plural := naming.Pluralize(domainName)
+	rows := ""
+	for _, field := range fields {
+		rows += fmt.Sprintf(`
+                        <tr class="border-t border-amber-200">
+                            <td class="py-1 pr-4 font-medium">{{t "%[1]s.fields.%[2]s"}}</td>
+                            <td class="py-1 pr-4">{{vm.conflict.submitted.%[2]s}}</td>
+                            <td class="py-1">{{vm.%[1]s.[0].%[2]s}}</td>
+                        </tr>`, plural, field.Column())
+	}
+	return fmt.Sprintf(`{{#if vm.conflict}}
+            <div class="bg-amber-50 border border-amber-300 rounded-2xl p-6 mb-6">
+                <h2 class="font-semibold text-amber-800 mb-2">{{t "%[1]s.conflict.title"}}</h2>
+                <p class="text-sm text-amber-700 mb-4">{{vm.conflict.message}}</p>
+                <table class="w-full text-sm text-left text-amber-900">
+                    <thead>
+                        <tr>
+                            <th></th>
+                            <th class="py-1 pr-4">{{t "%[1]s.conflict.yours"}}</th>
+                            <th class="py-1">{{t "%[1]s.conflict.current"}}</th>
+                        </tr>
+                    </thead>
+                    <tbody>%[2]s
+                    </tbody>
+                </table>
+            </div>
+        {{/if}}`, plural, rows)
+}
+
+// generateSearchBox renders a search form that swaps the matching rows into the index
+// table over HTMX, or nothing without search
+func generateSearchBox(domainName string, withSearch bool) string {
+	if !withSearch {
+		return ""
+	}
+	plural := naming.Pluralize(domainName)
+	return fmt.Sprintf(`<form action="/%[1]s" method="get" class="mb-6"
+          hx-get="/%[1]s" hx-target="#%[1]s-rows" hx-swap="innerHTML"
+          hx-trigger="submit, input changed delay:300ms from:find input" hx-push-url="true">
+        <input type="search" name="q" value="{{vm.search}}" placeholder="{{t "%[1]s.index.search"}}"
+               class="w-full px-4 py-3 border border-gray-300 rounded-xl focus:ring-2 focus:ring-purple-500 focus:border-transparent">
+    </form>`, plural)
+}
+
+// tableColumns returns the columns the index table shows: id, then every field
+func tableColumns(fields []Field) []string {
+	columns := []string{"id"}
+	for _, field := range fields {
+		columns = append(columns, field.Column())
+	}
+	return columns
+}
+
+// generateTableHeaders renders a header per column that links to the index sorted by
+// it, marked with the current sort direction
+func generateTableHeaders(domainName string, fields []Field) string {
+	plural := naming.Pluralize(domainName)
+	headers := ""
+	for _, column := range tableColumns(fields) {
+		headers += fmt.Sprintf(`
+                        <th scope="col" class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
+                            <a href="{{vm.sort.links.%[1]s}}" class="hover:text-gray-700">{{t "%[2]s.fields.%[1]s"}} {{vm.sort.indicators.%[1]s}}</a>
+                        </th>`, column, plural)
+	}
+	return headers
+}
+
+// generateTableCells renders a cell per column of a record row
+func generateTableCells(fields []Field) string {
+	cells := ""
+	for _, column := range tableColumns(fields) {
+		cells += fmt.Sprintf(`
+                            <td class="px-6 py-4 whitespace-nowrap text-sm text-gray-500">{{%s}}</td>`, column)
+	}
+	return cells
+}
+
+// generateReferenceLinks links a record to the records its references fields point at
+func generateReferenceLinks(domainName string, fields []Field) string {
+	plural := naming.Pluralize(domainName)
+	links := ""
+	for _, field := range fields {
+		if field.Type != "references" {
+			continue
+		}
+		links += fmt.Sprintf(`
+                {{#if %[1]s}}<a href="/%[2]s/{{%[1]s}}" class="text-indigo-600 hover:text-indigo-900 ml-4">{{t "%[3]s.fields.%[1]s"}}</a>{{/if}}`,
+			field.Column(), field.ReferencedTable(), plural)
+	}
+	return links
+}
+
+// identifierQuotes maps each --quote-style to the character that quotes identifiers
+var identifierQuotes = map[string]string{
+	"double":   `"`,
+	"backtick": "`",
+}
+
+// quoteIdentifier quotes a column name in the given style, doubling any quote
+// characters inside it
+func quoteIdentifier(name, style string) string {
+	quote, ok := identifierQuotes[style]
+	if !ok {
+		quote = identifierQuotes["double"]
+	}
+	return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+}
+
+func generateSqlColumns(fields []Field, style string) string {
 	columns := []string{}
 	for _, field := range fields {
-		columns = append(columns, field.Name)
+		columns = append(columns, quoteIdentifier(field.Column(), style))
 	}
 	return strings.Join(columns, ", ")
 }
@@ -278,15 +882,21 @@ func generateSqlColumns(fields []Field) string {
 func generateSqlValues(fields []Field) string {
 	values := []string{}
 	for _, field := range fields {
-		values = append(values, fmt.Sprintf("{{%s}}", field.Name))
+		values = append(values, fmt.Sprintf("{{%s}}", field.Column()))
 	}
 	return strings.Join(values, ", ")
 }
 
-func generateSqlSetters(fields []Field) string {
+func generateSqlSetters(fields []Field, style string) string {
 	setters := []string{}
 	for _, field := range fields {
-		setters = append(setters, fmt.Sprintf("%s = {{%s}}", field.Name, field.Name))
+		column := quoteIdentifier(field.Column(), style)
+		if field.Type == "image" {
+			// Keep the stored image when the edit form is sent without a new file
+			setters = append(setters, fmt.Sprintf("%s = COALESCE({{%s}}, %s)", column, field.Column(), column))
+			continue
+		}
+		setters = append(setters, fmt.Sprintf("%s = {{%s}}", column, field.Column()))
 	}
 	return strings.Join(setters, ", ")
 }
@@ -0,0 +1,700 @@
+package cmd
+
+import (
+	"fulcrum/lib/database/migration"
+	"fulcrum/lib/framework"
+	"fulcrum/lib/i18n"
+	"fulcrum/lib/parser"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestGenerateSqlQuotesColumns(t *testing.T) {
+	fields := []Field{{Name: "title", Type: "string"}, {Name: "from", Type: "string"}}
+
+	tests := []struct {
+		style   string
+		columns string
+		setters string
+	}{
+		{style: "double", columns: `"title", "from"`, setters: `"title" = {{title}}, "from" = {{from}}`},
+		{style: "backtick", columns: "`title`, `from`", setters: "`title` = {{title}}, `from` = {{from}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			if columns := generateSqlColumns(fields, tt.style); columns != tt.columns {
+				t.Errorf("Expected columns %s, got %s", tt.columns, columns)
+			}
+			if setters := generateSqlSetters(fields, tt.style); setters != tt.setters {
+				t.Errorf("Expected setters %s, got %s", tt.setters, setters)
+			}
+			if values := generateSqlValues(fields); values != "{{title}}, {{from}}" {
+				t.Errorf("Expected unquoted value placeholders, got %s", values)
+			}
+		})
+	}
+}
+
+func TestQuoteIdentifierEscapesQuotes(t *testing.T) {
+	if quoted := quoteIdentifier(`odd"name`, "double"); quoted != `"odd""name"` {
+		t.Errorf("Expected embedded quotes to be doubled, got %s", quoted)
+	}
+}
+
+func TestGenerateDomainInflectsNames(t *testing.T) {
+	migration := generateMigrationContent(1, "status", nil, false, false, false)
+	for _, want := range []string{"name: create_statuses_table", "name: statuses"} {
+		if !strings.Contains(migration, want) {
+			t.Errorf("Expected migration to contain %q, got:\n%s", want, migration)
+		}
+	}
+
+	form := generateFormFields("order", []Field{{Name: "shipping_address", Type: "string"}}, "")
+	if !strings.Contains(form, `>{{t "orders.fields.shipping_address"}}</label>`) {
+		t.Errorf("Expected the label to be looked up under the plural domain name, got:\n%s", form)
+	}
+	locale, err := generateDomainLocale("order", []Field{{Name: "shipping_address", Type: "string"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(locale), "shipping_address: Shipping Address") {
+		t.Errorf("Expected a titleized label, got:\n%s", locale)
+	}
+}
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    Field
+		wantErr string
+	}{
+		{arg: "title:string", want: Field{Name: "title", Type: "string"}},
+		{arg: "user:references", want: Field{Name: "user", Type: "references"}},
+		{arg: "status:enum[draft, published,archived]", want: Field{Name: "status", Type: "enum", Options: []string{"draft", "published", "archived"}}},
+		{arg: "title", wantErr: "Expected format: name:type"},
+		{arg: "status:enum", wantErr: "expected status:enum[value,value,...]"},
+		{arg: "status:enum[]", wantErr: "expected status:enum[value,value,...]"},
+		{arg: "status:enum[draft,published", wantErr: "expected status:enum[value,value,...]"},
+		{arg: "status:enum[draft,,published]", wantErr: `value "" must be`},
+		{arg: "status:enum[it's]", wantErr: `value "it's" must be`},
+		{arg: "status:enum[draft,draft]", wantErr: `value "draft" is listed twice`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			field, err := parseField(tt.arg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(field, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, field)
+			}
+		})
+	}
+}
+
+func TestGenerateDomainFieldTypes(t *testing.T) {
+	tests := []struct {
+		arg       string
+		migration []string
+		form      []string
+	}{
+		{
+			arg: "user:references",
+			migration: []string{
+				"- name: user_id\n          type: integer",
+				"- add_foreign_key:\n      table: posts\n      column: user_id\n      referenced_table: users\n      referenced_column: id",
+			},
+			form: []string{
+				`<select name="user_id" id="user_id"`,
+				`{{#each vm.posts}}{{#if_eq lookup "user_id"}}<option value="{{value}}">{{label}}</option>{{/if_eq}}{{/each}}`,
+			},
+		},
+		{
+			arg:       "price:decimal",
+			migration: []string{"- name: price\n          type: decimal"},
+			form:      []string{`<input type="number" step="any" name="price" id="price"`},
+		},
+		{
+			arg:       "published_on:date",
+			migration: []string{"- name: published_on\n          type: date"},
+			form:      []string{`<input type="date" name="published_on" id="published_on"`},
+		},
+		{
+			arg:       "avatar:image",
+			migration: []string{"- name: avatar\n          type: varchar(255)"},
+			form:      []string{`<input type="file" name="avatar" id="avatar" accept="image/png,image/jpeg,image/webp"`},
+		},
+		{
+			arg: "status:enum[draft,published,archived]",
+			migration: []string{
+				"- name: status\n          type: varchar(255)\n          nullable: true\n          check: \"status IN ('draft', 'published', 'archived')\"",
+			},
+			form: []string{
+				`<select name="status" id="status"`,
+				`<option value="published">Published</option>`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			field, err := parseField(tt.arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			content := generateMigrationContent(1, "posts", []Field{field}, false, false, false)
+			for _, want := range tt.migration {
+				if !strings.Contains(content, want) {
+					t.Errorf("Expected migration to contain %q, got:\n%s", want, content)
+				}
+			}
+			if _, err := migration.ParseYAMLContent([]byte(content)); err != nil {
+				t.Errorf("Expected the migration to parse, got %v", err)
+			}
+
+			form := generateFormFields("posts", []Field{field}, "vm.posts")
+			for _, want := range tt.form {
+				if !strings.Contains(form, want) {
+					t.Errorf("Expected form to contain %q, got:\n%s", want, form)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateDomainReferences(t *testing.T) {
+	opts := testDomainOptions(t)
+	opts.name = "posts"
+	opts.fields = []Field{{Name: "user", Type: "references"}, {Name: "title", Type: "string"}}
+	if _, err := generateDomainFiles(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	domainPath := filepath.Join(opts.basePath, "domains", "posts")
+	expected := map[string]string{
+		"new/get.sql.hbs":                "SELECT 'user_id' AS lookup, id AS value, id AS label FROM users;",
+		"create/post.sql.hbs":            `"user_id", "title"`,
+		"[posts_id]/update/post.sql.hbs": `"user_id" = {{user_id}}`,
+		"[posts_id]/show/get.sql.hbs":    `SELECT * FROM posts WHERE id = :posts_id{{tenant_scope prefix="AND"}} LIMIT 1;`,
+		"[posts_id]/edit/get.sql.hbs":    `WHERE id = :posts_id{{tenant_scope prefix="AND"}}`,
+		"[posts_id]/edit/get.html.hbs":   `<input type="number" name="user_id" id="user_id"`,
+		"[posts_id]/show/get.html.hbs":   `{{#if user_id}}<a href="/users/{{user_id}}"`,
+		"index/get.html.hbs":             `{{#if user_id}}<a href="/users/{{user_id}}"`,
+	}
+	for file, want := range expected {
+		content, err := os.ReadFile(filepath.Join(domainPath, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", file, want, content)
+		}
+	}
+}
+
+func TestGenerateDomainUpdateRendersEdit(t *testing.T) {
+	opts := testDomainOptions(t)
+	opts.name = "posts"
+	opts.fields = []Field{{Name: "title", Type: "string"}, {Name: "cover", Type: "image"}}
+	if _, err := generateDomainFiles(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	domainPath := filepath.Join(opts.basePath, "domains", "posts", "[posts_id]")
+	read := func(file string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(domainPath, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	if edit, update := read("edit/get.html.hbs"), read("update/post.html.hbs"); update != edit {
+		t.Errorf("Expected update to render the edit form, got:\n%s", update)
+	}
+	if sql := read("update/post.sql.hbs"); !strings.Contains(sql, `UPDATE posts SET "title" = {{title}}`) {
+		t.Errorf("Expected update to keep its own SQL, got:\n%s", sql)
+	}
+	if redirect := read("update/redirect.yaml"); !strings.Contains(redirect, "to: /posts/:posts_id/show\n") {
+		t.Errorf("Expected update to redirect to the show page, got:\n%s", redirect)
+	}
+}
+
+func TestGenerateDomainSoftDelete(t *testing.T) {
+	opts := testDomainOptions(t)
+	opts.name = "posts"
+	opts.fields = []Field{{Name: "title", Type: "string"}}
+	opts.softDelete = true
+	if _, err := generateDomainFiles(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	domainPath := filepath.Join(opts.basePath, "domains", "posts")
+	read := func(file string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(domainPath, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	if migration := read("migrations/001_create_posts_table.yml"); !strings.Contains(migration, "- name: deleted_at\n          type: timestamp\n          nullable: true") {
+		t.Errorf("Expected a nullable deleted_at column, got:\n%s", migration)
+	}
+	for file, expected := range map[string]string{
+		"index/get.sql.hbs":                `SELECT * FROM posts WHERE {{not_deleted}}{{search_where prefix="AND"}}{{order_by}};`,
+		"[posts_id]/show/get.sql.hbs":      `WHERE id = :posts_id{{not_deleted prefix="AND"}}{{tenant_scope prefix="AND"}} LIMIT 1`,
+		"[posts_id]/update/post.sql.hbs":   `{{not_deleted prefix="AND"}}`,
+		"[posts_id]/delete/post.sql.hbs":   "UPDATE posts SET deleted_at = CURRENT_TIMESTAMP WHERE id = :posts_id",
+		"[posts_id]/delete/redirect.yaml":  "to: /posts\n",
+		"[posts_id]/restore/post.sql.hbs":  "UPDATE posts SET deleted_at = NULL WHERE id = :posts_id",
+		"[posts_id]/restore/redirect.yaml": "to: /posts/:posts_id/show\n",
+		"[posts_id]/show/get.html.hbs":     `action="/posts/{{vm.posts.[0].id}}/delete"`,
+	} {
+		if content := read(file); !strings.Contains(content, expected) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", file, expected, content)
+		}
+	}
+
+	plain := testDomainOptions(t)
+	if _, err := generateDomainFiles(plain); err != nil {
+		t.Fatal(err)
+	}
+	plainPath := filepath.Join(plain.basePath, "domains", "users")
+	if _, err := os.Stat(filepath.Join(plainPath, "[users_id]", "delete")); !os.IsNotExist(err) {
+		t.Errorf("Expected no delete route without --soft-delete, got %v", err)
+	}
+	show, err := os.ReadFile(filepath.Join(plainPath, "[users_id]", "show", "get.html.hbs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(show), "delete") || strings.Contains(string(show), "PLACEHOLDER") {
+		t.Errorf("Expected no delete button without --soft-delete, got:\n%s", show)
+	}
+}
+
+func TestGenerateDomainRedirectsResolve(t *testing.T) {
+	opts := testDomainOptions(t)
+	opts.name = "posts"
+	opts.fields = []Field{{Name: "title", Type: "string"}}
+	opts.softDelete = true
+	if _, err := generateDomainFiles(opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(opts.basePath, parser.DomainConfigFileName), []byte("name: blog\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	appConfig, err := parser.GetAppConfig(opts.basePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := framework.BuildRouteTable(&appConfig)
+
+	for _, action := range []string{"create", "[posts_id]/update", "[posts_id]/delete", "[posts_id]/restore"} {
+		t.Run(action, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join(opts.basePath, "domains", "posts", action, "redirect.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var redirect parser.RedirectRule
+			if err := yaml.Unmarshal(content, &redirect); err != nil {
+				t.Fatal(err)
+			}
+
+			target := strings.ReplaceAll(redirect.To, ":posts_id", "1")
+			if entry, _ := table.Match(&appConfig, http.MethodGet, target); entry == nil || entry.HTMLTemplate == "" {
+				t.Errorf("Expected GET %s to be a page, got %+v", target, entry)
+			}
+		})
+	}
+}
+
+func TestGenerateDomainLockVersion(t *testing.T) {
+	opts := testDomainOptions(t)
+	opts.name = "posts"
+	opts.fields = []Field{{Name: "title", Type: "string"}}
+	opts.lockVersion = true
+	if _, err := generateDomainFiles(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	domainPath := filepath.Join(opts.basePath, "domains", "posts")
+	read := func(file string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(domainPath, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	for file, expected := range map[string]string{
+		"migrations/001_create_posts_table.yml": "- name: lock_version\n          type: integer\n          nullable: false\n          default: 0",
+		"[posts_id]/update/post.sql.hbs":        "lock_version = lock_version + 1 WHERE id = :posts_id AND lock_version = :lock_version",
+		"[posts_id]/edit/get.html.hbs":          `<input type="hidden" name="lock_version" value="{{vm.posts.[0].lock_version}}">`,
+	} {
+		if content := read(file); !strings.Contains(content, expected) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", file, expected, content)
+		}
+	}
+	edit := read("[posts_id]/edit/get.html.hbs")
+	for _, expected := range []string{"{{#if vm.conflict}}", "{{vm.conflict.submitted.title}}", "{{vm.posts.[0].title}}", `{{t "posts.conflict.title"}}`} {
+		if !strings.Contains(edit, expected) {
+			t.Errorf("Expected the edit form to contain %q, got:\n%s", expected, edit)
+		}
+	}
+
+	plain := testDomainOptions(t)
+	if _, err := generateDomainFiles(plain); err != nil {
+		t.Fatal(err)
+	}
+	plainPath := filepath.Join(plain.basePath, "domains", "users")
+	for _, file := range []string{"[users_id]/edit/get.html.hbs", "[users_id]/update/post.sql.hbs"} {
+		content, err := os.ReadFile(filepath.Join(plainPath, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(content), "lock_version") || strings.Contains(string(content), "PLACEHOLDER") {
+			t.Errorf("Expected no locking in %s without --lock-version, got:\n%s", file, content)
+		}
+	}
+}
+
+func TestGenerateDomainSearch(t *testing.T) {
+	tests := []struct {
+		name       string
+		fields     []Field
+		withSearch bool
+		apiOnly    bool
+		columns    []string
+		wantErr    bool
+	}{
+		{
+			name:       "text fields are searchable",
+			fields:     []Field{{Name: "title", Type: "string"}, {Name: "status", Type: "enum", Options: []string{"draft"}}, {Name: "user", Type: "references"}},
+			withSearch: true,
+			columns:    []string{"title", "status"},
+		},
+		{
+			name:   "without search",
+			fields: []Field{{Name: "title", Type: "string"}},
+		},
+		{
+			name:       "nothing to search",
+			fields:     []Field{{Name: "user", Type: "references"}, {Name: "views", Type: "integer"}},
+			withSearch: true,
+			wantErr:    true,
+		},
+		{
+			name:       "api only",
+			fields:     []Field{{Name: "title", Type: "string"}},
+			withSearch: true,
+			apiOnly:    true,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testDomainOptions(t)
+			opts.name = "posts"
+			opts.fields = tt.fields
+			opts.withSearch = tt.withSearch
+			opts.apiOnly = tt.apiOnly
+			_, err := generateDomainFiles(opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			domainPath := filepath.Join(opts.basePath, "domains", "posts")
+
+			content, err := os.ReadFile(filepath.Join(domainPath, "fulcrum.yml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var domain parser.DomainConfig
+			if err := yaml.UnmarshalStrict(content, &domain); err != nil {
+				t.Fatalf("Expected fulcrum.yml to parse, got %v:\n%s", err, content)
+			}
+			if !reflect.DeepEqual(domain.Search.Columns, tt.columns) {
+				t.Errorf("Expected search columns %v, got %v", tt.columns, domain.Search.Columns)
+			}
+			for _, field := range tt.fields {
+				if !domain.HasField(field.Column()) {
+					t.Errorf("Expected the post model to declare %s", field.Column())
+				}
+			}
+
+			sql, err := os.ReadFile(filepath.Join(domainPath, "index", "get.sql.hbs"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(sql), "SELECT * FROM posts{{search_where}}{{order_by}};") {
+				t.Errorf("Expected the index SQL to render search conditions and sort order, got %s", sql)
+			}
+
+			html, err := os.ReadFile(filepath.Join(domainPath, "index", "get.html.hbs"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(html), `<tbody id="posts-rows"`) {
+				t.Errorf("Expected the index table body to be #posts-rows")
+			}
+			if hasBox := strings.Contains(string(html), `hx-get="/posts" hx-target="#posts-rows"`); hasBox != tt.withSearch {
+				t.Errorf("Expected search box %v, got %v", tt.withSearch, hasBox)
+			}
+			if strings.Contains(string(html), "SEARCH_PLACEHOLDER") {
+				t.Errorf("Expected the search placeholder to be replaced")
+			}
+
+			rows, err := os.ReadFile(filepath.Join(domainPath, "index", "get.htmx.hbs"))
+			if !tt.withSearch {
+				if !os.IsNotExist(err) {
+					t.Errorf("Expected no HTMX partial without search, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range []string{"{{#each vm.posts}}", "{{title}}</td>", `{{#if user_id}}<a href="/users/{{user_id}}"`, `colspan="5"`} {
+				if !strings.Contains(string(rows), want) {
+					t.Errorf("Expected the HTMX partial to contain %q, got:\n%s", want, rows)
+				}
+			}
+			if strings.Contains(string(rows), "<html") || strings.Contains(string(rows), "<!--") {
+				t.Errorf("Expected the HTMX partial to hold only rows, got:\n%s", rows)
+			}
+		})
+	}
+}
+
+func TestGenerateDomainSortHeaders(t *testing.T) {
+	opts := testDomainOptions(t)
+	opts.name = "posts"
+	opts.fields = []Field{{Name: "title", Type: "string"}, {Name: "user", Type: "references"}}
+	if _, err := generateDomainFiles(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	html, err := os.ReadFile(filepath.Join(opts.basePath, "domains", "posts", "index", "get.html.hbs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, column := range []string{"id", "title", "user_id"} {
+		for _, want := range []string{
+			`<a href="{{vm.sort.links.` + column + `}}"`,
+			`{{vm.sort.indicators.` + column + `}}`,
+			`<td class="px-6 py-4 whitespace-nowrap text-sm text-gray-500">{{` + column + `}}</td>`,
+		} {
+			if !strings.Contains(string(html), want) {
+				t.Errorf("Expected the index page to contain %q", want)
+			}
+		}
+	}
+	if strings.Contains(string(html), "PLACEHOLDER -->") {
+		t.Errorf("Expected every placeholder to be replaced, got:\n%s", html)
+	}
+}
+
+func TestGenerateDomainLocale(t *testing.T) {
+	opts := testDomainOptions(t)
+	opts.name = "posts"
+	opts.fields = []Field{{Name: "title", Type: "string"}, {Name: "status", Type: "enum", Options: []string{"draft"}}, {Name: "user", Type: "references"}}
+	opts.withSearch = true
+	if _, err := generateDomainFiles(opts); err != nil {
+		t.Fatal(err)
+	}
+	domainPath := filepath.Join(opts.basePath, "domains", "posts")
+
+	catalog := i18n.NewCatalog("en")
+	if err := catalog.LoadDir(filepath.Join(domainPath, i18n.LocaleDir)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every key the generated templates look up is in the generated en.yml
+	keyPattern := regexp.MustCompile(`\{\{t "([^"]+)"`)
+	keys := 0
+	err := filepath.WalkDir(domainPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !strings.HasSuffix(path, ".hbs") {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range keyPattern.FindAllStringSubmatch(string(content), -1) {
+			keys++
+			if got := catalog.Translate("en", match[1], nil); got == match[1] {
+				t.Errorf("Expected %s to be translated for %s", match[1], path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys == 0 {
+		t.Fatal("Expected the generated templates to use the t helper")
+	}
+
+	for key, expected := range map[string]string{
+		"posts.fields.user_id":   "User",
+		"posts.prompts.status":   "Select Status",
+		"posts.update.title":     "Edit Posts #7",
+		"posts.index.no_matches": "No posts match your search.",
+	} {
+		if got := catalog.Translate("en", key, map[string]any{"id": 7}); got != expected {
+			t.Errorf("Expected %s to be %q, got %q", key, expected, got)
+		}
+	}
+
+	apiOpts := testDomainOptions(t)
+	apiOpts.apiOnly = true
+	if _, err := generateDomainFiles(apiOpts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(apiOpts.basePath, "domains", "users", i18n.LocaleDir)); !os.IsNotExist(err) {
+		t.Errorf("Expected no translations for an API-only domain, got %v", err)
+	}
+}
+
+// testDomainOptions generates a users domain in a temp project from the embedded templates
+func testDomainOptions(t *testing.T) domainOptions {
+	t.Helper()
+	return domainOptions{
+		basePath:   t.TempDir(),
+		templates:  domainTemplates(),
+		name:       "users",
+		fields:     []Field{{Name: "email", Type: "string"}},
+		quoteStyle: "double",
+	}
+}
+
+func TestGenerateDomainKeepsExistingFiles(t *testing.T) {
+	opts := testDomainOptions(t)
+	if _, err := generateDomainFiles(opts); err != nil {
+		t.Fatalf("First run failed: %v", err)
+	}
+
+	indexPath := filepath.Join(opts.basePath, "domains", "users", "index", "get.html.hbs")
+	if err := os.WriteFile(indexPath, []byte("customized"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := generateDomainFiles(opts)
+	if err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+	if files.skipped() != len(files.results) {
+		t.Errorf("Expected the second run to skip every file, got %+v", files.results)
+	}
+	if content, _ := os.ReadFile(indexPath); string(content) != "customized" {
+		t.Errorf("Expected the customized template to be kept, got %q", content)
+	}
+
+	migrations, _ := filepath.Glob(filepath.Join(opts.basePath, "domains", "users", "migrations", "*.yml"))
+	if len(migrations) != 1 || filepath.Base(migrations[0]) != "001_create_users_table.yml" {
+		t.Errorf("Expected the create migration not to be regenerated, got %v", migrations)
+	}
+
+	opts.force = true
+	files, err = generateDomainFiles(opts)
+	if err != nil {
+		t.Fatalf("Forced run failed: %v", err)
+	}
+	if files.skipped() != 0 {
+		t.Errorf("Expected --force to overwrite every file, got %+v", files.results)
+	}
+	if content, _ := os.ReadFile(indexPath); string(content) == "customized" {
+		t.Error("Expected --force to overwrite the customized template")
+	}
+	migrations, _ = filepath.Glob(filepath.Join(opts.basePath, "domains", "users", "migrations", "*.yml"))
+	if len(migrations) != 1 {
+		t.Errorf("Expected --force to rewrite the existing migration in place, got %v", migrations)
+	}
+}
+
+func TestGenerateDomainSelectsActions(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiOnly bool
+		only    []string
+		skip    []string
+		want    []string // Route files relative to the domain
+		wantErr bool
+	}{
+		{
+			name: "only",
+			only: []string{"index", "show"},
+			want: []string{"index/get.html.hbs", "index/get.sql.hbs", "[users_id]/show/get.html.hbs", "[users_id]/show/get.sql.hbs"},
+		},
+		{
+			name: "skip",
+			skip: []string{"new", "create", "edit", "update"},
+			want: []string{"index/get.html.hbs", "index/get.sql.hbs", "[users_id]/show/get.html.hbs", "[users_id]/show/get.sql.hbs"},
+		},
+		{
+			name:    "api",
+			apiOnly: true,
+			want:    []string{"index/get.json.hbs", "index/get.sql.hbs", "create/post.json.hbs", "create/post.sql.hbs"},
+		},
+		{name: "unknown action", only: []string{"destroy"}, wantErr: true},
+		{name: "nothing left", only: []string{"index"}, skip: []string{"index"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testDomainOptions(t)
+			opts.apiOnly, opts.only, opts.skip = tt.apiOnly, tt.only, tt.skip
+
+			_, err := generateDomainFiles(opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			domainPath := filepath.Join(opts.basePath, "domains", "users")
+			var routes []string
+			filepath.WalkDir(domainPath, func(path string, d fs.DirEntry, err error) error {
+				rel, _ := filepath.Rel(domainPath, path)
+				if !d.IsDir() && strings.HasSuffix(rel, ".hbs") {
+					routes = append(routes, filepath.ToSlash(rel))
+				}
+				return nil
+			})
+
+			sort.Strings(routes)
+			want := append([]string{}, tt.want...)
+			sort.Strings(want)
+			if !reflect.DeepEqual(routes, want) {
+				t.Errorf("Expected route files %v, got %v", want, routes)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// handlerPackage is the npm package that runs JavaScript handlers
+const handlerPackage = "@fulcrum/js"
+
+// writeDomainHandler writes domains/<name>/handler.js and, when the project has no
+// package.json, the package.json and index.js the process manager starts handlers with
+func writeDomainHandler(basePath, domainName string) error {
+	handlerPath := filepath.Join(basePath, "domains", domainName, "handler.js")
+	if _, err := os.Stat(handlerPath); err == nil {
+		return fmt.Errorf("%s already exists", handlerPath)
+	}
+	if err := os.WriteFile(handlerPath, []byte(domainHandlerContent(domainName)), 0644); err != nil {
+		return fmt.Errorf("failed to write handler.js: %w", err)
+	}
+	fmt.Printf("✅ Created handler: %s\n", handlerPath)
+
+	packagePath := filepath.Join(basePath, "package.json")
+	if _, err := os.Stat(packagePath); err == nil {
+		return nil
+	}
+
+	content, err := handlerPackageJSON(filepath.Base(basePath))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(packagePath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write package.json: %w", err)
+	}
+	fmt.Printf("✅ Created package.json: %s\n", packagePath)
+
+	indexPath := filepath.Join(basePath, "index.js")
+	if _, err := os.Stat(indexPath); err == nil {
+		return nil
+	}
+	if err := os.WriteFile(indexPath, []byte(handlerIndexContent), 0644); err != nil {
+		return fmt.Errorf("failed to write index.js: %w", err)
+	}
+	fmt.Printf("✅ Created index.js: %s\n", indexPath)
+	fmt.Println("💡 Run 'npm install' before starting the server so the handler service can start")
+	return nil
+}
+
+// handlerPackageJSON returns a package.json that installs the handler runtime
+func handlerPackageJSON(name string) ([]byte, error) {
+	pkg := map[string]any{
+		"name":    strings.ToLower(strings.ReplaceAll(name, " ", "-")),
+		"version": "1.0.0",
+		"private": true,
+		"main":    "index.js",
+		"scripts": map[string]string{
+			"start": "node index.js",
+		},
+		"dependencies": map[string]string{
+			handlerPackage: "^1.0.0",
+		},
+	}
+
+	content, err := json.MarshalIndent(pkg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode package.json: %w", err)
+	}
+	return append(content, '\n'), nil
+}
+
+// domainHandlerContent returns a handler.js skeleton for the generated domain routes
+func domainHandlerContent(domainName string) string {
+	return fmt.Sprintf(`// Handlers for the %[1]s domain.
+//
+// Each route calls the export named after its action with the rows returned by the
+// route's SQL template and the request data (path, query and form values, plus
+// _method and _path). Whatever a handler returns is what the template renders, so
+// these skeletons pass the SQL data through untouched, as do routes without an export
+// here (new, edit).
+
+module.exports = {
+  // GET /%[1]s
+  async index(sqlData, requestData) {
+    return sqlData;
+  },
+
+  // GET /%[1]s/[%[1]s_id]/show
+  async show(sqlData, requestData) {
+    return sqlData;
+  },
+
+  // POST /%[1]s/create
+  async create(sqlData, requestData) {
+    // Return a redirect instead of rendering:
+    // return { _redirect: { url: '/%[1]s', status: 303 } };
+    return sqlData;
+  },
+
+  // POST /%[1]s/[%[1]s_id]/update
+  async update(sqlData, requestData) {
+    return sqlData;
+  },
+
+  // DELETE /%[1]s/[%[1]s_id]
+  async delete(sqlData, requestData) {
+    return sqlData;
+  },
+};
+`, domainName)
+}
+
+// handlerIndexContent is the entry point the process manager runs with node when the
+// fulcrum-js CLI is not installed; it passes HANDLER_PORT and HANDLERS_PATH
+const handlerIndexContent = `const { FulcrumJS } = require('@fulcrum/js');
+
+const handlerService = new FulcrumJS({
+  port: Number(process.env.HANDLER_PORT) || 50052,
+  handlersPath: process.env.HANDLERS_PATH || './domains',
+  hotReload: true,
+  verbose: process.env.VERBOSE === 'true',
+});
+
+handlerService.initialize()
+  .then(() => handlerService.start())
+  .then(() => handlerService.setupGracefulShutdown())
+  .catch((error) => {
+    console.error('Failed to start handler service:', error);
+    process.exit(1);
+  });
+`
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteDomainHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		packageJSON string // Existing package.json, if any
+		wantIndex   bool
+	}{
+		{name: "new project", wantIndex: true},
+		{name: "existing package.json", packageJSON: `{"name": "mine"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			basePath := filepath.Join(t.TempDir(), "shop")
+			if err := os.MkdirAll(filepath.Join(basePath, "domains", "orders"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if tt.packageJSON != "" {
+				if err := os.WriteFile(filepath.Join(basePath, "package.json"), []byte(tt.packageJSON), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := writeDomainHandler(basePath, "orders"); err != nil {
+				t.Fatalf("writeDomainHandler failed: %v", err)
+			}
+
+			handler, err := os.ReadFile(filepath.Join(basePath, "domains", "orders", "handler.js"))
+			if err != nil {
+				t.Fatalf("Expected handler.js: %v", err)
+			}
+			for _, action := range []string{"index", "show", "create", "update", "delete"} {
+				if !strings.Contains(string(handler), "async "+action+"(sqlData, requestData) {") {
+					t.Errorf("Expected handler.js to export %s, got:\n%s", action, handler)
+				}
+			}
+			if !strings.Contains(string(handler), "_redirect") {
+				t.Errorf("Expected handler.js to show a redirect example")
+			}
+
+			packageContent, err := os.ReadFile(filepath.Join(basePath, "package.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.packageJSON != "" {
+				if string(packageContent) != tt.packageJSON {
+					t.Errorf("Expected the existing package.json to be kept, got %s", packageContent)
+				}
+			} else {
+				var pkg struct {
+					Name         string            `json:"name"`
+					Main         string            `json:"main"`
+					Dependencies map[string]string `json:"dependencies"`
+				}
+				if err := json.Unmarshal(packageContent, &pkg); err != nil {
+					t.Fatalf("Invalid package.json: %v", err)
+				}
+				if pkg.Name != "shop" || pkg.Main != "index.js" || pkg.Dependencies[handlerPackage] == "" {
+					t.Errorf("Expected package.json to depend on %s with an index.js entry point, got %+v", handlerPackage, pkg)
+				}
+			}
+
+			_, err = os.Stat(filepath.Join(basePath, "index.js"))
+			if exists := err == nil; exists != tt.wantIndex {
+				t.Errorf("Expected index.js to exist: %v, got %v", tt.wantIndex, exists)
+			}
+
+			if err := writeDomainHandler(basePath, "orders"); err == nil {
+				t.Error("Expected an error when handler.js already exists")
+			}
+		})
+	}
+}
@@ -2,11 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"path/filepath"
-	"runtime"
+
+	"fulcrum/lib/views"
 
 	"github.com/spf13/cobra"
 )
@@ -19,12 +19,44 @@ var generateProjectCmd = &cobra.Command{
 
 Usage:
   fulcrum generate project my-new-app
+  fulcrum generate project my-new-app --db sqlite
+  fulcrum generate project my-api --db sqlite --no-auth
+  fulcrum generate project my-new-app --template-engine gotemplate
+
+This will create a new directory with the specified name and populate it with the example project structure.
+
+--db picks the database (postgres, sqlite or mysql); sqlite stores data in
+data/app.db so no database server is needed. --no-auth leaves out the auth
+domain, serves every route without login and uses a home domain as root.
 
-This will create a new directory with the specified name and populate it with the example project structure.`,
+--template-engine gotemplate writes the layout and pages as .tmpl files rendered
+with Go's html/template, and sets views.engine: gotemplate in fulcrum.yml. SQL
+templates, the feed and the auth domain's pages stay Handlebars.`,
 	Args: cobra.ExactArgs(1),
 	Run:  runGenerateProject,
 }
 
+var (
+	projectDB             string
+	projectNoAuth         bool
+	projectTemplateEngine string
+)
+
+func init() {
+	generateProjectCmd.Flags().StringVar(&projectDB, "db", "postgres", "Database driver: postgres, sqlite or mysql")
+	generateProjectCmd.Flags().BoolVar(&projectNoAuth, "no-auth", false, "Skip the auth domain and use a home domain as root")
+	generateProjectCmd.Flags().StringVar(&projectTemplateEngine, "template-engine", views.EngineHandlebars, "Engine of the HTML templates: handlebars or gotemplate")
+}
+
+// projectOptions selects what generateProject writes
+type projectOptions struct {
+	DB     string // postgres, sqlite or mysql
+	NoAuth bool   // Skip the auth domain and serve a home domain as root
+
+	// TemplateEngine is handlebars or gotemplate, which writes .tmpl HTML templates
+	TemplateEngine string
+}
+
 func runGenerateProject(cmd *cobra.Command, args []string) {
 	projectName := args[0]
 
@@ -37,54 +69,119 @@ func runGenerateProject(cmd *cobra.Command, args []string) {
 	// Path to the new project
 	newProjectPath := filepath.Join(cwd, projectName)
 
+	opts := projectOptions{DB: projectDB, NoAuth: projectNoAuth, TemplateEngine: projectTemplateEngine}
+	if err := generateProject(newProjectPath, opts); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	fmt.Printf("✅ Created project: %s\n", newProjectPath)
+	fmt.Printf("✅ Configured database driver: %s\n", opts.DB)
+	fmt.Printf("✅ Created %s layout\n", opts.layoutFile())
+	if opts.NoAuth {
+		fmt.Printf("✅ Created home domain at /home (routes don't require login)\n")
+	} else {
+		fmt.Printf("✅ Created auth domain with login, register, dashboard templates\n")
+		fmt.Printf("\n💡 Auth templates can be customized in domains/auth/\n")
+	}
+
+	fmt.Printf("\nNext steps:\n")
+	fmt.Printf("  cd %s\n", projectName)
+	switch opts.DB {
+	case "sqlite":
+		fmt.Printf("  # No database server needed: data is stored in data/app.db\n")
+	case "mysql":
+		fmt.Printf("  # Start MySQL on localhost:3306 with a fulcrum_dev database, or set DATABASE_URL\n")
+		fmt.Printf("  export DATABASE_PASSWORD=...\n")
+	default:
+		fmt.Printf("  # Start PostgreSQL on localhost:5432 with a fulcrum_dev database, or set DATABASE_URL\n")
+		fmt.Printf("  export DATABASE_PASSWORD=...\n")
+	}
+	if !opts.NoAuth {
+		fmt.Printf("  fulcrum migrate up\n")
+	}
+	fmt.Printf("  fulcrum serve --dev\n")
+}
+
+// generateProject writes a new project into newProjectPath
+func generateProject(newProjectPath string, opts projectOptions) error {
+	if opts.DB == "" || opts.DB == "postgresql" {
+		opts.DB = "postgres"
+	}
+	dbConfig, ok := projectDBConfigs[opts.DB]
+	if !ok {
+		return fmt.Errorf("unsupported --db %q: use postgres, sqlite or mysql", opts.DB)
+	}
+	switch opts.TemplateEngine {
+	case "":
+		opts.TemplateEngine = views.EngineHandlebars
+	case views.EngineHandlebars, views.EngineGoTemplate:
+	default:
+		return fmt.Errorf("unsupported --template-engine %q: use handlebars or gotemplate", opts.TemplateEngine)
+	}
+
 	// Check if the new project directory already exists
 	if _, err := os.Stat(newProjectPath); !os.IsNotExist(err) {
-		log.Fatalf("Directory '%s' already exists.", newProjectPath)
+		return fmt.Errorf("directory '%s' already exists", newProjectPath)
 	}
 
 	// Create the new project directory
 	if err := os.MkdirAll(newProjectPath, 0755); err != nil {
-		log.Fatalf("Failed to create project directory: %v", err)
+		return fmt.Errorf("failed to create project directory: %w", err)
 	}
 
 	// Create the basic directory structure
 	dirs := []string{
 		"domains",
-		"domains/auth/login",
-		"domains/auth/register",
-		"domains/auth/dashboard",
-		"domains/auth/migrations",
-		"domains/auth/tenant/new",
+		"domains/feed",
 		"shared/views/layouts",
 	}
+	if opts.NoAuth {
+		dirs = append(dirs, "domains/home/index")
+	} else {
+		dirs = append(dirs,
+			"domains/auth/login",
+			"domains/auth/register",
+			"domains/auth/dashboard",
+			"domains/auth/migrations",
+			"domains/auth/tenant/new",
+		)
+	}
+	if opts.DB == "sqlite" {
+		dirs = append(dirs, "data")
+	}
 	for _, dir := range dirs {
 		if err := os.MkdirAll(filepath.Join(newProjectPath, dir), 0755); err != nil {
-			log.Fatalf("Failed to create directory: %v", err)
+			return fmt.Errorf("failed to create directory: %w", err)
 		}
 	}
 
 	// Create the fulcrum.yml file
-	fulcrumYmlPath := filepath.Join(newProjectPath, "fulcrum.yml")
-	fulcrumYmlContent := `db:
-  driver: postgresql
-  host: localhost
-  port: 5432
-  database: fulcrum_dev
-  username: fulcrum
-  password: fulcrum_pass
-  ssl_mode: disable
-  max_open_conns: 25
-  max_idle_conns: 10
-  conn_max_lifetime_minutes: 5
+	root := "root: /auth/dashboard\n"
+	if opts.NoAuth {
+		root = `root: /home
 
-root: /auth/dashboard
+# Routes are served without login; add the auth domain and remove this to require it
+auth:
+  disabled: true
+`
+	}
+	fulcrumYmlPath := filepath.Join(newProjectPath, "fulcrum.yml")
+	fulcrumYmlContent := `# ${VAR} and ${VAR:-default} are read from the environment. Settings for
+# one environment go in fulcrum.<env>.yml, selected by FULCRUM_ENV or --env.
+` + dbConfig + "\n" + root
+	if opts.TemplateEngine == views.EngineGoTemplate {
+		fulcrumYmlContent += `
+# HTML templates are .tmpl files rendered with Go's html/template
+views:
+  engine: gotemplate
 `
+	}
 	if err := os.WriteFile(fulcrumYmlPath, []byte(fulcrumYmlContent), 0644); err != nil {
-		log.Fatalf("Failed to write fulcrum.yml: %v", err)
+		return fmt.Errorf("failed to write fulcrum.yml: %w", err)
 	}
 
-	// Create the main.hbs layout
-	mainHbsPath := filepath.Join(newProjectPath, "shared", "views", "layouts", "main.hbs")
+	// Create the main layout
+	mainHbsPath := filepath.Join(newProjectPath, "shared", "views", "layouts", opts.layoutFile())
 	mainHbsContent := `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -220,33 +317,233 @@ root: /auth/dashboard
     </script>
 </body>
 </html>`
+	if opts.TemplateEngine == views.EngineGoTemplate {
+		mainHbsContent = goTemplateLayoutContent
+	}
 	if err := os.WriteFile(mainHbsPath, []byte(mainHbsContent), 0644); err != nil {
-		log.Fatalf("Failed to write main.hbs: %v", err)
+		return fmt.Errorf("failed to write %s: %w", opts.layoutFile(), err)
+	}
+
+	// Create the example RSS feed, served at /feed and /feed.xml
+	feedPath := filepath.Join(newProjectPath, "domains", "feed", "get.xml.hbs")
+	if err := os.WriteFile(feedPath, []byte(feedContent), 0644); err != nil {
+		return fmt.Errorf("failed to write feed: %w", err)
+	}
+
+	if opts.NoAuth {
+		homeFile := "get.html.hbs"
+		if opts.TemplateEngine == views.EngineGoTemplate {
+			homeFile = "get.html.tmpl"
+		}
+		homePath := filepath.Join(newProjectPath, "domains", "home", "index", homeFile)
+		if err := os.WriteFile(homePath, []byte(fmt.Sprintf(homePageContent, homeFile)), 0644); err != nil {
+			return fmt.Errorf("failed to write home page: %w", err)
+		}
+		return nil
 	}
 
 	// Create auth domain templates (these can be overridden by users)
 	createAuthDomainFiles(newProjectPath)
+	return nil
+}
 
-	fmt.Printf("✅ Created project: %s\n", newProjectPath)
-	fmt.Printf("✅ Configured database driver: postgresql\n")
-	fmt.Printf("✅ Created main.hbs layout\n")
-	fmt.Printf("✅ Created auth domain with login, register, dashboard templates\n")
-	fmt.Printf("\n💡 Auth templates can be customized in domains/auth/\n")
-	fmt.Printf("💡 Run migrations with: fulcrum migrate up\n")
+// projectDBConfigs are the db: blocks generate project writes for each --db
+var projectDBConfigs = map[string]string{
+	"postgres": `db:
+  # DATABASE_URL replaces url when set; host, password, etc. given here would override it
+  url: postgres://fulcrum:${DATABASE_PASSWORD:-fulcrum_pass}@localhost:5432/fulcrum_dev?sslmode=disable
+  max_open_conns: 25
+  max_idle_conns: 10
+  conn_max_lifetime_minutes: 5
+`,
+	"mysql": `db:
+  # DATABASE_URL replaces url when set; host, password, etc. given here would override it
+  url: mysql://fulcrum:${DATABASE_PASSWORD:-fulcrum_pass}@localhost:3306/fulcrum_dev
+  max_open_conns: 25
+  max_idle_conns: 10
+  conn_max_lifetime_minutes: 5
+`,
+	"sqlite": `db:
+  driver: sqlite
+  file_path: ./data/app.db # Relative to this file
+`,
 }
 
-// createAuthDomainFiles creates the auth domain files by copying from lib/views/auth
-func createAuthDomainFiles(projectPath string) {
-	// Get the path to the fulcrum executable to find lib/views/auth
-	_, filename, _, ok := runtime.Caller(0)
-	if !ok {
-		log.Fatalf("Failed to get runtime caller info")
+// layoutFile names the main layout of the project's template engine
+func (opts projectOptions) layoutFile() string {
+	if opts.TemplateEngine == views.EngineGoTemplate {
+		return "main.tmpl"
 	}
+	return "main.hbs"
+}
 
-	// Navigate from cmd/generate_project.go to lib/views/auth
-	fulcrumRoot := filepath.Dir(filepath.Dir(filename)) // Go up two levels from cmd/
-	libAuthPath := filepath.Join(fulcrumRoot, "lib", "views", "auth")
+// homePageContent is the root page of projects generated with --no-auth, formatted
+// with the name of its file
+const homePageContent = `<div class="max-w-3xl mx-auto px-6 py-16 text-center">
+    <h1 class="text-4xl font-bold text-gray-900 mb-4">Welcome to Fulcrum</h1>
+    <p class="text-gray-600">Edit domains/home/index/%s to change this page.</p>
+</div>
+`
+
+// feedContent is the example XML route of generated projects. Its items come from a
+// get.sql.hbs next to it, returning title, link and description columns.
+const feedContent = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Fulcrum</title>
+    <link>http://localhost:8080/</link>
+    <description>Add domains/feed/get.sql.hbs to list items in this feed.</description>
+    {{#each vm.feed}}
+    <item>
+      <title>{{title}}</title>
+      <link>{{link}}</link>
+      <description>{{description}}</description>
+    </item>
+    {{/each}}
+  </channel>
+</rss>
+`
 
+// goTemplateLayoutContent is the main layout of projects generated with
+// --template-engine gotemplate, rendered with html/template
+const goTemplateLayoutContent = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{if .pageTitle}}{{.pageTitle}} - {{end}}Fulcrum</title>
+    <script src="https://unpkg.com/htmx.org@1.9.10"></script>
+    <script src="https://cdn.tailwindcss.com"></script>
+    {{if .additionalCSS}}{{.additionalCSS}}{{end}}
+</head>
+<body class="min-h-screen bg-gradient-to-br from-purple-50 via-pink-50 to-indigo-50">
+    <!-- Header -->
+    <header class="bg-white/90 backdrop-blur-sm border-b border-purple-200/50 shadow-lg sticky top-0 z-50">
+        <div class="max-w-7xl mx-auto px-6 py-4">
+            <div class="flex items-center justify-between">
+                <a href="/" class="text-3xl font-bold bg-gradient-to-r from-purple-600 via-pink-600 to-indigo-600 bg-clip-text text-transparent hover:scale-105 transition-transform duration-200">
+                    Fulcrum
+                </a>
+                
+                {{if .navigation}}
+                <nav class="hidden md:flex space-x-8">
+                    {{range .navigation}}
+                    <a href="{{.url}}" class="text-gray-700 hover:text-purple-600 font-medium transition-colors duration-200 relative group">
+                        {{.label}}
+                        <span class="absolute -bottom-1 left-0 w-0 h-0.5 bg-gradient-to-r from-purple-500 to-pink-500 group-hover:w-full transition-all duration-300"></span>
+                    </a>
+                    {{end}}
+                </nav>
+                
+                <!-- Mobile menu button -->
+                <button class="md:hidden p-2 rounded-lg hover:bg-purple-100 transition-colors duration-200" onclick="toggleMobileMenu()">
+                    <svg class="w-6 h-6 text-gray-700" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                        <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M4 6h16M4 12h16M4 18h16"></path>
+                    </svg>
+                </button>
+                {{end}}
+            </div>
+            
+            {{if .navigation}}
+            <!-- Mobile menu -->
+            <div id="mobileMenu" class="hidden md:hidden mt-4 pb-4 border-t border-purple-200">
+                <nav class="flex flex-col space-y-3 pt-4">
+                    {{range .navigation}}
+                    <a href="{{.url}}" class="text-gray-700 hover:text-purple-600 font-medium transition-colors duration-200 py-2">
+                        {{.label}}
+                    </a>
+                    {{end}}
+                </nav>
+            </div>
+            {{end}}
+        </div>
+    </header>
+    
+    <!-- Main Content Container -->
+    <div class="flex-1">
+        {{if .pageTitle}}
+        <div class="max-w-7xl mx-auto px-6 py-8">
+            <div class="text-center mb-8">
+                <h1 class="text-4xl md:text-5xl font-bold bg-gradient-to-r from-purple-600 via-pink-600 to-indigo-600 bg-clip-text text-transparent mb-4">
+                    {{.pageTitle}}
+                </h1>
+                <div class="w-24 h-1 bg-gradient-to-r from-purple-500 via-pink-500 to-indigo-500 rounded-full mx-auto"></div>
+            </div>
+        </div>
+        {{end}}
+        
+        <!-- Flash Messages -->
+        {{if .flash}}
+        <div class="max-w-7xl mx-auto px-6 mb-6">
+            {{if .flash.success}}
+            <div class="bg-emerald-50/90 backdrop-blur-sm border border-emerald-200 text-emerald-800 px-6 py-4 rounded-xl shadow-lg mb-4">
+                <div class="flex items-center">
+                    <svg class="w-5 h-5 mr-3 text-emerald-500" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                        <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M9 12l2 2 4-4m6 2a9 9 0 11-18 0 9 9 0 0118 0z"></path>
+                    </svg>
+                    {{.flash.success}}
+                </div>
+            </div>
+            {{end}}
+            {{if .flash.error}}
+            <div class="bg-red-50/90 backdrop-blur-sm border border-red-200 text-red-800 px-6 py-4 rounded-xl shadow-lg mb-4">
+                <div class="flex items-center">
+                    <svg class="w-5 h-5 mr-3 text-red-500" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                        <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M12 8v4m0 4h.01M21 12a9 9 0 11-18 0 9 9 0 0118 0z"></path>
+                    </svg>
+                    {{.flash.error}}
+                </div>
+            </div>
+            {{end}}
+        </div>
+        {{end}}
+        
+        <!-- Main Content -->
+        <main class="flex-1">
+            {{.body}}
+        </main>
+    </div>
+    
+    <!-- Footer -->
+    <footer class="bg-white/80 backdrop-blur-sm border-t border-purple-200/50 mt-16">
+        <div class="max-w-7xl mx-auto px-6 py-8">
+            <div class="text-center">
+                <p class="text-gray-600">
+                    &copy; {{.currentYear}} {{.siteName}} &bull; 
+                    <span class="bg-gradient-to-r from-purple-600 to-pink-600 bg-clip-text text-transparent font-medium">
+                        All rights reserved
+                    </span>
+                </p>
+                <div class="mt-4">
+                    <div class="w-16 h-0.5 bg-gradient-to-r from-purple-400 via-pink-400 to-indigo-400 rounded-full mx-auto"></div>
+                </div>
+            </div>
+        </div>
+    </footer>
+    
+    {{if .additionalJS}}{{.additionalJS}}{{end}}
+    
+    <script>
+        function toggleMobileMenu() {
+            const menu = document.getElementById('mobileMenu');
+            menu.classList.toggle('hidden');
+        }
+        
+        // Auto-dismiss flash messages after 5 seconds
+        setTimeout(() => {
+            const flashMessages = document.querySelectorAll('[class*="bg-emerald-50"], [class*="bg-red-50"]');
+            flashMessages.forEach(msg => {
+                msg.style.transition = 'opacity 0.5s ease-out';
+                msg.style.opacity = '0';
+                setTimeout(() => msg.remove(), 500);
+            });
+        }, 5000);
+    </script>
+</body>
+</html>`
+
+// createAuthDomainFiles creates the auth domain files from the defaults embedded in lib/views
+func createAuthDomainFiles(projectPath string) {
 	// Copy auth templates to project
 	authFiles := map[string]string{
 		"login/get.html.hbs":                           "domains/auth/login/get.html.hbs",
@@ -258,39 +555,17 @@ func createAuthDomainFiles(projectPath string) {
 		"migrations/001_create_users_table.yml":        "domains/auth/migrations/001_create_users_table.yml",
 		"migrations/002_create_tenants_table.yml":      "domains/auth/migrations/002_create_tenants_table.yml",
 		"migrations/003_create_user_tenants_table.yml": "domains/auth/migrations/003_create_user_tenants_table.yml",
+		"migrations/004_create_sessions_table.yml":     "domains/auth/migrations/004_create_sessions_table.yml",
+		"migrations/005_add_slug_to_tenants.yml":       "domains/auth/migrations/005_add_slug_to_tenants.yml",
+		"locales/en.yml":                               "domains/auth/locales/en.yml",
 	}
 
 	for srcFile, dstFile := range authFiles {
-		srcPath := filepath.Join(libAuthPath, srcFile)
 		dstPath := filepath.Join(projectPath, dstFile)
 
-		if err := copyFile(srcPath, dstPath); err != nil {
+		if err := copyEmbeddedFile(views.AuthFiles(), srcFile, dstPath); err != nil {
 			log.Printf("Warning: Failed to copy %s: %v", srcFile, err)
 			// Don't fail the entire process, just warn
 		}
 	}
 }
-
-// copyFile copies a file from src to dst
-func copyFile(src, dst string) error {
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer srcFile.Close()
-
-	// Create destination directory if it doesn't exist
-	dstDir := filepath.Dir(dst)
-	if err := os.MkdirAll(dstDir, 0755); err != nil {
-		return err
-	}
-
-	dstFile, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer dstFile.Close()
-
-	_, err = io.Copy(dstFile, srcFile)
-	return err
-}
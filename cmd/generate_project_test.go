@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fulcrum/lib/framework"
+	"fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+func TestGenerateProjectVariants(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       projectOptions
+		driver     string
+		rootDomain string
+	}{
+		{name: "postgres", opts: projectOptions{DB: "postgres"}, driver: "postgresql", rootDomain: "auth"},
+		{name: "mysql", opts: projectOptions{DB: "mysql"}, driver: "mysql", rootDomain: "auth"},
+		{name: "sqlite", opts: projectOptions{DB: "sqlite"}, driver: "sqlite", rootDomain: "auth"},
+		{name: "sqlite without auth", opts: projectOptions{DB: "sqlite", NoAuth: true}, driver: "sqlite", rootDomain: "home"},
+		{name: "go templates", opts: projectOptions{DB: "sqlite", NoAuth: true, TemplateEngine: views.EngineGoTemplate}, driver: "sqlite", rootDomain: "home"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := filepath.Join(t.TempDir(), "app")
+			if err := generateProject(projectPath, tt.opts); err != nil {
+				t.Fatalf("generateProject failed: %v", err)
+			}
+
+			appConfig, err := parser.GetAppConfig(projectPath)
+			if err != nil {
+				t.Fatalf("Generated fulcrum.yml does not load: %v", err)
+			}
+			if err := appConfig.Validate(); err != nil {
+				t.Fatalf("Generated project is invalid: %v", err)
+			}
+
+			if appConfig.DB.Driver != tt.driver {
+				t.Errorf("Expected driver %s, got %s", tt.driver, appConfig.DB.Driver)
+			}
+			if !strings.HasPrefix(appConfig.Root, "/"+tt.rootDomain) {
+				t.Errorf("Expected root in the %s domain, got %s", tt.rootDomain, appConfig.Root)
+			}
+			if appConfig.Auth.Disabled != tt.opts.NoAuth {
+				t.Errorf("Expected auth.disabled %v, got %v", tt.opts.NoAuth, appConfig.Auth.Disabled)
+			}
+
+			if tt.driver == "sqlite" {
+				if want := filepath.Join(projectPath, "data", "app.db"); appConfig.DB.FilePath != want {
+					t.Errorf("Expected file_path %s, got %s", want, appConfig.DB.FilePath)
+				}
+				if info, err := os.Stat(filepath.Join(projectPath, "data")); err != nil || !info.IsDir() {
+					t.Errorf("Expected a data directory for the sqlite file: %v", err)
+				}
+			}
+
+			_, err = os.Stat(filepath.Join(projectPath, "domains", "auth"))
+			if tt.opts.NoAuth && !os.IsNotExist(err) {
+				t.Errorf("Expected no auth domain with --no-auth, got %v", err)
+			}
+			if !tt.opts.NoAuth && err != nil {
+				t.Errorf("Expected an auth domain: %v", err)
+			}
+
+			if entry, _ := framework.BuildRouteTable(&appConfig).Match(&appConfig, http.MethodGet, "/feed.xml"); entry == nil || entry.XMLTemplate == "" {
+				t.Errorf("Expected the example feed at GET /feed.xml, got %+v", entry)
+			}
+		})
+	}
+}
+
+func TestGenerateProjectRejectsUnknownDB(t *testing.T) {
+	projectPath := filepath.Join(t.TempDir(), "app")
+	err := generateProject(projectPath, projectOptions{DB: "oracle"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported --db") {
+		t.Fatalf("Expected an unsupported --db error, got %v", err)
+	}
+	if _, err := os.Stat(projectPath); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be written for an unknown driver")
+	}
+}
+
+func TestGenerateProjectGoTemplates(t *testing.T) {
+	projectPath := filepath.Join(t.TempDir(), "app")
+	if err := generateProject(projectPath, projectOptions{DB: "sqlite", NoAuth: true, TemplateEngine: views.EngineGoTemplate}); err != nil {
+		t.Fatalf("generateProject failed: %v", err)
+	}
+
+	appConfig, err := parser.GetAppConfig(projectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if appConfig.TemplateEngine() != views.EngineGoTemplate {
+		t.Errorf("Expected views.engine gotemplate, got %s", appConfig.TemplateEngine())
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "shared", "views", "layouts", "main.hbs")); !os.IsNotExist(err) {
+		t.Errorf("Expected no Handlebars layout, got %v", err)
+	}
+
+	renderer, err := views.SetupViewsFromConfig(&appConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appConfig.Views = renderer
+	if err := appConfig.PreloadAndValidateRoutes(); err != nil {
+		t.Fatalf("Generated templates do not load: %v", err)
+	}
+
+	page, err := renderer.Render("home/index/get.html", nil)
+	if err != nil || !strings.Contains(page, "Edit domains/home/index/get.html.tmpl") {
+		t.Fatalf("Expected the home page to render, got %q, %v", page, err)
+	}
+	html, err := renderer.Render("layouts/main", map[string]any{"body": page, "siteName": "Demo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, "<main class=\"flex-1\">\n            "+page) || !strings.Contains(html, "Demo") {
+		t.Errorf("Expected the page inside the layout, got:\n%s", html)
+	}
+	if strings.Contains(html, "<no value>") {
+		t.Errorf("Expected missing layout data to render empty, got:\n%s", html)
+	}
+}
+
+func TestGenerateProjectRejectsUnknownTemplateEngine(t *testing.T) {
+	projectPath := filepath.Join(t.TempDir(), "app")
+	err := generateProject(projectPath, projectOptions{DB: "sqlite", TemplateEngine: "jinja"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported --template-engine") {
+		t.Fatalf("Expected an unsupported --template-engine error, got %v", err)
+	}
+}
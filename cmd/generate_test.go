@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fulcrum/lib/database/migration"
+)
+
+// writeMigration adds a minimal valid migration file to a domain
+func writeMigration(t *testing.T, appPath, domain, fileName, name string, version int) {
+	t.Helper()
+	dir := filepath.Join(appPath, "domains", domain, "migrations")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := fmt.Sprintf("version: %d\nname: %s\nup:\n  - drop_table:\n      name: legacy\n", version, name)
+	if err := os.WriteFile(filepath.Join(dir, fileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerateMigrationUsesNextVersion(t *testing.T) {
+	appPath := t.TempDir()
+	writeMigration(t, appPath, "users", "001_create_users_table.yml", "create_users_table", 1)
+	writeMigration(t, appPath, "users", "002_add_email.yml", "add_email", 2)
+
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	filePath, err := generateMigration(appPath, "users", "add_email_index", nil, now)
+	if err != nil {
+		t.Fatalf("generateMigration failed: %v", err)
+	}
+
+	if filepath.Base(filePath) != "003_add_email_index.yml" {
+		t.Errorf("Expected 003_add_email_index.yml, got %s", filepath.Base(filePath))
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "# Generated by fulcrum generate migration on 2026-10-16T09:30:00Z\n") {
+		t.Errorf("Expected a timestamp header, got %q", strings.SplitN(string(content), "\n", 2)[0])
+	}
+
+	parsed, err := migration.ParseYAMLContent(content)
+	if err != nil {
+		t.Fatalf("Generated migration does not parse: %v", err)
+	}
+	if parsed.Version != 3 || parsed.Name != "add_email_index" {
+		t.Errorf("Expected version 3 add_email_index, got %d %s", parsed.Version, parsed.Name)
+	}
+
+	// The runner loads it alongside the existing migrations
+	migrations, err := migration.NewParser(appPath).LoadDomainMigrations("users")
+	if err != nil {
+		t.Fatalf("Failed to load domain migrations: %v", err)
+	}
+	if len(migrations) != 3 {
+		t.Errorf("Expected 3 migrations, got %d", len(migrations))
+	}
+}
+
+func TestGenerateMigrationColumnShorthand(t *testing.T) {
+	appPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(appPath, "domains", "users"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	filePath, err := generateMigration(appPath, "users", "reshape_users",
+		[]string{"add_column:users.phone:varchar", "drop_column:users.legacy_id"}, time.Now())
+	if err != nil {
+		t.Fatalf("generateMigration failed: %v", err)
+	}
+	if filepath.Base(filePath) != "001_reshape_users.yml" {
+		t.Errorf("Expected the first version in a domain without migrations, got %s", filepath.Base(filePath))
+	}
+
+	parsed, err := migration.ParseYAMLFile(filePath)
+	if err != nil {
+		t.Fatalf("Generated migration does not parse: %v", err)
+	}
+
+	if len(parsed.Up) != 2 || parsed.Up[0].AddColumn == nil || parsed.Up[1].DropColumn == nil {
+		t.Fatalf("Expected add_column then drop_column in up, got %+v", parsed.Up)
+	}
+	if parsed.Up[0].AddColumn.Name != "phone" || parsed.Up[0].AddColumn.Type != "varchar" {
+		t.Errorf("Unexpected add_column: %+v", parsed.Up[0].AddColumn)
+	}
+
+	// down undoes up in reverse order
+	if len(parsed.Down) != 2 || parsed.Down[0].AddColumn == nil || parsed.Down[1].DropColumn == nil {
+		t.Fatalf("Expected add_column then drop_column in down, got %+v", parsed.Down)
+	}
+	if parsed.Down[0].AddColumn.Name != "legacy_id" || parsed.Down[0].AddColumn.Type != "text" {
+		t.Errorf("Expected down to re-add legacy_id as text, got %+v", parsed.Down[0].AddColumn)
+	}
+}
+
+func TestGenerateMigrationErrors(t *testing.T) {
+	appPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(appPath, "domains", "users"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		domain    string
+		columnOps []string
+		errorMsg  string
+	}{
+		{name: "missing domain", domain: "orders", errorMsg: "domain 'orders' does not exist"},
+		{name: "missing column", domain: "users", columnOps: []string{"add_column:users:varchar"}, errorMsg: "expected add_column:table.column:type"},
+		{name: "missing type", domain: "users", columnOps: []string{"add_column:users.phone"}, errorMsg: "needs a type"},
+		{name: "unknown operation", domain: "users", columnOps: []string{"rename_column:users.phone:text"}, errorMsg: "expected add_column or drop_column"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := generateMigration(appPath, tt.domain, "change", tt.columnOps, time.Now())
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// What a generator did with a file
+const (
+	fileCreated     = "created"
+	fileSkipped     = "skipped"
+	fileOverwritten = "overwritten"
+)
+
+// generatedFiles writes a generator's files, keeping ones that already exist unless
+// force is set, and records what happened to each
+type generatedFiles struct {
+	force   bool
+	results []generatedFile
+}
+
+type generatedFile struct {
+	path   string
+	status string
+}
+
+// write writes content to path, creating its directory
+func (g *generatedFiles) write(path string, content []byte) error {
+	status := fileCreated
+	if _, err := os.Stat(path); err == nil {
+		if !g.force {
+			g.record(path, fileSkipped)
+			return nil
+		}
+		status = fileOverwritten
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	g.record(path, status)
+	return nil
+}
+
+// record notes what happened to a file written some other way
+func (g *generatedFiles) record(path, status string) {
+	g.results = append(g.results, generatedFile{path: path, status: status})
+}
+
+// skipped returns how many existing files were kept
+func (g *generatedFiles) skipped() int {
+	count := 0
+	for _, result := range g.results {
+		if result.status == fileSkipped {
+			count++
+		}
+	}
+	return count
+}
+
+// printSummary lists every file with what happened to it, relative to basePath
+func (g *generatedFiles) printSummary(w io.Writer, basePath string) {
+	icons := map[string]string{fileCreated: "✅", fileSkipped: "⏭️ ", fileOverwritten: "♻️ "}
+	for _, result := range g.results {
+		path := result.path
+		if rel, err := filepath.Rel(basePath, path); err == nil {
+			path = rel
+		}
+		fmt.Fprintf(w, "%s %-11s %s\n", icons[result.status], result.status, path)
+	}
+}
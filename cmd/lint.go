@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"fulcrum/lib/database/migration"
+	"fulcrum/lib/parser"
+	"fulcrum/lib/views"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// lintCmd validates the project without starting the server
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Validate the project structure",
+	Long: `Check fulcrum.yml, routes, templates and migrations without starting
+the server, reporting every problem found. HTML templates that write user
+input unescaped with {{{ }}} are reported; use {{ }} for request data and
+database rows.
+
+Use --check-db to also look for schema_migrations records whose
+migration files no longer exist, and --format json for output that
+CI pipelines can parse. Exits non-zero if any problem is found.`,
+	Run: runLint,
+}
+
+var (
+	lintFormat  string
+	lintCheckDB bool
+)
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "Output format: text or json")
+	lintCmd.Flags().BoolVar(&lintCheckDB, "check-db", false, "Check schema_migrations for records without a migration file")
+}
+
+// lintIssue is a single problem found by lint
+type lintIssue struct {
+	Check   string `json:"check"`
+	Message string `json:"message"`
+	File    string `json:"file,omitempty"`
+}
+
+// lintReport is the result of linting a project
+type lintReport struct {
+	OK     bool        `json:"ok"`
+	Issues []lintIssue `json:"issues"`
+}
+
+func (lr *lintReport) add(check, file string, messages ...string) {
+	for _, message := range messages {
+		lr.Issues = append(lr.Issues, lintIssue{Check: check, Message: message, File: file})
+	}
+}
+
+func runLint(cmd *cobra.Command, args []string) {
+	if lintFormat != "text" && lintFormat != "json" {
+		log.Fatalf("Unknown format %q (use text or json)", lintFormat)
+	}
+
+	appPath, err := os.Getwd()
+	if err != nil {
+		log.Fatalf("Failed to get current directory: %v", err)
+	}
+
+	report := lintProject(context.Background(), appPath, lintCheckDB)
+
+	if lintFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+	} else {
+		printLintReport(report)
+	}
+
+	if !report.OK {
+		os.Exit(1)
+	}
+}
+
+// lintProject runs every check against the project at appPath
+func lintProject(ctx context.Context, appPath string, checkDB bool) lintReport {
+	report := lintReport{Issues: []lintIssue{}}
+	configPath := filepath.Join(appPath, parser.DomainConfigFileName)
+
+	appConfig, err := parser.GetAppConfig(appPath)
+	if err != nil {
+		// Nothing else can be checked without the config
+		report.add("config", configPath, err.Error())
+		return report
+	}
+
+	report.add("config", configPath, appConfig.Warnings...)
+	report.add("config", configPath, appConfig.ValidationErrors()...)
+	report.add("config", configPath, appConfig.MessageRouteErrors()...)
+	report.add("routes", "", appConfig.RouteValidationErrors()...)
+
+	lintTemplates(&report, appConfig)
+	migrations := lintMigrations(&report, appConfig)
+
+	if checkDB {
+		lintDatabase(ctx, &report, migrations)
+	}
+
+	report.OK = len(report.Issues) == 0
+	return report
+}
+
+// lintTemplates reports raw outputs of user input in the project's HTML templates
+func lintTemplates(report *lintReport, appConfig parser.AppConfig) {
+	seen := make(map[string]bool)
+	for _, root := range appConfig.GetTemplateRoots() {
+		err := filepath.WalkDir(root.Dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() || !strings.HasSuffix(path, ".hbs") || strings.HasSuffix(path, ".sql.hbs") || seen[path] {
+				return err
+			}
+			seen[path] = true
+			source, err := os.ReadFile(path)
+			if err != nil {
+				report.add("templates", path, err.Error())
+				return nil
+			}
+			for _, raw := range views.UnsafeRawOutputs(string(source)) {
+				report.add("templates", path, fmt.Sprintf("line %d: %s writes user input unescaped; use {{ }} instead", raw.Line, raw.Expression))
+			}
+			return nil
+		})
+		if err != nil {
+			report.add("templates", root.Dir, err.Error())
+		}
+	}
+}
+
+// lintMigrations parses every domain's migration files and returns the valid ones
+func lintMigrations(report *lintReport, appConfig parser.AppConfig) []migration.Migration {
+	var migrations []migration.Migration
+
+	for _, domain := range appConfig.Domains {
+		migrationsDir := filepath.Join(appConfig.Path, "domains", domain.Name, "migrations")
+		files, err := filepath.Glob(filepath.Join(migrationsDir, "*.y*ml"))
+		if err != nil {
+			report.add("migrations", migrationsDir, err.Error())
+			continue
+		}
+		sort.Strings(files)
+
+		versions := make(map[int]string)
+		for _, file := range files {
+			parsed, err := migration.ParseYAMLFile(file)
+			if err != nil {
+				report.add("migrations", file, err.Error())
+				continue
+			}
+
+			if previous, exists := versions[parsed.Version]; exists {
+				report.add("migrations", file, fmt.Sprintf("version %d is also used by %s", parsed.Version, previous))
+				continue
+			}
+			versions[parsed.Version] = file
+
+			parsed.Domain = domain.Name
+			parsed.FilePath = file
+			migrations = append(migrations, *parsed)
+		}
+	}
+
+	return migrations
+}
+
+// lintDatabase reports schema_migrations records that have no migration file
+func lintDatabase(ctx context.Context, report *lintReport, migrations []migration.Migration) {
+	dbManager, _, err := setupDatabase(ctx)
+	if err != nil {
+		report.add("database", "", err.Error())
+		return
+	}
+	defer dbManager.Close()
+
+	applied, err := migration.NewTracker(dbManager.GetDatabase()).GetAppliedMigrations(ctx)
+	if err != nil {
+		report.add("database", "", fmt.Sprintf("failed to read schema_migrations: %v", err))
+		return
+	}
+
+	report.add("database", "", migration.FindOrphanedRecords(applied, migrations)...)
+}
+
+func printLintReport(report lintReport) {
+	if report.OK {
+		fmt.Println("✅ No problems found")
+		return
+	}
+
+	for _, issue := range report.Issues {
+		location := ""
+		if issue.File != "" {
+			location = " " + issue.File + ":"
+		}
+		fmt.Printf("❌ [%s]%s %s\n", issue.Check, location, strings.ReplaceAll(issue.Message, "\n", "\n    "))
+	}
+	fmt.Printf("\n%d problem(s) found\n", len(report.Issues))
+}
@@ -23,6 +23,7 @@ Available subcommands:
   up      - Apply pending migrations
   down    - Roll back migrations  
   status  - Show migration status
+  diff    - Show the SQL pending migrations would execute
   reset   - Reset database (drop and recreate)`,
 }
 
@@ -33,7 +34,13 @@ var migrateUpCmd = &cobra.Command{
 	Long: `Apply all pending migrations to the database.
 
 This will run all migration files that haven't been applied yet,
-in the correct order (by domain and version).`,
+in the correct order (by domain and version).
+
+Use --dry-run to print the SQL without executing it or recording
+anything in schema_migrations.
+
+Applied migrations whose files were edited afterwards stop the run,
+since the edits would never be applied. Use --force to continue anyway.`,
 	Run: runMigrateUp,
 }
 
@@ -57,11 +64,22 @@ applied and which are still pending.`,
 	Run: runMigrateStatus,
 }
 
+// migrateDiffCmd shows the SQL pending migrations would execute
+var migrateDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show SQL for pending migrations",
+	Long: `Print the domain, version, name and SQL of every pending migration
+without executing anything, so migrations can be audited before they
+are applied to production.`,
+	Run: runMigrateDiff,
+}
+
 // migrateResetCmd resets the database
 var migrateResetCmd = &cobra.Command{
 	Use:   "reset",
 	Short: "Reset database (DANGEROUS)",
-	Long: `Drop all tables and re-run all migrations from scratch.
+	Long: `Drop all tables, re-run all migrations from scratch and load the
+seeds, if the app has any.
 
 WARNING: This will delete all data in your database!
 Only use this in development environments.`,
@@ -72,6 +90,8 @@ var (
 	migrateDomain     string
 	migrateToVersion  int
 	migrateForceReset bool
+	migrateDryRun     bool
+	migrateForce      bool
 )
 
 func init() {
@@ -81,8 +101,16 @@ func init() {
 	migrateCmd.AddCommand(migrateUpCmd)
 	migrateCmd.AddCommand(migrateDownCmd)
 	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateDiffCmd)
 	migrateCmd.AddCommand(migrateResetCmd)
 
+	// Dry run prints the SQL without touching the database
+	migrateUpCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Print the SQL for pending migrations without executing it")
+	migrateDownCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Print the rollback SQL without executing it")
+
+	// Force continues past applied migrations whose files have changed
+	migrateUpCmd.Flags().BoolVar(&migrateForce, "force", false, "Apply migrations even if applied migration files were modified")
+
 	// Flags for migrate down
 	migrateDownCmd.Flags().StringVar(&migrateDomain, "domain", "", "Domain to roll back (required with --to)")
 	migrateDownCmd.Flags().IntVar(&migrateToVersion, "to", 0, "Version to roll back to (requires --domain)")
@@ -103,6 +131,8 @@ func runMigrateUp(cmd *cobra.Command, args []string) {
 
 	// Create migration runner
 	runner := migration.NewRunner(dbManager.GetDatabase(), appPath)
+	runner.SetDryRun(migrateDryRun)
+	runner.SetForce(migrateForce)
 
 	// Initialize migration system
 	if err := runner.Initialize(ctx); err != nil {
@@ -127,6 +157,7 @@ func runMigrateDown(cmd *cobra.Command, args []string) {
 
 	// Create migration runner
 	runner := migration.NewRunner(dbManager.GetDatabase(), appPath)
+	runner.SetDryRun(migrateDryRun)
 
 	// Initialize migration system
 	if err := runner.Initialize(ctx); err != nil {
@@ -209,6 +240,33 @@ func runMigrateStatus(cmd *cobra.Command, args []string) {
 	}
 }
 
+func runMigrateDiff(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	// Load configuration and setup database
+	dbManager, appPath, err := setupDatabase(ctx)
+	if err != nil {
+		log.Fatalf("Failed to setup database: %v", err)
+	}
+	defer dbManager.Close()
+
+	// The runner is not initialized so schema_migrations is never created
+	runner := migration.NewRunner(dbManager.GetDatabase(), appPath)
+
+	diffs, err := runner.MigrateUpDry(ctx)
+	if err != nil {
+		log.Fatalf("Failed to diff migrations: %v", err)
+	}
+
+	if len(diffs) == 0 {
+		fmt.Println("✅ No pending migrations")
+		return
+	}
+
+	fmt.Printf("📋 %d pending migrations\n\n", len(diffs))
+	migration.WriteDiffs(os.Stdout, diffs)
+}
+
 func runMigrateReset(cmd *cobra.Command, args []string) {
 	// Safety check
 	if !migrateForceReset {
@@ -264,41 +322,71 @@ func runMigrateReset(cmd *cobra.Command, args []string) {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
+	if _, err := seedIfPresent(ctx, db, appPath); err != nil {
+		log.Fatalf("Failed to run seeds: %v", err)
+	}
+
 	fmt.Println("✅ Database reset complete!")
 }
 
 // setupDatabase loads configuration and creates database manager
 func setupDatabase(ctx context.Context) (*database.Manager, string, error) {
+	return setupDatabaseForEnv(ctx, configEnv(""))
+}
+
+// setupDatabaseForEnv is setupDatabase with fulcrum.<env>.yml merged over fulcrum.yml
+func setupDatabaseForEnv(ctx context.Context, env string) (*database.Manager, string, error) {
+	dbConfig, appPath, err := loadDBConfig(env)
+	if err != nil {
+		return nil, "", err
+	}
+
+	dbManager, err := connectDatabase(ctx, dbConfig)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return dbManager, appPath, nil
+}
+
+// loadDBConfig reads the database config of the app in the current directory
+func loadDBConfig(env string) (interfaces.Config, string, error) {
 	// Get current working directory as app path
 	appPath, err := os.Getwd()
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to get current directory: %w", err)
+		return interfaces.Config{}, "", fmt.Errorf("failed to get current directory: %w", err)
 	}
 
 	// Load app configuration
-	appConfig, err := parser.GetAppConfig(appPath)
+	appConfig, err := parser.GetAppConfigForEnv(appPath, env)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to load app config: %w", err)
+		return interfaces.Config{}, "", fmt.Errorf("failed to load app config: %w", err)
+	}
+	if err := appConfig.DB.Validate(); err != nil {
+		return interfaces.Config{}, "", err
 	}
 
 	// Convert to database config
 	dbConfig, err := database.FromParserConfig(appConfig.DB)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to convert database config: %w", err)
+		return interfaces.Config{}, "", fmt.Errorf("failed to convert database config: %w", err)
 	}
 
-	// Create database manager
+	return dbConfig, appPath, nil
+}
+
+// connectDatabase creates a database manager and connects it
+func connectDatabase(ctx context.Context, dbConfig interfaces.Config) (*database.Manager, error) {
 	dbManager, err := database.NewManager(dbConfig)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to create database manager: %w", err)
+		return nil, fmt.Errorf("failed to create database manager: %w", err)
 	}
 
-	// Connect to database
 	if err := dbManager.Connect(ctx); err != nil {
-		return nil, "", fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	return dbManager, appPath, nil
+	return dbManager, nil
 }
 
 // getAllTables returns all table names in the database (PostgreSQL specific)
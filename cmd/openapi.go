@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"fulcrum/lib/framework"
+	"fulcrum/lib/parser"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// openapiCmd groups the OpenAPI commands
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Describe the JSON API",
+}
+
+// openapiExportCmd writes the OpenAPI document of the json routes
+var openapiExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export an OpenAPI 3 document for the json routes",
+	Long: `Write an OpenAPI 3 document describing every json route: a path per
+route with its [param] segments as parameters, request bodies for POST,
+PUT and PATCH built from the domain's model, and the
+{success, data, count, error} response envelope.
+
+In development the same document is served at GET /openapi.json.
+
+  fulcrum openapi export
+  fulcrum openapi export --output openapi.json`,
+	Run: runOpenAPIExport,
+}
+
+var (
+	openapiOutput string
+	openapiEnv    string
+)
+
+func init() {
+	rootCmd.AddCommand(openapiCmd)
+	openapiCmd.AddCommand(openapiExportCmd)
+
+	openapiExportCmd.Flags().StringVarP(&openapiOutput, "output", "o", "", "File to write (defaults to stdout)")
+	openapiExportCmd.Flags().StringVar(&openapiEnv, "env", "", "Merge fulcrum.<env>.yml over fulcrum.yml (defaults to FULCRUM_ENV)")
+}
+
+func runOpenAPIExport(cmd *cobra.Command, args []string) {
+	appPath, err := os.Getwd()
+	if err != nil {
+		log.Fatalf("Failed to get current directory: %v", err)
+	}
+
+	appConfig, err := parser.GetAppConfigForEnv(appPath, configEnv(openapiEnv))
+	if err != nil {
+		log.Fatalf("Failed to load app config: %v", err)
+	}
+
+	doc, err := framework.OpenAPI(&appConfig)
+	if err != nil {
+		log.Fatalf("Failed to generate OpenAPI document: %v", err)
+	}
+	doc = append(doc, '\n')
+
+	if openapiOutput == "" {
+		os.Stdout.Write(doc)
+		return
+	}
+	if err := os.WriteFile(openapiOutput, doc, 0644); err != nil {
+		log.Fatalf("Failed to write %s: %v", openapiOutput, err)
+	}
+	fmt.Printf("✅ Wrote %s\n", openapiOutput)
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"fulcrum/lib/framework"
+	parser "fulcrum/lib/parser"
+
+	"github.com/spf13/cobra"
+)
+
+// routesCmd prints the routing table the server would register
+var routesCmd = &cobra.Command{
+	Use:   "routes [path]",
+	Short: "List the resolved routing table",
+	Long: `Load the app at path (default ".") and print every route in the order the
+server registers them: method, pattern in both [param] and Go {param} form,
+domain, templates, redirect rule and whether the route requires login.
+Routes that are not registered are listed with the reason.
+
+  fulcrum routes
+  fulcrum routes --json
+  fulcrum routes --match /users/42
+  fulcrum routes --match "POST /users/new"
+
+--match shows which route would answer a request; the method defaults to GET.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runRoutes,
+}
+
+var (
+	routesJSON  bool
+	routesMatch string
+	routesEnv   string
+)
+
+func init() {
+	rootCmd.AddCommand(routesCmd)
+
+	routesCmd.Flags().BoolVar(&routesJSON, "json", false, "Print the table as JSON")
+	routesCmd.Flags().StringVar(&routesMatch, "match", "", `Show the route that answers a request, e.g. "/users/42" or "POST /users"`)
+	routesCmd.Flags().StringVar(&routesEnv, "env", "", "Merge fulcrum.<env>.yml over fulcrum.yml (defaults to FULCRUM_ENV)")
+}
+
+// routeMatch is the --json output of --match
+type routeMatch struct {
+	Method string                `json:"method"`
+	Path   string                `json:"path"`
+	Route  *framework.RouteEntry `json:"route"`
+	Params map[string]string     `json:"params,omitempty"`
+}
+
+func runRoutes(cmd *cobra.Command, args []string) {
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	appPath, err := filepath.Abs(path)
+	if err != nil {
+		log.Fatalf("Failed to resolve app path: %v", err)
+	}
+
+	// Config loading logs every route it discovers; the table says it better
+	log.SetOutput(io.Discard)
+	appConfig, err := parser.GetAppConfigForEnv(appPath, configEnv(routesEnv))
+	log.SetOutput(os.Stderr)
+	if err != nil {
+		log.Fatalf("Failed to load app config: %v", err)
+	}
+
+	table := framework.BuildRouteTable(&appConfig)
+
+	if routesMatch != "" {
+		method, requestPath := parseRouteMatch(routesMatch)
+		entry, params := table.Match(&appConfig, method, requestPath)
+		if routesJSON {
+			writeRoutesJSON(routeMatch{Method: method, Path: requestPath, Route: entry, Params: params})
+			return
+		}
+		printRouteMatch(os.Stdout, appPath, method, requestPath, entry, params)
+		return
+	}
+
+	if routesJSON {
+		writeRoutesJSON(table)
+		return
+	}
+	printRouteTable(os.Stdout, appPath, table)
+}
+
+// parseRouteMatch splits "POST /users" into method and path, defaulting to GET
+func parseRouteMatch(value string) (string, string) {
+	if method, path, found := strings.Cut(strings.TrimSpace(value), " "); found {
+		return strings.ToUpper(method), strings.TrimSpace(path)
+	}
+	return "GET", strings.TrimSpace(value)
+}
+
+// writeRoutesJSON prints v as indented JSON
+func writeRoutesJSON(v any) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		log.Fatalf("Failed to write routes: %v", err)
+	}
+}
+
+// printRouteTable writes the registered routes, then the skipped ones
+func printRouteTable(w io.Writer, appPath string, table framework.RouteTable) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATTERN\tGO PATTERN\tDOMAIN\tTEMPLATE\tSQL\tREDIRECT\tACCESS")
+	for _, entry := range table.Routes {
+		pattern := entry.Pattern
+		if entry.Root {
+			pattern += " (root)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Method, pattern, entry.GoPattern, entry.Domain,
+			relativeTemplate(appPath, routeTemplate(entry)), relativeTemplate(appPath, entry.SQLTemplate),
+			describeRedirect(entry.Redirect), entry.Access)
+	}
+	tw.Flush()
+
+	if len(table.Skipped) == 0 {
+		return
+	}
+
+	skipped := append([]framework.RouteEntry{}, table.Skipped...)
+	sort.SliceStable(skipped, func(i, j int) bool { return skipped[i].Pattern < skipped[j].Pattern })
+
+	fmt.Fprintf(w, "\nSkipped routes:\n")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, entry := range skipped {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", entry.Method, entry.Pattern, entry.Domain, entry.SkipReason)
+	}
+	tw.Flush()
+}
+
+// printRouteMatch describes the route that answers method and path
+func printRouteMatch(w io.Writer, appPath, method, path string, entry *framework.RouteEntry, params map[string]string) {
+	if entry == nil {
+		fmt.Fprintf(w, "No route matches %s %s\n", method, path)
+		return
+	}
+
+	fmt.Fprintf(w, "%s %s -> %s %s (%s)\n", method, path, entry.Method, entry.Pattern, entry.GoPattern)
+	fmt.Fprintf(w, "  Domain:   %s\n", entry.Domain)
+	fmt.Fprintf(w, "  Template: %s\n", relativeTemplate(appPath, routeTemplate(*entry)))
+	fmt.Fprintf(w, "  SQL:      %s\n", relativeTemplate(appPath, entry.SQLTemplate))
+	fmt.Fprintf(w, "  Redirect: %s\n", describeRedirect(entry.Redirect))
+	fmt.Fprintf(w, "  Access:   %s\n", entry.Access)
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  Param:    %s = %s\n", name, params[name])
+	}
+}
+
+// routeTemplate returns the template a route renders, HTML before JSON before XML
+func routeTemplate(entry framework.RouteEntry) string {
+	if entry.HTMLTemplate != "" {
+		return entry.HTMLTemplate
+	}
+	if entry.JSONTemplate != "" {
+		return entry.JSONTemplate
+	}
+	return entry.XMLTemplate
+}
+
+// relativeTemplate shortens a template path to be relative to the app
+func relativeTemplate(appPath, templatePath string) string {
+	if templatePath == "" {
+		return "-"
+	}
+	if rel, err := filepath.Rel(appPath, templatePath); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return templatePath
+}
+
+// describeRedirect formats a redirect rule for the table
+func describeRedirect(redirect *parser.RedirectRule) string {
+	if redirect == nil {
+		return "-"
+	}
+	description := redirect.To
+	if redirect.Status != 0 {
+		description += fmt.Sprintf(" (%d)", redirect.Status)
+	}
+	if redirect.When != "" {
+		description += " when " + redirect.When
+	}
+	return description
+}
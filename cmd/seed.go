@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"fulcrum/lib/database/seeder"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// seedCmd applies pending seed files
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Apply pending seed files",
+	Long: `Insert the rows declared in domains/<name>/seeds/*.yml and each
+domain's seeds.yml, which runs every time. fulcrum db seed describes
+unique_by, row keys and passwords.
+
+Seed files run in version order per domain and are recorded in the
+schema_seeds table, so each file is applied only once. Rows matched by
+a record's skip_if_exists are left out:
+
+  records:
+    - table: users
+      skip_if_exists:
+        where: {email: admin@example.com}
+      rows:
+        - email: admin@example.com
+          role: admin`,
+	Run: runSeed,
+}
+
+// seedStatusCmd shows seed status
+var seedStatusCmd = &cobra.Command{
+	Use:   "seed:status",
+	Short: "Show seed status",
+	Long: `Show the status of all seed files, including which have been
+applied and which are still pending.`,
+	Run: runSeedStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+	rootCmd.AddCommand(seedStatusCmd)
+}
+
+func runSeed(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	// Load configuration and setup database
+	dbManager, appPath, err := setupDatabase(ctx)
+	if err != nil {
+		log.Fatalf("Failed to setup database: %v", err)
+	}
+	defer dbManager.Close()
+
+	if err := seeder.NewSeeder(dbManager.GetDatabase(), appPath).SeedAll(ctx); err != nil {
+		log.Fatalf("Failed to run seeds: %v", err)
+	}
+}
+
+func runSeedStatus(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	// Load configuration and setup database
+	dbManager, appPath, err := setupDatabase(ctx)
+	if err != nil {
+		log.Fatalf("Failed to setup database: %v", err)
+	}
+	defer dbManager.Close()
+
+	s := seeder.NewSeeder(dbManager.GetDatabase(), appPath)
+	if err := s.Initialize(ctx); err != nil {
+		log.Fatalf("Failed to initialize seed tracking: %v", err)
+	}
+
+	statuses, err := s.GetStatus(ctx)
+	if err != nil {
+		log.Fatalf("Failed to get seed status: %v", err)
+	}
+
+	fmt.Println("🌱 Seed Status")
+	fmt.Println("==============")
+
+	if len(statuses) == 0 {
+		fmt.Println("No seed files found")
+		return
+	}
+
+	domain := ""
+	for _, status := range statuses {
+		if status.Seed.Domain != domain {
+			domain = status.Seed.Domain
+			fmt.Printf("\n🏗️  Domain: %s\n", domain)
+		}
+
+		if status.Seed.Repeatable {
+			fmt.Printf("   🔁 %s (runs on every seed)\n", status.Seed.Name)
+		} else if status.AppliedAt != nil {
+			fmt.Printf("   ✅ %d - %s (applied %s)\n",
+				status.Seed.Version, status.Seed.Name, status.AppliedAt.Format("2006-01-02 15:04:05"))
+		} else {
+			fmt.Printf("   ⏳ %d - %s\n", status.Seed.Version, status.Seed.Name)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"fulcrum/lib/framework"
+	parser "fulcrum/lib/parser"
+
+	"github.com/spf13/cobra"
+)
+
+// serveCmd runs an app's HTTP and gRPC servers
+var serveCmd = &cobra.Command{
+	Use:   "serve [path]",
+	Short: "Run a Fulcrum app",
+	Long: `Load fulcrum.yml from path (default ".") and serve the app over HTTP,
+with the gRPC framework server on :50051 and the JavaScript handler
+service started when the app has handler.js files.
+
+  fulcrum serve
+  fulcrum serve ./my-app --dev --port 3000
+
+--dev enables the development renderer, hot reloading and verbose
+logging, including every registered route. --mock serves without a
+database: SQL routes answer with placeholder rows built from the domain
+models, each labeled "_mock": true. Press Ctrl+C to stop.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runServe,
+}
+
+var (
+	serveDev        bool
+	servePort       int
+	serveNoHandlers bool
+	serveEnv        string
+	serveMock       bool
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().BoolVar(&serveDev, "dev", false, "Enable the development renderer, hot reloading and verbose logging")
+	serveCmd.Flags().IntVar(&servePort, "port", 8080, "HTTP port to listen on")
+	serveCmd.Flags().BoolVar(&serveNoHandlers, "no-handlers", false, "Don't start the JavaScript handler service")
+	serveCmd.Flags().BoolVar(&serveMock, "mock", false, "Serve placeholder rows instead of connecting to the database")
+	serveCmd.Flags().StringVar(&serveEnv, "env", "", "Merge fulcrum.<env>.yml over fulcrum.yml (defaults to FULCRUM_ENV)")
+}
+
+func runServe(cmd *cobra.Command, args []string) {
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := framework.ServeOptions{
+		Addr:       fmt.Sprintf(":%d", servePort),
+		Dev:        serveDev,
+		NoHandlers: serveNoHandlers,
+		Mock:       serveMock,
+	}
+	if err := serve(ctx, path, configEnv(serveEnv), opts); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+}
+
+// serve loads the app at path and runs it until ctx is cancelled
+func serve(ctx context.Context, path, env string, opts framework.ServeOptions) error {
+	appPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve app path: %w", err)
+	}
+
+	appConfig, err := parser.GetAppConfigForEnv(appPath, env)
+	if err != nil {
+		return fmt.Errorf("failed to load app config: %w", err)
+	}
+
+	if err := appConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return framework.Serve(ctx, &appConfig, opts)
+}
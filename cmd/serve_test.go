@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fulcrum/lib/framework"
+)
+
+func TestServeGeneratedProject(t *testing.T) {
+	projectPath := filepath.Join(t.TempDir(), "app")
+	if err := generateProject(projectPath, projectOptions{DB: "sqlite"}); err != nil {
+		t.Fatalf("generateProject failed: %v", err)
+	}
+
+	addr := freeAddr(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, projectPath, "", framework.ServeOptions{Addr: addr, NoHandlers: true})
+	}()
+
+	body, err := waitForHealth(fmt.Sprintf("http://%s/health", addr), done)
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	if !strings.Contains(body, "Status: OK") {
+		t.Errorf("Expected a healthy response, got %q", body)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serve returned an error on shutdown: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("serve did not stop after its context was cancelled")
+	}
+}
+
+// freeAddr returns a loopback address with a port nothing is listening on
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// waitForHealth polls url until it answers 200, or serve exits
+func waitForHealth(url string, done <-chan error) (string, error) {
+	deadline := time.Now().Add(15 * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-done:
+			return "", fmt.Errorf("serve exited before becoming healthy: %v", err)
+		default:
+		}
+
+		resp, err := http.Get(url)
+		if err == nil {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return string(body), nil
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return "", fmt.Errorf("%s did not become healthy", url)
+}
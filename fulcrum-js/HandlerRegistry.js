@@ -143,6 +143,13 @@ async route(domain, action, params = {}, context) {
         return await this.executeHandler(parameterizedId, context);
     }
     
+    // Fall back to a domain-level handler.js exporting one function per action
+    const domainAction = this.findDomainAction(domain, action, context);
+    if (domainAction) {
+        console.log(`✅ Found domain handler: ${domain}.${domainAction.name}`);
+        return await domainAction.fn(context.sql, context.request, context);
+    }
+    
     console.log(`❌ No handler found for: ${domain}.${action}`);
     throw new Error(`Handler not found for: ${domain}.${action}`);
 }
@@ -160,6 +167,45 @@ async route(domain, action, params = {}, context) {
     return null;
   }
   
+  // Resolve an action against domains/<domain>/handler.js. Exports are named after the
+  // generated routes: "index", "{users_id}.show" -> show, "{users_id}.update" -> update.
+  // Bare resources are named by method, e.g. DELETE on "{users_id}" -> delete.
+  // Actions the module does not export pass the SQL data through untouched.
+  findDomainAction(domain, action, context = {}) {
+    const domainHandler = this.handlers.get(domain);
+    if (!domainHandler || typeof domainHandler.handler !== 'object') {
+      return null;
+    }
+    
+    const name = this.domainActionName(action, (context.request && context.request._method) || 'GET');
+    const fn = domainHandler.handler[name];
+    if (typeof fn === 'function') {
+      return { name, fn };
+    }
+    return { name, fn: async (sqlData) => sqlData };
+  }
+  
+  domainActionName(action, method) {
+    const parts = action.split('.');
+    const named = parts.filter(part => !(part.startsWith('{') && part.endsWith('}'))).pop();
+    if (named && named !== 'index') {
+      return named;
+    }
+    
+    const onRecord = parts[0].startsWith('{');
+    switch (method.toUpperCase()) {
+      case 'POST':
+        return onRecord ? 'update' : 'create';
+      case 'PUT':
+      case 'PATCH':
+        return 'update';
+      case 'DELETE':
+        return 'delete';
+      default:
+        return onRecord ? 'show' : 'index';
+    }
+  }
+  
   // Check if a handler ID pattern matches the target with parameters
   matchesPattern(handlerId, targetPattern, params) {
     const handlerParts = handlerId.split('.');
@@ -219,7 +265,7 @@ async route(domain, action, params = {}, context) {
   // Public method to process requests (called from Go via gRPC)
   async processRequest(requestData) {
     try {
-      const { domain, action, params = {}, sql = null, request = {} } = requestData;
+      const { domain, action, params = {}, sql = null, request = {}, trace = null } = requestData;
       
       // Create context object
       const context = {
@@ -228,6 +274,7 @@ async route(domain, action, params = {}, context) {
         params,
         sql,
         request,
+        trace,
         route: {
           domain,
           action,
@@ -238,7 +285,11 @@ async route(domain, action, params = {}, context) {
           db: {
             find: async (table, query) => await this.sendFrameworkMessage('db_find', { table, query }, request),
             create: async (table, data) => await this.sendFrameworkMessage('db_create', { table, data }, request),
-            update: async (table, id, data) => await this.sendFrameworkMessage('db_update', { table, id, data }, request),
+            update: async (table, id, data, { expectedVersion } = {}) => await this.sendFrameworkMessage('db_update', { table, id, data, expected_version: expectedVersion }, request),
+            delete: async (table, id, { hard = false } = {}) => await this.sendFrameworkMessage('db_delete', { table, id, _hard: hard }, request),
+            restore: async (table, id) => await this.sendFrameworkMessage('db_restore', { table, id }, request),
+            deleteWhere: async (table, query) => await this.sendFrameworkMessage('db_delete_where', { table, query }, request),
+            execute: async (sql, params = {}) => await this.sendFrameworkMessage('db_execute', { sql, params }, request),
           }
         }
       };
@@ -46,6 +46,9 @@ class HandlerService {
       // Extract parameters from metadata or request
       const params = this.extractParams(request);
       
+      // W3C trace context from the framework, so handlers can continue its trace
+      const trace = this.extractTraceContext(call.metadata);
+      
       console.log(`SQL data:`, sqlData);
       console.log(`Request data:`, requestData);
       console.log(`Parameters:`, params);
@@ -56,7 +59,8 @@ class HandlerService {
         action: request.action,
         params: params,
         sql: sqlData,
-        request: requestData
+        request: requestData,
+        trace: trace
       }).then(result => {
           if (result.success) {
             const response = {
@@ -128,6 +132,21 @@ class HandlerService {
     });
   }
   
+  // Extract traceparent/tracestate sent by the framework, if tracing is enabled
+  extractTraceContext(metadata) {
+    if (!metadata) {
+      return null;
+    }
+    
+    const [traceparent] = metadata.get('traceparent');
+    if (!traceparent) {
+      return null;
+    }
+    
+    const [tracestate] = metadata.get('tracestate');
+    return { traceparent, tracestate: tracestate || null };
+  }
+  
   // Extract parameters from the request
   extractParams(request) {
     const params = {};
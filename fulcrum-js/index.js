@@ -1,5 +1,5 @@
-const HandlerRegistry = require('./lib/HandlerRegistry');
-const HandlerService = require('./lib/HandlerService');
+const HandlerRegistry = require('./HandlerRegistry');
+const HandlerService = require('./HandlerService');
 const FulcrumJS = require('./lib/FulcrumJS');
 
 module.exports = {
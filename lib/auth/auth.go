@@ -4,18 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
 	"time"
 
+	"fulcrum/lib/i18n"
 	lang_adapters "fulcrum/lib/lang/adapters"
+	"fulcrum/lib/middleware"
+	"fulcrum/lib/views"
 
 	"github.com/aymerick/raymond"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 type LoginRequest struct {
@@ -31,11 +33,6 @@ type User struct {
 
 var jwtSecret = []byte("your-secret-key-change-this-in-production")
 
-var users = map[string]User{
-	"admin": {Username: "admin", Password: "password123"},
-	"user":  {Username: "user", Password: "userpass"},
-}
-
 // func CurrentUser(jwt string, fs *lang_adapters.FrameworkServer) {
 // 	params := map[string]any{
 // 		"username": username,
@@ -50,52 +47,31 @@ var users = map[string]User{
 // 	}
 // }
 
-// findAuthTemplate finds an auth template, checking project domains first, then lib/views fallback
-func findAuthTemplate(templateName string) (string, error) {
-	// Get current working directory for project-specific templates
-	cwd, err := os.Getwd()
+// loadAuthTemplate renders an auth template with data, preferring the project's copy in
+// domains/auth over the default embedded in lib/views
+func loadAuthTemplate(templateName string, data map[string]interface{}) (string, error) {
+	var tmpl *raymond.Template
+	projectTemplate, err := filepath.Abs(filepath.Join("domains", "auth", templateName))
 	if err != nil {
-		return "", fmt.Errorf("failed to get current directory: %w", err)
+		return "", fmt.Errorf("failed to resolve template %s: %w", templateName, err)
 	}
-
-	// Check project-specific auth template first
-	projectTemplate := filepath.Join(cwd, "domains", "auth", templateName)
-	if _, err := os.Stat(projectTemplate); err == nil {
+	if _, statErr := os.Stat(projectTemplate); statErr == nil {
 		log.Printf("🎯 Using project-specific auth template: %s", projectTemplate)
-		return projectTemplate, nil
-	}
-
-	// Fall back to lib/views/auth default template
-	_, filename, _, ok := runtime.Caller(0)
-	if !ok {
-		return "", fmt.Errorf("failed to get runtime caller info")
-	}
-
-	// Navigate from lib/auth/auth.go to lib/views/auth
-	libDir := filepath.Dir(filepath.Dir(filename)) // Go up two levels from lib/auth/
-	libTemplate := filepath.Join(libDir, "views", "auth", templateName)
-
-	if _, err := os.Stat(libTemplate); err == nil {
-		log.Printf("🏷️ Using default auth template: %s", libTemplate)
-		return libTemplate, nil
-	}
-
-	return "", fmt.Errorf("auth template %s not found in project or lib/views", templateName)
-}
-
-// loadAuthTemplate loads and renders an auth template with data
-func loadAuthTemplate(templateName string, data map[string]interface{}) (string, error) {
-	templatePath, err := findAuthTemplate(templateName)
-	if err != nil {
-		return "", err
+		tmpl, err = raymond.ParseFile(projectTemplate)
+	} else {
+		var source []byte
+		source, err = fs.ReadFile(views.AuthFiles(), templateName)
+		if err != nil {
+			return "", fmt.Errorf("auth template %s not found: %w", templateName, err)
+		}
+		tmpl, err = raymond.Parse(string(source))
 	}
-
-	tmpl, err := raymond.ParseFile(templatePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template %s: %w", templateName, err)
 	}
+	views.AddHTMLHelpers(tmpl)
 
-	html, err := tmpl.Exec(data)
+	html, err := views.Exec(tmpl, data)
 	if err != nil {
 		return "", fmt.Errorf("failed to execute template %s: %w", templateName, err)
 	}
@@ -103,6 +79,20 @@ func loadAuthTemplate(templateName string, data map[string]interface{}) (string,
 	return html, nil
 }
 
+// renderAuthPage writes an auth template as the response, in the request's locale
+func renderAuthPage(w http.ResponseWriter, r *http.Request, templateName string, data map[string]interface{}) {
+	data[views.LocaleKey] = i18n.ResolveLocale(r)
+	html, err := loadAuthTemplate(templateName, data)
+	if err != nil {
+		log.Printf("❌ Failed to render auth template: %v", err)
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Write([]byte(html))
+}
+
 func handleLoginPage(w http.ResponseWriter, r *http.Request) {
 	if IsAuthenticated(r) {
 		http.Redirect(w, r, "/auth/dashboard", http.StatusSeeOther)
@@ -121,83 +111,17 @@ func handleLoginPage(w http.ResponseWriter, r *http.Request) {
 		data["success"] = successMsg
 	}
 
-	// Try to load dynamic template, fallback to hardcoded if needed
-	html, err := loadAuthTemplate("login/get.html.hbs", data)
-	if err != nil {
-		log.Printf("⚠️ Failed to load dynamic auth template, using fallback: %v", err)
-		// Fallback to hardcoded template
-		loginTemplate := `
-<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Login</title>
-    <script src="https://cdn.tailwindcss.com"></script>
-</head>
-<body class="bg-gray-100 min-h-screen flex items-center justify-center">
-    <div class="bg-white p-8 rounded-lg shadow-md w-full max-w-md">
-        <h2 class="text-2xl font-bold text-center text-gray-800 mb-6">Login</h2>
-        
-        {{#if error}}
-        <div class="bg-red-100 border border-red-400 text-red-700 px-4 py-3 rounded mb-4">
-            {{error}}
-        </div>
-        {{/if}}
-
-        {{#if success}}
-        <div class="bg-green-100 border border-green-400 text-green-700 px-4 py-3 rounded mb-4">
-            {{success}}
-        </div>
-        {{/if}}
-
-        <form method="POST" action="/auth/login" class="space-y-4">
-            <div>
-                <label for="username" class="block text-sm font-medium text-gray-700 mb-1">Email</label>
-                <input type="email" id="username" name="username" required 
-                       class="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-transparent">
-            </div>
-            
-            <div>
-                <label for="password" class="block text-sm font-medium text-gray-700 mb-1">Password</label>
-                <input type="password" id="password" name="password" required 
-                       class="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-transparent">
-            </div>
-            
-            <button type="submit" 
-                    class="w-full bg-blue-600 text-white py-2 px-4 rounded-md hover:bg-blue-700 focus:outline-none focus:ring-2 focus:ring-blue-500 focus:ring-offset-2 transition duration-200">
-                Sign In
-            </button>
-        </form>
-        
-        <div class="mt-6 text-center">
-            <p class="text-sm text-gray-600">
-                Don't have an account? 
-                <a href="/auth/register" class="text-blue-600 hover:text-blue-700 font-medium">Create one</a>
-            </p>
-        </div>
-    </div>
-</body>
-</html>`
-
-		tmpl, err := raymond.Parse(loginTemplate)
-		if err != nil {
-			http.Error(w, "Template error", http.StatusInternalServerError)
-			return
-		}
-
-		html, err = tmpl.Exec(data)
-		if err != nil {
-			http.Error(w, "Template execution error", http.StatusInternalServerError)
-			return
-		}
-	}
-
-	w.Header().Set("Content-Type", "text/html")
-	w.Write([]byte(html))
+	renderAuthPage(w, r, "login/get.html.hbs", data)
 }
 
 func handleLoginSubmit(w http.ResponseWriter, r *http.Request, fs *lang_adapters.FrameworkServer) {
+	// There are no built-in credentials to fall back to
+	if fs == nil || fs.DbExecutor == nil {
+		log.Printf("❌ Sign-in needs a database connection; check the db section of fulcrum.yml")
+		http.Redirect(w, r, "/auth/login?error=Sign-in+is+unavailable+without+a+database", http.StatusSeeOther)
+		return
+	}
+
 	username := r.FormValue("username")
 	password := r.FormValue("password")
 
@@ -278,36 +202,36 @@ func handleLoginSubmit(w http.ResponseWriter, r *http.Request, fs *lang_adapters
 		Id:       id,
 	}
 
-	// Create JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"Username": user.Username,
-		"UserId":   user.Id,
-		"exp":      time.Now().Add(24 * time.Hour).Unix(),
-		"iat":      time.Now().Unix(),
-	})
-
-	tokenString, err := token.SignedString(jwtSecret)
+	// Start a session
+	cookie, err := StartSession(ctx, user)
 	if err != nil {
-		log.Printf("❌ Failed to create JWT token: %v", err)
+		log.Printf("❌ Failed to create session: %v", err)
 		http.Redirect(w, r, "/auth/login?error=Internal+server+error", http.StatusSeeOther)
 		return
 	}
+	http.SetCookie(w, cookie)
 
-	// Set JWT as HTTP-only cookie
-	cookie := &http.Cookie{
+	log.Printf("✅ Login successful, redirecting to dashboard")
+	// Redirect to dashboard
+	http.Redirect(w, r, "/auth/dashboard", http.StatusSeeOther)
+}
+
+// StartSession creates a session for user and returns the HTTP-only auth cookie that
+// carries its token
+func StartSession(ctx context.Context, user User) (*http.Cookie, error) {
+	token, err := sessions.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Cookie{
 		Name:     "auth_token",
-		Value:    tokenString,
+		Value:    token,
 		Path:     "/",
-		MaxAge:   24 * 60 * 60, // 24 hours
+		MaxAge:   int(SessionTTL.Seconds()),
 		HttpOnly: true,
 		Secure:   false, // Set to true in production with HTTPS
 		SameSite: http.SameSiteLaxMode,
-	}
-	http.SetCookie(w, cookie)
-
-	log.Printf("✅ Login successful, redirecting to dashboard")
-	// Redirect to dashboard
-	http.Redirect(w, r, "/auth/dashboard", http.StatusSeeOther)
+	}, nil
 }
 
 // handleDashboard renders the protected dashboard page
@@ -319,85 +243,20 @@ func handleDashboard(w http.ResponseWriter, r *http.Request) {
 
 	username := getUserFromToken(r)
 
-	dashboardTemplate := `
-<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Dashboard</title>
-    <script src="https://cdn.tailwindcss.com"></script>
-</head>
-<body class="bg-gray-100 min-h-screen">
-    <nav class="bg-white shadow-sm">
-        <div class="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8">
-            <div class="flex justify-between h-16">
-                <div class="flex items-center">
-                    <h1 class="text-xl font-semibold text-gray-900">Dashboard</h1>
-                </div>
-                <div class="flex items-center space-x-4">
-                    <span class="text-gray-700">Welcome, {{username}}!</span>
-                    <form method="POST" action="/auth/logout" class="inline">
-                        <button type="submit" 
-                                class="bg-red-600 text-white px-4 py-2 rounded-md hover:bg-red-700 focus:outline-none focus:ring-2 focus:ring-red-500 focus:ring-offset-2 transition duration-200">
-                            Logout
-                        </button>
-                    </form>
-                </div>
-            </div>
-        </div>
-    </nav>
-
-    <main class="max-w-7xl mx-auto py-6 sm:px-6 lg:px-8">
-        <div class="px-4 py-6 sm:px-0">
-            <div class="border-4 border-dashed border-gray-200 rounded-lg p-8">
-                <div class="text-center">
-                    <h2 class="text-3xl font-bold text-gray-900 mb-4">Protected Dashboard</h2>
-                    <p class="text-gray-600 mb-8">You are successfully logged in as <strong>{{username}}</strong></p>
-                    
-                    <div class="grid grid-cols-1 md:grid-cols-3 gap-6">
-                        <div class="bg-blue-50 p-6 rounded-lg">
-                            <div class="text-blue-600 text-2xl font-bold">42</div>
-                            <div class="text-gray-600">Total Users</div>
-                        </div>
-                        <div class="bg-green-50 p-6 rounded-lg">
-                            <div class="text-green-600 text-2xl font-bold">127</div>
-                            <div class="text-gray-600">Active Sessions</div>
-                        </div>
-                        <div class="bg-purple-50 p-6 rounded-lg">
-                            <div class="text-purple-600 text-2xl font-bold">89%</div>
-                            <div class="text-gray-600">Uptime</div>
-                        </div>
-                    </div>
-                </div>
-            </div>
-        </div>
-    </main>
-</body>
-</html>`
-
 	data := map[string]interface{}{
 		"username": username,
 	}
-
-	tmpl, err := raymond.Parse(dashboardTemplate)
-	if err != nil {
-		http.Error(w, "Template error", http.StatusInternalServerError)
-		return
-	}
-
-	html, err := tmpl.Exec(data)
-	if err != nil {
-		http.Error(w, "Template execution error", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "text/html")
-	w.Write([]byte(html))
+	renderAuthPage(w, r, "dashboard/get.html.hbs", data)
 }
 
-// handleLogout clears the authentication cookie
+// handleLogout ends the session and clears the authentication cookie
 func handleLogout(w http.ResponseWriter, r *http.Request) {
+	if existing, err := r.Cookie("auth_token"); err == nil {
+		if err := sessions.Destroy(r.Context(), existing.Value); err != nil {
+			log.Printf("⚠️ Failed to destroy session: %v", err)
+		}
+	}
+
 	cookie := &http.Cookie{
 		Name:     "auth_token",
 		Value:    "",
@@ -412,122 +271,133 @@ func handleLogout(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
 }
 
-// isAuthenticated checks if the request has a valid JWT token
+// IsAuthenticated checks if the request's auth cookie names a live session
 func IsAuthenticated(r *http.Request) bool {
-	cookie, err := r.Cookie("auth_token")
-	if err != nil {
-		return false
-	}
+	_, err := currentSession(r)
+	return err == nil
+}
 
-	token, err := jwt.Parse(cookie.Value, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtSecret, nil
-	})
-	if err != nil {
-		return false
+// RateLimitKey keys rate limits by the signed-in user, or by client IP for visitors
+func RateLimitKey(r *http.Request) string {
+	if session, err := currentSession(r); err == nil {
+		return fmt.Sprintf("user:%v", session.UserID)
 	}
+	return "ip:" + middleware.ClientIP(r)
+}
 
-	return token.Valid
+// CurrentUserID returns the id of the signed-in user, and false for visitors
+func CurrentUserID(r *http.Request) (int64, bool) {
+	session, err := currentSession(r)
+	if err != nil || session.UserID == 0 {
+		return 0, false
+	}
+	return int64(session.UserID), true
 }
 
-// getUserFromToken extracts the username from the JWT token
+// getUserFromToken returns the username of the request's session
 func getUserFromToken(r *http.Request) string {
-	cookie, err := r.Cookie("auth_token")
+	session, err := currentSession(r)
 	if err != nil {
 		return ""
 	}
+	return session.Username
+}
 
-	token, err := jwt.Parse(cookie.Value, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+// currentSession looks up the session named by the request's auth cookie
+func currentSession(r *http.Request) (*Session, error) {
+	cookie, err := r.Cookie("auth_token")
 	if err != nil {
-		return ""
+		return nil, ErrNoSession
 	}
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		if username, ok := claims["username"].(string); ok {
-			return username
-		}
-	}
-
-	return ""
+	return sessions.Get(r.Context(), cookie.Value)
 }
 
-// tryRegisterRoute attempts to register a route, but gracefully handles conflicts
-func tryRegisterRoute(mux *http.ServeMux, pattern string, handler func(http.ResponseWriter, *http.Request)) {
+// tryRegisterRoute attempts to register a route, but gracefully handles conflicts.
+// It reports whether the route was registered.
+func tryRegisterRoute(mux *http.ServeMux, pattern string, handler func(http.ResponseWriter, *http.Request)) (registered bool) {
 	defer func() {
 		if r := recover(); r != nil {
-			// Handle different panic types that could come from ServeMux
-			var errStr string
-			switch v := r.(type) {
-			case string:
-				errStr = v
-			case error:
-				errStr = v.Error()
-			default:
-				errStr = fmt.Sprintf("%v", r)
-			}
+			errStr := middleware.PanicMessage(r)
 
 			// Check if this is a route conflict panic
 			if strings.Contains(errStr, "conflicts with pattern") {
 				log.Printf("⚠️ Route %s already registered, skipping manual registration", pattern)
+				registered = false
 				return
 			}
 			panic(r) // Re-panic if it's not a route conflict
 		}
 	}()
-	mux.HandleFunc(pattern, handler)
+	mux.HandleFunc(pattern, middleware.RecoverFunc(handler, false))
 	log.Printf("✅ Manually registered auth route: %s", pattern)
+	return true
+}
+
+// authRoute is a route AddLoginRoute registers
+type authRoute struct {
+	pattern string
+	handler http.HandlerFunc
 }
 
+// AddLoginRoute registers the built-in auth routes. Routes a domain already serves,
+// e.g. domains/auth/login/get.html.hbs, are skipped so the domain's version wins.
 func AddLoginRoute(mux *http.ServeMux, fs *lang_adapters.FrameworkServer) {
-	// New /auth prefixed routes
-	// Note: We defer to manual registration since auth routes need special handling
-	tryRegisterRoute(mux, "GET /auth/login", handleLoginPage)
-	mux.HandleFunc("POST /auth/login", func(w http.ResponseWriter, r *http.Request) {
+	loginSubmit := func(w http.ResponseWriter, r *http.Request) {
 		handleLoginSubmit(w, r, fs)
-	})
-	tryRegisterRoute(mux, "GET /auth/register", handleRegisterPage)
-	mux.HandleFunc("POST /auth/register", func(w http.ResponseWriter, r *http.Request) {
+	}
+	registerSubmit := func(w http.ResponseWriter, r *http.Request) {
 		handleRegisterSubmit(w, r, fs)
-	})
-	tryRegisterRoute(mux, "GET /auth/dashboard", handleDashboard)
-	mux.HandleFunc("POST /auth/logout", handleLogout)
-
-	// Backward compatibility redirects for old URLs
-	mux.HandleFunc("GET /login", func(w http.ResponseWriter, r *http.Request) {
-		// Preserve query parameters (like error messages)
-		query := r.URL.RawQuery
-		redirectURL := "/auth/login"
-		if query != "" {
-			redirectURL += "?" + query
-		}
-		http.Redirect(w, r, redirectURL, http.StatusMovedPermanently)
-	})
-	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
-		// For POST requests, we need to redirect but preserve the form data
+	}
+
+	routes := []authRoute{
+		// New /auth prefixed routes
+		{"GET /auth/login", handleLoginPage},
+		{"POST /auth/login", loginSubmit},
+		{"GET /auth/register", handleRegisterPage},
+		{"POST /auth/register", registerSubmit},
+		{"GET /auth/dashboard", handleDashboard},
+		{"POST /auth/logout", handleLogout},
+
+		// Backward compatibility redirects for old URLs
+		{"GET /login", redirectWithQuery("/auth/login")},
 		// Since we can't preserve POST data in a redirect, we'll handle the login here
-		handleLoginSubmit(w, r, fs)
-	})
-	mux.HandleFunc("GET /register", func(w http.ResponseWriter, r *http.Request) {
-		query := r.URL.RawQuery
-		redirectURL := "/auth/register"
-		if query != "" {
+		{"POST /login", loginSubmit},
+		{"GET /register", redirectWithQuery("/auth/register")},
+		{"POST /register", registerSubmit},
+		{"GET /dashboard", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/auth/dashboard", http.StatusMovedPermanently)
+		}},
+		{"POST /logout", handleLogout},
+	}
+
+	registeredRoutes := make(map[string]bool)
+	var skipped []string
+	for _, route := range routes {
+		if registeredRoutes[route.pattern] {
+			continue
+		}
+		if tryRegisterRoute(mux, route.pattern, route.handler) {
+			registeredRoutes[route.pattern] = true
+		} else {
+			skipped = append(skipped, route.pattern)
+		}
+	}
+
+	if len(skipped) > 0 {
+		log.Printf("⚠️ Skipped %d built-in auth routes already served by domain routes: %s", len(skipped), strings.Join(skipped, ", "))
+	}
+}
+
+// redirectWithQuery permanently redirects to target, preserving query parameters
+// (like error messages)
+func redirectWithQuery(target string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		redirectURL := target
+		if query := r.URL.RawQuery; query != "" {
 			redirectURL += "?" + query
 		}
 		http.Redirect(w, r, redirectURL, http.StatusMovedPermanently)
-	})
-	mux.HandleFunc("POST /register", func(w http.ResponseWriter, r *http.Request) {
-		handleRegisterSubmit(w, r, fs)
-	})
-	mux.HandleFunc("GET /dashboard", func(w http.ResponseWriter, r *http.Request) {
-		http.Redirect(w, r, "/auth/dashboard", http.StatusMovedPermanently)
-	})
-	mux.HandleFunc("POST /logout", func(w http.ResponseWriter, r *http.Request) {
-		handleLogout(w, r)
-	})
+	}
 }
 
 func handleRegisterPage(w http.ResponseWriter, r *http.Request) {
@@ -548,91 +418,17 @@ func handleRegisterPage(w http.ResponseWriter, r *http.Request) {
 		data["success"] = successMsg
 	}
 
-	// Try to load dynamic template, fallback to hardcoded if needed
-	html, err := loadAuthTemplate("register/get.html.hbs", data)
-	if err != nil {
-		log.Printf("⚠️ Failed to load dynamic register template, using fallback: %v", err)
-		// Fallback to hardcoded template
-		registerTemplate := `
-<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Register</title>
-    <script src="https://cdn.tailwindcss.com"></script>
-</head>
-<body class="bg-gray-100 min-h-screen flex items-center justify-center">
-    <div class="bg-white p-8 rounded-lg shadow-md w-full max-w-md">
-        <h2 class="text-2xl font-bold text-center text-gray-800 mb-6">Create Account</h2>
-        
-        {{#if error}}
-        <div class="bg-red-100 border border-red-400 text-red-700 px-4 py-3 rounded mb-4">
-            {{error}}
-        </div>
-        {{/if}}
-
-        {{#if success}}
-        <div class="bg-green-100 border border-green-400 text-green-700 px-4 py-3 rounded mb-4">
-            {{success}}
-        </div>
-        {{/if}}
-
-        <form method="POST" action="/auth/register" class="space-y-4">
-            <div>
-                <label for="email" class="block text-sm font-medium text-gray-700 mb-1">Email</label>
-                <input type="email" id="email" name="email" required 
-                       class="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-transparent">
-            </div>
-            
-            <div>
-                <label for="password" class="block text-sm font-medium text-gray-700 mb-1">Password</label>
-                <input type="password" id="password" name="password" required minlength="6"
-                       class="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-transparent">
-                <p class="text-xs text-gray-500 mt-1">Minimum 6 characters</p>
-            </div>
-            
-            <div>
-                <label for="confirm_password" class="block text-sm font-medium text-gray-700 mb-1">Confirm Password</label>
-                <input type="password" id="confirm_password" name="confirm_password" required 
-                       class="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-transparent">
-            </div>
-            
-            <button type="submit" 
-			class="w-full bg-green-600 text-white py-2 px-4 rounded-md hover:bg-green-700 focus:outline-none focus:ring-2 focus:ring-green-500 focus:ring-offset-2 transition duration-200">
-                Create Account
-            </button>
-        </form>
-        
-        <div class="mt-6 text-center">
-            <p class="text-sm text-gray-600">
-                Already have an account? 
-                <a href="/auth/login" class="text-blue-600 hover:text-blue-700 font-medium">Sign in</a>
-            </p>
-        </div>
-    </div>
-</body>
-</html>`
-
-		tmpl, err := raymond.Parse(registerTemplate)
-		if err != nil {
-			http.Error(w, "Template error", http.StatusInternalServerError)
-			return
-		}
-
-		html, err = tmpl.Exec(data)
-		if err != nil {
-			http.Error(w, "Template execution error", http.StatusInternalServerError)
-			return
-		}
-	}
-
-	w.Header().Set("Content-Type", "text/html")
-	w.Write([]byte(html))
+	renderAuthPage(w, r, "register/get.html.hbs", data)
 }
 
 // handleRegisterSubmit processes the registration form submission
 func handleRegisterSubmit(w http.ResponseWriter, r *http.Request, fs *lang_adapters.FrameworkServer) {
+	if fs == nil || fs.DbExecutor == nil {
+		log.Printf("❌ Registration needs a database connection; check the db section of fulcrum.yml")
+		http.Redirect(w, r, "/auth/register?error=Registration+is+unavailable+without+a+database", http.StatusSeeOther)
+		return
+	}
+
 	email := r.FormValue("email")
 	password := r.FormValue("password")
 	confirmPassword := r.FormValue("confirm_password")
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lang_adapters "fulcrum/lib/lang/adapters"
+)
+
+func TestAddLoginRouteSkipsDomainRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /auth/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("domain login page"))
+	})
+
+	AddLoginRoute(mux, nil)
+	// Registering twice must not panic either
+	AddLoginRoute(mux, nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	if body := rec.Body.String(); body != "domain login page" {
+		t.Errorf("Expected the domain route to serve GET /auth/login, got %q", body)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login?error=bad", nil))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/auth/login?error=bad" {
+		t.Errorf("Expected GET /login to redirect to /auth/login with its query, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestLoginPageTemplates(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	handler := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handleLoginPage(rec, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+		return rec
+	}
+
+	// Without domains/auth the embedded default is rendered
+	rec := handler()
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `action="/auth/login"`) {
+		t.Fatalf("Expected the default login page, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	projectTemplate := filepath.Join("domains", "auth", "login", "get.html.hbs")
+	if err := os.MkdirAll(filepath.Dir(projectTemplate), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(projectTemplate, []byte("<h1>Custom login</h1>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec = handler()
+	if body := rec.Body.String(); body != "<h1>Custom login</h1>" {
+		t.Errorf("Expected the project's login template, got %q", body)
+	}
+}
+
+func TestAuthWithoutDatabase(t *testing.T) {
+	tests := []struct {
+		name     string
+		submit   func(w http.ResponseWriter, r *http.Request)
+		path     string
+		form     string
+		location string
+	}{
+		{
+			name:     "no dev credentials sign in",
+			submit:   func(w http.ResponseWriter, r *http.Request) { handleLoginSubmit(w, r, &lang_adapters.FrameworkServer{}) },
+			path:     "/auth/login",
+			form:     "username=admin&password=password123",
+			location: "/auth/login?error=",
+		},
+		{
+			name:     "registration",
+			submit:   func(w http.ResponseWriter, r *http.Request) { handleRegisterSubmit(w, r, nil) },
+			path:     "/auth/register",
+			form:     "email=a@example.com&password=secret&confirm_password=secret",
+			location: "/auth/register?error=",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.form))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+			tt.submit(rec, r)
+
+			if rec.Code != http.StatusSeeOther || !strings.HasPrefix(rec.Header().Get("Location"), tt.location) {
+				t.Errorf("Expected a redirect to %s..., got %d %q", tt.location, rec.Code, rec.Header().Get("Location"))
+			}
+			for _, cookie := range rec.Result().Cookies() {
+				t.Errorf("Expected no session without a database, got cookie %s", cookie.Name)
+			}
+		})
+	}
+}
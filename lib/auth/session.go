@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"fulcrum/lib/database"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SessionTTL is how long a login session lasts
+const SessionTTL = 24 * time.Hour
+
+// ErrNoSession is returned by SessionStore.Get for unknown, expired or revoked sessions
+var ErrNoSession = errors.New("no session")
+
+// Session is the signed-in user a session token identifies
+type Session struct {
+	Username string
+	UserID   float64
+	Expires  time.Time
+}
+
+// SessionStore creates, looks up and ends login sessions. The token Create returns is
+// stored in the auth cookie.
+type SessionStore interface {
+	Create(ctx context.Context, user User) (string, error)
+	Get(ctx context.Context, token string) (*Session, error)
+	Destroy(ctx context.Context, token string) error
+}
+
+// Session store kinds accepted by auth.sessions in fulcrum.yml
+const (
+	SessionsJWT      = "jwt"
+	SessionsDatabase = "database"
+)
+
+// sessions is the store the auth handlers and IsAuthenticated use
+var sessions SessionStore = NewJWTSessionStore(jwtSecret)
+
+// SetSessionStore replaces the store used for login sessions
+func SetSessionStore(store SessionStore) {
+	sessions = store
+}
+
+// NewSessionStore returns the store for an auth.sessions kind; "" means jwt
+func NewSessionStore(kind string, executor *database.DatabaseExecutor) (SessionStore, error) {
+	switch kind {
+	case "", SessionsJWT:
+		return NewJWTSessionStore(jwtSecret), nil
+	case SessionsDatabase:
+		if executor == nil {
+			return nil, fmt.Errorf("database sessions need a database connection")
+		}
+		return NewDatabaseSessionStore(executor), nil
+	default:
+		return nil, fmt.Errorf("unknown session store %q (use jwt or database)", kind)
+	}
+}
+
+// JWTSessionStore keeps the session in a signed token, so nothing is stored server-side
+// and Destroy cannot revoke a token that was copied elsewhere
+type JWTSessionStore struct {
+	secret []byte
+}
+
+// NewJWTSessionStore creates a store that signs tokens with secret
+func NewJWTSessionStore(secret []byte) *JWTSessionStore {
+	return &JWTSessionStore{secret: secret}
+}
+
+// Create signs a token for user that expires after SessionTTL
+func (s *JWTSessionStore) Create(ctx context.Context, user User) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"Username": user.Username,
+		"UserId":   user.Id,
+		"exp":      now.Add(SessionTTL).Unix(),
+		"iat":      now.Unix(),
+	})
+	return token.SignedString(s.secret)
+}
+
+// Get verifies the token and returns the session it carries
+func (s *JWTSessionStore) Get(ctx context.Context, tokenString string) (*Session, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.secret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrNoSession
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrNoSession
+	}
+	session := &Session{}
+	session.Username, _ = claims["Username"].(string)
+	session.UserID, _ = claims["UserId"].(float64)
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		session.Expires = exp.Time
+	}
+	return session, nil
+}
+
+// Destroy does nothing; the cookie is cleared and the token expires on its own
+func (s *JWTSessionStore) Destroy(ctx context.Context, token string) error {
+	return nil
+}
+
+// DatabaseSessionStore keeps sessions in the sessions table behind an opaque token,
+// so logging out revokes the session everywhere
+type DatabaseSessionStore struct {
+	executor *database.DatabaseExecutor
+}
+
+// NewDatabaseSessionStore creates a store over the sessions table
+func NewDatabaseSessionStore(executor *database.DatabaseExecutor) *DatabaseSessionStore {
+	return &DatabaseSessionStore{executor: executor}
+}
+
+// Create stores a session for user under a random token, clearing expired sessions
+func (s *DatabaseSessionStore) Create(ctx context.Context, user User) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+	now := time.Now()
+
+	if _, err := s.execute(ctx, "DELETE FROM sessions WHERE expires_at <= :now", map[string]any{"now": now.Unix()}); err != nil {
+		return "", err
+	}
+	_, err := s.execute(ctx, "INSERT INTO sessions (token, username, user_id, expires_at) VALUES (:token, :username, :user_id, :expires_at)", map[string]any{
+		"token":      token,
+		"username":   user.Username,
+		"user_id":    user.Id,
+		"expires_at": now.Add(SessionTTL).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Get returns the unexpired session stored under token
+func (s *DatabaseSessionStore) Get(ctx context.Context, token string) (*Session, error) {
+	rows, err := s.execute(ctx, "SELECT username, user_id, expires_at FROM sessions WHERE token = :token AND expires_at > :now", map[string]any{
+		"token": token,
+		"now":   time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrNoSession
+	}
+
+	session := &Session{}
+	session.Username, _ = rows[0]["username"].(string)
+	session.UserID, _ = rows[0]["user_id"].(float64)
+	if expires, ok := rows[0]["expires_at"].(float64); ok {
+		session.Expires = time.Unix(int64(expires), 0)
+	}
+	return session, nil
+}
+
+// Destroy deletes the session stored under token
+func (s *DatabaseSessionStore) Destroy(ctx context.Context, token string) error {
+	_, err := s.execute(ctx, "DELETE FROM sessions WHERE token = :token", map[string]any{"token": token})
+	return err
+}
+
+// execute runs a statement and returns the rows it selected
+func (s *DatabaseSessionStore) execute(ctx context.Context, query string, params map[string]any) ([]map[string]any, error) {
+	resultJSON, err := s.executor.ExecuteSQL(ctx, query, params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("session query failed: %w", err)
+	}
+
+	var response database.OperationResponse
+	if err := json.Unmarshal(resultJSON, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse session query response: %w", err)
+	}
+	if !response.Success {
+		return nil, fmt.Errorf("session query failed: %s", response.Error)
+	}
+	return response.Data, nil
+}
@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fulcrum/lib/database"
+	"fulcrum/lib/database/drivers"
+	"fulcrum/lib/database/interfaces"
+)
+
+// newSQLiteDB connects to a fresh SQLite database, closed when the test ends, and runs
+// statements on it
+func newSQLiteDB(t *testing.T, statements ...string) interfaces.Database {
+	t.Helper()
+	db, err := drivers.NewSQLiteDB(interfaces.Config{FilePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, statement := range statements {
+		if _, err := db.Exec(context.Background(), statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// newSessionsExecutor returns an executor over a fresh SQLite database with a sessions table
+func newSessionsExecutor(t *testing.T) (*database.DatabaseExecutor, interfaces.Database) {
+	t.Helper()
+	db := newSQLiteDB(t, "CREATE TABLE sessions (token VARCHAR(64) PRIMARY KEY, username VARCHAR(255) NOT NULL, user_id INTEGER NOT NULL, expires_at BIGINT NOT NULL)")
+	return database.NewDatabaseExecutor(db), db
+}
+
+func TestSessionStores(t *testing.T) {
+	executor, _ := newSessionsExecutor(t)
+
+	tests := []struct {
+		name        string
+		store       SessionStore
+		destroyEnds bool
+		otherStore  SessionStore
+	}{
+		{"jwt", NewJWTSessionStore([]byte("test-secret")), false, NewJWTSessionStore([]byte("other-secret"))},
+		{"database", NewDatabaseSessionStore(executor), true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			token, err := tt.store.Create(ctx, User{Id: 7, Username: "ada"})
+			if err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+
+			session, err := tt.store.Get(ctx, token)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if session.Username != "ada" || session.UserID != 7 {
+				t.Errorf("Expected session for ada (7), got %s (%v)", session.Username, session.UserID)
+			}
+			if until := time.Until(session.Expires); until <= 0 || until > SessionTTL {
+				t.Errorf("Expected the session to expire within %v, got %v", SessionTTL, session.Expires)
+			}
+
+			if _, err := tt.store.Get(ctx, "not-a-token"); !errors.Is(err, ErrNoSession) {
+				t.Errorf("Expected ErrNoSession for an unknown token, got %v", err)
+			}
+			if tt.otherStore != nil {
+				if _, err := tt.otherStore.Get(ctx, token); !errors.Is(err, ErrNoSession) {
+					t.Errorf("Expected ErrNoSession for a token signed with another secret, got %v", err)
+				}
+			}
+
+			if err := tt.store.Destroy(ctx, token); err != nil {
+				t.Fatalf("Destroy failed: %v", err)
+			}
+			_, err = tt.store.Get(ctx, token)
+			if ended := errors.Is(err, ErrNoSession); ended != tt.destroyEnds {
+				t.Errorf("Expected Destroy to end the session: %v, got error %v", tt.destroyEnds, err)
+			}
+		})
+	}
+}
+
+func TestDatabaseSessionStoreExpiry(t *testing.T) {
+	executor, db := newSessionsExecutor(t)
+	store := NewDatabaseSessionStore(executor)
+	ctx := context.Background()
+
+	if _, err := db.Exec(ctx, "INSERT INTO sessions (token, username, user_id, expires_at) VALUES ('stale', 'ada', 7, ?)", time.Now().Add(-time.Minute).Unix()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "stale"); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Expected ErrNoSession for an expired session, got %v", err)
+	}
+
+	// Creating a session clears expired ones
+	if _, err := store.Create(ctx, User{Id: 8, Username: "grace"}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query(ctx, "SELECT token FROM sessions WHERE token = 'stale'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		t.Error("Expected the expired session to be deleted")
+	}
+}
+
+func TestLogoutRevokesDatabaseSession(t *testing.T) {
+	executor, _ := newSessionsExecutor(t)
+	previous := sessions
+	SetSessionStore(NewDatabaseSessionStore(executor))
+	t.Cleanup(func() { SetSessionStore(previous) })
+
+	token, err := sessions.Create(context.Background(), User{Id: 7, Username: "ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withCookie := func(r *http.Request) *http.Request {
+		r.AddCookie(&http.Cookie{Name: "auth_token", Value: token})
+		return r
+	}
+
+	if !IsAuthenticated(withCookie(httptest.NewRequest(http.MethodGet, "/", nil))) {
+		t.Fatal("Expected the session cookie to authenticate")
+	}
+
+	handleLogout(httptest.NewRecorder(), withCookie(httptest.NewRequest(http.MethodPost, "/auth/logout", nil)))
+
+	if IsAuthenticated(withCookie(httptest.NewRequest(http.MethodGet, "/", nil))) {
+		t.Error("Expected the session to be revoked after logout")
+	}
+}
+
+func TestRateLimitKey(t *testing.T) {
+	previous := sessions
+	SetSessionStore(NewJWTSessionStore([]byte("test-secret")))
+	t.Cleanup(func() { SetSessionStore(previous) })
+
+	token, err := sessions.Create(context.Background(), User{Id: 7, Username: "ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedIn := httptest.NewRequest(http.MethodGet, "/", nil)
+	signedIn.AddCookie(&http.Cookie{Name: "auth_token", Value: token})
+	if key := RateLimitKey(signedIn); key != "user:7" {
+		t.Errorf("Expected signed-in requests to be keyed by user, got %q", key)
+	}
+	if id, ok := CurrentUserID(signedIn); !ok || id != 7 {
+		t.Errorf("Expected user 7, got %d (%v)", id, ok)
+	}
+
+	visitor := httptest.NewRequest(http.MethodGet, "/", nil)
+	visitor.AddCookie(&http.Cookie{Name: "auth_token", Value: "forged"})
+	if key := RateLimitKey(visitor); key != "ip:192.0.2.1" {
+		t.Errorf("Expected visitors to be keyed by IP, got %q", key)
+	}
+	if _, ok := CurrentUserID(visitor); ok {
+		t.Error("Expected visitors to have no user id")
+	}
+}
+
+func TestNewSessionStore(t *testing.T) {
+	executor, _ := newSessionsExecutor(t)
+
+	tests := []struct {
+		kind     string
+		executor *database.DatabaseExecutor
+		want     string
+		wantErr  bool
+	}{
+		{"", nil, "jwt", false},
+		{SessionsJWT, nil, "jwt", false},
+		{SessionsDatabase, executor, "database", false},
+		{SessionsDatabase, nil, "", true},
+		{"redis", executor, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			store, err := NewSessionStore(tt.kind, tt.executor)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			switch store.(type) {
+			case *JWTSessionStore:
+				if tt.want != "jwt" {
+					t.Errorf("Expected %q, got a JWT store", tt.want)
+				}
+			case *DatabaseSessionStore:
+				if tt.want != "database" {
+					t.Errorf("Expected %q, got a database store", tt.want)
+				}
+			}
+		})
+	}
+}
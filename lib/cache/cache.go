@@ -0,0 +1,120 @@
+// Package cache holds the results of cached SQL routes. MemoryStore suits a single
+// server; a shared store such as Redis can implement Store to cache across servers.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries bounds a MemoryStore when no size is configured
+const DefaultMaxEntries = 1000
+
+// Store keeps values under keys for a time. Entries are tagged, e.g. with their
+// domain, so every entry with a tag can be invalidated at once.
+type Store interface {
+	// Get returns the value stored under key, unless it is missing or expired
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set stores value under key for ttl, tagged with tags
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+	// Invalidate removes the entries stored under any of names or tagged with one
+	Invalidate(ctx context.Context, names ...string) error
+}
+
+// MemoryStore keeps up to a fixed number of entries, evicting the least recently used
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	recency    *list.List // Of *memoryEntry, most recently used first
+	now        func() time.Time
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+	tags    []string
+}
+
+// NewMemoryStore returns an empty store holding at most maxEntries entries, or
+// DefaultMaxEntries when maxEntries is not positive
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		recency:    list.New(),
+		now:        time.Now,
+	}
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if !s.now().Before(entry.expires) {
+		s.remove(element)
+		return nil, false, nil
+	}
+	s.recency.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set implements Store
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := &memoryEntry{key: key, value: value, expires: s.now().Add(ttl), tags: tags}
+	if element, ok := s.entries[key]; ok {
+		element.Value = entry
+		s.recency.MoveToFront(element)
+		return nil
+	}
+
+	s.entries[key] = s.recency.PushFront(entry)
+	for len(s.entries) > s.maxEntries {
+		s.remove(s.recency.Back())
+	}
+	return nil
+}
+
+// Invalidate implements Store
+func (s *MemoryStore) Invalidate(ctx context.Context, names ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for element := s.recency.Front(); element != nil; {
+		next := element.Next()
+		entry := element.Value.(*memoryEntry)
+		if slices.Contains(names, entry.key) || slices.ContainsFunc(entry.tags, func(tag string) bool { return slices.Contains(names, tag) }) {
+			s.remove(element)
+		}
+		element = next
+	}
+	return nil
+}
+
+// Len returns the number of entries held, including expired ones not yet removed
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+func (s *MemoryStore) remove(element *list.Element) {
+	s.recency.Remove(element)
+	delete(s.entries, element.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	newStore := func(maxEntries int) *MemoryStore {
+		store := NewMemoryStore(maxEntries)
+		store.now = func() time.Time { return now }
+		return store
+	}
+	get := func(store *MemoryStore, key string) string {
+		value, ok, err := store.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			return "<miss>"
+		}
+		return string(value)
+	}
+
+	t.Run("expires after ttl", func(t *testing.T) {
+		store := newStore(10)
+		store.Set(ctx, "users", []byte("rows"), time.Minute)
+		if got := get(store, "users"); got != "rows" {
+			t.Errorf("Expected a hit, got %s", got)
+		}
+		now = now.Add(time.Minute)
+		if got := get(store, "users"); got != "<miss>" {
+			t.Errorf("Expected the entry to expire, got %s", got)
+		}
+		if store.Len() != 0 {
+			t.Errorf("Expected the expired entry to be removed, %d left", store.Len())
+		}
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		store := newStore(2)
+		store.Set(ctx, "a", []byte("1"), time.Minute)
+		store.Set(ctx, "b", []byte("2"), time.Minute)
+		get(store, "a")
+		store.Set(ctx, "c", []byte("3"), time.Minute)
+
+		for key, expected := range map[string]string{"a": "1", "b": "<miss>", "c": "3"} {
+			if got := get(store, key); got != expected {
+				t.Errorf("Expected %s for %s, got %s", expected, key, got)
+			}
+		}
+	})
+
+	t.Run("invalidates by tag and key", func(t *testing.T) {
+		store := newStore(10)
+		store.Set(ctx, "users:index", []byte("1"), time.Minute, "users")
+		store.Set(ctx, "users:show", []byte("2"), time.Minute, "users")
+		store.Set(ctx, "posts:index", []byte("3"), time.Minute, "posts")
+		store.Set(ctx, "posts:show", []byte("4"), time.Minute, "posts")
+
+		store.Invalidate(ctx, "users", "posts:show")
+		for key, expected := range map[string]string{"users:index": "<miss>", "users:show": "<miss>", "posts:index": "3", "posts:show": "<miss>"} {
+			if got := get(store, key); got != expected {
+				t.Errorf("Expected %s for %s, got %s", expected, key, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fulcrum/lib/database/interfaces"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sqliteSidecars are the files SQLite keeps next to a database file
+var sqliteSidecars = []string{"-journal", "-wal", "-shm"}
+
+// CreateDatabase creates the database the config names: the file at FilePath for
+// SQLite, or a CREATE DATABASE run on the server's maintenance database. It reports
+// false when the database already exists.
+func CreateDatabase(ctx context.Context, config interfaces.Config) (bool, error) {
+	config, err := resolveURL(config)
+	if err != nil {
+		return false, err
+	}
+
+	if config.Driver == interfaces.DriverSQLite {
+		path, err := sqliteFile(config)
+		if err != nil {
+			return false, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return false, fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if errors.Is(err, os.ErrExist) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		return true, file.Close()
+	}
+
+	return onMaintenanceDatabase(ctx, config, func(db interfaces.Database) (bool, error) {
+		exists, err := serverDatabaseExists(ctx, db, config)
+		if err != nil || exists {
+			return false, err
+		}
+		if _, err := db.Exec(ctx, "CREATE DATABASE "+quoteDatabaseName(config)); err != nil {
+			return false, fmt.Errorf("failed to create database %s: %w", config.Database, err)
+		}
+		return true, nil
+	})
+}
+
+// DropDatabase drops the database the config names, removing the file at FilePath
+// for SQLite. It reports false when there was no database to drop.
+func DropDatabase(ctx context.Context, config interfaces.Config) (bool, error) {
+	config, err := resolveURL(config)
+	if err != nil {
+		return false, err
+	}
+
+	if config.Driver == interfaces.DriverSQLite {
+		path, err := sqliteFile(config)
+		if err != nil {
+			return false, err
+		}
+		err = os.Remove(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		for _, suffix := range sqliteSidecars {
+			if err := os.Remove(path + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return true, fmt.Errorf("failed to remove %s%s: %w", path, suffix, err)
+			}
+		}
+		return true, nil
+	}
+
+	return onMaintenanceDatabase(ctx, config, func(db interfaces.Database) (bool, error) {
+		exists, err := serverDatabaseExists(ctx, db, config)
+		if err != nil || !exists {
+			return false, err
+		}
+		if _, err := db.Exec(ctx, "DROP DATABASE "+quoteDatabaseName(config)); err != nil {
+			return false, fmt.Errorf("failed to drop database %s: %w", config.Database, err)
+		}
+		return true, nil
+	})
+}
+
+// sqliteFile returns the database file of a SQLite config without its DSN options
+func sqliteFile(config interfaces.Config) (string, error) {
+	path, _, _ := strings.Cut(strings.TrimPrefix(config.FilePath, "file:"), "?")
+	if path == "" || path == ":memory:" {
+		return "", fmt.Errorf("sqlite database %q is not a file", config.FilePath)
+	}
+	return path, nil
+}
+
+// maintenanceDatabase returns the database to connect to while the config's own
+// database is created or dropped
+func maintenanceDatabase(driver interfaces.DatabaseDriver) string {
+	if driver == interfaces.DriverPostgreSQL {
+		return "postgres"
+	}
+	// MySQL connects to the server without selecting a database
+	return ""
+}
+
+// onMaintenanceDatabase runs fn connected to the server's maintenance database
+func onMaintenanceDatabase(ctx context.Context, config interfaces.Config, fn func(interfaces.Database) (bool, error)) (bool, error) {
+	if config.Database == "" {
+		return false, fmt.Errorf("no database name configured")
+	}
+
+	maintenance := config
+	maintenance.URL = ""
+	maintenance.Database = maintenanceDatabase(config.Driver)
+	maintenance.MaxOpenConns = 1
+
+	manager, err := NewManager(maintenance)
+	if err != nil {
+		return false, err
+	}
+	if err := manager.Connect(ctx); err != nil {
+		return false, err
+	}
+	defer manager.Close()
+
+	return fn(manager.GetDatabase())
+}
+
+// serverDatabaseExists reports whether the config's database exists on the server
+func serverDatabaseExists(ctx context.Context, db interfaces.Database, config interfaces.Config) (bool, error) {
+	query := "SELECT COUNT(*) FROM pg_database WHERE datname = $1"
+	if config.Driver == interfaces.DriverMySQL {
+		query = "SELECT COUNT(*) FROM information_schema.schemata WHERE schema_name = ?"
+	}
+
+	var count int
+	if err := db.QueryRow(ctx, query, config.Database).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to look up database %s: %w", config.Database, err)
+	}
+	return count > 0, nil
+}
+
+// quoteDatabaseName quotes the config's database name as an identifier
+func quoteDatabaseName(config interfaces.Config) string {
+	if config.Driver == interfaces.DriverMySQL {
+		return "`" + strings.ReplaceAll(config.Database, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(config.Database, `"`, `""`) + `"`
+}
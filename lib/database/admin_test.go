@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fulcrum/lib/database/interfaces"
+)
+
+func TestCreateAndDropSQLiteDatabase(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data", "app.db")
+	config := interfaces.Config{Driver: interfaces.DriverSQLite, FilePath: path + "?cache=shared"}
+
+	steps := []struct {
+		name     string
+		run      func(context.Context, interfaces.Config) (bool, error)
+		expected bool
+		exists   bool
+	}{
+		{name: "create", run: CreateDatabase, expected: true, exists: true},
+		{name: "create existing", run: CreateDatabase, expected: false, exists: true},
+		{name: "drop", run: DropDatabase, expected: true, exists: false},
+		{name: "drop missing", run: DropDatabase, expected: false, exists: false},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if step.name == "drop" {
+				if err := os.WriteFile(path+"-wal", nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			changed, err := step.run(ctx, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != step.expected {
+				t.Errorf("Expected %v, got %v", step.expected, changed)
+			}
+			if _, err := os.Stat(path); (err == nil) != step.exists {
+				t.Errorf("Expected the file to exist: %v, got %v", step.exists, err)
+			}
+			if _, err := os.Stat(path + "-wal"); err == nil && !step.exists {
+				t.Errorf("Expected the -wal file to be removed")
+			}
+		})
+	}
+}
+
+func TestCreateDatabaseRejectsInMemorySQLite(t *testing.T) {
+	_, err := CreateDatabase(context.Background(), interfaces.Config{Driver: interfaces.DriverSQLite, FilePath: ":memory:"})
+	if err == nil {
+		t.Fatal("Expected an error for an in-memory database")
+	}
+}
+
+func TestQuoteDatabaseName(t *testing.T) {
+	tests := []struct {
+		driver   interfaces.DatabaseDriver
+		name     string
+		expected string
+	}{
+		{interfaces.DriverPostgreSQL, "app_dev", `"app_dev"`},
+		{interfaces.DriverPostgreSQL, `we"ird`, `"we""ird"`},
+		{interfaces.DriverMySQL, "app`dev", "`app``dev`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.driver)+" "+tt.name, func(t *testing.T) {
+			if got := quoteDatabaseName(interfaces.Config{Driver: tt.driver, Database: tt.name}); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
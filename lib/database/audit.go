@@ -0,0 +1,318 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// AuditTable is the table audited changes are recorded in; fulcrum generate audit writes
+// the migration that creates it
+const AuditTable = "audit_log"
+
+// Actions recorded in audit_log
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditSQL     = "sql" // A write run through ExecuteSQL, recorded without row images
+)
+
+// auditContextKey is the context key of the request's auditSource
+type auditContextKey struct{}
+
+// auditSource is who made the changes of a request, and which request it was
+type auditSource struct {
+	actor     string
+	requestID string
+}
+
+// WithAuditActor returns a copy of ctx whose audited changes are attributed to actor,
+// typically the signed-in user's id, and to the request requestID. Either may be empty.
+func WithAuditActor(ctx context.Context, actor, requestID string) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, auditSource{actor: actor, requestID: requestID})
+}
+
+// AuditEntry is one change recorded in audit_log
+type AuditEntry struct {
+	ID        any            `json:"id"`
+	ActorID   string         `json:"actor_id,omitempty"`
+	Table     string         `json:"table"`
+	RecordID  string         `json:"record_id,omitempty"`
+	Action    string         `json:"action"`
+	Before    map[string]any `json:"before,omitempty"`
+	After     map[string]any `json:"after,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	CreatedAt any            `json:"created_at"`
+}
+
+// AuditQuery selects audit_log entries; empty fields match every entry
+type AuditQuery struct {
+	Table    string
+	RecordID string
+	ActorID  string
+	Limit    int // Defaults to 100
+}
+
+// errChangeFailed rolls back an audited change whose statement failed
+var errChangeFailed = errors.New("change failed")
+
+// writeStatementPattern finds the table an INSERT, UPDATE or DELETE statement writes to
+var writeStatementPattern = regexp.MustCompile("(?is)^\\s*(?:INSERT\\s+INTO|UPDATE|DELETE\\s+FROM)\\s+[\"`]?([A-Za-z_][A-Za-z0-9_]*)")
+
+// EnableAudit records every change made through the executor to tables in audit_log,
+// with the row's before and after images. It fails when audit_log does not exist, as
+// the changes could not be recorded.
+func (de *DatabaseExecutor) EnableAudit(ctx context.Context, tables []string) error {
+	if len(tables) == 0 {
+		return nil
+	}
+	exists, err := de.db.TableExists(ctx, AuditTable)
+	if err != nil {
+		return fmt.Errorf("failed to check for the %s table: %w", AuditTable, err)
+	}
+	if !exists {
+		return fmt.Errorf("no %s table; run fulcrum generate audit and fulcrum migrate up", AuditTable)
+	}
+
+	de.audit = make(map[string]bool, len(tables))
+	for _, table := range tables {
+		de.audit[table] = true
+	}
+	return nil
+}
+
+// audits reports whether changes to table are recorded
+func (de *DatabaseExecutor) audits(table string) bool {
+	return de.audit[table] && table != AuditTable
+}
+
+// auditedCreate runs an insert in a transaction with the audit_log entry of each record
+// it returns. The after image is the row read back by its id, with the defaults the
+// database filled in, or the inserted values when the driver does not report the id.
+func (de *DatabaseExecutor) auditedCreate(ctx context.Context, table string, create func(tx *DatabaseExecutor) OperationResponse) OperationResponse {
+	return de.audited(ctx, func(tx *DatabaseExecutor) (OperationResponse, error) {
+		response := create(tx)
+		if !response.Success {
+			return response, errChangeFailed
+		}
+		for _, record := range response.Data {
+			after := record
+			if id, ok := record["id"]; ok {
+				rows, err := tx.auditRows(ctx, table, "id = "+tx.placeholder(1), []any{id})
+				if err != nil {
+					return response, err
+				}
+				if len(rows) > 0 {
+					after = rows[0]
+				}
+			}
+			if err := tx.recordChange(ctx, table, AuditCreate, record["id"], nil, after); err != nil {
+				return response, err
+			}
+		}
+		return response, nil
+	})
+}
+
+// auditedChange runs change in a transaction, recording in audit_log the before image
+// of each row of table matching where and its after image, or none once deleted
+func (de *DatabaseExecutor) auditedChange(ctx context.Context, table, action, where string, args []any, change func(tx *DatabaseExecutor) OperationResponse) OperationResponse {
+	return de.audited(ctx, func(tx *DatabaseExecutor) (OperationResponse, error) {
+		before, err := tx.auditRows(ctx, table, where, args)
+		if err != nil {
+			return OperationResponse{}, err
+		}
+
+		response := change(tx)
+		if !response.Success {
+			return response, errChangeFailed
+		}
+
+		for _, row := range before {
+			after, err := tx.auditRows(ctx, table, "id = "+tx.placeholder(1), []any{row["id"]})
+			if err != nil {
+				return response, err
+			}
+			var afterImage map[string]any
+			if len(after) > 0 {
+				afterImage = after[0]
+			}
+			if err := tx.recordChange(ctx, table, action, row["id"], row, afterImage); err != nil {
+				return response, err
+			}
+		}
+		return response, nil
+	})
+}
+
+// audited runs change and its audit_log entries in one transaction, so a change is
+// never made without its record
+func (de *DatabaseExecutor) audited(ctx context.Context, change func(tx *DatabaseExecutor) (OperationResponse, error)) OperationResponse {
+	var response OperationResponse
+	err := de.WithTransaction(ctx, func(tx *DatabaseExecutor) error {
+		var err error
+		response, err = change(tx)
+		return err
+	})
+	if err != nil && !errors.Is(err, errChangeFailed) {
+		return OperationResponse{
+			Success: false,
+			Error:   "Audit failed: " + err.Error(),
+		}
+	}
+	return response
+}
+
+// auditRows reads the rows of table matching where, for before and after images
+func (de *DatabaseExecutor) auditRows(ctx context.Context, table, where string, args []any) ([]map[string]any, error) {
+	rows, err := de.queryUncached(ctx, fmt.Sprintf("SELECT * FROM %s WHERE %s", table, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s for the audit log: %w", table, err)
+	}
+	defer rows.Close()
+	return de.rowsToJSON(rows)
+}
+
+// recordChange writes one entry to audit_log, attributed to the actor and request of ctx
+func (de *DatabaseExecutor) recordChange(ctx context.Context, table, action string, recordID any, before, after map[string]any) error {
+	source, _ := ctx.Value(auditContextKey{}).(auditSource)
+
+	beforeJSON, err := auditImage(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditImage(after)
+	if err != nil {
+		return err
+	}
+	var id any
+	if recordID != nil {
+		id = fmt.Sprint(recordID)
+	}
+
+	placeholders := make([]string, 8)
+	for i := range placeholders {
+		placeholders[i] = de.placeholder(i + 1)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (actor_id, table_name, record_id, action, before_data, after_data, request_id, created_at) VALUES (%s)",
+		AuditTable, strings.Join(placeholders, ", "))
+	if _, err := de.execUncached(ctx, query, nullString(source.actor), table, id, action, beforeJSON, afterJSON, nullString(source.requestID), time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to write the audit log: %w", err)
+	}
+	return nil
+}
+
+// recordStatement records a write run through ExecuteSQL to an audited table, with the
+// statement and how many rows it affected. The statement has already run, so a failure
+// to record it is logged.
+func (de *DatabaseExecutor) recordStatement(ctx context.Context, statement string, affected int) {
+	match := writeStatementPattern.FindStringSubmatch(statement)
+	if match == nil || !de.audits(match[1]) {
+		return
+	}
+	change := map[string]any{"statement": statement, "affected": affected}
+	if err := de.recordChange(ctx, match[1], AuditSQL, nil, nil, change); err != nil {
+		log.Printf("⚠️ SQL write to %s was not audited: %v", match[1], err)
+	}
+}
+
+// AuditEntries returns the audit_log entries matching query, newest first
+func (de *DatabaseExecutor) AuditEntries(ctx context.Context, query AuditQuery) ([]AuditEntry, error) {
+	var conditions []string
+	var args []any
+	for column, value := range map[string]string{"table_name": query.Table, "record_id": query.RecordID, "actor_id": query.ActorID} {
+		if value != "" {
+			args = append(args, value)
+			conditions = append(conditions, column+" = "+de.placeholder(len(args)))
+		}
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	sqlQuery := fmt.Sprintf("SELECT id, actor_id, table_name, record_id, action, before_data, after_data, request_id, created_at FROM %s%s ORDER BY id DESC LIMIT %d",
+		AuditTable, where, limit)
+	rows, err := de.queryUncached(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	defer rows.Close()
+	data, err := de.rowsToJSON(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the audit log: %w", err)
+	}
+
+	entries := make([]AuditEntry, 0, len(data))
+	for _, row := range data {
+		entry := AuditEntry{
+			ID:        row["id"],
+			ActorID:   stringValue(row["actor_id"]),
+			Table:     stringValue(row["table_name"]),
+			RecordID:  stringValue(row["record_id"]),
+			Action:    stringValue(row["action"]),
+			RequestID: stringValue(row["request_id"]),
+			CreatedAt: row["created_at"],
+		}
+		if entry.Before, err = parseAuditImage(row["before_data"]); err != nil {
+			return nil, err
+		}
+		if entry.After, err = parseAuditImage(row["after_data"]); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// auditImage encodes a row image for audit_log; a missing image is NULL
+func auditImage(image map[string]any) (any, error) {
+	if image == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the audit image: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// parseAuditImage decodes a row image read back from audit_log
+func parseAuditImage(value any) (map[string]any, error) {
+	encoded := stringValue(value)
+	if encoded == "" {
+		return nil, nil
+	}
+	var image map[string]any
+	if err := json.Unmarshal([]byte(encoded), &image); err != nil {
+		return nil, fmt.Errorf("failed to decode the audit image: %w", err)
+	}
+	return image, nil
+}
+
+// nullString binds an empty string as NULL
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// stringValue formats a column value read back from the database, "" for NULL
+func stringValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
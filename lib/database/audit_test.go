@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+// newAuditExecutor returns an executor auditing the posts table, holding post 1
+func newAuditExecutor(t *testing.T) *DatabaseExecutor {
+	t.Helper()
+	executor, db := newSQLiteExecutor(t)
+	execAll(t, db,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT, status TEXT DEFAULT 'draft', deleted_at TIMESTAMP)",
+		"CREATE TABLE audit_log (id INTEGER PRIMARY KEY AUTOINCREMENT, actor_id VARCHAR(255), table_name VARCHAR(255) NOT NULL, record_id VARCHAR(255), action VARCHAR(32) NOT NULL, before_data TEXT, after_data TEXT, request_id VARCHAR(64), created_at TIMESTAMP NOT NULL)",
+		"INSERT INTO posts (id, title) VALUES (1, 'first')",
+	)
+	ctx := context.Background()
+	if err := executor.LoadSoftDeleteTables(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := executor.EnableAudit(ctx, []string{"posts"}); err != nil {
+		t.Fatal(err)
+	}
+	return executor
+}
+
+// auditEntries returns every entry in the audit log, newest first
+func auditEntries(t *testing.T, executor *DatabaseExecutor) []AuditEntry {
+	t.Helper()
+	entries, err := executor.AuditEntries(context.Background(), AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestAuditUpdateCapturesBeforeAndAfter(t *testing.T) {
+	executor := newAuditExecutor(t)
+	ctx := WithAuditActor(context.Background(), "42", "req-1")
+
+	if err := responseError(executor.UpdateRecord(ctx, "posts", 1, map[string]any{"title": "second"}, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := auditEntries(t, executor)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 audit entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Action != AuditUpdate || entry.Table != "posts" || entry.RecordID != "1" {
+		t.Errorf("Expected an update of posts 1, got %s of %s %s", entry.Action, entry.Table, entry.RecordID)
+	}
+	if entry.Before["title"] != "first" || entry.After["title"] != "second" {
+		t.Errorf("Expected title first before and second after, got %v and %v", entry.Before, entry.After)
+	}
+	if entry.Before["status"] != "draft" || entry.After["status"] != "draft" {
+		t.Errorf("Expected the images to hold unchanged columns, got %v and %v", entry.Before, entry.After)
+	}
+	if entry.ActorID != "42" || entry.RequestID != "req-1" {
+		t.Errorf("Expected the change attributed to actor 42 in req-1, got %q in %q", entry.ActorID, entry.RequestID)
+	}
+}
+
+func TestAuditActions(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(ctx context.Context, executor *DatabaseExecutor) ([]byte, error)
+		action string
+		before bool
+		after  bool
+	}{
+		{
+			name: "create",
+			change: func(ctx context.Context, executor *DatabaseExecutor) ([]byte, error) {
+				return executor.CreateRecord(ctx, "posts", map[string]any{"title": "new"}, nil)
+			},
+			action: AuditCreate,
+			after:  true,
+		},
+		{
+			name: "soft delete",
+			change: func(ctx context.Context, executor *DatabaseExecutor) ([]byte, error) {
+				return executor.DeleteRecord(ctx, "posts", 1, nil)
+			},
+			action: AuditDelete,
+			before: true,
+			after:  true,
+		},
+		{
+			name: "hard delete",
+			change: func(ctx context.Context, executor *DatabaseExecutor) ([]byte, error) {
+				return executor.HardDeleteRecord(ctx, "posts", 1, nil)
+			},
+			action: AuditDelete,
+			before: true,
+		},
+		{
+			name: "delete where",
+			change: func(ctx context.Context, executor *DatabaseExecutor) ([]byte, error) {
+				return executor.DeleteWhere(ctx, "posts", map[string]any{"title": "first"}, nil)
+			},
+			action: AuditDelete,
+			before: true,
+			after:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := newAuditExecutor(t)
+			ctx := WithAuditActor(context.Background(), "7", "")
+			if err := responseError(tt.change(ctx, executor)); err != nil {
+				t.Fatal(err)
+			}
+
+			entries := auditEntries(t, executor)
+			if len(entries) != 1 {
+				t.Fatalf("Expected 1 audit entry, got %d", len(entries))
+			}
+			entry := entries[0]
+			if entry.Action != tt.action || entry.ActorID != "7" {
+				t.Errorf("Expected %s by actor 7, got %s by %q", tt.action, entry.Action, entry.ActorID)
+			}
+			if (entry.Before != nil) != tt.before || (entry.After != nil) != tt.after {
+				t.Errorf("Expected before image %v and after image %v, got %v and %v", tt.before, tt.after, entry.Before, entry.After)
+			}
+		})
+	}
+}
+
+func TestAuditSQLWrites(t *testing.T) {
+	executor := newAuditExecutor(t)
+	ctx := context.Background()
+
+	if err := responseError(executor.ExecuteSQL(ctx, "UPDATE posts SET status = :status", map[string]any{"status": "live"}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := responseError(executor.ExecuteSQL(ctx, "INSERT INTO items (name) VALUES ('a')", nil, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := auditEntries(t, executor)
+	if len(entries) != 1 {
+		t.Fatalf("Expected only the write to posts audited, got %d entries", len(entries))
+	}
+	if entries[0].Action != AuditSQL || entries[0].After["statement"] != "UPDATE posts SET status = ?" || entries[0].After["affected"] != float64(1) {
+		t.Errorf("Expected the statement and affected count, got %+v", entries[0])
+	}
+}
+
+func TestAuditRequiresTable(t *testing.T) {
+	executor, _ := newSQLiteExecutor(t)
+	if err := executor.EnableAudit(context.Background(), []string{"items"}); err == nil {
+		t.Error("Expected auditing to fail without an audit_log table")
+	}
+	if err := executor.EnableAudit(context.Background(), nil); err != nil {
+		t.Errorf("Expected no tables to leave auditing off, got %v", err)
+	}
+}
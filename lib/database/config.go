@@ -9,6 +9,11 @@ import (
 
 // FromParserConfig converts a parser.DBConfig to database.Config
 func FromParserConfig(parserConfig parser.DBConfig) (interfaces.Config, error) {
+	parserConfig, err := parserConfig.WithURL()
+	if err != nil {
+		return interfaces.Config{}, err
+	}
+
 	// Map driver string to DatabaseDriver type
 	var driver interfaces.DatabaseDriver
 	switch parserConfig.Driver {
@@ -29,6 +34,7 @@ func FromParserConfig(parserConfig parser.DBConfig) (interfaces.Config, error) {
 	}
 
 	config := interfaces.Config{
+		URL:             parserConfig.URL,
 		Driver:          driver,
 		Host:            parserConfig.Host,
 		Port:            parserConfig.Port,
@@ -45,6 +51,46 @@ func FromParserConfig(parserConfig parser.DBConfig) (interfaces.Config, error) {
 	return config, nil
 }
 
+// ReplicasFromParserConfig converts the db.replicas of a config loaded by GetAppConfig,
+// which has filled their unset settings from the primary
+func ReplicasFromParserConfig(parserConfig parser.DBConfig) ([]interfaces.Config, error) {
+	var replicas []interfaces.Config
+	for i, replica := range parserConfig.Replicas {
+		config, err := FromParserConfig(replica)
+		if err != nil {
+			return nil, fmt.Errorf("replica %d: %w", i+1, err)
+		}
+		replicas = append(replicas, config)
+	}
+	return replicas, nil
+}
+
+// defaultRetryBackoff is the wait before the first retry when retry_backoff_ms is unset
+const defaultRetryBackoff = 50 * time.Millisecond
+
+// RetryPolicyFromConfig returns the retry policy set by max_retries and retry_backoff_ms
+func RetryPolicyFromConfig(parserConfig parser.DBConfig) RetryPolicy {
+	backoff := defaultRetryBackoff
+	if parserConfig.RetryBackoffMs > 0 {
+		backoff = time.Duration(parserConfig.RetryBackoffMs) * time.Millisecond
+	}
+	return RetryPolicy{MaxRetries: max(parserConfig.MaxRetries, 0), Backoff: backoff}
+}
+
+// defaultSlowQuery is the slow query threshold when slow_query_ms is unset
+const defaultSlowQuery = 500 * time.Millisecond
+
+// SlowQueryThresholdFromConfig returns the threshold set by slow_query_ms; 0 when disabled
+func SlowQueryThresholdFromConfig(parserConfig parser.DBConfig) time.Duration {
+	switch {
+	case parserConfig.SlowQueryMs < 0:
+		return 0
+	case parserConfig.SlowQueryMs == 0:
+		return defaultSlowQuery
+	}
+	return time.Duration(parserConfig.SlowQueryMs) * time.Millisecond
+}
+
 // ToParserConfig converts a database.Config back to parser.DBConfig
 func ToParserConfig(dbConfig interfaces.Config) parser.DBConfig {
 	var driver string
@@ -61,6 +107,7 @@ func ToParserConfig(dbConfig interfaces.Config) parser.DBConfig {
 	lifetimeMinutes := int(dbConfig.ConnMaxLifetime.Minutes())
 
 	return parser.DBConfig{
+		URL:             dbConfig.URL,
 		Driver:          driver,
 		Host:            dbConfig.Host,
 		Port:            dbConfig.Port,
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// executorContextKey is the context key for the executor a request should use
+type executorContextKey struct{}
+
+// ContextWithExecutor returns a copy of ctx carrying executor, typically one bound to the
+// request's transaction
+func ContextWithExecutor(ctx context.Context, executor *DatabaseExecutor) context.Context {
+	return context.WithValue(ctx, executorContextKey{}, executor)
+}
+
+// ExecutorFromContext returns the executor stored by ContextWithExecutor, if any
+func ExecutorFromContext(ctx context.Context) (*DatabaseExecutor, bool) {
+	executor, ok := ctx.Value(executorContextKey{}).(*DatabaseExecutor)
+	return executor, ok && executor != nil
+}
+
+// primaryContextKey marks a context whose reads must go to the primary
+type primaryContextKey struct{}
+
+// ForcePrimary returns a copy of ctx whose reads go to the primary instead of a replica,
+// e.g. to read back a write the replica may not have caught up with yet
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// readsPrimary reports whether ctx came from ForcePrimary or its request has written
+func readsPrimary(ctx context.Context) bool {
+	if forced, _ := ctx.Value(primaryContextKey{}).(bool); forced {
+		return true
+	}
+	pin, ok := ctx.Value(pinContextKey{}).(*primaryPin)
+	return ok && pin.pinned.Load()
+}
+
+// pinContextKey holds a request's *primaryPin
+type pinContextKey struct{}
+
+// primaryPin is set once a request writes, sending its later reads to the primary
+type primaryPin struct {
+	pinned atomic.Bool
+}
+
+// WithReadYourWrites returns a copy of ctx for one request whose reads go to the
+// primary once it has written, so the request never reads older data than it wrote
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pinContextKey{}, &primaryPin{})
+}
+
+// pinToPrimary sends the rest of the request's reads to the primary, if ctx came from
+// WithReadYourWrites
+func pinToPrimary(ctx context.Context) {
+	if pin, ok := ctx.Value(pinContextKey{}).(*primaryPin); ok {
+		pin.pinned.Store(true)
+	}
+}
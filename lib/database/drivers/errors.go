@@ -0,0 +1,66 @@
+package drivers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fulcrum/lib/database/interfaces"
+	"io"
+	"net"
+	"syscall"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// PostgreSQL error codes for statements the server rolled back
+var postgresAbortedCodes = map[pq.ErrorCode]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"55P03": true, // lock_not_available
+}
+
+// ClassifyError recognises serialization failures, deadlocks and lost connections
+func (p *PostgreSQLDB) ClassifyError(err error) interfaces.ErrorClass {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case postgresAbortedCodes[pqErr.Code]:
+			return interfaces.ErrorAborted
+		case pqErr.Code.Class() == "08", pqErr.Code == "57P01": // connection_exception, admin_shutdown
+			return interfaces.ErrorConnection
+		}
+		return interfaces.ErrorPermanent
+	}
+	return classifyConnectionError(err)
+}
+
+// ClassifyError recognises a busy or locked database file
+func (s *SQLiteDB) ClassifyError(err error) interfaces.ErrorClass {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		if sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked {
+			return interfaces.ErrorAborted
+		}
+		return interfaces.ErrorPermanent
+	}
+	return classifyConnectionError(err)
+}
+
+// classifyConnectionError recognises a connection that failed underneath any driver
+func classifyConnectionError(err error) interfaces.ErrorClass {
+	// A cancelled request must not be retried
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return interfaces.ErrorPermanent
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) || errors.As(err, &netErr) {
+		return interfaces.ErrorConnection
+	}
+	return interfaces.ErrorPermanent
+}
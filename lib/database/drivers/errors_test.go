@@ -0,0 +1,44 @@
+package drivers
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"fulcrum/lib/database/interfaces"
+	"syscall"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name       string
+		classifier interfaces.ErrorClassifier
+		err        error
+		want       interfaces.ErrorClass
+	}{
+		{"postgres deadlock", &PostgreSQLDB{}, &pq.Error{Code: "40P01"}, interfaces.ErrorAborted},
+		{"postgres serialization failure", &PostgreSQLDB{}, fmt.Errorf("query: %w", &pq.Error{Code: "40001"}), interfaces.ErrorAborted},
+		{"postgres connection failure", &PostgreSQLDB{}, &pq.Error{Code: "08006"}, interfaces.ErrorConnection},
+		{"postgres admin shutdown", &PostgreSQLDB{}, &pq.Error{Code: "57P01"}, interfaces.ErrorConnection},
+		{"postgres unique violation", &PostgreSQLDB{}, &pq.Error{Code: "23505"}, interfaces.ErrorPermanent},
+		{"postgres connection reset", &PostgreSQLDB{}, fmt.Errorf("read: %w", syscall.ECONNRESET), interfaces.ErrorConnection},
+		{"sqlite busy", &SQLiteDB{}, sqlite3.Error{Code: sqlite3.ErrBusy}, interfaces.ErrorAborted},
+		{"sqlite locked", &SQLiteDB{}, sqlite3.Error{Code: sqlite3.ErrLocked}, interfaces.ErrorAborted},
+		{"sqlite constraint", &SQLiteDB{}, sqlite3.Error{Code: sqlite3.ErrConstraint}, interfaces.ErrorPermanent},
+		{"bad connection", &SQLiteDB{}, driver.ErrBadConn, interfaces.ErrorConnection},
+		{"cancelled", &SQLiteDB{}, context.Canceled, interfaces.ErrorPermanent},
+		{"other", &PostgreSQLDB{}, errors.New("syntax error"), interfaces.ErrorPermanent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.classifier.ClassifyError(tt.err); got != tt.want {
+				t.Errorf("Expected class %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
@@ -98,6 +98,15 @@ func (p *PostgreSQLDB) Exec(ctx context.Context, query string, args ...interface
 	return result, nil
 }
 
+// Prepare creates a prepared statement for later queries or executions
+func (p *PostgreSQLDB) Prepare(ctx context.Context, query string) (interfaces.Stmt, error) {
+	stmt, err := p.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlStmt{stmt: stmt}, nil
+}
+
 // Begin starts a transaction
 func (p *PostgreSQLDB) Begin(ctx context.Context) (interfaces.Tx, error) {
 	tx, err := p.db.BeginTx(ctx, nil)
@@ -1,19 +1,223 @@
 package drivers
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"fulcrum/lib/database/interfaces"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite driver
 )
 
 // SQLiteDB implements the Database interface for SQLite
 type SQLiteDB struct {
 	config interfaces.Config
+	db     *sql.DB
 }
 
 // NewSQLiteDB creates a new SQLite database connection
 func NewSQLiteDB(config interfaces.Config) (interfaces.Database, error) {
-	return nil, fmt.Errorf("SQLite driver not implemented yet")
+	if config.FilePath == "" {
+		return nil, fmt.Errorf("sqlite requires file_path")
+	}
+
+	return &SQLiteDB{
+		config: config,
+	}, nil
+}
+
+// Connect opens the SQLite database file, creating it if needed
+func (s *SQLiteDB) Connect(ctx context.Context) error {
+	db, err := sql.Open("sqlite3", s.GetConnectionString())
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+
+	// SQLite allows a single writer; more connections only add lock contention
+	if s.config.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(s.config.MaxOpenConns)
+	} else {
+		db.SetMaxOpenConns(1)
+	}
+
+	if s.config.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(s.config.ConnMaxLifetime)
+	} else {
+		db.SetConnMaxLifetime(5 * time.Minute) // Default
+	}
+
+	s.db = db
+	return nil
+}
+
+// Close closes the database connection
+func (s *SQLiteDB) Close() error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// Ping tests the database connection
+func (s *SQLiteDB) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Stats returns database connection statistics
+func (s *SQLiteDB) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
+// Query executes a query that returns rows
+func (s *SQLiteDB) Query(ctx context.Context, query string, args ...interface{}) (interfaces.Rows, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// QueryRow executes a query that returns at most one row
+func (s *SQLiteDB) QueryRow(ctx context.Context, query string, args ...interface{}) interfaces.Row {
+	return s.db.QueryRowContext(ctx, query, args...)
+}
+
+// Exec executes a query without returning any rows
+func (s *SQLiteDB) Exec(ctx context.Context, query string, args ...interface{}) (interfaces.Result, error) {
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Prepare creates a prepared statement for later queries or executions
+func (s *SQLiteDB) Prepare(ctx context.Context, query string) (interfaces.Stmt, error) {
+	stmt, err := s.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlStmt{stmt: stmt}, nil
+}
+
+// Begin starts a transaction
+func (s *SQLiteDB) Begin(ctx context.Context) (interfaces.Tx, error) {
+	return s.BeginTx(ctx, nil)
+}
+
+// BeginTx starts a transaction with options
+func (s *SQLiteDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (interfaces.Tx, error) {
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteTx{tx: tx}, nil
+}
+
+// CreateTable creates a table with the given schema
+func (s *SQLiteDB) CreateTable(ctx context.Context, tableName string, schema interfaces.TableSchema) error {
+	query := s.buildCreateTableQuery(tableName, schema)
+	_, err := s.Exec(ctx, query)
+	return err
+}
+
+// DropTable drops a table
+func (s *SQLiteDB) DropTable(ctx context.Context, tableName string) error {
+	query := fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)
+	_, err := s.Exec(ctx, query)
+	return err
+}
+
+// TableExists checks if a table exists
+func (s *SQLiteDB) TableExists(ctx context.Context, tableName string) (bool, error) {
+	var count int
+	err := s.QueryRow(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", tableName).Scan(&count)
+	return count > 0, err
+}
+
+// GetDriver returns the database driver type
+func (s *SQLiteDB) GetDriver() interfaces.DatabaseDriver {
+	return interfaces.DriverSQLite
+}
+
+// GetConnectionString builds the SQLite DSN, enforcing foreign keys
+func (s *SQLiteDB) GetConnectionString() string {
+	separator := "?"
+	if strings.Contains(s.config.FilePath, "?") {
+		separator = "&"
+	}
+	return s.config.FilePath + separator + "_foreign_keys=on&_busy_timeout=5000"
+}
+
+// buildCreateTableQuery builds a CREATE TABLE query for SQLite
+func (s *SQLiteDB) buildCreateTableQuery(tableName string, schema interfaces.TableSchema) string {
+	var columns []string
+	for _, col := range schema.Columns {
+		def := fmt.Sprintf("%s %s", col.Name, strings.ToUpper(col.Type))
+		if col.NotNull {
+			def += " NOT NULL"
+		}
+		if col.DefaultValue != nil {
+			def += fmt.Sprintf(" DEFAULT %s", sqliteDefault(*col.DefaultValue))
+		}
+		columns = append(columns, def)
+	}
+
+	if len(schema.PrimaryKey) > 0 {
+		columns = append(columns, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(schema.PrimaryKey, ", ")))
+	}
+
+	for _, fk := range schema.ForeignKeys {
+		fkDef := fmt.Sprintf(
+			"CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+			fk.Name, fk.Column, fk.ReferencedTable, fk.ReferencedColumn,
+		)
+		if fk.OnDelete != "" {
+			fkDef += fmt.Sprintf(" ON DELETE %s", fk.OnDelete)
+		}
+		if fk.OnUpdate != "" {
+			fkDef += fmt.Sprintf(" ON UPDATE %s", fk.OnUpdate)
+		}
+		columns = append(columns, fkDef)
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (%s)", tableName, strings.Join(columns, ", "))
+}
+
+// sqliteDefault translates function defaults SQLite lacks, such as NOW()
+func sqliteDefault(value string) string {
+	if strings.EqualFold(value, "NOW()") {
+		return "CURRENT_TIMESTAMP"
+	}
+	return value
+}
+
+// SQLiteTx wraps sql.Tx
+type SQLiteTx struct {
+	tx *sql.Tx
+}
+
+func (t *SQLiteTx) Query(ctx context.Context, query string, args ...any) (interfaces.Rows, error) {
+	rows, err := t.tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (t *SQLiteTx) QueryRow(ctx context.Context, query string, args ...any) interfaces.Row {
+	return t.tx.QueryRowContext(ctx, query, args...)
+}
+
+func (t *SQLiteTx) Exec(ctx context.Context, query string, args ...any) (interfaces.Result, error) {
+	result, err := t.tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-// All interface methods would be implemented here
-// This is a placeholder for future implementation
+func (t *SQLiteTx) Commit() error   { return t.tx.Commit() }
+func (t *SQLiteTx) Rollback() error { return t.tx.Rollback() }
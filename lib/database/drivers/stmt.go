@@ -0,0 +1,34 @@
+package drivers
+
+import (
+	"context"
+	"database/sql"
+	"fulcrum/lib/database/interfaces"
+)
+
+// sqlStmt wraps sql.Stmt
+type sqlStmt struct {
+	stmt *sql.Stmt
+}
+
+func (s *sqlStmt) Query(ctx context.Context, args ...any) (interfaces.Rows, error) {
+	rows, err := s.stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (s *sqlStmt) QueryRow(ctx context.Context, args ...any) interfaces.Row {
+	return s.stmt.QueryRowContext(ctx, args...)
+}
+
+func (s *sqlStmt) Exec(ctx context.Context, args ...any) (interfaces.Result, error) {
+	result, err := s.stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *sqlStmt) Close() error { return s.stmt.Close() }
@@ -5,29 +5,158 @@ import (
 	"encoding/json"
 	"fmt"
 	"fulcrum/lib/database/interfaces"
+	"fulcrum/lib/tracing"
+	"maps"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // DatabaseExecutor handles JSON to SQL conversion and back
 type DatabaseExecutor struct {
 	db interfaces.Database
+	tx interfaces.Tx // Set on executors handed out by WithTransaction
+
+	replica interfaces.Querier // Runs ExecuteSQL's reads when set; see SetReplica
+
+	softDelete *softDeleteTables // Tables with a deleted_at column; see LoadSoftDeleteTables
+	audit      map[string]bool   // Tables whose changes are recorded in audit_log; see EnableAudit
+
+	lockColumns map[string]string // Lock columns of tables not using lock_version; see SetLockColumns
+
+	stmts *stmtCache  // Prepared SQL template statements; nil unless enabled
+	retry RetryPolicy // Retries for transient errors; none by default
+
+	observer  QueryObserver // Called after every statement; nil for none
+	slowQuery time.Duration // Statements slower than this are logged; 0 for none
 }
 
 func NewDatabaseExecutor(db interfaces.Database) *DatabaseExecutor {
-	return &DatabaseExecutor{db: db}
+	return &DatabaseExecutor{db: db, softDelete: &softDeleteTables{}}
+}
+
+// Driver returns the driver of the executor's database
+func (de *DatabaseExecutor) Driver() interfaces.DatabaseDriver {
+	return de.db.GetDriver()
+}
+
+// querier returns the transaction the executor is bound to, or the database
+func (de *DatabaseExecutor) querier() interfaces.Querier {
+	if de.tx != nil {
+		return de.tx
+	}
+	return de.db
+}
+
+// EnableStatementCache keeps up to size prepared statements for the SQL that ExecuteSQL
+// runs, reusing them when the same query text comes back. It is a no-op when size is
+// not positive or the database does not support prepared statements.
+func (de *DatabaseExecutor) EnableStatementCache(size int) {
+	preparer, ok := de.db.(interfaces.Preparer)
+	if size <= 0 || !ok {
+		return
+	}
+	de.stmts = newStmtCache(preparer, size)
+}
+
+// Close releases the executor's prepared statements
+func (de *DatabaseExecutor) Close() error {
+	if de.stmts == nil {
+		return nil
+	}
+	return de.stmts.close()
+}
+
+// query runs a query, through a cached prepared statement when the cache is enabled
+func (de *DatabaseExecutor) query(ctx context.Context, query string, args ...any) (_ interfaces.Rows, err error) {
+	defer de.observe(ctx, query, time.Now(), &err)
+	if de.stmts == nil || de.tx != nil || !cacheable(query) {
+		return de.querier().Query(ctx, query, args...)
+	}
+
+	entry, err := de.stmts.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer de.stmts.release(entry)
+	return entry.stmt.Query(ctx, args...)
+}
+
+// exec runs a statement, through a cached prepared statement when the cache is enabled
+func (de *DatabaseExecutor) exec(ctx context.Context, query string, args ...any) (_ interfaces.Result, err error) {
+	defer de.observe(ctx, query, time.Now(), &err)
+	if de.stmts == nil || de.tx != nil || !cacheable(query) {
+		return de.querier().Exec(ctx, query, args...)
+	}
+
+	entry, err := de.stmts.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer de.stmts.release(entry)
+	return entry.stmt.Exec(ctx, args...)
+}
+
+// queryUncached runs a query built for one request, which is not worth preparing
+func (de *DatabaseExecutor) queryUncached(ctx context.Context, query string, args ...any) (_ interfaces.Rows, err error) {
+	defer de.observe(ctx, query, time.Now(), &err)
+	return de.querier().Query(ctx, query, args...)
+}
+
+// execUncached runs a statement built for one request, which is not worth preparing
+func (de *DatabaseExecutor) execUncached(ctx context.Context, query string, args ...any) (_ interfaces.Result, err error) {
+	defer de.observe(ctx, query, time.Now(), &err)
+	pinToPrimary(ctx)
+	return de.querier().Exec(ctx, query, args...)
 }
 
-// SingleOperationRequest represents a direct method call (create, update, find)
+// WithTransaction runs fn with an executor bound to a new transaction. The transaction
+// commits when fn returns nil and rolls back when it returns an error or panics. Calls on
+// an executor that is already in a transaction join it instead of starting another.
+func (de *DatabaseExecutor) WithTransaction(ctx context.Context, fn func(tx *DatabaseExecutor) error) (err error) {
+	if de.tx != nil {
+		return fn(de)
+	}
+
+	tx, err := de.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(&DatabaseExecutor{db: de.db, tx: tx, softDelete: de.softDelete, audit: de.audit, lockColumns: de.lockColumns, retry: de.retry, observer: de.observer, slowQuery: de.slowQuery}); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// SingleOperationRequest represents a direct method call (create, update, find, delete, restore)
 type SingleOperationRequest struct {
-	Operation string         `json:"operation"` // "create", "update", "find"
+	Operation string         `json:"operation"` // "create", "update", "find", "delete", "delete_where", "restore"
 	Table     string         `json:"table"`
-	ID        any            `json:"id,omitempty"`    // for update
+	ID        any            `json:"id,omitempty"`    // for update/delete/restore
 	Data      map[string]any `json:"data,omitempty"`  // for create/update
-	Query     map[string]any `json:"query,omitempty"` // for find
+	Query     map[string]any `json:"query,omitempty"` // for find/delete_where
+	Hard      bool           `json:"_hard,omitempty"` // for delete/delete_where on tables with deleted_at
 	RequestID *string        `json:"request_id,omitempty"`
+
+	// ExpectedVersion makes an update apply only while the record's lock column holds it
+	ExpectedVersion any `json:"expected_version,omitempty"`
 }
 
 // OperationResponse represents the response
@@ -37,6 +166,7 @@ type OperationResponse struct {
 	Error     string           `json:"error,omitempty"`
 	Count     int              `json:"count"`
 	RequestID *string          `json:"request_id,omitempty"`
+	Conflict  bool             `json:"conflict,omitempty"` // An update lost to a newer version; see UpdateRecordAtVersion
 }
 
 // CreateRecord handles direct create calls
@@ -77,6 +207,29 @@ func (de *DatabaseExecutor) FindRecords(ctx context.Context, table string, query
 	return de.executeOperation(ctx, req)
 }
 
+// DeleteRecord handles direct delete calls by id, soft deleting rows of tables with a
+// deleted_at column
+func (de *DatabaseExecutor) DeleteRecord(ctx context.Context, table string, id any, requestID *string) ([]byte, error) {
+	req := SingleOperationRequest{
+		Operation: "delete",
+		Table:     table,
+		ID:        id,
+		RequestID: requestID,
+	}
+	return de.executeOperation(ctx, req)
+}
+
+// DeleteWhere handles direct delete calls for every record matching the query
+func (de *DatabaseExecutor) DeleteWhere(ctx context.Context, table string, query map[string]any, requestID *string) ([]byte, error) {
+	req := SingleOperationRequest{
+		Operation: "delete_where",
+		Table:     table,
+		Query:     query,
+		RequestID: requestID,
+	}
+	return de.executeOperation(ctx, req)
+}
+
 // ExecuteJSON is a generic handler that can accept JSON from any source
 func (de *DatabaseExecutor) ExecuteJSON(ctx context.Context, requestJSON []byte) ([]byte, error) {
 	var req SingleOperationRequest
@@ -95,9 +248,15 @@ func (de *DatabaseExecutor) executeOperation(ctx context.Context, req SingleOper
 	case "create":
 		response = de.createRecord(ctx, req.Table, req.Data)
 	case "update":
-		response = de.updateRecord(ctx, req.Table, req.ID, req.Data)
+		response = de.updateRecord(ctx, req.Table, req.ID, req.Data, req.ExpectedVersion)
 	case "find":
 		response = de.findRecords(ctx, req.Table, req.Query)
+	case "delete":
+		response = de.deleteRecord(ctx, req.Table, req.ID, req.Hard)
+	case "delete_where":
+		response = de.deleteWhere(ctx, req.Table, req.Query, req.Hard)
+	case "restore":
+		response = de.restoreRecord(ctx, req.Table, req.ID)
 	default:
 		response = OperationResponse{
 			Success: false,
@@ -124,8 +283,8 @@ func (de *DatabaseExecutor) createRecord(ctx context.Context, table string, data
 
 	for field, value := range data {
 		fields = append(fields, field)
-		placeholders = append(placeholders, "?")
 		args = append(args, value)
+		placeholders = append(placeholders, de.placeholder(len(args)))
 	}
 
 	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
@@ -133,7 +292,17 @@ func (de *DatabaseExecutor) createRecord(ctx context.Context, table string, data
 		strings.Join(fields, ", "),
 		strings.Join(placeholders, ", "))
 
-	result, err := de.db.Exec(ctx, query, args...)
+	if de.audits(table) {
+		return de.auditedCreate(ctx, table, func(tx *DatabaseExecutor) OperationResponse {
+			return tx.insert(ctx, query, args, data)
+		})
+	}
+	return de.insert(ctx, query, args, data)
+}
+
+// insert runs a createRecord INSERT, returning the record with its new id
+func (de *DatabaseExecutor) insert(ctx context.Context, query string, args []any, data map[string]any) OperationResponse {
+	result, err := de.execUncached(ctx, query, args...)
 	if err != nil {
 		return OperationResponse{
 			Success: false,
@@ -161,8 +330,13 @@ func (de *DatabaseExecutor) createRecord(ctx context.Context, table string, data
 	return response
 }
 
-// updateRecord handles UPDATE operations
-func (de *DatabaseExecutor) updateRecord(ctx context.Context, table string, id any, data map[string]any) OperationResponse {
+// updateRecord handles UPDATE operations. With an expectedVersion only the record at that
+// version is updated, and its lock column is incremented.
+func (de *DatabaseExecutor) updateRecord(ctx context.Context, table string, id any, data map[string]any, expectedVersion any) OperationResponse {
+	if expectedVersion != nil {
+		data = maps.Clone(data)
+		delete(data, de.lockColumn(table))
+	}
 	if len(data) == 0 {
 		return OperationResponse{
 			Success: false,
@@ -174,18 +348,42 @@ func (de *DatabaseExecutor) updateRecord(ctx context.Context, table string, id a
 	args := make([]any, 0, len(data)+1)
 
 	for field, value := range data {
-		setParts = append(setParts, field+" = ?")
 		args = append(args, value)
+		setParts = append(setParts, field+" = "+de.placeholder(len(args)))
 	}
 
 	// Add ID to args
 	args = append(args, id)
+	where := "id = " + de.placeholder(len(args))
+
+	if expectedVersion != nil {
+		column := de.lockColumn(table)
+		setParts = append(setParts, column+" = "+column+" + 1")
+		args = append(args, expectedVersion)
+		where += " AND " + column + " = " + de.placeholder(len(args))
+	}
 
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = ?",
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
 		table,
-		strings.Join(setParts, ", "))
+		strings.Join(setParts, ", "),
+		where)
 
-	result, err := de.db.Exec(ctx, query, args...)
+	change := func(tx *DatabaseExecutor) OperationResponse {
+		response := tx.update(ctx, query, args, id, data)
+		if expectedVersion != nil {
+			response = tx.checkVersion(ctx, table, id, expectedVersion, response)
+		}
+		return response
+	}
+	if de.audits(table) {
+		return de.auditedChange(ctx, table, AuditUpdate, "id = "+de.placeholder(1), []any{id}, change)
+	}
+	return change(de)
+}
+
+// update runs an updateRecord UPDATE, returning the changed fields with the id
+func (de *DatabaseExecutor) update(ctx context.Context, query string, args []any, id any, data map[string]any) OperationResponse {
+	result, err := de.execUncached(ctx, query, args...)
 	if err != nil {
 		return OperationResponse{
 			Success: false,
@@ -209,10 +407,82 @@ func (de *DatabaseExecutor) updateRecord(ctx context.Context, table string, id a
 	}
 }
 
-// findRecords handles SELECT operations
+// deleteRecord handles DELETE operations for a single id. Rows of tables with a
+// deleted_at column are soft deleted unless hard is set.
+func (de *DatabaseExecutor) deleteRecord(ctx context.Context, table string, id any, hard bool) OperationResponse {
+	if id == nil {
+		return OperationResponse{
+			Success: false,
+			Error:   "No id provided for delete",
+		}
+	}
+
+	where := "id = " + de.placeholder(1)
+	if !hard && de.softDeletes(table) {
+		where += " AND " + notDeleted
+		query := fmt.Sprintf("UPDATE %s SET %s = CURRENT_TIMESTAMP WHERE %s", table, SoftDeleteColumn, where)
+		return de.execDelete(ctx, table, where, query, []any{id})
+	}
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", table, where)
+	return de.execDelete(ctx, table, where, query, []any{id})
+}
+
+// deleteWhere handles DELETE operations for records matching query conditions, soft
+// deleting them like deleteRecord
+func (de *DatabaseExecutor) deleteWhere(ctx context.Context, table string, query map[string]any, hard bool) OperationResponse {
+	// Refuse to build an unconditional DELETE that would empty the table
+	if len(query) == 0 {
+		return OperationResponse{
+			Success: false,
+			Error:   "No query conditions provided for delete_where",
+		}
+	}
+
+	whereClause, args := de.buildWhereClause(query)
+	if !hard && de.softDeletes(table) {
+		whereClause += " AND " + notDeleted
+		sqlQuery := fmt.Sprintf("UPDATE %s SET %s = CURRENT_TIMESTAMP WHERE %s", table, SoftDeleteColumn, whereClause)
+		return de.execDelete(ctx, table, whereClause, sqlQuery, args)
+	}
+	sqlQuery := fmt.Sprintf("DELETE FROM %s WHERE %s", table, whereClause)
+	return de.execDelete(ctx, table, whereClause, sqlQuery, args)
+}
+
+// execDelete runs a DELETE statement, or the UPDATE of a soft delete, on the rows of
+// table matching where and reports the affected row count
+func (de *DatabaseExecutor) execDelete(ctx context.Context, table, where, query string, args []any) OperationResponse {
+	if de.audits(table) {
+		return de.auditedChange(ctx, table, AuditDelete, where, args, func(tx *DatabaseExecutor) OperationResponse {
+			return tx.delete(ctx, query, args)
+		})
+	}
+	return de.delete(ctx, query, args)
+}
+
+// delete runs an execDelete statement
+func (de *DatabaseExecutor) delete(ctx context.Context, query string, args []any) OperationResponse {
+	result, err := de.execUncached(ctx, query, args...)
+	if err != nil {
+		return OperationResponse{
+			Success: false,
+			Error:   "Delete failed: " + err.Error(),
+		}
+	}
+
+	affected, _ := result.RowsAffected()
+	return OperationResponse{
+		Success: true,
+		Count:   int(affected),
+	}
+}
+
+// findRecords handles SELECT operations. Soft deleted rows are left out unless the
+// query sets _with_deleted.
 func (de *DatabaseExecutor) findRecords(ctx context.Context, table string, query map[string]any) OperationResponse {
 	var sqlQuery strings.Builder
 	var args []any
+	var where []string
+	withDeleted := truthy(query[WithDeletedKey])
 
 	sqlQuery.WriteString("SELECT * FROM " + table)
 
@@ -256,39 +526,35 @@ func (de *DatabaseExecutor) findRecords(ctx context.Context, table string, query
 		if len(queryConditions) > 0 {
 			whereClause, whereArgs := de.buildWhereClause(queryConditions)
 			if whereClause != "" {
-				sqlQuery.WriteString(" WHERE " + whereClause)
+				where = append(where, whereClause)
 				args = append(args, whereArgs...)
 			}
 		}
 	}
+	if !withDeleted && de.softDeletes(table) {
+		where = append(where, notDeleted)
+	}
+	if len(where) > 0 {
+		sqlQuery.WriteString(" WHERE " + strings.Join(where, " AND "))
+	}
 
-	fmt.Println("HEERE =============================================")
-	fmt.Println("Executing SQL Query:", sqlQuery.String(), "Args:", args)
-	fmt.Println("HEERE =============================================")
-
-	rows, err := de.db.Query(ctx, sqlQuery.String(), args...)
+	rows, err := de.queryUncached(ctx, sqlQuery.String(), args...)
 	if err != nil {
-		fmt.Printf("❌ DB Query Error: %v\n", err)
 		return OperationResponse{
 			Success: false,
 			Error:   "Find failed: " + err.Error(),
 		}
 	}
-	fmt.Println("✅ DB Query executed successfully")
 	defer rows.Close()
 
 	data, err := de.rowsToJSON(rows)
 	if err != nil {
-		fmt.Printf("❌ rowsToJSON Error: %v\n", err)
 		return OperationResponse{
 			Success: false,
 			Error:   "Failed to convert results: " + err.Error(),
 		}
 	}
 
-	fmt.Printf("✅ rowsToJSON successful - Records found: %d\n", len(data))
-	fmt.Printf("📊 Data preview: %+v\n", data)
-
 	return OperationResponse{
 		Success: true,
 		Data:    data,
@@ -300,7 +566,7 @@ func (de *DatabaseExecutor) findRecords(ctx context.Context, table string, query
 func (de *DatabaseExecutor) buildWhereClause(where map[string]any) (string, []any) {
 	var conditions []string
 	var args []any
-	paramIndex := 1 // PostgreSQL parameters start at $1
+	paramIndex := 1 // Only used by drivers with numbered placeholders
 
 	for field, value := range where {
 		// Skip special parameters that start with underscore
@@ -314,23 +580,23 @@ func (de *DatabaseExecutor) buildWhereClause(where map[string]any) (string, []an
 			op := parts[1]
 			switch op {
 			case "gt":
-				conditions = append(conditions, fmt.Sprintf("%s > $%d", field, paramIndex))
+				conditions = append(conditions, fmt.Sprintf("%s > %s", field, de.placeholder(paramIndex)))
 				args = append(args, value)
 				paramIndex++
 			case "gte":
-				conditions = append(conditions, fmt.Sprintf("%s >= $%d", field, paramIndex))
+				conditions = append(conditions, fmt.Sprintf("%s >= %s", field, de.placeholder(paramIndex)))
 				args = append(args, value)
 				paramIndex++
 			case "lt":
-				conditions = append(conditions, fmt.Sprintf("%s < $%d", field, paramIndex))
+				conditions = append(conditions, fmt.Sprintf("%s < %s", field, de.placeholder(paramIndex)))
 				args = append(args, value)
 				paramIndex++
 			case "lte":
-				conditions = append(conditions, fmt.Sprintf("%s <= $%d", field, paramIndex))
+				conditions = append(conditions, fmt.Sprintf("%s <= %s", field, de.placeholder(paramIndex)))
 				args = append(args, value)
 				paramIndex++
 			case "like":
-				conditions = append(conditions, fmt.Sprintf("%s LIKE $%d", field, paramIndex))
+				conditions = append(conditions, fmt.Sprintf("%s LIKE %s", field, de.placeholder(paramIndex)))
 				args = append(args, value)
 				paramIndex++
 			case "in":
@@ -338,19 +604,19 @@ func (de *DatabaseExecutor) buildWhereClause(where map[string]any) (string, []an
 				if arr, ok := value.([]any); ok {
 					var placeholders []string
 					for i := 0; i < len(arr); i++ {
-						placeholders = append(placeholders, fmt.Sprintf("$%d", paramIndex))
+						placeholders = append(placeholders, de.placeholder(paramIndex))
 						paramIndex++
 					}
 					conditions = append(conditions, fmt.Sprintf("%s IN (%s)", field, strings.Join(placeholders, ",")))
 					args = append(args, arr...)
 				}
 			default:
-				conditions = append(conditions, fmt.Sprintf("%s = $%d", field, paramIndex))
+				conditions = append(conditions, fmt.Sprintf("%s = %s", field, de.placeholder(paramIndex)))
 				args = append(args, value)
 				paramIndex++
 			}
 		} else {
-			conditions = append(conditions, fmt.Sprintf("%s = $%d", field, paramIndex))
+			conditions = append(conditions, fmt.Sprintf("%s = %s", field, de.placeholder(paramIndex)))
 			args = append(args, value)
 			paramIndex++
 		}
@@ -358,6 +624,29 @@ func (de *DatabaseExecutor) buildWhereClause(where map[string]any) (string, []an
 	return strings.Join(conditions, " AND "), args
 }
 
+// placeholder returns the bind parameter for the given 1-based position:
+// $N for PostgreSQL, ? for MySQL and SQLite where argument order is enough.
+// Every query builder in the executor binds arguments through this method.
+// listItems returns the items of a slice parameter; byte slices are single values
+func listItems(value any) ([]any, bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	items := make([]any, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, true
+}
+
+func (de *DatabaseExecutor) placeholder(index int) string {
+	if de.db.GetDriver() == interfaces.DriverPostgreSQL {
+		return fmt.Sprintf("$%d", index)
+	}
+	return "?"
+}
+
 // rowsToJSON converts database rows to JSON-friendly format
 func (de *DatabaseExecutor) rowsToJSON(rows interfaces.Rows) ([]map[string]any, error) {
 	columns, err := rows.Columns()
@@ -436,17 +725,24 @@ func (de *DatabaseExecutor) errorResponse(message string, requestID *string) ([]
 
 // ExecuteSQL executes a raw SQL query with optional parameters
 func (de *DatabaseExecutor) ExecuteSQL(ctx context.Context, sqlQuery string, params map[string]any, requestID *string) ([]byte, error) {
-	fmt.Printf("🔍 ExecuteSQL called with query: %s\n", sqlQuery)
-	fmt.Printf("📊 Parameters: %+v\n", params)
+	ctx, span := tracing.Start(ctx, "sql.execute")
+	defer span.End()
+
+	// fail records the error on the span before responding
+	fail := func(message string, err error) ([]byte, error) {
+		tracing.RecordError(span, err)
+		return de.errorResponse(message+err.Error(), requestID)
+	}
 
 	// Parse and prepare the SQL query with parameters
 	processedQuery, args, err := de.processSQLParameters(sqlQuery, params)
 	if err != nil {
-		return de.errorResponse("Failed to process SQL parameters: "+err.Error(), requestID)
+		return fail("Failed to process SQL parameters: ", err)
 	}
 
-	fmt.Printf("🔧 Processed query: %s\n", processedQuery)
-	fmt.Printf("🎯 Args: %+v\n", args)
+	// Parameters stay out of the span; only the placeholder query is recorded
+	tracing.SetString(span, "db.system", string(de.db.GetDriver()))
+	tracing.SetString(span, "db.query.text", tracing.Truncate(processedQuery, tracing.MaxQueryLength))
 
 	// Determine if this is a SELECT query or modification query
 	trimmedQuery := strings.TrimSpace(strings.ToUpper(sqlQuery))
@@ -458,43 +754,70 @@ func (de *DatabaseExecutor) ExecuteSQL(ctx context.Context, sqlQuery string, par
 	var response OperationResponse
 	response.RequestID = requestID
 
+	// A write on a lost connection may already have applied, and a failed statement
+	// may leave the earlier statements of a multi-statement template applied
+	mode := retryNever
+	switch {
+	case isSelectQuery && !hasReturning:
+		mode = retryTransient
+	case cacheable(processedQuery):
+		mode = retryAborted
+	}
+
 	if isSelectQuery || hasReturning {
 		// Execute SELECT query
-		rows, err := de.db.Query(ctx, processedQuery, args...)
-		if err != nil {
-			fmt.Printf("❌ SELECT Query Error: %v\n", err)
-			return de.errorResponse("Query execution failed: "+err.Error(), requestID)
+		var data []map[string]any
+		var message string
+		run := de.read
+		if hasReturning {
+			pinToPrimary(ctx)
+			run = de.query
 		}
-		defer rows.Close()
+		err := de.withRetry(ctx, mode, func() error {
+			rows, err := run(ctx, processedQuery, args...)
+			if err != nil {
+				message = "Query execution failed: "
+				return err
+			}
+			defer rows.Close()
 
-		data, err := de.rowsToJSON(rows)
+			if data, err = de.rowsToJSON(rows); err != nil {
+				message = "Failed to convert results: "
+				return err
+			}
+			return nil
+		})
 		if err != nil {
-			fmt.Printf("❌ rowsToJSON Error: %v\n", err)
-			return de.errorResponse("Failed to convert results: "+err.Error(), requestID)
+			return fail(message, err)
 		}
 
-		fmt.Printf("✅ SELECT query successful - Records found: %d\n", len(data))
-
 		response = OperationResponse{
 			Success: true,
 			Data:    data,
 			Count:   len(data),
 		}
+		if hasReturning {
+			de.recordStatement(ctx, processedQuery, response.Count)
+		}
 	} else {
 		// Execute modification query (INSERT, UPDATE, DELETE, etc.)
-		result, err := de.db.Exec(ctx, processedQuery, args...)
+		pinToPrimary(ctx)
+		var result interfaces.Result
+		err := de.withRetry(ctx, mode, func() error {
+			var err error
+			result, err = de.exec(ctx, processedQuery, args...)
+			return err
+		})
 		if err != nil {
-			fmt.Printf("❌ EXEC Query Error: %v\n", err)
-			return de.errorResponse("Query execution failed: "+err.Error(), requestID)
+			return fail("Query execution failed: ", err)
 		}
 
 		affected, _ := result.RowsAffected()
-		fmt.Printf("✅ EXEC query successful - Rows affected: %d\n", affected)
-
 		response = OperationResponse{
 			Success: true,
 			Count:   int(affected),
 		}
+		de.recordStatement(ctx, processedQuery, response.Count)
 
 		// For INSERT queries, try to get the last insert ID
 		if strings.HasPrefix(trimmedQuery, "INSERT") {
@@ -538,7 +861,7 @@ func (de *DatabaseExecutor) processSQLParameters(sqlQuery string, params map[str
 
 		if value, exists := params[paramName]; exists {
 			args = append(args, value)
-			placeholder := fmt.Sprintf("$%d", paramIndex)
+			placeholder := de.placeholder(paramIndex)
 			paramIndex++
 			return placeholder
 		}
@@ -554,8 +877,22 @@ func (de *DatabaseExecutor) processSQLParameters(sqlQuery string, params map[str
 		paramName := strings.TrimPrefix(match, ":")
 
 		if value, exists := params[paramName]; exists {
+			// A list, such as the in_list SQL helper's, binds one placeholder per item
+			if items, ok := listItems(value); ok {
+				if len(items) == 0 {
+					return "NULL"
+				}
+				placeholders := make([]string, len(items))
+				for i, item := range items {
+					args = append(args, item)
+					placeholders[i] = de.placeholder(paramIndex)
+					paramIndex++
+				}
+				return strings.Join(placeholders, ", ")
+			}
+
 			args = append(args, value)
-			placeholder := fmt.Sprintf("$%d", paramIndex)
+			placeholder := de.placeholder(paramIndex)
 			paramIndex++
 			return placeholder
 		}
@@ -569,10 +906,6 @@ func (de *DatabaseExecutor) processSQLParameters(sqlQuery string, params map[str
 
 // ExecuteSQLTemplate renders a SQL template and executes it
 func (de *DatabaseExecutor) ExecuteSQLTemplate(ctx context.Context, sqlTemplate string, templateData map[string]any, requestID *string) ([]byte, error) {
-	fmt.Printf("🎨 ExecuteSQLTemplate called\n")
-	fmt.Printf("📝 Template: %s\n", sqlTemplate)
-	fmt.Printf("📊 Template Data: %+v\n", templateData)
-
 	// For now, we'll treat the sqlTemplate as the actual SQL
 	// In a real implementation, you'd render the template first
 	// using your template engine, then execute the resulting SQL
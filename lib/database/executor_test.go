@@ -0,0 +1,484 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fulcrum/lib/database/drivers"
+	"fulcrum/lib/database/interfaces"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mockDatabase implements interfaces.Database and records the queries it receives
+type mockDatabase struct {
+	driver  interfaces.DatabaseDriver
+	queries []string
+	args    [][]any
+}
+
+func (m *mockDatabase) Connect(ctx context.Context) error { return nil }
+func (m *mockDatabase) Close() error                      { return nil }
+func (m *mockDatabase) Ping(ctx context.Context) error    { return nil }
+func (m *mockDatabase) Stats() sql.DBStats                { return sql.DBStats{} }
+
+func (m *mockDatabase) Query(ctx context.Context, query string, args ...any) (interfaces.Rows, error) {
+	m.queries = append(m.queries, query)
+	m.args = append(m.args, args)
+	return &mockRows{}, nil
+}
+
+func (m *mockDatabase) QueryRow(ctx context.Context, query string, args ...any) interfaces.Row {
+	return nil
+}
+
+func (m *mockDatabase) Exec(ctx context.Context, query string, args ...any) (interfaces.Result, error) {
+	m.queries = append(m.queries, query)
+	m.args = append(m.args, args)
+	return &mockResult{}, nil
+}
+
+func (m *mockDatabase) Begin(ctx context.Context) (interfaces.Tx, error) { return nil, nil }
+func (m *mockDatabase) BeginTx(ctx context.Context, opts *sql.TxOptions) (interfaces.Tx, error) {
+	return nil, nil
+}
+func (m *mockDatabase) CreateTable(ctx context.Context, tableName string, schema interfaces.TableSchema) error {
+	return nil
+}
+func (m *mockDatabase) DropTable(ctx context.Context, tableName string) error { return nil }
+func (m *mockDatabase) TableExists(ctx context.Context, tableName string) (bool, error) {
+	return false, nil
+}
+func (m *mockDatabase) GetDriver() interfaces.DatabaseDriver { return m.driver }
+func (m *mockDatabase) GetConnectionString() string          { return "mock://" + string(m.driver) }
+
+// lastQuery returns the most recent query sent to the mock
+func (m *mockDatabase) lastQuery() string {
+	if len(m.queries) == 0 {
+		return ""
+	}
+	return m.queries[len(m.queries)-1]
+}
+
+type mockRows struct{}
+
+func (r *mockRows) Close() error                            { return nil }
+func (r *mockRows) ColumnTypes() ([]*sql.ColumnType, error) { return nil, nil }
+func (r *mockRows) Columns() ([]string, error)              { return []string{}, nil }
+func (r *mockRows) Err() error                              { return nil }
+func (r *mockRows) Next() bool                              { return false }
+func (r *mockRows) NextResultSet() bool                     { return false }
+func (r *mockRows) Scan(dest ...any) error                  { return nil }
+
+type mockResult struct{}
+
+func (r *mockResult) LastInsertId() (int64, error) { return 1, nil }
+func (r *mockResult) RowsAffected() (int64, error) { return 1, nil }
+
+func TestBuildWhereClausePlaceholders(t *testing.T) {
+	tests := []struct {
+		name     string
+		driver   interfaces.DatabaseDriver
+		where    map[string]any
+		expected string
+	}{
+		{
+			name:     "postgres equality",
+			driver:   interfaces.DriverPostgreSQL,
+			where:    map[string]any{"email": "a@example.com"},
+			expected: "email = $1",
+		},
+		{
+			name:     "mysql equality",
+			driver:   interfaces.DriverMySQL,
+			where:    map[string]any{"email": "a@example.com"},
+			expected: "email = ?",
+		},
+		{
+			name:     "sqlite comparison",
+			driver:   interfaces.DriverSQLite,
+			where:    map[string]any{"age__gte": 18},
+			expected: "age >= ?",
+		},
+		{
+			name:     "postgres IN list",
+			driver:   interfaces.DriverPostgreSQL,
+			where:    map[string]any{"id__in": []any{1, 2, 3}},
+			expected: "id IN ($1,$2,$3)",
+		},
+		{
+			name:     "sqlite IN list",
+			driver:   interfaces.DriverSQLite,
+			where:    map[string]any{"id__in": []any{1, 2, 3}},
+			expected: "id IN (?,?,?)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			de := NewDatabaseExecutor(&mockDatabase{driver: tt.driver})
+
+			clause, _ := de.buildWhereClause(tt.where)
+			if clause != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, clause)
+			}
+		})
+	}
+}
+
+func TestBuildWhereClauseMultipleConditions(t *testing.T) {
+	where := map[string]any{"name": "Ada", "age__gt": 30, "email__like": "%@example.com"}
+
+	t.Run("postgres numbers each placeholder", func(t *testing.T) {
+		de := NewDatabaseExecutor(&mockDatabase{driver: interfaces.DriverPostgreSQL})
+		clause, args := de.buildWhereClause(where)
+
+		for _, placeholder := range []string{"$1", "$2", "$3"} {
+			if !strings.Contains(clause, placeholder) {
+				t.Errorf("Expected %s in %q", placeholder, clause)
+			}
+		}
+		if len(args) != 3 {
+			t.Errorf("Expected 3 args, got %d", len(args))
+		}
+	})
+
+	t.Run("mysql uses positional placeholders", func(t *testing.T) {
+		de := NewDatabaseExecutor(&mockDatabase{driver: interfaces.DriverMySQL})
+		clause, args := de.buildWhereClause(where)
+
+		if strings.Contains(clause, "$") {
+			t.Errorf("Expected no numbered placeholders, got %q", clause)
+		}
+		if strings.Count(clause, "?") != 3 || len(args) != 3 {
+			t.Errorf("Expected 3 placeholders and args, got %q with %d args", clause, len(args))
+		}
+	})
+}
+
+func TestQueryBuildersUseDriverPlaceholders(t *testing.T) {
+	tests := []struct {
+		driver         interfaces.DatabaseDriver
+		expectedCreate string
+		expectedUpdate string
+		expectedFind   string
+		expectedSQL    string
+	}{
+		{
+			driver:         interfaces.DriverPostgreSQL,
+			expectedCreate: "INSERT INTO users (email) VALUES ($1)",
+			expectedUpdate: "UPDATE users SET email = $1 WHERE id = $2",
+			expectedFind:   "SELECT * FROM users WHERE email = $1",
+			expectedSQL:    "SELECT * FROM users WHERE email = $1 AND id = $2",
+		},
+		{
+			driver:         interfaces.DriverMySQL,
+			expectedCreate: "INSERT INTO users (email) VALUES (?)",
+			expectedUpdate: "UPDATE users SET email = ? WHERE id = ?",
+			expectedFind:   "SELECT * FROM users WHERE email = ?",
+			expectedSQL:    "SELECT * FROM users WHERE email = ? AND id = ?",
+		},
+		{
+			driver:         interfaces.DriverSQLite,
+			expectedCreate: "INSERT INTO users (email) VALUES (?)",
+			expectedUpdate: "UPDATE users SET email = ? WHERE id = ?",
+			expectedFind:   "SELECT * FROM users WHERE email = ?",
+			expectedSQL:    "SELECT * FROM users WHERE email = ? AND id = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.driver), func(t *testing.T) {
+			ctx := context.Background()
+			db := &mockDatabase{driver: tt.driver}
+			de := NewDatabaseExecutor(db)
+			data := map[string]any{"email": "ada@example.com"}
+
+			if _, err := de.CreateRecord(ctx, "users", data, nil); err != nil {
+				t.Fatalf("CreateRecord failed: %v", err)
+			}
+			if got := db.lastQuery(); got != tt.expectedCreate {
+				t.Errorf("create: expected %q, got %q", tt.expectedCreate, got)
+			}
+
+			if _, err := de.UpdateRecord(ctx, "users", 7, data, nil); err != nil {
+				t.Fatalf("UpdateRecord failed: %v", err)
+			}
+			if got := db.lastQuery(); got != tt.expectedUpdate {
+				t.Errorf("update: expected %q, got %q", tt.expectedUpdate, got)
+			}
+
+			if _, err := de.FindRecords(ctx, "users", data, nil); err != nil {
+				t.Fatalf("FindRecords failed: %v", err)
+			}
+			if got := db.lastQuery(); got != tt.expectedFind {
+				t.Errorf("find: expected %q, got %q", tt.expectedFind, got)
+			}
+
+			params := map[string]any{"email": "ada@example.com", "id": 7}
+			if _, err := de.ExecuteSQL(ctx, "SELECT * FROM users WHERE email = {{email}} AND id = :id", params, nil); err != nil {
+				t.Fatalf("ExecuteSQL failed: %v", err)
+			}
+			if got := db.lastQuery(); got != tt.expectedSQL {
+				t.Errorf("raw sql: expected %q, got %q", tt.expectedSQL, got)
+			}
+		})
+	}
+}
+
+func TestDeleteRecord(t *testing.T) {
+	tests := []struct {
+		driver   interfaces.DatabaseDriver
+		expected string
+	}{
+		{driver: interfaces.DriverPostgreSQL, expected: "DELETE FROM users WHERE id = $1"},
+		{driver: interfaces.DriverMySQL, expected: "DELETE FROM users WHERE id = ?"},
+		{driver: interfaces.DriverSQLite, expected: "DELETE FROM users WHERE id = ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.driver), func(t *testing.T) {
+			db := &mockDatabase{driver: tt.driver}
+			de := NewDatabaseExecutor(db)
+
+			resp, err := de.DeleteRecord(context.Background(), "users", 42, nil)
+			if err != nil {
+				t.Fatalf("DeleteRecord failed: %v", err)
+			}
+			if got := db.lastQuery(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+			if len(db.args[0]) != 1 || db.args[0][0] != 42 {
+				t.Errorf("Expected id 42 as the only arg, got %v", db.args[0])
+			}
+			if !strings.Contains(string(resp), `"count":1`) || !strings.Contains(string(resp), `"success":true`) {
+				t.Errorf("Expected affected row count in response, got %s", resp)
+			}
+		})
+	}
+}
+
+func TestDeleteWhere(t *testing.T) {
+	t.Run("builds conditions from the query", func(t *testing.T) {
+		db := &mockDatabase{driver: interfaces.DriverPostgreSQL}
+		de := NewDatabaseExecutor(db)
+
+		if _, err := de.DeleteWhere(context.Background(), "sessions", map[string]any{"expires_at__lt": "2024-01-01"}, nil); err != nil {
+			t.Fatalf("DeleteWhere failed: %v", err)
+		}
+		if got := db.lastQuery(); got != "DELETE FROM sessions WHERE expires_at < $1" {
+			t.Errorf("Unexpected query %q", got)
+		}
+	})
+
+	t.Run("refuses to delete without conditions", func(t *testing.T) {
+		db := &mockDatabase{driver: interfaces.DriverSQLite}
+		de := NewDatabaseExecutor(db)
+
+		resp, err := de.DeleteWhere(context.Background(), "sessions", nil, nil)
+		if err != nil {
+			t.Fatalf("DeleteWhere failed: %v", err)
+		}
+		if len(db.queries) != 0 {
+			t.Errorf("Expected no query to run, got %v", db.queries)
+		}
+		if !strings.Contains(string(resp), `"success":false`) {
+			t.Errorf("Expected failure response, got %s", resp)
+		}
+	})
+}
+
+func TestExecuteSQLBindsLists(t *testing.T) {
+	executor, db := newSQLiteExecutor(t)
+	if _, err := db.Exec(context.Background(), "INSERT INTO items (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c')"); err != nil {
+		t.Fatal(err)
+	}
+	const sql = "SELECT name FROM items WHERE id IN (:ids) AND name <> :name"
+
+	tests := []struct {
+		name     string
+		ids      any
+		query    string
+		expected int
+	}{
+		{name: "each item is bound", ids: []any{1, "3"}, query: "SELECT name FROM items WHERE id IN (?, ?) AND name <> ?", expected: 2},
+		{name: "string lists", ids: []string{"2"}, query: "SELECT name FROM items WHERE id IN (?) AND name <> ?", expected: 1},
+		{name: "empty list matches nothing", ids: []any{}, query: "SELECT name FROM items WHERE id IN (NULL) AND name <> ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]any{"ids": tt.ids, "name": "z"}
+			query, _, err := executor.processSQLParameters(sql, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query != tt.query {
+				t.Errorf("Expected %q, got %q", tt.query, query)
+			}
+
+			response, err := executor.ExecuteSQL(context.Background(), sql, params, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded OperationResponse
+			if err := json.Unmarshal(response, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if !decoded.Success || decoded.Count != tt.expected {
+				t.Errorf("Expected %d rows, got %+v", tt.expected, decoded)
+			}
+		})
+	}
+}
+
+// newSQLiteExecutor returns an executor over a fresh SQLite database with an items table
+func newSQLiteExecutor(t testing.TB) (*DatabaseExecutor, interfaces.Database) {
+	t.Helper()
+	db := newSQLiteDB(t, "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE)")
+	return NewDatabaseExecutor(db), db
+}
+
+// newSQLiteDB connects to a fresh SQLite database, closed when the test ends, and runs
+// statements on it
+func newSQLiteDB(t testing.TB, statements ...string) interfaces.Database {
+	t.Helper()
+	db, err := drivers.NewSQLiteDB(interfaces.Config{FilePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	execAll(t, db, statements...)
+	return db
+}
+
+// execAll runs each statement on db, failing the test on the first error
+func execAll(t testing.TB, db interfaces.Database, statements ...string) {
+	t.Helper()
+	for _, statement := range statements {
+		if _, err := db.Exec(context.Background(), statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// countItems returns the number of rows in the items table
+func countItems(t *testing.T, db interfaces.Database) int {
+	t.Helper()
+	var count int
+	if err := db.QueryRow(context.Background(), "SELECT COUNT(*) FROM items").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+// responseError turns an executor response that reports failure into an error
+func responseError(response []byte, err error) error {
+	if err != nil {
+		return err
+	}
+	var decoded OperationResponse
+	if err := json.Unmarshal(response, &decoded); err != nil {
+		return err
+	}
+	if !decoded.Success {
+		return errors.New(decoded.Error)
+	}
+	return nil
+}
+
+func TestWithTransaction(t *testing.T) {
+	errHandler := errors.New("handler failed")
+
+	tests := []struct {
+		name     string
+		fn       func(tx *DatabaseExecutor) error
+		wantErr  bool
+		expected int
+	}{
+		{
+			name: "commits on success",
+			fn: func(tx *DatabaseExecutor) error {
+				if err := responseError(tx.ExecuteSQL(context.Background(), "INSERT INTO items (name) VALUES ('a')", nil, nil)); err != nil {
+					return err
+				}
+				return responseError(tx.CreateRecord(context.Background(), "items", map[string]any{"name": "b"}, nil))
+			},
+			expected: 2,
+		},
+		{
+			name: "rolls back when a later statement fails",
+			fn: func(tx *DatabaseExecutor) error {
+				if err := responseError(tx.ExecuteSQL(context.Background(), "INSERT INTO items (name) VALUES ('a')", nil, nil)); err != nil {
+					return err
+				}
+				return responseError(tx.ExecuteSQL(context.Background(), "INSERT INTO items (name) VALUES ('a')", nil, nil))
+			},
+			wantErr: true,
+		},
+		{
+			name: "rolls back when the caller fails",
+			fn: func(tx *DatabaseExecutor) error {
+				if err := responseError(tx.ExecuteSQL(context.Background(), "INSERT INTO items (name) VALUES ('a')", nil, nil)); err != nil {
+					return err
+				}
+				return errHandler
+			},
+			wantErr: true,
+		},
+		{
+			name: "nested calls join the outer transaction",
+			fn: func(tx *DatabaseExecutor) error {
+				err := tx.WithTransaction(context.Background(), func(inner *DatabaseExecutor) error {
+					return responseError(inner.ExecuteSQL(context.Background(), "INSERT INTO items (name) VALUES ('a')", nil, nil))
+				})
+				if err != nil {
+					return err
+				}
+				return errHandler
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor, db := newSQLiteExecutor(t)
+
+			err := executor.WithTransaction(context.Background(), func(tx *DatabaseExecutor) error {
+				return tt.fn(tx)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if count := countItems(t, db); count != tt.expected {
+				t.Errorf("Expected %d rows after the transaction, got %d", tt.expected, count)
+			}
+		})
+	}
+}
+
+func TestWithTransactionRollsBackOnPanic(t *testing.T) {
+	executor, db := newSQLiteExecutor(t)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected the panic to be re-raised")
+			}
+		}()
+		executor.WithTransaction(context.Background(), func(tx *DatabaseExecutor) error {
+			tx.ExecuteSQL(context.Background(), "INSERT INTO items (name) VALUES ('a')", nil, nil)
+			panic("boom")
+		})
+	}()
+
+	if count := countItems(t, db); count != 0 {
+		t.Errorf("Expected the insert to be rolled back, got %d rows", count)
+	}
+}
@@ -18,6 +18,7 @@ const (
 
 // Config holds database configuration
 type Config struct {
+	URL             string // Connection URL; fills the fields below that are left empty
 	Driver          DatabaseDriver
 	Host            string
 	Port            int
@@ -82,6 +83,44 @@ type Tx interface {
 	Exec(ctx context.Context, query string, args ...any) (Result, error)
 }
 
+// Querier runs statements against either a Database or a Tx
+type Querier interface {
+	Query(ctx context.Context, query string, args ...any) (Rows, error)
+	QueryRow(ctx context.Context, query string, args ...any) Row
+	Exec(ctx context.Context, query string, args ...any) (Result, error)
+}
+
+// Stmt is a prepared statement
+type Stmt interface {
+	Query(ctx context.Context, args ...any) (Rows, error)
+	QueryRow(ctx context.Context, args ...any) Row
+	Exec(ctx context.Context, args ...any) (Result, error)
+	Close() error
+}
+
+// Preparer is implemented by databases that support prepared statements
+type Preparer interface {
+	Prepare(ctx context.Context, query string) (Stmt, error)
+}
+
+// ErrorClass describes whether a failed statement is safe to run again
+type ErrorClass int
+
+const (
+	// ErrorPermanent errors fail the same way when retried (syntax, constraints, ...)
+	ErrorPermanent ErrorClass = iota
+	// ErrorAborted means the database rolled the statement back (deadlock,
+	// serialization failure, busy), so it had no effect and can be retried
+	ErrorAborted
+	// ErrorConnection means the connection failed; a write may or may not have applied
+	ErrorConnection
+)
+
+// ErrorClassifier is implemented by databases that recognise their transient errors
+type ErrorClassifier interface {
+	ClassifyError(err error) ErrorClass
+}
+
 // TableSchema represents a database table schema
 type TableSchema struct {
 	Columns     []ColumnDefinition
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"fulcrum/lib/parser"
+)
+
+// UpdateRecordAtVersion handles direct update calls with optimistic locking: the record
+// is only updated while its lock column still holds expectedVersion, and the column is
+// incremented. When another update got there first the response fails with Conflict
+// set and the record as it is now; a nil expectedVersion updates like UpdateRecord.
+func (de *DatabaseExecutor) UpdateRecordAtVersion(ctx context.Context, table string, id any, data map[string]any, expectedVersion any, requestID *string) ([]byte, error) {
+	req := SingleOperationRequest{
+		Operation:       "update",
+		Table:           table,
+		ID:              id,
+		Data:            data,
+		ExpectedVersion: expectedVersion,
+		RequestID:       requestID,
+	}
+	return de.executeOperation(ctx, req)
+}
+
+// SetLockColumns sets the lock column of the tables that do not use lock_version
+func (de *DatabaseExecutor) SetLockColumns(columns map[string]string) {
+	de.lockColumns = columns
+}
+
+// lockColumn returns the column holding table's version for optimistic locking
+func (de *DatabaseExecutor) lockColumn(table string) string {
+	if column := de.lockColumns[table]; column != "" {
+		return column
+	}
+	return parser.DefaultLockColumn
+}
+
+// checkVersion turns a versioned update that changed no rows into a conflict when the
+// record still exists, as another update changed its version first. A successful
+// update returns the record's new version.
+func (de *DatabaseExecutor) checkVersion(ctx context.Context, table string, id, expectedVersion any, response OperationResponse) OperationResponse {
+	column := de.lockColumn(table)
+	if !response.Success {
+		return response
+	}
+	if response.Count > 0 {
+		if version, err := strconv.ParseInt(stringValue(expectedVersion), 10, 64); err == nil && len(response.Data) > 0 {
+			response.Data[0][column] = version + 1
+		}
+		return response
+	}
+
+	rows, err := de.queryUncached(ctx, fmt.Sprintf("SELECT * FROM %s WHERE id = %s", table, de.placeholder(1)), id)
+	if err != nil {
+		return OperationResponse{
+			Success: false,
+			Error:   "Update failed: " + err.Error(),
+		}
+	}
+	defer rows.Close()
+	current, err := de.rowsToJSON(rows)
+	if err != nil {
+		return OperationResponse{
+			Success: false,
+			Error:   "Update failed: " + err.Error(),
+		}
+	}
+	if len(current) == 0 {
+		return response
+	}
+	return OperationResponse{
+		Success:  false,
+		Error:    "Conflict: the record was modified by someone else",
+		Conflict: true,
+		Data:     current,
+	}
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// newLockingExecutor returns an executor whose posts table has a lock_version column,
+// holding post 1 at version 0
+func newLockingExecutor(t *testing.T) (*DatabaseExecutor, func() (string, int)) {
+	t.Helper()
+	executor, db := newSQLiteExecutor(t)
+	execAll(t, db,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT, lock_version INTEGER NOT NULL DEFAULT 0)",
+		"INSERT INTO posts (id, title) VALUES (1, 'first')",
+	)
+	ctx := context.Background()
+	post := func() (string, int) {
+		t.Helper()
+		var title string
+		var version int
+		if err := db.QueryRow(ctx, "SELECT title, lock_version FROM posts WHERE id = 1").Scan(&title, &version); err != nil {
+			t.Fatal(err)
+		}
+		return title, version
+	}
+	return executor, post
+}
+
+// updateAtVersion runs a versioned update of a record and decodes the response
+func updateAtVersion(t *testing.T, executor *DatabaseExecutor, table string, id any, data map[string]any, version any) OperationResponse {
+	t.Helper()
+	response, err := executor.UpdateRecordAtVersion(context.Background(), table, id, data, version, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded OperationResponse
+	if err := json.Unmarshal(response, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestUpdateRecordAtVersion(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("concurrent updates conflict instead of overwriting", func(t *testing.T) {
+		executor, post := newLockingExecutor(t)
+
+		// Both editors loaded the post at version 0
+		first := updateAtVersion(t, executor, "posts", 1, map[string]any{"title": "alice"}, 0)
+		if !first.Success || first.Data[0]["lock_version"] != float64(1) {
+			t.Fatalf("Expected the first update to apply and return version 1, got %+v", first)
+		}
+
+		second := updateAtVersion(t, executor, "posts", 1, map[string]any{"title": "bob", "lock_version": 0}, 0)
+		if second.Success || !second.Conflict {
+			t.Fatalf("Expected the second update to conflict, got %+v", second)
+		}
+		if len(second.Data) != 1 || second.Data[0]["title"] != "alice" || second.Data[0]["lock_version"] != float64(1) {
+			t.Errorf("Expected the conflict to return the latest record, got %v", second.Data)
+		}
+
+		if title, version := post(); title != "alice" || version != 1 {
+			t.Errorf("Expected alice's update kept at version 1, got %q at %d", title, version)
+		}
+	})
+
+	t.Run("an update at the latest version applies", func(t *testing.T) {
+		executor, post := newLockingExecutor(t)
+		for version, title := range []string{"second", "third"} {
+			if err := responseError(executor.UpdateRecordAtVersion(ctx, "posts", 1, map[string]any{"title": title}, version, nil)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if title, version := post(); title != "third" || version != 2 {
+			t.Errorf("Expected third at version 2, got %q at %d", title, version)
+		}
+	})
+
+	t.Run("a missing record is not a conflict", func(t *testing.T) {
+		executor, _ := newLockingExecutor(t)
+		response := updateAtVersion(t, executor, "posts", 2, map[string]any{"title": "x"}, 0)
+		if !response.Success || response.Conflict || response.Count != 0 {
+			t.Errorf("Expected no rows updated without a conflict, got %+v", response)
+		}
+	})
+
+	t.Run("without a version updates are not checked", func(t *testing.T) {
+		executor, post := newLockingExecutor(t)
+		if err := responseError(executor.UpdateRecordAtVersion(ctx, "posts", 1, map[string]any{"title": "plain"}, nil, nil)); err != nil {
+			t.Fatal(err)
+		}
+		if title, version := post(); title != "plain" || version != 0 {
+			t.Errorf("Expected plain at version 0, got %q at %d", title, version)
+		}
+	})
+
+	t.Run("a configured lock column", func(t *testing.T) {
+		executor, db := newSQLiteExecutor(t)
+		execAll(t, db,
+			"CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT, revision INTEGER NOT NULL DEFAULT 3)",
+			"INSERT INTO notes (id, body) VALUES (1, 'a')",
+		)
+		executor.SetLockColumns(map[string]string{"notes": "revision"})
+
+		if response := updateAtVersion(t, executor, "notes", 1, map[string]any{"body": "b"}, 0); !response.Conflict {
+			t.Errorf("Expected a stale revision to conflict, got %+v", response)
+		}
+		if err := responseError(executor.UpdateRecordAtVersion(ctx, "notes", 1, map[string]any{"body": "b"}, 3, nil)); err != nil {
+			t.Errorf("Expected the current revision to apply, got %v", err)
+		}
+	})
+}
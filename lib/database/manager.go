@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"fulcrum/lib/database/drivers"
 	"fulcrum/lib/database/interfaces"
+	"fulcrum/lib/parser"
 	"log"
 )
 
@@ -12,35 +13,94 @@ import (
 type Manager struct {
 	config   interfaces.Config
 	database interfaces.Database
+
+	replicas []interfaces.Database // Read replicas; those that fail to connect are dropped
+	pool     *ReplicaPool          // Set by Connect when a replica connected
 }
 
-// NewManager creates a new database manager
-func NewManager(config interfaces.Config) (*Manager, error) {
+// NewManager creates a new database manager, with optional read replicas
+func NewManager(config interfaces.Config, replicas ...interfaces.Config) (*Manager, error) {
+	config, err := resolveURL(config)
+	if err != nil {
+		return nil, err
+	}
+
 	manager := &Manager{
 		config: config,
 	}
 
 	// Create the appropriate database driver
-	db, err := manager.createDriver()
+	db, err := createDriver(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create database driver: %w", err)
 	}
-
 	manager.database = db
+
+	for i, replicaConfig := range replicas {
+		replicaConfig, err := resolveURL(replicaConfig)
+		if err != nil {
+			return nil, fmt.Errorf("replica %d: %w", i+1, err)
+		}
+		replica, err := createDriver(replicaConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create database driver for replica %d: %w", i+1, err)
+		}
+		manager.replicas = append(manager.replicas, replica)
+	}
+
 	return manager, nil
 }
 
+// resolveURL fills fields left empty in the config from its connection URL
+func resolveURL(config interfaces.Config) (interfaces.Config, error) {
+	if config.URL == "" {
+		return config, nil
+	}
+
+	fromURL, err := FromParserConfig(parser.DBConfig{URL: config.URL})
+	if err != nil {
+		return config, err
+	}
+
+	if config.Driver == "" {
+		config.Driver = fromURL.Driver
+	}
+	if config.Host == "" {
+		config.Host = fromURL.Host
+	}
+	if config.Port == 0 {
+		config.Port = fromURL.Port
+	}
+	if config.Database == "" {
+		config.Database = fromURL.Database
+	}
+	if config.Username == "" {
+		config.Username = fromURL.Username
+	}
+	if config.Password == "" {
+		config.Password = fromURL.Password
+	}
+	if config.SSLMode == "" {
+		config.SSLMode = fromURL.SSLMode
+	}
+	if config.FilePath == "" {
+		config.FilePath = fromURL.FilePath
+	}
+
+	return config, nil
+}
+
 // createDriver creates the appropriate database driver based on configuration
-func (m *Manager) createDriver() (interfaces.Database, error) {
-	switch m.config.Driver {
+func createDriver(config interfaces.Config) (interfaces.Database, error) {
+	switch config.Driver {
 	case interfaces.DriverPostgreSQL:
-		return drivers.NewPostgreSQLDB(m.config)
+		return drivers.NewPostgreSQLDB(config)
 	case interfaces.DriverMySQL:
-		return drivers.NewMySQLDB(m.config)
+		return drivers.NewMySQLDB(config)
 	case interfaces.DriverSQLite:
-		return drivers.NewSQLiteDB(m.config)
+		return drivers.NewSQLiteDB(config)
 	default:
-		return nil, fmt.Errorf("unsupported database driver: %s", m.config.Driver)
+		return nil, fmt.Errorf("unsupported database driver: %s", config.Driver)
 	}
 }
 
@@ -60,15 +120,50 @@ func (m *Manager) Connect(ctx context.Context) error {
 	}
 
 	log.Printf("Successfully connected to %s database", m.config.Driver)
+
+	// A replica that is down at startup is left out; reads go to the others or the primary
+	var connected []interfaces.Database
+	for i, replica := range m.replicas {
+		if err := connectReplica(ctx, replica); err != nil {
+			log.Printf("⚠️ Leaving out database replica %d: %v", i+1, err)
+			continue
+		}
+		connected = append(connected, replica)
+	}
+	m.replicas = connected
+	if len(connected) > 0 {
+		m.pool = NewReplicaPool(m.database, connected...)
+		log.Printf("📚 Reading from %d database replica(s)", len(connected))
+	}
 	return nil
 }
 
-// Close closes the database connection
+// connectReplica connects to a replica and checks it answers
+func connectReplica(ctx context.Context, replica interfaces.Database) error {
+	if err := replica.Connect(ctx); err != nil {
+		return err
+	}
+	if err := replica.Ping(ctx); err != nil {
+		replica.Close()
+		return err
+	}
+	return nil
+}
+
+// Close closes the database connection and the replicas'
 func (m *Manager) Close() error {
 	log.Printf("Closing database connection...")
+	for _, replica := range m.replicas {
+		replica.Close()
+	}
 	return m.database.Close()
 }
 
+// Replicas returns the pool of connected replicas, or nil when there are none
+func (m *Manager) Replicas() *ReplicaPool {
+	return m.pool
+}
+
 // GetDatabase returns the database instance
 func (m *Manager) GetDatabase() interfaces.Database {
 	return m.database
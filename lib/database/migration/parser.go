@@ -1,8 +1,11 @@
 package migration
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/fs"
+	"log"
 	"os"
 	"path/filepath"
 	"sort"
@@ -148,15 +151,30 @@ func (p *Parser) parseMigrationFile(filePath, domainName string) (Migration, err
 	// Set metadata
 	migration.Domain = domainName
 	migration.FilePath = filePath
+	migration.Checksum = Checksum(content)
 
 	// Validate migration
 	if err := p.validateMigration(&migration); err != nil {
 		return Migration{}, fmt.Errorf("invalid migration: %w", err)
 	}
+	for _, warning := range MigrationWarnings(migration) {
+		log.Printf("⚠️ %s", warning)
+	}
 
 	return migration, nil
 }
 
+// MigrationWarnings describes problems that don't stop a migration from being applied
+func MigrationWarnings(migration Migration) []string {
+	var warnings []string
+	if len(migration.Down) == 0 && !migration.Irreversible {
+		warnings = append(warnings, fmt.Sprintf(
+			"migration %s:%d (%s) has no down operations, so it cannot be rolled back; add them or set irreversible: true",
+			migration.Domain, migration.Version, migration.Name))
+	}
+	return warnings
+}
+
 // validateMigration validates a parsed migration
 func (p *Parser) validateMigration(migration *Migration) error {
 	if migration.Version <= 0 {
@@ -171,6 +189,10 @@ func (p *Parser) validateMigration(migration *Migration) error {
 		return fmt.Errorf("up operations are required")
 	}
 
+	if migration.Irreversible && len(migration.Down) > 0 {
+		return fmt.Errorf("irreversible migrations cannot define down operations")
+	}
+
 	// Validate each operation
 	for i, op := range migration.Up {
 		if err := p.validateOperation(&op); err != nil {
@@ -187,33 +209,49 @@ func (p *Parser) validateMigration(migration *Migration) error {
 	return nil
 }
 
-// ParseYAMLFile parses a migration YAML file by path
+// ParseYAMLFile parses and validates a migration YAML file, naming the file in errors
 func ParseYAMLFile(filePath string) (*Migration, error) {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read migration file: %w", err)
+		return nil, fmt.Errorf("failed to read migration file %s: %w", filePath, err)
 	}
-	
-	return ParseYAMLContent(content)
+
+	migration, err := ParseYAMLContent(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	migration.FilePath = filePath
+	return migration, nil
 }
 
-// ParseYAMLContent parses migration YAML content from bytes
+// ParseYAMLContent parses migration YAML content and validates it with ValidateMigration
 func ParseYAMLContent(content []byte) (*Migration, error) {
 	var migration Migration
-	err := yaml.Unmarshal(content, &migration)
-	if err != nil {
+	if err := yaml.Unmarshal(content, &migration); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
-	
-	// Create a temporary parser for validation
-	p := &Parser{}
-	if err := p.validateMigration(&migration); err != nil {
+
+	if err := ValidateMigration(migration); err != nil {
 		return nil, fmt.Errorf("invalid migration: %w", err)
 	}
-	
+
+	migration.Checksum = Checksum(content)
 	return &migration, nil
 }
 
+// ValidateMigration checks that a migration has a name, a version of at least 1 and
+// up operations, and that each of its operations is well formed
+func ValidateMigration(migration Migration) error {
+	p := &Parser{}
+	return p.validateMigration(&migration)
+}
+
+// Checksum returns the sha256 of a migration file's contents
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
 // validateOperation validates a single migration operation
 func (p *Parser) validateOperation(op *MigrationOperation) error {
 	operationCount := 0
@@ -226,6 +264,20 @@ func (p *Parser) validateOperation(op *MigrationOperation) error {
 		if len(op.CreateTable.Columns) == 0 {
 			return fmt.Errorf("create_table: at least one column is required")
 		}
+		if len(op.CreateTable.PrimaryKey) > 0 {
+			columns := make(map[string]bool)
+			for _, col := range op.CreateTable.Columns {
+				if col.PrimaryKey {
+					return fmt.Errorf("create_table: column %s sets primary_key, use either the table primary_key or column primary_key flags", col.Name)
+				}
+				columns[col.Name] = true
+			}
+			for _, name := range op.CreateTable.PrimaryKey {
+				if !columns[name] {
+					return fmt.Errorf("create_table: primary_key column %s is not defined", name)
+				}
+			}
+		}
 	}
 	
 	if op.DropTable != nil {
@@ -235,6 +287,13 @@ func (p *Parser) validateOperation(op *MigrationOperation) error {
 		}
 	}
 	
+	if op.RenameTable != nil {
+		operationCount++
+		if op.RenameTable.From == "" || op.RenameTable.To == "" {
+			return fmt.Errorf("rename_table: from and to are required")
+		}
+	}
+	
 	if op.AddColumn != nil {
 		operationCount++
 		if op.AddColumn.Table == "" || op.AddColumn.Name == "" || op.AddColumn.Type == "" {
@@ -256,6 +315,13 @@ func (p *Parser) validateOperation(op *MigrationOperation) error {
 		}
 	}
 	
+	if op.RenameColumn != nil {
+		operationCount++
+		if op.RenameColumn.Table == "" || op.RenameColumn.From == "" || op.RenameColumn.To == "" {
+			return fmt.Errorf("rename_column: table, from, and to are required")
+		}
+	}
+	
 	if op.AddIndex != nil {
 		operationCount++
 		if op.AddIndex.Table == "" || len(op.AddIndex.Columns) == 0 {
@@ -276,12 +342,26 @@ func (p *Parser) validateOperation(op *MigrationOperation) error {
 		if fk.Table == "" || fk.Column == "" || fk.ReferencedTable == "" || fk.ReferencedColumn == "" {
 			return fmt.Errorf("add_foreign_key: table, column, referenced_table, and referenced_column are required")
 		}
+		if fk.OnDelete != "" {
+			if _, err := referentialAction(fk.OnDelete); err != nil {
+				return fmt.Errorf("add_foreign_key: on_delete %w", err)
+			}
+		}
+		if fk.OnUpdate != "" {
+			if _, err := referentialAction(fk.OnUpdate); err != nil {
+				return fmt.Errorf("add_foreign_key: on_update %w", err)
+			}
+		}
 	}
 	
 	if op.DropForeignKey != nil {
 		operationCount++
-		if op.DropForeignKey.Name == "" {
-			return fmt.Errorf("drop_foreign_key: name is required")
+		fk := op.DropForeignKey
+		if fk.Table == "" {
+			return fmt.Errorf("drop_foreign_key: table is required")
+		}
+		if fk.Name == "" && (fk.Column == "" || fk.ReferencedTable == "") {
+			return fmt.Errorf("drop_foreign_key: name, or column and referenced_table, is required")
 		}
 	}
 	
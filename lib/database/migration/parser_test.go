@@ -489,3 +489,202 @@ func TestValidateMigrationColumn(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRenameColumn(t *testing.T) {
+	content := []byte(`version: 2
+name: rename_name_to_full_name_in_users
+up:
+  - rename_column:
+      table: users
+      from: name
+      to: full_name
+down:
+  - rename_column:
+      table: users
+      from: full_name
+      to: name
+`)
+
+	migration, err := ParseYAMLContent(content)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	op := migration.Up[0].RenameColumn
+	if op == nil || op.Table != "users" || op.From != "name" || op.To != "full_name" {
+		t.Errorf("Expected rename of users.name to full_name, got %+v", op)
+	}
+
+	p := NewParser(t.TempDir())
+	if err := p.validateOperation(&MigrationOperation{RenameColumn: &RenameColumnOp{Table: "users", From: "name"}}); err == nil || !strings.Contains(err.Error(), "rename_column") {
+		t.Errorf("Expected rename_column validation error, got %v", err)
+	}
+	if err := p.validateOperation(&migration.Up[0]); err != nil {
+		t.Errorf("Unexpected validation error: %v", err)
+	}
+}
+
+func TestParseRenameTable(t *testing.T) {
+	content := []byte(`version: 3
+name: rename_people_to_users
+up:
+  - rename_table:
+      from: people
+      to: users
+down:
+  - rename_table:
+      from: users
+      to: people
+`)
+
+	migration, err := ParseYAMLContent(content)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	up, down := migration.Up[0].RenameTable, migration.Down[0].RenameTable
+	if up == nil || down == nil {
+		t.Fatalf("Expected rename_table in up and down, got %+v / %+v", up, down)
+	}
+	if *down != *up.Reverse() {
+		t.Errorf("Expected down to reverse up, got %+v", down)
+	}
+
+	p := NewParser(t.TempDir())
+	if err := p.validateOperation(&MigrationOperation{RenameTable: &RenameTableOp{From: "people"}}); err == nil || !strings.Contains(err.Error(), "rename_table") {
+		t.Errorf("Expected rename_table validation error, got %v", err)
+	}
+}
+
+func TestValidateDropForeignKeyOperation(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       DropForeignKeyOp
+		errorMsg string
+	}{
+		{name: "named", op: DropForeignKeyOp{Table: "orders", Name: "fk_orders_user"}},
+		{name: "derived", op: DropForeignKeyOp{Table: "orders", Column: "user_id", ReferencedTable: "users"}},
+		{name: "missing table", op: DropForeignKeyOp{Name: "fk_orders_user"}, errorMsg: "table is required"},
+		{name: "missing name", op: DropForeignKeyOp{Table: "orders", Column: "user_id"}, errorMsg: "name, or column and referenced_table"},
+	}
+
+	p := NewParser(t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := tt.op
+			err := p.validateOperation(&MigrationOperation{DropForeignKey: &op})
+
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestParseAddForeignKeyActions(t *testing.T) {
+	content := []byte(`version: 2
+name: add_orders_user_fk
+up:
+  - add_foreign_key:
+      table: orders
+      column: user_id
+      referenced_table: users
+      referenced_column: id
+      on_delete: cascade
+      on_update: restrict
+down:
+  - drop_foreign_key:
+      table: orders
+      column: user_id
+      referenced_table: users
+`)
+	migration, err := ParseYAMLContent(content)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fk := migration.Up[0].AddForeignKey
+	if fk == nil || fk.OnDelete != "cascade" || fk.OnUpdate != "restrict" {
+		t.Fatalf("Expected on_delete and on_update to be parsed, got %+v", fk)
+	}
+
+	invalid := []byte(strings.Replace(string(content), "on_update: restrict", "on_update: nothing", 1))
+	if _, err := ParseYAMLContent(invalid); err == nil || !strings.Contains(err.Error(), "add_foreign_key: on_update must be one of") {
+		t.Errorf("Expected an on_update validation error, got %v", err)
+	}
+}
+
+func TestValidateCreateTablePrimaryKey(t *testing.T) {
+	columns := []MigrationColumn{
+		{Name: "user_id", Type: "integer"},
+		{Name: "role_id", Type: "integer"},
+	}
+
+	tests := []struct {
+		name     string
+		op       CreateTableOp
+		errorMsg string
+	}{
+		{name: "table primary_key", op: CreateTableOp{Name: "user_roles", Columns: columns, PrimaryKey: []string{"user_id", "role_id"}}},
+		{name: "unknown column", op: CreateTableOp{Name: "user_roles", Columns: columns, PrimaryKey: []string{"user_id", "team_id"}}, errorMsg: "team_id is not defined"},
+		{
+			name: "mixed with column flag",
+			op: CreateTableOp{Name: "user_roles", PrimaryKey: []string{"role_id"}, Columns: []MigrationColumn{
+				{Name: "user_id", Type: "integer", PrimaryKey: true},
+				{Name: "role_id", Type: "integer"},
+			}},
+			errorMsg: "either the table primary_key",
+		},
+	}
+
+	p := NewParser(t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := tt.op
+			err := p.validateOperation(&MigrationOperation{CreateTable: &op})
+
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestParseTablePrimaryKey(t *testing.T) {
+	content := []byte(`version: 3
+name: create_user_roles
+up:
+  - create_table:
+      name: user_roles
+      primary_key: [user_id, role_id]
+      columns:
+        - name: user_id
+          type: integer
+        - name: role_id
+          type: integer
+down:
+  - drop_table:
+      name: user_roles
+`)
+
+	migration, err := ParseYAMLContent(content)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	primaryKey := migration.Up[0].CreateTable.PrimaryKey
+	if len(primaryKey) != 2 || primaryKey[0] != "user_id" || primaryKey[1] != "role_id" {
+		t.Errorf("Expected primary_key [user_id role_id], got %v", primaryKey)
+	}
+}
@@ -3,8 +3,11 @@ package migration
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"sort"
+	"strings"
 
 	"fulcrum/lib/database/interfaces"
 )
@@ -15,6 +18,14 @@ type Runner struct {
 	parser       *Parser
 	tracker      *Tracker
 	sqlGenerator *SQLGenerator
+
+	// force applies migrations even when applied ones were edited
+	force bool
+
+	// dryRun prints the SQL for each operation instead of executing it
+	dryRun        bool
+	out           io.Writer
+	printedDomain string
 }
 
 // NewRunner creates a new migration runner
@@ -24,11 +35,25 @@ func NewRunner(db interfaces.Database, appPath string) *Runner {
 		parser:       NewParser(appPath),
 		tracker:      NewTracker(db),
 		sqlGenerator: NewSQLGenerator(db.GetDriver()),
+		out:          os.Stdout,
 	}
 }
 
+// SetDryRun makes the runner print SQL without executing it or recording anything
+func (r *Runner) SetDryRun(dryRun bool) {
+	r.dryRun = dryRun
+}
+
+// SetForce lets MigrateUp continue when applied migrations no longer match their checksums
+func (r *Runner) SetForce(force bool) {
+	r.force = force
+}
+
 // Initialize sets up the migration system (creates schema_migrations table)
 func (r *Runner) Initialize(ctx context.Context) error {
+	if r.dryRun {
+		return nil
+	}
 	return r.tracker.InitializeSchema(ctx)
 }
 
@@ -42,8 +67,13 @@ func (r *Runner) MigrateUp(ctx context.Context) error {
 		return fmt.Errorf("failed to load migrations: %w", err)
 	}
 
+	// Refuse to run when applied migrations were edited, since those edits never run
+	if err := r.verifyChecksums(ctx, allMigrations); err != nil {
+		return err
+	}
+
 	// Get pending migrations
-	pendingMigrations, err := r.tracker.GetPendingMigrations(ctx, allMigrations)
+	pendingMigrations, err := r.pendingMigrations(ctx, allMigrations)
 	if err != nil {
 		return fmt.Errorf("failed to get pending migrations: %w", err)
 	}
@@ -63,6 +93,16 @@ func (r *Runner) MigrateUp(ctx context.Context) error {
 
 	log.Printf("📋 Found %d pending migrations", len(pendingMigrations))
 
+	if r.dryRun {
+		diffs, err := r.diffMigrations(pendingMigrations)
+		if err != nil {
+			return err
+		}
+		WriteDiffs(r.out, diffs)
+		log.Printf("📝 Dry run: %d migrations would be applied", len(pendingMigrations))
+		return nil
+	}
+
 	// Execute each migration
 	for _, migration := range pendingMigrations {
 		if err := r.executeMigrationUp(ctx, migration); err != nil {
@@ -80,7 +120,7 @@ func (r *Runner) MigrateDown(ctx context.Context) error {
 	log.Println("🔄 Rolling back migrations...")
 
 	// Get all applied migrations
-	appliedMigrations, err := r.tracker.GetAppliedMigrations(ctx)
+	appliedMigrations, err := r.appliedMigrations(ctx, "")
 	if err != nil {
 		return fmt.Errorf("failed to get applied migrations: %w", err)
 	}
@@ -111,12 +151,20 @@ func (r *Runner) MigrateDown(ctx context.Context) error {
 		migrationMap[key] = migration
 	}
 
-	// Roll back the latest migration for each domain
+	// Roll back the latest migration for each domain, in domain order
+	domains := make([]string, 0, len(domainLatest))
+	for domain := range domainLatest {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	r.printedDomain = ""
 	rollbackCount := 0
-	for _, latestRecord := range domainLatest {
+	for _, domain := range domains {
+		latestRecord := domainLatest[domain]
 		key := fmt.Sprintf("%s:%d", latestRecord.Domain, latestRecord.Version)
 		if migration, exists := migrationMap[key]; exists {
-			if err := r.executeMigrationDown(ctx, migration); err != nil {
+			if err := r.rollBack(ctx, migration); err != nil {
 				return fmt.Errorf("failed to roll back migration %s:%d (%s): %w", 
 					migration.Domain, migration.Version, migration.Name, err)
 			}
@@ -124,6 +172,11 @@ func (r *Runner) MigrateDown(ctx context.Context) error {
 		}
 	}
 
+	if r.dryRun {
+		log.Printf("📝 Dry run: %d migrations would be rolled back", rollbackCount)
+		return nil
+	}
+
 	log.Printf("✅ Successfully rolled back %d migrations", rollbackCount)
 	return nil
 }
@@ -133,7 +186,7 @@ func (r *Runner) MigrateDownTo(ctx context.Context, domain string, targetVersion
 	log.Printf("🔄 Rolling back %s migrations to version %d...", domain, targetVersion)
 
 	// Get applied migrations for the domain
-	appliedMigrations, err := r.tracker.GetAppliedMigrationsForDomain(ctx, domain)
+	appliedMigrations, err := r.appliedMigrations(ctx, domain)
 	if err != nil {
 		return fmt.Errorf("failed to get applied migrations for domain %s: %w", domain, err)
 	}
@@ -169,10 +222,11 @@ func (r *Runner) MigrateDownTo(ctx context.Context, domain string, targetVersion
 	}
 
 	// Roll back each migration
+	r.printedDomain = ""
 	rollbackCount := 0
 	for _, record := range toRollback {
 		if migration, exists := migrationMap[record.Version]; exists {
-			if err := r.executeMigrationDown(ctx, migration); err != nil {
+			if err := r.rollBack(ctx, migration); err != nil {
 				return fmt.Errorf("failed to roll back migration %s:%d (%s): %w", 
 					migration.Domain, migration.Version, migration.Name, err)
 			}
@@ -180,6 +234,11 @@ func (r *Runner) MigrateDownTo(ctx context.Context, domain string, targetVersion
 		}
 	}
 
+	if r.dryRun {
+		log.Printf("📝 Dry run: %d migrations would be rolled back for domain %s", rollbackCount, domain)
+		return nil
+	}
+
 	log.Printf("✅ Successfully rolled back %d migrations for domain %s", rollbackCount, domain)
 	return nil
 }
@@ -194,10 +253,215 @@ func (r *Runner) GetStatus(ctx context.Context) ([]MigrationStatus, error) {
 	return r.tracker.GetMigrationStatus(ctx, allMigrations)
 }
 
+// verifyChecksums compares applied migrations against their files on disk
+func (r *Runner) verifyChecksums(ctx context.Context, allMigrations []Migration) error {
+	applied, err := r.appliedMigrations(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	mismatches := FindChecksumMismatches(applied, allMigrations)
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	if r.force {
+		for _, mismatch := range mismatches {
+			log.Printf("⚠️  %s", mismatch)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("applied migrations have changed (use --force to continue):\n  - %s",
+		strings.Join(mismatches, "\n  - "))
+}
+
+// MigrateUpDry returns the SQL each pending migration would execute, without executing
+// anything or creating the schema_migrations table
+func (r *Runner) MigrateUpDry(ctx context.Context) ([]MigrationDiff, error) {
+	allMigrations, err := r.parser.LoadAllMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	pendingMigrations, err := r.readOnlyPendingMigrations(ctx, allMigrations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending migrations: %w", err)
+	}
+
+	return r.diffMigrations(pendingMigrations)
+}
+
+// PendingMigrations returns the migrations MigrateUp would apply, without creating the
+// schema_migrations table
+func (r *Runner) PendingMigrations(ctx context.Context) ([]Migration, error) {
+	allMigrations, err := r.parser.LoadAllMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return r.readOnlyPendingMigrations(ctx, allMigrations)
+}
+
+// pendingMigrations returns unapplied migrations; a dry run treats a missing
+// schema_migrations table as nothing applied instead of creating it
+func (r *Runner) pendingMigrations(ctx context.Context, allMigrations []Migration) ([]Migration, error) {
+	if r.dryRun {
+		return r.readOnlyPendingMigrations(ctx, allMigrations)
+	}
+	return r.tracker.GetPendingMigrations(ctx, allMigrations)
+}
+
+// readOnlyPendingMigrations finds unapplied migrations without creating schema_migrations
+func (r *Runner) readOnlyPendingMigrations(ctx context.Context, allMigrations []Migration) ([]Migration, error) {
+	exists, err := r.db.TableExists(ctx, "schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if schema_migrations table exists: %w", err)
+	}
+	if !exists {
+		return allMigrations, nil
+	}
+	return r.tracker.GetPendingMigrations(ctx, allMigrations)
+}
+
+// appliedMigrations returns applied migrations, optionally for one domain
+func (r *Runner) appliedMigrations(ctx context.Context, domain string) ([]MigrationRecord, error) {
+	if r.dryRun {
+		exists, err := r.db.TableExists(ctx, "schema_migrations")
+		if err != nil {
+			return nil, fmt.Errorf("failed to check if schema_migrations table exists: %w", err)
+		}
+		if !exists {
+			return nil, nil
+		}
+	}
+
+	if domain == "" {
+		return r.tracker.GetAppliedMigrations(ctx)
+	}
+	return r.tracker.GetAppliedMigrationsForDomain(ctx, domain)
+}
+
+// rollBack executes or, in a dry run, prints the down operations of a migration
+func (r *Runner) rollBack(ctx context.Context, migration Migration) error {
+	if err := checkReversible(migration); err != nil {
+		return err
+	}
+
+	if !r.dryRun {
+		return r.executeMigrationDown(ctx, migration)
+	}
+	return r.printMigrationSQL(migration, migration.Down, "down")
+}
+
+// checkReversible fails for migrations that have nothing to roll back with
+func checkReversible(migration Migration) error {
+	if migration.Irreversible {
+		return fmt.Errorf("migration %s:%d (%s) is marked irreversible and cannot be rolled back",
+			migration.Domain, migration.Version, migration.Name)
+	}
+	if len(migration.Down) == 0 {
+		return fmt.Errorf("migration %s:%d has no down operations defined", migration.Domain, migration.Version)
+	}
+	return nil
+}
+
+// printMigrationSQL writes the SQL for a migration's operations, grouped under its domain
+func (r *Runner) printMigrationSQL(migration Migration, operations []MigrationOperation, direction string) error {
+	diff, err := r.diffMigration(migration, operations, direction)
+	if err != nil {
+		return err
+	}
+	writeDiff(r.out, diff, &r.printedDomain)
+	return nil
+}
+
+// diffMigrations generates the up SQL for each migration
+func (r *Runner) diffMigrations(migrations []Migration) ([]MigrationDiff, error) {
+	diffs := make([]MigrationDiff, 0, len(migrations))
+	for _, migration := range migrations {
+		diff, err := r.diffMigration(migration, migration.Up, "up")
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// diffMigration generates the SQL for a migration's operations without executing it
+func (r *Runner) diffMigration(migration Migration, operations []MigrationOperation, direction string) (MigrationDiff, error) {
+	diff := MigrationDiff{
+		Domain:    migration.Domain,
+		Version:   migration.Version,
+		Name:      migration.Name,
+		Direction: direction,
+	}
+
+	for i, operation := range operations {
+		sql, err := r.sqlGenerator.GenerateSQL(&operation)
+		if err != nil {
+			return MigrationDiff{}, fmt.Errorf("failed to generate SQL for %s operation %d of %s:%d: %w",
+				direction, i, migration.Domain, migration.Version, err)
+		}
+		diff.SQL = append(diff.SQL, sql)
+	}
+
+	return diff, nil
+}
+
+// WriteDiffs prints migration diffs as SQL, grouped by domain and ordered as given
+func WriteDiffs(w io.Writer, diffs []MigrationDiff) {
+	printedDomain := ""
+	for _, diff := range diffs {
+		writeDiff(w, diff, &printedDomain)
+	}
+}
+
+// writeDiff prints one diff, adding a domain header when the domain changes
+func writeDiff(w io.Writer, diff MigrationDiff, printedDomain *string) {
+	if diff.Domain != *printedDomain {
+		fmt.Fprintf(w, "-- Domain: %s\n", diff.Domain)
+		*printedDomain = diff.Domain
+	}
+	fmt.Fprintf(w, "-- Version %d (%s): %s\n", diff.Version, diff.Direction, diff.Name)
+
+	for _, sql := range diff.SQL {
+		fmt.Fprintf(w, "%s;\n", sql)
+	}
+	fmt.Fprintln(w)
+}
+
+// checkRenameColumn fails when operations rename a column on a MySQL server too old to
+// support RENAME COLUMN, before any of them run
+func (r *Runner) checkRenameColumn(ctx context.Context, operations []MigrationOperation) error {
+	if r.db.GetDriver() != interfaces.DriverMySQL {
+		return nil
+	}
+	for _, operation := range operations {
+		if operation.RenameColumn == nil {
+			continue
+		}
+		var version string
+		if err := r.db.QueryRow(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+			return fmt.Errorf("failed to read the MySQL server version: %w", err)
+		}
+		if !mysqlRenamesColumns(version) {
+			return fmt.Errorf("rename_column %s.%s needs MySQL 8.0 or MariaDB 10.5 or later, but the server is %s; rename it with an execute operation using ALTER TABLE ... CHANGE instead",
+				operation.RenameColumn.Table, operation.RenameColumn.From, version)
+		}
+		return nil
+	}
+	return nil
+}
+
 // executeMigrationUp executes the up operations of a migration
 func (r *Runner) executeMigrationUp(ctx context.Context, migration Migration) error {
 	log.Printf("⬆️  Applying migration %s:%d - %s", migration.Domain, migration.Version, migration.Name)
 
+	if err := r.checkRenameColumn(ctx, migration.Up); err != nil {
+		return err
+	}
+
 	// Begin transaction
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -226,10 +490,10 @@ func (r *Runner) executeMigrationUp(ctx context.Context, migration Migration) er
 
 	// Record the migration in schema_migrations table
 	insertSQL := `
-		INSERT INTO schema_migrations (version, domain, name, applied_at)
-		VALUES ($1, $2, $3, NOW())`
+		INSERT INTO schema_migrations (version, domain, name, applied_at, checksum)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP, $4)`
 	
-	_, err = tx.Exec(ctx, insertSQL, migration.Version, migration.Domain, migration.Name)
+	_, err = tx.Exec(ctx, insertSQL, migration.Version, migration.Domain, migration.Name, migration.Checksum)
 	if err != nil {
 		return fmt.Errorf("failed to record migration: %w", err)
 	}
@@ -248,8 +512,11 @@ func (r *Runner) executeMigrationUp(ctx context.Context, migration Migration) er
 func (r *Runner) executeMigrationDown(ctx context.Context, migration Migration) error {
 	log.Printf("⬇️  Rolling back migration %s:%d - %s", migration.Domain, migration.Version, migration.Name)
 
-	if len(migration.Down) == 0 {
-		return fmt.Errorf("migration %s:%d has no down operations defined", migration.Domain, migration.Version)
+	if err := checkReversible(migration); err != nil {
+		return err
+	}
+	if err := r.checkRenameColumn(ctx, migration.Down); err != nil {
+		return err
 	}
 
 	// Begin transaction
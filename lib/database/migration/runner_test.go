@@ -1,10 +1,15 @@
 package migration
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
+	"fulcrum/lib/database/drivers"
 	"fulcrum/lib/database/interfaces"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -256,3 +261,395 @@ func TestMockDatabase(t *testing.T) {
 		t.Error("Expected error but got none")
 	}
 }
+
+func writeTestMigration(t *testing.T, appPath, domain, fileName, content string) {
+	t.Helper()
+
+	dir := filepath.Join(appPath, "domains", domain, "migrations")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, fileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateUpDryRun(t *testing.T) {
+	appPath := t.TempDir()
+	writeTestMigration(t, appPath, "users", "001_create_users.yml", `
+version: 1
+name: create_users
+up:
+  - create_table:
+      name: users
+      columns:
+        - name: id
+          type: integer
+          primary_key: true
+        - name: email
+          type: string
+down:
+  - drop_table:
+      name: users
+`)
+	writeTestMigration(t, appPath, "users", "002_add_phone.yml", `
+version: 2
+name: add_phone
+up:
+  - add_column:
+      table: users
+      name: phone
+      type: string
+      nullable: true
+down:
+  - drop_column:
+      table: users
+      name: phone
+`)
+	writeTestMigration(t, appPath, "posts", "001_create_posts.yml", `
+version: 1
+name: create_posts
+up:
+  - create_table:
+      name: posts
+      columns:
+        - name: id
+          type: integer
+          primary_key: true
+down:
+  - drop_table:
+      name: posts
+`)
+
+	mockDB := &MockDatabase{}
+	runner := NewRunner(mockDB, appPath)
+	runner.SetDryRun(true)
+
+	var out bytes.Buffer
+	runner.out = &out
+
+	ctx := context.Background()
+	if err := runner.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if err := runner.MigrateUp(ctx); err != nil {
+		t.Fatalf("MigrateUp dry run failed: %v", err)
+	}
+
+	if len(mockDB.queries) != 0 {
+		t.Errorf("Expected no statements to be executed, got %v", mockDB.queries)
+	}
+	if mockDB.txMode {
+		t.Error("Expected no transaction to be started")
+	}
+
+	output := out.String()
+	for _, expected := range []string{
+		"-- Domain: posts",
+		"-- Domain: users",
+		"-- Version 1 (up): create_users",
+		"-- Version 2 (up): add_phone",
+		"CREATE TABLE users",
+		"ALTER TABLE users ADD COLUMN phone",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected dry run output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	// Output is grouped by domain, then ordered by version
+	if strings.Index(output, "-- Domain: posts") > strings.Index(output, "-- Domain: users") {
+		t.Error("Expected posts to be printed before users")
+	}
+	if strings.Index(output, "create_users") > strings.Index(output, "add_phone") {
+		t.Error("Expected version 1 to be printed before version 2")
+	}
+	if strings.Count(output, "-- Domain: users") != 1 {
+		t.Errorf("Expected a single users group, got:\n%s", output)
+	}
+}
+
+func TestMigrateUpDryReturnsDiffs(t *testing.T) {
+	appPath := t.TempDir()
+	writeTestMigration(t, appPath, "users", "001_create_users.yml", `
+version: 1
+name: create_users
+up:
+  - create_table:
+      name: users
+      columns:
+        - name: id
+          type: integer
+          primary_key: true
+  - add_index:
+      table: users
+      columns: [id]
+      name: idx_users_id
+down:
+  - drop_table:
+      name: users
+`)
+
+	mockDB := &MockDatabase{}
+	runner := NewRunner(mockDB, appPath)
+
+	diffs, err := runner.MigrateUpDry(context.Background())
+	if err != nil {
+		t.Fatalf("MigrateUpDry failed: %v", err)
+	}
+
+	if len(mockDB.queries) != 0 || mockDB.txMode {
+		t.Errorf("Expected nothing to be executed, got %v", mockDB.queries)
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("Expected 1 diff, got %d", len(diffs))
+	}
+
+	diff := diffs[0]
+	if diff.Domain != "users" || diff.Version != 1 || diff.Name != "create_users" {
+		t.Errorf("Unexpected diff metadata: %+v", diff)
+	}
+	if len(diff.SQL) != 2 {
+		t.Fatalf("Expected SQL for both operations, got %v", diff.SQL)
+	}
+	if !strings.HasPrefix(diff.SQL[0], "CREATE TABLE users") || !strings.Contains(diff.SQL[1], "idx_users_id") {
+		t.Errorf("Unexpected SQL: %v", diff.SQL)
+	}
+}
+
+func TestFindChecksumMismatches(t *testing.T) {
+	const original = `
+version: 1
+name: create_users
+up:
+  - create_table:
+      name: users
+      columns:
+        - name: id
+          type: integer
+          primary_key: true
+`
+
+	appPath := t.TempDir()
+	writeTestMigration(t, appPath, "users", "001_create_users.yml", original)
+
+	parser := NewParser(appPath)
+	migrations, err := parser.LoadAllMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrations[0].Checksum == "" {
+		t.Fatal("Expected parsed migration to carry a checksum")
+	}
+
+	// Recorded as applied with the checksum it had at apply time
+	applied := []MigrationRecord{{
+		Domain:   "users",
+		Version:  1,
+		Name:     "create_users",
+		Checksum: migrations[0].Checksum,
+	}}
+
+	t.Run("unchanged migration passes", func(t *testing.T) {
+		if mismatches := FindChecksumMismatches(applied, migrations); len(mismatches) != 0 {
+			t.Errorf("Expected no mismatches, got %v", mismatches)
+		}
+	})
+
+	t.Run("tampered migration is flagged", func(t *testing.T) {
+		writeTestMigration(t, appPath, "users", "001_create_users.yml", original+`        - name: email
+          type: string
+`)
+		tampered, err := parser.LoadAllMigrations()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mismatches := FindChecksumMismatches(applied, tampered)
+		if len(mismatches) != 1 || !strings.Contains(mismatches[0], "users:1") {
+			t.Errorf("Expected users:1 to be flagged, got %v", mismatches)
+		}
+	})
+
+	t.Run("records without a checksum are skipped", func(t *testing.T) {
+		legacy := []MigrationRecord{{Domain: "users", Version: 1, Name: "create_users"}}
+		if mismatches := FindChecksumMismatches(legacy, migrations); len(mismatches) != 0 {
+			t.Errorf("Expected legacy records to be skipped, got %v", mismatches)
+		}
+	})
+}
+
+func TestFindOrphanedRecords(t *testing.T) {
+	migrations := []Migration{
+		{Domain: "users", Version: 1, Name: "create_users"},
+		{Domain: "users", Version: 2, Name: "add_email"},
+	}
+	applied := []MigrationRecord{
+		{Domain: "users", Version: 1, Name: "create_users"},
+		{Domain: "users", Version: 3, Name: "add_avatar"},
+		{Domain: "orders", Version: 1, Name: "create_orders"},
+	}
+
+	orphaned := FindOrphanedRecords(applied, migrations)
+	if len(orphaned) != 2 {
+		t.Fatalf("Expected 2 orphaned records, got %v", orphaned)
+	}
+	if !strings.Contains(orphaned[0], "users:3") || !strings.Contains(orphaned[1], "orders:1") {
+		t.Errorf("Expected users:3 and orders:1 to be reported, got %v", orphaned)
+	}
+}
+
+// newSQLiteDB connects to a fresh SQLite database, closed when the test ends, and runs
+// statements on it
+func newSQLiteDB(t *testing.T, statements ...string) interfaces.Database {
+	t.Helper()
+	db, err := drivers.NewSQLiteDB(interfaces.Config{FilePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, statement := range statements {
+		if _, err := db.Exec(context.Background(), statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// newSQLiteRunner returns a runner for appPath backed by a fresh SQLite database
+func newSQLiteRunner(t *testing.T, appPath string) (*Runner, interfaces.Database) {
+	t.Helper()
+	db := newSQLiteDB(t)
+
+	runner := NewRunner(db, appPath)
+	if err := runner.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return runner, db
+}
+
+func TestMigrateDownReversibility(t *testing.T) {
+	const createUsers = `
+version: 1
+name: create_users
+up:
+  - create_table:
+      name: users
+      columns:
+        - name: id
+          type: integer
+          primary_key: true
+down:
+  - drop_table:
+      name: users
+`
+
+	tests := []struct {
+		name        string
+		migration   string
+		errorMsg    string
+		tableExists bool
+	}{
+		{
+			name: "reversible migration rolls back",
+			migration: `
+version: 2
+name: add_email
+up:
+  - add_column:
+      table: users
+      name: email
+      type: string
+      nullable: true
+down:
+  - drop_column:
+      table: users
+      name: email
+`,
+		},
+		{
+			name: "irreversible migration is rejected",
+			migration: `
+version: 2
+name: drop_legacy
+irreversible: true
+up:
+  - drop_table:
+      name: legacy
+`,
+			errorMsg: "is marked irreversible and cannot be rolled back",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appPath := t.TempDir()
+			writeTestMigration(t, appPath, "users", "001_create_users.yml", createUsers)
+			writeTestMigration(t, appPath, "users", "002_change.yml", tt.migration)
+
+			runner, db := newSQLiteRunner(t, appPath)
+			ctx := context.Background()
+			if _, err := db.Exec(ctx, "CREATE TABLE legacy (id INTEGER)"); err != nil {
+				t.Fatal(err)
+			}
+			if err := runner.MigrateUp(ctx); err != nil {
+				t.Fatalf("MigrateUp failed: %v", err)
+			}
+
+			err := runner.MigrateDown(ctx)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errorMsg, err)
+				}
+			} else if err != nil {
+				t.Fatalf("MigrateDown failed: %v", err)
+			}
+
+			// Version 2 stays applied only when it could not be rolled back
+			applied, err := runner.tracker.IsMigrationApplied(ctx, "users", 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if applied != (tt.errorMsg != "") {
+				t.Errorf("Expected version 2 applied: %v, got %v", tt.errorMsg != "", applied)
+			}
+		})
+	}
+}
+
+func TestMigrationWarnings(t *testing.T) {
+	up := []MigrationOperation{{DropTable: &DropTableOp{Name: "legacy"}}}
+	down := []MigrationOperation{{DropTable: &DropTableOp{Name: "users"}}}
+
+	tests := []struct {
+		name      string
+		migration Migration
+		warns     bool
+	}{
+		{name: "reversible", migration: Migration{Version: 1, Name: "change", Up: up, Down: down}},
+		{name: "missing down", migration: Migration{Version: 1, Name: "change", Up: up}, warns: true},
+		{name: "irreversible flag permits no down", migration: Migration{Version: 1, Name: "change", Up: up, Irreversible: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (&Parser{}).validateMigration(&tt.migration); err != nil {
+				t.Fatalf("Unexpected validation error: %v", err)
+			}
+			if warnings := MigrationWarnings(tt.migration); (len(warnings) > 0) != tt.warns {
+				t.Errorf("Expected warnings: %v, got %v", tt.warns, warnings)
+			}
+		})
+	}
+
+	t.Run("irreversible with down operations is invalid", func(t *testing.T) {
+		migration := Migration{Version: 1, Name: "change", Up: up, Down: down, Irreversible: true}
+		err := (&Parser{}).validateMigration(&migration)
+		if err == nil || !strings.Contains(err.Error(), "irreversible migrations cannot define down operations") {
+			t.Errorf("Expected an irreversible/down conflict, got %v", err)
+		}
+	})
+}
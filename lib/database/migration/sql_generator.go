@@ -2,6 +2,9 @@ package migration
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"fulcrum/lib/database/interfaces"
@@ -26,12 +29,16 @@ func (g *SQLGenerator) GenerateSQL(operation *MigrationOperation) (string, error
 		return g.generateCreateTable(operation.CreateTable)
 	case operation.DropTable != nil:
 		return g.generateDropTable(operation.DropTable)
+	case operation.RenameTable != nil:
+		return g.generateRenameTable(operation.RenameTable)
 	case operation.AddColumn != nil:
 		return g.generateAddColumn(operation.AddColumn)
 	case operation.DropColumn != nil:
 		return g.generateDropColumn(operation.DropColumn)
 	case operation.ChangeColumn != nil:
 		return g.generateChangeColumn(operation.ChangeColumn)
+	case operation.RenameColumn != nil:
+		return g.generateRenameColumn(operation.RenameColumn)
 	case operation.AddIndex != nil:
 		return g.generateAddIndex(operation.AddIndex)
 	case operation.DropIndex != nil:
@@ -52,18 +59,40 @@ func (g *SQLGenerator) generateCreateTable(op *CreateTableOp) (string, error) {
 	var columns []string
 	var constraints []string
 
+	// A table has one primary key, so flagged columns form a single composite key
+	primaryKey := append([]string{}, op.PrimaryKey...)
 	for _, col := range op.Columns {
+		if col.PrimaryKey {
+			primaryKey = append(primaryKey, col.Name)
+		}
+	}
+
+	// SQLite only auto-increments an INTEGER PRIMARY KEY declared on the column itself
+	autoIncrementKey := ""
+	if g.driver == interfaces.DriverSQLite && len(primaryKey) == 1 {
+		for _, col := range op.Columns {
+			if col.Name == primaryKey[0] && strings.EqualFold(col.Type, "serial") {
+				autoIncrementKey = col.Name
+			}
+		}
+	}
+
+	if len(primaryKey) > 0 && autoIncrementKey == "" {
+		constraints = append(constraints, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(primaryKey, ", ")))
+	}
+
+	for _, col := range op.Columns {
+		if col.Name == autoIncrementKey {
+			columns = append(columns, col.Name+" INTEGER PRIMARY KEY AUTOINCREMENT")
+			continue
+		}
+
 		colSQL, err := g.generateColumnDefinition(&col)
 		if err != nil {
 			return "", fmt.Errorf("failed to generate column definition for %s: %w", col.Name, err)
 		}
 		columns = append(columns, colSQL)
 
-		// Handle primary key constraint
-		if col.PrimaryKey {
-			constraints = append(constraints, fmt.Sprintf("PRIMARY KEY (%s)", col.Name))
-		}
-
 		// Handle unique constraint
 		if col.Unique && !col.PrimaryKey {
 			constraints = append(constraints, fmt.Sprintf("UNIQUE (%s)", col.Name))
@@ -84,6 +113,14 @@ func (g *SQLGenerator) generateDropTable(op *DropTableOp) (string, error) {
 	return fmt.Sprintf("DROP TABLE IF EXISTS %s", op.Name), nil
 }
 
+// generateRenameTable generates the driver's table rename SQL
+func (g *SQLGenerator) generateRenameTable(op *RenameTableOp) (string, error) {
+	if g.driver == interfaces.DriverMySQL {
+		return fmt.Sprintf("RENAME TABLE %s TO %s", op.From, op.To), nil
+	}
+	return fmt.Sprintf("ALTER TABLE %s RENAME TO %s", op.From, op.To), nil
+}
+
 // generateAddColumn generates ALTER TABLE ADD COLUMN SQL
 func (g *SQLGenerator) generateAddColumn(op *AddColumnOp) (string, error) {
 	colDef, err := g.generateColumnDefinitionFromAddColumn(op)
@@ -130,6 +167,31 @@ func (g *SQLGenerator) generateChangeColumn(op *ChangeColumnOp) (string, error)
 	return strings.Join(alterations, ";\n"), nil
 }
 
+// generateRenameColumn generates column rename SQL, which every driver shares: SQLite
+// needs 3.25+ and MySQL 8.0+ (MariaDB 10.5+), which the runner checks before migrating.
+// MySQL's older CHANGE would need the column's full definition, which a rename lacks.
+func (g *SQLGenerator) generateRenameColumn(op *RenameColumnOp) (string, error) {
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", op.Table, op.From, op.To), nil
+}
+
+// mysqlRenamesColumns reports whether a server reporting version, as SELECT VERSION()
+// does, supports RENAME COLUMN: MySQL 8.0 or MariaDB 10.5 and later
+func mysqlRenamesColumns(version string) bool {
+	numbers := strings.SplitN(strings.SplitN(version, "-", 2)[0], ".", 3)
+	major, err := strconv.Atoi(numbers[0])
+	if err != nil {
+		return false
+	}
+	minor := 0
+	if len(numbers) > 1 {
+		minor, _ = strconv.Atoi(numbers[1])
+	}
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		return major > 10 || (major == 10 && minor >= 5)
+	}
+	return major >= 8
+}
+
 // generateAddIndex generates CREATE INDEX SQL
 func (g *SQLGenerator) generateAddIndex(op *AddIndexOp) (string, error) {
 	indexName := op.Name
@@ -159,27 +221,64 @@ func (g *SQLGenerator) generateAddForeignKey(op *AddForeignKeyOp) (string, error
 	constraintName := op.Name
 	if constraintName == "" {
 		// Generate constraint name if not provided
-		constraintName = fmt.Sprintf("fk_%s_%s_%s", op.Table, op.Column, op.ReferencedTable)
+		constraintName = foreignKeyName(op.Table, op.Column, op.ReferencedTable)
 	}
 
 	sql := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
 		op.Table, constraintName, op.Column, op.ReferencedTable, op.ReferencedColumn)
 
 	if op.OnDelete != "" {
-		sql += fmt.Sprintf(" ON DELETE %s", op.OnDelete)
+		action, err := referentialAction(op.OnDelete)
+		if err != nil {
+			return "", fmt.Errorf("add_foreign_key: on_delete %w", err)
+		}
+		sql += " ON DELETE " + action
 	}
 
 	if op.OnUpdate != "" {
-		sql += fmt.Sprintf(" ON UPDATE %s", op.OnUpdate)
+		action, err := referentialAction(op.OnUpdate)
+		if err != nil {
+			return "", fmt.Errorf("add_foreign_key: on_update %w", err)
+		}
+		sql += " ON UPDATE " + action
 	}
 
 	return sql, nil
 }
 
-// generateDropForeignKey generates ALTER TABLE DROP CONSTRAINT SQL
+// referentialActions are the ON DELETE and ON UPDATE actions a foreign key accepts
+var referentialActions = []string{"CASCADE", "RESTRICT", "SET NULL", "SET DEFAULT", "NO ACTION"}
+
+// referentialAction returns the SQL for an on_delete or on_update value, accepting
+// any case and underscores for spaces, as in set_null
+func referentialAction(value string) (string, error) {
+	action := strings.ToUpper(strings.Join(strings.Fields(strings.ReplaceAll(value, "_", " ")), " "))
+	if !slices.Contains(referentialActions, action) {
+		return "", fmt.Errorf("must be one of %s, got %q", strings.Join(referentialActions, ", "), value)
+	}
+	return action, nil
+}
+
+// generateDropForeignKey generates the driver's ALTER TABLE SQL for dropping a foreign key
 func (g *SQLGenerator) generateDropForeignKey(op *DropForeignKeyOp) (string, error) {
-	sql := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", op.Name, op.Name)
-	return sql, nil
+	constraintName := op.Name
+	if constraintName == "" {
+		constraintName = foreignKeyName(op.Table, op.Column, op.ReferencedTable)
+	}
+
+	switch g.driver {
+	case interfaces.DriverMySQL:
+		return fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", op.Table, constraintName), nil
+	case interfaces.DriverSQLite:
+		return "", fmt.Errorf("drop_foreign_key is not supported on SQLite; recreate the table instead")
+	default:
+		return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", op.Table, constraintName), nil
+	}
+}
+
+// foreignKeyName is the constraint name used when add_foreign_key is not given one
+func foreignKeyName(table, column, referencedTable string) string {
+	return fmt.Sprintf("fk_%s_%s_%s", table, column, referencedTable)
 }
 
 // generateColumnDefinition generates a column definition from MigrationColumn
@@ -187,19 +286,12 @@ func (g *SQLGenerator) generateColumnDefinition(col *MigrationColumn) (string, e
 	dataType := g.mapDataType(col.Type, col.Length)
 	def := fmt.Sprintf("%s %s", col.Name, dataType)
 
-	if !col.Nullable {
-		def += " NOT NULL"
-	}
-
-	if col.Default != nil {
-		if str, ok := col.Default.(string); ok && strings.ToUpper(str) == "NOW()" {
-			def += " DEFAULT NOW()"
-		} else {
-			def += fmt.Sprintf(" DEFAULT %v", col.Default)
-		}
+	constraints, err := g.columnConstraints(col.Type, col.Nullable, col.Default, col.Check)
+	if err != nil {
+		return "", err
 	}
 
-	return def, nil
+	return def + constraints, nil
 }
 
 // generateColumnDefinitionFromAddColumn generates a column definition from AddColumnOp
@@ -207,17 +299,11 @@ func (g *SQLGenerator) generateColumnDefinitionFromAddColumn(op *AddColumnOp) (s
 	dataType := g.mapDataType(op.Type, op.Length)
 	def := fmt.Sprintf("%s %s", op.Name, dataType)
 
-	if !op.Nullable {
-		def += " NOT NULL"
-	}
-
-	if op.Default != nil {
-		if str, ok := op.Default.(string); ok && strings.ToUpper(str) == "NOW()" {
-			def += " DEFAULT NOW()"
-		} else {
-			def += fmt.Sprintf(" DEFAULT %v", op.Default)
-		}
+	constraints, err := g.columnConstraints(op.Type, op.Nullable, op.Default, op.Check)
+	if err != nil {
+		return "", fmt.Errorf("column %s: %w", op.Name, err)
 	}
+	def += constraints
 
 	if op.Unique {
 		def += " UNIQUE"
@@ -226,6 +312,95 @@ func (g *SQLGenerator) generateColumnDefinitionFromAddColumn(op *AddColumnOp) (s
 	return def, nil
 }
 
+// columnConstraints generates the NOT NULL, DEFAULT and CHECK clauses of a column definition
+func (g *SQLGenerator) columnConstraints(dataType string, nullable bool, defaultValue any, check string) (string, error) {
+	var clauses string
+
+	if !nullable {
+		clauses += " NOT NULL"
+	}
+
+	if defaultValue != nil {
+		literal, err := g.defaultLiteral(dataType, defaultValue)
+		if err != nil {
+			return "", err
+		}
+		clauses += " DEFAULT " + literal
+	}
+
+	if check != "" {
+		clauses += fmt.Sprintf(" CHECK (%s)", check)
+	}
+
+	return clauses, nil
+}
+
+// sqlFunctionPattern matches defaults that call a function, like NOW() or gen_random_uuid()
+var sqlFunctionPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*\(\)$`)
+
+// defaultLiteral formats a column default as a SQL literal for the column type.
+// Strings are quoted unless they are a function call, a CURRENT_* keyword or
+// already quoted; booleans use the driver's boolean literal.
+func (g *SQLGenerator) defaultLiteral(dataType string, value any) (string, error) {
+	kind := strings.ToLower(dataType)
+
+	switch v := value.(type) {
+	case bool:
+		return g.booleanLiteral(v), nil
+	case int, int64, float64:
+		if kind == "boolean" || kind == "bool" {
+			return g.booleanLiteral(fmt.Sprint(v) != "0"), nil
+		}
+		return fmt.Sprint(v), nil
+	case string:
+		upper := strings.ToUpper(v)
+		switch {
+		case upper == "NOW()" || upper == "CURRENT_TIMESTAMP":
+			if g.driver == interfaces.DriverSQLite {
+				return "CURRENT_TIMESTAMP", nil
+			}
+			return upper, nil
+		case upper == "CURRENT_DATE" || upper == "CURRENT_TIME" || upper == "NULL":
+			return upper, nil
+		case sqlFunctionPattern.MatchString(v):
+			return v, nil
+		case len(v) >= 2 && strings.HasPrefix(v, "'") && strings.HasSuffix(v, "'"):
+			return v, nil
+		}
+
+		switch kind {
+		case "boolean", "bool":
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				return "", fmt.Errorf("invalid boolean default %q", v)
+			}
+			return g.booleanLiteral(parsed), nil
+		case "integer", "int", "bigint", "int64", "serial", "decimal", "numeric", "float", "double":
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return "", fmt.Errorf("invalid numeric default %q", v)
+			}
+			return v, nil
+		}
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'", nil
+	default:
+		return "", fmt.Errorf("unsupported default value %v (%T)", value, value)
+	}
+}
+
+// booleanLiteral returns the driver's boolean literal; MySQL and SQLite store booleans as integers
+func (g *SQLGenerator) booleanLiteral(value bool) string {
+	if g.driver == interfaces.DriverPostgreSQL {
+		if value {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
 // mapDataType maps migration data types to database-specific types
 func (g *SQLGenerator) mapDataType(dataType string, length *int) string {
 	switch g.driver {
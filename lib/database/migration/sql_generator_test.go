@@ -0,0 +1,467 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"fulcrum/lib/database/interfaces"
+)
+
+func TestGenerateRenameColumn(t *testing.T) {
+	tests := []struct {
+		name     string
+		driver   interfaces.DatabaseDriver
+		op       RenameColumnOp
+		expected string
+	}{
+		{
+			name:     "postgres",
+			driver:   interfaces.DriverPostgreSQL,
+			op:       RenameColumnOp{Table: "users", From: "name", To: "full_name"},
+			expected: "ALTER TABLE users RENAME COLUMN name TO full_name",
+		},
+		{
+			name:     "sqlite",
+			driver:   interfaces.DriverSQLite,
+			op:       RenameColumnOp{Table: "users", From: "name", To: "full_name"},
+			expected: "ALTER TABLE users RENAME COLUMN name TO full_name",
+		},
+		{
+			name:     "mysql",
+			driver:   interfaces.DriverMySQL,
+			op:       RenameColumnOp{Table: "users", From: "name", To: "full_name"},
+			expected: "ALTER TABLE users RENAME COLUMN name TO full_name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := tt.op
+			sql, err := NewSQLGenerator(tt.driver).GenerateSQL(&MigrationOperation{RenameColumn: &op})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if sql != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, sql)
+			}
+		})
+	}
+}
+
+func TestMySQLRenamesColumns(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"8.0.36", true},
+		{"8.4.0-commercial", true},
+		{"5.7.44-log", false},
+		{"10.6.12-MariaDB-1:10.6.12+maria~ubu2004", true},
+		{"10.4.32-MariaDB", false},
+		{"unknown", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := mysqlRenamesColumns(tt.version); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGenerateRenameTable(t *testing.T) {
+	tests := []struct {
+		driver  interfaces.DatabaseDriver
+		forward string
+		reverse string
+	}{
+		{
+			driver:  interfaces.DriverPostgreSQL,
+			forward: "ALTER TABLE people RENAME TO users",
+			reverse: "ALTER TABLE users RENAME TO people",
+		},
+		{
+			driver:  interfaces.DriverSQLite,
+			forward: "ALTER TABLE people RENAME TO users",
+			reverse: "ALTER TABLE users RENAME TO people",
+		},
+		{
+			driver:  interfaces.DriverMySQL,
+			forward: "RENAME TABLE people TO users",
+			reverse: "RENAME TABLE users TO people",
+		},
+	}
+
+	op := &RenameTableOp{From: "people", To: "users"}
+
+	for _, tt := range tests {
+		t.Run(string(tt.driver), func(t *testing.T) {
+			generator := NewSQLGenerator(tt.driver)
+
+			forward, err := generator.GenerateSQL(&MigrationOperation{RenameTable: op})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if forward != tt.forward {
+				t.Errorf("Expected forward %q, got %q", tt.forward, forward)
+			}
+
+			reverse, err := generator.GenerateSQL(&MigrationOperation{RenameTable: op.Reverse()})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if reverse != tt.reverse {
+				t.Errorf("Expected reverse %q, got %q", tt.reverse, reverse)
+			}
+		})
+	}
+}
+
+func TestGenerateDropForeignKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		driver   interfaces.DatabaseDriver
+		op       DropForeignKeyOp
+		expected string
+		errorMsg string
+	}{
+		{
+			name:     "postgres",
+			driver:   interfaces.DriverPostgreSQL,
+			op:       DropForeignKeyOp{Table: "orders", Name: "fk_orders_user"},
+			expected: "ALTER TABLE orders DROP CONSTRAINT IF EXISTS fk_orders_user",
+		},
+		{
+			name:     "postgres derived name",
+			driver:   interfaces.DriverPostgreSQL,
+			op:       DropForeignKeyOp{Table: "orders", Column: "user_id", ReferencedTable: "users"},
+			expected: "ALTER TABLE orders DROP CONSTRAINT IF EXISTS fk_orders_user_id_users",
+		},
+		{
+			name:     "mysql",
+			driver:   interfaces.DriverMySQL,
+			op:       DropForeignKeyOp{Table: "orders", Name: "fk_orders_user"},
+			expected: "ALTER TABLE orders DROP FOREIGN KEY fk_orders_user",
+		},
+		{
+			name:     "sqlite",
+			driver:   interfaces.DriverSQLite,
+			op:       DropForeignKeyOp{Table: "orders", Name: "fk_orders_user"},
+			errorMsg: "not supported on SQLite",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := tt.op
+			sql, err := NewSQLGenerator(tt.driver).GenerateSQL(&MigrationOperation{DropForeignKey: &op})
+
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if sql != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, sql)
+			}
+		})
+	}
+}
+
+func TestGenerateAddForeignKeyActions(t *testing.T) {
+	tests := []struct {
+		name     string
+		onDelete string
+		onUpdate string
+		expected string
+		errorMsg string
+	}{
+		{
+			name:     "no actions",
+			expected: "ALTER TABLE orders ADD CONSTRAINT fk_orders_user_id_users FOREIGN KEY (user_id) REFERENCES users (id)",
+		},
+		{
+			name:     "lowercase actions",
+			onDelete: "cascade",
+			onUpdate: "restrict",
+			expected: "ALTER TABLE orders ADD CONSTRAINT fk_orders_user_id_users FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE RESTRICT",
+		},
+		{
+			name:     "two word actions",
+			onDelete: "set_null",
+			onUpdate: "No Action",
+			expected: "ALTER TABLE orders ADD CONSTRAINT fk_orders_user_id_users FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL ON UPDATE NO ACTION",
+		},
+		{
+			name:     "unknown action",
+			onDelete: "destroy",
+			errorMsg: `on_delete must be one of CASCADE, RESTRICT, SET NULL, SET DEFAULT, NO ACTION, got "destroy"`,
+		},
+		{
+			name:     "injected action",
+			onUpdate: "CASCADE; DROP TABLE users",
+			errorMsg: "on_update must be one of",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, err := NewSQLGenerator(interfaces.DriverPostgreSQL).GenerateSQL(&MigrationOperation{AddForeignKey: &AddForeignKeyOp{
+				Table: "orders", Column: "user_id", ReferencedTable: "users", ReferencedColumn: "id",
+				OnDelete: tt.onDelete, OnUpdate: tt.onUpdate,
+			}})
+
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if sql != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, sql)
+			}
+		})
+	}
+}
+
+func TestDropForeignKeyMatchesAddForeignKeyName(t *testing.T) {
+	generator := NewSQLGenerator(interfaces.DriverPostgreSQL)
+
+	add, err := generator.GenerateSQL(&MigrationOperation{AddForeignKey: &AddForeignKeyOp{
+		Table: "orders", Column: "user_id", ReferencedTable: "users", ReferencedColumn: "id",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	drop, err := generator.GenerateSQL(&MigrationOperation{DropForeignKey: &DropForeignKeyOp{
+		Table: "orders", Column: "user_id", ReferencedTable: "users",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := "fk_orders_user_id_users"
+	if !strings.Contains(add, "ADD CONSTRAINT "+name+" ") || !strings.HasSuffix(drop, " "+name) {
+		t.Errorf("Expected both statements to use %s, got %q and %q", name, add, drop)
+	}
+}
+
+func TestGenerateColumnDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		driver   interfaces.DatabaseDriver
+		column   MigrationColumn
+		expected string
+		errorMsg string
+	}{
+		{
+			name:     "string is quoted",
+			driver:   interfaces.DriverPostgreSQL,
+			column:   MigrationColumn{Name: "status", Type: "varchar", Default: "active"},
+			expected: "status VARCHAR(255) NOT NULL DEFAULT 'active'",
+		},
+		{
+			name:     "quotes are escaped",
+			driver:   interfaces.DriverMySQL,
+			column:   MigrationColumn{Name: "note", Type: "text", Nullable: true, Default: "it's"},
+			expected: "note TEXT DEFAULT 'it''s'",
+		},
+		{
+			name:     "already quoted string is kept",
+			driver:   interfaces.DriverPostgreSQL,
+			column:   MigrationColumn{Name: "status", Type: "text", Default: "'active'"},
+			expected: "status TEXT NOT NULL DEFAULT 'active'",
+		},
+		{
+			name:     "integer",
+			driver:   interfaces.DriverPostgreSQL,
+			column:   MigrationColumn{Name: "count", Type: "integer", Default: 0},
+			expected: "count INTEGER NOT NULL DEFAULT 0",
+		},
+		{
+			name:     "numeric string",
+			driver:   interfaces.DriverMySQL,
+			column:   MigrationColumn{Name: "price", Type: "decimal", Default: "9.99"},
+			expected: "price DECIMAL NOT NULL DEFAULT 9.99",
+		},
+		{
+			name:     "invalid numeric string",
+			driver:   interfaces.DriverMySQL,
+			column:   MigrationColumn{Name: "price", Type: "decimal", Default: "free"},
+			errorMsg: "invalid numeric default",
+		},
+		{
+			name:     "postgres boolean",
+			driver:   interfaces.DriverPostgreSQL,
+			column:   MigrationColumn{Name: "active", Type: "boolean", Default: true},
+			expected: "active BOOLEAN NOT NULL DEFAULT TRUE",
+		},
+		{
+			name:     "mysql boolean",
+			driver:   interfaces.DriverMySQL,
+			column:   MigrationColumn{Name: "active", Type: "boolean", Default: false},
+			expected: "active BOOLEAN NOT NULL DEFAULT 0",
+		},
+		{
+			name:     "sqlite boolean from string",
+			driver:   interfaces.DriverSQLite,
+			column:   MigrationColumn{Name: "active", Type: "bool", Default: "true"},
+			expected: "active INTEGER NOT NULL DEFAULT 1",
+		},
+		{
+			name:     "postgres boolean from integer",
+			driver:   interfaces.DriverPostgreSQL,
+			column:   MigrationColumn{Name: "active", Type: "boolean", Default: 0},
+			expected: "active BOOLEAN NOT NULL DEFAULT FALSE",
+		},
+		{
+			name:     "now on postgres",
+			driver:   interfaces.DriverPostgreSQL,
+			column:   MigrationColumn{Name: "created_at", Type: "timestamp", Default: "NOW()"},
+			expected: "created_at TIMESTAMP NOT NULL DEFAULT NOW()",
+		},
+		{
+			name:     "now on sqlite",
+			driver:   interfaces.DriverSQLite,
+			column:   MigrationColumn{Name: "created_at", Type: "timestamp", Default: "now()"},
+			expected: "created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP",
+		},
+		{
+			name:     "function call",
+			driver:   interfaces.DriverPostgreSQL,
+			column:   MigrationColumn{Name: "id", Type: "uuid", Default: "gen_random_uuid()"},
+			expected: "id UUID NOT NULL DEFAULT gen_random_uuid()",
+		},
+		{
+			name:     "check constraint",
+			driver:   interfaces.DriverPostgreSQL,
+			column:   MigrationColumn{Name: "status", Type: "varchar", Default: "active", Check: "status IN ('active', 'disabled')"},
+			expected: "status VARCHAR(255) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'disabled'))",
+		},
+		{
+			name:     "check constraint on sqlite",
+			driver:   interfaces.DriverSQLite,
+			column:   MigrationColumn{Name: "age", Type: "integer", Nullable: true, Check: "age >= 0"},
+			expected: "age INTEGER CHECK (age >= 0)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := CreateTableOp{Name: "items", Columns: []MigrationColumn{tt.column}}
+			sql, err := NewSQLGenerator(tt.driver).GenerateSQL(&MigrationOperation{CreateTable: &op})
+
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if expected := "CREATE TABLE items (" + tt.expected + ")"; sql != expected {
+				t.Errorf("Expected %q, got %q", expected, sql)
+			}
+		})
+	}
+}
+
+func TestGenerateAddColumnDefaultAndCheck(t *testing.T) {
+	op := AddColumnOp{Table: "users", Name: "role", Type: "varchar", Default: "member", Check: "role <> ''"}
+	sql, err := NewSQLGenerator(interfaces.DriverMySQL).GenerateSQL(&MigrationOperation{AddColumn: &op})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "ALTER TABLE users ADD COLUMN role VARCHAR(255) NOT NULL DEFAULT 'member' CHECK (role <> '')"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+}
+
+func TestGenerateCreateTablePrimaryKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       CreateTableOp
+		expected string
+	}{
+		{
+			name: "single column",
+			op: CreateTableOp{Name: "users", Columns: []MigrationColumn{
+				{Name: "id", Type: "integer", PrimaryKey: true},
+				{Name: "email", Type: "string"},
+			}},
+			expected: "PRIMARY KEY (id)",
+		},
+		{
+			name: "composite column flags",
+			op: CreateTableOp{Name: "user_roles", Columns: []MigrationColumn{
+				{Name: "user_id", Type: "integer", PrimaryKey: true},
+				{Name: "role_id", Type: "integer", PrimaryKey: true},
+			}},
+			expected: "PRIMARY KEY (user_id, role_id)",
+		},
+		{
+			name: "table primary_key",
+			op: CreateTableOp{Name: "user_roles", PrimaryKey: []string{"role_id", "user_id"}, Columns: []MigrationColumn{
+				{Name: "user_id", Type: "integer"},
+				{Name: "role_id", Type: "integer"},
+			}},
+			expected: "PRIMARY KEY (role_id, user_id)",
+		},
+	}
+
+	generator := NewSQLGenerator(interfaces.DriverPostgreSQL)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := tt.op
+			sql, err := generator.GenerateSQL(&MigrationOperation{CreateTable: &op})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if count := strings.Count(sql, "PRIMARY KEY"); count != 1 {
+				t.Errorf("Expected exactly one PRIMARY KEY clause, got %d in %q", count, sql)
+			}
+			if !strings.Contains(sql, tt.expected) {
+				t.Errorf("Expected %q in %q", tt.expected, sql)
+			}
+		})
+	}
+}
+
+func TestGenerateCreateTableSQLiteSerial(t *testing.T) {
+	op := CreateTableOp{Name: "users", Columns: []MigrationColumn{
+		{Name: "id", Type: "serial", PrimaryKey: true},
+		{Name: "email", Type: "varchar"},
+	}}
+
+	sql, err := NewSQLGenerator(interfaces.DriverSQLite).GenerateSQL(&MigrationOperation{CreateTable: &op})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, email TEXT NOT NULL)"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+
+	// A serial column in a composite key keeps the table constraint
+	op.Columns = append(op.Columns, MigrationColumn{Name: "tenant_id", Type: "integer", PrimaryKey: true})
+	sql, err = NewSQLGenerator(interfaces.DriverSQLite).GenerateSQL(&MigrationOperation{CreateTable: &op})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sql, "id INTEGER NOT NULL") || !strings.Contains(sql, "PRIMARY KEY (id, tenant_id)") {
+		t.Errorf("Expected a composite PRIMARY KEY constraint, got %q", sql)
+	}
+}
@@ -28,7 +28,8 @@ func (t *Tracker) InitializeSchema(ctx context.Context) error {
 	}
 
 	if exists {
-		return nil // Table already exists
+		// Tables created before checksums were tracked need the column added
+		return t.ensureChecksumColumn(ctx)
 	}
 
 	// Create schema_migrations table
@@ -55,6 +56,10 @@ func (t *Tracker) InitializeSchema(ctx context.Context) error {
 				NotNull:      true,
 				DefaultValue: func() *string { s := "NOW()"; return &s }(),
 			},
+			{
+				Name: "checksum",
+				Type: "varchar(64)",
+			},
 		},
 		PrimaryKey: []string{"version", "domain"},
 	}
@@ -67,10 +72,24 @@ func (t *Tracker) InitializeSchema(ctx context.Context) error {
 	return nil
 }
 
+// ensureChecksumColumn adds the checksum column to an existing schema_migrations table
+func (t *Tracker) ensureChecksumColumn(ctx context.Context) error {
+	rows, err := t.db.Query(ctx, "SELECT checksum FROM schema_migrations WHERE 1 = 0")
+	if err == nil {
+		rows.Close()
+		return nil
+	}
+
+	if _, err := t.db.Exec(ctx, "ALTER TABLE schema_migrations ADD COLUMN checksum varchar(64)"); err != nil {
+		return fmt.Errorf("failed to add checksum column to schema_migrations: %w", err)
+	}
+	return nil
+}
+
 // GetAppliedMigrations returns all applied migrations
 func (t *Tracker) GetAppliedMigrations(ctx context.Context) ([]MigrationRecord, error) {
 	query := `
-		SELECT version, domain, name, applied_at 
+		SELECT version, domain, name, applied_at, COALESCE(checksum, '')
 		FROM schema_migrations 
 		ORDER BY domain, version`
 	
@@ -83,7 +102,7 @@ func (t *Tracker) GetAppliedMigrations(ctx context.Context) ([]MigrationRecord,
 	var migrations []MigrationRecord
 	for rows.Next() {
 		var record MigrationRecord
-		err := rows.Scan(&record.Version, &record.Domain, &record.Name, &record.AppliedAt)
+		err := rows.Scan(&record.Version, &record.Domain, &record.Name, &record.AppliedAt, &record.Checksum)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan migration record: %w", err)
 		}
@@ -100,7 +119,7 @@ func (t *Tracker) GetAppliedMigrations(ctx context.Context) ([]MigrationRecord,
 // GetAppliedMigrationsForDomain returns applied migrations for a specific domain
 func (t *Tracker) GetAppliedMigrationsForDomain(ctx context.Context, domain string) ([]MigrationRecord, error) {
 	query := `
-		SELECT version, domain, name, applied_at 
+		SELECT version, domain, name, applied_at, COALESCE(checksum, '')
 		FROM schema_migrations 
 		WHERE domain = $1 
 		ORDER BY version`
@@ -114,7 +133,7 @@ func (t *Tracker) GetAppliedMigrationsForDomain(ctx context.Context, domain stri
 	var migrations []MigrationRecord
 	for rows.Next() {
 		var record MigrationRecord
-		err := rows.Scan(&record.Version, &record.Domain, &record.Name, &record.AppliedAt)
+		err := rows.Scan(&record.Version, &record.Domain, &record.Name, &record.AppliedAt, &record.Checksum)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan migration record: %w", err)
 		}
@@ -147,10 +166,10 @@ func (t *Tracker) IsMigrationApplied(ctx context.Context, domain string, version
 // RecordMigration records that a migration has been applied
 func (t *Tracker) RecordMigration(ctx context.Context, migration Migration) error {
 	query := `
-		INSERT INTO schema_migrations (version, domain, name, applied_at)
-		VALUES ($1, $2, $3, $4)`
+		INSERT INTO schema_migrations (version, domain, name, applied_at, checksum)
+		VALUES ($1, $2, $3, $4, $5)`
 	
-	_, err := t.db.Exec(ctx, query, migration.Version, migration.Domain, migration.Name, time.Now())
+	_, err := t.db.Exec(ctx, query, migration.Version, migration.Domain, migration.Name, time.Now(), migration.Checksum)
 	if err != nil {
 		return fmt.Errorf("failed to record migration: %w", err)
 	}
@@ -197,6 +216,54 @@ func (t *Tracker) GetLatestVersion(ctx context.Context, domain string) (int, err
 	return version, nil
 }
 
+// FindChecksumMismatches reports applied migrations whose file has changed since they ran.
+// Records without a checksum predate checksum tracking and are skipped.
+func FindChecksumMismatches(applied []MigrationRecord, allMigrations []Migration) []string {
+	current := make(map[string]Migration)
+	for _, migration := range allMigrations {
+		current[fmt.Sprintf("%s:%d", migration.Domain, migration.Version)] = migration
+	}
+
+	var mismatches []string
+	for _, record := range applied {
+		if record.Checksum == "" {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%d", record.Domain, record.Version)
+		migration, exists := current[key]
+		if !exists || migration.Checksum == "" {
+			continue
+		}
+
+		if migration.Checksum != record.Checksum {
+			mismatches = append(mismatches, fmt.Sprintf("%s (%s) was modified after it was applied: %s",
+				key, record.Name, migration.FilePath))
+		}
+	}
+
+	return mismatches
+}
+
+// FindOrphanedRecords reports applied migrations whose migration file no longer exists
+func FindOrphanedRecords(applied []MigrationRecord, allMigrations []Migration) []string {
+	current := make(map[string]bool)
+	for _, migration := range allMigrations {
+		current[fmt.Sprintf("%s:%d", migration.Domain, migration.Version)] = true
+	}
+
+	var orphaned []string
+	for _, record := range applied {
+		key := fmt.Sprintf("%s:%d", record.Domain, record.Version)
+		if !current[key] {
+			orphaned = append(orphaned, fmt.Sprintf("%s (%s) is recorded in schema_migrations but has no migration file",
+				key, record.Name))
+		}
+	}
+
+	return orphaned
+}
+
 // GetPendingMigrations returns migrations that haven't been applied yet
 func (t *Tracker) GetPendingMigrations(ctx context.Context, allMigrations []Migration) ([]Migration, error) {
 	appliedMigrations, err := t.GetAppliedMigrations(ctx)
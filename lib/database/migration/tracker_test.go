@@ -1,24 +1,9 @@
 package migration
 
 import (
-	"fmt"
 	"testing"
 )
 
-// ValidateMigration validates a migration structure
-func ValidateMigration(migration Migration) error {
-	if migration.Domain == "" {
-		return fmt.Errorf("migration domain cannot be empty")
-	}
-	if migration.Name == "" {
-		return fmt.Errorf("migration name cannot be empty")
-	}
-	if migration.Version <= 0 {
-		return fmt.Errorf("migration version must be positive")
-	}
-	return nil
-}
-
 func testParseExample(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -4,22 +4,26 @@ import "time"
 
 // Migration represents a single migration file
 type Migration struct {
-	Version     int                    `yaml:"version"`
-	Name        string                 `yaml:"name"`
-	Description string                 `yaml:"description"`
-	Up          []MigrationOperation   `yaml:"up"`
-	Down        []MigrationOperation   `yaml:"down"`
-	Domain      string                 // Set during parsing
-	FilePath    string                 // Set during parsing
+	Version      int                  `yaml:"version"`
+	Name         string               `yaml:"name"`
+	Description  string               `yaml:"description"`
+	Up           []MigrationOperation `yaml:"up"`
+	Down         []MigrationOperation `yaml:"down"`
+	Irreversible bool                 `yaml:"irreversible"` // No down operations; refuse to roll back
+	Domain       string               // Set during parsing
+	FilePath     string               // Set during parsing
+	Checksum     string               // sha256 of the file, set during parsing
 }
 
 // MigrationOperation represents a single operation in a migration
 type MigrationOperation struct {
 	CreateTable   *CreateTableOp   `yaml:"create_table,omitempty"`
 	DropTable     *DropTableOp     `yaml:"drop_table,omitempty"`
+	RenameTable   *RenameTableOp   `yaml:"rename_table,omitempty"`
 	AddColumn     *AddColumnOp     `yaml:"add_column,omitempty"`
 	DropColumn    *DropColumnOp    `yaml:"drop_column,omitempty"`
 	ChangeColumn  *ChangeColumnOp  `yaml:"change_column,omitempty"`
+	RenameColumn  *RenameColumnOp  `yaml:"rename_column,omitempty"`
 	AddIndex      *AddIndexOp      `yaml:"add_index,omitempty"`
 	DropIndex     *DropIndexOp     `yaml:"drop_index,omitempty"`
 	AddForeignKey *AddForeignKeyOp `yaml:"add_foreign_key,omitempty"`
@@ -29,8 +33,9 @@ type MigrationOperation struct {
 
 // CreateTableOp creates a new table
 type CreateTableOp struct {
-	Name    string             `yaml:"name"`
-	Columns []MigrationColumn  `yaml:"columns"`
+	Name       string             `yaml:"name"`
+	Columns    []MigrationColumn  `yaml:"columns"`
+	PrimaryKey []string           `yaml:"primary_key,omitempty"` // Table-level key, e.g. [user_id, role_id]
 }
 
 // DropTableOp drops an existing table
@@ -38,6 +43,17 @@ type DropTableOp struct {
 	Name string `yaml:"name"`
 }
 
+// RenameTableOp renames a table
+type RenameTableOp struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// Reverse returns the rename that undoes this one, for use in down operations
+func (op *RenameTableOp) Reverse() *RenameTableOp {
+	return &RenameTableOp{From: op.To, To: op.From}
+}
+
 // AddColumnOp adds a column to an existing table
 type AddColumnOp struct {
 	Table  string          `yaml:"table"`
@@ -47,6 +63,7 @@ type AddColumnOp struct {
 	Nullable bool          `yaml:"nullable,omitempty"`
 	Default interface{}    `yaml:"default,omitempty"`
 	Unique  bool           `yaml:"unique,omitempty"`
+	Check   string         `yaml:"check,omitempty"` // SQL expression for a CHECK constraint
 }
 
 // DropColumnOp drops a column from a table
@@ -65,6 +82,14 @@ type ChangeColumnOp struct {
 	Default  interface{}  `yaml:"default,omitempty"`
 }
 
+// RenameColumnOp renames a column, keeping its type, nullability and default. MySQL
+// needs 8.0 or later.
+type RenameColumnOp struct {
+	Table string `yaml:"table"`
+	From  string `yaml:"from"`
+	To    string `yaml:"to"`
+}
+
 // AddIndexOp adds an index to a table
 type AddIndexOp struct {
 	Table   string   `yaml:"table"`
@@ -89,9 +114,13 @@ type AddForeignKeyOp struct {
 	OnUpdate        string `yaml:"on_update,omitempty"`
 }
 
-// DropForeignKeyOp drops a foreign key constraint
+// DropForeignKeyOp drops a foreign key constraint. Without a name, the constraint
+// name is derived from column and referenced_table the same way add_foreign_key does.
 type DropForeignKeyOp struct {
-	Name string `yaml:"name"`
+	Table           string `yaml:"table"`
+	Name            string `yaml:"name,omitempty"`
+	Column          string `yaml:"column,omitempty"`
+	ReferencedTable string `yaml:"referenced_table,omitempty"`
 }
 
 // ExecuteOp executes raw SQL
@@ -108,6 +137,7 @@ type MigrationColumn struct {
 	Default    interface{} `yaml:"default,omitempty"`
 	PrimaryKey bool        `yaml:"primary_key,omitempty"`
 	Unique     bool        `yaml:"unique,omitempty"`
+	Check      string      `yaml:"check,omitempty"` // SQL expression for a CHECK constraint
 }
 
 // MigrationRecord represents a migration that has been applied
@@ -116,6 +146,16 @@ type MigrationRecord struct {
 	Domain    string    `json:"domain"`
 	Name      string    `json:"name"`
 	AppliedAt time.Time `json:"applied_at"`
+	Checksum  string    `json:"checksum,omitempty"` // Empty for migrations applied before checksums were tracked
+}
+
+// MigrationDiff holds the SQL a migration would execute, for auditing before it runs
+type MigrationDiff struct {
+	Domain    string   `json:"domain"`
+	Version   int      `json:"version"`
+	Name      string   `json:"name"`
+	Direction string   `json:"direction"`
+	SQL       []string `json:"sql"`
 }
 
 // MigrationStatus represents the status of migrations
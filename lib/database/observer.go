@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"fulcrum/lib/metrics"
+	"fulcrum/lib/tracing"
+	"log"
+	"time"
+)
+
+// QueryEvent describes a statement the executor ran
+type QueryEvent struct {
+	SQL      string // The statement with placeholders; arguments are not included
+	Duration time.Duration
+	Err      error
+}
+
+// QueryObserver is called after every statement the executor runs, e.g. to feed metrics
+type QueryObserver func(ctx context.Context, event QueryEvent)
+
+// SetQueryObserver calls observer after every statement, including those in transactions
+func (de *DatabaseExecutor) SetQueryObserver(observer QueryObserver) {
+	de.observer = observer
+}
+
+// SetSlowQueryThreshold logs statements that take longer than threshold; 0 disables it
+func (de *DatabaseExecutor) SetSlowQueryThreshold(threshold time.Duration) {
+	de.slowQuery = threshold
+}
+
+// observe reports a statement that started at start and failed with *err, if set.
+// Deferred by the methods that run statements.
+func (de *DatabaseExecutor) observe(ctx context.Context, query string, start time.Time, err *error) {
+	elapsed := time.Since(start)
+
+	// Label timings by the route that issued the statement
+	route := metrics.RouteFromContext(ctx)
+	metrics.SQLDuration.Observe(elapsed.Seconds(), route)
+	if *err != nil {
+		metrics.SQLErrors.Inc(route)
+	}
+
+	if de.slowQuery > 0 && elapsed > de.slowQuery {
+		log.Printf("⚠️ Slow query took %v: %s", elapsed.Round(time.Millisecond), tracing.Truncate(query, tracing.MaxQueryLength))
+	}
+	if de.observer != nil {
+		de.observer(ctx, QueryEvent{SQL: query, Duration: elapsed, Err: *err})
+	}
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"fulcrum/lib/metrics"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryObserver(t *testing.T) {
+	executor, _ := newSQLiteExecutor(t)
+	ctx := context.Background()
+
+	var events []QueryEvent
+	executor.SetQueryObserver(func(ctx context.Context, event QueryEvent) {
+		events = append(events, event)
+	})
+
+	if err := responseError(executor.ExecuteSQL(ctx, "INSERT INTO items (name) VALUES (:name)", map[string]any{"name": "a"}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := executor.WithTransaction(ctx, func(tx *DatabaseExecutor) error {
+		return responseError(tx.FindRecords(ctx, "items", nil, nil))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	executor.ExecuteSQL(ctx, "SELECT * FROM missing", nil, nil)
+
+	expected := []string{"INSERT INTO items (name) VALUES (?)", "SELECT * FROM items", "SELECT * FROM missing"}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %+v", len(expected), events)
+	}
+	for i, event := range events {
+		if event.SQL != expected[i] {
+			t.Errorf("Expected event %d for %q, got %q", i, expected[i], event.SQL)
+		}
+		if event.Duration <= 0 {
+			t.Errorf("Expected a duration for %q, got %v", event.SQL, event.Duration)
+		}
+		if failed := event.Err != nil; failed != (i == 2) {
+			t.Errorf("Expected an error only for the missing table, got %v for %q", event.Err, event.SQL)
+		}
+	}
+}
+
+func TestSlowQueryLogging(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		wantLog   bool
+	}{
+		{name: "slower than threshold", threshold: time.Nanosecond, wantLog: true},
+		{name: "faster than threshold", threshold: time.Hour},
+		{name: "disabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			previous := log.Writer()
+			log.SetOutput(&output)
+			t.Cleanup(func() { log.SetOutput(previous) })
+
+			executor, _ := newSQLiteExecutor(t)
+			executor.SetSlowQueryThreshold(tt.threshold)
+			if err := responseError(executor.ExecuteSQL(context.Background(), "SELECT name FROM items WHERE name = :name", map[string]any{"name": "secret"}, nil)); err != nil {
+				t.Fatal(err)
+			}
+
+			logged := output.String()
+			if strings.Contains(logged, "Slow query") != tt.wantLog {
+				t.Errorf("Expected slow query log %v, got %q", tt.wantLog, logged)
+			}
+			if tt.wantLog && !strings.Contains(logged, "SELECT name FROM items WHERE name = ?") {
+				t.Errorf("Expected the SQL in the log, got %q", logged)
+			}
+			if strings.Contains(logged, "secret") {
+				t.Errorf("Expected arguments to stay out of the log, got %q", logged)
+			}
+		})
+	}
+}
+
+func TestStatementsRecordMetrics(t *testing.T) {
+	executor, _ := newSQLiteExecutor(t)
+	ctx := metrics.WithRoute(context.Background(), "/observer/metrics")
+
+	if err := responseError(executor.CreateRecord(ctx, "items", map[string]any{"name": "a"}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	executor.FindRecords(ctx, "missing", nil, nil)
+
+	if count := metrics.SQLDuration.Count("/observer/metrics"); count != 2 {
+		t.Errorf("Expected 2 timed statements, got %d", count)
+	}
+	if failures := metrics.SQLErrors.Value("/observer/metrics"); failures != 1 {
+		t.Errorf("Expected 1 failed statement, got %v", failures)
+	}
+}
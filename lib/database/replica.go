@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"fulcrum/lib/database/interfaces"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// replicaRetryAfter is how long a replica whose connection failed is left out of the pool
+const replicaRetryAfter = 30 * time.Second
+
+// SetReplica sends the reads ExecuteSQL runs (SELECT, WITH and SHOW statements without
+// RETURNING) to replica, and everything else to the primary. Reads in a transaction,
+// under ForcePrimary or after a write under WithReadYourWrites stay on the primary.
+// Replica reads skip the statement cache, which prepares statements on the primary.
+func (de *DatabaseExecutor) SetReplica(replica interfaces.Querier) {
+	de.replica = replica
+}
+
+// read runs a read-only query, on the replica unless the executor has none or the
+// read must see the primary
+func (de *DatabaseExecutor) read(ctx context.Context, query string, args ...any) (_ interfaces.Rows, err error) {
+	if de.replica == nil || de.tx != nil || readsPrimary(ctx) {
+		return de.query(ctx, query, args...)
+	}
+	defer de.observe(ctx, query, time.Now(), &err)
+	return de.replica.Query(ctx, query, args...)
+}
+
+// ReplicaPool spreads reads across replicas in turn. A replica whose connection fails
+// is left out for a while, and reads fall back to the primary while none is available.
+// Only Query reads from replicas: QueryRow and Exec run on the primary.
+type ReplicaPool struct {
+	primary  interfaces.Querier
+	replicas []*poolReplica
+	next     atomic.Uint64
+	now      func() time.Time
+}
+
+// poolReplica is a replica and when it may be tried again after failing
+type poolReplica struct {
+	db        interfaces.Database
+	number    int // 1-based, for logs; connection strings may hold passwords
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+// NewReplicaPool reads from replicas, falling back to primary
+func NewReplicaPool(primary interfaces.Querier, replicas ...interfaces.Database) *ReplicaPool {
+	pool := &ReplicaPool{primary: primary, now: time.Now}
+	for i, replica := range replicas {
+		pool.replicas = append(pool.replicas, &poolReplica{db: replica, number: i + 1})
+	}
+	return pool
+}
+
+// Query implements interfaces.Querier, trying each available replica once before the primary
+func (p *ReplicaPool) Query(ctx context.Context, query string, args ...any) (interfaces.Rows, error) {
+	for range p.replicas {
+		replica := p.pick()
+		if replica == nil {
+			break
+		}
+		rows, err := replica.db.Query(ctx, query, args...)
+		if err == nil || ctx.Err() != nil || !connectionFailed(replica.db, err) {
+			return rows, err
+		}
+		replica.markDown(p.now().Add(replicaRetryAfter))
+		log.Printf("⚠️ Replica %d failed, reading from the primary instead for %v: %v", replica.number, replicaRetryAfter, err)
+	}
+	return p.primary.Query(ctx, query, args...)
+}
+
+// QueryRow implements interfaces.Querier on the primary
+func (p *ReplicaPool) QueryRow(ctx context.Context, query string, args ...any) interfaces.Row {
+	return p.primary.QueryRow(ctx, query, args...)
+}
+
+// Exec implements interfaces.Querier on the primary
+func (p *ReplicaPool) Exec(ctx context.Context, query string, args ...any) (interfaces.Result, error) {
+	return p.primary.Exec(ctx, query, args...)
+}
+
+// pick returns the next available replica in turn, or nil when all are left out
+func (p *ReplicaPool) pick() *poolReplica {
+	now := p.now()
+	for range p.replicas {
+		replica := p.replicas[(p.next.Add(1)-1)%uint64(len(p.replicas))]
+		if replica.available(now) {
+			return replica
+		}
+	}
+	return nil
+}
+
+func (r *poolReplica) available(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !now.Before(r.downUntil)
+}
+
+func (r *poolReplica) markDown(until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.downUntil = until
+}
+
+// connectionFailed reports whether db classifies err as a lost or refused connection
+func connectionFailed(db interfaces.Database, err error) bool {
+	classifier, ok := db.(interfaces.ErrorClassifier)
+	return ok && classifier.ClassifyError(err) == interfaces.ErrorConnection
+}
@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fulcrum/lib/database/interfaces"
+	"testing"
+	"time"
+)
+
+func TestReplicaRouting(t *testing.T) {
+	tests := []struct {
+		name        string
+		sql         string
+		ctx         func() context.Context
+		wantReplica bool
+	}{
+		{name: "select reads the replica", sql: "SELECT * FROM items", wantReplica: true},
+		{name: "with reads the replica", sql: "  with recent AS (SELECT 1) SELECT * FROM recent", wantReplica: true},
+		{name: "show reads the replica", sql: "SHOW search_path", wantReplica: true},
+		{name: "insert writes the primary", sql: "INSERT INTO items (name) VALUES ('a')"},
+		{name: "update writes the primary", sql: "UPDATE items SET name = 'b'"},
+		{name: "returning writes the primary", sql: "INSERT INTO items (name) VALUES ('a') RETURNING id"},
+		{
+			name: "forced reads use the primary",
+			sql:  "SELECT * FROM items",
+			ctx:  func() context.Context { return ForcePrimary(context.Background()) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &mockDatabase{driver: interfaces.DriverPostgreSQL}
+			replica := &mockDatabase{driver: interfaces.DriverPostgreSQL}
+			executor := NewDatabaseExecutor(primary)
+			executor.SetReplica(replica)
+
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx()
+			}
+			if err := responseError(executor.ExecuteSQL(ctx, tt.sql, nil, nil)); err != nil {
+				t.Fatal(err)
+			}
+
+			target, other, where := primary, replica, "primary"
+			if tt.wantReplica {
+				target, other, where = replica, primary, "replica"
+			}
+			if len(target.queries) != 1 || len(other.queries) != 0 {
+				t.Errorf("Expected %q on the %s only, got primary %v and replica %v", tt.sql, where, primary.queries, replica.queries)
+			}
+		})
+	}
+}
+
+func TestReplicaSkippedInTransactions(t *testing.T) {
+	executor, db := newSQLiteExecutor(t)
+	replica := &mockDatabase{driver: interfaces.DriverSQLite}
+	executor.SetReplica(replica)
+
+	err := executor.WithTransaction(context.Background(), func(tx *DatabaseExecutor) error {
+		if err := responseError(tx.ExecuteSQL(context.Background(), "INSERT INTO items (name) VALUES ('a')", nil, nil)); err != nil {
+			return err
+		}
+		return responseError(tx.ExecuteSQL(context.Background(), "SELECT * FROM items", nil, nil))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replica.queries) != 0 {
+		t.Errorf("Expected reads in a transaction to stay on the primary, got replica queries %v", replica.queries)
+	}
+	if count := countItems(t, db); count != 1 {
+		t.Errorf("Expected the insert to reach the primary, got %d rows", count)
+	}
+}
+
+// downReplica is a replica whose connection is gone
+type downReplica struct {
+	mockDatabase
+}
+
+var errConnectionLost = errors.New("connection refused")
+
+func (d *downReplica) Query(ctx context.Context, query string, args ...any) (interfaces.Rows, error) {
+	d.queries = append(d.queries, query)
+	return nil, errConnectionLost
+}
+
+func (d *downReplica) ClassifyError(err error) interfaces.ErrorClass {
+	if errors.Is(err, errConnectionLost) {
+		return interfaces.ErrorConnection
+	}
+	return interfaces.ErrorPermanent
+}
+
+func TestReplicaPool(t *testing.T) {
+	t.Run("reads take turns", func(t *testing.T) {
+		primary := &mockDatabase{}
+		a, b := &mockDatabase{}, &mockDatabase{}
+		pool := NewReplicaPool(primary, a, b)
+		for range 4 {
+			if _, err := pool.Query(context.Background(), "SELECT 1"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(a.queries) != 2 || len(b.queries) != 2 || len(primary.queries) != 0 {
+			t.Errorf("Expected 2 reads per replica, got %d, %d and %d on the primary", len(a.queries), len(b.queries), len(primary.queries))
+		}
+	})
+
+	t.Run("failed replicas are left out until they may have recovered", func(t *testing.T) {
+		primary := &mockDatabase{}
+		down, up := &downReplica{}, &mockDatabase{}
+		pool := NewReplicaPool(primary, down, up)
+		now := time.Now()
+		pool.now = func() time.Time { return now }
+
+		for range 3 {
+			if _, err := pool.Query(context.Background(), "SELECT 1"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(down.queries) != 1 || len(up.queries) != 3 {
+			t.Errorf("Expected the failed replica to be tried once, got %d reads on it and %d on the other", len(down.queries), len(up.queries))
+		}
+
+		now = now.Add(replicaRetryAfter)
+		pool.Query(context.Background(), "SELECT 1")
+		pool.Query(context.Background(), "SELECT 1")
+		if len(down.queries) != 2 {
+			t.Errorf("Expected the replica to be tried again after %v, got %d reads", replicaRetryAfter, len(down.queries))
+		}
+	})
+
+	t.Run("falls back to the primary", func(t *testing.T) {
+		primary := &mockDatabase{}
+		pool := NewReplicaPool(primary, &downReplica{})
+		if _, err := pool.Query(context.Background(), "SELECT 1"); err != nil {
+			t.Fatalf("Expected the primary to answer, got %v", err)
+		}
+		if len(primary.queries) != 1 {
+			t.Errorf("Expected the read on the primary, got %v", primary.queries)
+		}
+	})
+}
+
+func TestReadYourWrites(t *testing.T) {
+	primary, replica := &mockDatabase{}, &mockDatabase{}
+	executor := NewDatabaseExecutor(primary)
+	executor.SetReplica(NewReplicaPool(primary, replica))
+
+	request := WithReadYourWrites(context.Background())
+	run := func(ctx context.Context, sql string) {
+		t.Helper()
+		if err := responseError(executor.ExecuteSQL(ctx, sql, nil, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run(request, "SELECT * FROM items")
+	if len(replica.queries) != 1 {
+		t.Fatalf("Expected reads before a write on the replica, got %v", replica.queries)
+	}
+
+	run(request, "INSERT INTO items (name) VALUES ('a')")
+	run(request, "SELECT * FROM items")
+	if len(replica.queries) != 1 || primary.lastQuery() != "SELECT * FROM items" {
+		t.Errorf("Expected reads after a write on the primary, got primary %v and replica %v", primary.queries, replica.queries)
+	}
+
+	run(WithReadYourWrites(context.Background()), "SELECT * FROM items")
+	if len(replica.queries) != 2 {
+		t.Errorf("Expected another request to read from the replica, got %v", replica.queries)
+	}
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"fulcrum/lib/database/interfaces"
+	"log"
+	"math/rand/v2"
+	"time"
+)
+
+// maxRetryBackoff caps the wait between retries however many attempts are configured
+const maxRetryBackoff = 2 * time.Second
+
+// RetryPolicy controls how ExecuteSQL retries statements that failed transiently
+type RetryPolicy struct {
+	MaxRetries int           // Retries after the first attempt; 0 disables retrying
+	Backoff    time.Duration // Wait before the first retry, doubled for each one after
+}
+
+// retryMode says which transient failures a statement can be retried after
+type retryMode int
+
+const (
+	retryNever     retryMode = iota
+	retryAborted             // Writes: only when the database rolled the statement back
+	retryTransient           // Reads: after any transient failure
+)
+
+// SetRetryPolicy retries statements that fail with an error the driver classifies as
+// transient. Reads are retried after deadlocks and lost connections; writes only when
+// the database rolled the statement back, since a write on a lost connection may have
+// applied. Statements inside a transaction are never retried on their own.
+func (de *DatabaseExecutor) SetRetryPolicy(policy RetryPolicy) {
+	de.retry = policy
+}
+
+// retryable reports whether a statement that failed with err can run again
+func (de *DatabaseExecutor) retryable(err error, mode retryMode) bool {
+	classifier, ok := de.db.(interfaces.ErrorClassifier)
+	if !ok || de.tx != nil || mode == retryNever {
+		return false
+	}
+
+	switch classifier.ClassifyError(err) {
+	case interfaces.ErrorAborted:
+		return true
+	case interfaces.ErrorConnection:
+		return mode == retryTransient
+	}
+	return false
+}
+
+// withRetry runs fn, running it again under the retry policy while it fails in a way
+// mode allows retrying
+func (de *DatabaseExecutor) withRetry(ctx context.Context, mode retryMode, fn func() error) error {
+	backoff := de.retry.Backoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= de.retry.MaxRetries || !de.retryable(err, mode) {
+			return err
+		}
+
+		// Jitter keeps requests that deadlocked on each other from retrying in step
+		wait := backoff/2 + rand.N(backoff/2+1)
+		log.Printf("🔁 Retrying query in %v (retry %d of %d): %v", wait, attempt+1, de.retry.MaxRetries, err)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
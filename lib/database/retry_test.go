@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fulcrum/lib/database/interfaces"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// flakyDatabase fails the first failures statements with err before running them
+type flakyDatabase struct {
+	interfaces.Database
+	err      error
+	failures int
+	calls    int
+}
+
+func (f *flakyDatabase) fail() error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func (f *flakyDatabase) Query(ctx context.Context, query string, args ...any) (interfaces.Rows, error) {
+	if err := f.fail(); err != nil {
+		return nil, err
+	}
+	return f.Database.Query(ctx, query, args...)
+}
+
+func (f *flakyDatabase) Exec(ctx context.Context, query string, args ...any) (interfaces.Result, error) {
+	if err := f.fail(); err != nil {
+		return nil, err
+	}
+	return f.Database.Exec(ctx, query, args...)
+}
+
+func (f *flakyDatabase) ClassifyError(err error) interfaces.ErrorClass {
+	return f.Database.(interfaces.ErrorClassifier).ClassifyError(err)
+}
+
+func TestExecuteSQLRetries(t *testing.T) {
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+	constraint := sqlite3.Error{Code: sqlite3.ErrConstraint}
+
+	tests := []struct {
+		name        string
+		sql         string
+		err         error
+		failures    int
+		maxRetries  int
+		inTx        bool
+		wantSuccess bool
+		wantCalls   int
+	}{
+		{name: "select after busy", sql: "SELECT * FROM items", err: busy, failures: 2, maxRetries: 3, wantSuccess: true, wantCalls: 3},
+		{name: "select after lost connection", sql: "SELECT * FROM items", err: driver.ErrBadConn, failures: 1, maxRetries: 3, wantSuccess: true, wantCalls: 2},
+		{name: "select gives up after max retries", sql: "SELECT * FROM items", err: busy, failures: 5, maxRetries: 2, wantCalls: 3},
+		{name: "insert after busy", sql: "INSERT INTO items (name) VALUES ('a')", err: busy, failures: 1, maxRetries: 3, wantSuccess: true, wantCalls: 2},
+		{name: "insert not retried after lost connection", sql: "INSERT INTO items (name) VALUES ('a')", err: driver.ErrBadConn, failures: 1, maxRetries: 3, wantCalls: 1},
+		{name: "insert returning not retried after lost connection", sql: "INSERT INTO items (name) VALUES ('a') RETURNING id", err: driver.ErrBadConn, failures: 1, maxRetries: 3, wantCalls: 1},
+		{name: "multiple statements not retried", sql: "INSERT INTO items (name) VALUES ('a'); INSERT INTO items (name) VALUES ('b');", err: busy, failures: 1, maxRetries: 3, wantCalls: 1},
+		{name: "permanent error not retried", sql: "SELECT * FROM items", err: constraint, failures: 1, maxRetries: 3, wantCalls: 1},
+		{name: "disabled by default", sql: "SELECT * FROM items", err: busy, failures: 1, wantCalls: 1},
+		{name: "not retried inside a transaction", sql: "SELECT * FROM items", err: busy, failures: 1, maxRetries: 3, inTx: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, db := newSQLiteExecutor(t)
+			flaky := &flakyDatabase{Database: db, err: tt.err, failures: tt.failures}
+			executor := NewDatabaseExecutor(flaky)
+			executor.SetRetryPolicy(RetryPolicy{MaxRetries: tt.maxRetries, Backoff: time.Millisecond})
+
+			ctx := context.Background()
+			var err error
+			if tt.inTx {
+				// The transaction's statements bypass the flaky wrapper, so fail one directly
+				err = executor.WithTransaction(ctx, func(tx *DatabaseExecutor) error {
+					return tx.withRetry(ctx, retryTransient, flaky.fail)
+				})
+			} else {
+				err = responseError(executor.ExecuteSQL(ctx, tt.sql, nil, nil))
+			}
+
+			if success := err == nil; success != tt.wantSuccess {
+				t.Errorf("Expected success %v, got error %v", tt.wantSuccess, err)
+			}
+			if flaky.calls != tt.wantCalls {
+				t.Errorf("Expected %d attempts, got %d", tt.wantCalls, flaky.calls)
+			}
+		})
+	}
+}
+
+func TestWithRetryStopsWhenContextEnds(t *testing.T) {
+	_, db := newSQLiteExecutor(t)
+	executor := NewDatabaseExecutor(db)
+	executor.SetRetryPolicy(RetryPolicy{MaxRetries: 5, Backoff: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	err := executor.withRetry(ctx, retryTransient, func() error {
+		calls++
+		return sqlite3.Error{Code: sqlite3.ErrBusy}
+	})
+	if !errors.As(err, new(sqlite3.Error)) || calls != 1 {
+		t.Errorf("Expected the busy error after one attempt, got %v after %d", err, calls)
+	}
+}
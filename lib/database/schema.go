@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"fulcrum/lib/database/interfaces"
+)
+
+// TablesWithColumn returns the tables of the current database or schema that have a
+// column named column, read from the information schema (sqlite_master on SQLite)
+func (de *DatabaseExecutor) TablesWithColumn(ctx context.Context, column string) (map[string]bool, error) {
+	var query string
+	switch de.Driver() {
+	case interfaces.DriverPostgreSQL:
+		query = "SELECT table_name FROM information_schema.columns WHERE table_schema = current_schema() AND column_name = " + de.placeholder(1)
+	case interfaces.DriverMySQL:
+		query = "SELECT table_name FROM information_schema.columns WHERE table_schema = DATABASE() AND column_name = " + de.placeholder(1)
+	case interfaces.DriverSQLite:
+		query = "SELECT m.name FROM sqlite_master m JOIN pragma_table_info(m.name) c WHERE m.type = 'table' AND c.name = " + de.placeholder(1)
+	default:
+		return nil, fmt.Errorf("cannot list the columns of a %s database", de.Driver())
+	}
+
+	rows, err := de.queryUncached(ctx, query, column)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the information schema: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make(map[string]bool)
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, fmt.Errorf("failed to read the information schema: %w", err)
+		}
+		tables[table] = true
+	}
+	return tables, rows.Err()
+}
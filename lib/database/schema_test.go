@@ -0,0 +1,23 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTablesWithColumn(t *testing.T) {
+	db := newSQLiteDB(t,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, tenant_id INTEGER, title TEXT)",
+		"CREATE TABLE comments (id INTEGER PRIMARY KEY, tenant_id INTEGER, body TEXT)",
+		"CREATE TABLE tenants (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE VIEW tenant_posts AS SELECT tenant_id FROM posts",
+	)
+
+	tables, err := NewDatabaseExecutor(db).TablesWithColumn(context.Background(), "tenant_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 || !tables["posts"] || !tables["comments"] {
+		t.Errorf("Expected posts and comments, got %v", tables)
+	}
+}
@@ -0,0 +1,325 @@
+package seeder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// seedFilePattern matches seed file names such as 001_admin_user.yml
+var seedFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.ya?ml$`)
+
+// repeatableSeedFiles are the names of a domain's seeds.yml, which runs on every seed
+var repeatableSeedFiles = []string{"seeds.yml", "seeds.yaml"}
+
+// keyColumn is the row field that names a row for "@key" references
+const keyColumn = "_key"
+
+// identifierPattern matches the table and column names seeds may use
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// LoadAllSeeds loads the seed files of every domain, ordered by domain then version
+func LoadAllSeeds(appPath string) ([]Seed, error) {
+	domainsDir := filepath.Join(appPath, "domains")
+	entries, err := os.ReadDir(domainsDir)
+	if os.IsNotExist(err) {
+		return []Seed{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read domains directory: %w", err)
+	}
+
+	var seeds []Seed
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		domainSeeds, err := LoadDomainSeeds(appPath, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load seeds for domain %s: %w", entry.Name(), err)
+		}
+		seeds = append(seeds, domainSeeds...)
+	}
+
+	return orderByReferences(seeds), nil
+}
+
+// LoadDomainSeeds loads domains/<domain>/seeds/*.yml in version order, then the
+// domain's seeds.yml
+func LoadDomainSeeds(appPath, domain string) ([]Seed, error) {
+	seeds, err := loadVersionedSeeds(appPath, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range repeatableSeedFiles {
+		filePath := filepath.Join(appPath, "domains", domain, name)
+		content, err := os.ReadFile(filePath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read seed file: %w", err)
+		}
+
+		seed, err := ParseSeedContent(content)
+		if err == nil {
+			err = validateRepeatable(seed)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid seed file %s: %w", filePath, err)
+		}
+		seed.Name = strings.TrimSuffix(name, filepath.Ext(name))
+		seed.Domain = domain
+		seed.FilePath = filePath
+		seed.Repeatable = true
+		seeds = append(seeds, *seed)
+	}
+	return seeds, nil
+}
+
+// loadVersionedSeeds loads domains/<domain>/seeds/*.yml in version order
+func loadVersionedSeeds(appPath, domain string) ([]Seed, error) {
+	seedsDir := filepath.Join(appPath, "domains", domain, "seeds")
+	entries, err := os.ReadDir(seedsDir)
+	if os.IsNotExist(err) {
+		return []Seed{}, nil // No seeds directory is ok
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seeds directory: %w", err)
+	}
+
+	var seeds []Seed
+	versions := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !(strings.HasSuffix(entry.Name(), ".yml") || strings.HasSuffix(entry.Name(), ".yaml")) {
+			continue
+		}
+
+		filePath := filepath.Join(seedsDir, entry.Name())
+		seed, err := ParseSeedFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		if previous, exists := versions[seed.Version]; exists {
+			return nil, fmt.Errorf("seed version %d is used by both %s and %s", seed.Version, previous, entry.Name())
+		}
+		versions[seed.Version] = entry.Name()
+
+		seed.Domain = domain
+		seeds = append(seeds, *seed)
+	}
+
+	sort.Slice(seeds, func(i, j int) bool {
+		return seeds[i].Version < seeds[j].Version
+	})
+
+	return seeds, nil
+}
+
+// ParseSeedFile parses a seed file, taking its version and name from the file name
+func ParseSeedFile(filePath string) (*Seed, error) {
+	match := seedFilePattern.FindStringSubmatch(filepath.Base(filePath))
+	if match == nil {
+		return nil, fmt.Errorf("seed file %s must be named <version>_<name>.yml", filePath)
+	}
+	version, _ := strconv.Atoi(match[1])
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed file: %w", err)
+	}
+
+	seed, err := ParseSeedContent(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seed file %s: %w", filePath, err)
+	}
+
+	seed.Version = version
+	seed.Name = match[2]
+	seed.FilePath = filePath
+	return seed, nil
+}
+
+// ParseSeedContent parses and validates seed YAML
+func ParseSeedContent(content []byte) (*Seed, error) {
+	var seed Seed
+	if err := yaml.Unmarshal(content, &seed); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	if err := validateSeed(&seed); err != nil {
+		return nil, err
+	}
+	return &seed, nil
+}
+
+// validateSeed checks names are plain identifiers and values are scalars
+func validateSeed(seed *Seed) error {
+	if len(seed.Records) == 0 {
+		return fmt.Errorf("records are required")
+	}
+
+	for i, record := range seed.Records {
+		if !identifierPattern.MatchString(record.Table) {
+			return fmt.Errorf("records[%d]: invalid table name %q", i, record.Table)
+		}
+		if len(record.Rows) == 0 {
+			return fmt.Errorf("records[%d] (%s): rows are required", i, record.Table)
+		}
+
+		for j, row := range record.Rows {
+			if len(row) == 0 {
+				return fmt.Errorf("records[%d] (%s): rows[%d] is empty", i, record.Table, j)
+			}
+			if err := validateValues(row); err != nil {
+				return fmt.Errorf("records[%d] (%s): rows[%d]: %w", i, record.Table, j, err)
+			}
+		}
+
+		for _, column := range record.UniqueBy {
+			if !identifierPattern.MatchString(column) {
+				return fmt.Errorf("records[%d] (%s): unique_by: invalid column name %q", i, record.Table, column)
+			}
+			for j, row := range record.Rows {
+				if _, exists := row[column]; !exists {
+					return fmt.Errorf("records[%d] (%s): rows[%d] has no %s to match unique_by against", i, record.Table, j, column)
+				}
+			}
+		}
+
+		for j, row := range record.Rows {
+			key, keyed := row[keyColumn]
+			if !keyed {
+				continue
+			}
+			if name, ok := key.(string); !ok || name == "" {
+				return fmt.Errorf("records[%d] (%s): rows[%d]: %s must be a name", i, record.Table, j, keyColumn)
+			}
+			if len(record.keyColumns()) == 0 {
+				return fmt.Errorf("records[%d] (%s): rows with a %s need unique_by to be found by", i, record.Table, keyColumn)
+			}
+		}
+
+		if skip := record.SkipIfExists; skip != nil {
+			if skip.Table != "" && !identifierPattern.MatchString(skip.Table) {
+				return fmt.Errorf("records[%d]: skip_if_exists: invalid table name %q", i, skip.Table)
+			}
+			if len(skip.Where) == 0 && len(skip.Columns) == 0 {
+				return fmt.Errorf("records[%d] (%s): skip_if_exists needs where or columns", i, record.Table)
+			}
+			if err := validateValues(skip.Where); err != nil {
+				return fmt.Errorf("records[%d] (%s): skip_if_exists: %w", i, record.Table, err)
+			}
+			for _, column := range skip.Columns {
+				if !identifierPattern.MatchString(column) {
+					return fmt.Errorf("records[%d] (%s): skip_if_exists: invalid column name %q", i, record.Table, column)
+				}
+				for j, row := range record.Rows {
+					if _, exists := row[column]; !exists {
+						return fmt.Errorf("records[%d] (%s): rows[%d] has no %s to check skip_if_exists against", i, record.Table, j, column)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateRepeatable checks every record of a seed that runs on every seed leaves
+// existing rows alone or updates them, rather than inserting them again
+func validateRepeatable(seed *Seed) error {
+	for i, record := range seed.Records {
+		if len(record.UniqueBy) == 0 && record.SkipIfExists == nil {
+			return fmt.Errorf("records[%d] (%s): seeds.yml runs on every seed, so its records need unique_by or skip_if_exists", i, record.Table)
+		}
+	}
+	return nil
+}
+
+// orderByReferences moves each domain's seeds after those of the domains defining the
+// keys they reference, keeping domains in name order otherwise
+func orderByReferences(seeds []Seed) []Seed {
+	keyDomains := make(map[string]string)
+	var domains []string
+	byDomain := make(map[string][]Seed)
+	for _, seed := range seeds {
+		if _, seen := byDomain[seed.Domain]; !seen {
+			domains = append(domains, seed.Domain)
+		}
+		byDomain[seed.Domain] = append(byDomain[seed.Domain], seed)
+		for _, record := range seed.Records {
+			for _, row := range record.Rows {
+				if key, ok := row[keyColumn].(string); ok {
+					keyDomains[key] = seed.Domain
+				}
+			}
+		}
+	}
+
+	// Each domain is placed after the domains it depends on; a cycle is left for
+	// the seeding to report when a key is missing
+	ordered := make([]Seed, 0, len(seeds))
+	placed := make(map[string]bool)
+	var place func(domain string)
+	place = func(domain string) {
+		if placed[domain] {
+			return
+		}
+		placed[domain] = true
+		var dependencies []string
+		for _, seed := range byDomain[domain] {
+			for _, record := range seed.Records {
+				for _, row := range record.Rows {
+					for _, value := range row {
+						if key, ok := referencedKey(value); ok && keyDomains[key] != "" {
+							dependencies = append(dependencies, keyDomains[key])
+						}
+					}
+				}
+			}
+		}
+		sort.Strings(dependencies)
+		for _, dependency := range dependencies {
+			place(dependency)
+		}
+		ordered = append(ordered, byDomain[domain]...)
+	}
+	for _, domain := range domains {
+		place(domain)
+	}
+	return ordered
+}
+
+// referencedKey returns the key an "@key" value references; "@@" escapes a leading @
+func referencedKey(value any) (string, bool) {
+	s, ok := value.(string)
+	if !ok || !strings.HasPrefix(s, "@") || strings.HasPrefix(s, "@@") {
+		return "", false
+	}
+	return s[1:], true
+}
+
+// validateValues checks column names and that every value is a scalar
+func validateValues(values map[string]any) error {
+	for column, value := range values {
+		if !identifierPattern.MatchString(column) {
+			return fmt.Errorf("invalid column name %q", column)
+		}
+		switch value.(type) {
+		case nil, string, bool, int, int64, uint64, float64:
+		default:
+			return fmt.Errorf("column %s: unsupported value %v (use a string, number, boolean or null)", column, value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,464 @@
+// Package seeder loads YAML seed files from domains/<name>/seeds/ and inserts their
+// rows, recording each applied file in schema_seeds so it only runs once. A domain's
+// seeds.yml runs on every seed, updating the rows it seeded before.
+package seeder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"fulcrum/lib/auth"
+	"fulcrum/lib/database/interfaces"
+)
+
+// bcryptPrefix marks a seed value as a password to store hashed
+const bcryptPrefix = "bcrypt:"
+
+// Seeder applies seed files to the database
+type Seeder struct {
+	db      interfaces.Database
+	appPath string
+	keys    map[string]keyedRow // Rows named by _key in every seed file
+}
+
+// keyedRow is a seed row named by _key, found by its record's key columns
+type keyedRow struct {
+	table   string
+	columns []string
+	row     map[string]any
+}
+
+// NewSeeder creates a seeder for the app at appPath
+func NewSeeder(db interfaces.Database, appPath string) *Seeder {
+	return &Seeder{
+		db:      db,
+		appPath: appPath,
+	}
+}
+
+// Initialize creates the schema_seeds table if it doesn't exist
+func (s *Seeder) Initialize(ctx context.Context) error {
+	exists, err := s.db.TableExists(ctx, "schema_seeds")
+	if err != nil {
+		return fmt.Errorf("failed to check if schema_seeds table exists: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	schema := interfaces.TableSchema{
+		Columns: []interfaces.ColumnDefinition{
+			{Name: "version", Type: "integer", NotNull: true},
+			{Name: "domain", Type: "varchar(255)", NotNull: true},
+			{Name: "name", Type: "varchar(255)", NotNull: true},
+			{Name: "applied_at", Type: "timestamp", NotNull: true},
+		},
+		PrimaryKey: []string{"version", "domain"},
+	}
+
+	if err := s.db.CreateTable(ctx, "schema_seeds", schema); err != nil {
+		return fmt.Errorf("failed to create schema_seeds table: %w", err)
+	}
+	return nil
+}
+
+// SeedAll applies every seed file not yet recorded in schema_seeds, in version order per
+// domain, and every seeds.yml
+func (s *Seeder) SeedAll(ctx context.Context) error {
+	return s.seed(ctx, "")
+}
+
+// SeedDomain is SeedAll for one domain's seeds
+func (s *Seeder) SeedDomain(ctx context.Context, domain string) error {
+	return s.seed(ctx, domain)
+}
+
+// seed applies the pending seeds of domain, or of every domain when it is empty
+func (s *Seeder) seed(ctx context.Context, domain string) error {
+	log.Println("🌱 Running pending seeds...")
+
+	if err := s.Initialize(ctx); err != nil {
+		return err
+	}
+
+	statuses, err := s.GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Keys resolve across files and domains, including seeds applied before
+	s.keys = make(map[string]keyedRow)
+	for _, status := range statuses {
+		for _, record := range status.Seed.Records {
+			for _, row := range record.Rows {
+				key, ok := row[keyColumn].(string)
+				if !ok {
+					continue
+				}
+				if _, exists := s.keys[key]; exists {
+					return fmt.Errorf("seed key %q is defined twice (again in %s)", key, status.Seed.FilePath)
+				}
+				s.keys[key] = keyedRow{table: record.Table, columns: record.keyColumns(), row: row}
+			}
+		}
+	}
+
+	applied := 0
+	for _, status := range statuses {
+		if status.AppliedAt != nil || (domain != "" && status.Seed.Domain != domain) {
+			continue
+		}
+		if err := s.applySeed(ctx, status.Seed); err != nil {
+			return fmt.Errorf("failed to apply seed %s:%d (%s): %w", status.Seed.Domain, status.Seed.Version, status.Seed.Name, err)
+		}
+		applied++
+	}
+
+	if applied == 0 {
+		log.Println("✅ No pending seeds")
+	} else {
+		log.Printf("✅ Applied %d seeds", applied)
+	}
+	return nil
+}
+
+// GetStatus lists every seed file with the time it was applied, if it has been
+func (s *Seeder) GetStatus(ctx context.Context) ([]SeedStatus, error) {
+	seeds, err := LoadAllSeeds(s.appPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seeds: %w", err)
+	}
+
+	applied, err := s.AppliedSeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[string]time.Time)
+	for _, record := range applied {
+		appliedAt[seedKey(record.Domain, record.Version)] = record.AppliedAt
+	}
+
+	statuses := make([]SeedStatus, 0, len(seeds))
+	for _, seed := range seeds {
+		status := SeedStatus{Seed: seed}
+		if at, exists := appliedAt[seedKey(seed.Domain, seed.Version)]; exists {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// AppliedSeeds returns the rows of schema_seeds
+func (s *Seeder) AppliedSeeds(ctx context.Context) ([]AppliedSeed, error) {
+	rows, err := s.db.Query(ctx, "SELECT version, domain, name, applied_at FROM schema_seeds ORDER BY domain, version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applied seeds: %w", err)
+	}
+	defer rows.Close()
+
+	var applied []AppliedSeed
+	for rows.Next() {
+		var record AppliedSeed
+		if err := rows.Scan(&record.Version, &record.Domain, &record.Name, &record.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan seed record: %w", err)
+		}
+		applied = append(applied, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating seed records: %w", err)
+	}
+	return applied, nil
+}
+
+// applySeed inserts a seed's rows and records it in one transaction
+func (s *Seeder) applySeed(ctx context.Context, seed Seed) (err error) {
+	log.Printf("🌱 Applying seed %s:%d - %s", seed.Domain, seed.Version, seed.Name)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, record := range seed.Records {
+		counts, err := s.applyRecord(ctx, tx, record)
+		if err != nil {
+			return err
+		}
+		log.Printf("   🔨 %s: %d inserted, %d updated, %d skipped", record.Table, counts.inserted, counts.updated, counts.skipped)
+	}
+
+	if seed.Repeatable {
+		if err = tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil
+	}
+
+	insertSQL := fmt.Sprintf("INSERT INTO schema_seeds (version, domain, name, applied_at) VALUES (%s)",
+		strings.Join(s.placeholders(1, 4), ", "))
+	if _, err = tx.Exec(ctx, insertSQL, seed.Version, seed.Domain, seed.Name, time.Now()); err != nil {
+		return fmt.Errorf("failed to record seed: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// recordCounts tallies what applyRecord did with a record's rows
+type recordCounts struct {
+	inserted, updated, skipped int
+}
+
+// applyRecord inserts a record's rows, leaving out those skip_if_exists finds and
+// updating those unique_by finds
+func (s *Seeder) applyRecord(ctx context.Context, tx interfaces.Tx, record SeedRecord) (counts recordCounts, err error) {
+	skip := record.SkipIfExists
+	skipTable := record.Table
+	if skip != nil && skip.Table != "" {
+		skipTable = skip.Table
+	}
+
+	if skip != nil && len(skip.Where) > 0 {
+		where, err := s.resolveRow(ctx, tx, skip.Where)
+		if err != nil {
+			return counts, err
+		}
+		exists, err := s.rowExists(ctx, tx, skipTable, where)
+		if err != nil {
+			return counts, err
+		}
+		if exists {
+			counts.skipped = len(record.Rows)
+			return counts, nil
+		}
+	}
+
+	for _, raw := range record.Rows {
+		row, err := s.resolveRow(ctx, tx, raw)
+		if err != nil {
+			return counts, fmt.Errorf("%s: %w", record.Table, err)
+		}
+
+		if skip != nil && len(skip.Columns) > 0 {
+			exists, err := s.rowExists(ctx, tx, skipTable, pick(row, skip.Columns))
+			if err != nil {
+				return counts, err
+			}
+			if exists {
+				counts.skipped++
+				continue
+			}
+		}
+
+		if len(record.UniqueBy) > 0 {
+			where := pick(row, record.UniqueBy)
+			exists, err := s.rowExists(ctx, tx, record.Table, where)
+			if err != nil {
+				return counts, err
+			}
+			if exists {
+				if err := s.updateRow(ctx, tx, record.Table, raw, row, where); err != nil {
+					return counts, err
+				}
+				counts.updated++
+				continue
+			}
+		}
+
+		columns := sortedColumns(row)
+		args := make([]any, len(columns))
+		for i, column := range columns {
+			args[i] = row[column]
+		}
+
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+			record.Table, strings.Join(columns, ", "), strings.Join(s.placeholders(1, len(columns)), ", "))
+		if _, err := tx.Exec(ctx, query, args...); err != nil {
+			return counts, fmt.Errorf("failed to insert into %s: %w", record.Table, err)
+		}
+		counts.inserted++
+	}
+
+	return counts, nil
+}
+
+// updateRow sets the columns of the row matching where to a seed row's values. Passwords
+// still matching their stored hash are left alone, so reseeding doesn't rehash them.
+func (s *Seeder) updateRow(ctx context.Context, tx interfaces.Tx, table string, raw, row, where map[string]any) error {
+	var setters []string
+	var args []any
+	for _, column := range sortedColumns(row) {
+		if _, unique := where[column]; unique {
+			continue
+		}
+		if password, ok := strings.CutPrefix(fmt.Sprint(raw[column]), bcryptPrefix); ok {
+			conditions, whereArgs := s.conditions(where, 1)
+			var hash string
+			query := fmt.Sprintf("SELECT %s FROM %s WHERE %s", column, table, conditions)
+			if err := tx.QueryRow(ctx, query, whereArgs...).Scan(&hash); err == nil && auth.ValidatePassword(password, hash) {
+				continue
+			}
+		}
+		args = append(args, row[column])
+		setters = append(setters, column+" = "+s.placeholder(len(args)))
+	}
+	if len(setters) == 0 {
+		return nil
+	}
+
+	conditions, whereArgs := s.conditions(where, len(args)+1)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, strings.Join(setters, ", "), conditions)
+	if _, err := tx.Exec(ctx, query, append(args, whereArgs...)...); err != nil {
+		return fmt.Errorf("failed to update %s: %w", table, err)
+	}
+	return nil
+}
+
+// resolveRow returns a seed row's columns with @key references replaced by the ids of
+// the rows they name and bcrypt: passwords hashed
+func (s *Seeder) resolveRow(ctx context.Context, tx interfaces.Tx, row map[string]any) (map[string]any, error) {
+	resolved := make(map[string]any, len(row))
+	for column, value := range row {
+		if column == keyColumn {
+			continue
+		}
+		value, err := s.resolveValue(ctx, tx, value)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", column, err)
+		}
+		resolved[column] = value
+	}
+	return resolved, nil
+}
+
+// resolveValue returns the value to store for a seed value
+func (s *Seeder) resolveValue(ctx context.Context, tx interfaces.Tx, value any) (any, error) {
+	str, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	if key, ok := referencedKey(str); ok {
+		return s.keyID(ctx, tx, key)
+	}
+	if strings.HasPrefix(str, "@@") {
+		return str[1:], nil
+	}
+	if password, ok := strings.CutPrefix(str, bcryptPrefix); ok {
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+		return hash, nil
+	}
+	return str, nil
+}
+
+// keyID returns the id of the row a key names, found by its record's key columns
+func (s *Seeder) keyID(ctx context.Context, tx interfaces.Tx, key string) (any, error) {
+	keyed, ok := s.keys[key]
+	if !ok {
+		return nil, fmt.Errorf("unknown seed key @%s", key)
+	}
+
+	where := make(map[string]any, len(keyed.columns))
+	for _, column := range keyed.columns {
+		value, err := s.resolveValue(ctx, tx, keyed.row[column])
+		if err != nil {
+			return nil, fmt.Errorf("@%s: %w", key, err)
+		}
+		where[column] = value
+	}
+
+	conditions, args := s.conditions(where, 1)
+	var id any
+	query := fmt.Sprintf("SELECT id FROM %s WHERE %s", keyed.table, conditions)
+	if err := tx.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("@%s has not been seeded into %s yet", key, keyed.table)
+		}
+		return nil, fmt.Errorf("failed to look up @%s in %s: %w", key, keyed.table, err)
+	}
+	return id, nil
+}
+
+// rowExists reports whether table has a row matching every column in where
+func (s *Seeder) rowExists(ctx context.Context, tx interfaces.Tx, table string, where map[string]any) (bool, error) {
+	conditions, args := s.conditions(where, 1)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", table, conditions)
+	var count int
+	if err := tx.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check for existing rows in %s: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// conditions returns a WHERE clause matching every column in where, with bind
+// parameters starting at index, and its arguments
+func (s *Seeder) conditions(where map[string]any, index int) (string, []any) {
+	var conditions []string
+	var args []any
+	for _, column := range sortedColumns(where) {
+		if where[column] == nil {
+			conditions = append(conditions, column+" IS NULL")
+			continue
+		}
+		args = append(args, where[column])
+		conditions = append(conditions, column+" = "+s.placeholder(index+len(args)-1))
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// pick returns the given columns of row
+func pick(row map[string]any, columns []string) map[string]any {
+	picked := make(map[string]any, len(columns))
+	for _, column := range columns {
+		picked[column] = row[column]
+	}
+	return picked
+}
+
+// placeholder returns the driver's bind parameter for the given 1-based index
+func (s *Seeder) placeholder(index int) string {
+	if s.db.GetDriver() == interfaces.DriverPostgreSQL {
+		return fmt.Sprintf("$%d", index)
+	}
+	return "?"
+}
+
+// placeholders returns count bind parameters starting at index
+func (s *Seeder) placeholders(index, count int) []string {
+	placeholders := make([]string, count)
+	for i := range placeholders {
+		placeholders[i] = s.placeholder(index + i)
+	}
+	return placeholders
+}
+
+// sortedColumns returns the map's keys in order so generated SQL is stable
+func sortedColumns(values map[string]any) []string {
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func seedKey(domain string, version int) string {
+	return fmt.Sprintf("%s:%d", domain, version)
+}
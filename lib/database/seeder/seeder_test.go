@@ -0,0 +1,429 @@
+package seeder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fulcrum/lib/auth"
+	"fulcrum/lib/database/interfaces"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteDatabase adapts an in-memory SQLite database to interfaces.Database
+type sqliteDatabase struct {
+	db *sql.DB
+}
+
+func (s *sqliteDatabase) Connect(ctx context.Context) error { return nil }
+func (s *sqliteDatabase) Close() error                      { return s.db.Close() }
+func (s *sqliteDatabase) Ping(ctx context.Context) error    { return s.db.PingContext(ctx) }
+func (s *sqliteDatabase) Stats() sql.DBStats                { return s.db.Stats() }
+func (s *sqliteDatabase) GetConnectionString() string       { return ":memory:" }
+
+func (s *sqliteDatabase) GetDriver() interfaces.DatabaseDriver {
+	return interfaces.DriverSQLite
+}
+
+func (s *sqliteDatabase) Query(ctx context.Context, query string, args ...any) (interfaces.Rows, error) {
+	return s.db.QueryContext(ctx, query, args...)
+}
+
+func (s *sqliteDatabase) QueryRow(ctx context.Context, query string, args ...any) interfaces.Row {
+	return s.db.QueryRowContext(ctx, query, args...)
+}
+
+func (s *sqliteDatabase) Exec(ctx context.Context, query string, args ...any) (interfaces.Result, error) {
+	return s.db.ExecContext(ctx, query, args...)
+}
+
+func (s *sqliteDatabase) Begin(ctx context.Context) (interfaces.Tx, error) {
+	return s.BeginTx(ctx, nil)
+}
+
+func (s *sqliteDatabase) BeginTx(ctx context.Context, opts *sql.TxOptions) (interfaces.Tx, error) {
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteTx{tx: tx}, nil
+}
+
+func (s *sqliteDatabase) CreateTable(ctx context.Context, tableName string, schema interfaces.TableSchema) error {
+	var columns []string
+	for _, column := range schema.Columns {
+		definition := column.Name + " " + column.Type
+		if column.NotNull {
+			definition += " NOT NULL"
+		}
+		columns = append(columns, definition)
+	}
+	columns = append(columns, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(schema.PrimaryKey, ", ")))
+
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", tableName, strings.Join(columns, ", ")))
+	return err
+}
+
+func (s *sqliteDatabase) DropTable(ctx context.Context, tableName string) error {
+	_, err := s.db.ExecContext(ctx, "DROP TABLE IF EXISTS "+tableName)
+	return err
+}
+
+func (s *sqliteDatabase) TableExists(ctx context.Context, tableName string) (bool, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", tableName).Scan(&count)
+	return count > 0, err
+}
+
+type sqliteTx struct {
+	tx *sql.Tx
+}
+
+func (t *sqliteTx) Commit() error   { return t.tx.Commit() }
+func (t *sqliteTx) Rollback() error { return t.tx.Rollback() }
+
+func (t *sqliteTx) Query(ctx context.Context, query string, args ...any) (interfaces.Rows, error) {
+	return t.tx.QueryContext(ctx, query, args...)
+}
+
+func (t *sqliteTx) QueryRow(ctx context.Context, query string, args ...any) interfaces.Row {
+	return t.tx.QueryRowContext(ctx, query, args...)
+}
+
+func (t *sqliteTx) Exec(ctx context.Context, query string, args ...any) (interfaces.Result, error) {
+	return t.tx.ExecContext(ctx, query, args...)
+}
+
+func newTestDatabase(t *testing.T) *sqliteDatabase {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1) // Every connection to :memory: is a separate database
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT, role TEXT, active INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	return &sqliteDatabase{db: db}
+}
+
+func writeSeed(t *testing.T, appPath, domain, name, content string) {
+	t.Helper()
+	dir := filepath.Join(appPath, "domains", domain, "seeds")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func countUsers(t *testing.T, db *sqliteDatabase, where string) int {
+	t.Helper()
+	var count int
+	if err := db.db.QueryRow("SELECT COUNT(*) FROM users WHERE " + where).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestSeedAll(t *testing.T) {
+	ctx := context.Background()
+	appPath := t.TempDir()
+	db := newTestDatabase(t)
+
+	writeSeed(t, appPath, "users", "001_admin_user.yml", `records:
+  - table: users
+    skip_if_exists:
+      table: users
+      where:
+        email: admin@example.com
+    rows:
+      - email: admin@example.com
+        role: admin
+        active: true
+`)
+	writeSeed(t, appPath, "users", "002_members.yml", `records:
+  - table: users
+    skip_if_exists:
+      columns: [email]
+    rows:
+      - email: existing@example.com
+        role: member
+      - email: new@example.com
+        role: member
+`)
+
+	// Rows already present before seeding are skipped, not duplicated
+	if _, err := db.db.Exec("INSERT INTO users (email, role) VALUES ('admin@example.com', 'owner'), ('existing@example.com', 'member')"); err != nil {
+		t.Fatal(err)
+	}
+
+	seeder := NewSeeder(db, appPath)
+	if err := seeder.SeedAll(ctx); err != nil {
+		t.Fatalf("SeedAll failed: %v", err)
+	}
+
+	if count := countUsers(t, db, "email = 'admin@example.com'"); count != 1 {
+		t.Errorf("Expected where to skip the existing admin, got %d rows", count)
+	}
+	if count := countUsers(t, db, "role = 'admin'"); count != 0 {
+		t.Errorf("Expected the admin row not to be inserted, got %d", count)
+	}
+	if count := countUsers(t, db, "email = 'existing@example.com'"); count != 1 {
+		t.Errorf("Expected columns to skip the existing member, got %d rows", count)
+	}
+	if count := countUsers(t, db, "email = 'new@example.com'"); count != 1 {
+		t.Errorf("Expected the new member to be inserted, got %d rows", count)
+	}
+
+	statuses, err := seeder.GetStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 seeds, got %d", len(statuses))
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			t.Errorf("Expected %s to be recorded as applied", status.Seed.Name)
+		}
+	}
+
+	// A second run applies nothing, even after the data is removed
+	if _, err := db.db.Exec("DELETE FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	if err := seeder.SeedAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if count := countUsers(t, db, "1 = 1"); count != 0 {
+		t.Errorf("Expected applied seeds not to run again, got %d rows", count)
+	}
+}
+
+func TestSeedAllOrdersByVersionAndRollsBackFailures(t *testing.T) {
+	ctx := context.Background()
+	appPath := t.TempDir()
+	db := newTestDatabase(t)
+
+	writeSeed(t, appPath, "users", "010_later.yml", "records:\n  - table: users\n    rows:\n      - email: later@example.com\n")
+	writeSeed(t, appPath, "users", "002_first.yml", "records:\n  - table: users\n    rows:\n      - email: first@example.com\n")
+	writeSeed(t, appPath, "users", "011_broken.yml", `records:
+  - table: users
+    rows:
+      - email: partial@example.com
+  - table: missing_table
+    rows:
+      - name: x
+`)
+
+	seeder := NewSeeder(db, appPath)
+	err := seeder.SeedAll(ctx)
+	if err == nil || !strings.Contains(err.Error(), "users:11 (broken)") {
+		t.Fatalf("Expected the broken seed to fail, got %v", err)
+	}
+
+	var first, later int
+	db.db.QueryRow("SELECT id FROM users WHERE email = 'first@example.com'").Scan(&first)
+	db.db.QueryRow("SELECT id FROM users WHERE email = 'later@example.com'").Scan(&later)
+	if first == 0 || later == 0 || first > later {
+		t.Errorf("Expected 002 to run before 010, got ids %d and %d", first, later)
+	}
+	if count := countUsers(t, db, "email = 'partial@example.com'"); count != 0 {
+		t.Errorf("Expected the failed seed to be rolled back, got %d rows", count)
+	}
+
+	applied, err := seeder.AppliedSeeds(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 2 {
+		t.Errorf("Expected only the successful seeds to be recorded, got %+v", applied)
+	}
+}
+
+func TestParseSeedContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		errorMsg string
+	}{
+		{name: "valid", content: "records:\n  - table: users\n    rows:\n      - email: a@example.com\n"},
+		{name: "no records", content: "records: []\n", errorMsg: "records are required"},
+		{name: "no rows", content: "records:\n  - table: users\n", errorMsg: "rows are required"},
+		{name: "bad table", content: "records:\n  - table: users; DROP TABLE users\n    rows:\n      - email: a\n", errorMsg: "invalid table name"},
+		{name: "bad column", content: "records:\n  - table: users\n    rows:\n      - \"email)\": a\n", errorMsg: "invalid column name"},
+		{name: "nested value", content: "records:\n  - table: users\n    rows:\n      - email: {a: b}\n", errorMsg: "unsupported value"},
+		{name: "empty skip", content: "records:\n  - table: users\n    skip_if_exists: {}\n    rows:\n      - email: a\n", errorMsg: "needs where or columns"},
+		{name: "unique_by column missing from row", content: "records:\n  - table: users\n    unique_by: [email]\n    rows:\n      - role: a\n", errorMsg: "has no email"},
+		{name: "key without unique_by", content: "records:\n  - table: users\n    rows:\n      - _key: admin\n        email: a\n", errorMsg: "need unique_by"},
+		{name: "key that is not a name", content: "records:\n  - table: users\n    unique_by: [email]\n    rows:\n      - _key: 1\n        email: a\n", errorMsg: "_key must be a name"},
+		{name: "skip column missing from row", content: "records:\n  - table: users\n    skip_if_exists:\n      columns: [email]\n    rows:\n      - role: a\n", errorMsg: "has no email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSeedContent([]byte(tt.content))
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestParseSeedFileName(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "admin.yml")
+	if err := os.WriteFile(path, []byte("records:\n  - table: users\n    rows:\n      - email: a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParseSeedFile(path); err == nil || !strings.Contains(err.Error(), "<version>_<name>.yml") {
+		t.Errorf("Expected a file name error, got %v", err)
+	}
+}
+
+func TestSeedsYMLIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	appPath := t.TempDir()
+	db := newTestDatabase(t)
+	if _, err := db.db.Exec("ALTER TABLE users ADD COLUMN password_hash TEXT"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.db.Exec("CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT, user_id INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+
+	// posts sorts before users, so its reference has to move users first
+	writeDomainFile(t, appPath, "posts", "seeds.yml", `records:
+  - table: posts
+    unique_by: [title]
+    rows:
+      - title: Welcome
+        user_id: "@admin"
+      - title: "@@mentions"
+        user_id: "@member"
+`)
+	writeDomainFile(t, appPath, "users", "seeds.yml", `records:
+  - table: users
+    unique_by: [email]
+    rows:
+      - _key: admin
+        email: admin@example.com
+        role: admin
+        password_hash: bcrypt:secret
+      - _key: member
+        email: member@example.com
+        role: member
+`)
+
+	seeder := NewSeeder(db, appPath)
+	for run := 1; run <= 2; run++ {
+		if err := seeder.SeedAll(ctx); err != nil {
+			t.Fatalf("Seed run %d failed: %v", run, err)
+		}
+	}
+
+	if count := countUsers(t, db, "1 = 1"); count != 2 {
+		t.Errorf("Expected 2 users after seeding twice, got %d", count)
+	}
+	var posts int
+	db.db.QueryRow("SELECT COUNT(*) FROM posts").Scan(&posts)
+	if posts != 2 {
+		t.Errorf("Expected 2 posts after seeding twice, got %d", posts)
+	}
+
+	var adminID, authorID int
+	var hash string
+	db.db.QueryRow("SELECT id, password_hash FROM users WHERE email = 'admin@example.com'").Scan(&adminID, &hash)
+	db.db.QueryRow("SELECT user_id FROM posts WHERE title = 'Welcome'").Scan(&authorID)
+	if adminID == 0 || authorID != adminID {
+		t.Errorf("Expected @admin to resolve to user %d, got %d", adminID, authorID)
+	}
+	if !auth.ValidatePassword("secret", hash) {
+		t.Errorf("Expected the seeded password to be hashed with bcrypt, got %q", hash)
+	}
+	var mentions int
+	db.db.QueryRow("SELECT COUNT(*) FROM posts WHERE title = '@mentions'").Scan(&mentions)
+	if mentions != 1 {
+		t.Error("Expected @@ to escape a leading @")
+	}
+
+	// Changed values update the seeded row; an unchanged password keeps its hash
+	writeDomainFile(t, appPath, "users", "seeds.yml", `records:
+  - table: users
+    unique_by: [email]
+    rows:
+      - _key: admin
+        email: admin@example.com
+        role: owner
+        password_hash: bcrypt:secret
+`)
+	if err := seeder.SeedDomain(ctx, "users"); err != nil {
+		t.Fatal(err)
+	}
+	var role, rehashed string
+	db.db.QueryRow("SELECT role, password_hash FROM users WHERE email = 'admin@example.com'").Scan(&role, &rehashed)
+	if role != "owner" {
+		t.Errorf("Expected the seeded row to be updated, got role %q", role)
+	}
+	if rehashed != hash {
+		t.Error("Expected an unchanged password not to be rehashed")
+	}
+}
+
+func TestSeedReferenceErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		errorMsg string
+	}{
+		{
+			name:     "unknown key",
+			content:  "records:\n  - table: users\n    unique_by: [email]\n    rows:\n      - email: a@example.com\n        role: \"@nobody\"\n",
+			errorMsg: "unknown seed key @nobody",
+		},
+		{
+			name:     "seeds.yml without unique_by",
+			content:  "records:\n  - table: users\n    rows:\n      - email: a@example.com\n",
+			errorMsg: "need unique_by or skip_if_exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appPath := t.TempDir()
+			writeDomainFile(t, appPath, "users", "seeds.yml", tt.content)
+
+			err := NewSeeder(newTestDatabase(t), appPath).SeedAll(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func writeDomainFile(t *testing.T, appPath, domain, name, content string) {
+	t.Helper()
+	dir := filepath.Join(appPath, "domains", domain)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
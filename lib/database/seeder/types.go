@@ -0,0 +1,58 @@
+package seeder
+
+import "time"
+
+// Seed represents a single seed file, e.g. domains/users/seeds/001_admin_user.yml, or
+// a domain's seeds.yml, which is Repeatable
+type Seed struct {
+	Version    int          `yaml:"-"` // From the file name prefix
+	Name       string       `yaml:"-"` // From the file name after the prefix
+	Domain     string       `yaml:"-"`
+	FilePath   string       `yaml:"-"`
+	Repeatable bool         `yaml:"-"` // Runs on every seed instead of once
+	Records    []SeedRecord `yaml:"records"`
+}
+
+// SeedRecord inserts rows into one table. Rows matching another by the UniqueBy
+// columns update it instead. A row's _key names it, so other rows can store its id
+// with "@key"; "bcrypt:<password>" values are stored hashed.
+type SeedRecord struct {
+	Table        string           `yaml:"table"`
+	Rows         []map[string]any `yaml:"rows"`
+	UniqueBy     []string         `yaml:"unique_by,omitempty"`
+	SkipIfExists *SkipIfExists    `yaml:"skip_if_exists,omitempty"`
+}
+
+// keyColumns returns the columns that find a record's rows once they are seeded
+func (r SeedRecord) keyColumns() []string {
+	if len(r.UniqueBy) > 0 {
+		return r.UniqueBy
+	}
+	if r.SkipIfExists != nil && (r.SkipIfExists.Table == "" || r.SkipIfExists.Table == r.Table) {
+		return r.SkipIfExists.Columns
+	}
+	return nil
+}
+
+// SkipIfExists decides which rows are already present. Where skips the whole
+// record when a matching row exists; Columns skips each row whose values in
+// those columns are already present.
+type SkipIfExists struct {
+	Table   string         `yaml:"table"` // Defaults to the record's table
+	Where   map[string]any `yaml:"where,omitempty"`
+	Columns []string       `yaml:"columns,omitempty"`
+}
+
+// AppliedSeed is a row of the schema_seeds table
+type AppliedSeed struct {
+	Version   int       `json:"version"`
+	Domain    string    `json:"domain"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// SeedStatus reports whether a seed file has been applied
+type SeedStatus struct {
+	Seed      Seed       `json:"seed"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"` // Nil while pending
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// SoftDeleteColumn is the column that marks a row deleted; tables that have it keep
+// their deleted rows until a hard delete
+const SoftDeleteColumn = "deleted_at"
+
+// WithDeletedKey in a find query includes soft deleted rows
+const WithDeletedKey = "_with_deleted"
+
+// notDeleted is the condition that leaves out soft deleted rows
+const notDeleted = SoftDeleteColumn + " IS NULL"
+
+// softDeleteTables caches which tables have a deleted_at column. It is shared with the
+// executors WithTransaction hands out.
+type softDeleteTables struct {
+	mu     sync.RWMutex
+	tables map[string]bool
+}
+
+// LoadSoftDeleteTables reads which tables have a deleted_at column from the information
+// schema. Until it is loaded, finds and deletes treat every table as having none; load
+// it again after migrations add or drop the column.
+func (de *DatabaseExecutor) LoadSoftDeleteTables(ctx context.Context) error {
+	tables, err := de.TablesWithColumn(ctx, SoftDeleteColumn)
+	if err != nil {
+		return fmt.Errorf("failed to find soft delete tables: %w", err)
+	}
+	de.softDelete.mu.Lock()
+	de.softDelete.tables = tables
+	de.softDelete.mu.Unlock()
+	return nil
+}
+
+// softDeletes reports whether table has a deleted_at column
+func (de *DatabaseExecutor) softDeletes(table string) bool {
+	de.softDelete.mu.RLock()
+	defer de.softDelete.mu.RUnlock()
+	return de.softDelete.tables[table]
+}
+
+// RestoreRecord handles direct restore calls, clearing a soft deleted record's deleted_at
+func (de *DatabaseExecutor) RestoreRecord(ctx context.Context, table string, id any, requestID *string) ([]byte, error) {
+	req := SingleOperationRequest{
+		Operation: "restore",
+		Table:     table,
+		ID:        id,
+		RequestID: requestID,
+	}
+	return de.executeOperation(ctx, req)
+}
+
+// HardDeleteRecord handles direct delete calls by id that remove the row even when the
+// table soft deletes
+func (de *DatabaseExecutor) HardDeleteRecord(ctx context.Context, table string, id any, requestID *string) ([]byte, error) {
+	req := SingleOperationRequest{
+		Operation: "delete",
+		Table:     table,
+		ID:        id,
+		Hard:      true,
+		RequestID: requestID,
+	}
+	return de.executeOperation(ctx, req)
+}
+
+// restoreRecord handles restore operations for a single id
+func (de *DatabaseExecutor) restoreRecord(ctx context.Context, table string, id any) OperationResponse {
+	if id == nil {
+		return OperationResponse{
+			Success: false,
+			Error:   "No id provided for restore",
+		}
+	}
+	if !de.softDeletes(table) {
+		return OperationResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Restore failed: %s has no %s column", table, SoftDeleteColumn),
+		}
+	}
+
+	where := fmt.Sprintf("id = %s AND %s IS NOT NULL", de.placeholder(1), SoftDeleteColumn)
+	query := fmt.Sprintf("UPDATE %s SET %s = NULL WHERE %s", table, SoftDeleteColumn, where)
+	if de.audits(table) {
+		return de.auditedChange(ctx, table, AuditRestore, where, []any{id}, func(tx *DatabaseExecutor) OperationResponse {
+			return tx.restore(ctx, query, id)
+		})
+	}
+	return de.restore(ctx, query, id)
+}
+
+// restore runs a restoreRecord UPDATE
+func (de *DatabaseExecutor) restore(ctx context.Context, query string, id any) OperationResponse {
+	result, err := de.execUncached(ctx, query, id)
+	if err != nil {
+		return OperationResponse{
+			Success: false,
+			Error:   "Restore failed: " + err.Error(),
+		}
+	}
+
+	affected, _ := result.RowsAffected()
+	return OperationResponse{
+		Success: true,
+		Count:   int(affected),
+	}
+}
+
+// truthy reports whether a query flag such as _with_deleted is set, whether it came
+// from JSON or a query string
+func truthy(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		set, err := strconv.ParseBool(v)
+		return err == nil && set
+	case nil:
+		return false
+	default:
+		return fmt.Sprint(v) != "0"
+	}
+}
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// newSoftDeleteExecutor returns an executor whose posts table has a deleted_at column,
+// holding posts 1 and 2 and a post 3 deleted earlier
+func newSoftDeleteExecutor(t *testing.T) *DatabaseExecutor {
+	t.Helper()
+	executor, db := newSQLiteExecutor(t)
+	execAll(t, db,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT, deleted_at TIMESTAMP)",
+		"INSERT INTO posts (id, title, deleted_at) VALUES (1, 'a', NULL), (2, 'b', NULL), (3, 'c', '2024-01-01 00:00:00')",
+	)
+	ctx := context.Background()
+	if err := executor.LoadSoftDeleteTables(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return executor
+}
+
+// postIDs returns the ids a find on posts returns
+func postIDs(t *testing.T, executor *DatabaseExecutor, query map[string]any) []int64 {
+	t.Helper()
+	response, err := executor.FindRecords(context.Background(), "posts", query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded OperationResponse
+	if err := json.Unmarshal(response, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Success {
+		t.Fatalf("Find failed: %s", decoded.Error)
+	}
+	var ids []int64
+	for _, row := range decoded.Data {
+		id, _ := row["id"].(float64)
+		ids = append(ids, int64(id))
+	}
+	return ids
+}
+
+func TestSoftDeleteFinds(t *testing.T) {
+	executor := newSoftDeleteExecutor(t)
+
+	tests := []struct {
+		name     string
+		query    map[string]any
+		expected int
+	}{
+		{name: "deleted rows are left out", expected: 2},
+		{name: "with conditions", query: map[string]any{"title": "c"}, expected: 0},
+		{name: "with deleted", query: map[string]any{WithDeletedKey: true}, expected: 3},
+		{name: "with deleted from a query string", query: map[string]any{WithDeletedKey: "true", "title": "c"}, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := postIDs(t, executor, tt.query); len(got) != tt.expected {
+				t.Errorf("Expected %d posts, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("delete sets deleted_at", func(t *testing.T) {
+		executor := newSoftDeleteExecutor(t)
+		if err := responseError(executor.DeleteRecord(ctx, "posts", 1, nil)); err != nil {
+			t.Fatal(err)
+		}
+		if got := postIDs(t, executor, nil); len(got) != 1 || got[0] != 2 {
+			t.Errorf("Expected only post 2 left, got %v", got)
+		}
+		if got := postIDs(t, executor, map[string]any{WithDeletedKey: true}); len(got) != 3 {
+			t.Errorf("Expected the deleted post to be kept, got %v", got)
+		}
+	})
+
+	t.Run("restore clears deleted_at", func(t *testing.T) {
+		executor := newSoftDeleteExecutor(t)
+		if err := responseError(executor.RestoreRecord(ctx, "posts", 3, nil)); err != nil {
+			t.Fatal(err)
+		}
+		if got := postIDs(t, executor, nil); len(got) != 3 {
+			t.Errorf("Expected the restored post back, got %v", got)
+		}
+	})
+
+	t.Run("hard delete removes the row", func(t *testing.T) {
+		executor := newSoftDeleteExecutor(t)
+		request := []byte(`{"operation": "delete", "table": "posts", "id": 3, "_hard": true}`)
+		if err := responseError(executor.ExecuteJSON(ctx, request)); err != nil {
+			t.Fatal(err)
+		}
+		if got := postIDs(t, executor, map[string]any{WithDeletedKey: true}); len(got) != 2 {
+			t.Errorf("Expected post 3 to be gone, got %v", got)
+		}
+	})
+
+	t.Run("delete where soft deletes matches", func(t *testing.T) {
+		executor := newSoftDeleteExecutor(t)
+		if err := responseError(executor.DeleteWhere(ctx, "posts", map[string]any{"title": "b"}, nil)); err != nil {
+			t.Fatal(err)
+		}
+		if got := postIDs(t, executor, map[string]any{WithDeletedKey: 1}); len(got) != 3 {
+			t.Errorf("Expected every post to be kept, got %v", got)
+		}
+	})
+
+	t.Run("tables without deleted_at delete rows", func(t *testing.T) {
+		executor, db := newSQLiteExecutor(t)
+		if _, err := db.Exec(ctx, "INSERT INTO items (id, name) VALUES (1, 'a')"); err != nil {
+			t.Fatal(err)
+		}
+		if err := executor.LoadSoftDeleteTables(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := responseError(executor.DeleteRecord(ctx, "items", 1, nil)); err != nil {
+			t.Fatal(err)
+		}
+		if count := countItems(t, db); count != 0 {
+			t.Errorf("Expected the item to be deleted, got %d rows", count)
+		}
+		if err := responseError(executor.RestoreRecord(ctx, "items", 1, nil)); err == nil {
+			t.Error("Expected restore to fail on a table without deleted_at")
+		}
+	})
+}
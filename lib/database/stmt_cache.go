@@ -0,0 +1,131 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"fulcrum/lib/database/interfaces"
+	"strings"
+	"sync"
+)
+
+// stmtCache is an LRU cache of prepared statements keyed by query text
+type stmtCache struct {
+	mu       sync.Mutex
+	preparer interfaces.Preparer
+	size     int
+	entries  map[string]*list.Element
+	order    *list.List // Most recently used at the front
+}
+
+// cachedStmt is a prepared statement and the requests currently using it
+type cachedStmt struct {
+	query   string
+	stmt    interfaces.Stmt
+	refs    int
+	evicted bool // Closed once the last user releases it
+}
+
+func newStmtCache(preparer interfaces.Preparer, size int) *stmtCache {
+	return &stmtCache{
+		preparer: preparer,
+		size:     size,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// cacheable reports whether query can be prepared as a single statement; templates
+// with several statements run as before
+func cacheable(query string) bool {
+	return !strings.Contains(strings.TrimRight(strings.TrimSpace(query), "; \t\n"), ";")
+}
+
+// acquire returns the prepared statement for query, preparing it on first use.
+// Callers must release the statement when they are done with it.
+func (c *stmtCache) acquire(ctx context.Context, query string) (*cachedStmt, error) {
+	if entry := c.lookup(query); entry != nil {
+		return entry, nil
+	}
+
+	stmt, err := c.preparer.Prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another request may have prepared the same query in the meantime
+	if elem, ok := c.entries[query]; ok {
+		stmt.Close()
+		c.order.MoveToFront(elem)
+		entry := elem.Value.(*cachedStmt)
+		entry.refs++
+		return entry, nil
+	}
+
+	entry := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	c.entries[query] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		c.evict(c.order.Back())
+	}
+	return entry, nil
+}
+
+// lookup returns the cached statement for query, marked as in use, or nil
+func (c *stmtCache) lookup(query string) *cachedStmt {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[query]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(elem)
+	entry := elem.Value.(*cachedStmt)
+	entry.refs++
+	return entry
+}
+
+// release marks a statement from acquire as no longer in use
+func (c *stmtCache) release(entry *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.refs--
+	if entry.evicted && entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// evict removes a statement from the cache, closing it unless it is still in use
+func (c *stmtCache) evict(elem *list.Element) error {
+	entry := c.order.Remove(elem).(*cachedStmt)
+	delete(c.entries, entry.query)
+	entry.evicted = true
+	if entry.refs == 0 {
+		return entry.stmt.Close()
+	}
+	return nil
+}
+
+// len returns the number of cached statements
+func (c *stmtCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// close evicts every statement, returning the first error from closing one
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for elem := c.order.Back(); elem != nil; elem = c.order.Back() {
+		if err := c.evict(elem); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"fulcrum/lib/database/interfaces"
+	"testing"
+)
+
+// countingDatabase counts the statements prepared on a real database
+type countingDatabase struct {
+	interfaces.Database
+	prepared []string
+	closed   int
+}
+
+func (c *countingDatabase) Prepare(ctx context.Context, query string) (interfaces.Stmt, error) {
+	c.prepared = append(c.prepared, query)
+	stmt, err := c.Database.(interfaces.Preparer).Prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &countingStmt{Stmt: stmt, db: c}, nil
+}
+
+type countingStmt struct {
+	interfaces.Stmt
+	db *countingDatabase
+}
+
+func (s *countingStmt) Close() error {
+	s.db.closed++
+	return s.Stmt.Close()
+}
+
+// newCachingExecutor returns an executor over SQLite with a statement cache of size
+func newCachingExecutor(t testing.TB, size int) (*DatabaseExecutor, *countingDatabase) {
+	t.Helper()
+	_, db := newSQLiteExecutor(t)
+	counting := &countingDatabase{Database: db}
+	executor := NewDatabaseExecutor(counting)
+	executor.EnableStatementCache(size)
+	return executor, counting
+}
+
+func TestStatementCacheReusesPreparedStatements(t *testing.T) {
+	executor, db := newCachingExecutor(t, 2)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		params := map[string]any{"name": fmt.Sprintf("item-%d", i)}
+		if err := responseError(executor.ExecuteSQL(ctx, "INSERT INTO items (name) VALUES (:name)", params, nil)); err != nil {
+			t.Fatalf("Insert %d failed: %v", i, err)
+		}
+		if err := responseError(executor.ExecuteSQL(ctx, "SELECT * FROM items WHERE name = :name", params, nil)); err != nil {
+			t.Fatalf("Select %d failed: %v", i, err)
+		}
+	}
+
+	if len(db.prepared) != 2 {
+		t.Errorf("Expected the insert and select to be prepared once each, got %d prepares: %v", len(db.prepared), db.prepared)
+	}
+	if count := countItems(t, db.Database); count != 3 {
+		t.Errorf("Expected 3 rows written through the prepared insert, got %d", count)
+	}
+
+	if err := executor.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if db.closed != 2 || executor.stmts.len() != 0 {
+		t.Errorf("Expected Close to close both statements, closed %d with %d cached", db.closed, executor.stmts.len())
+	}
+}
+
+func TestStatementCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	executor, db := newCachingExecutor(t, 2)
+	ctx := context.Background()
+
+	queries := []string{
+		"SELECT 1",
+		"SELECT 2",
+		"SELECT 1", // Now most recently used
+		"SELECT 3", // Evicts SELECT 2
+		"SELECT 1",
+		"SELECT 2", // Prepared again
+	}
+	for _, query := range queries {
+		if err := responseError(executor.ExecuteSQL(ctx, query, nil, nil)); err != nil {
+			t.Fatalf("%s failed: %v", query, err)
+		}
+	}
+
+	expected := []string{"SELECT 1", "SELECT 2", "SELECT 3", "SELECT 2"}
+	if fmt.Sprint(db.prepared) != fmt.Sprint(expected) {
+		t.Errorf("Expected prepares %v, got %v", expected, db.prepared)
+	}
+	if db.closed != 2 {
+		t.Errorf("Expected evicted statements to be closed, got %d closes", db.closed)
+	}
+}
+
+func TestStatementCacheBypass(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, executor *DatabaseExecutor) error
+	}{
+		{
+			name: "disabled",
+			run: func(ctx context.Context, executor *DatabaseExecutor) error {
+				executor.stmts = nil
+				return responseError(executor.ExecuteSQL(ctx, "SELECT 1", nil, nil))
+			},
+		},
+		{
+			name: "multiple statements",
+			run: func(ctx context.Context, executor *DatabaseExecutor) error {
+				return responseError(executor.ExecuteSQL(ctx, "INSERT INTO items (name) VALUES ('a'); INSERT INTO items (name) VALUES ('b');", nil, nil))
+			},
+		},
+		{
+			name: "inside a transaction",
+			run: func(ctx context.Context, executor *DatabaseExecutor) error {
+				return executor.WithTransaction(ctx, func(tx *DatabaseExecutor) error {
+					return responseError(tx.ExecuteSQL(ctx, "SELECT 1", nil, nil))
+				})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor, db := newCachingExecutor(t, 2)
+			if err := tt.run(context.Background(), executor); err != nil {
+				t.Fatal(err)
+			}
+			if len(db.prepared) != 0 {
+				t.Errorf("Expected no prepared statements, got %v", db.prepared)
+			}
+		})
+	}
+}
+
+func BenchmarkExecuteSQL(b *testing.B) {
+	for _, size := range []int{0, 16} {
+		b.Run(fmt.Sprintf("statement_cache_size=%d", size), func(b *testing.B) {
+			executor, _ := newCachingExecutor(b, size)
+			ctx := context.Background()
+			if _, err := executor.ExecuteSQL(ctx, "INSERT INTO items (name) VALUES ('a')", nil, nil); err != nil {
+				b.Fatal(err)
+			}
+			params := map[string]any{"name": "a"}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := executor.ExecuteSQL(ctx, "SELECT id, name FROM items WHERE name = :name", params, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
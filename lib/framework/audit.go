@@ -0,0 +1,58 @@
+package framework
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	"fulcrum/lib/auth"
+	"fulcrum/lib/database"
+	lang_adapters "fulcrum/lib/lang/adapters"
+	parser "fulcrum/lib/parser"
+)
+
+// requestIDHeader carries the id of a request, from a proxy or set by the framework
+const requestIDHeader = "X-Request-Id"
+
+// withAuditActor attributes the changes a request makes to audited tables to the
+// signed-in user and to the request's id, which is echoed in X-Request-Id. It is a
+// no-op unless fulcrum.yml lists tables under audit.
+func withAuditActor(appConfig *parser.AppConfig, next http.HandlerFunc) http.HandlerFunc {
+	if len(appConfig.Audit) == 0 {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		var actor string
+		if userID, ok := auth.CurrentUserID(r); ok {
+			actor = strconv.FormatInt(userID, 10)
+		}
+		next(w, r.WithContext(database.WithAuditActor(r.Context(), actor, requestID)))
+	}
+}
+
+// newRequestID returns a random id for a request that came without one
+func newRequestID() string {
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(raw)
+}
+
+// dashboardAudit lists the newest audit_log entries, of one table when given
+func dashboardAudit(ctx context.Context, frameworkServer *lang_adapters.FrameworkServer, table string) ([]database.AuditEntry, error) {
+	if frameworkServer == nil || frameworkServer.DbExecutor == nil {
+		return nil, errNoDatabase
+	}
+	ctx, cancel := context.WithTimeout(ctx, dashboardTimeout)
+	defer cancel()
+	return frameworkServer.DbExecutor.AuditEntries(ctx, database.AuditQuery{Table: table})
+}
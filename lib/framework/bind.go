@@ -0,0 +1,255 @@
+package framework
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fulcrum/lib/naming"
+	parser "fulcrum/lib/parser"
+)
+
+// ValidationError describes a request field that could not be bound to its model type
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// ValidationErrors is the error extractRequestData returns when binding fails
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return "invalid request data: " + strings.Join(messages, "; ")
+}
+
+// dateLayouts are the date and time formats accepted for date and datetime fields,
+// including what <input type="date"> and <input type="datetime-local"> submit
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", time.DateOnly}
+
+// BindModel returns the fields of data the model declares, converted to their model
+// types, and drops every other key so a request cannot set columns it was not meant
+// to, like is_admin. Empty values of non-string fields bind as nil.
+func BindModel(data map[string]any, model parser.Model) (map[string]any, []ValidationError) {
+	bound := make(map[string]any, len(model))
+	var errs []ValidationError
+
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		field, ok := model.GetField(name)
+		if !ok {
+			continue
+		}
+		var value any
+		var err error
+		if strings.EqualFold(field.Type, "image") {
+			value, err = bindImage(data[name], field)
+		} else {
+			value, err = coerceField(field.Type, data[name])
+		}
+		if err != nil {
+			errs = append(errs, ValidationError{Field: name, Message: err.Error()})
+			continue
+		}
+		bound[name] = value
+	}
+	return bound, errs
+}
+
+// coerceField converts a form or JSON value to a field type; a blank value of a
+// non-text field is nil
+func coerceField(fieldType string, value any) (any, error) {
+	if values, ok := value.([]string); ok {
+		if len(values) != 1 {
+			return nil, fmt.Errorf("must have a single value")
+		}
+		value = values[0]
+	}
+	if value == nil {
+		return nil, nil
+	}
+	s, isString := value.(string)
+	s = strings.TrimSpace(s)
+
+	switch strings.ToLower(fieldType) {
+	case "integer", "int", "bigint", "serial", "bigserial", "references":
+		var n int64
+		var err error
+		switch v := value.(type) {
+		case string:
+			if s == "" {
+				return nil, nil
+			}
+			n, err = strconv.ParseInt(s, 10, 64)
+		case float64:
+			n = int64(v)
+			if float64(n) != v {
+				err = strconv.ErrSyntax
+			}
+		default:
+			err = strconv.ErrSyntax
+		}
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return n, nil
+	case "decimal", "numeric", "float", "double":
+		switch v := value.(type) {
+		case string:
+			if s == "" {
+				return nil, nil
+			}
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f, nil
+			}
+		case float64:
+			return v, nil
+		}
+		return nil, fmt.Errorf("must be a number")
+	case "boolean", "bool":
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		if !isString {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		if s == "" {
+			return nil, nil
+		}
+		// Checked checkboxes submit "on"
+		if strings.EqualFold(s, "on") {
+			return true, nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return b, nil
+	case "date", "datetime", "timestamp":
+		if isString && s == "" {
+			return nil, nil
+		}
+		if isString {
+			for _, layout := range dateLayouts {
+				if _, err := time.Parse(layout, s); err == nil {
+					return s, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("must be a date")
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case *UploadedFile:
+		// Text columns store the path the file was saved under
+		return v, nil
+	case float64, bool:
+		return fmt.Sprint(v), nil
+	}
+	return nil, fmt.Errorf("must be text")
+}
+
+// isJSONRequest reports whether r's body is JSON
+func isJSONRequest(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "application/json"
+}
+
+// decodeJSONBody adds the fields of r's JSON object body to params
+func decodeJSONBody(r *http.Request, params map[string]any) error {
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	for k, v := range body {
+		if !reservedRequestKeys[k] {
+			params[k] = v
+		}
+	}
+	return nil
+}
+
+// domainModel returns the model a domain's records follow: the one named after the
+// domain's singular, or else the first declared
+func domainModel(domain *parser.DomainConfig) (parser.Model, bool) {
+	if domain == nil {
+		return nil, false
+	}
+	if model, ok := domain.GetModel(naming.Singularize(domain.Name)); ok {
+		return model, true
+	}
+	if len(domain.Models) == 0 || len(domain.Models[0]) == 0 {
+		return nil, false
+	}
+	names := make([]string, 0, len(domain.Models[0]))
+	for name := range domain.Models[0] {
+		names = append(names, name)
+	}
+	return domain.Models[0][slices.Min(names)], true
+}
+
+// systemFields are set by the database or the framework, so create and update routes
+// leave them out unless their permit list names them
+var systemFields = map[string]bool{"id": true, "created_at": true, "updated_at": true, tenantColumn: true}
+
+// permittedFields returns the request fields a create or update route accepts: the
+// route's permit list, or else the model's fields other than systemFields. It reports
+// false when the route has neither.
+func permittedFields(route parser.Route, model parser.Model) ([]string, bool) {
+	if route.Permit != nil {
+		return route.Permit, true
+	}
+	if model == nil {
+		return nil, false
+	}
+	var fields []string
+	for name := range model {
+		if !systemFields[name] {
+			fields = append(fields, name)
+		}
+	}
+	return fields, true
+}
+
+// permit returns the fields of params that are in fields
+func permit(params map[string]any, fields []string) map[string]any {
+	permitted := make(map[string]any, len(fields))
+	for _, field := range fields {
+		if value, ok := params[field]; ok {
+			permitted[field] = value
+		}
+	}
+	return permitted
+}
+
+// bindsModel reports whether a route creates or updates records, so its request data
+// is bound to the domain model
+func bindsModel(route parser.Route) bool {
+	switch path.Base(route.Link) {
+	case "create", "update":
+		return true
+	}
+	return route.Method == "PUT" || route.Method == "PATCH"
+}
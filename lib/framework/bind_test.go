@@ -0,0 +1,242 @@
+package framework
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	parser "fulcrum/lib/parser"
+)
+
+var bindTestModel = parser.Model{
+	"name":       {Type: "string"},
+	"bio":        {Type: "text"},
+	"age":        {Type: "integer"},
+	"score":      {Type: "decimal"},
+	"active":     {Type: "boolean"},
+	"born_on":    {Type: "date"},
+	"starts_at":  {Type: "datetime"},
+	"manager_id": {Type: "references"},
+}
+
+func TestBindModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     map[string]any
+		expected map[string]any
+		errs     []string
+	}{
+		{
+			name: "form values are converted",
+			data: map[string]any{"name": "Ada", "age": "36", "score": "9.5", "active": "on", "born_on": "1815-12-10", "starts_at": "2026-01-02T09:30", "manager_id": "7"},
+			expected: map[string]any{"name": "Ada", "age": int64(36), "score": 9.5, "active": true, "born_on": "1815-12-10",
+				"starts_at": "2026-01-02T09:30", "manager_id": int64(7)},
+		},
+		{
+			name:     "JSON values are converted",
+			data:     map[string]any{"name": "Ada", "age": float64(36), "score": float64(9), "active": false},
+			expected: map[string]any{"name": "Ada", "age": int64(36), "score": float64(9), "active": false},
+		},
+		{
+			name:     "blank values of non-text fields are nil",
+			data:     map[string]any{"bio": "", "age": "", "active": " ", "born_on": ""},
+			expected: map[string]any{"bio": "", "age": nil, "active": nil, "born_on": nil},
+		},
+		{
+			name:     "unknown keys are dropped",
+			data:     map[string]any{"name": "Ada", "nickname": "Countess", "_method": "POST"},
+			expected: map[string]any{"name": "Ada"},
+		},
+		{
+			name:     "mass assignment is filtered",
+			data:     map[string]any{"name": "Mallory", "is_admin": "true", "role": "admin", "id": "1"},
+			expected: map[string]any{"name": "Mallory"},
+		},
+		{
+			name:     "values that don't convert are reported",
+			data:     map[string]any{"name": "Ada", "age": "thirty", "score": "lots", "active": "maybe", "born_on": "yesterday", "manager_id": float64(1.5)},
+			expected: map[string]any{"name": "Ada"},
+			errs:     []string{"active must be true or false", "age must be an integer", "born_on must be a date", "manager_id must be an integer", "score must be a number"},
+		},
+		{
+			name:     "repeated values are reported",
+			data:     map[string]any{"age": []string{"1", "2"}},
+			expected: map[string]any{},
+			errs:     []string{"age must have a single value"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bound, errs := BindModel(tt.data, bindTestModel)
+			if !reflect.DeepEqual(bound, tt.expected) {
+				t.Errorf("Expected %#v, got %#v", tt.expected, bound)
+			}
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			if !reflect.DeepEqual(messages, tt.errs) {
+				t.Errorf("Expected errors %v, got %v", tt.errs, messages)
+			}
+		})
+	}
+}
+
+func TestExtractRequestDataBindsModel(t *testing.T) {
+	domain := &parser.DomainConfig{
+		Name:   "users",
+		Models: []parser.ModelDefinition{{"user": parser.Model{"name": {Type: "string"}, "age": {Type: "integer"}}}},
+	}
+	createRoute := parser.Route{Link: "/users/create", Method: "POST"}
+
+	form := func(values url.Values) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/users/create?is_admin=true", strings.NewReader(values.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+	jsonBody := func(body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/users/create", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return r
+	}
+
+	tests := []struct {
+		name      string
+		request   *http.Request
+		route     parser.Route
+		expected  map[string]any
+		dropped   []string
+		expectErr bool
+	}{
+		{
+			name:     "form",
+			request:  form(url.Values{"name": {"Mallory"}, "age": {"30"}, "is_admin": {"true"}}),
+			route:    createRoute,
+			expected: map[string]any{"name": "Mallory", "age": int64(30), "_method": "POST"},
+			dropped:  []string{"is_admin"},
+		},
+		{
+			name:     "JSON",
+			request:  jsonBody(`{"name": "Mallory", "age": 30, "is_admin": true}`),
+			route:    createRoute,
+			expected: map[string]any{"name": "Mallory", "age": int64(30)},
+			dropped:  []string{"is_admin"},
+		},
+		{
+			name:      "invalid field",
+			request:   form(url.Values{"age": {"thirty"}}),
+			route:     createRoute,
+			expectErr: true,
+		},
+		{
+			name:      "invalid JSON",
+			request:   jsonBody(`{"name":`),
+			route:     createRoute,
+			expectErr: true,
+		},
+		{
+			name:     "other actions keep their parameters",
+			request:  form(url.Values{"reason": {"spam"}}),
+			route:    parser.Route{Link: "/users/report", Method: "POST"},
+			expected: map[string]any{"reason": "spam", "is_admin": "true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := extractRequestData(tt.request, tt.route, domain)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %v", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, value := range tt.expected {
+				if data[key] != value {
+					t.Errorf("Expected %s=%#v, got %#v", key, value, data[key])
+				}
+			}
+			for _, key := range tt.dropped {
+				if _, ok := data[key]; ok {
+					t.Errorf("Expected %s to be dropped, got %#v", key, data[key])
+				}
+			}
+		})
+	}
+}
+
+func TestPermittedFields(t *testing.T) {
+	domain := &parser.DomainConfig{
+		Name: "users",
+		Models: []parser.ModelDefinition{{"user": parser.Model{
+			"id": {Type: "integer"}, "name": {Type: "string"}, "email": {Type: "string"},
+			"created_at": {Type: "datetime"}, "updated_at": {Type: "datetime"},
+		}}},
+	}
+	body := url.Values{"id": {"9"}, "name": {"Mallory"}, "email": {"m@example.com"}, "created_at": {"2000-01-01"}}
+
+	tests := []struct {
+		name     string
+		permit   []string
+		expected map[string]any
+		stripped []string
+	}{
+		{
+			name:     "model fields except system fields by default",
+			expected: map[string]any{"name": "Mallory", "email": "m@example.com"},
+			stripped: []string{"id", "created_at"},
+		},
+		{
+			name:     "permit list",
+			permit:   []string{"name"},
+			expected: map[string]any{"name": "Mallory"},
+			stripped: []string{"email", "id", "created_at"},
+		},
+		{
+			name:     "permit list naming a system field",
+			permit:   []string{"name", "created_at"},
+			expected: map[string]any{"name": "Mallory", "created_at": "2000-01-01"},
+			stripped: []string{"email", "id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/users/create", strings.NewReader(body.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			data, err := extractRequestData(r, parser.Route{Link: "/users/create", Method: "POST", Permit: tt.permit}, domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, value := range tt.expected {
+				if data[key] != value {
+					t.Errorf("Expected %s=%#v, got %#v", key, value, data[key])
+				}
+			}
+			for _, key := range tt.stripped {
+				if _, ok := data[key]; ok {
+					t.Errorf("Expected %s to be stripped, got %#v", key, data[key])
+				}
+			}
+		})
+	}
+
+	// Without a model the permit list still applies
+	r := httptest.NewRequest(http.MethodPost, "/notes/create", strings.NewReader("body=hi&pinned=true"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	data, err := extractRequestData(r, parser.Route{Link: "/notes/create", Method: "POST", Permit: []string{"body"}}, &parser.DomainConfig{Name: "notes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data["body"] != "hi" || data["pinned"] != nil {
+		t.Errorf("Expected only body to be permitted, got %v", data)
+	}
+}
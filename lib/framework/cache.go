@@ -0,0 +1,169 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"fulcrum/lib/auth"
+	"fulcrum/lib/cache"
+	"fulcrum/lib/metrics"
+	"fulcrum/lib/middleware"
+	parser "fulcrum/lib/parser"
+)
+
+// cacheInvalidateKey in handler result data lists domains or cache keys to invalidate
+const cacheInvalidateKey = "_cache_invalidate"
+
+// routeCache is the result cache as a request sees it, carried by the request context
+type routeCache struct {
+	store cache.Store
+	key   string // Set when the request's SQL results are cached
+	ttl   time.Duration
+	tag   string // The route's domain
+}
+
+// routeCacheContextKey holds a request's *routeCache
+type routeCacheContextKey struct{}
+
+// newCacheStore returns the store for cached routes, or nil when no route is cached
+func newCacheStore(appConfig *parser.AppConfig) cache.Store {
+	for _, domain := range appConfig.Domains {
+		for _, route := range domain.Logic.HTTP.Routes {
+			if route.Cache.Enabled() {
+				return cache.NewMemoryStore(appConfig.Server.Cache.MaxEntries)
+			}
+		}
+	}
+	return nil
+}
+
+// cacheConfig returns the cache settings of the group's routes
+func (g RouteGroup) cacheConfig() parser.RouteCacheConfig {
+	for _, route := range []*parser.Route{g.HTMLRoute, g.JSONRoute, g.XMLRoute, g.SQLRoute} {
+		if route != nil && route.Cache.Enabled() {
+			return route.Cache
+		}
+	}
+	return parser.RouteCacheConfig{}
+}
+
+// withCache caches the SQL results of GET routes with a cache: block, and after a
+// successful POST, PUT, PATCH or DELETE invalidates its domain's cached results
+func withCache(store cache.Store, group RouteGroup, next http.HandlerFunc) http.HandlerFunc {
+	if store == nil {
+		return next
+	}
+	config := group.cacheConfig()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCache := &routeCache{store: store, tag: group.Domain}
+		if r.Method == http.MethodGet && config.Enabled() {
+			requestCache.key = cacheKey(r, group, config)
+			requestCache.ttl = time.Duration(config.TTL) * time.Second
+		}
+
+		recorder := &middleware.StatusRecorder{ResponseWriter: w}
+		next(recorder, r.WithContext(context.WithValue(r.Context(), routeCacheContextKey{}, requestCache)))
+
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			if recorder.Status < http.StatusBadRequest {
+				invalidateCache(r.Context(), store, group.Domain)
+			}
+		}
+	}
+}
+
+// cacheKey identifies a route's results: the route, the requested path and the values
+// of the inputs it varies on. Results for a tenant are always kept apart.
+func cacheKey(r *http.Request, group RouteGroup, config parser.RouteCacheConfig) string {
+	parts := []string{group.Method + " " + group.Pattern, r.URL.Path}
+	query := r.URL.Query()
+	for _, input := range config.VaryOn {
+		if input == parser.CacheVaryUser {
+			// Anonymous requests share one entry that no signed-in user is served
+			userID, _ := auth.CurrentUserID(r)
+			parts = append(parts, fmt.Sprintf("user=%d", userID))
+			continue
+		}
+		parts = append(parts, input+"="+strings.Join(query[input], ","))
+	}
+	if tenant, ok := tenantFromContext(r.Context()); ok {
+		parts = append(parts, fmt.Sprintf("tenant=%v", tenant.id))
+	}
+	return strings.Join(parts, "|")
+}
+
+// cachedResult returns the cached results of the request in ctx, if it is cached
+func cachedResult(ctx context.Context) (*routeCache, []byte, bool) {
+	requestCache, ok := ctx.Value(routeCacheContextKey{}).(*routeCache)
+	if !ok || requestCache.key == "" {
+		return nil, nil, false
+	}
+	result, ok, err := requestCache.store.Get(ctx, requestCache.key)
+	if err != nil {
+		log.Printf("⚠️ Cache lookup failed for %s: %v", requestCache.key, err)
+	}
+
+	lookup := "miss"
+	if ok {
+		lookup = "hit"
+	}
+	metrics.CacheLookups.Inc(metrics.RouteFromContext(ctx), lookup)
+	return requestCache, result, ok
+}
+
+// queryCache returns ctx with the request's cached results scoped to one of the route's
+// named queries, so each query is cached apart from the route's own results
+func queryCache(ctx context.Context, name string) context.Context {
+	requestCache, ok := ctx.Value(routeCacheContextKey{}).(*routeCache)
+	if !ok || requestCache.key == "" {
+		return ctx
+	}
+	scoped := *requestCache
+	scoped.key += "|query=" + name
+	return context.WithValue(ctx, routeCacheContextKey{}, &scoped)
+}
+
+// save caches a request's results, logging failures since the response doesn't depend on them
+func (rc *routeCache) save(ctx context.Context, result []byte) {
+	if err := rc.store.Set(ctx, rc.key, result, rc.ttl, rc.tag); err != nil {
+		log.Printf("⚠️ Failed to cache %s: %v", rc.key, err)
+	}
+}
+
+// invalidateRequested invalidates the domains or keys a handler lists in _cache_invalidate
+func invalidateRequested(ctx context.Context, result any) {
+	requestCache, ok := ctx.Value(routeCacheContextKey{}).(*routeCache)
+	resultMap, isMap := result.(map[string]any)
+	if !ok || !isMap {
+		return
+	}
+
+	var names []string
+	switch requested := resultMap[cacheInvalidateKey].(type) {
+	case string:
+		names = []string{requested}
+	case []string:
+		names = requested
+	case []any:
+		for _, name := range requested {
+			names = append(names, fmt.Sprint(name))
+		}
+	}
+	if len(names) > 0 {
+		invalidateCache(ctx, requestCache.store, names...)
+	}
+}
+
+// invalidateCache removes cached results by domain or key
+func invalidateCache(ctx context.Context, store cache.Store, names ...string) {
+	log.Printf("🧹 Invalidating cached results: %s", strings.Join(names, ", "))
+	if err := store.Invalidate(ctx, names...); err != nil {
+		log.Printf("⚠️ Cache invalidation failed: %v", err)
+	}
+}
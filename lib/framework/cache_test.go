@@ -0,0 +1,167 @@
+package framework
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fulcrum/lib/auth"
+	"fulcrum/lib/cache"
+	"fulcrum/lib/database"
+	lang_adapters "fulcrum/lib/lang/adapters"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+// cachedNotesApp serves GET /notes, cached with the given settings, and POST /notes,
+// counting the statements the executor runs
+type cachedNotesApp struct {
+	mux     *http.ServeMux
+	queries atomic.Int32
+}
+
+func newCachedNotesApp(t *testing.T, cacheConfig parser.RouteCacheConfig, authDisabled bool) *cachedNotesApp {
+	t.Helper()
+	t.Setenv("FULCRUM_ENV", "production")
+
+	db := newSQLiteDB(t, "CREATE TABLE notes (id INTEGER PRIMARY KEY, title TEXT)")
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	app := &cachedNotesApp{}
+	executor := database.NewDatabaseExecutor(db)
+	executor.SetQueryObserver(func(context.Context, database.QueryEvent) { app.queries.Add(1) })
+
+	appConfig := &parser.AppConfig{
+		Domains: []parser.DomainConfig{{
+			Name: "notes",
+			Logic: parser.LogicConfig{HTTP: parser.HTTPConfig{Routes: []parser.Route{
+				{Method: "GET", Link: "/notes", Format: "json", ViewPath: write("get.json.hbs", ""), Cache: cacheConfig},
+				{Method: "GET", Link: "/notes", Format: "sql", ViewPath: write("get.sql.hbs", "SELECT title FROM notes"), Cache: cacheConfig},
+				{Method: "POST", Link: "/notes", Format: "json", ViewPath: write("post.json.hbs", "")},
+				{Method: "POST", Link: "/notes", Format: "sql", ViewPath: write("post.sql.hbs", "INSERT INTO notes (title) VALUES ('new')")},
+			}}},
+		}},
+		Auth:  parser.AuthConfig{Disabled: authDisabled},
+		Views: views.NewTemplateRenderer(),
+	}
+	frameworkServer := &lang_adapters.FrameworkServer{Db: db, DbExecutor: executor}
+	app.mux = CreateRouteDispatcher(appConfig, frameworkServer)
+	return app
+}
+
+// serve requests path as JSON, with cookie if set, and returns how many statements ran
+func (app *cachedNotesApp) serve(t *testing.T, method, path string, cookie *http.Cookie) int {
+	t.Helper()
+	before := app.queries.Load()
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Accept", "application/json")
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	app.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s %s: expected status 200, got %d: %s", method, path, rec.Code, rec.Body.String())
+	}
+	return int(app.queries.Load() - before)
+}
+
+func TestRouteCache(t *testing.T) {
+	t.Run("second read is served from the cache", func(t *testing.T) {
+		app := newCachedNotesApp(t, parser.RouteCacheConfig{TTL: 60}, true)
+		if queries := app.serve(t, "GET", "/notes", nil); queries != 1 {
+			t.Errorf("Expected the first read to query, ran %d statements", queries)
+		}
+		if queries := app.serve(t, "GET", "/notes", nil); queries != 0 {
+			t.Errorf("Expected the second read to skip the executor, ran %d statements", queries)
+		}
+	})
+
+	t.Run("uncached routes always query", func(t *testing.T) {
+		app := newCachedNotesApp(t, parser.RouteCacheConfig{}, true)
+		app.serve(t, "GET", "/notes", nil)
+		if queries := app.serve(t, "GET", "/notes", nil); queries != 1 {
+			t.Errorf("Expected every read to query, ran %d statements", queries)
+		}
+	})
+
+	t.Run("write to the domain invalidates", func(t *testing.T) {
+		app := newCachedNotesApp(t, parser.RouteCacheConfig{TTL: 60}, true)
+		app.serve(t, "GET", "/notes", nil)
+		app.serve(t, "POST", "/notes", nil)
+		if queries := app.serve(t, "GET", "/notes", nil); queries != 1 {
+			t.Errorf("Expected the read after a write to query, ran %d statements", queries)
+		}
+	})
+
+	t.Run("varies on listed query parameters", func(t *testing.T) {
+		app := newCachedNotesApp(t, parser.RouteCacheConfig{TTL: 60, VaryOn: []string{"page"}}, true)
+		app.serve(t, "GET", "/notes?page=1", nil)
+		if queries := app.serve(t, "GET", "/notes?page=2", nil); queries != 1 {
+			t.Errorf("Expected another page to query, ran %d statements", queries)
+		}
+		if queries := app.serve(t, "GET", "/notes?page=1&other=x", nil); queries != 0 {
+			t.Errorf("Expected unlisted parameters to share the entry, ran %d statements", queries)
+		}
+	})
+
+	t.Run("varies on user", func(t *testing.T) {
+		app := newCachedNotesApp(t, parser.RouteCacheConfig{TTL: 60, VaryOn: []string{parser.CacheVaryUser}}, false)
+		signIn := func(id float64) *http.Cookie {
+			cookie, err := auth.StartSession(context.Background(), auth.User{Username: "user", Id: id})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return cookie
+		}
+		ada, grace := signIn(1), signIn(2)
+
+		app.serve(t, "GET", "/notes", ada)
+		if queries := app.serve(t, "GET", "/notes", grace); queries != 1 {
+			t.Errorf("Expected another user's read to query, ran %d statements", queries)
+		}
+		if queries := app.serve(t, "GET", "/notes", ada); queries != 0 {
+			t.Errorf("Expected the same user's read to be cached, ran %d statements", queries)
+		}
+	})
+}
+
+func TestHandlerRequestsInvalidation(t *testing.T) {
+	RegisterHandler("notes", "index", func(ctx context.Context, sqlData any, requestData map[string]any) (any, error) {
+		return map[string]any{cacheInvalidateKey: []any{"notes", "GET /posts|/posts"}}, nil
+	})
+	defer UnregisterHandler("notes", "index")
+
+	ctx := context.Background()
+	store := cache.NewMemoryStore(10)
+	store.Set(ctx, "GET /notes|/notes", []byte("{}"), time.Minute, "notes")
+	store.Set(ctx, "GET /posts|/posts", []byte("{}"), time.Minute, "posts")
+	store.Set(ctx, "GET /posts|/posts/1", []byte("{}"), time.Minute, "posts")
+
+	requestCtx := context.WithValue(ctx, routeCacheContextKey{}, &routeCache{store: store})
+	result, handled, err := runDomainHandler(requestCtx, "notes", "index", nil, nil, nil)
+	if err != nil || !handled {
+		t.Fatalf("Expected the handler to run, got %v", err)
+	}
+
+	for key, expected := range map[string]bool{"GET /notes|/notes": false, "GET /posts|/posts": false, "GET /posts|/posts/1": true} {
+		if _, ok, _ := store.Get(ctx, key); ok != expected {
+			t.Errorf("Expected %s cached: %v", key, expected)
+		}
+	}
+	if cleaned := stripHandlerControlKeys(result).(map[string]any); len(cleaned) != 0 {
+		t.Errorf("Expected _cache_invalidate to be stripped, got %v", cleaned)
+	}
+}
@@ -0,0 +1,199 @@
+package framework
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"fulcrum/lib/database/migration"
+	lang_adapters "fulcrum/lib/lang/adapters"
+	"fulcrum/lib/middleware"
+	parser "fulcrum/lib/parser"
+)
+
+//go:embed dashboard
+var dashboardFS embed.FS
+
+// dashboardPages are the pages of the dev dashboard, each served at /_fulcrum/<name>
+var dashboardPages = []string{"routes", "templates", "domains", "processes", "requests", "migrations", "audit"}
+
+// dashboardTemplates holds each page parsed with the shared layout
+var dashboardTemplates = parseDashboardTemplates()
+
+// dashboardTimeout bounds the database queries of the migrations and audit pages
+const dashboardTimeout = 5 * time.Second
+
+func parseDashboardTemplates() map[string]*template.Template {
+	funcs := template.FuncMap{
+		"ms": func(d time.Duration) string {
+			return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+		},
+	}
+	templates := make(map[string]*template.Template)
+	for _, page := range append([]string{"index"}, dashboardPages...) {
+		templates[page] = template.Must(template.New("layout.html").Funcs(funcs).
+			ParseFS(dashboardFS, "dashboard/layout.html", "dashboard/"+page+".html"))
+	}
+	return templates
+}
+
+// dashboardView is what a dashboard page renders
+type dashboardView struct {
+	Page  string
+	Pages []string
+	Data  any
+	Error string
+}
+
+// dashboardRoute is a route table entry and whether its templates were preloaded
+type dashboardRoute struct {
+	RouteEntry
+	Preloaded bool
+}
+
+// dashboardTemplate is a template loaded in the renderer
+type dashboardTemplate struct {
+	Name string
+	File string
+}
+
+// dashboardDomain is a discovered domain with its models
+type dashboardDomain struct {
+	Name   string
+	Path   string
+	Routes int
+	Models []dashboardModel
+}
+
+type dashboardModel struct {
+	Name   string
+	Fields []dashboardField
+}
+
+type dashboardField struct {
+	Name string
+	Type string
+}
+
+// registerDashboard serves the dev dashboard under /_fulcrum; only local clients may view it
+func registerDashboard(mux *http.ServeMux, appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer, requests *RequestLog) {
+	pages := map[string]func(r *http.Request) (any, error){
+		"index":     func(*http.Request) (any, error) { return nil, nil },
+		"routes":    func(*http.Request) (any, error) { return dashboardRoutes(appConfig), nil },
+		"templates": func(*http.Request) (any, error) { return dashboardTemplatesLoaded(appConfig), nil },
+		"domains":   func(*http.Request) (any, error) { return dashboardDomains(appConfig), nil },
+		"processes": func(*http.Request) (any, error) { return dashboardProcesses(frameworkServer), nil },
+		"requests":  func(*http.Request) (any, error) { return requests.Recent(), nil },
+		"migrations": func(r *http.Request) (any, error) {
+			return dashboardMigrations(r.Context(), appConfig, frameworkServer)
+		},
+		"audit": func(r *http.Request) (any, error) {
+			return dashboardAudit(r.Context(), frameworkServer, r.URL.Query().Get("table"))
+		},
+	}
+
+	for page, data := range pages {
+		path := "GET /_fulcrum/" + page
+		if page == "index" {
+			path = "GET /_fulcrum/{$}"
+		}
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			serveDashboardPage(w, r, page, data)
+		})
+	}
+}
+
+// serveDashboardPage renders a dashboard page, showing an error from data on the page
+func serveDashboardPage(w http.ResponseWriter, r *http.Request, page string, data func(r *http.Request) (any, error)) {
+	if !middleware.IsLoopback(r) {
+		log.Printf("🚫 Rejected dashboard request from %s", r.RemoteAddr)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	view := dashboardView{Page: page, Pages: dashboardPages}
+	var err error
+	if view.Data, err = data(r); err != nil {
+		view.Error = err.Error()
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplates[page].Execute(w, view); err != nil {
+		log.Printf("❌ Dashboard page %s failed to render: %v", page, err)
+	}
+}
+
+// dashboardRoutes lists the route table, registered routes first
+func dashboardRoutes(appConfig *parser.AppConfig) []dashboardRoute {
+	table := BuildRouteTable(appConfig)
+	var routes []dashboardRoute
+	for _, entry := range slices.Concat(table.Routes, table.Skipped) {
+		preloaded := true
+		for _, route := range []*parser.Route{entry.group.HTMLRoute, entry.group.JSONRoute, entry.group.XMLRoute, entry.group.SQLRoute} {
+			if route != nil && route.TemplateName == "" {
+				preloaded = false
+			}
+		}
+		routes = append(routes, dashboardRoute{RouteEntry: entry, Preloaded: preloaded})
+	}
+	return routes
+}
+
+// dashboardTemplatesLoaded lists the renderer's templates by name
+func dashboardTemplatesLoaded(appConfig *parser.AppConfig) []dashboardTemplate {
+	if appConfig.Views == nil {
+		return nil
+	}
+	var templates []dashboardTemplate
+	for name, file := range appConfig.Views.Templates() {
+		templates = append(templates, dashboardTemplate{Name: name, File: file})
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates
+}
+
+// dashboardDomains lists the discovered domains and their models' fields
+func dashboardDomains(appConfig *parser.AppConfig) []dashboardDomain {
+	var domains []dashboardDomain
+	for _, domain := range appConfig.Domains {
+		entry := dashboardDomain{Name: domain.Name, Path: domain.Path, Routes: len(domain.Logic.HTTP.Routes)}
+		for _, definition := range domain.Models {
+			for name, model := range definition {
+				fields := make([]dashboardField, 0, len(model))
+				for fieldName, field := range model {
+					fields = append(fields, dashboardField{Name: fieldName, Type: field.Type})
+				}
+				sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+				entry.Models = append(entry.Models, dashboardModel{Name: name, Fields: fields})
+			}
+		}
+		sort.Slice(entry.Models, func(i, j int) bool { return entry.Models[i].Name < entry.Models[j].Name })
+		domains = append(domains, entry)
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].Name < domains[j].Name })
+	return domains
+}
+
+// dashboardProcesses returns the process manager's view of the handler processes
+func dashboardProcesses(frameworkServer *lang_adapters.FrameworkServer) map[string]any {
+	if frameworkServer == nil || frameworkServer.ProcessManager == nil {
+		return nil
+	}
+	return frameworkServer.ProcessManager.GetProcessInfo()
+}
+
+// dashboardMigrations lists the migrations that have not been applied
+func dashboardMigrations(ctx context.Context, appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) ([]migration.Migration, error) {
+	if frameworkServer == nil || frameworkServer.Db == nil {
+		return nil, errNoDatabase
+	}
+	ctx, cancel := context.WithTimeout(ctx, dashboardTimeout)
+	defer cancel()
+	return migration.NewRunner(frameworkServer.Db, appConfig.Path).PendingMigrations(ctx)
+}
@@ -0,0 +1,129 @@
+package framework
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fulcrum/lib/database"
+	lang_adapters "fulcrum/lib/lang/adapters"
+	"fulcrum/lib/views"
+)
+
+func TestDashboardPages(t *testing.T) {
+	t.Setenv("FULCRUM_ENV", "production")
+	appConfig := loadRoutesFixture(t)
+	appConfig.Mode = "develop"
+	renderer, err := views.SetupViewsFromConfig(appConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appConfig.Views = renderer
+	if err := appConfig.PreloadAndValidateRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	db := newSQLiteDB(t)
+	frameworkServer := &lang_adapters.FrameworkServer{
+		Db:             db,
+		DbExecutor:     database.NewDatabaseExecutor(db),
+		ProcessManager: lang_adapters.NewProcessManager(appConfig.Path, false),
+	}
+	mux := CreateRouteDispatcher(appConfig, frameworkServer)
+
+	serve := func(path, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	// A request for the requests page to list
+	serve("/users/42", "127.0.0.1:5000")
+
+	tests := []struct {
+		page     string
+		expected string
+	}{
+		{page: "", expected: "Fulcrum dev dashboard"},
+		{page: "routes", expected: "<code>/users/:users_id</code>"},
+		{page: "templates", expected: filepath.Join("users", "[users_id]", "get.html.hbs")},
+		{page: "domains", expected: "<h2>users</h2>"},
+		{page: "processes", expected: "Initialized: false"},
+		{page: "requests", expected: "GET <code>/users/42</code>"},
+		{page: "migrations", expected: "create_users"},
+		{page: "audit", expected: "<h1>Audit log</h1>"},
+	}
+
+	for _, tt := range tests {
+		t.Run("/_fulcrum/"+tt.page, func(t *testing.T) {
+			rec := serve("/_fulcrum/"+tt.page, "127.0.0.1:5000")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.expected) {
+				t.Errorf("Expected %q in:\n%s", tt.expected, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("rejects remote clients", func(t *testing.T) {
+		if rec := serve("/_fulcrum/routes", "10.0.0.8:5000"); rec.Code != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", rec.Code)
+		}
+	})
+}
+
+func TestDashboardDisabledOutsideDevMode(t *testing.T) {
+	t.Setenv("FULCRUM_ENV", "production")
+	appConfig := loadRoutesFixture(t)
+	mux := CreateRouteDispatcher(appConfig, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/_fulcrum/routes", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if strings.Contains(rec.Body.String(), "<h1>Routes</h1>") {
+		t.Errorf("Expected no dashboard outside dev mode, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRequestLogKeepsNewest(t *testing.T) {
+	requests := NewRequestLog(3)
+	for i, path := range []string{"/a", "/b", "/c", "/d"} {
+		requests.Add(RequestLogEntry{Path: path, Duration: time.Duration(i)})
+	}
+
+	var paths []string
+	for _, entry := range requests.Recent() {
+		paths = append(paths, entry.Path)
+	}
+	if got := strings.Join(paths, ","); got != "/d,/c,/b" {
+		t.Errorf("Expected /d,/c,/b, got %s", got)
+	}
+}
+
+func TestLogRequestsTimesStages(t *testing.T) {
+	requests := NewRequestLog(10)
+	handler := logRequests(requests, "/users", func(w http.ResponseWriter, r *http.Request) {
+		timeStage(r.Context(), StageSQL)()
+		timeStage(r.Context(), StageRender)()
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users?x=1", nil))
+
+	recent := requests.Recent()
+	if len(recent) != 1 {
+		t.Fatalf("Expected one logged request, got %d", len(recent))
+	}
+	entry := recent[0]
+	if entry.Method != http.MethodPost || entry.Path != "/users?x=1" || entry.Pattern != "/users" || entry.Status != http.StatusCreated {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+	if len(entry.Stages) != 2 || entry.Stages[0].Name != StageSQL || entry.Stages[1].Name != StageRender {
+		t.Errorf("Expected sql and render stages, got %+v", entry.Stages)
+	}
+}
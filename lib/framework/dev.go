@@ -0,0 +1,66 @@
+package framework
+
+import (
+	"fmt"
+	parser "fulcrum/lib/parser"
+	"log"
+	"net/http"
+	"time"
+
+	lang_adapters "fulcrum/lib/lang/adapters"
+	"fulcrum/lib/middleware"
+)
+
+// registerDevRoutes adds development-only tooling endpoints and the dashboard under
+// /_fulcrum; requests are the recent requests the dashboard lists
+func registerDevRoutes(mux *http.ServeMux, appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer, requests *RequestLog) {
+	if !appConfig.IsDevelopment() {
+		return
+	}
+
+	registerDashboard(mux, appConfig, frameworkServer, requests)
+
+	mux.HandleFunc("POST /_fulcrum/handlers/restart", func(w http.ResponseWriter, r *http.Request) {
+		handleHandlerRestart(w, r, frameworkServer)
+	})
+}
+
+// handleHandlerRestart restarts just the Node handler process for editor tooling
+func handleHandlerRestart(w http.ResponseWriter, r *http.Request, frameworkServer *lang_adapters.FrameworkServer) {
+	if !middleware.IsLoopback(r) {
+		log.Printf("🚫 Rejected handler restart from %s", r.RemoteAddr)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	if frameworkServer == nil || frameworkServer.ProcessManager == nil {
+		http.Error(w, "Handler service not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	start := time.Now()
+	if err := frameworkServer.ProcessManager.RestartHandlerService(); err != nil {
+		log.Printf("❌ Handler restart failed: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprintf(w, "Handlers restarted in %s\n", time.Since(start).Round(time.Millisecond))
+}
+
+// startHandlerWatcher restarts the handler service when handler files change in dev mode
+func startHandlerWatcher(appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) *lang_adapters.HandlerWatcher {
+	if !appConfig.IsDevelopment() || frameworkServer.ProcessManager == nil || !frameworkServer.ProcessManager.IsHandlerServiceRunning() {
+		return nil
+	}
+
+	config := frameworkServer.ProcessManager.GetHandlerConfig()
+	if config.HandlersPath == "" {
+		return nil
+	}
+
+	watcher := lang_adapters.NewHandlerWatcher(config.HandlersPath, frameworkServer.ProcessManager.RestartHandlerService)
+	watcher.Start()
+	return watcher
+}
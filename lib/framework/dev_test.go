@@ -0,0 +1,39 @@
+package framework
+
+import (
+	parser "fulcrum/lib/parser"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandlerRestartEndpoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		remoteAddr string
+		expected   int
+	}{
+		{name: "not registered outside dev mode", mode: "", remoteAddr: "127.0.0.1:5000", expected: http.StatusNotFound},
+		{name: "rejects remote clients", mode: "develop", remoteAddr: "10.0.0.8:5000", expected: http.StatusForbidden},
+		{name: "no handler service", mode: "develop", remoteAddr: "127.0.0.1:5000", expected: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("FULCRUM_ENV", "production")
+
+			mux := http.NewServeMux()
+			registerDevRoutes(mux, &parser.AppConfig{Mode: tt.mode}, nil, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/_fulcrum/handlers/restart", nil)
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, rec.Code)
+			}
+		})
+	}
+}
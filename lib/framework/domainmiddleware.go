@@ -0,0 +1,185 @@
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fulcrum/lib/auth"
+	"fulcrum/lib/middleware"
+	parser "fulcrum/lib/parser"
+)
+
+// MiddlewareFactory builds a middleware from the arguments that follow its name in a
+// domain's middleware list, e.g. ["100", "60"] for rate_limit:100:60
+type MiddlewareFactory func(args []string) (middleware.Middleware, error)
+
+var (
+	middlewareMu        sync.RWMutex
+	middlewareFactories = map[string]MiddlewareFactory{
+		"auth":       requireAuthMiddleware,
+		"rate_limit": domainRateLimitMiddleware,
+	}
+)
+
+// RegisterMiddleware makes a middleware available to the middleware lists of domains'
+// fulcrum.yml by name, e.g. an admin check; a factory registered under the same name
+// is replaced. Register before serving.
+func RegisterMiddleware(name string, factory MiddlewareFactory) {
+	middlewareMu.Lock()
+	defer middlewareMu.Unlock()
+	middlewareFactories[name] = factory
+}
+
+// registeredMiddleware returns the names domains can list, sorted
+func registeredMiddleware() []string {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+	names := make([]string, 0, len(middlewareFactories))
+	for name := range middlewareFactories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// domainMiddleware chains a domain's middleware list, the first entry outermost; it is
+// nil when the domain lists none
+func domainMiddleware(domain *parser.DomainConfig) (middleware.Middleware, error) {
+	var chain []middleware.Middleware
+	for _, spec := range domain.Middleware {
+		name, rest, hasArgs := strings.Cut(spec, ":")
+		var args []string
+		if hasArgs {
+			args = strings.Split(rest, ":")
+		}
+
+		middlewareMu.RLock()
+		factory, ok := middlewareFactories[name]
+		middlewareMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("domains/%s: unknown middleware %q (registered: %s)", domain.Name, name, strings.Join(registeredMiddleware(), ", "))
+		}
+		built, err := factory(args)
+		if err != nil {
+			return nil, fmt.Errorf("domains/%s: middleware %q: %w", domain.Name, spec, err)
+		}
+		chain = append(chain, built)
+	}
+
+	if len(chain) == 0 {
+		return nil, nil
+	}
+	return func(next http.Handler) http.Handler {
+		for i := len(chain) - 1; i >= 0; i-- {
+			next = chain[i](next)
+		}
+		return next
+	}, nil
+}
+
+// validateDomainMiddleware reports every domain whose middleware list cannot be built
+func validateDomainMiddleware(appConfig *parser.AppConfig) error {
+	var errs []error
+	for i := range appConfig.Domains {
+		if _, err := domainMiddleware(&appConfig.Domains[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// domainMiddlewareChains builds each domain's middleware once, so its routes share
+// state such as a rate limit. A domain whose list cannot be built refuses requests
+// rather than serving its routes without the middleware.
+func domainMiddlewareChains(appConfig *parser.AppConfig) map[string]middleware.Middleware {
+	chains := make(map[string]middleware.Middleware)
+	for i := range appConfig.Domains {
+		domain := &appConfig.Domains[i]
+		chain, err := domainMiddleware(domain)
+		if err != nil {
+			log.Printf("❌ Refusing requests to %s: %v", domain.Name, err)
+			chain = refuseRequests
+		}
+		if chain != nil {
+			chains[domain.Name] = chain
+		}
+	}
+	return chains
+}
+
+// withDomainMiddleware wraps next in its domain's middleware, if it has any
+func withDomainMiddleware(chains map[string]middleware.Middleware, domain string, next http.HandlerFunc) http.HandlerFunc {
+	chain, ok := chains[domain]
+	if !ok {
+		return next
+	}
+	return chain(next).ServeHTTP
+}
+
+// refuseRequests stands in for middleware that could not be built
+func refuseRequests(http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	})
+}
+
+// requireAuthMiddleware lets only signed-in users through, even with auth.disabled set
+// app-wide. Visitors are sent to the login page, or get a 401 when they asked for JSON.
+func requireAuthMiddleware(args []string) (middleware.Middleware, error) {
+	if len(args) > 0 {
+		return nil, fmt.Errorf("takes no arguments")
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if auth.IsAuthenticated(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if determineRequestedFormat(r) == "json" {
+				log.Printf("🔒 Rejected unauthenticated request: %s %s", r.Method, r.URL.Path)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			log.Printf("🔍 Request: %s %s has been redirected to login", r.Method, r.URL.Path)
+			http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		})
+	}, nil
+}
+
+// domainRateLimitMiddleware limits a domain's routes to rate_limit:requests[:window_seconds[:by]]
+// requests, by ip (the default) or user, per window (a minute by default)
+func domainRateLimitMiddleware(args []string) (middleware.Middleware, error) {
+	if len(args) == 0 || len(args) > 3 {
+		return nil, fmt.Errorf("expected rate_limit:requests[:window_seconds[:by]]")
+	}
+	requests, err := strconv.Atoi(args[0])
+	if err != nil || requests <= 0 {
+		return nil, fmt.Errorf("requests must be a positive number, got %q", args[0])
+	}
+	window := time.Minute
+	if len(args) > 1 {
+		seconds, err := strconv.Atoi(args[1])
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("window_seconds must be a positive number, got %q", args[1])
+		}
+		window = time.Duration(seconds) * time.Second
+	}
+	keyFn := middleware.ClientIP
+	if len(args) > 2 {
+		switch args[2] {
+		case "ip":
+		case "user":
+			keyFn = auth.RateLimitKey
+		default:
+			return nil, fmt.Errorf("unknown rate limit key %q (use ip or user)", args[2])
+		}
+	}
+	return middleware.RateLimitMiddleware(requests, window, keyFn), nil
+}
@@ -0,0 +1,140 @@
+package framework
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fulcrum/lib/auth"
+	"fulcrum/lib/middleware"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+// newMiddlewareApp serves GET /<domain>/page for each domain, with login disabled app-wide
+func newMiddlewareApp(t *testing.T, domains map[string][]string) *http.ServeMux {
+	t.Helper()
+	viewPath := filepath.Join(t.TempDir(), "get.html.hbs")
+	if err := os.WriteFile(viewPath, []byte("<p>ok</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	appConfig := &parser.AppConfig{Auth: parser.AuthConfig{Disabled: true}, Views: views.NewTemplateRenderer()}
+	for name, chain := range domains {
+		route := parser.Route{Link: "/" + name + "/page", Method: "GET", Format: "html", ViewPath: viewPath}
+		appConfig.Domains = append(appConfig.Domains, parser.DomainConfig{
+			Name:       name,
+			Middleware: chain,
+			Logic:      parser.LogicConfig{HTTP: parser.HTTPConfig{Routes: []parser.Route{route}}},
+		})
+	}
+	return CreateRouteDispatcher(appConfig, nil)
+}
+
+func TestDomainMiddleware(t *testing.T) {
+	RegisterMiddleware("admin", func(args []string) (middleware.Middleware, error) {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Role") != "admin" {
+					http.Error(w, "Forbidden", http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		}, nil
+	})
+
+	mux := newMiddlewareApp(t, map[string][]string{
+		"api":    {"rate_limit:2:60"},
+		"admin":  {"auth", "admin"},
+		"public": nil,
+		"broken": {"rate_limit:fast"},
+	})
+	cookie, err := auth.StartSession(context.Background(), auth.User{Username: "ada", Id: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(path string, prepare func(r *http.Request)) int {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if prepare != nil {
+			prepare(r)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, r)
+		return rec.Code
+	}
+	signedIn := func(role string) func(r *http.Request) {
+		return func(r *http.Request) {
+			r.AddCookie(cookie)
+			r.Header.Set("X-Role", role)
+		}
+	}
+
+	t.Run("rate limit is shared by the domain's routes", func(t *testing.T) {
+		for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+			if got := get("/api/page", nil); got != want {
+				t.Fatalf("Request %d: expected status %d, got %d", i+1, want, got)
+			}
+		}
+		if got := get("/public/page", nil); got != http.StatusOK {
+			t.Errorf("Expected other domains to be unlimited, got %d", got)
+		}
+	})
+
+	t.Run("chain runs in order", func(t *testing.T) {
+		if got := get("/admin/page", nil); got != http.StatusSeeOther {
+			t.Errorf("Expected visitors to be sent to login, got %d", got)
+		}
+		if got := get("/admin/page?format=json", nil); got != http.StatusUnauthorized {
+			t.Errorf("Expected JSON visitors to get 401, got %d", got)
+		}
+		if got := get("/admin/page", signedIn("editor")); got != http.StatusForbidden {
+			t.Errorf("Expected a registered middleware to reject non-admins, got %d", got)
+		}
+		if got := get("/admin/page", signedIn("admin")); got != http.StatusOK {
+			t.Errorf("Expected admins through, got %d", got)
+		}
+	})
+
+	t.Run("invalid middleware refuses requests", func(t *testing.T) {
+		if got := get("/broken/page", nil); got != http.StatusInternalServerError {
+			t.Errorf("Expected a misconfigured domain to refuse requests, got %d", got)
+		}
+	})
+}
+
+func TestValidateDomainMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		chain   []string
+		wantErr string
+	}{
+		{name: "valid", chain: []string{"auth", "rate_limit:100", "rate_limit:10:1:user"}},
+		{name: "unknown", chain: []string{"sudo"}, wantErr: `unknown middleware "sudo"`},
+		{name: "auth takes no arguments", chain: []string{"auth:admin"}, wantErr: "takes no arguments"},
+		{name: "missing requests", chain: []string{"rate_limit"}, wantErr: "expected rate_limit:requests"},
+		{name: "bad window", chain: []string{"rate_limit:10:0"}, wantErr: "window_seconds must be a positive number"},
+		{name: "bad key", chain: []string{"rate_limit:10:60:tenant"}, wantErr: `unknown rate limit key "tenant"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appConfig := &parser.AppConfig{Domains: []parser.DomainConfig{{Name: "api", Middleware: tt.chain}}}
+			err := validateDomainMiddleware(appConfig)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "domains/api") {
+				t.Fatalf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
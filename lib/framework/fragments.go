@@ -0,0 +1,79 @@
+package framework
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+// fragmentsKey in handler result data picks which of the route's htmx.yaml fragments an
+// HTMX response includes, e.g. {"_fragments": ["row", "counter"]}
+const fragmentsKey = "_fragments"
+
+// openingTag matches the start of a fragment's first element, e.g. "<span" of
+// <span id="counter">
+var openingTag = regexp.MustCompile(`<[a-zA-Z][a-zA-Z0-9-]*`)
+
+// selectFragments returns the files of the fragments an HTMX response to route appends:
+// those the handler picked with _fragments, in its order, or else every fragment its
+// htmx.yaml lists
+func selectFragments(route *parser.Route, data any) []string {
+	names := route.HTMXFragments
+	if dataMap, ok := data.(map[string]any); ok {
+		switch picked := dataMap[fragmentsKey].(type) {
+		case []string:
+			names = picked
+		case []any:
+			names = make([]string, 0, len(picked))
+			for _, name := range picked {
+				names = append(names, fmt.Sprint(name))
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		if !slices.Contains(route.HTMXFragments, name) {
+			log.Printf("⚠️ Skipping fragment %s, which htmx.yaml of %s %s does not list", name, route.Method, route.Link)
+			continue
+		}
+		paths = append(paths, route.HTMXFragmentPath(name))
+	}
+	return paths
+}
+
+// appendFragments renders each fragment after content, marked to be swapped out of band
+// so htmx places it by its id rather than into the request's target
+func appendFragments(content string, fragments []string, data any, renderer views.Renderer) (string, error) {
+	var response strings.Builder
+	response.WriteString(content)
+	for _, path := range fragments {
+		fragment, err := renderRouteTemplate(path, data, renderer)
+		if err != nil {
+			return "", fmt.Errorf("failed to render fragment %s: %w", filepath.Base(path), err)
+		}
+		response.WriteString("\n")
+		response.WriteString(markOutOfBand(strings.TrimSpace(fragment)))
+	}
+	return response.String(), nil
+}
+
+// markOutOfBand adds hx-swap-oob="true" to a fragment's first element, unless it already
+// sets how it is swapped, e.g. hx-swap-oob="beforeend:#rows"
+func markOutOfBand(fragment string) string {
+	start := openingTag.FindStringIndex(fragment)
+	if start == nil {
+		log.Printf("⚠️ Fragment has no element to swap out of band: %q", fragment)
+		return fragment
+	}
+	if end := strings.IndexByte(fragment[start[1]:], '>'); end >= 0 && strings.Contains(fragment[start[1]:start[1]+end], "hx-swap-oob") {
+		return fragment
+	}
+	return fragment[:start[1]] + ` hx-swap-oob="true"` + fragment[start[1]:]
+}
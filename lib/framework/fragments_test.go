@@ -0,0 +1,107 @@
+package framework
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lang_adapters "fulcrum/lib/lang/adapters"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+func TestHTMXFragments(t *testing.T) {
+	t.Setenv("FULCRUM_ENV", "production")
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"get.html.hbs":      `<tr id="task-1">done</tr>`,
+		"counter.htmx.hbs":  `<span id="counter">2 left</span>`,
+		"flash.htmx.hbs":    "\n<!-- notice -->\n<div id=\"flash\" class=\"notice\">Saved</div>\n",
+		"activity.htmx.hbs": `<ul id="activity" hx-swap-oob="afterbegin"><li>done</li></ul>`,
+	}
+	for file, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	htmlRoute := parser.Route{
+		Link:          "/tasks/done",
+		Method:        "GET",
+		Format:        "html",
+		ViewPath:      filepath.Join(dir, "get.html.hbs"),
+		HTMXFragments: []string{"counter", "flash", "activity"},
+	}
+	group := RouteGroup{Pattern: htmlRoute.Link, Method: "GET", Domain: "tasks", HTMLRoute: &htmlRoute}
+	appConfig := &parser.AppConfig{Views: views.NewTemplateRenderer()}
+
+	serve := func(htmx bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/tasks/done", nil)
+		if htmx {
+			req.Header.Set("HX-Request", "true")
+		}
+		rec := httptest.NewRecorder()
+		handleHTMLRouteWithProcessManager(rec, req, group, appConfig, &lang_adapters.FrameworkServer{})
+		return rec
+	}
+
+	t.Run("htmx requests get every fragment out of band", func(t *testing.T) {
+		rec := serve(true)
+		expected := `<tr id="task-1">done</tr>` + "\n" +
+			`<span hx-swap-oob="true" id="counter">2 left</span>` + "\n" +
+			`<!-- notice -->` + "\n" + `<div hx-swap-oob="true" id="flash" class="notice">Saved</div>` + "\n" +
+			`<ul id="activity" hx-swap-oob="afterbegin"><li>done</li></ul>`
+		if rec.Code != http.StatusOK || rec.Body.String() != expected {
+			t.Errorf("Expected the page and its fragments:\n%s\ngot %d:\n%s", expected, rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("handlers pick the fragments", func(t *testing.T) {
+		RegisterHandler("tasks", "done", func(ctx context.Context, sqlData any, requestData map[string]any) (any, error) {
+			return map[string]any{"_fragments": []any{"counter", "missing"}, "htmx_trigger": "taskDone"}, nil
+		})
+		t.Cleanup(func() { UnregisterHandler("tasks", "done") })
+
+		rec := serve(true)
+		expected := `<tr id="task-1">done</tr>` + "\n" + `<span hx-swap-oob="true" id="counter">2 left</span>`
+		if rec.Body.String() != expected {
+			t.Errorf("Expected only the counter fragment:\n%s\ngot:\n%s", expected, rec.Body.String())
+		}
+		if trigger := rec.Header().Get("HX-Trigger"); trigger != "taskDone" {
+			t.Errorf("Expected the handler's trigger header alongside, got %q", trigger)
+		}
+	})
+
+	t.Run("other requests are unaffected", func(t *testing.T) {
+		rec := serve(false)
+		if rec.Body.String() != `<tr id="task-1">done</tr>` {
+			t.Errorf("Expected the page alone, got %s", rec.Body.String())
+		}
+	})
+}
+
+func TestMarkOutOfBand(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		expected string
+	}{
+		{name: "first element", fragment: `<p id="count">1</p><p>2</p>`, expected: `<p hx-swap-oob="true" id="count">1</p><p>2</p>`},
+		{name: "after a comment", fragment: `<!-- c --><td id="cell">1</td>`, expected: `<!-- c --><td hx-swap-oob="true" id="cell">1</td>`},
+		{name: "own swap kept", fragment: `<tbody id="rows" hx-swap-oob="beforeend"><tr></tr></tbody>`, expected: `<tbody id="rows" hx-swap-oob="beforeend"><tr></tr></tbody>`},
+		{name: "a later swap is not its own", fragment: `<div id="a"><p hx-swap-oob="true"></p></div>`, expected: `<div hx-swap-oob="true" id="a"><p hx-swap-oob="true"></p></div>`},
+		{name: "no element", fragment: "just text", expected: "just text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markOutOfBand(tt.fragment); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	parser "fulcrum/lib/parser"
+	"log"
+	"sync"
+
+	lang_adapters "fulcrum/lib/lang/adapters"
+)
+
+// HandlerFunc is a Go-native route handler. It receives the SQL results for the route
+// (or the request data when the route has no SQL) and returns the data to render.
+// Returning a map with a "_redirect" entry ({"url": ..., "status": ...}) follows the
+// same convention as the JavaScript handlers.
+type HandlerFunc func(ctx context.Context, sqlData any, requestData map[string]any) (any, error)
+
+var (
+	handlerRegistry = make(map[string]HandlerFunc)
+	handlerMutex    sync.RWMutex
+)
+
+// handlerKey builds the registry key for a domain action
+func handlerKey(domain, action string) string {
+	return domain + "." + action
+}
+
+// RegisterHandler registers a Go handler for a domain action. Actions use the same
+// naming as the JavaScript handlers, e.g. "index", "{user_id}" or "{user_id}.edit".
+func RegisterHandler(domain, action string, fn HandlerFunc) {
+	handlerMutex.Lock()
+	defer handlerMutex.Unlock()
+
+	if _, exists := handlerRegistry[handlerKey(domain, action)]; exists {
+		log.Printf("⚠️ Replacing Go handler for %s.%s", domain, action)
+	}
+	handlerRegistry[handlerKey(domain, action)] = fn
+}
+
+// UnregisterHandler removes the Go handler registered for a domain action, so the
+// JavaScript handler service handles it again
+func UnregisterHandler(domain, action string) {
+	handlerMutex.Lock()
+	defer handlerMutex.Unlock()
+
+	delete(handlerRegistry, handlerKey(domain, action))
+}
+
+// lookupHandler returns the Go handler registered for a domain action
+func lookupHandler(domain, action string) (HandlerFunc, bool) {
+	handlerMutex.RLock()
+	defer handlerMutex.RUnlock()
+
+	fn, exists := handlerRegistry[handlerKey(domain, action)]
+	return fn, exists
+}
+
+// runDomainHandler executes the handler for a domain action, preferring a registered
+// Go handler and falling back to the JavaScript handler service
+func runDomainHandler(ctx context.Context, domain, action string, templateData any, requestData map[string]any, frameworkServer *lang_adapters.FrameworkServer) (any, bool, error) {
+	if fn, ok := lookupHandler(domain, action); ok {
+		log.Printf("Executing Go handler: %s.%s", domain, action)
+		defer timeStage(ctx, StageHandler)()
+		result, err := fn(ctx, templateData, requestData)
+		if err == nil {
+			invalidateRequested(ctx, result)
+		}
+		return result, true, err
+	}
+
+	if frameworkServer == nil || frameworkServer.ProcessManager == nil || !frameworkServer.ProcessManager.IsHandlerServiceRunning() {
+		return nil, false, nil
+	}
+
+	log.Printf("Executing handler: %s.%s", domain, action)
+	defer timeStage(ctx, StageHandler)()
+
+	// Convert htmx struct to map for protobuf compatibility
+	safeTemplateData := convertHtmxStructToMap(templateData)
+	safeRequestData := convertHtmxStructToMap(requestData).(map[string]any)
+
+	result, err := frameworkServer.ProcessManager.ExecuteHandlerWithContext(ctx, domain, action, safeTemplateData, safeRequestData)
+	if err == nil {
+		invalidateRequested(ctx, result)
+	}
+	return result, true, err
+}
+
+// RunWithHandlers runs the setup hook so a project can register its Go handlers,
+// then starts the HTTP and gRPC servers
+func RunWithHandlers(appConfig *parser.AppConfig, setup func() error) error {
+	if setup != nil {
+		if err := setup(); err != nil {
+			return fmt.Errorf("handler setup failed: %w", err)
+		}
+	}
+
+	StartBothServersWithProcessManager(appConfig)
+	return nil
+}
@@ -0,0 +1,463 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"fulcrum/handler"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lang_adapters "fulcrum/lib/lang/adapters"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestGoHandlerTransformsSQLRows(t *testing.T) {
+	RegisterHandler("users", "index", func(ctx context.Context, sqlData any, requestData map[string]any) (any, error) {
+		rows, ok := sqlData.([]map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unexpected sql data: %T", sqlData)
+		}
+
+		users := make([]map[string]any, 0, len(rows))
+		for _, row := range rows {
+			users = append(users, map[string]any{
+				"id":           row["id"],
+				"display_name": fmt.Sprintf("%s <%s>", row["name"], row["email"]),
+			})
+		}
+		return map[string]any{"users": users, "count": len(users)}, nil
+	})
+	t.Cleanup(func() { UnregisterHandler("users", "index") })
+
+	sqlRows := []map[string]any{
+		{"id": 1, "name": "Ada", "email": "ada@example.com"},
+		{"id": 2, "name": "Linus", "email": "linus@example.com"},
+	}
+
+	result, handled, err := runDomainHandler(context.Background(), "users", "index", sqlRows, map[string]any{}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !handled {
+		t.Fatal("Expected Go handler to handle the request")
+	}
+
+	data, ok := result.(map[string]any)
+	if !ok {
+		t.Fatalf("Expected map result, got %T", result)
+	}
+	if data["count"] != 2 {
+		t.Errorf("Expected count 2, got %v", data["count"])
+	}
+
+	users := data["users"].([]map[string]any)
+	if users[0]["display_name"] != "Ada <ada@example.com>" {
+		t.Errorf("Unexpected display name: %v", users[0]["display_name"])
+	}
+}
+
+func TestGoHandlerRedirect(t *testing.T) {
+	RegisterHandler("posts", "create", func(ctx context.Context, sqlData any, requestData map[string]any) (any, error) {
+		return map[string]any{
+			"_redirect": map[string]any{"url": "/posts", "status": 303},
+		}, nil
+	})
+	t.Cleanup(func() { UnregisterHandler("posts", "create") })
+
+	result, _, err := runDomainHandler(context.Background(), "posts", "create", nil, map[string]any{}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	redirect, ok := result.(map[string]any)["_redirect"].(map[string]any)
+	if !ok || redirect["url"] != "/posts" {
+		t.Errorf("Expected redirect to /posts, got %v", result)
+	}
+}
+
+func TestRunDomainHandlerWithoutHandler(t *testing.T) {
+	_, handled, err := runDomainHandler(context.Background(), "missing", "index", nil, map[string]any{}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if handled {
+		t.Error("Expected request to be unhandled without a Go handler or handler service")
+	}
+}
+
+// stubHandlerClient stands in for the JavaScript handler service
+type stubHandlerClient struct {
+	response *handler.HandlerResponse
+}
+
+func (s *stubHandlerClient) ProcessData(ctx context.Context, in *handler.HandlerRequest, opts ...grpc.CallOption) (*handler.HandlerResponse, error) {
+	return s.response, nil
+}
+
+func (s *stubHandlerClient) Health(ctx context.Context, in *handler.HealthRequest, opts ...grpc.CallOption) (*handler.HealthResponse, error) {
+	return &handler.HealthResponse{Healthy: true}, nil
+}
+
+func newStubbedFrameworkServer(t *testing.T, data map[string]any, redirect *handler.RedirectInfo) *lang_adapters.FrameworkServer {
+	t.Helper()
+
+	processed, err := structpb.NewStruct(data)
+	if err != nil {
+		t.Fatalf("Failed to build handler response: %v", err)
+	}
+
+	pm := lang_adapters.NewProcessManager(t.TempDir(), false)
+	pm.UseHandlerClient(&stubHandlerClient{response: &handler.HandlerResponse{
+		Success:       true,
+		ProcessedData: processed,
+		Redirect:      redirect,
+	}})
+
+	return &lang_adapters.FrameworkServer{ProcessManager: pm}
+}
+
+func serveStubbedRoute(t *testing.T, frameworkServer *lang_adapters.FrameworkServer, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	t.Setenv("FULCRUM_ENV", "production")
+
+	viewPath := filepath.Join(t.TempDir(), "post.html.hbs")
+	if err := os.WriteFile(viewPath, []byte("<p>{{vm.orders.name}}</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	route := parser.Route{Link: "/orders", Method: req.Method, Format: "html", ViewPath: viewPath}
+	group := RouteGroup{Pattern: "/orders", Method: req.Method, Domain: "orders", HTMLRoute: &route}
+	appConfig := &parser.AppConfig{
+		Domains: []parser.DomainConfig{{Name: "orders"}},
+		Views:   views.NewTemplateRenderer(),
+	}
+
+	rec := httptest.NewRecorder()
+	handleHTMLRouteWithProcessManager(rec, req, group, appConfig, frameworkServer)
+	return rec
+}
+
+func TestHandlerRedirectIsIssued(t *testing.T) {
+	frameworkServer := newStubbedFrameworkServer(t, map[string]any{"name": "order"}, &handler.RedirectInfo{Url: "/orders/7", StatusCode: 303})
+
+	rec := serveStubbedRoute(t, frameworkServer, httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	if rec.Code != http.StatusSeeOther {
+		t.Errorf("Expected status %d, got %d", http.StatusSeeOther, rec.Code)
+	}
+	if location := rec.Header().Get("Location"); location != "/orders/7" {
+		t.Errorf("Expected Location /orders/7, got %q", location)
+	}
+}
+
+func TestHandlerRedirectForHTMXUsesHeader(t *testing.T) {
+	frameworkServer := newStubbedFrameworkServer(t, map[string]any{"name": "order"}, &handler.RedirectInfo{Url: "/orders/7", StatusCode: 303})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := serveStubbedRoute(t, frameworkServer, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if hxRedirect := rec.Header().Get("HX-Redirect"); hxRedirect != "/orders/7" {
+		t.Errorf("Expected HX-Redirect /orders/7, got %q", hxRedirect)
+	}
+}
+
+func TestHandlerStatusIsHonored(t *testing.T) {
+	frameworkServer := newStubbedFrameworkServer(t, map[string]any{"name": "order", "_status": 422}, nil)
+
+	rec := serveStubbedRoute(t, frameworkServer, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d, got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+}
+
+func TestStripHandlerControlKeys(t *testing.T) {
+	data := map[string]any{
+		"name":      "order",
+		"_status":   422,
+		"_redirect": map[string]any{"url": "/orders"},
+	}
+
+	cleaned := stripHandlerControlKeys(data).(map[string]any)
+	if _, exists := cleaned["_redirect"]; exists {
+		t.Error("Expected _redirect to be stripped")
+	}
+	if _, exists := cleaned["_status"]; exists {
+		t.Error("Expected _status to be stripped")
+	}
+	if cleaned["name"] != "order" {
+		t.Errorf("Expected other keys to be kept, got %v", cleaned)
+	}
+}
+
+func TestPanickingHandlerReturns500(t *testing.T) {
+	RegisterHandler("auth", "boom", func(ctx context.Context, sqlData any, requestData map[string]any) (any, error) {
+		panic("handler blew up")
+	})
+	t.Cleanup(func() { UnregisterHandler("auth", "boom") })
+
+	route := parser.Route{Link: "/auth/boom", Method: "GET", Format: "html", View: "get.html.hbs"}
+	appConfig := &parser.AppConfig{
+		Domains: []parser.DomainConfig{
+			{Name: "auth", Logic: parser.LogicConfig{HTTP: parser.HTTPConfig{Routes: []parser.Route{route}}}},
+		},
+		Views: views.NewTemplateRenderer(),
+	}
+
+	mux := CreateRouteDispatcher(appConfig, nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/boom", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+}
+
+func TestMetricsEndpointCountsRequests(t *testing.T) {
+	t.Setenv("FULCRUM_ENV", "production")
+
+	viewPath := filepath.Join(t.TempDir(), "get.html.hbs")
+	if err := os.WriteFile(viewPath, []byte("<p>ok</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	route := parser.Route{Link: "/auth/probe/{id}", Method: "GET", Format: "html", ViewPath: viewPath}
+	appConfig := &parser.AppConfig{
+		Domains: []parser.DomainConfig{
+			{Name: "auth", Logic: parser.LogicConfig{HTTP: parser.HTTPConfig{Routes: []parser.Route{route}}}},
+		},
+		Views:   views.NewTemplateRenderer(),
+		Metrics: parser.MetricsConfig{Enabled: true},
+	}
+
+	mux := CreateRouteDispatcher(appConfig, nil)
+
+	for _, path := range []string{"/auth/probe/1", "/auth/probe/2", "/auth/probe/3"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d for %s, got %d: %s", http.StatusOK, path, rec.Code, rec.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.RemoteAddr = "127.0.0.1:40000"
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected metrics scrape to succeed, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, expected := range []string{
+		`fulcrum_http_requests_total{method="GET",route="/auth/probe/{id}",status="200"} 3`,
+		`fulcrum_http_request_duration_seconds_count{method="GET",route="/auth/probe/{id}"} 3`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected %q in scrape:\n%s", expected, body)
+		}
+	}
+	if strings.Contains(body, "/auth/probe/1") {
+		t.Error("Expected raw paths to stay out of metric labels")
+	}
+}
+
+func TestMetricsEndpointPlacement(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      parser.MetricsConfig
+		onAppMux    bool
+		adminServer bool
+	}{
+		{name: "off by default"},
+		{name: "enabled on the app listener", config: parser.MetricsConfig{Enabled: true}, onAppMux: true},
+		{name: "enabled on an admin address", config: parser.MetricsConfig{Enabled: true, Address: "127.0.0.1:0"}, adminServer: true},
+		{name: "address without enabled", config: parser.MetricsConfig{Address: "127.0.0.1:0"}},
+	}
+
+	// scrape reports whether handler answered a loopback scrape with metrics
+	scrape := func(handler http.Handler) bool {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.RemoteAddr = "127.0.0.1:40000"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code == http.StatusOK && strings.Contains(rec.Body.String(), "# TYPE fulcrum_")
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appConfig := &parser.AppConfig{Views: views.NewTemplateRenderer(), Metrics: tt.config}
+			if got := scrape(CreateRouteDispatcher(appConfig, nil)); got != tt.onAppMux {
+				t.Errorf("Expected metrics on the app listener %v, got %v", tt.onAppMux, got)
+			}
+
+			server, err := startMetricsServer(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (server != nil) != tt.adminServer {
+				t.Fatalf("Expected admin server %v, got %v", tt.adminServer, server != nil)
+			}
+			if server != nil {
+				t.Cleanup(func() { server.Close() })
+				if !scrape(server.Handler) {
+					t.Error("Expected the admin server to serve metrics")
+				}
+			}
+		})
+	}
+}
+
+func TestRoutesWithoutSQLNeverRenderMockData(t *testing.T) {
+	RegisterHandler("reports", "summary", func(ctx context.Context, sqlData any, requestData map[string]any) (any, error) {
+		return map[string]any{"total": 3, "period": requestData["period"]}, nil
+	})
+	t.Cleanup(func() { UnregisterHandler("reports", "summary") })
+
+	sqlPath := filepath.Join(t.TempDir(), "get.sql.hbs")
+	if err := os.WriteFile(sqlPath, []byte("SELECT * FROM reports"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	indexRoute := parser.Route{Method: "GET", Link: "/reports", Format: "json"}
+	summaryRoute := parser.Route{Method: "GET", Link: "/reports/summary", Format: "json"}
+	exportRoute := parser.Route{Method: "GET", Link: "/reports/export", Format: "json"}
+	exportSQL := parser.Route{Method: "GET", Link: "/reports/export", Format: "sql", View: "get.sql.hbs", ViewPath: sqlPath}
+	appConfig := &parser.AppConfig{
+		Domains: []parser.DomainConfig{{
+			Name:  "reports",
+			Logic: parser.LogicConfig{HTTP: parser.HTTPConfig{Routes: []parser.Route{indexRoute, summaryRoute, exportRoute, exportSQL}}},
+		}},
+		Views: views.NewTemplateRenderer(),
+	}
+
+	tests := []struct {
+		name     string
+		route    parser.Route
+		path     string
+		expected string
+	}{
+		{"no handler returns the request data", indexRoute, "/reports?period=q1", `"period":"q1"`},
+		{"handler result", summaryRoute, "/reports/summary?period=q1", `{"period":"q1","total":3}`},
+		{"SQL without a database fails", exportRoute, "/reports/export", `"success":false`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			requestData, err := extractRequestData(r, tt.route, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rec := httptest.NewRecorder()
+			handleJSONRoute(rec, r, tt.route, requestData, appConfig, &lang_adapters.FrameworkServer{})
+
+			body := rec.Body.String()
+			if !strings.Contains(body, tt.expected) {
+				t.Errorf("Expected %s in the response, got %s", tt.expected, body)
+			}
+			for _, mock := range []string{"John Doe", "jane@example.com", "processed_at"} {
+				if strings.Contains(body, mock) {
+					t.Errorf("Expected no mock data in the response, found %q in %s", mock, body)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLRouteWithoutDatabase(t *testing.T) {
+	dir := t.TempDir()
+	sqlPath := filepath.Join(dir, "get.sql.hbs")
+	if err := os.WriteFile(sqlPath, []byte("SELECT * FROM users"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	viewPath := filepath.Join(dir, "get.html.hbs")
+	if err := os.WriteFile(viewPath, []byte("<p>users</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	jsonRoute := parser.Route{Method: "GET", Link: "/users", Format: "json"}
+	htmlRoute := parser.Route{Method: "GET", Link: "/users", Format: "html", ViewPath: viewPath}
+	sqlRoute := parser.Route{Method: "GET", Link: "/users", Format: "sql", View: "get.sql.hbs", ViewPath: sqlPath}
+	newAppConfig := func(mock bool) *parser.AppConfig {
+		return &parser.AppConfig{
+			Domains: []parser.DomainConfig{{
+				Name:   "users",
+				Models: []parser.ModelDefinition{{"user": parser.Model{"name": {Type: "string"}, "age": {Type: "integer"}}}},
+				Logic:  parser.LogicConfig{HTTP: parser.HTTPConfig{Routes: []parser.Route{jsonRoute, htmlRoute, sqlRoute}}},
+			}},
+			Views: views.NewTemplateRenderer(),
+			Mock:  mock,
+		}
+	}
+
+	t.Run("JSON fails with a configuration hint", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleJSONRoute(rec, httptest.NewRequest(http.MethodGet, "/users", nil), jsonRoute, map[string]any{}, newAppConfig(false), &lang_adapters.FrameworkServer{})
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", rec.Code)
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, `"success":false`) || !strings.Contains(body, "fulcrum serve --mock") {
+			t.Errorf("Expected an error with a configuration hint, got %s", body)
+		}
+		for _, fake := range []string{"John", "Jane", "Bob", "_mock"} {
+			if strings.Contains(body, fake) {
+				t.Errorf("Expected no fake users, found %q in %s", fake, body)
+			}
+		}
+	})
+
+	t.Run("HTML fails with a configuration hint", func(t *testing.T) {
+		t.Setenv("FULCRUM_ENV", "production")
+		group := RouteGroup{Pattern: "/users", Method: "GET", Domain: "users", HTMLRoute: &htmlRoute, SQLRoute: &sqlRoute}
+
+		rec := httptest.NewRecorder()
+		handleHTMLRouteWithProcessManager(rec, httptest.NewRequest(http.MethodGet, "/users", nil), group, newAppConfig(false), nil)
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", rec.Code)
+		}
+		if body := rec.Body.String(); !strings.Contains(body, "no database connection") || strings.Contains(body, "<p>users</p>") {
+			t.Errorf("Expected the error instead of the page, got %s", body)
+		}
+	})
+
+	t.Run("mock mode serves labeled rows", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleJSONRoute(rec, httptest.NewRequest(http.MethodGet, "/users", nil), jsonRoute, map[string]any{}, newAppConfig(true), &lang_adapters.FrameworkServer{})
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rec.Code)
+		}
+		var response struct {
+			Data []map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Data) != mockRowCount {
+			t.Fatalf("Expected %d rows, got %v", mockRowCount, response.Data)
+		}
+		row := response.Data[0]
+		if row["_mock"] != true || row["name"] != "Mock name 1" || row["age"] != float64(1) {
+			t.Errorf("Expected a labeled placeholder user, got %v", row)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package framework
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// errNotFound fails a single-resource route whose record does not exist
+var errNotFound = errors.New("record not found")
+
+// Codes of the JSON error envelope, each answered with its own status
+const (
+	errorCodeInvalidRequest = "invalid_request"   // 400: the request data does not bind or validate
+	errorCodeNotFound       = "not_found"         // 404: the route's record does not exist
+	errorCodeConflict       = "conflict"          // 409: an update lost to a newer version
+	errorCodeTooLarge       = "payload_too_large" // 413: the upload exceeds server.uploads.max_size_mb
+	errorCodeDatabase       = "database_error"    // 500: the query failed or no database is connected
+	errorCodeHandler        = "handler_error"     // 500: the domain's handler failed
+	errorCodeTimeout        = "timeout"           // 504: the request ran out of time
+)
+
+// errorEnvelope is the body of a failed JSON route:
+// {"success": false, "error": {"code": "not_found", "message": "..."}}
+type errorEnvelope struct {
+	Success bool      `json:"success"`
+	Error   errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	// Fields lists each invalid field of an invalid_request error
+	Fields ValidationErrors `json:"fields,omitempty"`
+}
+
+// writeJSONError answers a JSON route with the envelope of err and its status
+func writeJSONError(w http.ResponseWriter, status int, code string, err error) {
+	body := errorBody{Code: code, Message: err.Error()}
+	var invalid ValidationErrors
+	if errors.As(err, &invalid) {
+		body.Fields = invalid
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(errorEnvelope{Error: body}); err != nil {
+		log.Printf("❌ Failed to encode JSON error: %v", err)
+	}
+}
+
+// writeRequestDataError answers a request whose data could not be read: as JSON for
+// JSON routes, and as text otherwise
+func writeRequestDataError(w http.ResponseWriter, format string, err error) {
+	status := requestDataStatus(err)
+	if format != "json" {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	code := errorCodeInvalidRequest
+	if status == http.StatusRequestEntityTooLarge {
+		code = errorCodeTooLarge
+	}
+	writeJSONError(w, status, code, err)
+}
+
+// sqlErrorStatus returns the status and code answering a JSON route whose SQL failed
+func sqlErrorStatus(err error) (int, string) {
+	switch {
+	case isTimeout(err):
+		return http.StatusGatewayTimeout, errorCodeTimeout
+	case errors.Is(err, errNotFound):
+		return http.StatusNotFound, errorCodeNotFound
+	case errors.Is(err, errStaleRecord):
+		return http.StatusConflict, errorCodeConflict
+	default:
+		return http.StatusInternalServerError, errorCodeDatabase
+	}
+}
+
+// recordPages are the actions generate domain routes under a record's id that read it,
+// e.g. /users/[users_id]/show
+var recordPages = map[string]bool{"show": true, "edit": true}
+
+// singleResource reports whether a route link reads one record: its last segment is a
+// path parameter such as /users/:user_id or /users/[user_id], or a show or edit page
+// under one such as /users/[users_id]/show
+func singleResource(link string) bool {
+	segments := strings.Split(strings.Trim(link, "/"), "/")
+	last := segments[len(segments)-1]
+	if recordPages[last] && len(segments) > 1 {
+		last = segments[len(segments)-2]
+	}
+	if isCatchAllSegment(last) {
+		return false
+	}
+	return strings.HasPrefix(last, ":") || (strings.HasPrefix(last, "[") && strings.HasSuffix(last, "]"))
+}
+
+// requireRecord fails a GET of a single resource that found no rows with errNotFound
+func requireRecord(method, link string, data any) error {
+	if method != http.MethodGet || !singleResource(link) {
+		return nil
+	}
+	if rows, ok := data.([]map[string]any); ok && len(rows) == 0 {
+		return fmt.Errorf("%w at %s", errNotFound, link)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fulcrum/lib/database"
+	lang_adapters "fulcrum/lib/lang/adapters"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+func TestJSONRouteErrors(t *testing.T) {
+	t.Setenv("FULCRUM_ENV", "production")
+
+	db := newSQLiteDB(t,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT)",
+		"INSERT INTO posts (id, title) VALUES (1, 'first')",
+	)
+	server := &lang_adapters.FrameworkServer{Db: db, DbExecutor: database.NewDatabaseExecutor(db)}
+
+	// route serves link as JSON with the results of sql
+	route := func(t *testing.T, link, sql string) (parser.Route, *parser.AppConfig) {
+		dir := t.TempDir()
+		for file, source := range map[string]string{"get.sql.hbs": sql, "get.json.hbs": "{{{json this}}}"} {
+			if err := os.WriteFile(filepath.Join(dir, file), []byte(source), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		jsonRoute := parser.Route{Link: link, Method: "GET", Format: "json", ViewPath: filepath.Join(dir, "get.json.hbs")}
+		domain := parser.DomainConfig{Name: "posts"}
+		domain.Logic.HTTP.Routes = []parser.Route{
+			jsonRoute,
+			{Link: link, Method: "GET", Format: "sql", ViewPath: filepath.Join(dir, "get.sql.hbs")},
+		}
+		return jsonRoute, &parser.AppConfig{Domains: []parser.DomainConfig{domain}, Views: views.NewTemplateRenderer()}
+	}
+
+	tests := []struct {
+		name         string
+		link         string
+		sql          string
+		requestData  map[string]any
+		expectedCode int
+		errorCode    string
+	}{
+		{
+			name:         "existing record",
+			link:         "/posts/:post_id",
+			sql:          "SELECT * FROM posts WHERE id = :post_id",
+			requestData:  map[string]any{"post_id": int64(1)},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "missing record",
+			link:         "/posts/:post_id",
+			sql:          "SELECT * FROM posts WHERE id = :post_id",
+			requestData:  map[string]any{"post_id": int64(2)},
+			expectedCode: http.StatusNotFound,
+			errorCode:    errorCodeNotFound,
+		},
+		{
+			name:         "empty list",
+			link:         "/posts",
+			sql:          "SELECT * FROM posts WHERE id > 5",
+			requestData:  map[string]any{},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "database failure",
+			link:         "/posts",
+			sql:          "SELECT * FROM missing_table",
+			requestData:  map[string]any{},
+			expectedCode: http.StatusInternalServerError,
+			errorCode:    errorCodeDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonRoute, appConfig := route(t, tt.link, tt.sql)
+			rec := httptest.NewRecorder()
+			handleJSONRoute(rec, httptest.NewRequest(http.MethodGet, "/posts", nil), jsonRoute, tt.requestData, appConfig, server)
+
+			if rec.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, rec.Code, rec.Body.String())
+			}
+			if contentType := rec.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("Expected a JSON response, got %s", contentType)
+			}
+			if tt.errorCode == "" {
+				return
+			}
+			var body errorEnvelope
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Success || body.Error.Code != tt.errorCode || body.Error.Message == "" {
+				t.Errorf("Expected a %s error, got %+v", tt.errorCode, body)
+			}
+		})
+	}
+}
+
+func TestWriteRequestDataError(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       string
+		err          error
+		expectedCode int
+		errorCode    string
+	}{
+		{
+			name:         "invalid fields",
+			format:       "json",
+			err:          ValidationErrors{{Field: "age", Message: "must be an integer"}},
+			expectedCode: http.StatusBadRequest,
+			errorCode:    errorCodeInvalidRequest,
+		},
+		{
+			name:         "upload too large",
+			format:       "json",
+			err:          fmt.Errorf("%w: limit is 10 MB", errUploadTooLarge),
+			expectedCode: http.StatusRequestEntityTooLarge,
+			errorCode:    errorCodeTooLarge,
+		},
+		{name: "html stays text", format: "html", err: ValidationErrors{{Field: "age", Message: "must be an integer"}}, expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeRequestDataError(rec, tt.format, tt.err)
+			if rec.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if tt.errorCode == "" {
+				if rec.Header().Get("Content-Type") == "application/json" {
+					t.Errorf("Expected a text response, got %s", rec.Body.String())
+				}
+				return
+			}
+			var body errorEnvelope
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error.Code != tt.errorCode {
+				t.Errorf("Expected code %s, got %+v", tt.errorCode, body)
+			}
+			if invalid, ok := tt.err.(ValidationErrors); ok && len(body.Error.Fields) != len(invalid) {
+				t.Errorf("Expected the invalid fields listed, got %+v", body.Error.Fields)
+			}
+		})
+	}
+}
+
+func TestSingleResource(t *testing.T) {
+	tests := []struct {
+		link     string
+		expected bool
+	}{
+		{link: "/posts/:post_id", expected: true},
+		{link: "/posts/[post_id]", expected: true},
+		{link: "/posts"},
+		{link: "/posts/:posts_id/show", expected: true},
+		{link: "/posts/[posts_id]/edit", expected: true},
+		{link: "/posts/:post_id/comments"},
+		{link: "/posts/show"},
+		{link: "/docs/:...path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			if got := singleResource(tt.link); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"fulcrum/lib/i18n"
+	lang_adapters "fulcrum/lib/lang/adapters"
+	"fulcrum/lib/naming"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+// errStaleRecord fails an update guarded by its domain's lock column that changed no
+// rows: someone else updated the record after the form was loaded
+var errStaleRecord = errors.New("the record was modified by someone else")
+
+// conflictMessage is shown above the form an update lost to a newer version re-renders
+const conflictMessage = "This record was modified by someone else while you were editing it. Review the latest version and submit your changes again."
+
+// staleUpdate reports whether sqlQuery is an UPDATE comparing lockColumn with the
+// submitted version that changed no rows
+func staleUpdate(sqlQuery string, count int, requestData map[string]any, lockColumn string) bool {
+	return count == 0 && requestData[lockColumn] != nil &&
+		strings.HasPrefix(strings.ToUpper(strings.TrimSpace(sqlQuery)), "UPDATE") &&
+		strings.Contains(sqlQuery, ":"+lockColumn)
+}
+
+// domainLockColumn returns the lock column of the domain serving route
+func domainLockColumn(appConfig *parser.AppConfig, route parser.Route) string {
+	if domain, ok := appConfig.GetDomain(routeDomain(appConfig, route)); ok {
+		return domain.LockColumnName()
+	}
+	return parser.DefaultLockColumn
+}
+
+// lockColumns maps the tables of domains that name their own lock column to it, for the
+// executor's versioned updates
+func lockColumns(appConfig *parser.AppConfig) map[string]string {
+	columns := make(map[string]string)
+	for _, domain := range appConfig.Domains {
+		if domain.LockColumn != "" {
+			columns[naming.Pluralize(domain.Name)] = domain.LockColumn
+		}
+	}
+	return columns
+}
+
+// bindLockVersion keeps the version an update form submits in params as an integer, as
+// the lock column is not a model field and binding drops it
+func bindLockVersion(raw, params map[string]any, domain *parser.DomainConfig) error {
+	column := parser.DefaultLockColumn
+	if domain != nil {
+		column = domain.LockColumnName()
+	}
+	value, ok := raw[column]
+	if !ok {
+		return nil
+	}
+	version, err := coerceField("integer", value)
+	if err != nil {
+		return ValidationErrors{{Field: column, Message: err.Error()}}
+	}
+	params[column] = version
+	return nil
+}
+
+// renderConflict answers an update that lost to a newer version with 409 Conflict,
+// re-rendering its form with the record as it is now under vm.<domain> and what was
+// submitted under vm.conflict.submitted, so both can be compared
+func renderConflict(ctx context.Context, w http.ResponseWriter, r *http.Request, group RouteGroup, htmxReq HTMXRequest, requestData map[string]any, appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) {
+	if group.HTMLRoute == nil {
+		http.Error(w, conflictMessage, http.StatusConflict)
+		return
+	}
+
+	latest := latestRecord(ctx, group, requestData, appConfig, frameworkServer)
+	lockColumn := domainLockColumn(appConfig, *group.HTMLRoute)
+	submitted := make(map[string]any)
+	if len(latest) > 0 {
+		for column := range latest[0] {
+			if value, ok := requestData[column]; ok && column != "id" && column != lockColumn {
+				submitted[column] = value
+			}
+		}
+	}
+
+	viewModel := map[string]any{
+		"vm": map[string]any{
+			group.Domain: latest,
+			"domain":     group.Domain,
+			"group":      group,
+			"htmx":       htmxReq,
+			"conflict": map[string]any{
+				"message":   conflictMessage,
+				"submitted": submitted,
+			},
+		},
+		views.LocaleKey: i18n.ResolveLocale(r),
+	}
+	html, err := loadAndRenderHTMXTemplate(group.HTMLRoute.ViewPath, nil, viewModel, appConfig.Views, htmxReq.IsHTMX, appConfig.StrictLayoutEnabled())
+	if err != nil {
+		log.Printf("Template render failed: %v", err)
+		http.Error(w, conflictMessage, http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusConflict)
+	w.Write([]byte(html))
+}
+
+// latestRecord reads the record an update lost to with the SQL of its domain's edit
+// route, or else its show route, e.g. /posts/[post_id]/edit for /posts/[post_id]/update
+func latestRecord(ctx context.Context, group RouteGroup, requestData map[string]any, appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) []map[string]any {
+	domain, ok := appConfig.GetDomain(group.Domain)
+	if !ok || group.SQLRoute == nil {
+		return nil
+	}
+	base := path.Dir(group.SQLRoute.Link)
+	for _, link := range []string{base + "/edit", base} {
+		for _, route := range domain.Logic.HTTP.Routes {
+			if route.Link != link || route.Format != "sql" || route.Query != "" || !strings.EqualFold(route.Method, http.MethodGet) {
+				continue
+			}
+			data, err := executeSQL(ctx, &route, requestData, appConfig, frameworkServer)
+			if err != nil {
+				log.Printf("⚠️ Failed to read the latest version of the record: %v", err)
+				return nil
+			}
+			rows, _ := data.([]map[string]any)
+			return rows
+		}
+	}
+	return nil
+}
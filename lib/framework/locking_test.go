@@ -0,0 +1,180 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fulcrum/lib/database"
+	"fulcrum/lib/database/interfaces"
+	lang_adapters "fulcrum/lib/lang/adapters"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+// lockingApp serves a posts domain whose update route checks lock_version, against a
+// fresh SQLite database holding post 1 at version 0
+type lockingApp struct {
+	db        interfaces.Database
+	group     RouteGroup
+	json      parser.Route
+	appConfig *parser.AppConfig
+	server    *lang_adapters.FrameworkServer
+}
+
+func newLockingApp(t *testing.T) *lockingApp {
+	t.Helper()
+	t.Setenv("FULCRUM_ENV", "production")
+
+	db := newSQLiteDB(t,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT, lock_version INTEGER NOT NULL DEFAULT 0)",
+		"INSERT INTO posts (id, title) VALUES (1, 'first')",
+	)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"update.html.hbs": `<form>{{vm.conflict.message}}</form>`,
+		"update.sql.hbs":  "UPDATE posts SET title = :title, lock_version = lock_version + 1 WHERE id = :post_id AND lock_version = :lock_version",
+		"update.json.hbs": "{{{json this}}}",
+		"edit.sql.hbs":    "SELECT * FROM posts WHERE id = :post_id",
+	}
+	for file, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const link = "/posts/[post_id]/update"
+	htmlRoute := parser.Route{Link: link, Method: "POST", Format: "html", ViewPath: filepath.Join(dir, "update.html.hbs")}
+	sqlRoute := parser.Route{Link: link, Method: "POST", Format: "sql", ViewPath: filepath.Join(dir, "update.sql.hbs")}
+	jsonRoute := parser.Route{Link: link, Method: "POST", Format: "json", ViewPath: filepath.Join(dir, "update.json.hbs")}
+	editRoute := parser.Route{Link: "/posts/[post_id]/edit", Method: "GET", Format: "sql", ViewPath: filepath.Join(dir, "edit.sql.hbs")}
+
+	domain := parser.DomainConfig{Name: "posts"}
+	domain.Logic.HTTP.Routes = []parser.Route{htmlRoute, sqlRoute, jsonRoute, editRoute}
+	return &lockingApp{
+		db:        db,
+		group:     RouteGroup{Pattern: link, Method: "POST", Domain: "posts", HTMLRoute: &htmlRoute, SQLRoute: &sqlRoute},
+		json:      jsonRoute,
+		appConfig: &parser.AppConfig{Domains: []parser.DomainConfig{domain}, Views: views.NewTemplateRenderer()},
+		server:    &lang_adapters.FrameworkServer{Db: db, DbExecutor: database.NewDatabaseExecutor(db)},
+	}
+}
+
+// submit posts the edit form of post 1 with title, as loaded at version
+func (app *lockingApp) submit(title, version string) *httptest.ResponseRecorder {
+	form := url.Values{"title": {title}, "lock_version": {version}}
+	req := httptest.NewRequest(http.MethodPost, "/posts/1/update", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetPathValue("post_id", "1")
+	rec := httptest.NewRecorder()
+	handleHTMLRouteWithProcessManager(rec, req, app.group, app.appConfig, app.server)
+	return rec
+}
+
+// post returns post 1's title and version
+func (app *lockingApp) post(t *testing.T) (string, int) {
+	t.Helper()
+	var title string
+	var version int
+	if err := app.db.QueryRow(context.Background(), "SELECT title, lock_version FROM posts WHERE id = 1").Scan(&title, &version); err != nil {
+		t.Fatal(err)
+	}
+	return title, version
+}
+
+func TestUpdateConflict(t *testing.T) {
+	t.Run("the later of two edits of the same version conflicts", func(t *testing.T) {
+		app := newLockingApp(t)
+
+		// Alice and Bob both opened the edit form at version 0
+		if rec := app.submit("alice", "0"); rec.Code != http.StatusOK {
+			t.Fatalf("Expected alice's update to apply, got %d: %s", rec.Code, rec.Body.String())
+		}
+		rec := app.submit("bob", "0")
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("Expected bob's update to conflict, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if !strings.Contains(rec.Body.String(), "<form>") {
+			t.Errorf("Expected the form to be re-rendered, got %s", rec.Body.String())
+		}
+
+		if title, version := app.post(t); title != "alice" || version != 1 {
+			t.Errorf("Expected alice's update kept at version 1, got %q at %d", title, version)
+		}
+
+		// Submitting again from the latest version applies
+		if rec := app.submit("bob", "1"); rec.Code != http.StatusOK {
+			t.Fatalf("Expected the resubmitted update to apply, got %d", rec.Code)
+		}
+		if title, version := app.post(t); title != "bob" || version != 2 {
+			t.Errorf("Expected bob's update at version 2, got %q at %d", title, version)
+		}
+	})
+
+	t.Run("the conflict shows the latest record beside the submission", func(t *testing.T) {
+		app := newLockingApp(t)
+		app.submit("alice", "0")
+
+		requestData := map[string]any{"post_id": int64(1), "title": "bob", "lock_version": int64(0)}
+		latest := latestRecord(context.Background(), app.group, requestData, app.appConfig, app.server)
+		if len(latest) != 1 || latest[0]["title"] != "alice" {
+			t.Errorf("Expected alice's version as the latest record, got %v", latest)
+		}
+	})
+
+	t.Run("json routes answer 409", func(t *testing.T) {
+		app := newLockingApp(t)
+		app.submit("alice", "0")
+
+		rec := httptest.NewRecorder()
+		requestData := map[string]any{"post_id": int64(1), "title": "bob", "lock_version": int64(0)}
+		handleJSONRoute(rec, httptest.NewRequest(http.MethodPost, "/posts/1/update", nil), app.json, requestData, app.appConfig, app.server)
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("Expected status %d, got %d", http.StatusConflict, rec.Code)
+		}
+		var body errorEnvelope
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Error.Code != errorCodeConflict {
+			t.Errorf("Expected the response to report the conflict, got %+v", body)
+		}
+		if title, _ := app.post(t); title != "alice" {
+			t.Errorf("Expected alice's update kept, got %q", title)
+		}
+	})
+}
+
+func TestStaleUpdate(t *testing.T) {
+	const locked = "UPDATE posts SET title = :title, lock_version = lock_version + 1 WHERE id = :post_id AND lock_version = :lock_version"
+	versioned := map[string]any{"lock_version": int64(0)}
+
+	tests := []struct {
+		name        string
+		query       string
+		count       int
+		requestData map[string]any
+		expected    bool
+	}{
+		{name: "locked update changing no rows", query: locked, requestData: versioned, expected: true},
+		{name: "locked update changing a row", query: locked, count: 1, requestData: versioned},
+		{name: "no version submitted", query: locked, requestData: map[string]any{}},
+		{name: "update without the lock column", query: "UPDATE posts SET title = :title WHERE id = :post_id", requestData: versioned},
+		{name: "select", query: "SELECT * FROM posts WHERE lock_version = :lock_version", requestData: versioned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := staleUpdate(tt.query, tt.count, tt.requestData, parser.DefaultLockColumn); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package framework
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+
+	"fulcrum/lib/metrics"
+	parser "fulcrum/lib/parser"
+)
+
+// metricsHandler serves the metrics registry with the access rules from fulcrum.yml
+func metricsHandler(mc parser.MetricsConfig) http.Handler {
+	return metrics.Handler(metrics.HandlerOptions{
+		Username: mc.Username,
+		Password: mc.Password,
+		Public:   mc.Public,
+	})
+}
+
+// startMetricsServer serves the metrics endpoint on metrics.address, apart from the
+// app's routes. It returns nil when metrics are off or share the app's listener.
+func startMetricsServer(mc parser.MetricsConfig) (*http.Server, error) {
+	if !mc.Enabled || mc.Address == "" {
+		return nil, nil
+	}
+
+	listener, err := net.Listen("tcp", mc.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on metrics address %s: %w", mc.Address, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET "+mc.MetricsPath(), metricsHandler(mc))
+	server := &http.Server{Handler: mux}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("Metrics server error: %v", err)
+		}
+	}()
+	return server, nil
+}
+
+// metricsLocation describes where metrics are scraped for the banner
+func metricsLocation(mc parser.MetricsConfig, appPort string) string {
+	if mc.Address != "" {
+		return "http://" + mc.Address + mc.MetricsPath()
+	}
+	return "http://localhost:" + appPort + mc.MetricsPath()
+}
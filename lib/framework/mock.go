@@ -0,0 +1,59 @@
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	parser "fulcrum/lib/parser"
+)
+
+// errNoDatabase is returned when a SQL route runs without a database outside mock mode
+var errNoDatabase = errors.New("no database connection")
+
+// noDatabaseHint tells whoever sees errNoDatabase how to get past it
+const noDatabaseHint = "check the db section of fulcrum.yml, or run fulcrum serve --mock to serve placeholder rows"
+
+// mockRowCount is how many placeholder rows a SQL route returns in mock mode
+const mockRowCount = 3
+
+// mockRows returns placeholder rows for a SQL route of the domain's model, each
+// labeled "_mock": true so they can't pass for real data
+func mockRows(appConfig *parser.AppConfig, route parser.Route) []map[string]any {
+	domain, _ := appConfig.GetDomain(routeDomain(appConfig, route))
+	model, _ := domainModel(domain)
+
+	fields := make([]string, 0, len(model))
+	for name := range model {
+		fields = append(fields, name)
+	}
+	slices.Sort(fields)
+
+	rows := make([]map[string]any, mockRowCount)
+	for i := range rows {
+		row := map[string]any{"id": i + 1, "_mock": true}
+		for _, name := range fields {
+			row[name] = mockValue(name, model[name].Type, i+1)
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// mockValue returns the nth placeholder value for a field of the given type
+func mockValue(name, fieldType string, n int) any {
+	switch strings.ToLower(fieldType) {
+	case "integer", "int", "bigint", "references":
+		return n
+	case "decimal", "numeric", "float", "double":
+		return float64(n) + 0.5
+	case "boolean", "bool":
+		return n%2 == 1
+	case "date":
+		return fmt.Sprintf("2000-01-%02d", n)
+	case "datetime", "timestamp":
+		return fmt.Sprintf("2000-01-%02dT00:00:00Z", n)
+	}
+	return fmt.Sprintf("Mock %s %d", name, n)
+}
@@ -0,0 +1,300 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	parser "fulcrum/lib/parser"
+)
+
+// openAPIVersion is the OpenAPI version of the generated document
+const openAPIVersion = "3.0.3"
+
+// jsonSchema is an OpenAPI schema object; encoding/json sorts its keys, which keeps
+// the document deterministic
+type jsonSchema map[string]any
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]jsonSchema `json:"schemas"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Tags        []string                   `json:"tags"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string     `json:"name"`
+	In       string     `json:"in"`
+	Required bool       `json:"required"`
+	Schema   jsonSchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema jsonSchema `json:"schema"`
+}
+
+// OpenAPI returns an OpenAPI 3 document describing the app's json routes. Request
+// bodies of POST, PUT and PATCH routes follow the fields the route accepts from the
+// domain model; responses use the {success, data, count} envelope, and failures
+// {success, error: {code, message}} with a status per code.
+func OpenAPI(appConfig *parser.AppConfig) ([]byte, error) {
+	title := "Fulcrum app"
+	if appConfig.Path != "" {
+		title = filepath.Base(appConfig.Path)
+	}
+
+	doc := openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    openAPIInfo{Title: title, Version: "1.0.0"},
+		Paths:   map[string]map[string]*openAPIOperation{},
+		Components: openAPIComponents{Schemas: map[string]jsonSchema{
+			"SuccessResponse": {
+				"type":     "object",
+				"required": []string{"success"},
+				"properties": map[string]jsonSchema{
+					"success": {"type": "boolean"},
+					"data":    {},
+					"count":   {"type": "integer"},
+				},
+			},
+			"ErrorResponse": {
+				"type":     "object",
+				"required": []string{"success", "error"},
+				"properties": map[string]jsonSchema{
+					"success": {"type": "boolean"},
+					"error": {
+						"type":     "object",
+						"required": []string{"code", "message"},
+						"properties": map[string]jsonSchema{
+							"code": {"type": "string", "enum": []string{
+								errorCodeInvalidRequest, errorCodeNotFound, errorCodeConflict, errorCodeTooLarge,
+								errorCodeDatabase, errorCodeHandler, errorCodeTimeout,
+							}},
+							"message": {"type": "string"},
+							"fields":  {"type": "array", "items": jsonSchema{"type": "object"}},
+						},
+					},
+				},
+			},
+		}},
+	}
+
+	for i := range appConfig.Domains {
+		domain := &appConfig.Domains[i]
+		for _, route := range domain.Logic.HTTP.Routes {
+			if route.Format != "json" {
+				continue
+			}
+			path, params := openAPIPath(domain, route.Link)
+			method := strings.ToLower(route.Method)
+			if doc.Paths[path] == nil {
+				doc.Paths[path] = map[string]*openAPIOperation{}
+			}
+			if doc.Paths[path][method] != nil {
+				continue
+			}
+			doc.Paths[path][method] = openAPIOperationFor(domain, route, params)
+		}
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// openAPIPath converts a route link to an OpenAPI path, /users/:user_id becoming
+// /users/{user_id}, and returns the path's parameters
+func openAPIPath(domain *parser.DomainConfig, link string) (string, []openAPIParameter) {
+	var params []openAPIParameter
+	segments := strings.Split(link, "/")
+	for i, segment := range segments {
+		name, ok := strings.CutPrefix(segment, ":")
+		if !ok {
+			continue
+		}
+		name = strings.TrimPrefix(name, "...")
+		segments[i] = "{" + name + "}"
+
+		schema := jsonSchema{"type": "string"}
+		if fieldType, ok := lookupPathParameterType(domain, name); ok {
+			schema = fieldSchema(parser.Field{Type: fieldType})
+			delete(schema, "nullable")
+		}
+		params = append(params, openAPIParameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// openAPIOperationFor describes one json route
+func openAPIOperationFor(domain *parser.DomainConfig, route parser.Route, params []openAPIParameter) *openAPIOperation {
+	operation := &openAPIOperation{
+		OperationID: openAPIOperationID(route),
+		Tags:        []string{domain.Name},
+		Parameters:  params,
+		Responses: map[string]openAPIResponse{
+			"200": envelopeResponse("Success", "SuccessResponse"),
+			"400": envelopeResponse("Invalid request data", "ErrorResponse"),
+			"500": envelopeResponse("Server error", "ErrorResponse"),
+			"504": envelopeResponse("Request timed out", "ErrorResponse"),
+		},
+	}
+	if route.Method == "GET" && singleResource(route.Link) {
+		operation.Responses["404"] = envelopeResponse("Record not found", "ErrorResponse")
+	}
+
+	switch route.Method {
+	case "POST", "PUT", "PATCH":
+	default:
+		return operation
+	}
+
+	// Create and update routes only accept their permitted fields; others take any
+	schema := jsonSchema{"type": "object", "additionalProperties": true}
+	if bindsModel(route) {
+		model, _ := domainModel(domain)
+		if fields, ok := permittedFields(route, model); ok {
+			schema = modelSchema(model, fields)
+		}
+	}
+
+	content := map[string]openAPIMediaType{
+		"application/json":                  {Schema: schema},
+		"application/x-www-form-urlencoded": {Schema: schema},
+	}
+	if hasBinaryProperty(schema) {
+		// Image fields are uploaded as files
+		content = map[string]openAPIMediaType{"multipart/form-data": {Schema: schema}}
+	}
+	operation.RequestBody = &openAPIRequestBody{Required: true, Content: content}
+	return operation
+}
+
+// hasBinaryProperty reports whether an object schema has a file property
+func hasBinaryProperty(schema jsonSchema) bool {
+	properties, _ := schema["properties"].(map[string]jsonSchema)
+	for _, property := range properties {
+		if property["format"] == "binary" {
+			return true
+		}
+	}
+	return false
+}
+
+// openAPIOperationID names an operation after its method and link, e.g. get_users_user_id
+func openAPIOperationID(route parser.Route) string {
+	id := strings.NewReplacer("/", "_", ":", "", ".", "", "-", "_").Replace(strings.Trim(route.Link, "/"))
+	return strings.ToLower(route.Method) + "_" + id
+}
+
+// envelopeResponse is a response whose body is one of the envelope schemas
+func envelopeResponse(description, schema string) openAPIResponse {
+	return openAPIResponse{
+		Description: description,
+		Content: map[string]openAPIMediaType{
+			"application/json": {Schema: jsonSchema{"$ref": "#/components/schemas/" + schema}},
+		},
+	}
+}
+
+// modelSchema describes the given fields of a model as an object schema; fields the
+// model doesn't declare are untyped
+func modelSchema(model parser.Model, fields []string) jsonSchema {
+	fields = slices.Clone(fields)
+	slices.Sort(fields)
+
+	properties := map[string]jsonSchema{}
+	var required []string
+	for _, name := range fields {
+		field, ok := model.GetField(name)
+		if !ok {
+			properties[name] = jsonSchema{}
+			continue
+		}
+		properties[name] = fieldSchema(field)
+		if !field.IsNullable() {
+			required = append(required, name)
+		}
+	}
+
+	schema := jsonSchema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fieldSchema describes a model field, including its length validation
+func fieldSchema(field parser.Field) jsonSchema {
+	var schema jsonSchema
+	switch strings.ToLower(field.Type) {
+	case "integer", "int", "bigint", "serial", "bigserial", "references":
+		schema = jsonSchema{"type": "integer", "format": "int64"}
+	case "decimal", "numeric", "float", "double":
+		schema = jsonSchema{"type": "number"}
+	case "boolean", "bool":
+		schema = jsonSchema{"type": "boolean"}
+	case "date":
+		schema = jsonSchema{"type": "string", "format": "date"}
+	case "datetime", "timestamp":
+		schema = jsonSchema{"type": "string", "format": "date-time"}
+	case "image":
+		schema = jsonSchema{"type": "string", "format": "binary"}
+	default:
+		schema = jsonSchema{"type": "string"}
+		if minLength, maxLength, ok := field.GetLengthConstraints(); ok {
+			if minLength > 0 {
+				schema["minLength"] = minLength
+			}
+			if maxLength > 0 {
+				schema["maxLength"] = maxLength
+			}
+		}
+	}
+	if field.IsNullable() {
+		schema["nullable"] = true
+	}
+	return schema
+}
+
+// openAPIHandler serves the OpenAPI document at GET /openapi.json in development
+func openAPIHandler(appConfig *parser.AppConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		doc, err := OpenAPI(appConfig)
+		if err != nil {
+			log.Printf("❌ OpenAPI generation failed: %v", err)
+			http.Error(w, fmt.Sprintf("OpenAPI generation failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(doc)
+	}
+}
@@ -0,0 +1,157 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func openAPITestConfig() *parser.AppConfig {
+	jsonRoute := func(method, link string) parser.Route {
+		return parser.Route{Method: method, Link: link, Format: "json"}
+	}
+	return &parser.AppConfig{
+		Path: "/apps/shop",
+		Domains: []parser.DomainConfig{
+			{
+				Name: "users",
+				Models: []parser.ModelDefinition{{"user": parser.Model{
+					"id":    {Type: "integer"},
+					"email": {Type: "text", Validations: []parser.Validation{{"nullable": false}, {"length": map[string]any{"min": 5, "max": 80}}}},
+					"age":   {Type: "integer"},
+				}}},
+				Logic: parser.LogicConfig{HTTP: parser.HTTPConfig{Routes: []parser.Route{
+					jsonRoute("GET", "/users"),
+					jsonRoute("GET", "/users/:user_id"),
+					jsonRoute("POST", "/users/create"),
+					{Method: "POST", Link: "/users/:user_id/update", Format: "json", Permit: []string{"age"}},
+					{Method: "GET", Link: "/users", Format: "html"},
+				}}},
+			},
+			{
+				Name: "notes",
+				Logic: parser.LogicConfig{HTTP: parser.HTTPConfig{Routes: []parser.Route{
+					jsonRoute("POST", "/notes/create"),
+					jsonRoute("GET", "/notes/:slug"),
+				}}},
+			},
+		},
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	appConfig := openAPITestConfig()
+
+	data, err := OpenAPI(appConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(data)
+	if err != nil {
+		t.Fatalf("Failed to load the document: %v\n%s", err, data)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("Invalid OpenAPI document: %v\n%s", err, data)
+	}
+
+	again, err := OpenAPI(appConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Error("Expected the same document on every run")
+	}
+
+	var paths []string
+	for path := range doc.Paths.Map() {
+		paths = append(paths, path)
+	}
+	expectedPaths := map[string]bool{"/users": true, "/users/{user_id}": true, "/users/create": true, "/users/{user_id}/update": true, "/notes/create": true, "/notes/{slug}": true}
+	if len(paths) != len(expectedPaths) {
+		t.Errorf("Expected paths %v, got %v", expectedPaths, paths)
+	}
+
+	t.Run("path parameters", func(t *testing.T) {
+		param := doc.Paths.Value("/users/{user_id}").Get.Parameters[0].Value
+		if param.Name != "user_id" || param.In != "path" || !param.Required || !param.Schema.Value.Type.Is("integer") {
+			t.Errorf("Expected a required integer user_id, got %+v %+v", param, param.Schema.Value)
+		}
+		slug := doc.Paths.Value("/notes/{slug}").Get.Parameters[0].Value
+		if !slug.Schema.Value.Type.Is("string") {
+			t.Errorf("Expected an untyped parameter to be a string, got %v", slug.Schema.Value.Type)
+		}
+	})
+
+	t.Run("create body follows the model", func(t *testing.T) {
+		schema := doc.Paths.Value("/users/create").Post.RequestBody.Value.Content["application/json"].Schema.Value
+		var properties []string
+		for name := range schema.Properties {
+			properties = append(properties, name)
+		}
+		if len(properties) != 2 || schema.Properties["id"] != nil {
+			t.Errorf("Expected email and age without id, got %v", properties)
+		}
+		email := schema.Properties["email"].Value
+		if !email.Type.Is("string") || email.MinLength != 5 || email.MaxLength == nil || *email.MaxLength != 80 {
+			t.Errorf("Expected the length validation on email, got %+v", email)
+		}
+		if !reflect.DeepEqual(schema.Required, []string{"email"}) {
+			t.Errorf("Expected email to be required, got %v", schema.Required)
+		}
+	})
+
+	t.Run("permit list", func(t *testing.T) {
+		schema := doc.Paths.Value("/users/{user_id}/update").Post.RequestBody.Value.Content["application/json"].Schema.Value
+		if len(schema.Properties) != 1 || schema.Properties["age"] == nil {
+			t.Errorf("Expected only age, got %v", schema.Properties)
+		}
+	})
+
+	t.Run("domain without models", func(t *testing.T) {
+		schema := doc.Paths.Value("/notes/create").Post.RequestBody.Value.Content["application/json"].Schema.Value
+		if !schema.Type.Is("object") || len(schema.Properties) != 0 {
+			t.Errorf("Expected an untyped object, got %+v", schema)
+		}
+	})
+
+	t.Run("envelope responses", func(t *testing.T) {
+		responses := doc.Paths.Value("/users").Get.Responses
+		if ref := responses.Status(200).Value.Content["application/json"].Schema.Ref; ref != "#/components/schemas/SuccessResponse" {
+			t.Errorf("Expected the success envelope, got %q", ref)
+		}
+		envelope := doc.Components.Schemas["ErrorResponse"].Value
+		if envelope.Properties["error"] == nil || envelope.Properties["success"] == nil {
+			t.Errorf("Expected success and error in the error envelope, got %v", envelope.Properties)
+		}
+	})
+}
+
+func TestOpenAPIRouteOnlyInDevelopment(t *testing.T) {
+	for _, env := range []string{"development", "production"} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv("FULCRUM_ENV", env)
+			appConfig := openAPITestConfig()
+			appConfig.Auth.Disabled = true
+			appConfig.Views = views.NewTemplateRenderer()
+
+			rec := httptest.NewRecorder()
+			CreateRouteDispatcher(appConfig, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+			served := rec.Code == http.StatusOK && json.Valid(rec.Body.Bytes()) && bytes.Contains(rec.Body.Bytes(), []byte(`"openapi"`))
+			if served != (env == "development") {
+				t.Errorf("Expected /openapi.json to be served: %v, got %d %s", env == "development", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
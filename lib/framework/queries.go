@@ -0,0 +1,85 @@
+package framework
+
+import (
+	"context"
+	"log"
+	"maps"
+	"sync"
+
+	lang_adapters "fulcrum/lib/lang/adapters"
+	parser "fulcrum/lib/parser"
+)
+
+// maxQueryWorkers bounds how many of a route's named queries run at once
+const maxQueryWorkers = 4
+
+// startQueries starts running a route's named queries, such as stats.sql.hbs beside
+// get.sql.hbs, and returns the func that waits for their rows by query name. They run
+// concurrently with the route's own SQL but outside its transaction, as reads. A query
+// that fails is logged and left empty so the page still renders.
+func startQueries(ctx context.Context, queries []*parser.Route, requestData map[string]any, appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) func() map[string]any {
+	results := make(map[string]any, len(queries))
+	if len(queries) == 0 {
+		return func() map[string]any { return results }
+	}
+
+	// Handlers may change the request data while the queries render it
+	requestData = maps.Clone(requestData)
+
+	var mutex sync.Mutex
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		forEachConcurrently(maxQueryWorkers, len(queries), func(i int) {
+			query := queries[i]
+			var rows any = []map[string]any{}
+			data, err := executeSQL(queryCache(ctx, query.Query), query, requestData, appConfig, frameworkServer)
+			if err != nil {
+				log.Printf("❌ Query %s failed, leaving it empty: %v", query.Query, err)
+			} else {
+				rows = data
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			results[query.Query] = rows
+		})
+	}()
+
+	return func() map[string]any {
+		<-done
+		return results
+	}
+}
+
+// forEachConcurrently calls fn with each index below count, at most workers at a time,
+// and returns once every call has
+func forEachConcurrently(workers, count int, fn func(i int)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, count) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := range count {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// withQueryResults serves a route's own results beside its named queries' as
+// {"data": ..., "stats": ..., "recent": ...}, or data alone when it has none
+func withQueryResults(data any, results map[string]any) any {
+	if len(results) == 0 {
+		return data
+	}
+	combined := maps.Clone(results)
+	combined["data"] = data
+	return combined
+}
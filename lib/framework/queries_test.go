@@ -0,0 +1,148 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fulcrum/lib/database"
+	lang_adapters "fulcrum/lib/lang/adapters"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+func TestForEachConcurrently(t *testing.T) {
+	const workers, count = 2, 6
+
+	var mutex sync.Mutex
+	var inFlight, peak int
+	var called [count]atomic.Bool
+	var once sync.Once
+	bothRunning := make(chan struct{})
+
+	forEachConcurrently(workers, count, func(i int) {
+		mutex.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		if inFlight == workers {
+			once.Do(func() { close(bothRunning) })
+		}
+		mutex.Unlock()
+
+		// Hold the first calls until another runs beside them
+		select {
+		case <-bothRunning:
+		case <-time.After(time.Second):
+		}
+		mutex.Lock()
+		inFlight--
+		mutex.Unlock()
+		called[i].Store(true)
+	})
+
+	if got := peak; got != workers {
+		t.Errorf("Expected %d calls at once, got %d", workers, got)
+	}
+	for i := range called {
+		if !called[i].Load() {
+			t.Errorf("Expected call %d to run", i)
+		}
+	}
+}
+
+func TestNamedQueries(t *testing.T) {
+	t.Setenv("FULCRUM_ENV", "production")
+
+	db := newSQLiteDB(t,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT)",
+		"INSERT INTO posts (id, title) VALUES (1, 'first'), (2, 'second')",
+	)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"get.json.hbs":   "{{{json this}}}",
+		"get.sql.hbs":    "SELECT * FROM posts ORDER BY id",
+		"stats.sql.hbs":  "SELECT COUNT(*) AS total FROM posts",
+		"recent.sql.hbs": "SELECT * FROM missing_table",
+	}
+	for file, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const link = "/posts"
+	jsonRoute := parser.Route{Link: link, Method: "GET", Format: "json", ViewPath: filepath.Join(dir, "get.json.hbs")}
+	domain := parser.DomainConfig{Name: "posts"}
+	domain.Logic.HTTP.Routes = []parser.Route{
+		jsonRoute,
+		{Link: link, Method: "GET", Format: "sql", ViewPath: filepath.Join(dir, "get.sql.hbs")},
+		{Link: link, Method: "GET", Format: "sql", Query: "stats", ViewPath: filepath.Join(dir, "stats.sql.hbs")},
+		{Link: link, Method: "GET", Format: "sql", Query: "recent", ViewPath: filepath.Join(dir, "recent.sql.hbs")},
+	}
+	appConfig := &parser.AppConfig{Domains: []parser.DomainConfig{domain}, Views: views.NewTemplateRenderer()}
+	server := &lang_adapters.FrameworkServer{Db: db, DbExecutor: database.NewDatabaseExecutor(db)}
+
+	rec := httptest.NewRecorder()
+	handleJSONRoute(rec, httptest.NewRequest(http.MethodGet, link, nil), jsonRoute, map[string]any{}, appConfig, server)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected a failed named query to leave the route answering 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Data   []map[string]any `json:"data"`
+		Stats  []map[string]any `json:"stats"`
+		Recent []map[string]any `json:"recent"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Data) != 2 {
+		t.Errorf("Expected the route's own rows as data, got %v", body.Data)
+	}
+	if len(body.Stats) != 1 || body.Stats[0]["total"] != float64(2) {
+		t.Errorf("Expected the stats query's rows, got %v", body.Stats)
+	}
+	if body.Recent == nil || len(body.Recent) != 0 {
+		t.Errorf("Expected the failed recent query to be empty, got %v", body.Recent)
+	}
+
+	t.Run("handler keys are kept", func(t *testing.T) {
+		RegisterHandler("posts", "index", func(ctx context.Context, sqlData any, requestData map[string]any) (any, error) {
+			return map[string]any{"stats": "mine"}, nil
+		})
+		t.Cleanup(func() { UnregisterHandler("posts", "index") })
+
+		handlerDomain := domain
+		handlerDomain.Logic.HTTP.Routes = []parser.Route{jsonRoute, domain.Logic.HTTP.Routes[2]}
+		handlerConfig := &parser.AppConfig{Domains: []parser.DomainConfig{handlerDomain}, Views: views.NewTemplateRenderer()}
+
+		rec := httptest.NewRecorder()
+		handleJSONRoute(rec, httptest.NewRequest(http.MethodGet, link, nil), jsonRoute, map[string]any{}, handlerConfig, server)
+		var body map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body["stats"] != "mine" {
+			t.Errorf("Expected the handler's stats kept over the query's, got %v", body["stats"])
+		}
+	})
+
+	t.Run("results sit beside the route's own", func(t *testing.T) {
+		rows := []map[string]any{{"id": 1}}
+		combined, ok := withQueryResults(rows, map[string]any{"stats": []map[string]any{}}).(map[string]any)
+		if !ok || combined["stats"] == nil || len(combined["data"].([]map[string]any)) != 1 {
+			t.Errorf("Expected data and stats, got %v", combined)
+		}
+		if data := withQueryResults(rows, map[string]any{}); len(data.([]map[string]any)) != 1 {
+			t.Errorf("Expected data unchanged without named queries, got %v", data)
+		}
+	})
+}
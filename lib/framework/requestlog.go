@@ -0,0 +1,133 @@
+package framework
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"fulcrum/lib/middleware"
+)
+
+// requestLogSize is how many recent requests the dev dashboard shows
+const requestLogSize = 100
+
+// Request stages timed for the dev dashboard
+const (
+	StageSQL     = "sql"
+	StageHandler = "handler"
+	StageRender  = "render"
+)
+
+// StageTiming is how long one stage of a request took
+type StageTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+// RequestLogEntry is one request served by a domain route
+type RequestLogEntry struct {
+	Time     time.Time
+	Method   string
+	Path     string
+	Pattern  string
+	Status   int
+	Duration time.Duration
+	Stages   []StageTiming
+}
+
+// RequestLog keeps the most recent requests in a ring buffer
+type RequestLog struct {
+	mu      sync.Mutex
+	entries []RequestLogEntry
+	next    int
+	full    bool
+}
+
+// NewRequestLog keeps the last size requests
+func NewRequestLog(size int) *RequestLog {
+	return &RequestLog{entries: make([]RequestLogEntry, size)}
+}
+
+// Add records a request, replacing the oldest once the log is full
+func (l *RequestLog) Add(entry RequestLogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Recent returns the logged requests, newest first
+func (l *RequestLog) Recent() []RequestLogEntry {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	count := l.next
+	if l.full {
+		count = len(l.entries)
+	}
+	recent := make([]RequestLogEntry, 0, count)
+	for i := 1; i <= count; i++ {
+		recent = append(recent, l.entries[(l.next-i+len(l.entries))%len(l.entries)])
+	}
+	return recent
+}
+
+// stagesContextKey holds the *requestStages of a logged request
+type stagesContextKey struct{}
+
+// requestStages collects a request's stage timings; the handler may outlive a timeout
+type requestStages struct {
+	mu     sync.Mutex
+	stages []StageTiming
+}
+
+// timeStage starts timing a stage of the request in ctx, returning the func that ends
+// it; requests that are not logged are not timed
+func timeStage(ctx context.Context, name string) func() {
+	stages, ok := ctx.Value(stagesContextKey{}).(*requestStages)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		stages.mu.Lock()
+		defer stages.mu.Unlock()
+		stages.stages = append(stages.stages, StageTiming{Name: name, Duration: time.Since(start)})
+	}
+}
+
+// logRequests adds each request served by next to requests, with its stage timings
+func logRequests(requests *RequestLog, pattern string, next http.HandlerFunc) http.HandlerFunc {
+	if requests == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		stages := &requestStages{}
+		recorder := &middleware.StatusRecorder{ResponseWriter: w}
+		next(recorder, r.WithContext(context.WithValue(r.Context(), stagesContextKey{}, stages)))
+
+		status := recorder.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		stages.mu.Lock()
+		timings := append([]StageTiming(nil), stages.stages...)
+		stages.mu.Unlock()
+		requests.Add(RequestLogEntry{
+			Time:     start,
+			Method:   r.Method,
+			Path:     r.URL.RequestURI(),
+			Pattern:  pattern,
+			Status:   status,
+			Duration: time.Since(start),
+			Stages:   timings,
+		})
+	}
+}
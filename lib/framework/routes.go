@@ -0,0 +1,245 @@
+package framework
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	parser "fulcrum/lib/parser"
+)
+
+// Access levels reported in the route table
+const (
+	AccessPublic = "public"
+	AccessLogin  = "login"
+)
+
+// RouteEntry is one method and pattern in the resolved routing table
+type RouteEntry struct {
+	Method        string               `json:"method"`
+	Pattern       string               `json:"pattern"`    // As discovered, e.g. /users/[users_id]
+	GoPattern     string               `json:"go_pattern"` // As registered with http.ServeMux, e.g. /users/{users_id}
+	Domain        string               `json:"domain"`
+	HTMLTemplate  string               `json:"html_template,omitempty"`
+	JSONTemplate  string               `json:"json_template,omitempty"`
+	XMLTemplate   string               `json:"xml_template,omitempty"`
+	SQLTemplate   string               `json:"sql_template,omitempty"`
+	Queries       []string             `json:"queries,omitempty"` // Named query templates
+	Redirect      *parser.RedirectRule `json:"redirect,omitempty"`
+	Access        string               `json:"access"`         // public or login
+	Root          bool                 `json:"root,omitempty"` // Also served at /
+	Transactional bool                 `json:"transactional,omitempty"`
+	Specificity   int                  `json:"specificity"`
+	SkipReason    string               `json:"skip_reason,omitempty"` // Why the route is not registered
+
+	group RouteGroup
+}
+
+// RouteTable is the routing table CreateRouteDispatcher registers
+type RouteTable struct {
+	Routes  []RouteEntry `json:"routes"`  // Registered routes, most specific first
+	Skipped []RouteEntry `json:"skipped"` // Routes that are not registered, with the reason
+}
+
+// BuildRouteTable groups the app's routes by method and pattern and orders them the way
+// the dispatcher registers them
+func BuildRouteTable(appConfig *parser.AppConfig) RouteTable {
+	// Group routes by method and pattern; SQL routes only supply data to HTML and JSON routes
+	groups := make(map[string]RouteGroup)
+	for _, domain := range appConfig.Domains {
+		for _, route := range domain.Logic.HTTP.Routes {
+			key := fmt.Sprintf("%s %s", route.Method, route.Link)
+
+			group := groups[key]
+			group.Domain = domain.Name
+			group.Method = route.Method
+			group.Pattern = route.Link
+
+			switch route.Format {
+			case "html":
+				group.HTMLRoute = &route
+			case "json":
+				group.JSONRoute = &route
+			case "xml":
+				group.XMLRoute = &route
+			case "sql":
+				if route.Query != "" {
+					group.Queries = append(group.Queries, &route)
+				} else {
+					group.SQLRoute = &route
+				}
+			}
+
+			groups[key] = group
+		}
+	}
+	addXMLAliases(groups)
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var table RouteTable
+	var candidates []RouteEntry
+	for _, key := range keys {
+		entry := newRouteEntry(groups[key], appConfig)
+		if entry.group.HTMLRoute == nil && entry.group.JSONRoute == nil && entry.group.XMLRoute == nil {
+			entry.SkipReason = "no HTML or JSON template found"
+			table.Skipped = append(table.Skipped, entry)
+			continue
+		}
+		candidates = append(candidates, entry)
+	}
+
+	// More specific routes first, so /users/[user_id] is registered before /users
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Specificity > candidates[j].Specificity
+	})
+
+	registered := make(map[string]string)
+	for _, entry := range candidates {
+		entry.Root = appConfig.Root != "" && entry.Pattern == appConfig.Root
+
+		routeKey := fmt.Sprintf("%s %s", entry.Method, entry.GoPattern)
+		if existing, ok := registered[routeKey]; ok {
+			entry.SkipReason = fmt.Sprintf("duplicate of %s %s", entry.Method, existing)
+			table.Skipped = append(table.Skipped, entry)
+			continue
+		}
+		registered[routeKey] = entry.Pattern
+		table.Routes = append(table.Routes, entry)
+	}
+
+	return table
+}
+
+// addXMLAliases also serves each GET route with an XML template at its path plus .xml,
+// e.g. /feed.xml for domains/feed/get.xml.hbs, unless the path ends in a parameter or
+// another route already has it
+func addXMLAliases(groups map[string]RouteGroup) {
+	for _, group := range groups {
+		if group.XMLRoute == nil || group.Method != http.MethodGet || strings.HasSuffix(group.Pattern, ".xml") {
+			continue
+		}
+		segments := strings.Split(group.Pattern, "/")
+		if last := segments[len(segments)-1]; strings.HasPrefix(last, ":") || strings.HasPrefix(last, "[") {
+			continue
+		}
+
+		alias := RouteGroup{
+			Domain:   group.Domain,
+			Method:   group.Method,
+			Pattern:  group.Pattern + ".xml",
+			XMLRoute: group.XMLRoute,
+			SQLRoute: group.SQLRoute,
+			Queries:  group.Queries,
+		}
+		key := fmt.Sprintf("%s %s", alias.Method, alias.Pattern)
+		if _, exists := groups[key]; !exists {
+			groups[key] = alias
+		}
+	}
+}
+
+// newRouteEntry describes a route group for the table
+func newRouteEntry(group RouteGroup, appConfig *parser.AppConfig) RouteEntry {
+	entry := RouteEntry{
+		Method:        group.Method,
+		Pattern:       group.Pattern,
+		GoPattern:     convertToGoServeMuxPattern(group.Pattern),
+		Domain:        group.Domain,
+		Access:        AccessLogin,
+		Specificity:   calculateRouteSpecificity(group.Pattern),
+		Transactional: group.transactional(),
+		group:         group,
+	}
+	if appConfig.Auth.Disabled || group.Domain == "auth" {
+		entry.Access = AccessPublic
+	}
+
+	if group.HTMLRoute != nil {
+		entry.HTMLTemplate = group.HTMLRoute.ViewPath
+	}
+	if group.JSONRoute != nil {
+		entry.JSONTemplate = group.JSONRoute.ViewPath
+	}
+	if group.XMLRoute != nil {
+		entry.XMLTemplate = group.XMLRoute.ViewPath
+	}
+	if group.SQLRoute != nil {
+		entry.SQLTemplate = group.SQLRoute.ViewPath
+	}
+	for _, query := range group.Queries {
+		entry.Queries = append(entry.Queries, query.ViewPath)
+	}
+	for _, route := range []*parser.Route{group.HTMLRoute, group.JSONRoute, group.XMLRoute, group.SQLRoute} {
+		if route != nil && route.Redirect.To != "" {
+			redirect := route.Redirect
+			entry.Redirect = &redirect
+			break
+		}
+	}
+
+	return entry
+}
+
+// RootRoute returns the route served at /, or nil when no root is configured
+func (rt RouteTable) RootRoute(appConfig *parser.AppConfig) *RouteEntry {
+	if appConfig.Root == "" || len(rt.Routes) == 0 {
+		return nil
+	}
+	for _, entries := range [][]RouteEntry{rt.Routes, rt.Skipped} {
+		for i := range entries {
+			if entries[i].Root {
+				return &entries[i]
+			}
+		}
+	}
+	// Without a matching pattern the most specific route is served
+	return &rt.Routes[0]
+}
+
+// goPatternParam matches the wildcards of an http.ServeMux pattern
+var goPatternParam = regexp.MustCompile(`\{([^}.]+)(?:\.\.\.)?\}`)
+
+// Match returns the route http.ServeMux would pick for method and path, with its path
+// parameters, or nil when the request would not match a route
+func (rt RouteTable) Match(appConfig *parser.AppConfig, method, path string) (*RouteEntry, map[string]string) {
+	var matched *RouteEntry
+	var params map[string]string
+
+	mux := http.NewServeMux()
+	for i := range rt.Routes {
+		entry := &rt.Routes[i]
+		mux.HandleFunc(fmt.Sprintf("%s %s", entry.Method, entry.GoPattern), func(w http.ResponseWriter, r *http.Request) {
+			matched = entry
+			params = make(map[string]string)
+			for _, name := range goPatternParam.FindAllStringSubmatch(entry.GoPattern, -1) {
+				params[name[1]] = r.PathValue(name[1])
+			}
+		})
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			matched = rt.RootRoute(appConfig)
+		}
+	})
+
+	req, err := http.NewRequest(strings.ToUpper(method), path, nil)
+	if err != nil {
+		return nil, nil
+	}
+	mux.ServeHTTP(discardResponseWriter{}, req)
+	return matched, params
+}
+
+// discardResponseWriter lets Match run a ServeMux without writing a response
+type discardResponseWriter struct{}
+
+func (discardResponseWriter) Header() http.Header         { return http.Header{} }
+func (discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (discardResponseWriter) WriteHeader(int)             {}
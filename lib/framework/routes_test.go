@@ -0,0 +1,127 @@
+package framework
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	parser "fulcrum/lib/parser"
+)
+
+// loadRoutesFixture loads the app in testdata/routes_app
+func loadRoutesFixture(t *testing.T) *parser.AppConfig {
+	t.Helper()
+	appConfig, err := parser.GetAppConfig(filepath.Join("testdata", "routes_app"))
+	if err != nil {
+		t.Fatalf("Failed to load fixture app: %v", err)
+	}
+	return &appConfig
+}
+
+func TestBuildRouteTable(t *testing.T) {
+	appConfig := loadRoutesFixture(t)
+	table := BuildRouteTable(appConfig)
+
+	var registered []string
+	for _, entry := range table.Routes {
+		registered = append(registered, entry.Method+" "+entry.GoPattern)
+	}
+	expected := []string{
+		"GET /api/status",
+		"GET /auth/login",
+		"POST /users/new",
+		"GET /users/{users_id}",
+		"GET /users",
+		"GET /docs/{path...}",
+	}
+	if !reflect.DeepEqual(registered, expected) {
+		t.Errorf("Expected routes in specificity order %v, got %v", expected, registered)
+	}
+
+	byKey := make(map[string]RouteEntry)
+	for _, entry := range table.Routes {
+		byKey[entry.Method+" "+entry.Pattern] = entry
+	}
+
+	users := byKey["GET /users"]
+	if !users.Root || users.Domain != "users" || users.Access != AccessLogin {
+		t.Errorf("Expected /users to be the login-only root route, got %+v", users)
+	}
+	if filepath.Base(users.HTMLTemplate) != "get.html.hbs" || filepath.Base(users.SQLTemplate) != "get.sql.hbs" {
+		t.Errorf("Expected html and sql templates for /users, got %+v", users)
+	}
+
+	if len(users.Queries) != 1 || filepath.Base(users.Queries[0]) != "stats.sql.hbs" {
+		t.Errorf("Expected stats.sql.hbs as a named query of /users, got %v", users.Queries)
+	}
+
+	if user := byKey["GET /users/:users_id"]; user.Pattern != "/users/:users_id" || user.GoPattern != "/users/{users_id}" {
+		t.Errorf("Expected both pattern forms for the user route, got %+v", user)
+	}
+
+	create := byKey["POST /users/new"]
+	if create.Redirect == nil || create.Redirect.To != "/users" || create.Redirect.Status != 303 {
+		t.Errorf("Expected the redirect rule on POST /users/new, got %+v", create.Redirect)
+	}
+	if len(create.Queries) != 0 {
+		t.Errorf("Expected SQL files beside a route without GET to be left as partials, got %v", create.Queries)
+	}
+	if !create.Transactional || users.Transactional {
+		t.Errorf("Expected only POST /users/new to be transactional from its route.yaml")
+	}
+
+	if login := byKey["GET /auth/login"]; login.Access != AccessPublic {
+		t.Errorf("Expected auth routes to be public, got %s", login.Access)
+	}
+	if status := byKey["GET /api/status"]; status.HTMLTemplate != "" || status.JSONTemplate == "" {
+		t.Errorf("Expected /api/status to be served from its JSON template, got %+v", status)
+	}
+
+	if len(table.Skipped) != 1 {
+		t.Fatalf("Expected one skipped route, got %+v", table.Skipped)
+	}
+	if skipped := table.Skipped[0]; skipped.Pattern != "/users/export" || skipped.SkipReason != "no HTML or JSON template found" {
+		t.Errorf("Expected /users/export to be skipped for lacking a template, got %+v", skipped)
+	}
+}
+
+func TestRouteTableMatch(t *testing.T) {
+	appConfig := loadRoutesFixture(t)
+	table := BuildRouteTable(appConfig)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		pattern string
+		params  map[string]string
+	}{
+		{name: "literal beats parameter", method: "POST", path: "/users/new", pattern: "/users/new", params: map[string]string{}},
+		{name: "parameter", method: "GET", path: "/users/42", pattern: "/users/:users_id", params: map[string]string{"users_id": "42"}},
+		{name: "method decides between routes", method: "GET", path: "/users/new", pattern: "/users/:users_id", params: map[string]string{"users_id": "new"}},
+		{name: "catch-all", method: "GET", path: "/docs/guides/setup", pattern: "/docs/:...path", params: map[string]string{"path": "guides/setup"}},
+		{name: "root", method: "GET", path: "/", pattern: "/users"},
+		{name: "no route", method: "GET", path: "/missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, params := table.Match(appConfig, tt.method, tt.path)
+			if tt.pattern == "" {
+				if entry != nil {
+					t.Errorf("Expected no match, got %s %s", entry.Method, entry.Pattern)
+				}
+				return
+			}
+			if entry == nil {
+				t.Fatalf("Expected %s to match %s, got no match", tt.path, tt.pattern)
+			}
+			if entry.Pattern != tt.pattern {
+				t.Errorf("Expected %s, got %s", tt.pattern, entry.Pattern)
+			}
+			if tt.params != nil && !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("Expected params %v, got %v", tt.params, params)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package framework
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"fulcrum/lib/database/interfaces"
+	lang_adapters "fulcrum/lib/lang/adapters"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+// filterParamPattern matches ?filter[column]=value query parameters
+var filterParamPattern = regexp.MustCompile(`^filter\[(.*)\]$`)
+
+// addSearchConditions turns ?q= and ?filter[column]=value into the conditions the
+// search_where helper renders. q matches the domain's search columns and every filter
+// must name a model field; values are only ever bound as parameters. Requests without
+// either leave requestData untouched, so their SQL renders as before.
+func addSearchConditions(r *http.Request, domain *parser.DomainConfig, driver interfaces.DatabaseDriver, requestData map[string]any) error {
+	query := r.URL.Query()
+	var conditions []string
+
+	if q := strings.TrimSpace(query.Get("q")); q != "" && domain != nil && len(domain.Search.Columns) > 0 {
+		like := "LIKE"
+		if driver == interfaces.DriverPostgreSQL {
+			like = "ILIKE"
+		}
+		matches := make([]string, len(domain.Search.Columns))
+		for i, column := range domain.Search.Columns {
+			matches[i] = fmt.Sprintf("%s %s :_search", column, like)
+		}
+		conditions = append(conditions, "("+strings.Join(matches, " OR ")+")")
+		requestData["_search"] = "%" + q + "%"
+	}
+
+	// Sorted so the same filters always render the same SQL
+	var columns []string
+	for key := range query {
+		match := filterParamPattern.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+		column := match[1]
+		if !parser.ColumnNamePattern.MatchString(column) || domain == nil || !domain.HasField(column) {
+			return fmt.Errorf("unknown filter column %q", column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		param := "_filter_" + column
+		conditions = append(conditions, fmt.Sprintf("%s = :%s", column, param))
+		requestData[param] = query.Get("filter[" + column + "]")
+	}
+
+	if len(conditions) > 0 {
+		requestData[views.SearchWhereKey] = strings.Join(conditions, " AND ")
+	}
+	return nil
+}
+
+// databaseDriver returns the driver of the framework's database, or "" without one
+func databaseDriver(frameworkServer *lang_adapters.FrameworkServer) interfaces.DatabaseDriver {
+	if frameworkServer == nil || frameworkServer.DbExecutor == nil {
+		return ""
+	}
+	return frameworkServer.DbExecutor.Driver()
+}
@@ -0,0 +1,162 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fulcrum/lib/database"
+	"fulcrum/lib/database/interfaces"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+// searchDomain is a posts domain that searches title and body
+var searchDomain = &parser.DomainConfig{
+	Name: "posts",
+	Models: []parser.ModelDefinition{{
+		"post": parser.Model{
+			"title":  parser.Field{Type: "string"},
+			"body":   parser.Field{Type: "text"},
+			"status": parser.Field{Type: "string"},
+		},
+	}},
+	Search: parser.SearchConfig{Columns: []string{"title", "body"}},
+}
+
+func TestAddSearchConditions(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		driver   interfaces.DatabaseDriver
+		where    string
+		params   map[string]any
+		wantErr  bool
+		noSearch bool
+	}{
+		{name: "no search", query: "", noSearch: true},
+		{name: "blank q", query: "?q=++", noSearch: true},
+		{
+			name:   "q on sqlite",
+			query:  "?q=go",
+			driver: interfaces.DriverSQLite,
+			where:  "(title LIKE :_search OR body LIKE :_search)",
+			params: map[string]any{"_search": "%go%"},
+		},
+		{
+			name:   "q on postgres",
+			query:  "?q=go",
+			driver: interfaces.DriverPostgreSQL,
+			where:  "(title ILIKE :_search OR body ILIKE :_search)",
+			params: map[string]any{"_search": "%go%"},
+		},
+		{
+			name:   "q and filters",
+			query:  "?q=go&filter[status]=draft&filter[id]=3",
+			where:  "(title LIKE :_search OR body LIKE :_search) AND id = :_filter_id AND status = :_filter_status",
+			params: map[string]any{"_search": "%go%", "_filter_id": "3", "_filter_status": "draft"},
+		},
+		{
+			name:   "injection in q is bound",
+			query:  "?q=%27%3B+DROP+TABLE+posts%3B+--",
+			where:  "(title LIKE :_search OR body LIKE :_search)",
+			params: map[string]any{"_search": "%'; DROP TABLE posts; --%"},
+		},
+		{name: "unknown filter column", query: "?filter[password]=x", wantErr: true},
+		{name: "injection in filter key", query: "?filter[status%3D1+OR+1]=1", wantErr: true},
+		{name: "empty filter key", query: "?filter[]=1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string]any{}
+			err := addSearchConditions(httptest.NewRequest(http.MethodGet, "/posts"+tt.query, nil), searchDomain, tt.driver, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			where, ok := data[views.SearchWhereKey]
+			if tt.noSearch {
+				if ok || len(data) != 0 {
+					t.Errorf("Expected request data untouched, got %v", data)
+				}
+				return
+			}
+			if where != tt.where {
+				t.Errorf("Expected conditions %q, got %q", tt.where, where)
+			}
+			for name, value := range tt.params {
+				if data[name] != value {
+					t.Errorf("Expected %s=%q, got %q", name, value, data[name])
+				}
+			}
+		})
+	}
+}
+
+func TestSearchConditionsBindValues(t *testing.T) {
+	db := newSQLiteDB(t,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT, body TEXT, status TEXT)",
+		"INSERT INTO posts (title, body, status) VALUES ('Hello Go', 'first', 'draft'), ('Other', 'second', 'published')",
+	)
+	ctx := context.Background()
+	executor := database.NewDatabaseExecutor(db)
+
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{"?q=hello", 1},
+		{"?q=%27+OR+%271%27%3D%271", 0},
+		{"?q=%27%3B+DROP+TABLE+posts%3B+--", 0},
+		{"?filter[status]=published", 1},
+		{"?filter[status]=draft%27+OR+%271%27%3D%271", 0},
+		{"?q=o&filter[status]=draft", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			data := map[string]any{}
+			if err := addSearchConditions(httptest.NewRequest(http.MethodGet, "/posts"+tt.query, nil), searchDomain, executor.Driver(), data); err != nil {
+				t.Fatal(err)
+			}
+
+			resultJSON, err := executor.ExecuteSQL(ctx, "SELECT * FROM posts WHERE "+data[views.SearchWhereKey].(string), data, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var response database.OperationResponse
+			if err := json.Unmarshal(resultJSON, &response); err != nil {
+				t.Fatal(err)
+			}
+			if !response.Success {
+				t.Fatalf("Query failed: %s", response.Error)
+			}
+			if response.Count != tt.expected {
+				t.Errorf("Expected %d rows, got %d", tt.expected, response.Count)
+			}
+		})
+	}
+
+	if exists, err := db.TableExists(ctx, "posts"); err != nil || !exists {
+		t.Errorf("Expected the posts table to survive, got exists=%v err=%v", exists, err)
+	}
+}
+
+func TestUnknownFilterColumnReturnsBadRequest(t *testing.T) {
+	route := parser.Route{Link: "/posts", Method: "GET", Format: "html"}
+	appConfig := &parser.AppConfig{Domains: []parser.DomainConfig{*searchDomain}}
+	group := RouteGroup{Pattern: route.Link, Method: "GET", Domain: "posts", HTMLRoute: &route}
+
+	rec := httptest.NewRecorder()
+	handleHTMLRouteWithProcessManager(rec, httptest.NewRequest(http.MethodGet, "/posts?filter[password]=x", nil), group, appConfig, nil)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
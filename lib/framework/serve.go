@@ -0,0 +1,314 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"fulcrum/lib/auth"
+	"fulcrum/lib/database"
+	"fulcrum/lib/database/interfaces"
+	"fulcrum/lib/i18n"
+	lang_adapters "fulcrum/lib/lang/adapters"
+	"fulcrum/lib/metrics"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+// DefaultHTTPAddr is where the HTTP server listens unless ServeOptions.Addr is set
+const DefaultHTTPAddr = ":8080"
+
+// ServeOptions configures Serve
+type ServeOptions struct {
+	// Addr is the HTTP listen address; defaults to DefaultHTTPAddr
+	Addr string
+	// Dev enables the development renderer, hot reloading and verbose logging
+	Dev bool
+	// NoHandlers skips starting the JavaScript handler service
+	NoHandlers bool
+	// Mock serves placeholder rows for SQL routes without connecting to the database
+	Mock bool
+}
+
+// verbose turns on debugf output; Serve sets it in dev mode
+var verbose atomic.Bool
+
+// debugf logs details that are only useful while developing
+func debugf(format string, args ...any) {
+	if verbose.Load() {
+		log.Printf(format, args...)
+	}
+}
+
+// Serve runs the HTTP and gRPC servers with the handler process manager until ctx is cancelled
+func Serve(ctx context.Context, appConfig *parser.AppConfig, opts ServeOptions) error {
+	if opts.Addr == "" {
+		opts.Addr = DefaultHTTPAddr
+	}
+	if opts.Dev {
+		appConfig.Mode = "develop"
+	}
+	verbose.Store(opts.Dev)
+
+	// Validate message routing before connecting to anything
+	messageRouter, err := lang_adapters.NewMessageRouterFromConfig(appConfig)
+	if err != nil {
+		return fmt.Errorf("invalid message routing in fulcrum.yml:\n%w", err)
+	}
+	if err := validateDomainMiddleware(appConfig); err != nil {
+		return fmt.Errorf("invalid domain middleware:\n%w", err)
+	}
+
+	// Export traces when enabled in fulcrum.yml
+	defer startTracing(appConfig)()
+
+	// Database setup; mock mode runs without one
+	var db interfaces.Database
+	var dbExecutor *database.DatabaseExecutor
+	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if opts.Mock {
+		appConfig.Mock = true
+		log.Printf("🎭 Mock mode: SQL routes serve placeholder rows, not a database")
+	} else {
+		dbConfig, err := database.FromParserConfig(appConfig.DB)
+		if err != nil {
+			return fmt.Errorf("invalid database configuration: %w", err)
+		}
+
+		replicaConfigs, err := database.ReplicasFromParserConfig(appConfig.DB)
+		if err != nil {
+			return fmt.Errorf("invalid database replica configuration: %w", err)
+		}
+
+		dbManager, err := database.NewManager(dbConfig, replicaConfigs...)
+		if err != nil {
+			return fmt.Errorf("failed to create database manager: %w", err)
+		}
+
+		if err := dbManager.Connect(connectCtx); err != nil {
+			return fmt.Errorf("failed to connect to the database: %w", err)
+		}
+		defer dbManager.Close()
+
+		db = dbManager.GetDatabase()
+		metrics.RegisterDBStats(db.Stats)
+
+		dbExecutor = database.NewDatabaseExecutor(db)
+		dbExecutor.EnableStatementCache(appConfig.DB.StatementCacheSize)
+		dbExecutor.SetRetryPolicy(database.RetryPolicyFromConfig(appConfig.DB))
+		dbExecutor.SetSlowQueryThreshold(database.SlowQueryThresholdFromConfig(appConfig.DB))
+		dbExecutor.SetLockColumns(lockColumns(appConfig))
+		if replicas := dbManager.Replicas(); replicas != nil {
+			dbExecutor.SetReplica(replicas)
+		}
+		// Tables with a deleted_at column are soft deleted; without the list they never are
+		if err := dbExecutor.LoadSoftDeleteTables(connectCtx); err != nil {
+			log.Printf("⚠️ Soft deletes disabled: %v", err)
+		}
+		// Changes to the tables listed under audit are recorded in audit_log
+		if err := dbExecutor.EnableAudit(connectCtx, appConfig.Audit); err != nil {
+			log.Printf("⚠️ Auditing disabled: %v", err)
+		}
+		defer dbExecutor.Close()
+	}
+
+	// Login sessions live in signed cookies or the sessions table
+	sessionStore, err := auth.NewSessionStore(appConfig.Auth.Sessions, dbExecutor)
+	if err != nil {
+		return fmt.Errorf("invalid auth configuration: %w", err)
+	}
+	auth.SetSessionStore(sessionStore)
+
+	// Tenancy resolves each request's tenant; which tables hold tenant rows is read once
+	tenants, err := newTenancy(connectCtx, appConfig, dbExecutor)
+	if err != nil {
+		return fmt.Errorf("invalid tenancy configuration: %w", err)
+	}
+
+	// Framework Server Setup with Process Manager
+	frameworkServer := &lang_adapters.FrameworkServer{
+		Db:                db,
+		DbExecutor:        dbExecutor,
+		DomainStreams:     make(map[string]lang_adapters.FrameworkService_DomainCommunicationServer),
+		PendingRequests:   make(map[string]*lang_adapters.PendingRequest),
+		OutboundQueueSize: appConfig.DomainQueueSize,
+		OutboundQueueTTL:  time.Duration(appConfig.DomainQueueTTL) * time.Second,
+		Router:            messageRouter,
+	}
+	frameworkServer.StartCleanupRoutine()
+	metrics.RegisterPendingRequests(frameworkServer.PendingCount)
+
+	// Initialize Process Manager for JavaScript handlers
+	if !opts.NoHandlers {
+		if err := frameworkServer.InitializeProcessManager(appConfig.Path, opts.Dev); err != nil {
+			log.Printf("Warning: Failed to initialize process manager: %v", err)
+		}
+		if appConfig.HandlerTimeout > 0 && frameworkServer.ProcessManager != nil {
+			frameworkServer.ProcessManager.SetHandlerTimeout(time.Duration(appConfig.HandlerTimeout) * time.Second)
+		}
+	}
+
+	// Reload handlers on change in dev mode without restarting the servers
+	if watcher := startHandlerWatcher(appConfig, frameworkServer); watcher != nil {
+		defer watcher.Stop()
+	}
+
+	// Translations for the t helper
+	catalog, err := LoadTranslations(appConfig)
+	if err != nil {
+		return err
+	}
+	i18n.SetCatalog(catalog)
+
+	// Template setup
+	var renderer views.Renderer
+	if opts.Dev {
+		renderer, err = views.SetupViewsForDevelopment(appConfig)
+	} else {
+		renderer, err = views.SetupViewsFromConfig(appConfig)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to setup views: %w", err)
+	}
+	appConfig.Views = renderer
+
+	if appConfig.IsDevelopment() {
+		if err := setupHotReloading(appConfig); err != nil {
+			log.Printf("Warning: Could not setup hot reloading: %v", err)
+		}
+	}
+
+	// Preload templates and validate routes
+	if err := appConfig.PreloadAndValidateRoutes(); err != nil {
+		return fmt.Errorf("route templates could not be loaded:\n%w", err)
+	}
+
+	// Listen before printing the banner so a taken port fails fast
+	grpcListener, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		return fmt.Errorf("failed to listen on port 50051: %w", err)
+	}
+	httpListener, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		grpcListener.Close()
+		return fmt.Errorf("failed to listen on %s: %w", opts.Addr, err)
+	}
+	metricsServer, err := startMetricsServer(appConfig.Metrics)
+	if err != nil {
+		grpcListener.Close()
+		httpListener.Close()
+		return err
+	}
+
+	grpcServer := newGRPCServer(frameworkServer)
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Printf("gRPC server error: %v", err)
+		}
+	}()
+
+	mux := CreateRouteDispatcher(appConfig, frameworkServer)
+	if !appConfig.Auth.Disabled {
+		auth.AddLoginRoute(mux, frameworkServer)
+	}
+	httpServer := &http.Server{Handler: tenants.Wrap(mux)}
+	go func() {
+		if err := httpServer.Serve(httpListener); err != nil && err != http.ErrServerClosed {
+			log.Printf("HTTP server error: %v", err)
+		}
+	}()
+
+	printBanner(appConfig, opts, httpListener.Addr(), frameworkServer)
+	logRegisteredRoutes(appConfig)
+
+	log.Println("Application ready. Press Ctrl+C to shutdown.")
+	<-ctx.Done()
+
+	log.Println("Shutting down servers...")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+
+	// Shutdown HTTP server
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP server shutdown error: %v", err)
+	}
+
+	// Shutdown metrics server
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Metrics server shutdown error: %v", err)
+		}
+	}
+
+	// Shutdown gRPC server
+	grpcServer.GracefulStop()
+
+	// Stop process manager
+	if frameworkServer.ProcessManager != nil {
+		if err := frameworkServer.ProcessManager.StopAll(); err != nil {
+			log.Printf("Process manager shutdown error: %v", err)
+		}
+	}
+
+	log.Println("Servers gracefully stopped.")
+	return nil
+}
+
+// printBanner prints a short summary of what is being served
+func printBanner(appConfig *parser.AppConfig, opts ServeOptions, addr net.Addr, frameworkServer *lang_adapters.FrameworkServer) {
+	mode := "production"
+	if appConfig.IsDevelopment() {
+		mode = "development"
+	}
+
+	routes := 0
+	for _, domain := range appConfig.Domains {
+		routes += len(domain.Logic.HTTP.Routes)
+	}
+
+	port := DefaultHTTPAddr[1:]
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+		port = fmt.Sprint(tcpAddr.Port)
+	}
+
+	fmt.Printf("🚀 Fulcrum serving %s on http://localhost:%s (%s)\n", appConfig.Path, port, mode)
+	fmt.Printf("   Domains:  %d\n", len(appConfig.Domains))
+	fmt.Printf("   Routes:   %d\n", routes)
+	fmt.Printf("   Database: %s\n", appConfig.DB.Driver)
+	fmt.Printf("   Handlers: %s\n", handlerRuntime(opts, frameworkServer))
+	fmt.Println("   gRPC:     :50051")
+	if appConfig.Metrics.Enabled {
+		fmt.Printf("   Metrics:  %s\n", metricsLocation(appConfig.Metrics, port))
+	}
+	if appConfig.IsDevelopment() {
+		fmt.Printf("   Dashboard: http://localhost:%s/_fulcrum/\n", port)
+	}
+	fmt.Println()
+}
+
+// handlerRuntime describes the state of the JavaScript handler service for the banner
+func handlerRuntime(opts ServeOptions, frameworkServer *lang_adapters.FrameworkServer) string {
+	switch {
+	case opts.NoHandlers:
+		return "disabled (--no-handlers)"
+	case frameworkServer.ProcessManager == nil || !frameworkServer.ProcessManager.IsHandlerServiceRunning():
+		return "none"
+	default:
+		return fmt.Sprintf("node on :%d", frameworkServer.ProcessManager.GetHandlerConfig().Port)
+	}
+}
+
+// logRegisteredRoutes lists every route at debug level
+func logRegisteredRoutes(appConfig *parser.AppConfig) {
+	for _, domain := range appConfig.Domains {
+		debugf("Domain '%s' (%s):", domain.Name, domain.Path)
+		for _, route := range domain.Logic.HTTP.Routes {
+			debugf("  %s %s -> %s (format: %s)", route.Method, route.Link, route.ViewPath, route.Format)
+		}
+	}
+}
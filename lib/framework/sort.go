@@ -0,0 +1,90 @@
+package framework
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+// sortIndicators mark the direction of sorted columns in the view model
+var sortIndicators = map[string]string{"asc": "▲", "desc": "▼"}
+
+// addSortOrder turns ?sort=column[,column...]&dir=asc|desc[,...] into the ORDER BY the
+// order_by helper renders, and returns the sort state templates use to draw headers.
+// Each column takes the dir at the same position, or the last one given. Columns the
+// domain does not allow are skipped with a warning rather than failing the request.
+func addSortOrder(r *http.Request, domain *parser.DomainConfig, requestData map[string]any) map[string]any {
+	if domain == nil {
+		return nil
+	}
+	query := r.URL.Query()
+	allowed := domain.SortableColumns()
+
+	var dirs []string
+	if dir := query.Get("dir"); dir != "" {
+		dirs = strings.Split(dir, ",")
+	}
+
+	var sorted []map[string]any
+	var clauses []string
+	indicators := make(map[string]string)
+	for i, column := range strings.Split(query.Get("sort"), ",") {
+		column = strings.TrimSpace(column)
+		if _, seen := indicators[column]; column == "" || seen {
+			continue
+		}
+		if !slices.Contains(allowed, column) || !parser.ColumnNamePattern.MatchString(column) {
+			log.Printf("⚠️ Ignoring sort by %q: not a sortable column of %s", column, domain.Name)
+			continue
+		}
+
+		dir := "asc"
+		if len(dirs) > 0 {
+			dir = strings.ToLower(strings.TrimSpace(dirs[min(i, len(dirs)-1)]))
+		}
+		if _, ok := sortIndicators[dir]; !ok {
+			log.Printf("⚠️ Ignoring sort direction %q for %s: expected asc or desc", dir, column)
+			dir = "asc"
+		}
+
+		sorted = append(sorted, map[string]any{"column": column, "dir": dir})
+		clauses = append(clauses, column+" "+strings.ToUpper(dir))
+		indicators[column] = sortIndicators[dir]
+	}
+	if len(clauses) > 0 {
+		requestData[views.OrderByKey] = strings.Join(clauses, ", ")
+	}
+
+	state := map[string]any{
+		"columns":    sorted,
+		"links":      sortLinks(r, allowed, sorted),
+		"indicators": indicators,
+	}
+	if len(sorted) > 0 {
+		state["column"] = sorted[0]["column"]
+		state["dir"] = sorted[0]["dir"]
+	}
+	return state
+}
+
+// sortLinks returns, per sortable column, the current URL sorted by that column alone.
+// The link for the primary sort column flips its direction; every other query
+// parameter (page, q, filters) is kept.
+func sortLinks(r *http.Request, columns []string, sorted []map[string]any) map[string]string {
+	links := make(map[string]string, len(columns))
+	for _, column := range columns {
+		dir := "asc"
+		if len(sorted) > 0 && sorted[0]["column"] == column && sorted[0]["dir"] == "asc" {
+			dir = "desc"
+		}
+		values := r.URL.Query()
+		values.Set("sort", column)
+		values.Set("dir", dir)
+		links[column] = r.URL.Path + "?" + values.Encode()
+	}
+	return links
+}
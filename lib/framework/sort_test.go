@@ -0,0 +1,116 @@
+package framework
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+func TestAddSortOrder(t *testing.T) {
+	restricted := *searchDomain
+	restricted.Sort = parser.SortConfig{Columns: []string{"title"}}
+
+	tests := []struct {
+		name    string
+		domain  *parser.DomainConfig
+		query   string
+		orderBy string
+		warning string
+	}{
+		{name: "no sort", domain: searchDomain, query: ""},
+		{name: "single column", domain: searchDomain, query: "?sort=title", orderBy: "title ASC"},
+		{name: "descending", domain: searchDomain, query: "?sort=title&dir=DESC", orderBy: "title DESC"},
+		{name: "id is always sortable", domain: searchDomain, query: "?sort=id&dir=desc", orderBy: "id DESC"},
+		{name: "multi-column", domain: searchDomain, query: "?sort=status,title&dir=desc,asc", orderBy: "status DESC, title ASC"},
+		{name: "last dir repeats", domain: searchDomain, query: "?sort=status,title&dir=desc", orderBy: "status DESC, title DESC"},
+		{name: "duplicate column", domain: searchDomain, query: "?sort=title,title", orderBy: "title ASC"},
+		{name: "unknown column ignored", domain: searchDomain, query: "?sort=password,title", orderBy: "title ASC", warning: `Ignoring sort by "password"`},
+		{name: "injection ignored", domain: searchDomain, query: "?sort=title%3B+DROP+TABLE+posts", warning: "Ignoring sort by"},
+		{name: "bad dir falls back to asc", domain: searchDomain, query: "?sort=title&dir=sideways", orderBy: "title ASC", warning: `Ignoring sort direction "sideways"`},
+		{name: "explicit sortable list", domain: &restricted, query: "?sort=status,title", orderBy: "title ASC", warning: `Ignoring sort by "status"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			previous := log.Writer()
+			log.SetOutput(&logs)
+			data := map[string]any{}
+			state := addSortOrder(httptest.NewRequest(http.MethodGet, "/posts"+tt.query, nil), tt.domain, data)
+			log.SetOutput(previous)
+
+			orderBy, _ := data[views.OrderByKey].(string)
+			if orderBy != tt.orderBy {
+				t.Errorf("Expected ORDER BY %q, got %q", tt.orderBy, orderBy)
+			}
+			if tt.warning != "" && !strings.Contains(logs.String(), tt.warning) {
+				t.Errorf("Expected warning %q, got %q", tt.warning, logs.String())
+			}
+			if tt.warning == "" && strings.Contains(logs.String(), "Ignoring") {
+				t.Errorf("Expected no warning, got %q", logs.String())
+			}
+			if state == nil {
+				t.Fatal("Expected a sort state")
+			}
+		})
+	}
+}
+
+func TestSortLinksPreserveQuery(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/posts?q=go&page=2&filter[status]=draft&sort=title&dir=asc", nil)
+	state := addSortOrder(r, searchDomain, map[string]any{})
+
+	if state["column"] != "title" || state["dir"] != "asc" {
+		t.Errorf("Expected title asc as the current sort, got %v %v", state["column"], state["dir"])
+	}
+	indicators := state["indicators"].(map[string]string)
+	if indicators["title"] != "▲" || indicators["status"] != "" {
+		t.Errorf("Expected only title to be marked ascending, got %v", indicators)
+	}
+
+	links := state["links"].(map[string]string)
+	tests := []struct {
+		column string
+		dir    string
+	}{
+		{"title", "desc"}, // The current sort flips
+		{"status", "asc"},
+		{"id", "asc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			link, ok := links[tt.column]
+			if !ok {
+				t.Fatalf("Expected a link for %s, got %v", tt.column, links)
+			}
+			parsed, err := url.Parse(link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Path != "/posts" {
+				t.Errorf("Expected the link to stay on /posts, got %s", parsed.Path)
+			}
+			expected := url.Values{
+				"q":              {"go"},
+				"page":           {"2"},
+				"filter[status]": {"draft"},
+				"sort":           {tt.column},
+				"dir":            {tt.dir},
+			}
+			if got := parsed.Query(); got.Encode() != expected.Encode() {
+				t.Errorf("Expected query %s, got %s", expected.Encode(), got.Encode())
+			}
+		})
+	}
+
+	if _, ok := links["password"]; ok {
+		t.Error("Expected no link for a column the domain does not declare")
+	}
+}
@@ -4,19 +4,24 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"fulcrum/lib/auth"
 	"fulcrum/lib/database"
-	"fulcrum/lib/database/interfaces"
+	"fulcrum/lib/i18n"
+	"fulcrum/lib/metrics"
+	"fulcrum/lib/middleware"
 	parser "fulcrum/lib/parser"
+	"fulcrum/lib/tracing"
 	"fulcrum/lib/views"
 	"log"
+	"maps"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"regexp"
-	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -85,6 +90,25 @@ func setHTMXResponseHeaders(w http.ResponseWriter, options map[string]string) {
 	}
 }
 
+// htmxDataKeys maps top-level template data keys to the setHTMXResponseHeaders key,
+// and so the response header, they set:
+//
+//	redirect_to   -> redirect -> HX-Redirect
+//	htmx_trigger  -> trigger  -> HX-Trigger
+//	htmx_reswap   -> reswap   -> HX-Reswap
+//	htmx_retarget -> retarget -> HX-Retarget
+//	htmx_reselect -> reselect -> HX-Reselect
+//
+// Any header setHTMXResponseHeaders supports can also be set through an
+// "htmx_response" map keyed by its name, e.g. {"push-url": "/users"}.
+var htmxDataKeys = map[string]string{
+	"redirect_to":   "redirect",
+	"htmx_trigger":  "trigger",
+	"htmx_reswap":   "reswap",
+	"htmx_retarget": "retarget",
+	"htmx_reselect": "reselect",
+}
+
 // extractHTMXHeaders extracts HTMX response headers from template data
 func extractHTMXHeaders(data any) map[string]string {
 	headers := make(map[string]string)
@@ -102,15 +126,9 @@ func extractHTMXHeaders(data any) map[string]string {
 		}
 
 		// Check for common response patterns
-		if redirect, exists := dataMap["redirect_to"]; exists {
-			if redirectStr, ok := redirect.(string); ok {
-				headers["redirect"] = redirectStr
-			}
-		}
-
-		if trigger, exists := dataMap["htmx_trigger"]; exists {
-			if triggerStr, ok := trigger.(string); ok {
-				headers["trigger"] = triggerStr
+		for dataKey, headerKey := range htmxDataKeys {
+			if value, ok := dataMap[dataKey].(string); ok {
+				headers[headerKey] = value
 			}
 		}
 	}
@@ -129,8 +147,9 @@ func extractBodyContent(html string) string {
 	return html // Return as-is if no body tags found
 }
 
-// wrapInLayout wraps content in the main layout
-func wrapInLayout(content string, data any, renderer *views.TemplateRenderer) (string, error) {
+// wrapInLayout wraps content in the main layout. When strict is set a layout failure is
+// returned as an error; otherwise the bare content is served and the failure is counted.
+func wrapInLayout(content string, data any, renderer views.Renderer, strict bool) (string, error) {
 	layoutData := map[string]any{
 		"body": content,
 	}
@@ -145,6 +164,10 @@ func wrapInLayout(content string, data any, renderer *views.TemplateRenderer) (s
 
 	html, err := renderer.Render("layouts/main", layoutData)
 	if err != nil {
+		if strict {
+			return "", fmt.Errorf("failed to render layout: %w", err)
+		}
+		metrics.LayoutRenderFailures.Inc()
 		log.Printf("⚠️ Layout render failed, returning content directly: %v", err)
 		return content, nil
 	}
@@ -152,18 +175,53 @@ func wrapInLayout(content string, data any, renderer *views.TemplateRenderer) (s
 	return html, nil
 }
 
+// healthTimeout bounds the database ping made by /health
+const healthTimeout = 2 * time.Second
+
+// healthHandler reports OK, or 503 when the database does not answer a ping
+func healthHandler(frameworkServer *lang_adapters.FrameworkServer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("🏥 Health check: %s %s", r.Method, r.URL.Path)
+		now := time.Now().Format("2006-01-02 15:04:05")
+
+		if frameworkServer != nil && frameworkServer.Db != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), healthTimeout)
+			defer cancel()
+			if err := frameworkServer.Db.Ping(ctx); err != nil {
+				log.Printf("🏥 Health check failed, database ping: %v", err)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprintf(w, "Status: UNAVAILABLE\nDatabase: unreachable\nTime: %s\n", now)
+				return
+			}
+		}
+
+		fmt.Fprintf(w, "Status: OK\nTime: %s\n", now)
+	}
+}
+
 // CreateRouteDispatcher creates the main HTTP route multiplexer with HTMX support
 func CreateRouteDispatcher(appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) *http.ServeMux {
 	mux := http.NewServeMux()
 
-	// Track registered routes to avoid conflicts
-	registeredRoutes := make(map[string]bool)
-
 	// Health check handler
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("🏥 Health check: %s %s", r.Method, r.URL.Path)
-		fmt.Fprintf(w, "Status: OK\nTime: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	})
+	mux.HandleFunc("/health", healthHandler(frameworkServer))
+
+	// Development tooling endpoints, with the requests the dashboard lists
+	var requests *RequestLog
+	if appConfig.IsDevelopment() {
+		requests = NewRequestLog(requestLogSize)
+	}
+	registerDevRoutes(mux, appConfig, frameworkServer, requests)
+
+	// Prometheus metrics, unless they are served on their own admin address
+	if appConfig.Metrics.Enabled && appConfig.Metrics.Address == "" {
+		mux.Handle("GET "+appConfig.Metrics.MetricsPath(), metricsHandler(appConfig.Metrics))
+	}
+
+	// API description of the json routes while developing
+	if appConfig.IsDevelopment() {
+		mux.HandleFunc("GET /openapi.json", openAPIHandler(appConfig))
+	}
 
 	// HTMX static assets handler
 	mux.HandleFunc("GET /htmx.min.js", func(w http.ResponseWriter, r *http.Request) {
@@ -173,81 +231,24 @@ func CreateRouteDispatcher(appConfig *parser.AppConfig, frameworkServer *lang_ad
 		http.Redirect(w, r, "https://unpkg.com/htmx.org@1.9.10/dist/htmx.min.js", http.StatusMovedPermanently)
 	})
 
-	// Group routes by method and pattern, but only register HTML routes
-	// SQL routes are used internally for data fetching
-	routeGroups := make(map[string]RouteGroup)
+	// One limiter covers every route, so a client's budget is app-wide
+	rateLimit := rateLimitMiddleware(appConfig.Server.RateLimit)
 
-	for _, domain := range appConfig.Domains {
-		for _, route := range domain.Logic.HTTP.Routes {
-			key := fmt.Sprintf("%s %s", route.Method, route.Link)
-
-			group := routeGroups[key]
-			group.Domain = domain.Name
-			group.Method = route.Method
-			group.Pattern = route.Link
-
-			if route.Format == "html" {
-				group.HTMLRoute = &route
-			} else if route.Format == "sql" {
-				group.SQLRoute = &route
-			}
+	// Routes with a cache: block share one store, which writes to their domain invalidate
+	cacheStore := newCacheStore(appConfig)
 
-			routeGroups[key] = group
-		}
-	}
-
-	// Sort routes by specificity (more specific routes first)
-	// This ensures /users/[user_id] is registered before /users
-	type routeInfo struct {
-		key         string
-		group       RouteGroup
-		specificity int
-	}
-
-	var sortedRoutes []routeInfo
-	for key, group := range routeGroups {
-		if group.HTMLRoute == nil {
-			log.Printf("⚠️ Skipping route %s - no HTML template found", key)
-			continue
-		}
-
-		// Calculate specificity: more path segments and fewer parameters = higher specificity
-		specificity := calculateRouteSpecificity(group.Pattern)
-		sortedRoutes = append(sortedRoutes, routeInfo{
-			key:         key,
-			group:       group,
-			specificity: specificity,
-		})
-	}
-
-	// Sort by specificity (higher specificity first)
-	sort.Slice(sortedRoutes, func(i, j int) bool {
-		return sortedRoutes[i].specificity > sortedRoutes[j].specificity
-	})
-
-	rootGroup := sortedRoutes[0].group
+	// Middleware listed in a domain's fulcrum.yml wraps each of its routes
+	domainChains := domainMiddlewareChains(appConfig)
 
 	// Register routes in order of specificity
-	for _, routeInfo := range sortedRoutes {
-		group := routeInfo.group
-		if group.Pattern == appConfig.Root {
-			rootGroup = group
-			rootGroup.Pattern = "/"
-		}
-
-		// Convert [param] syntax to Go's {param} syntax for ServeMux
-		goPattern := convertToGoServeMuxPattern(group.Pattern)
-		routeKey := fmt.Sprintf("%s %s", group.Method, goPattern)
-
-		// Check if this route is already registered
-		if registeredRoutes[routeKey] {
-			log.Printf("⏭️ Skipping duplicate route: %s (already registered)", routeKey)
-			continue
-		}
+	table := BuildRouteTable(appConfig)
+	for _, entry := range table.Routes {
+		group := entry.group
+		goPattern := entry.GoPattern
 
-		log.Printf("📝 Registering: %s %s -> %s (domain: %s, html: %s, sql: %s)",
+		debugf("📝 Registering: %s %s -> %s (domain: %s, html: %s, sql: %s)",
 			group.Method, group.Pattern, goPattern, group.Domain,
-			group.HTMLRoute.View,
+			group.primaryRoute().View,
 			func() string {
 				if group.SQLRoute != nil {
 					return group.SQLRoute.View
@@ -255,16 +256,13 @@ func CreateRouteDispatcher(appConfig *parser.AppConfig, frameworkServer *lang_ad
 				return "none"
 			}())
 
-		// Mark this route as registered
-		registeredRoutes[routeKey] = true
-
 		// Capture variables in closure
 		capturedGroup := group
 
 		// Create handler function for this pattern with HTMX support
 		handlerFunc := func(w http.ResponseWriter, r *http.Request) {
 			// Skip authentication check for auth domain routes - they handle auth themselves
-			if capturedGroup.Domain != "auth" && !auth.IsAuthenticated(r) {
+			if !appConfig.Auth.Disabled && capturedGroup.Domain != "auth" && !auth.IsAuthenticated(r) {
 				log.Printf("🔍 Request: %s %s has been redirected to login", r.Method, r.URL.Path)
 				http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
 				return
@@ -289,28 +287,78 @@ func CreateRouteDispatcher(appConfig *parser.AppConfig, frameworkServer *lang_ad
 			requestedFormat := determineRequestedFormat(r)
 			log.Printf("🎯 Requested format: %s", requestedFormat)
 
-			// Handle based on the requested format
-			if requestedFormat == "json" {
-				// Extract request data for JSON handling
-				requestData := extractRequestData(r, *capturedGroup.HTMLRoute)
-				handleJSONRoute(w, r, *capturedGroup.HTMLRoute, requestData, appConfig, frameworkServer)
+			// Handle based on the requested format (API-only routes always respond with JSON)
+			servesXML := capturedGroup.servesXML(requestedFormat)
+			if servesXML || requestedFormat == "json" || capturedGroup.HTMLRoute == nil {
+				// Extract request data for JSON and XML handling
+				route := *capturedGroup.primaryRoute()
+				if servesXML {
+					route = *capturedGroup.XMLRoute
+				}
+				domainConfig, _ := appConfig.GetDomain(capturedGroup.Domain)
+				requestData, err := extractRequestData(r, route, domainConfig)
+				if err != nil {
+					log.Printf("❌ Invalid request data: %v", err)
+					writeRequestDataError(w, route.Format, err)
+					return
+				}
+				if err := addSearchConditions(r, domainConfig, databaseDriver(frameworkServer), requestData); err != nil {
+					log.Printf("❌ Invalid filter: %v", err)
+					writeRequestDataError(w, route.Format, err)
+					return
+				}
+				addSortOrder(r, domainConfig, requestData)
+				if !scopeToTenant(w, r, appConfig, capturedGroup.Domain, requestData) {
+					return
+				}
+				if servesXML {
+					handleXMLRoute(w, r, route, requestData, appConfig, frameworkServer)
+				} else {
+					handleJSONRoute(w, r, route, requestData, appConfig, frameworkServer)
+				}
 			} else {
 				// Handle HTML/HTMX requests
 				handleHTMLRouteWithProcessManager(w, r, capturedGroup, appConfig, frameworkServer)
 			}
 		}
 
-		// Register the handler with Go's pattern syntax
-		mux.HandleFunc(fmt.Sprintf("%s %s", group.Method, goPattern), handlerFunc)
+		// Wrap the handler innermost first: the audit actor and read-your-writes pinning
+		// see the request last, after the route cache, uploads and the domain's middleware
+		handler := withAuditActor(appConfig, handlerFunc)
+		handler = withReadYourWrites(handler)
+		handler = withCache(cacheStore, group, handler)
+		handler = withUploads(handler, appConfig)
+		handler = withDomainMiddleware(domainChains, group.Domain, handler)
+		handler = middleware.RecoverFunc(handler, appConfig.IsDevelopment())
+
+		// Register the handler with Go's pattern syntax, bounding each request
+		pattern := group.Pattern
+		bounded := middleware.TimeoutFunc(handler,
+			func(r *http.Request) time.Duration { return requestTimeout(r, appConfig) },
+			func(w http.ResponseWriter, r *http.Request) {
+				log.Printf("⏱️ Request timed out: %s %s", r.Method, r.URL.Path)
+				metrics.HTTPTimeouts.Inc(pattern)
+				writeTimeout(w, r, capturedGroup)
+			})
+		if rateLimit != nil {
+			bounded = rateLimit(bounded).ServeHTTP
+		}
+		instrumented := metrics.InstrumentFunc(group.Pattern, tracing.HandlerFunc(group.Pattern, bounded))
+		mux.HandleFunc(fmt.Sprintf("%s %s", group.Method, goPattern), logRequests(requests, group.Pattern, instrumented))
+	}
+
+	for _, entry := range table.Skipped {
+		debugf("⚠️ Skipping route %s %s - %s", entry.Method, entry.Pattern, entry.SkipReason)
 	}
 
 	// Catch-all for debugging unmatched routes
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/" {
-			if appConfig.Root != "" {
-				handleHTMLRouteWithProcessManager(w, r, rootGroup, appConfig, frameworkServer)
-				return
-			}
+	rootRoute := table.RootRoute(appConfig)
+	mux.HandleFunc("/", metrics.InstrumentFunc("/", tracing.HandlerFunc("/", middleware.RecoverFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" && rootRoute != nil {
+			rootGroup := rootRoute.group
+			rootGroup.Pattern = "/"
+			handleHTMLRouteWithProcessManager(w, r, rootGroup, appConfig, frameworkServer)
+			return
 		}
 
 		if appConfig.Mode == "develop" {
@@ -320,12 +368,11 @@ func CreateRouteDispatcher(appConfig *parser.AppConfig, frameworkServer *lang_ad
 			fmt.Fprintf(w, "No route found for %s %s\n\n", r.Method, r.URL.Path)
 			fmt.Fprintf(w, "Available routes:\n")
 
-			for _, routeInfo := range sortedRoutes {
-				group := routeInfo.group
-				goPattern := convertToGoServeMuxPattern(group.Pattern)
+			for _, entry := range table.Routes {
+				group := entry.group
 				fmt.Fprintf(w, "  %s %s -> %s (html: %s, sql: %s)\n",
-					group.Method, goPattern, group.Pattern,
-					group.HTMLRoute.View,
+					group.Method, entry.GoPattern, group.Pattern,
+					group.primaryRoute().View,
 					func() string {
 						if group.SQLRoute != nil {
 							return group.SQLRoute.View
@@ -334,7 +381,7 @@ func CreateRouteDispatcher(appConfig *parser.AppConfig, frameworkServer *lang_ad
 					}())
 			}
 		}
-	})
+	}, appConfig.IsDevelopment()))))
 
 	return mux
 }
@@ -399,13 +446,32 @@ func convertHtmxStructToMap(data any) any {
 	return data // Return original data if not a map or no _htmx struct
 }
 
+// htmxTemplateReplacer names the HTMX override of a route's HTML template, e.g.
+// get.htmx.hbs for get.html.hbs
+var htmxTemplateReplacer = strings.NewReplacer(".html.hbs", ".htmx.hbs", ".html.tmpl", ".htmx.tmpl")
+
 func handleHTMLRouteWithProcessManager(w http.ResponseWriter, r *http.Request, group RouteGroup, appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) {
 	log.Printf("Processing route: %s %s", group.Method, group.Pattern)
 
 	// Parse HTMX headers
 	htmxReq := parseHTMXHeaders(r)
 
-	requestData := extractRequestData(r, *group.HTMLRoute)
+	domainConfig, _ := appConfig.GetDomain(group.Domain)
+	requestData, err := extractRequestData(r, *group.HTMLRoute, domainConfig)
+	if err != nil {
+		log.Printf("❌ Invalid request data: %v", err)
+		http.Error(w, err.Error(), requestDataStatus(err))
+		return
+	}
+	if err := addSearchConditions(r, domainConfig, databaseDriver(frameworkServer), requestData); err != nil {
+		log.Printf("❌ Invalid filter: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sortState := addSortOrder(r, domainConfig, requestData)
+	if !scopeToTenant(w, r, appConfig, group.Domain, requestData) {
+		return
+	}
 
 	// Add HTMX context to request data
 	requestData["htmx"] = map[string]any{
@@ -418,46 +484,98 @@ func handleHTMLRouteWithProcessManager(w http.ResponseWriter, r *http.Request, g
 
 	var templateData any = requestData
 
-	// Step 1: Execute SQL if exists
-	if group.SQLRoute != nil {
-		log.Printf("Executing SQL template: %s", group.SQLRoute.View)
-		sqlData, err := executeSQL(group.SQLRoute, requestData, appConfig, frameworkServer)
-		if err != nil {
-			log.Printf("SQL execution failed: %v", err)
-		} else {
-			templateData = sqlData
-			log.Printf("SQL data retrieved successfully")
+	ctx := r.Context()
+	waitQueries := startQueries(ctx, group.Queries, requestData, appConfig, frameworkServer)
+
+	// Steps 1 and 2 share a transaction on transactional routes, where any failure rolls back
+	transactional := group.transactional()
+	sqlFailed, notFound := false, false
+	err = withRouteTransaction(ctx, transactional, frameworkServer, func(ctx context.Context) error {
+		// Step 1: Execute SQL if exists
+		if group.SQLRoute != nil {
+			log.Printf("Executing SQL template: %s", group.SQLRoute.View)
+			sqlData, err := executeSQL(ctx, group.SQLRoute, requestData, appConfig, frameworkServer)
+			if isTimeout(err) {
+				return fmt.Errorf("SQL execution timed out: %w", err)
+			} else if errors.Is(err, errNoDatabase) || errors.Is(err, errStaleRecord) || (err != nil && transactional) {
+				return fmt.Errorf("SQL execution failed: %w", err)
+			} else if err != nil {
+				log.Printf("SQL execution failed: %v", err)
+				sqlFailed = true
+			} else {
+				templateData = sqlData
+				notFound = requireRecord(group.Method, group.Pattern, sqlData) != nil
+				log.Printf("SQL data retrieved successfully")
+			}
 		}
-	}
+		templateData = withQueryResults(templateData, waitQueries())
 
-	// Step 2: Execute JavaScript handler if available
-	if frameworkServer.ProcessManager != nil && frameworkServer.ProcessManager.IsHandlerServiceRunning() {
-		domain := group.Domain
+		// Step 2: Execute Go or JavaScript handler if available
 		action := extractActionFromRoute(group.Pattern, group.Method)
-		log.Printf("Executing handler: %s.%s", domain, action)
-
-		// Convert htmx struct to map for protobuf compatibility
-		safeTemplateData := convertHtmxStructToMap(templateData)
-		safeRequestData := convertHtmxStructToMap(requestData).(map[string]any)
-
-		processedData, err := frameworkServer.ProcessManager.ExecuteHandler(domain, action, safeTemplateData, safeRequestData)
-
-		if err != nil {
+		processedData, handled, err := runDomainHandler(ctx, group.Domain, action, templateData, requestData, frameworkServer)
+		if isTimeout(err) {
+			return fmt.Errorf("handler execution timed out: %w", err)
+		} else if !handled {
+			log.Printf("Handler service not available, skipping handler execution")
+		} else if err != nil && transactional {
+			return fmt.Errorf("handler execution failed: %w", err)
+		} else if err != nil {
 			log.Printf("Handler execution failed: %v", err)
 		} else {
 			templateData = processedData
 			log.Printf("Handler processing completed successfully")
 		}
-	} else {
-		log.Printf("Handler service not available, skipping handler execution")
+		return nil
+	})
+	if isTimeout(err) {
+		log.Printf("⏱️ %v", err)
+		http.Error(w, "Request timed out", http.StatusGatewayTimeout)
+		return
+	} else if errors.Is(err, errNoDatabase) {
+		log.Printf("❌ %v", err)
+		http.Error(w, fmt.Sprintf("%v; %s", err, noDatabaseHint), http.StatusInternalServerError)
+		return
+	} else if errors.Is(err, errStaleRecord) {
+		log.Printf("⚠️ Update conflict on %s: %v", group.Pattern, err)
+		renderConflict(ctx, w, r, group, htmxReq, requestData, appConfig, frameworkServer)
+		return
+	} else if err != nil {
+		log.Printf("❌ Request rolled back: %v", err)
+		http.Error(w, "Request failed", http.StatusInternalServerError)
+		return
+	}
+
+	// Honor handler-provided redirects instead of rendering
+	if redirectURL, status, ok := lang_adapters.CheckForRedirect(templateData); ok {
+		log.Printf("🔀 Handler redirect to: %s (%d)", redirectURL, status)
+		if htmxReq.IsHTMX {
+			w.Header().Set("HX-Redirect", redirectURL)
+			w.WriteHeader(http.StatusOK)
+		} else {
+			http.Redirect(w, r, redirectURL, status)
+		}
+		return
 	}
 
+	// Handler control keys never reach the template
+	responseStatus := extractResponseStatus(templateData)
+	if responseStatus == 0 && notFound {
+		// The page still renders, so templates can show their not-found branch
+		log.Printf("🔍 No record for %s %s", r.Method, r.URL.Path)
+		responseStatus = http.StatusNotFound
+	}
+	var fragments []string
+	if htmxReq.IsHTMX {
+		fragments = selectFragments(group.HTMLRoute, templateData)
+	}
+	templateData = stripHandlerControlKeys(templateData)
+
 	// Step 3: Determine template path with HTMX override support
 	templatePath := group.HTMLRoute.ViewPath
 
 	// Check for HTMX-specific template override
 	if htmxReq.IsHTMX {
-		htmxTemplatePath := strings.Replace(templatePath, ".html.hbs", ".htmx.hbs", 1)
+		htmxTemplatePath := htmxTemplateReplacer.Replace(templatePath)
 		if _, err := os.Stat(htmxTemplatePath); err == nil {
 			templatePath = htmxTemplatePath
 			log.Printf("🎯 Using HTMX-specific template: %s", templatePath)
@@ -473,11 +591,21 @@ func handleHTMLRouteWithProcessManager(w http.ResponseWriter, r *http.Request, g
 			"domain":     group.Domain,
 			"group":      group,
 			"htmx":       htmxReq,
+			"search":     r.URL.Query().Get("q"),
+			"sort":       sortState,
+			"tenant":     requestData[tenantKey],
 		},
+		views.LocaleKey: i18n.ResolveLocale(r),
 	}
 
 	// Step 5: Render template with HTMX-aware logic
-	html, err := loadAndRenderHTMXTemplate(templatePath, viewModel, appConfig.Views, htmxReq.IsHTMX)
+	stopRender := timeStage(ctx, StageRender)
+	_, renderSpan := tracing.Start(ctx, "template.render")
+	tracing.SetString(renderSpan, "template", templatePath)
+	html, err := loadAndRenderHTMXTemplate(templatePath, fragments, viewModel, appConfig.Views, htmxReq.IsHTMX, appConfig.StrictLayoutEnabled())
+	tracing.RecordError(renderSpan, err)
+	renderSpan.End()
+	stopRender()
 	if err != nil {
 		log.Printf("Template render failed: %v", err)
 		http.Error(w, "Template error", http.StatusInternalServerError)
@@ -488,42 +616,105 @@ func handleHTMLRouteWithProcessManager(w http.ResponseWriter, r *http.Request, g
 	htmxHeaders := extractHTMXHeaders(templateData)
 	setHTMXResponseHeaders(w, htmxHeaders)
 
-	// Step 7: Handle redirects for successful form submissions (non-HTMX only)
-	if (r.Method == "POST" || r.Method == "PUT" || r.Method == "PATCH") && !htmxReq.IsHTMX {
-		if dataArray, ok := templateData.([]map[string]any); ok && len(dataArray) > 0 {
-			if id, exists := dataArray[0]["id"]; exists {
-				redirectURL := buildShowURL(group.Pattern, id)
-				log.Printf("🔀 Redirecting to: %s", redirectURL)
-				http.Redirect(w, r, redirectURL, http.StatusSeeOther)
-				return
+	// Step 7: Handle redirects for form submissions, configured ones first
+	if r.Method == "POST" || r.Method == "PUT" || r.Method == "PATCH" {
+		if redirectURL, status, ok := configuredRedirect(group, templateData, requestData, sqlFailed); ok {
+			log.Printf("🔀 Redirecting to: %s (%d)", redirectURL, status)
+			if htmxReq.IsHTMX {
+				w.Header().Set("HX-Redirect", redirectURL)
+				w.WriteHeader(http.StatusOK)
+			} else {
+				http.Redirect(w, r, redirectURL, status)
 			}
+			return
+		}
+	}
+	if (r.Method == "POST" || r.Method == "PUT" || r.Method == "PATCH") && !htmxReq.IsHTMX {
+		if dataArray, ok := templateData.([]map[string]any); ok && len(dataArray) > 0 && dataArray[0]["id"] != nil {
+			redirectURL := buildShowURL(group, appConfig, dataArray[0]["id"])
+			log.Printf("🔀 Redirecting to: %s", redirectURL)
+			http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+			return
+		}
+		if r.Method == "POST" {
+			log.Printf("⚠️ POST %s returned no id and has no redirect.yaml, so the page is rendered instead of redirecting; add RETURNING id to the INSERT or a redirect.yaml", group.Pattern)
 		}
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if responseStatus != 0 {
+		w.WriteHeader(responseStatus)
+	}
 	w.Write([]byte(html))
 }
 
-// loadAndRenderHTMXTemplate renders templates with HTMX-specific logic
-func loadAndRenderHTMXTemplate(templatePath string, data any, renderer *views.TemplateRenderer, isHTMXRequest bool) (string, error) {
-	pathHash := fmt.Sprintf("%x", sha256.Sum256([]byte(templatePath)))
-	templateName := fmt.Sprintf("route_%s", pathHash[:16])
+// extractResponseStatus returns the handler-provided "_status" code, or 0 when absent
+func extractResponseStatus(data any) int {
+	dataMap, ok := data.(map[string]any)
+	if !ok {
+		return 0
+	}
 
-	content, err := renderer.Render(templateName, data)
-	if err != nil {
-		// Fallback: load template dynamically
-		log.Printf("⚠️ Template %s not preloaded, loading dynamically: %s", templateName, templatePath)
+	status := 0
+	switch v := dataMap["_status"].(type) {
+	case int:
+		status = v
+	case int32:
+		status = int(v)
+	case int64:
+		status = int(v)
+	case float64:
+		status = int(v)
+	case string:
+		status, _ = strconv.Atoi(v)
+	}
 
-		tempName := fmt.Sprintf("temp_%d", time.Now().UnixNano())
-		if loadErr := renderer.LoadTemplate(tempName, templatePath); loadErr != nil {
-			return "", fmt.Errorf("failed to load template: %w", loadErr)
+	if status < 100 || status > 599 {
+		return 0
+	}
+	return status
+}
+
+// handlerControlKeys are handler result keys that direct the framework, not the template
+var handlerControlKeys = map[string]bool{"_redirect": true, "_status": true, cacheInvalidateKey: true, fragmentsKey: true}
+
+// stripHandlerControlKeys removes "_redirect", "_status", "_cache_invalidate" and
+// "_fragments" from handler result data
+func stripHandlerControlKeys(data any) any {
+	dataMap, ok := data.(map[string]any)
+	if !ok {
+		return data
+	}
+
+	hasControlKey := false
+	for k := range handlerControlKeys {
+		if _, ok := dataMap[k]; ok {
+			hasControlKey = true
 		}
+	}
+	if !hasControlKey {
+		return data
+	}
 
-		content, err = renderer.Render(tempName, data)
-		if err != nil {
-			return "", fmt.Errorf("failed to render template: %w", err)
+	cleaned := make(map[string]any, len(dataMap))
+	for k, v := range dataMap {
+		if !handlerControlKeys[k] {
+			cleaned[k] = v
 		}
 	}
+	return cleaned
+}
+
+// loadAndRenderHTMXTemplate renders templates with HTMX-specific logic: HTMX requests
+// get the bare template followed by its out-of-band fragments, others the page in its
+// layout
+func loadAndRenderHTMXTemplate(templatePath string, fragments []string, data any, renderer views.Renderer, isHTMXRequest bool, strictLayout bool) (string, error) {
+	defer metrics.ObserveSince(metrics.TemplateRenderDuration, time.Now(), templatePath)
+
+	content, err := renderRouteTemplate(templatePath, data, renderer)
+	if err != nil {
+		return "", err
+	}
 
 	contentTrimmed := strings.TrimSpace(content)
 	isCompleteDocument := strings.HasPrefix(strings.ToLower(contentTrimmed), "<!doctype html") ||
@@ -533,11 +724,11 @@ func loadAndRenderHTMXTemplate(templatePath string, data any, renderer *views.Te
 		// For HTMX requests, always return content without layout
 		if isCompleteDocument {
 			log.Printf("⚠️ HTMX request received full document, extracting body content")
-			return extractBodyContent(content), nil
+			content = extractBodyContent(content)
 		} else {
 			log.Printf("📦 Returning HTMX fragment (no layout)")
-			return content, nil
 		}
+		return appendFragments(content, fragments, data, renderer)
 	} else if isCompleteDocument {
 		// Return full document for regular requests
 		log.Printf("📄 Returning complete document")
@@ -545,7 +736,7 @@ func loadAndRenderHTMXTemplate(templatePath string, data any, renderer *views.Te
 	} else {
 		// Wrap in layout for regular requests
 		log.Printf("📄 Wrapping content in layout")
-		return wrapInLayout(content, data, renderer)
+		return wrapInLayout(content, data, renderer, strictLayout)
 	}
 }
 
@@ -556,6 +747,12 @@ func calculateRouteSpecificity(pattern string) int {
 	specificity := len(parts) * 10 // Base score for number of segments
 
 	for _, part := range parts {
+		if isCatchAllSegment(part) {
+			// Catch-all segment - matches anything, so it must sort after every other route
+			specificity -= 1000
+			continue
+		}
+
 		if strings.HasPrefix(part, ":") || (strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]")) {
 			// Parameter segment - less specific
 			specificity -= 5
@@ -568,11 +765,25 @@ func calculateRouteSpecificity(pattern string) int {
 	return specificity
 }
 
+// isCatchAllSegment reports whether a route segment is a catch-all ([...name] or :...name)
+func isCatchAllSegment(part string) bool {
+	return strings.HasPrefix(part, "[...") || strings.HasPrefix(part, ":...")
+}
+
+// pathParamName strips the catch-all prefix from a parameter name
+func pathParamName(name string) string {
+	return strings.TrimPrefix(name, "...")
+}
+
 // convertToGoServeMuxPattern converts our [param] syntax to Go 1.22+ ServeMux {param} syntax
 func convertToGoServeMuxPattern(pattern string) string {
 	// Convert [param] to {param}
 	result := pattern
 
+	// Convert catch-all [...name] and :...name to Go's {name...} wildcard first
+	reCatchAll := regexp.MustCompile(`(?:\[\.\.\.([^\]]+)\]|:\.\.\.([^/]+))`)
+	result = reCatchAll.ReplaceAllString(result, "{$1$2...}")
+
 	// Use regex to find [param] patterns and convert them
 	re := regexp.MustCompile(`\[([^\]]+)\]`)
 	result = re.ReplaceAllString(result, "{$1}")
@@ -589,40 +800,233 @@ type RouteGroup struct {
 	Domain    string
 	Method    string
 	Pattern   string
-	HTMLRoute *parser.Route // The .html.hbs file for rendering
-	SQLRoute  *parser.Route // The .sql.hbs file for data fetching
+	HTMLRoute *parser.Route   // The .html.hbs file for rendering
+	JSONRoute *parser.Route   // The .json.hbs file for API-only routes
+	XMLRoute  *parser.Route   // The .xml.hbs file for feeds and other XML documents
+	SQLRoute  *parser.Route   // The .sql.hbs file for data fetching
+	Queries   []*parser.Route // Named queries run alongside SQLRoute, e.g. stats.sql.hbs
+}
+
+// primaryRoute returns the route used to serve the group, preferring HTML over JSON, and
+// JSON over XML
+func (g RouteGroup) primaryRoute() *parser.Route {
+	if g.HTMLRoute != nil {
+		return g.HTMLRoute
+	}
+	if g.JSONRoute != nil {
+		return g.JSONRoute
+	}
+	return g.XMLRoute
 }
 
-// buildShowURL constructs the show URL based on the create pattern
-func buildShowURL(createPattern string, id any) string {
+// servesXML reports whether a request for format is answered from the XML template:
+// when XML is asked for, or when the group has nothing else to serve
+func (g RouteGroup) servesXML(format string) bool {
+	return g.XMLRoute != nil && (format == "xml" || g.HTMLRoute == nil && g.JSONRoute == nil)
+}
+
+// transactional reports whether any of the group's templates opted into a transaction
+func (g RouteGroup) transactional() bool {
+	for _, route := range []*parser.Route{g.HTMLRoute, g.JSONRoute, g.XMLRoute, g.SQLRoute} {
+		if route != nil && route.Transactional {
+			return true
+		}
+	}
+	return false
+}
+
+// withRouteTransaction runs fn in a database transaction when transactional is set, with
+// the transaction's executor carried by ctx, and commits unless fn returns an error
+func withRouteTransaction(ctx context.Context, transactional bool, frameworkServer *lang_adapters.FrameworkServer, fn func(ctx context.Context) error) error {
+	if !transactional || frameworkServer == nil || frameworkServer.DbExecutor == nil {
+		return fn(ctx)
+	}
+	return frameworkServer.DbExecutor.WithTransaction(ctx, func(tx *database.DatabaseExecutor) error {
+		return fn(database.ContextWithExecutor(ctx, tx))
+	})
+}
+
+// writeTimeout answers a request that ran past its timeout with 504, as the JSON error
+// envelope for JSON requests
+func writeTimeout(w http.ResponseWriter, r *http.Request, group RouteGroup) {
+	format := determineRequestedFormat(r)
+	if !group.servesXML(format) && (format == "json" || group.HTMLRoute == nil) {
+		writeJSONError(w, http.StatusGatewayTimeout, errorCodeTimeout, errors.New("request timed out"))
+		return
+	}
+	http.Error(w, middleware.TimeoutMessage, http.StatusGatewayTimeout)
+}
+
+// requestTimeout returns the deadline for r, which HTMX requests configure separately
+func requestTimeout(r *http.Request, appConfig *parser.AppConfig) time.Duration {
+	if parseHTMXHeaders(r).IsHTMX {
+		return appConfig.HTMXRequestTimeoutDuration()
+	}
+	return appConfig.RequestTimeoutDuration()
+}
+
+// withReadYourWrites sends a request's reads to the primary database once it has
+// written, since replicas may not have the write yet
+func withReadYourWrites(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next(w, r.WithContext(database.WithReadYourWrites(r.Context())))
+	}
+}
+
+// rateLimitMiddleware returns the server.rate_limit limiter, or nil when it is off
+func rateLimitMiddleware(rc parser.RateLimitConfig) middleware.Middleware {
+	if rc.Requests <= 0 {
+		return nil
+	}
+	keyFn, by := middleware.ClientIP, "ip"
+	if rc.By == "user" {
+		keyFn, by = auth.RateLimitKey, "user"
+	}
+	log.Printf("🚦 Rate limiting to %d requests per %v by %s", rc.Requests, rc.Window(), by)
+	return middleware.RateLimitMiddleware(rc.Requests, rc.Window(), keyFn)
+}
+
+// isTimeout reports whether err was caused by the request deadline being exceeded
+func isTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// buildShowURL constructs the show URL for a record created through the group's route
+func buildShowURL(group RouteGroup, appConfig *parser.AppConfig, id any) string {
+	// The root route is registered as "/", so resolve it back to its configured pattern
+	createPattern := group.Pattern
+	if createPattern == "/" && appConfig.Root != "" {
+		createPattern = appConfig.Root
+	}
+
 	// Convert /users/create to /users/:user_id pattern
 	if strings.Contains(createPattern, "/create") {
 		basePattern := strings.Replace(createPattern, "/create", "", 1)
 		return fmt.Sprintf("%s/%v", basePattern, id)
 	}
 
-	// Fallback for other patterns
-	return fmt.Sprintf("/users/%v", id)
+	// Fallback to the owning domain's show route
+	return fmt.Sprintf("/%s/%v", group.Domain, id)
+}
+
+// redirectParamPattern matches the :name and [name] parameters of a redirect target
+var redirectParamPattern = regexp.MustCompile(`:([A-Za-z_]\w*)|\[(\w+)\]`)
+
+// configuredRedirect returns where the group's redirect.yaml sends a submission, with
+// its parameters filled from the first result row, then the request data. A name_id
+// parameter missing from both takes the row's recordID. It reports false when there is no
+// rule, its when condition doesn't hold, or a parameter has no value.
+func configuredRedirect(group RouteGroup, templateData any, requestData map[string]any, sqlFailed bool) (string, int, bool) {
+	var rule parser.RedirectRule
+	for _, route := range []*parser.Route{group.HTMLRoute, group.SQLRoute} {
+		if route != nil && route.Redirect.To != "" {
+			rule = route.Redirect
+			break
+		}
+	}
+	if rule.To == "" {
+		return "", 0, false
+	}
+	switch rule.When {
+	case "", "success":
+		if sqlFailed {
+			return "", 0, false
+		}
+	case "error":
+		if !sqlFailed {
+			return "", 0, false
+		}
+	}
+
+	row, _ := templateData.(map[string]any)
+	if rows, ok := templateData.([]map[string]any); ok && len(rows) > 0 {
+		row = rows[0]
+	}
+
+	complete := true
+	target := redirectParamPattern.ReplaceAllStringFunc(rule.To, func(param string) string {
+		name := strings.Trim(param, ":[]")
+		for _, data := range []map[string]any{row, requestData} {
+			if value, ok := data[name]; ok && value != nil {
+				return fmt.Sprint(value)
+			}
+		}
+		if id := recordID(row); id != nil && strings.HasSuffix(name, "_id") {
+			return fmt.Sprint(id)
+		}
+		complete = false
+		return param
+	})
+	if !complete {
+		log.Printf("⚠️ Redirect %s has parameters without values; not redirecting", rule.To)
+		return "", 0, false
+	}
+
+	status := rule.Status
+	if status == 0 {
+		status = http.StatusSeeOther
+	}
+	return target, status, true
+}
+
+// recordID returns the id of a result row: its id column, or the last_insert_id an
+// INSERT without RETURNING reports
+func recordID(row map[string]any) any {
+	if id := row["id"]; id != nil {
+		return id
+	}
+	return row["last_insert_id"]
 }
 
 // executeSQL renders the SQL template and executes it against the database
-func executeSQL(sqlRoute *parser.Route, requestData map[string]any, appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) (any, error) {
+func executeSQL(ctx context.Context, sqlRoute *parser.Route, requestData map[string]any, appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) (any, error) {
+	defer timeStage(ctx, StageSQL)()
+
 	// Load and render the SQL template to generate the actual SQL query
+	_, renderSpan := tracing.Start(ctx, "sql.render")
+	tracing.SetString(renderSpan, "template", sqlRoute.ViewPath)
 	sqlQuery, err := loadAndRenderSQLTemplate(sqlRoute.ViewPath, requestData, appConfig.Views)
+	tracing.RecordError(renderSpan, err)
+	renderSpan.End()
 	if err != nil {
 		return nil, fmt.Errorf("failed to render SQL template: %w", err)
 	}
 
+	// Rows inserted for a tenant always belong to it
+	if tenant, ok := tenantFromContext(ctx); ok && requestData[tenantIDKey] != nil {
+		sqlQuery = tenant.scopeInserts(sqlQuery)
+	}
+
 	log.Printf("🔍 Generated SQL query: %s", sqlQuery)
 
-	// Execute the SQL query using the database executor
-	if frameworkServer != nil && frameworkServer.DbExecutor != nil {
-		// Use the real database executor
-		ctx := context.Background()
-		resultJSON, err := frameworkServer.DbExecutor.ExecuteSQL(ctx, sqlQuery, requestData, nil)
-		if err != nil {
-			log.Printf("❌ Database execution failed: %v", err)
-			return nil, fmt.Errorf("database execution failed: %w", err)
+	// Execute the SQL query using the request's transaction, or the shared executor
+	executor, ok := database.ExecutorFromContext(ctx)
+	if !ok && frameworkServer != nil {
+		executor = frameworkServer.DbExecutor
+	}
+	if executor != nil {
+		// Cached routes reuse results, except in transactions, which may read their own writes
+		var requestCache *routeCache
+		var resultJSON []byte
+		hit := false
+		if !ok {
+			requestCache, resultJSON, hit = cachedResult(ctx)
+		}
+
+		if hit {
+			log.Printf("⚡ Serving cached results for %s", requestCache.key)
+		} else {
+			// Use the real database executor
+			resultJSON, err = executor.ExecuteSQL(ctx, sqlQuery, requestData, nil)
+			if err != nil {
+				log.Printf("❌ Database execution failed: %v", err)
+				return nil, fmt.Errorf("database execution failed: %w", err)
+			}
+
+			// The executor reports query errors in its response, so surface deadlines explicitly
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, fmt.Errorf("database execution failed: %w", ctxErr)
+			}
 		}
 
 		log.Printf("🔍 Raw database response: %s", string(resultJSON))
@@ -644,6 +1048,12 @@ func executeSQL(sqlRoute *parser.Route, requestData map[string]any, appConfig *p
 			log.Printf("❌ Database query failed: %s", dbResponse.Error)
 			return nil, fmt.Errorf("database query failed: %s", dbResponse.Error)
 		}
+		if staleUpdate(sqlQuery, dbResponse.Count, requestData, domainLockColumn(appConfig, *sqlRoute)) {
+			return nil, errStaleRecord
+		}
+		if requestCache != nil && !hit {
+			requestCache.save(ctx, resultJSON)
+		}
 
 		log.Printf("✅ Database query successful: %d records", dbResponse.Count)
 		log.Printf("📦 Database response data: %+v", dbResponse.Data)
@@ -653,19 +1063,15 @@ func executeSQL(sqlRoute *parser.Route, requestData map[string]any, appConfig *p
 		return dbResponse.Data, nil
 	}
 
-	// Fallback to mock data if no database executor
-	log.Printf("⚠️ No database executor available, using mock data")
-	mockData := []map[string]any{
-		{"id": 1, "name": "John Doe", "email": "john@example.com", "age": 30},
-		{"id": 2, "name": "Jane Smith", "email": "jane@example.com", "age": 28},
-		{"id": 3, "name": "Bob Johnson", "email": "bob@example.com", "age": 35},
+	if appConfig.Mock {
+		log.Printf("🎭 No database in mock mode; serving placeholder rows for %s", sqlRoute.View)
+		return mockRows(appConfig, *sqlRoute), nil
 	}
-
-	return mockData, nil
+	return nil, fmt.Errorf("%w to run %s", errNoDatabase, sqlRoute.View)
 }
 
 // loadAndRenderSQLTemplate loads a SQL template file and renders it to generate SQL
-func loadAndRenderSQLTemplate(templatePath string, data any, renderer *views.TemplateRenderer) (string, error) {
+func loadAndRenderSQLTemplate(templatePath string, data any, renderer views.Renderer) (string, error) {
 	// Create the expected template name based on path hash
 	pathHash := fmt.Sprintf("%x", sha256.Sum256([]byte(templatePath)))
 	templateName := fmt.Sprintf("route_%s", pathHash[:16])
@@ -717,7 +1123,19 @@ func handleSingleRoute(w http.ResponseWriter, r *http.Request, route parser.Rout
 		route.Method, route.Link, route.Format, route.View)
 
 	// Extract request data
-	requestData := extractRequestData(r, route)
+	domainConfig, _ := appConfig.GetDomain(domainName)
+	requestData, err := extractRequestData(r, route, domainConfig)
+	if err != nil {
+		log.Printf("❌ Invalid request data: %v", err)
+		writeRequestDataError(w, route.Format, err)
+		return
+	}
+	if err := addSearchConditions(r, domainConfig, databaseDriver(frameworkServer), requestData); err != nil {
+		log.Printf("❌ Invalid filter: %v", err)
+		writeRequestDataError(w, route.Format, err)
+		return
+	}
+	addSortOrder(r, domainConfig, requestData)
 	log.Printf("📊 Request data: %+v", requestData)
 
 	switch route.Format {
@@ -725,6 +1143,8 @@ func handleSingleRoute(w http.ResponseWriter, r *http.Request, route parser.Rout
 		handleHTMLRoute(w, r, route, requestData, appConfig, frameworkServer)
 	case "json":
 		handleJSONRoute(w, r, route, requestData, appConfig, frameworkServer)
+	case "xml":
+		handleXMLRoute(w, r, route, requestData, appConfig, frameworkServer)
 	case "sql":
 		handleSQLRoute(w, r, route, requestData, appConfig)
 	default:
@@ -775,6 +1195,11 @@ func determineRequestedFormat(r *http.Request) string {
 		return format
 	}
 
+	// Paths like /feed.xml ask for XML
+	if strings.HasSuffix(r.URL.Path, ".xml") {
+		return "xml"
+	}
+
 	// Check Accept header
 	accept := r.Header.Get("Accept")
 	log.Printf("🔍 Accept header: %s", accept)
@@ -785,6 +1210,9 @@ func determineRequestedFormat(r *http.Request) string {
 	if strings.Contains(accept, "text/html") {
 		return "html"
 	}
+	if strings.Contains(accept, "application/xml") || strings.Contains(accept, "text/xml") {
+		return "xml"
+	}
 
 	// Default to html
 	return "html"
@@ -834,14 +1262,20 @@ func createMultiFormatHandler(routes []parser.Route, appConfig *parser.AppConfig
 
 // handleRouteByFormat handles the request based on the route format
 func handleRouteByFormat(w http.ResponseWriter, r *http.Request, route parser.Route, appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) {
-	// Extract path parameters and request data
-	requestData := extractRequestData(r, route)
+	// Extract path parameters and request data (no domain context, so params stay strings)
+	requestData, err := extractRequestData(r, route, nil)
+	if err != nil {
+		writeRequestDataError(w, route.Format, err)
+		return
+	}
 
 	switch route.Format {
 	case "html":
 		handleHTMLRoute(w, r, route, requestData, appConfig, frameworkServer)
 	case "json":
 		handleJSONRoute(w, r, route, requestData, appConfig, frameworkServer)
+	case "xml":
+		handleXMLRoute(w, r, route, requestData, appConfig, frameworkServer)
 	case "sql":
 		handleSQLRoute(w, r, route, requestData, appConfig)
 	default:
@@ -857,18 +1291,15 @@ func handleHTMLRoute(w http.ResponseWriter, r *http.Request, route parser.Route,
 	// 1. Process business logic via domain communication
 	// 2. Render template with the result
 
-	// First, try to get data from domain logic if there's a domain process
-	var templateData map[string]any = requestData
+	// First, try to get data from domain logic if there's a domain handler
+	var templateData any = requestData
 
-	// If this route has domain logic, call it
-	if frameworkServer != nil {
-		domainData, err := callDomainLogic(r, route, requestData, frameworkServer)
-		if err == nil && domainData != nil {
-			templateData = domainData
-			log.Printf("📦 Domain data received: %+v", templateData)
-		} else if err != nil {
-			log.Printf("⚠️ Domain logic error: %v", err)
-		}
+	domainData, err := callDomainLogic(r.Context(), route, requestData, appConfig, frameworkServer)
+	if err == nil && domainData != nil {
+		templateData = domainData
+		log.Printf("📦 Domain data received: %+v", templateData)
+	} else if err != nil {
+		log.Printf("⚠️ Domain logic error: %v", err)
 	}
 
 	// The route files like get.html.hbs are not loaded as templates
@@ -883,12 +1314,19 @@ func handleHTMLRoute(w http.ResponseWriter, r *http.Request, route parser.Route,
 	}
 
 	// Load and render the template directly
-	html, err := loadAndRenderTemplate(route.ViewPath, templateData, appConfig.Views)
+	stopRender := timeStage(r.Context(), StageRender)
+	_, renderSpan := tracing.Start(r.Context(), "template.render")
+	tracing.SetString(renderSpan, "template", route.ViewPath)
+	html, err := loadAndRenderTemplate(route.ViewPath, templateData, appConfig.Views, appConfig.StrictLayoutEnabled())
+	tracing.RecordError(renderSpan, err)
+	renderSpan.End()
+	stopRender()
 	if err != nil {
 		log.Printf("❌ Template render failed: %v", err)
 
 		// Return a helpful error page
 		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, `
 			<html>
 			<head><title>Template Error</title></head>
@@ -910,7 +1348,33 @@ func handleHTMLRoute(w http.ResponseWriter, r *http.Request, route parser.Route,
 }
 
 // loadAndRenderTemplate loads a template file and renders it intelligently
-func loadAndRenderTemplate(templatePath string, data any, renderer *views.TemplateRenderer) (string, error) {
+func loadAndRenderTemplate(templatePath string, data any, renderer views.Renderer, strictLayout bool) (string, error) {
+	defer metrics.ObserveSince(metrics.TemplateRenderDuration, time.Now(), templatePath)
+
+	content, err := renderRouteTemplate(templatePath, data, renderer)
+	if err != nil {
+		return "", err
+	}
+
+	// Check if this is a complete HTML document
+	contentTrimmed := strings.TrimSpace(content)
+	isCompleteDocument := strings.HasPrefix(strings.ToLower(contentTrimmed), "<!doctype html") ||
+		strings.HasPrefix(strings.ToLower(contentTrimmed), "<html")
+
+	if isCompleteDocument {
+		// This is a complete document, return as-is
+		log.Printf("📄 Template is complete document, rendering directly")
+		return content, nil
+	} else {
+		// This is content that should go in a layout
+		log.Printf("📄 Template is content, rendering with layout")
+		return wrapInLayout(content, data, renderer, strictLayout)
+	}
+}
+
+// renderRouteTemplate renders a route's template file by its preloaded name, loading it
+// on the fly when it was not preloaded
+func renderRouteTemplate(templatePath string, data any, renderer views.Renderer) (string, error) {
 	// Create the expected template name based on path hash
 	pathHash := fmt.Sprintf("%x", sha256.Sum256([]byte(templatePath)))
 	templateName := fmt.Sprintf("route_%s", pathHash[:16])
@@ -936,44 +1400,92 @@ func loadAndRenderTemplate(templatePath string, data any, renderer *views.Templa
 		// Note: We can't delete the temp template since DeleteTemplate doesn't exist
 		// But this should only happen in development when templates aren't preloaded
 	}
+	return content, nil
+}
 
-	// Check if this is a complete HTML document
-	contentTrimmed := strings.TrimSpace(content)
-	isCompleteDocument := strings.HasPrefix(strings.ToLower(contentTrimmed), "<!doctype html") ||
-		strings.HasPrefix(strings.ToLower(contentTrimmed), "<html")
-
-	if isCompleteDocument {
-		// This is a complete document, return as-is
-		log.Printf("📄 Template is complete document, rendering directly")
-		return content, nil
-	} else {
-		// This is content that should go in a layout
-		log.Printf("📄 Template is content, rendering with layout")
+// handleXMLRoute renders an XML template, such as an RSS feed, as application/xml. Like
+// an HTML template it gets the results of the route's SQL template as vm.<domain>, but
+// no layout; the template is responsible for producing valid XML.
+func handleXMLRoute(w http.ResponseWriter, r *http.Request, route parser.Route, requestData map[string]any, appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) {
+	log.Printf("📰 Rendering XML template: %s", route.View)
 
-		// Prepare layout data
-		layoutData := map[string]any{
-			"body": content,
+	domainName, sqlRoute := routeDomain(appConfig, route), siblingSQLRoute(appConfig, route)
+	var templateData any = requestData
+	ctx := r.Context()
+	waitQueries := startQueries(ctx, siblingQueries(appConfig, route), requestData, appConfig, frameworkServer)
+	if sqlRoute != nil {
+		err := withRouteTransaction(ctx, route.Transactional || sqlRoute.Transactional, frameworkServer, func(ctx context.Context) error {
+			sqlData, err := executeSQL(ctx, sqlRoute, requestData, appConfig, frameworkServer)
+			if err == nil {
+				templateData = sqlData
+			}
+			return err
+		})
+		if isTimeout(err) {
+			log.Printf("⏱️ SQL execution timed out for XML route: %v", err)
+			http.Error(w, "Request timed out", http.StatusGatewayTimeout)
+			return
+		} else if errors.Is(err, errNoDatabase) {
+			log.Printf("❌ %v", err)
+			http.Error(w, fmt.Sprintf("%v; %s", err, noDatabaseHint), http.StatusInternalServerError)
+			return
+		} else if err != nil {
+			log.Printf("❌ SQL execution failed for XML route: %v", err)
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
 		}
+	}
+	templateData = withQueryResults(templateData, waitQueries())
 
-		// If data is a map, merge it with layout data for layout context
-		if dataMap, ok := data.(map[string]any); ok {
-			for key, value := range dataMap {
-				if key != "body" { // Don't override body
-					layoutData[key] = value
-				}
+	viewModel := map[string]any{
+		"vm": map[string]any{
+			domainName: templateData,
+			"domain":   domainName,
+		},
+		views.LocaleKey: i18n.ResolveLocale(r),
+	}
+
+	stopRender := timeStage(r.Context(), StageRender)
+	_, renderSpan := tracing.Start(r.Context(), "template.render")
+	tracing.SetString(renderSpan, "template", route.ViewPath)
+	content, err := renderRouteTemplate(route.ViewPath, viewModel, appConfig.Views)
+	tracing.RecordError(renderSpan, err)
+	renderSpan.End()
+	stopRender()
+	if err != nil {
+		log.Printf("❌ Template render failed: %v", err)
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write([]byte(content))
+}
+
+// siblingSQLRoute returns the SQL template with the same method and link as route,
+// which supplies its data, or nil when there is none
+func siblingSQLRoute(appConfig *parser.AppConfig, route parser.Route) *parser.Route {
+	for _, domain := range appConfig.Domains {
+		for i, domainRoute := range domain.Logic.HTTP.Routes {
+			if domainRoute.Method == route.Method && domainRoute.Link == route.Link && domainRoute.Format == "sql" && domainRoute.Query == "" {
+				return &domain.Logic.HTTP.Routes[i]
 			}
 		}
+	}
+	return nil
+}
 
-		// Render with layout
-		html, err := renderer.Render("layouts/main", layoutData)
-		if err != nil {
-			// If layout fails, return the content as-is
-			log.Printf("⚠️ Layout render failed, returning content directly: %v", err)
-			return content, nil
+// siblingQueries returns the named queries run alongside route's SQL template
+func siblingQueries(appConfig *parser.AppConfig, route parser.Route) []*parser.Route {
+	var queries []*parser.Route
+	for _, domain := range appConfig.Domains {
+		for i, domainRoute := range domain.Logic.HTTP.Routes {
+			if domainRoute.Method == route.Method && domainRoute.Link == route.Link && domainRoute.Query != "" {
+				queries = append(queries, &domain.Logic.HTTP.Routes[i])
+			}
 		}
-
-		return html, nil
 	}
+	return queries
 }
 
 // handleJSONRoute handles JSON API responses
@@ -981,6 +1493,8 @@ func handleJSONRoute(w http.ResponseWriter, r *http.Request, route parser.Route,
 	log.Printf("🔗 Processing JSON route: %s", route.View)
 
 	var responseData any
+	ctx := r.Context()
+	waitQueries := startQueries(ctx, siblingQueries(appConfig, route), requestData, appConfig, frameworkServer)
 
 	// Look for a corresponding SQL route with the same pattern and method
 	var sqlRoute *parser.Route
@@ -988,7 +1502,7 @@ func handleJSONRoute(w http.ResponseWriter, r *http.Request, route parser.Route,
 		for _, domainRoute := range domain.Logic.HTTP.Routes {
 			if domainRoute.Method == route.Method &&
 				domainRoute.Link == route.Link &&
-				domainRoute.Format == "sql" {
+				domainRoute.Format == "sql" && domainRoute.Query == "" {
 				sqlRoute = &domainRoute
 				break
 			}
@@ -1002,48 +1516,67 @@ func handleJSONRoute(w http.ResponseWriter, r *http.Request, route parser.Route,
 	if sqlRoute != nil {
 		log.Printf("🗄️ Found SQL route for JSON: %s", sqlRoute.View)
 
-		sqlData, err := executeSQL(sqlRoute, requestData, appConfig, frameworkServer)
+		var sqlData any
+		err := withRouteTransaction(ctx, route.Transactional || sqlRoute.Transactional, frameworkServer, func(ctx context.Context) error {
+			var err error
+			sqlData, err = executeSQL(ctx, sqlRoute, requestData, appConfig, frameworkServer)
+			if err != nil {
+				return err
+			}
+			return requireRecord(route.Method, route.Link, sqlData)
+		})
 		if err != nil {
-			log.Printf("❌ SQL execution failed for JSON route: %v", err)
+			status, code := sqlErrorStatus(err)
+			switch {
+			case isTimeout(err):
+				log.Printf("⏱️ SQL execution timed out for JSON route: %v", err)
+				err = errors.New("request timed out")
+			case errors.Is(err, errNoDatabase):
+				log.Printf("❌ %v", err)
+				err = fmt.Errorf("%v; %s", err, noDatabaseHint)
+			case errors.Is(err, errStaleRecord):
+				log.Printf("⚠️ Update conflict on %s: %v", route.Link, err)
+				err = errors.New(conflictMessage)
+			case errors.Is(err, errNotFound):
+				log.Printf("🔍 No record for %s %s", route.Method, r.URL.Path)
+				err = errNotFound
+			default:
+				log.Printf("❌ SQL execution failed for JSON route: %v", err)
+				err = fmt.Errorf("database error: %v", err)
+			}
+			writeJSONError(w, status, code, err)
+			return
+		}
+
+		log.Printf("✅ SQL data retrieved for JSON: %+v", sqlData)
+		// Return the SQL data directly, or wrap it in a success response
+		if dataArray, ok := sqlData.([]map[string]any); ok {
 			responseData = map[string]any{
-				"success": false,
-				"error":   fmt.Sprintf("Database error: %v", err),
+				"success": true,
+				"data":    dataArray,
+				"count":   len(dataArray),
 			}
 		} else {
-			log.Printf("✅ SQL data retrieved for JSON: %+v", sqlData)
-			// Return the SQL data directly, or wrap it in a success response
-			if dataArray, ok := sqlData.([]map[string]any); ok {
-				responseData = map[string]any{
-					"success": true,
-					"data":    dataArray,
-					"count":   len(dataArray),
-				}
-			} else {
-				responseData = map[string]any{
-					"success": true,
-					"data":    sqlData,
-				}
+			responseData = map[string]any{
+				"success": true,
+				"data":    sqlData,
 			}
 		}
 	} else {
 		// No SQL route found, fall back to domain logic or request data
 		log.Printf("⚠️ No SQL route found for JSON route, using fallback")
 
-		if frameworkServer != nil {
-			domainData, err := callDomainLogic(r, route, requestData, frameworkServer)
-			if err != nil {
-				responseData = map[string]any{
-					"success": false,
-					"error":   err.Error(),
-				}
-			} else if domainData != nil {
-				responseData = domainData
-			} else {
-				responseData = map[string]any{
-					"success": true,
-					"data":    requestData,
-				}
-			}
+		domainData, err := callDomainLogic(ctx, route, requestData, appConfig, frameworkServer)
+		if isTimeout(err) {
+			log.Printf("⏱️ Handler timed out for JSON route: %v", err)
+			writeJSONError(w, http.StatusGatewayTimeout, errorCodeTimeout, errors.New("request timed out"))
+			return
+		} else if err != nil {
+			log.Printf("❌ Handler failed for JSON route: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, errorCodeHandler, err)
+			return
+		} else if domainData != nil {
+			responseData = domainData
 		} else {
 			responseData = map[string]any{
 				"success": true,
@@ -1052,6 +1585,17 @@ func handleJSONRoute(w http.ResponseWriter, r *http.Request, route parser.Route,
 		}
 	}
 
+	// Named queries are served beside data, e.g. {"success": true, "data": [...], "stats": [...]}
+	if response, ok := responseData.(map[string]any); ok {
+		for name, rows := range waitQueries() {
+			if _, taken := response[name]; taken {
+				log.Printf("⚠️ Not serving query %s, whose name the response already uses", name)
+				continue
+			}
+			response[name] = rows
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(responseData); err != nil {
 		log.Printf("❌ Failed to encode JSON response: %v", err)
@@ -1074,57 +1618,123 @@ func handleSQLRoute(w http.ResponseWriter, r *http.Request, route parser.Route,
 	w.Write([]byte(sqlQuery))
 }
 
-// callDomainLogic communicates with domain process for business logic
-func callDomainLogic(r *http.Request, route parser.Route, requestData map[string]any, frameworkServer *lang_adapters.FrameworkServer) (map[string]any, error) {
-	// This would communicate with the domain process
-	// For now, just return the request data with some mock processing
+// callDomainLogic runs the Go or JavaScript handler of the route's domain, passing the
+// request data as the route has no SQL. It returns nil when no handler is available.
+func callDomainLogic(ctx context.Context, route parser.Route, requestData map[string]any, appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) (any, error) {
+	domain := routeDomain(appConfig, route)
+	if domain == "" {
+		return nil, nil
+	}
 
-	// Add some mock data to demonstrate the system working
-	mockData := map[string]any{
-		"users": []map[string]any{
-			{"id": 1, "name": "John Doe", "email": "john@example.com"},
-			{"id": 2, "name": "Jane Smith", "email": "jane@example.com"},
-		},
-		"request_data": requestData,
-		"processed_at": time.Now().Format(time.RFC3339),
+	action := extractActionFromRoute(route.Link, route.Method)
+	data, handled, err := runDomainHandler(ctx, domain, action, requestData, requestData, frameworkServer)
+	if !handled {
+		return nil, nil
 	}
+	return data, err
+}
 
-	return mockData, nil
+// routeDomain returns the name of the domain that defines a route, or "" when none does
+func routeDomain(appConfig *parser.AppConfig, route parser.Route) string {
+	for _, domain := range appConfig.Domains {
+		for _, domainRoute := range domain.Logic.HTTP.Routes {
+			if domainRoute.Method == route.Method && domainRoute.Link == route.Link {
+				return domain.Name
+			}
+		}
+	}
+	return ""
+}
+
+// reservedRequestKeys are request data keys the framework sets and SQL templates trust,
+// so query and form parameters may not supply them
+var reservedRequestKeys = map[string]bool{
+	views.SearchWhereKey: true,
+	views.OrderByKey:     true,
+	views.TenantScopeKey: true,
+	tenantKey:            true,
+	tenantIDKey:          true,
 }
 
-// extractRequestData extracts all relevant data from the HTTP request with HTMX support
-func extractRequestData(r *http.Request, route parser.Route) map[string]any {
+// extractRequestData extracts all relevant data from the HTTP request with HTMX support.
+// Path parameters are coerced to the types declared in the domain's models; an error
+// is returned when a parameter does not match its declared type.
+func extractRequestData(r *http.Request, route parser.Route, domain *parser.DomainConfig) (map[string]any, error) {
 	data := make(map[string]any)
 
 	// In Go 1.22+, path values are available via r.PathValue()
 	// Extract path parameters based on the route pattern
-	pathParams := extractPathParametersFromGoServeMux(r, route.Link)
-	for k, v := range pathParams {
-		data[k] = v
+	pathValues := make(map[string]any)
+	for k, v := range extractPathParametersFromGoServeMux(r, route.Link) {
+		coerced, err := coercePathParameter(domain, k, v)
+		if err != nil {
+			return nil, err
+		}
+		pathValues[k] = coerced
 	}
 
-	// Add query parameters
+	// Add query parameters, then the form or JSON body
+	params := make(map[string]any)
 	for k, v := range r.URL.Query() {
+		if reservedRequestKeys[k] {
+			continue
+		}
 		if len(v) == 1 {
-			data[k] = v[0]
+			params[k] = v[0]
 		} else {
-			data[k] = v
+			params[k] = v
 		}
 	}
 
-	// For POST/PUT, also include form data
 	if r.Method == "POST" || r.Method == "PUT" || r.Method == "PATCH" {
-		if err := r.ParseForm(); err == nil {
-			for k, v := range r.Form {
+		if isJSONRequest(r) {
+			if err := decodeJSONBody(r, params); err != nil {
+				return nil, err
+			}
+		} else if isMultipartRequest(r) {
+			if err := decodeMultipartBody(r, params); err != nil {
+				return nil, err
+			}
+		} else if err := r.ParseForm(); err == nil {
+			for k, v := range r.PostForm {
+				if reservedRequestKeys[k] {
+					continue
+				}
 				if len(v) == 1 {
-					data[k] = v[0]
+					params[k] = v[0]
 				} else {
-					data[k] = v
+					params[k] = v
 				}
 			}
 		}
 	}
 
+	// Create and update routes only take the permitted model fields, converted to their types
+	if bindsModel(route) {
+		raw := params
+		model, _ := domainModel(domain)
+		if model != nil {
+			bound, errs := BindModel(params, model)
+			if len(errs) > 0 {
+				return nil, ValidationErrors(errs)
+			}
+			params = bound
+		}
+		if fields, ok := permittedFields(route, model); ok {
+			params = permit(params, fields)
+		}
+		if err := bindLockVersion(raw, params, domain); err != nil {
+			return nil, err
+		}
+	}
+	if err := storeUploads(r, params); err != nil {
+		return nil, err
+	}
+	// Path parameters are copied last so a query or body value of the same name cannot
+	// replace them
+	maps.Copy(data, params)
+	maps.Copy(data, pathValues)
+
 	// Add HTMX-specific data
 	htmxReq := parseHTMXHeaders(r)
 	data["_htmx"] = htmxReq
@@ -1135,7 +1745,60 @@ func extractRequestData(r *http.Request, route parser.Route) map[string]any {
 	data["_path"] = r.URL.Path
 	data["_route"] = route.Link
 
-	return data
+	return data, nil
+}
+
+// lookupPathParameterType finds the model field type for a path parameter.
+// "user_id" resolves to the "id" field of the "user" model (integer unless declared
+// otherwise), any other name resolves to a field with the same name in the domain models.
+func lookupPathParameterType(domain *parser.DomainConfig, name string) (string, bool) {
+	if domain == nil {
+		return "", false
+	}
+
+	if modelName, ok := strings.CutSuffix(name, "_id"); ok {
+		if model, exists := domain.GetModel(modelName); exists {
+			if field, exists := model.GetField("id"); exists {
+				return field.Type, true
+			}
+			return "integer", true
+		}
+	}
+
+	for _, modelDef := range domain.Models {
+		for _, model := range modelDef {
+			if field, exists := model.GetField(name); exists {
+				return field.Type, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// coercePathParameter converts a path parameter to its model type, leaving unknown params as strings
+func coercePathParameter(domain *parser.DomainConfig, name, value string) (any, error) {
+	fieldType, ok := lookupPathParameterType(domain, name)
+	if !ok {
+		return value, nil
+	}
+
+	switch strings.ToLower(fieldType) {
+	case "integer", "int", "bigint", "serial", "bigserial":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path parameter %s: %q is not an integer", name, value)
+		}
+		return n, nil
+	case "boolean", "bool":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path parameter %s: %q is not a boolean", name, value)
+		}
+		return b, nil
+	default:
+		return value, nil
+	}
 }
 
 // extractPathParametersFromGoServeMux extracts parameters using Go 1.22+ ServeMux
@@ -1149,7 +1812,7 @@ func extractPathParametersFromGoServeMux(r *http.Request, routePattern string) m
 
 	for _, match := range matches {
 		if len(match) > 1 {
-			paramName := match[1]
+			paramName := pathParamName(match[1])
 			// Use Go 1.22+ PathValue method
 			if value := r.PathValue(paramName); value != "" {
 				params[paramName] = value
@@ -1163,7 +1826,7 @@ func extractPathParametersFromGoServeMux(r *http.Request, routePattern string) m
 
 	for _, match := range matches2 {
 		if len(match) > 1 {
-			paramName := match[1]
+			paramName := pathParamName(match[1])
 			if value := r.PathValue(paramName); value != "" {
 				params[paramName] = value
 			}
@@ -1199,32 +1862,20 @@ func extractPathParameters(actualPath, routePattern string) map[string]string {
 
 // StartHTTPServerWithConfig starts HTTP server using the parsed configuration
 func StartHTTPServerWithConfig(appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) *http.Server {
+	tenants, err := newTenancy(context.Background(), appConfig, frameworkServer.DbExecutor)
+	if err != nil {
+		log.Fatalf("❌ Invalid tenancy configuration: %v", err)
+	}
+
 	// Create the route dispatcher with the fixed logic
 	mux := CreateRouteDispatcher(appConfig, frameworkServer)
 
 	server := &http.Server{
-		Addr:    ":8080",
-		Handler: mux,
+		Addr:    DefaultHTTPAddr,
+		Handler: tenants.Wrap(mux),
 	}
 
 	fmt.Printf("🚀 HTTP Server starting on http://localhost%s\n", server.Addr)
-	fmt.Println("📍 Registered routes:")
-
-	// Group and log routes properly
-	routeGroups := make(map[string][]string)
-	for _, domain := range appConfig.Domains {
-		for _, route := range domain.Logic.HTTP.Routes {
-			key := fmt.Sprintf("%s %s", route.Method, route.Link)
-			routeGroups[key] = append(routeGroups[key], route.Format)
-		}
-	}
-
-	for pattern, formats := range routeGroups {
-		fmt.Printf("   %s (formats: %s)\n", pattern, strings.Join(formats, ", "))
-	}
-	fmt.Printf("   GET /health -> Health check\n")
-	fmt.Printf("   GET /htmx.min.js -> HTMX library\n")
-	fmt.Println()
 
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -1242,9 +1893,7 @@ func StartGRPCServerWithShutdown(frameworkServer *lang_adapters.FrameworkServer)
 		log.Fatalf("Failed to listen on port 50051: %v", err)
 	}
 
-	server := grpc.NewServer()
-	reflection.Register(server)
-	lang_adapters.RegisterFrameworkServiceServer(server, frameworkServer)
+	server := newGRPCServer(frameworkServer)
 
 	log.Println("gRPC server starting on :50051")
 
@@ -1258,24 +1907,37 @@ func StartGRPCServerWithShutdown(frameworkServer *lang_adapters.FrameworkServer)
 	return server
 }
 
+// newGRPCServer creates the framework gRPC server with reflection enabled
+func newGRPCServer(frameworkServer *lang_adapters.FrameworkServer) *grpc.Server {
+	server := grpc.NewServer()
+	reflection.Register(server)
+	lang_adapters.RegisterFrameworkServiceServer(server, frameworkServer)
+	return server
+}
+
 // StartBothServersWithConfig starts the servers using the new file-system based config
 func StartBothServersWithConfig(appConfig *parser.AppConfig) {
+	// Validate message routing before connecting to anything
+	messageRouter, err := lang_adapters.NewMessageRouterFromConfig(appConfig)
+	if err != nil {
+		log.Fatalf("❌ Invalid message routing in fulcrum.yml:\n%v", err)
+	}
+
+	// Export traces when enabled in fulcrum.yml
+	defer startTracing(appConfig)()
+
 	// --- Database Setup ---
-	dbConfig := interfaces.Config{
-		Driver:          interfaces.DatabaseDriver(appConfig.DB.Driver),
-		Host:            appConfig.DB.Host,
-		Port:            appConfig.DB.Port,
-		Username:        appConfig.DB.Username,
-		Password:        appConfig.DB.Password,
-		Database:        appConfig.DB.Database,
-		SSLMode:         appConfig.DB.SSLMode,
-		MaxOpenConns:    appConfig.DB.MaxOpenConns,
-		MaxIdleConns:    appConfig.DB.MaxIdleConns,
-		ConnMaxLifetime: time.Duration(appConfig.DB.ConnMaxLifetime) * time.Minute,
-		FilePath:        appConfig.DB.FilePath,
-	}
-
-	dbManager, err := database.NewManager(dbConfig)
+	dbConfig, err := database.FromParserConfig(appConfig.DB)
+	if err != nil {
+		log.Fatalf("Invalid database configuration: %v", err)
+	}
+
+	replicaConfigs, err := database.ReplicasFromParserConfig(appConfig.DB)
+	if err != nil {
+		log.Fatalf("Invalid database replica configuration: %v", err)
+	}
+
+	dbManager, err := database.NewManager(dbConfig, replicaConfigs...)
 	if err != nil {
 		log.Fatalf("Failed to create database manager: %v", err)
 	}
@@ -1289,33 +1951,49 @@ func StartBothServersWithConfig(appConfig *parser.AppConfig) {
 	defer dbManager.Close()
 
 	db := dbManager.GetDatabase()
+	metrics.RegisterDBStats(db.Stats)
+
+	dbExecutor := database.NewDatabaseExecutor(db)
+	dbExecutor.EnableStatementCache(appConfig.DB.StatementCacheSize)
+	dbExecutor.SetRetryPolicy(database.RetryPolicyFromConfig(appConfig.DB))
+	dbExecutor.SetSlowQueryThreshold(database.SlowQueryThresholdFromConfig(appConfig.DB))
+	dbExecutor.SetLockColumns(lockColumns(appConfig))
+	if replicas := dbManager.Replicas(); replicas != nil {
+		dbExecutor.SetReplica(replicas)
+	}
+	if err := dbExecutor.LoadSoftDeleteTables(ctx); err != nil {
+		log.Printf("⚠️ Soft deletes disabled: %v", err)
+	}
+	if err := dbExecutor.EnableAudit(ctx, appConfig.Audit); err != nil {
+		log.Printf("⚠️ Auditing disabled: %v", err)
+	}
+	defer dbExecutor.Close()
 
 	// --- Framework Server Setup ---
 	frameworkServer := &lang_adapters.FrameworkServer{
-		Db:              db,
-		DbExecutor:      database.NewDatabaseExecutor(db),
-		DomainStreams:   make(map[string]lang_adapters.FrameworkService_DomainCommunicationServer),
-		PendingRequests: make(map[string]*lang_adapters.PendingRequest),
+		Db:                db,
+		DbExecutor:        dbExecutor,
+		DomainStreams:     make(map[string]lang_adapters.FrameworkService_DomainCommunicationServer),
+		PendingRequests:   make(map[string]*lang_adapters.PendingRequest),
+		OutboundQueueSize: appConfig.DomainQueueSize,
+		OutboundQueueTTL:  time.Duration(appConfig.DomainQueueTTL) * time.Second,
+		Router:            messageRouter,
 	}
 	frameworkServer.StartCleanupRoutine()
+	metrics.RegisterPendingRequests(frameworkServer.PendingCount)
 
 	// --- Enhanced Renderer Setup ---
 	log.Println("Setting up template renderer...")
 
 	// Log discovered domains and their template directories
-	log.Printf("Discovered %d domains:", len(appConfig.Domains))
-	for _, domain := range appConfig.Domains {
-		log.Printf("  - Domain: %s", domain.Name)
-		log.Printf("    Path: %s", domain.Path)
-		log.Printf("    Routes: %d", len(domain.Logic.HTTP.Routes))
-		for _, route := range domain.Logic.HTTP.Routes {
-			log.Printf("      %s %s -> %s", route.Method, route.Link, route.ViewPath)
-		}
-	}
+	log.Printf("Discovered %d domains", len(appConfig.Domains))
+	debugf("Template directories found: %v", appConfig.GetAllTemplateDirectories())
 
-	// Get all template directories
-	templateDirs := appConfig.GetAllTemplateDirectories()
-	log.Printf("Template directories found: %v", templateDirs)
+	catalog, err := LoadTranslations(appConfig)
+	if err != nil {
+		log.Fatalf("Failed to load translations: %v", err)
+	}
+	i18n.SetCatalog(catalog)
 
 	// Setup renderer with the new system
 	renderer, err := views.SetupViewsFromConfig(appConfig)
@@ -1325,18 +2003,12 @@ func StartBothServersWithConfig(appConfig *parser.AppConfig) {
 
 	appConfig.Views = renderer
 
-	// --- Validate Routes and Templates ---
-	if err := appConfig.ValidateRoutes(); err != nil {
-		log.Printf("Warning: Route validation issues found: %v", err)
-		// Don't fail, just warn - some templates might be loaded dynamically
-	}
-
+	// --- Preload Templates and Validate Routes ---
 	log.Println("Pre-loading route templates...")
-	if err := appConfig.PreloadRouteTemplates(); err != nil {
-		log.Printf("Warning: failed to preload route templates: %v", err)
-	} else {
-		log.Println("✅ Route templates preloaded successfully")
+	if err := appConfig.PreloadAndValidateRoutes(); err != nil {
+		log.Fatalf("❌ Route templates could not be loaded:\n%v", err)
 	}
+	log.Println("✅ Route templates preloaded successfully")
 
 	// --- Start Servers ---
 	log.Println("Starting gRPC server...")
@@ -1345,9 +2017,16 @@ func StartBothServersWithConfig(appConfig *parser.AppConfig) {
 	log.Println("Starting HTTP server...")
 	httpServer := StartHTTPServerWithConfig(appConfig, frameworkServer)
 
+	metricsServer, err := startMetricsServer(appConfig.Metrics)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if appConfig.Metrics.Enabled {
+		log.Printf("📈 Metrics available on %s", metricsLocation(appConfig.Metrics, DefaultHTTPAddr[1:]))
+	}
+
 	log.Println("Servers started successfully!")
-	log.Printf("HTTP routes registered:")
-	printRegisteredRoutes(appConfig)
+	logRegisteredRoutes(appConfig)
 
 	// --- Graceful Shutdown ---
 	c := make(chan os.Signal, 1)
@@ -1365,19 +2044,42 @@ func StartBothServersWithConfig(appConfig *parser.AppConfig) {
 		log.Printf("HTTP server shutdown error: %v", err)
 	}
 
+	// Shutdown metrics server
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Metrics server shutdown error: %v", err)
+		}
+	}
+
 	// Shutdown gRPC server
 	grpcServer.GracefulStop()
 
 	log.Println("Servers gracefully stopped.")
 }
 
-// printRegisteredRoutes logs all registered routes for debugging
-func printRegisteredRoutes(appConfig *parser.AppConfig) {
-	for _, domain := range appConfig.Domains {
-		log.Printf("Domain '%s' routes:", domain.Name)
-		for _, route := range domain.Logic.HTTP.Routes {
-			log.Printf("  %s %s (format: %s, template: %s)",
-				route.Method, route.Link, route.Format, route.View)
+// startTracing enables OpenTelemetry when configured and returns a func that flushes pending spans
+func startTracing(appConfig *parser.AppConfig) func() {
+	if !appConfig.Tracing.Enabled {
+		return func() {}
+	}
+
+	shutdown, err := tracing.Init(context.Background(), tracing.Config{
+		Endpoint:    appConfig.Tracing.OTLPEndpoint,
+		Insecure:    appConfig.Tracing.Insecure,
+		SampleRate:  appConfig.Tracing.Rate(),
+		ServiceName: appConfig.Tracing.ServiceName,
+	})
+	if err != nil {
+		log.Printf("⚠️ Tracing disabled: %v", err)
+		return func() {}
+	}
+	log.Printf("🔭 Tracing enabled: exporting to %s (sample rate %g)", appConfig.Tracing.OTLPEndpoint, appConfig.Tracing.Rate())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			log.Printf("Tracing shutdown error: %v", err)
 		}
 	}
 }
@@ -1423,39 +2125,22 @@ func setupHotReloading(appConfig *parser.AppConfig) error {
 
 // StartHTTPServerWithProcessManager starts HTTP server with HTMX and process manager support
 func StartHTTPServerWithProcessManager(appConfig *parser.AppConfig, frameworkServer *lang_adapters.FrameworkServer) *http.Server {
+	tenants, err := newTenancy(context.Background(), appConfig, frameworkServer.DbExecutor)
+	if err != nil {
+		log.Fatalf("❌ Invalid tenancy configuration: %v", err)
+	}
 	mux := CreateRouteDispatcher(appConfig, frameworkServer)
-	auth.AddLoginRoute(mux, frameworkServer)
+	if !appConfig.Auth.Disabled {
+		auth.AddLoginRoute(mux, frameworkServer)
+	}
 
 	server := &http.Server{
-		Addr:    ":8080",
-		Handler: mux,
+		Addr:    DefaultHTTPAddr,
+		Handler: tenants.Wrap(mux),
 	}
 
 	fmt.Printf("🚀 HTTP Server with HTMX support starting on http://localhost%s\n", server.Addr)
-	fmt.Println("📍 Registered routes:")
-
-	// Log routes with HTMX support indication
-	routeGroups := make(map[string][]string)
-	for _, domain := range appConfig.Domains {
-		for _, route := range domain.Logic.HTTP.Routes {
-			key := fmt.Sprintf("%s %s", route.Method, route.Link)
-			routeGroups[key] = append(routeGroups[key], route.Format)
-		}
-	}
-
-	for pattern, formats := range routeGroups {
-		fmt.Printf("   %s (formats: %s, HTMX: ✓)\n", pattern, strings.Join(formats, ", "))
-	}
-	fmt.Println("   GET /health -> Health check")
-	fmt.Println("   GET /htmx.min.js -> HTMX library")
-	fmt.Println()
-	fmt.Println("🔄 HTMX Features Enabled:")
-	fmt.Println("   - Automatic fragment detection")
-	fmt.Println("   - HTMX-specific templates (.htmx.hbs)")
-	fmt.Println("   - Regular templates without layout for HTMX")
-	fmt.Println("   - Response header management")
-	fmt.Println("   - Context injection for handlers")
-	fmt.Println()
+	logRegisteredRoutes(appConfig)
 
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -1466,105 +2151,14 @@ func StartHTTPServerWithProcessManager(appConfig *parser.AppConfig, frameworkSer
 	return server
 }
 
-// Add this function to framework_integration.go
+// StartBothServersWithProcessManager serves the app with the handler process manager until Ctrl+C
 func StartBothServersWithProcessManager(appConfig *parser.AppConfig) {
-	// Database setup (your existing code)
-	dbConfig := interfaces.Config{
-		Driver:          interfaces.DatabaseDriver(appConfig.DB.Driver),
-		Host:            appConfig.DB.Host,
-		Port:            appConfig.DB.Port,
-		Username:        appConfig.DB.Username,
-		Password:        appConfig.DB.Password,
-		Database:        appConfig.DB.Database,
-		SSLMode:         appConfig.DB.SSLMode,
-		MaxOpenConns:    appConfig.DB.MaxOpenConns,
-		MaxIdleConns:    appConfig.DB.MaxIdleConns,
-		ConnMaxLifetime: time.Duration(appConfig.DB.ConnMaxLifetime) * time.Minute,
-		FilePath:        appConfig.DB.FilePath,
-	}
-
-	dbManager, err := database.NewManager(dbConfig)
-	if err != nil {
-		log.Fatalf("Failed to create database manager: %v", err)
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	if err := dbManager.Connect(ctx); err != nil {
-		log.Fatalf("Failed to connect to the database: %v", err)
-	}
-	defer dbManager.Close()
-
-	db := dbManager.GetDatabase()
-
-	// Framework Server Setup with Process Manager
-	frameworkServer := &lang_adapters.FrameworkServer{
-		Db:              db,
-		DbExecutor:      database.NewDatabaseExecutor(db),
-		DomainStreams:   make(map[string]lang_adapters.FrameworkService_DomainCommunicationServer),
-		PendingRequests: make(map[string]*lang_adapters.PendingRequest),
-	}
-	frameworkServer.StartCleanupRoutine()
-
-	// Initialize Process Manager for JavaScript handlers
-	if err := frameworkServer.InitializeProcessManager(appConfig.Path, true); err != nil {
-		log.Printf("Warning: Failed to initialize process manager: %v", err)
-	}
-
-	// Template setup (your existing code)
-	renderer, err := views.SetupViewsFromConfig(appConfig)
-	if err != nil {
-		log.Fatalf("Failed to setup views: %v", err)
-	}
-	appConfig.Views = renderer
-
-	if appConfig.Mode == "develop" {
-		if err := setupHotReloading(appConfig); err != nil {
-			log.Printf("Warning: Could not setup hot reloading: %v", err)
-		}
-	}
-
-	// Validate and preload templates
-	if err := appConfig.ValidateRoutes(); err != nil {
-		log.Printf("Warning: Route validation issues found: %v", err)
-	}
-
-	if err := appConfig.PreloadRouteTemplates(); err != nil {
-		log.Printf("Warning: failed to preload route templates: %v", err)
-	}
-
-	// Start servers with process manager integration
-	grpcServer := StartGRPCServerWithShutdown(frameworkServer)
-	httpServer := StartHTTPServerWithProcessManager(appConfig, frameworkServer)
-
-	// Graceful shutdown
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-
-	log.Println("Application ready. Press Ctrl+C to shutdown.")
-	<-c
-
-	log.Println("Shutting down servers...")
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer shutdownCancel()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Shutdown HTTP server
-	if err := httpServer.Shutdown(shutdownCtx); err != nil {
-		log.Printf("HTTP server shutdown error: %v", err)
+	if err := Serve(ctx, appConfig, ServeOptions{Dev: appConfig.Mode == "develop"}); err != nil {
+		log.Fatalf("❌ %v", err)
 	}
-
-	// Shutdown gRPC server
-	grpcServer.GracefulStop()
-
-	// Stop process manager
-	if frameworkServer.ProcessManager != nil {
-		if err := frameworkServer.ProcessManager.StopAll(); err != nil {
-			log.Printf("Process manager shutdown error: %v", err)
-		}
-	}
-
-	log.Println("Servers gracefully stopped.")
 }
 
 // Legacy functions for backward compatibility
@@ -1596,9 +2190,7 @@ func StartHTTPServerWithShutdown(frameworkServer *lang_adapters.FrameworkServer)
 	mux := http.NewServeMux()
 
 	// Health check handler
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintf(w, "Status: OK\nTime: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	})
+	mux.HandleFunc("/health", healthHandler(frameworkServer))
 
 	// Catch-all handler
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -1646,7 +2238,7 @@ func StartHTTPServerWithShutdown(frameworkServer *lang_adapters.FrameworkServer)
 	})
 
 	server := &http.Server{
-		Addr:    ":8080",
+		Addr:    DefaultHTTPAddr,
 		Handler: mux,
 	}
 
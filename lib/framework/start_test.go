@@ -0,0 +1,896 @@
+package framework
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fulcrum/lib/database"
+	"fulcrum/lib/database/drivers"
+	"fulcrum/lib/database/interfaces"
+	"fulcrum/lib/metrics"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lang_adapters "fulcrum/lib/lang/adapters"
+)
+
+func TestConvertToGoServeMuxPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		expected string
+	}{
+		{
+			name:     "static route",
+			pattern:  "/users",
+			expected: "/users",
+		},
+		{
+			name:     "bracket parameter",
+			pattern:  "/users/[user_id]",
+			expected: "/users/{user_id}",
+		},
+		{
+			name:     "colon parameter",
+			pattern:  "/users/:user_id/edit",
+			expected: "/users/{user_id}/edit",
+		},
+		{
+			name:     "bracket catch-all",
+			pattern:  "/docs/[...slug]",
+			expected: "/docs/{slug...}",
+		},
+		{
+			name:     "colon catch-all",
+			pattern:  "/docs/:...slug",
+			expected: "/docs/{slug...}",
+		},
+		{
+			name:     "parameter followed by catch-all",
+			pattern:  "/wikis/:wiki_id/:...path",
+			expected: "/wikis/{wiki_id}/{path...}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := convertToGoServeMuxPattern(tt.pattern)
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestBuildShowURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    RouteGroup
+		root     string
+		expected string
+	}{
+		{
+			name:     "create pattern",
+			group:    RouteGroup{Pattern: "/orders/create", Domain: "orders"},
+			expected: "/orders/123",
+		},
+		{
+			name:     "collection pattern falls back to the domain",
+			group:    RouteGroup{Pattern: "/orders", Domain: "orders"},
+			expected: "/orders/123",
+		},
+		{
+			name:     "root route resolves the configured root",
+			group:    RouteGroup{Pattern: "/", Domain: "orders"},
+			root:     "/orders/create",
+			expected: "/orders/123",
+		},
+		{
+			name:     "root route without a create pattern uses the domain",
+			group:    RouteGroup{Pattern: "/", Domain: "orders"},
+			root:     "/dashboard",
+			expected: "/orders/123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := buildShowURL(tt.group, &parser.AppConfig{Root: tt.root}, 123)
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConfiguredRedirect(t *testing.T) {
+	tests := []struct {
+		name         string
+		rule         parser.RedirectRule
+		templateData any
+		sqlFailed    bool
+		expected     string
+		status       int
+	}{
+		{
+			name:         "row id fills the record parameter",
+			rule:         parser.RedirectRule{To: "/orders/:order_id"},
+			templateData: []map[string]any{{"id": 7}},
+			expected:     "/orders/7",
+			status:       http.StatusSeeOther,
+		},
+		{
+			name:         "last insert id fills the record parameter",
+			rule:         parser.RedirectRule{To: "/orders/[order_id]", Status: http.StatusFound},
+			templateData: []map[string]any{{"last_insert_id": 8}},
+			expected:     "/orders/8",
+			status:       http.StatusFound,
+		},
+		{
+			name:         "request data fills other parameters",
+			rule:         parser.RedirectRule{To: "/customers/:customer_id/orders"},
+			templateData: []map[string]any{{"rows_affected": 1}},
+			expected:     "/customers/3/orders",
+			status:       http.StatusSeeOther,
+		},
+		{
+			name:         "parameter without a value",
+			rule:         parser.RedirectRule{To: "/orders/:order_id"},
+			templateData: map[string]any{"total": 5},
+		},
+		{
+			name: "no rule",
+		},
+		{
+			name:      "success rule after a failure",
+			rule:      parser.RedirectRule{To: "/orders"},
+			sqlFailed: true,
+		},
+		{
+			name:      "error rule after a failure",
+			rule:      parser.RedirectRule{To: "/orders/new", When: "error"},
+			sqlFailed: true,
+			expected:  "/orders/new",
+			status:    http.StatusSeeOther,
+		},
+		{
+			name:     "always rule",
+			rule:     parser.RedirectRule{To: "http://localhost:8080/orders", When: "always"},
+			expected: "http://localhost:8080/orders",
+			status:   http.StatusSeeOther,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := parser.Route{Link: "/orders/create", Method: "POST", Redirect: tt.rule}
+			group := RouteGroup{Pattern: "/orders/create", Method: "POST", Domain: "orders", HTMLRoute: &route}
+
+			target, status, ok := configuredRedirect(group, tt.templateData, map[string]any{"customer_id": int64(3)}, tt.sqlFailed)
+			if ok != (tt.expected != "") {
+				t.Fatalf("Expected redirect %v, got %v to %q", tt.expected != "", ok, target)
+			}
+			if target != tt.expected || status != tt.status {
+				t.Errorf("Expected %q (%d), got %q (%d)", tt.expected, tt.status, target, status)
+			}
+		})
+	}
+}
+
+func TestPostWithoutIDHonorsRedirectRule(t *testing.T) {
+	t.Setenv("FULCRUM_ENV", "production")
+	viewPath := filepath.Join(t.TempDir(), "post.html.hbs")
+	if err := os.WriteFile(viewPath, []byte("<p>created</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	appConfig := &parser.AppConfig{Domains: []parser.DomainConfig{{Name: "orders"}}, Views: views.NewTemplateRenderer()}
+
+	serve := func(rule parser.RedirectRule) *httptest.ResponseRecorder {
+		route := parser.Route{Link: "/orders/create", Method: "POST", Format: "html", ViewPath: viewPath, Redirect: rule}
+		group := RouteGroup{Pattern: "/orders/create", Method: "POST", Domain: "orders", HTMLRoute: &route}
+		r := httptest.NewRequest(http.MethodPost, "/orders/create", strings.NewReader("customer_id=3"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handleHTMLRouteWithProcessManager(rec, r, group, appConfig, nil)
+		return rec
+	}
+
+	rec := serve(parser.RedirectRule{To: "/customers/:customer_id/orders"})
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/customers/3/orders" {
+		t.Errorf("Expected a redirect to /customers/3/orders, got %d to %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	// Without a rule or an id there is nothing to redirect to
+	rec = serve(parser.RedirectRule{})
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "created") {
+		t.Errorf("Expected the page to be rendered, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHTMXHeadersFromTemplateData(t *testing.T) {
+	tests := []struct {
+		key    string
+		value  string
+		header string
+	}{
+		{"redirect_to", "/users", "HX-Redirect"},
+		{"htmx_trigger", "userSaved", "HX-Trigger"},
+		{"htmx_reswap", "outerHTML", "HX-Reswap"},
+		{"htmx_retarget", "#errors", "HX-Retarget"},
+		{"htmx_reselect", "#form", "HX-Reselect"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			setHTMXResponseHeaders(rec, extractHTMXHeaders(map[string]any{tt.key: tt.value}))
+			if got := rec.Header().Get(tt.header); got != tt.value {
+				t.Errorf("Expected %s %q, got %q", tt.header, tt.value, got)
+			}
+		})
+	}
+
+	t.Run("htmx_response", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		setHTMXResponseHeaders(rec, extractHTMXHeaders(map[string]any{
+			"htmx_response": map[string]any{"push-url": "/users/1", "reswap": "none"},
+		}))
+		if rec.Header().Get("HX-Push-Url") != "/users/1" || rec.Header().Get("HX-Reswap") != "none" {
+			t.Errorf("Expected HX-Push-Url and HX-Reswap from htmx_response, got %v", rec.Header())
+		}
+	})
+}
+
+func TestCatchAllRouteCapturesMultipleSegments(t *testing.T) {
+	tests := []struct {
+		name         string
+		routePattern string
+		path         string
+		expected     map[string]string
+	}{
+		{
+			name:         "single segment",
+			routePattern: "/docs/:...slug",
+			path:         "/docs/intro",
+			expected:     map[string]string{"slug": "intro"},
+		},
+		{
+			name:         "multiple segments",
+			routePattern: "/docs/:...slug",
+			path:         "/docs/guides/routing/catch-all",
+			expected:     map[string]string{"slug": "guides/routing/catch-all"},
+		},
+		{
+			name:         "regular parameter with catch-all",
+			routePattern: "/wikis/:wiki_id/:...path",
+			path:         "/wikis/42/pages/home",
+			expected:     map[string]string{"wiki_id": "42", "path": "pages/home"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params map[string]string
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET "+convertToGoServeMuxPattern(tt.routePattern), func(w http.ResponseWriter, r *http.Request) {
+				params = extractPathParametersFromGoServeMux(r, tt.routePattern)
+			})
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected route to match %s, got status %d", tt.path, rec.Code)
+			}
+
+			if len(params) != len(tt.expected) {
+				t.Fatalf("Expected %d params, got %d: %v", len(tt.expected), len(params), params)
+			}
+
+			for key, expected := range tt.expected {
+				if params[key] != expected {
+					t.Errorf("Expected %s=%q, got %q", key, expected, params[key])
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateRouteSpecificityCatchAllSortsLast(t *testing.T) {
+	catchAll := calculateRouteSpecificity("/docs/:...slug")
+
+	others := []string{
+		"/docs",
+		"/docs/:doc_id",
+		"/docs/:doc_id/edit",
+		"/users/:user_id/posts/:post_id",
+	}
+
+	for _, pattern := range others {
+		if specificity := calculateRouteSpecificity(pattern); specificity <= catchAll {
+			t.Errorf("Expected %s (%d) to be more specific than catch-all (%d)", pattern, specificity, catchAll)
+		}
+	}
+}
+
+func TestExtractRequestDataCoercesPathParameters(t *testing.T) {
+	domain := &parser.DomainConfig{
+		Name: "users",
+		Models: []parser.ModelDefinition{
+			{
+				"user": parser.Model{
+					"email":  parser.Field{Type: "text"},
+					"active": parser.Field{Type: "boolean"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		routePattern string
+		path         string
+		param        string
+		expected     any
+		expectErr    bool
+	}{
+		{
+			name:         "valid integer id",
+			routePattern: "/users/:user_id",
+			path:         "/users/42",
+			param:        "user_id",
+			expected:     int64(42),
+		},
+		{
+			name:         "non-numeric id",
+			routePattern: "/users/:user_id",
+			path:         "/users/abc",
+			param:        "user_id",
+			expectErr:    true,
+		},
+		{
+			name:         "query cannot replace the id",
+			routePattern: "/users/:user_id",
+			path:         "/users/42?user_id=abc",
+			param:        "user_id",
+			expected:     int64(42),
+		},
+		{
+			name:         "query cannot replace the id with another",
+			routePattern: "/users/:user_id",
+			path:         "/users/42?user_id=7",
+			param:        "user_id",
+			expected:     int64(42),
+		},
+		{
+			name:         "boolean field",
+			routePattern: "/users/active/:active",
+			path:         "/users/active/true",
+			param:        "active",
+			expected:     true,
+		},
+		{
+			name:         "unmodeled param passes through",
+			routePattern: "/users/tags/:tag",
+			path:         "/users/tags/go",
+			param:        "tag",
+			expected:     "go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data map[string]any
+			var err error
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET "+convertToGoServeMuxPattern(tt.routePattern), func(w http.ResponseWriter, r *http.Request) {
+				data, err = extractRequestData(r, parser.Route{Link: tt.routePattern, Method: "GET"}, domain)
+			})
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("Expected error for %s, got data %v", tt.path, data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if data[tt.param] != tt.expected {
+				t.Errorf("Expected %s=%#v, got %#v", tt.param, tt.expected, data[tt.param])
+			}
+		})
+	}
+}
+
+func TestHandleHTMLRouteRejectsNonNumericID(t *testing.T) {
+	route := parser.Route{Link: "/users/:user_id", Method: "GET", Format: "html"}
+	appConfig := &parser.AppConfig{
+		Domains: []parser.DomainConfig{
+			{
+				Name:   "users",
+				Models: []parser.ModelDefinition{{"user": parser.Model{"email": parser.Field{Type: "text"}}}},
+			},
+		},
+	}
+	group := RouteGroup{Pattern: route.Link, Method: "GET", Domain: "users", HTMLRoute: &route}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+convertToGoServeMuxPattern(route.Link), func(w http.ResponseWriter, r *http.Request) {
+		handleHTMLRouteWithProcessManager(w, r, group, appConfig, nil)
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/not-a-number", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestWrapInLayoutFailure(t *testing.T) {
+	renderer := views.NewTemplateRenderer()
+
+	t.Run("strict returns error", func(t *testing.T) {
+		if _, err := wrapInLayout("<p>content</p>", nil, renderer, true); err == nil {
+			t.Error("Expected error when layout is missing in strict mode")
+		}
+	})
+
+	t.Run("lenient serves bare content and counts failure", func(t *testing.T) {
+		before := metrics.LayoutRenderFailures.Value()
+
+		html, err := wrapInLayout("<p>content</p>", nil, renderer, false)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if html != "<p>content</p>" {
+			t.Errorf("Expected bare content, got %q", html)
+		}
+		if metrics.LayoutRenderFailures.Value() != before+1 {
+			t.Errorf("Expected layout failure counter to increase")
+		}
+	})
+}
+
+func TestStrictLayoutEnabled(t *testing.T) {
+	t.Setenv("FULCRUM_ENV", "production")
+
+	if (&parser.AppConfig{}).StrictLayoutEnabled() {
+		t.Error("Expected lenient layout in production")
+	}
+	if !(&parser.AppConfig{StrictLayout: true}).StrictLayoutEnabled() {
+		t.Error("Expected strict_layout to force strict layout")
+	}
+	if !(&parser.AppConfig{Mode: "develop"}).StrictLayoutEnabled() {
+		t.Error("Expected strict layout in development mode")
+	}
+}
+
+// slowDatabase blocks every query until the caller's context is done
+type slowDatabase struct {
+	active atomic.Int32
+}
+
+func (s *slowDatabase) wait(ctx context.Context) error {
+	s.active.Add(1)
+	defer s.active.Add(-1)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (s *slowDatabase) Connect(ctx context.Context) error { return nil }
+func (s *slowDatabase) Close() error                      { return nil }
+func (s *slowDatabase) Ping(ctx context.Context) error    { return nil }
+func (s *slowDatabase) Stats() sql.DBStats                { return sql.DBStats{} }
+func (s *slowDatabase) Query(ctx context.Context, query string, args ...any) (interfaces.Rows, error) {
+	return nil, s.wait(ctx)
+}
+func (s *slowDatabase) QueryRow(ctx context.Context, query string, args ...any) interfaces.Row {
+	return nil
+}
+func (s *slowDatabase) Exec(ctx context.Context, query string, args ...any) (interfaces.Result, error) {
+	return nil, s.wait(ctx)
+}
+func (s *slowDatabase) Begin(ctx context.Context) (interfaces.Tx, error) { return nil, nil }
+func (s *slowDatabase) BeginTx(ctx context.Context, opts *sql.TxOptions) (interfaces.Tx, error) {
+	return nil, nil
+}
+func (s *slowDatabase) CreateTable(ctx context.Context, tableName string, schema interfaces.TableSchema) error {
+	return nil
+}
+func (s *slowDatabase) DropTable(ctx context.Context, tableName string) error { return nil }
+func (s *slowDatabase) TableExists(ctx context.Context, tableName string) (bool, error) {
+	return false, nil
+}
+func (s *slowDatabase) GetDriver() interfaces.DatabaseDriver { return interfaces.DriverPostgreSQL }
+func (s *slowDatabase) GetConnectionString() string          { return "slow://" }
+
+// pingDatabase is a slowDatabase whose Ping fails with err
+type pingDatabase struct {
+	slowDatabase
+	err error
+}
+
+func (p *pingDatabase) Ping(ctx context.Context) error { return p.err }
+
+func TestHealthPingsDatabase(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   *lang_adapters.FrameworkServer
+		expected int
+		body     string
+	}{
+		{name: "no database", expected: http.StatusOK, body: "Status: OK"},
+		{name: "database answers", server: &lang_adapters.FrameworkServer{Db: &pingDatabase{}}, expected: http.StatusOK, body: "Status: OK"},
+		{name: "database down", server: &lang_adapters.FrameworkServer{Db: &pingDatabase{err: errors.New("connection refused")}}, expected: http.StatusServiceUnavailable, body: "Database: unreachable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			healthHandler(tt.server)(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			if rec.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("Expected %q in body, got %q", tt.body, rec.Body.String())
+			}
+			if tt.server != nil {
+				if active := tt.server.Db.(*pingDatabase).active.Load(); active != 0 {
+					t.Errorf("Expected no queries, got %d", active)
+				}
+			}
+		})
+	}
+}
+
+func TestSlowQueryReturnsGatewayTimeout(t *testing.T) {
+	sqlPath := filepath.Join(t.TempDir(), "get.sql.hbs")
+	if err := os.WriteFile(sqlPath, []byte("SELECT * FROM users"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	htmlRoute := parser.Route{Link: "/users", Method: "GET", Format: "html"}
+	sqlRoute := parser.Route{Link: "/users", Method: "GET", Format: "sql", ViewPath: sqlPath}
+	group := RouteGroup{Pattern: "/users", Method: "GET", Domain: "users", HTMLRoute: &htmlRoute, SQLRoute: &sqlRoute}
+
+	appConfig := &parser.AppConfig{
+		Domains: []parser.DomainConfig{{Name: "users"}},
+		Views:   views.NewTemplateRenderer(),
+	}
+
+	db := &slowDatabase{}
+	frameworkServer := &lang_adapters.FrameworkServer{DbExecutor: database.NewDatabaseExecutor(db)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users", nil).WithContext(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		handleHTMLRouteWithProcessManager(rec, req, group, appConfig, frameworkServer)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Handler did not return after the deadline")
+	}
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("Expected status %d, got %d", http.StatusGatewayTimeout, rec.Code)
+	}
+	if active := db.active.Load(); active != 0 {
+		t.Errorf("Expected connection to be released, %d queries still active", active)
+	}
+}
+
+func TestDispatcherTimeouts(t *testing.T) {
+	sqlPath := filepath.Join(t.TempDir(), "get.sql.hbs")
+	if err := os.WriteFile(sqlPath, []byte("SELECT * FROM reports"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	appConfig := &parser.AppConfig{
+		Auth:   parser.AuthConfig{Disabled: true},
+		Server: parser.ServerConfig{RequestTimeout: 1},
+		Views:  views.NewTemplateRenderer(),
+	}
+	appConfig.Domains = []parser.DomainConfig{{
+		Name: "reports",
+		Logic: parser.LogicConfig{HTTP: parser.HTTPConfig{Routes: []parser.Route{
+			{Link: "/reports", Method: "GET", Format: "html"},
+			{Link: "/reports", Method: "GET", Format: "sql", ViewPath: sqlPath},
+		}}},
+	}}
+	mux := CreateRouteDispatcher(appConfig, &lang_adapters.FrameworkServer{DbExecutor: database.NewDatabaseExecutor(&slowDatabase{})})
+
+	tests := []struct {
+		name     string
+		accept   string
+		envelope bool
+	}{
+		{name: "html", accept: "text/html"},
+		{name: "json", accept: "application/json", envelope: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := metrics.HTTPTimeouts.Value("/reports")
+
+			req := httptest.NewRequest(http.MethodGet, "/reports", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusGatewayTimeout {
+				t.Errorf("Expected status %d, got %d: %s", http.StatusGatewayTimeout, rec.Code, rec.Body.String())
+			}
+			if tt.envelope && !strings.Contains(rec.Body.String(), `"code":"`+errorCodeTimeout+`"`) {
+				t.Errorf("Expected the timeout error envelope, got %s", rec.Body.String())
+			}
+			if counted := metrics.HTTPTimeouts.Value("/reports") - before; counted != 1 {
+				t.Errorf("Expected 1 timeout counted, got %v", counted)
+			}
+		})
+	}
+}
+
+func TestRequestTimeoutDuration(t *testing.T) {
+	if d := (&parser.AppConfig{}).RequestTimeoutDuration(); d != parser.DefaultRequestTimeout {
+		t.Errorf("Expected default timeout %s, got %s", parser.DefaultRequestTimeout, d)
+	}
+	if d := (&parser.AppConfig{RequestTimeout: 5}).RequestTimeoutDuration(); d != 5*time.Second {
+		t.Errorf("Expected 5s timeout, got %s", d)
+	}
+}
+
+func TestRequestTimeoutForHTMX(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   parser.ServerConfig
+		htmx     bool
+		expected time.Duration
+	}{
+		{name: "default", expected: parser.DefaultRequestTimeout},
+		{name: "htmx falls back to request timeout", server: parser.ServerConfig{RequestTimeout: 10}, htmx: true, expected: 10 * time.Second},
+		{name: "htmx timeout", server: parser.ServerConfig{RequestTimeout: 10, HTMXRequestTimeout: 3}, htmx: true, expected: 3 * time.Second},
+		{name: "htmx timeout ignored for full pages", server: parser.ServerConfig{RequestTimeout: 10, HTMXRequestTimeout: 3}, expected: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if tt.htmx {
+				req.Header.Set("HX-Request", "true")
+			}
+			if d := requestTimeout(req, &parser.AppConfig{Server: tt.server}); d != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, d)
+			}
+		})
+	}
+}
+
+// newSQLiteDB connects to a fresh SQLite database, closed when the test ends, and runs
+// statements on it
+func newSQLiteDB(t *testing.T, statements ...string) interfaces.Database {
+	t.Helper()
+	db, err := drivers.NewSQLiteDB(interfaces.Config{FilePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, statement := range statements {
+		if _, err := db.Exec(context.Background(), statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// serveLedgerTransfer posts to a /ledger/transfer route whose SQL template is sqlSource,
+// against a fresh SQLite database, and returns the response with the rows left in entries
+func serveLedgerTransfer(t *testing.T, transactional bool, sqlSource string) (*httptest.ResponseRecorder, int) {
+	t.Helper()
+	t.Setenv("FULCRUM_ENV", "production")
+
+	db := newSQLiteDB(t, "CREATE TABLE entries (id INTEGER PRIMARY KEY, account TEXT NOT NULL UNIQUE)")
+
+	dir := t.TempDir()
+	viewPath := filepath.Join(dir, "post.html.hbs")
+	sqlPath := filepath.Join(dir, "post.sql.hbs")
+	if err := os.WriteFile(viewPath, []byte("<p>ok</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sqlPath, []byte(sqlSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	htmlRoute := parser.Route{Link: "/ledger/transfer", Method: "POST", Format: "html", ViewPath: viewPath}
+	sqlRoute := parser.Route{Link: "/ledger/transfer", Method: "POST", Format: "sql", ViewPath: sqlPath, Transactional: transactional}
+	group := RouteGroup{Pattern: "/ledger/transfer", Method: "POST", Domain: "ledger", HTMLRoute: &htmlRoute, SQLRoute: &sqlRoute}
+	appConfig := &parser.AppConfig{
+		Domains: []parser.DomainConfig{{Name: "ledger"}},
+		Views:   views.NewTemplateRenderer(),
+	}
+	frameworkServer := &lang_adapters.FrameworkServer{Db: db, DbExecutor: database.NewDatabaseExecutor(db)}
+
+	rec := httptest.NewRecorder()
+	handleHTMLRouteWithProcessManager(rec, httptest.NewRequest(http.MethodPost, "/ledger/transfer", nil), group, appConfig, frameworkServer)
+
+	var count int
+	if err := db.QueryRow(context.Background(), "SELECT COUNT(*) FROM entries").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return rec, count
+}
+
+func TestTransactionalRouteRollsBack(t *testing.T) {
+	const twoInserts = "INSERT INTO entries (account) VALUES ('debit'); INSERT INTO entries (account) VALUES ('credit');"
+	const failingInsert = "INSERT INTO entries (account) VALUES ('debit'); INSERT INTO entries (account) VALUES ('debit');"
+
+	tests := []struct {
+		name          string
+		transactional bool
+		sql           string
+		handlerErr    error
+		status        int
+		rows          int
+	}{
+		{name: "commits when everything succeeds", transactional: true, sql: twoInserts, status: http.StatusOK, rows: 2},
+		{name: "SQL failure mid-template", transactional: true, sql: failingInsert, status: http.StatusInternalServerError, rows: 0},
+		{name: "handler failure after SQL", transactional: true, sql: twoInserts, handlerErr: errors.New("insufficient funds"), status: http.StatusInternalServerError, rows: 0},
+		{name: "non-transactional routes keep partial writes", sql: failingInsert, status: http.StatusOK, rows: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RegisterHandler("ledger", "transfer", func(ctx context.Context, sqlData any, requestData map[string]any) (any, error) {
+				if _, ok := database.ExecutorFromContext(ctx); ok != tt.transactional {
+					t.Errorf("Expected the transaction's executor in the handler context: %v", tt.transactional)
+				}
+				return sqlData, tt.handlerErr
+			})
+			t.Cleanup(func() { UnregisterHandler("ledger", "transfer") })
+
+			rec, rows := serveLedgerTransfer(t, tt.transactional, tt.sql)
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+			if rows != tt.rows {
+				t.Errorf("Expected %d rows to be written, got %d", tt.rows, rows)
+			}
+		})
+	}
+}
+
+func TestXMLRoutes(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	feed := write("feed.xml.hbs", `<rss version="2.0"></rss>`)
+	page := write("page.html.hbs", "<p>page</p>")
+	pageFeed := write("page.xml.hbs", `<rss version="2.0"><channel/></rss>`)
+
+	appConfig := &parser.AppConfig{Auth: parser.AuthConfig{Disabled: true}, Views: views.NewTemplateRenderer()}
+	appConfig.Domains = []parser.DomainConfig{{
+		Name: "feed",
+		Logic: parser.LogicConfig{HTTP: parser.HTTPConfig{Routes: []parser.Route{
+			{Link: "/feed", Method: "GET", Format: "xml", ViewPath: feed},
+			{Link: "/feed/page", Method: "GET", Format: "html", ViewPath: page},
+			{Link: "/feed/page", Method: "GET", Format: "xml", ViewPath: pageFeed},
+		}}},
+	}}
+	mux := CreateRouteDispatcher(appConfig, nil)
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{name: "xml path", path: "/feed.xml", contentType: "application/xml", body: `<rss version="2.0"></rss>`},
+		{name: "xml only route", path: "/feed", contentType: "application/xml", body: `<rss version="2.0"></rss>`},
+		{name: "format parameter", path: "/feed/page?format=xml", contentType: "application/xml", body: "<channel/>"},
+		{name: "accept header", path: "/feed/page", accept: "application/xml", contentType: "application/xml", body: "<channel/>"},
+		{name: "html by default", path: "/feed/page", contentType: "text/html", body: "<p>page</p>"},
+		{name: "xml path beside html", path: "/feed/page.xml", contentType: "application/xml", body: "<channel/>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+				t.Errorf("Expected Content-Type %s, got %s", tt.contentType, got)
+			}
+			if !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("Expected body to contain %q, got %q", tt.body, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestShowRouteNotFound(t *testing.T) {
+	t.Setenv("FULCRUM_ENV", "production")
+
+	db := newSQLiteDB(t,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT)",
+		"INSERT INTO posts (id, title) VALUES (1, 'first')",
+	)
+
+	dir := t.TempDir()
+	for file, source := range map[string]string{
+		"get.html.hbs": "<article>post</article>",
+		"get.sql.hbs":  "SELECT * FROM posts WHERE id = :posts_id",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const link = "/posts/:posts_id/show"
+	htmlRoute := parser.Route{Link: link, Method: "GET", Format: "html", ViewPath: filepath.Join(dir, "get.html.hbs")}
+	sqlRoute := parser.Route{Link: link, Method: "GET", Format: "sql", ViewPath: filepath.Join(dir, "get.sql.hbs")}
+	group := RouteGroup{Pattern: link, Method: "GET", Domain: "posts", HTMLRoute: &htmlRoute, SQLRoute: &sqlRoute}
+	appConfig := &parser.AppConfig{Domains: []parser.DomainConfig{{Name: "posts"}}, Views: views.NewTemplateRenderer()}
+	server := &lang_adapters.FrameworkServer{Db: db, DbExecutor: database.NewDatabaseExecutor(db)}
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{name: "existing id", id: "1", status: http.StatusOK},
+		{name: "missing id", id: "2", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/posts/"+tt.id+"/show", nil)
+			req.SetPathValue("posts_id", tt.id)
+			rec := httptest.NewRecorder()
+			handleHTMLRouteWithProcessManager(rec, req, group, appConfig, server)
+
+			if rec.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), "<article>") {
+				t.Errorf("Expected the page rendered either way, got %s", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"fulcrum/lib/auth"
+	"fulcrum/lib/database"
+	"fulcrum/lib/naming"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+// tenantColumn marks the tables whose rows belong to a tenant
+const tenantColumn = "tenant_id"
+
+// Request data keys holding the request's tenant row and the id its queries bind
+const (
+	tenantKey   = "_tenant"
+	tenantIDKey = "_tenant_id"
+)
+
+// tenantCondition is the condition tenant_scope and search_where render for scoped tables
+const tenantCondition = tenantColumn + " = :" + tenantIDKey
+
+// insertPattern matches the head of an INSERT up to its first value: the statement and
+// table, the column list and ") VALUES ("
+var insertPattern = regexp.MustCompile("(?i)(\\bINSERT\\s+INTO\\s+[`\"]?(\\w+)[`\"]?\\s*\\()([^)]*)(\\)\\s*VALUES\\s*\\()")
+
+// Lookups by slug are cached for tenantCacheTTL; the cache is emptied once it holds
+// tenantCacheSize slugs, so requests naming made-up tenants cannot grow it unbounded
+const (
+	tenantCacheTTL  = time.Minute
+	tenantCacheSize = 1024
+)
+
+// tenancy resolves requests to rows of the tenants table by slug
+type tenancy struct {
+	config   parser.TenancyConfig
+	executor *database.DatabaseExecutor
+	scoped   map[string]bool // Tables with a tenant_id column, read at startup
+	exempt   map[string]bool // First path segments of framework endpoints, which have no tenant
+
+	mutex sync.Mutex
+	cache map[string]cachedTenant
+}
+
+// cachedTenant is a slug's tenants row, nil for an unknown slug, and when it was read
+type cachedTenant struct {
+	row    map[string]any
+	readAt time.Time
+}
+
+// requestTenant is the tenant a request resolved to
+type requestTenant struct {
+	row     map[string]any
+	id      any
+	tenancy *tenancy
+}
+
+// tenantContextKey is the context key of the request's *requestTenant
+type tenantContextKey struct{}
+
+// newTenancy reads which tables are tenant-scoped; it returns nil when tenancy is off
+func newTenancy(ctx context.Context, appConfig *parser.AppConfig, executor *database.DatabaseExecutor) (*tenancy, error) {
+	config := appConfig.Tenancy
+	if !config.Enabled() {
+		return nil, nil
+	}
+	if executor == nil {
+		return nil, fmt.Errorf("tenancy needs a database connection")
+	}
+	scoped, err := executor.TablesWithColumn(ctx, tenantColumn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tenant-scoped tables: %w", err)
+	}
+
+	tables := make([]string, 0, len(scoped))
+	for table := range scoped {
+		tables = append(tables, table)
+	}
+	slices.Sort(tables)
+	log.Printf("🏢 Resolving tenants by %s; tables scoped by %s: %v", config.Resolver, tenantColumn, tables)
+	exempt := map[string]bool{"health": true, "_fulcrum": true, "openapi.json": true, "htmx.min.js": true}
+	if appConfig.Metrics.Enabled && appConfig.Metrics.Address == "" {
+		exempt[firstSegment(appConfig.Metrics.MetricsPath())] = true
+	}
+	return &tenancy{config: config, executor: executor, scoped: scoped, exempt: exempt, cache: make(map[string]cachedTenant)}, nil
+}
+
+// Wrap resolves each request's tenant before next handles it, looking it up once.
+// Requests naming an unknown tenant get 404; requests naming none pass through without
+// one, which the auth pages allow and tenant-scoped routes refuse. The path resolver
+// strips the tenant's segment, so /acme/posts is routed as /posts. Framework endpoints
+// such as /health and /_fulcrum, and files such as /favicon.ico, never have a tenant.
+func (t *tenancy) Wrap(next http.Handler) http.Handler {
+	if t == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if segment := firstSegment(r.URL.Path); t.exempt[segment] || strings.Contains(segment, ".") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		slug, rest := t.slug(r)
+		if slug == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		row, err := t.lookup(r.Context(), slug)
+		if err != nil {
+			log.Printf("❌ Tenant lookup failed: %v", err)
+			http.Error(w, "Tenant lookup failed", http.StatusInternalServerError)
+			return
+		}
+		if row == nil {
+			if t.config.Resolver == parser.TenantByPath {
+				// The first segment was not a tenant, so it is part of an unscoped path
+				next.ServeHTTP(w, r)
+				return
+			}
+			log.Printf("🏢 Unknown tenant %q: %s %s", slug, r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+
+		tenant := &requestTenant{row: row, id: row["id"], tenancy: t}
+		r = r.WithContext(context.WithValue(r.Context(), tenantContextKey{}, tenant))
+		if t.config.Resolver == parser.TenantByPath {
+			url := *r.URL
+			url.Path, url.RawPath = rest, ""
+			r.URL = &url
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// slug returns the tenant slug r names, and for the path resolver the path after it
+func (t *tenancy) slug(r *http.Request) (slug, rest string) {
+	switch t.config.Resolver {
+	case parser.TenantByHeader:
+		return strings.TrimSpace(r.Header.Get(t.config.HeaderName())), ""
+	case parser.TenantBySubdomain:
+		return subdomain(r.Host), ""
+	case parser.TenantByPath:
+		segment, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		return segment, "/" + rest
+	}
+	return "", ""
+}
+
+// subdomain returns the first label of host, e.g. acme for acme.example.com or
+// acme.localhost:8080, or "" for a bare domain or an IP address
+func subdomain(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if net.ParseIP(host) != nil {
+		return ""
+	}
+	labels := strings.Split(strings.ToLower(host), ".")
+	if len(labels) >= 3 || (len(labels) == 2 && labels[1] == "localhost") {
+		return labels[0]
+	}
+	return ""
+}
+
+// firstSegment returns the first segment of path, e.g. posts for /posts/1
+func firstSegment(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return segment
+}
+
+// lookup returns the tenants row with slug, or nil when there is none, reading the
+// tenants table at most once per tenantCacheTTL for each slug
+func (t *tenancy) lookup(ctx context.Context, slug string) (map[string]any, error) {
+	t.mutex.Lock()
+	cached, ok := t.cache[slug]
+	t.mutex.Unlock()
+	if ok && time.Since(cached.readAt) < tenantCacheTTL {
+		return cached.row, nil
+	}
+
+	rows, err := queryRows(ctx, t.executor, "SELECT * FROM tenants WHERE slug = :slug LIMIT 1", map[string]any{"slug": slug})
+	if err != nil {
+		return nil, err
+	}
+	var row map[string]any
+	if len(rows) > 0 {
+		row = rows[0]
+		// JSON numbers come back as float64; bind ids as integers
+		if id, ok := row["id"].(float64); ok && id == float64(int64(id)) {
+			row["id"] = int64(id)
+		}
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if len(t.cache) >= tenantCacheSize {
+		clear(t.cache)
+	}
+	t.cache[slug] = cachedTenant{row: row, readAt: time.Now()}
+	return row, nil
+}
+
+// member reports whether the user belongs to the tenant through user_tenants
+func (rt *requestTenant) member(ctx context.Context, userID int64) (bool, error) {
+	rows, err := queryRows(ctx, rt.tenancy.executor, "SELECT 1 AS member FROM user_tenants WHERE user_id = :user_id AND tenant_id = :tenant_id LIMIT 1",
+		map[string]any{"user_id": userID, "tenant_id": rt.id})
+	return len(rows) > 0, err
+}
+
+// queryRows runs a query and returns the rows it selected
+func queryRows(ctx context.Context, executor *database.DatabaseExecutor, query string, params map[string]any) ([]map[string]any, error) {
+	resultJSON, err := executor.ExecuteSQL(ctx, query, params, nil)
+	if err != nil {
+		return nil, err
+	}
+	var response database.OperationResponse
+	if err := json.Unmarshal(resultJSON, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse query response: %w", err)
+	}
+	if !response.Success {
+		return nil, fmt.Errorf("query failed: %s", response.Error)
+	}
+	return response.Data, nil
+}
+
+// tenantFromContext returns the tenant the request resolved to
+func tenantFromContext(ctx context.Context) (*requestTenant, bool) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(*requestTenant)
+	return tenant, ok
+}
+
+// scopeToTenant adds the request's tenant to requestData for routes outside the auth
+// domain when tenancy is on: the tenant row as _tenant, its id as _tenant_id and
+// tenant_id, and, when the domain's table has a tenant_id column, the condition
+// tenant_scope renders, also ANDed into search_where. It answers 404 and returns false
+// when the request has no tenant or the signed-in user does not belong to it.
+func scopeToTenant(w http.ResponseWriter, r *http.Request, appConfig *parser.AppConfig, domain string, requestData map[string]any) bool {
+	if !appConfig.Tenancy.Enabled() || domain == "auth" {
+		return true
+	}
+
+	tenant, ok := tenantFromContext(r.Context())
+	if !ok {
+		log.Printf("🏢 No tenant for %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+		return false
+	}
+	if !appConfig.Auth.Disabled {
+		userID, signedIn := auth.CurrentUserID(r)
+		member := false
+		if signedIn {
+			var err error
+			if member, err = tenant.member(r.Context(), userID); err != nil {
+				log.Printf("❌ Tenant membership check failed: %v", err)
+				http.Error(w, "Tenant lookup failed", http.StatusInternalServerError)
+				return false
+			}
+		}
+		if !member {
+			log.Printf("🏢 User %d is not a member of tenant %v", userID, tenant.id)
+			http.NotFound(w, r)
+			return false
+		}
+	}
+
+	requestData[tenantKey] = tenant.row
+	requestData[tenantIDKey] = tenant.id
+	requestData[tenantColumn] = tenant.id
+	if tenant.tenancy.scoped[naming.Pluralize(domain)] {
+		requestData[views.TenantScopeKey] = tenantCondition
+		if where, ok := requestData[views.SearchWhereKey].(string); ok && where != "" {
+			requestData[views.SearchWhereKey] = tenantCondition + " AND " + where
+		} else {
+			requestData[views.SearchWhereKey] = tenantCondition
+		}
+	}
+	return true
+}
+
+// scopeInserts adds tenant_id, bound to the request's tenant, to INSERTs into
+// tenant-scoped tables whose column list leaves it out
+func (rt *requestTenant) scopeInserts(sqlQuery string) string {
+	return insertPattern.ReplaceAllStringFunc(sqlQuery, func(statement string) string {
+		match := insertPattern.FindStringSubmatch(statement)
+		head, table, columns, values := match[1], match[2], match[3], match[4]
+		if !rt.tenancy.scoped[table] || hasColumn(columns, tenantColumn) {
+			return statement
+		}
+		if strings.TrimSpace(columns) == "" {
+			return head + tenantColumn + values + ":" + tenantIDKey
+		}
+		return head + tenantColumn + ", " + columns + values + ":" + tenantIDKey + ", "
+	})
+}
+
+// hasColumn reports whether a comma separated, possibly quoted, column list names column
+func hasColumn(columns, column string) bool {
+	for _, name := range strings.Split(columns, ",") {
+		if strings.EqualFold(strings.Trim(strings.TrimSpace(name), "`\""), column) {
+			return true
+		}
+	}
+	return false
+}
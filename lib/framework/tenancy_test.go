@@ -0,0 +1,388 @@
+package framework
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fulcrum/lib/auth"
+	"fulcrum/lib/database"
+	"fulcrum/lib/database/interfaces"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+// newTenantDatabase has tenants acme (1) and globex (2), user 7 in acme, a post for each
+// tenant and a tags table shared between them
+func newTenantDatabase(t *testing.T) *database.DatabaseExecutor {
+	t.Helper()
+	db := newSQLiteDB(t,
+		"CREATE TABLE tenants (id INTEGER PRIMARY KEY, name TEXT, slug TEXT UNIQUE)",
+		"CREATE TABLE user_tenants (id INTEGER PRIMARY KEY, user_id INTEGER, tenant_id INTEGER)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, tenant_id INTEGER, title TEXT)",
+		"CREATE TABLE tags (id INTEGER PRIMARY KEY, name TEXT)",
+		"INSERT INTO tenants (id, name, slug) VALUES (1, 'Acme', 'acme'), (2, 'Globex', 'globex')",
+		"INSERT INTO user_tenants (user_id, tenant_id) VALUES (7, 1)",
+		"INSERT INTO posts (id, tenant_id, title) VALUES (1, 1, 'Acme news'), (2, 2, 'Globex news')",
+	)
+	return database.NewDatabaseExecutor(db)
+}
+
+func newTestTenancy(t *testing.T, executor *database.DatabaseExecutor, resolver string) *tenancy {
+	t.Helper()
+	tenants, err := newTenancy(context.Background(), &parser.AppConfig{Tenancy: parser.TenancyConfig{Resolver: resolver}}, executor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tenants
+}
+
+func TestSubdomain(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"acme.example.com", "acme"},
+		{"ACME.example.com:8080", "acme"},
+		{"acme.localhost:3000", "acme"},
+		{"example.com", ""},
+		{"localhost:3000", ""},
+		{"127.0.0.1:3000", ""},
+		{"[::1]:3000", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := subdomain(tt.host); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTenancyWrap(t *testing.T) {
+	executor := newTenantDatabase(t)
+
+	tests := []struct {
+		name     string
+		resolver string
+		path     string
+		host     string
+		header   string
+		status   int
+		tenantID any
+		seenPath string
+	}{
+		{name: "header", resolver: parser.TenantByHeader, path: "/posts", header: "acme", status: http.StatusOK, tenantID: int64(1), seenPath: "/posts"},
+		{name: "unknown header tenant", resolver: parser.TenantByHeader, path: "/posts", header: "initech", status: http.StatusNotFound},
+		{name: "no header", resolver: parser.TenantByHeader, path: "/auth/login", status: http.StatusOK, seenPath: "/auth/login"},
+		{name: "subdomain", resolver: parser.TenantBySubdomain, path: "/posts", host: "globex.example.com", status: http.StatusOK, tenantID: int64(2), seenPath: "/posts"},
+		{name: "unknown subdomain", resolver: parser.TenantBySubdomain, path: "/posts", host: "initech.example.com", status: http.StatusNotFound},
+		{name: "path prefix is stripped", resolver: parser.TenantByPath, path: "/acme/posts/1", status: http.StatusOK, tenantID: int64(1), seenPath: "/posts/1"},
+		{name: "tenant root", resolver: parser.TenantByPath, path: "/acme", status: http.StatusOK, tenantID: int64(1), seenPath: "/"},
+		{name: "path without tenant", resolver: parser.TenantByPath, path: "/auth/login", status: http.StatusOK, seenPath: "/auth/login"},
+		{name: "health has no tenant", resolver: parser.TenantByHeader, path: "/health", header: "initech", status: http.StatusOK, seenPath: "/health"},
+		{name: "dev tools have no tenant", resolver: parser.TenantByPath, path: "/_fulcrum/requests", status: http.StatusOK, seenPath: "/_fulcrum/requests"},
+		{name: "files have no tenant", resolver: parser.TenantBySubdomain, path: "/favicon.ico", host: "initech.example.com", status: http.StatusOK, seenPath: "/favicon.ico"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seenTenant any
+			var seenPath string
+			handler := newTestTenancy(t, executor, tt.resolver).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tenant, ok := tenantFromContext(r.Context()); ok {
+					seenTenant = tenant.id
+				}
+				seenPath = r.URL.Path
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.host != "" {
+				r.Host = tt.host
+			}
+			if tt.header != "" {
+				r.Header.Set(parser.DefaultTenantHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+			if seenTenant != tt.tenantID {
+				t.Errorf("Expected tenant %v, got %v", tt.tenantID, seenTenant)
+			}
+			if seenPath != tt.seenPath {
+				t.Errorf("Expected the handler to see %q, got %q", tt.seenPath, seenPath)
+			}
+		})
+	}
+
+	if tenants := newTestTenancy(t, executor, ""); tenants != nil {
+		t.Error("Expected no tenancy without a resolver")
+	}
+}
+
+// tenantRequest resolves a request for slug and scopes its request data to domain as the
+// dispatcher does, returning the data, the tenant and the response status
+func tenantRequest(t *testing.T, tenants *tenancy, appConfig *parser.AppConfig, domain, slug, target string, form map[string]string, cookie *http.Cookie) (map[string]any, *requestTenant, int) {
+	t.Helper()
+	method := http.MethodGet
+	var body *strings.Reader
+	if form != nil {
+		method = http.MethodPost
+		values := make([]string, 0, len(form))
+		for k, v := range form {
+			values = append(values, k+"="+v)
+		}
+		body = strings.NewReader(strings.Join(values, "&"))
+	}
+
+	var r *http.Request
+	if body != nil {
+		r = httptest.NewRequest(method, target, body)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		r = httptest.NewRequest(method, target, nil)
+	}
+	r.Header.Set(parser.DefaultTenantHeader, slug)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+
+	var data map[string]any
+	var tenant *requestTenant
+	rec := httptest.NewRecorder()
+	tenants.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		data, err = extractRequestData(r, parser.Route{Link: "/posts", Method: method}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		searchable := &parser.DomainConfig{Name: domain, Search: parser.SearchConfig{Columns: []string{"title"}}}
+		if err := addSearchConditions(r, searchable, interfaces.DriverSQLite, data); err != nil {
+			t.Fatal(err)
+		}
+		if scopeToTenant(w, r, appConfig, domain, data) {
+			tenant, _ = tenantFromContext(r.Context())
+		}
+	})).ServeHTTP(rec, r)
+	return data, tenant, rec.Code
+}
+
+// queryTitles runs query and returns the titles of the rows it selected
+func queryTitles(t *testing.T, executor *database.DatabaseExecutor, query string, data map[string]any) []string {
+	t.Helper()
+	rows, err := queryRows(context.Background(), executor, query, data)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	var titles []string
+	for _, row := range rows {
+		titles = append(titles, row["title"].(string))
+	}
+	return titles
+}
+
+func TestTenantLookupCache(t *testing.T) {
+	executor := newTenantDatabase(t)
+	tenants := newTestTenancy(t, executor, parser.TenantByPath)
+	ctx := context.Background()
+
+	for _, slug := range []string{"acme", "posts"} {
+		if _, err := tenants.lookup(ctx, slug); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := executor.ExecuteSQL(ctx, "UPDATE tenants SET slug = 'posts' WHERE id = 1", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both slugs are answered from the cache until it expires
+	if row, err := tenants.lookup(ctx, "acme"); err != nil || row == nil || row["id"] != int64(1) {
+		t.Errorf("Expected acme to stay cached, got %v, %v", row, err)
+	}
+	if row, err := tenants.lookup(ctx, "posts"); err != nil || row != nil {
+		t.Errorf("Expected posts to stay unknown, got %v, %v", row, err)
+	}
+
+	tenants.cache["posts"] = cachedTenant{readAt: time.Now().Add(-tenantCacheTTL)}
+	if row, err := tenants.lookup(ctx, "posts"); err != nil || row == nil || row["id"] != int64(1) {
+		t.Errorf("Expected an expired entry to be read again, got %v, %v", row, err)
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	executor := newTenantDatabase(t)
+	tenants := newTestTenancy(t, executor, parser.TenantByHeader)
+	appConfig := &parser.AppConfig{Tenancy: parser.TenancyConfig{Resolver: parser.TenantByHeader}, Auth: parser.AuthConfig{Disabled: true}}
+
+	// The SQL the generated index, show and update templates render once search_where
+	// and tenant_scope prefix="AND" have been expanded
+	index := func(data map[string]any) string {
+		return "SELECT * FROM posts WHERE " + data[views.SearchWhereKey].(string)
+	}
+	show := func(data map[string]any) string {
+		return "SELECT * FROM posts WHERE id = :posts_id AND " + data[views.TenantScopeKey].(string)
+	}
+	update := func(data map[string]any) string {
+		return `UPDATE posts SET "title" = :title WHERE id = :posts_id AND ` + data[views.TenantScopeKey].(string)
+	}
+
+	t.Run("index lists the tenant's rows", func(t *testing.T) {
+		data, _, _ := tenantRequest(t, tenants, appConfig, "posts", "acme", "/posts", nil, nil)
+		if titles := queryTitles(t, executor, index(data), data); len(titles) != 1 || titles[0] != "Acme news" {
+			t.Errorf("Expected only Acme's post, got %v", titles)
+		}
+		if data[tenantKey].(map[string]any)["name"] != "Acme" {
+			t.Errorf("Expected the tenant row in the request data, got %v", data[tenantKey])
+		}
+	})
+
+	t.Run("search keeps the tenant scope", func(t *testing.T) {
+		data, _, _ := tenantRequest(t, tenants, appConfig, "posts", "acme", "/posts?q=news", nil, nil)
+		if titles := queryTitles(t, executor, index(data), data); len(titles) != 1 || titles[0] != "Acme news" {
+			t.Errorf("Expected only Acme's post, got %v", titles)
+		}
+	})
+
+	t.Run("show cannot read another tenant's row", func(t *testing.T) {
+		data, _, _ := tenantRequest(t, tenants, appConfig, "posts", "acme", "/posts?posts_id=2", nil, nil)
+		if titles := queryTitles(t, executor, show(data), data); len(titles) != 0 {
+			t.Errorf("Expected Globex's post to be hidden from Acme, got %v", titles)
+		}
+	})
+
+	t.Run("update cannot change another tenant's row", func(t *testing.T) {
+		data, _, _ := tenantRequest(t, tenants, appConfig, "posts", "acme", "/posts", map[string]string{"posts_id": "2", "title": "Hijacked"}, nil)
+		if _, err := queryRows(context.Background(), executor, update(data), data); err != nil {
+			t.Fatal(err)
+		}
+		other, _, _ := tenantRequest(t, tenants, appConfig, "posts", "globex", "/posts?posts_id=2", nil, nil)
+		if titles := queryTitles(t, executor, show(other), other); len(titles) != 1 || titles[0] != "Globex news" {
+			t.Errorf("Expected Globex's post to be untouched, got %v", titles)
+		}
+	})
+
+	t.Run("create is assigned to the tenant", func(t *testing.T) {
+		// A submitted tenant_id or scope is ignored in favour of the resolved tenant
+		data, tenant, _ := tenantRequest(t, tenants, appConfig, "posts", "globex", "/posts", map[string]string{"title": "Made+by+Globex", "tenant_id": "1", views.TenantScopeKey: "1=1"}, nil)
+		for _, insert := range []string{
+			`INSERT INTO posts ("title") VALUES (:title)`,
+			`INSERT INTO posts ("title", "tenant_id") VALUES (:title, :tenant_id)`,
+		} {
+			if _, err := queryRows(context.Background(), executor, tenant.scopeInserts(insert), data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		acme, _, _ := tenantRequest(t, tenants, appConfig, "posts", "acme", "/posts", nil, nil)
+		if titles := queryTitles(t, executor, index(acme), acme); len(titles) != 1 {
+			t.Errorf("Expected Globex's posts to stay out of Acme, got %v", titles)
+		}
+		globex, _, _ := tenantRequest(t, tenants, appConfig, "posts", "globex", "/posts", nil, nil)
+		if titles := queryTitles(t, executor, index(globex), globex); len(titles) != 3 {
+			t.Errorf("Expected both new posts in Globex, got %v", titles)
+		}
+	})
+
+	t.Run("shared tables are not scoped", func(t *testing.T) {
+		data, _, status := tenantRequest(t, tenants, appConfig, "tags", "acme", "/tags", nil, nil)
+		if status != http.StatusOK {
+			t.Fatalf("Expected the request to be allowed, got %d", status)
+		}
+		if _, ok := data[views.TenantScopeKey]; ok {
+			t.Error("Expected no tenant scope for a table without tenant_id")
+		}
+		if data[tenantIDKey] != int64(1) {
+			t.Errorf("Expected the tenant id in the request data, got %v", data[tenantIDKey])
+		}
+	})
+
+	t.Run("missing tenant is not found", func(t *testing.T) {
+		if _, _, status := tenantRequest(t, tenants, appConfig, "posts", "", "/posts", nil, nil); status != http.StatusNotFound {
+			t.Errorf("Expected 404 without a tenant, got %d", status)
+		}
+	})
+}
+
+func TestTenantMembership(t *testing.T) {
+	executor := newTenantDatabase(t)
+	tenants := newTestTenancy(t, executor, parser.TenantByHeader)
+	appConfig := &parser.AppConfig{Tenancy: parser.TenancyConfig{Resolver: parser.TenantByHeader}}
+
+	store := auth.NewJWTSessionStore([]byte("test-secret"))
+	auth.SetSessionStore(store)
+	t.Cleanup(func() { auth.SetSessionStore(auth.NewJWTSessionStore([]byte("test-secret"))) })
+	token, err := store.Create(context.Background(), auth.User{Id: 7, Username: "ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookie := &http.Cookie{Name: "auth_token", Value: token}
+
+	tests := []struct {
+		name   string
+		slug   string
+		cookie *http.Cookie
+		status int
+	}{
+		{name: "member", slug: "acme", cookie: cookie, status: http.StatusOK},
+		{name: "other tenant", slug: "globex", cookie: cookie, status: http.StatusNotFound},
+		{name: "signed out", slug: "acme", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, status := tenantRequest(t, tenants, appConfig, "posts", tt.slug, "/posts", nil, tt.cookie); status != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, status)
+			}
+		})
+	}
+}
+
+func TestScopeInserts(t *testing.T) {
+	tenant := &requestTenant{id: int64(1), tenancy: &tenancy{scoped: map[string]bool{"posts": true}}}
+
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{
+			name:     "adds the column",
+			sql:      `INSERT INTO posts ("title", "body") VALUES ({{title}}, {{body}});`,
+			expected: `INSERT INTO posts (tenant_id, "title", "body") VALUES (:_tenant_id, {{title}}, {{body}});`,
+		},
+		{
+			name:     "quoted table",
+			sql:      "insert into `posts`(`title`) values (:title)",
+			expected: "insert into `posts`(tenant_id, `title`) values (:_tenant_id, :title)",
+		},
+		{
+			name:     "column already listed",
+			sql:      `INSERT INTO posts ("tenant_id", "title") VALUES (:tenant_id, :title)`,
+			expected: `INSERT INTO posts ("tenant_id", "title") VALUES (:tenant_id, :title)`,
+		},
+		{
+			name:     "shared table",
+			sql:      `INSERT INTO tags (name) VALUES (:name)`,
+			expected: `INSERT INTO tags (name) VALUES (:name)`,
+		},
+		{
+			name:     "every statement",
+			sql:      `INSERT INTO tags (name) VALUES (:name); INSERT INTO posts (title) VALUES (:title);`,
+			expected: `INSERT INTO tags (name) VALUES (:name); INSERT INTO posts (tenant_id, title) VALUES (:_tenant_id, :title);`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tenant.scopeInserts(tt.sql); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package framework
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fulcrum/handler"
+	"fulcrum/lib/database"
+	"fulcrum/lib/database/interfaces"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/tracing"
+	"fulcrum/lib/views"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// emptyDatabase answers every query with no rows
+type emptyDatabase struct {
+	slowDatabase
+}
+
+func (e *emptyDatabase) Query(ctx context.Context, query string, args ...any) (interfaces.Rows, error) {
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Close() error                            { return nil }
+func (emptyRows) ColumnTypes() ([]*sql.ColumnType, error) { return nil, nil }
+func (emptyRows) Columns() ([]string, error)              { return []string{}, nil }
+func (emptyRows) Err() error                              { return nil }
+func (emptyRows) Next() bool                              { return false }
+func (emptyRows) NextResultSet() bool                     { return false }
+func (emptyRows) Scan(dest ...any) error                  { return nil }
+
+// metadataHandlerClient records the gRPC metadata each handler call carries
+type metadataHandlerClient struct {
+	stubHandlerClient
+	metadata metadata.MD
+}
+
+func (m *metadataHandlerClient) ProcessData(ctx context.Context, in *handler.HandlerRequest, opts ...grpc.CallOption) (*handler.HandlerResponse, error) {
+	m.metadata, _ = metadata.FromOutgoingContext(ctx)
+	return m.stubHandlerClient.ProcessData(ctx, in, opts...)
+}
+
+func TestTracingSpanHierarchy(t *testing.T) {
+	t.Setenv("FULCRUM_ENV", "production")
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracing.Use(provider)
+	t.Cleanup(tracing.Disable)
+
+	dir := t.TempDir()
+	viewPath := filepath.Join(dir, "get.html.hbs")
+	sqlPath := filepath.Join(dir, "get.sql.hbs")
+	if err := os.WriteFile(viewPath, []byte("<p>traced</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sqlPath, []byte("SELECT * FROM orders WHERE id = :id"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	routes := []parser.Route{
+		{Link: "/auth/traced/[id]", Method: "GET", Format: "html", ViewPath: viewPath},
+		{Link: "/auth/traced/[id]", Method: "GET", Format: "sql", ViewPath: sqlPath},
+	}
+	appConfig := &parser.AppConfig{
+		Domains: []parser.DomainConfig{
+			{Name: "auth", Logic: parser.LogicConfig{HTTP: parser.HTTPConfig{Routes: routes}}},
+		},
+		Views: views.NewTemplateRenderer(),
+	}
+
+	frameworkServer := newStubbedFrameworkServer(t, map[string]any{"name": "order"}, nil)
+	handlerClient := &metadataHandlerClient{stubHandlerClient: stubHandlerClient{response: &handler.HandlerResponse{Success: true}}}
+	frameworkServer.ProcessManager.UseHandlerClient(handlerClient)
+	frameworkServer.DbExecutor = database.NewDatabaseExecutor(&emptyDatabase{})
+
+	rec := httptest.NewRecorder()
+	CreateRouteDispatcher(appConfig, frameworkServer).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/traced/7", nil))
+
+	// The database has no order 7, so the page renders as not found
+	if rec.Code != http.StatusNotFound || rec.Body.String() != "<p>traced</p>" {
+		t.Fatalf("Expected the page rendered with status %d, got %d: %s", http.StatusNotFound, rec.Code, rec.Body.String())
+	}
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+
+	root, exists := spans["GET /auth/traced/[id]"]
+	if !exists {
+		t.Fatalf("Expected a root span named by the route pattern, got %v", spanNames(spans))
+	}
+	if root.Parent.IsValid() {
+		t.Error("Expected the request span to be the root")
+	}
+
+	for _, name := range []string{"sql.render", "sql.execute", "handler.execute", "template.render"} {
+		span, exists := spans[name]
+		if !exists {
+			t.Errorf("Expected a %s span, got %v", name, spanNames(spans))
+			continue
+		}
+		if span.Parent.SpanID() != root.SpanContext.SpanID() {
+			t.Errorf("Expected %s to be a child of the request span", name)
+		}
+	}
+
+	query := attributeValue(spans["sql.execute"].Attributes, "db.query.text")
+	if query != "SELECT * FROM orders WHERE id = $1" {
+		t.Errorf("Expected the placeholder query without parameters, got %q", query)
+	}
+
+	if traceparent := handlerClient.metadata.Get("traceparent"); len(traceparent) == 0 {
+		t.Error("Expected the handler call to carry a traceparent")
+	} else if traceID := root.SpanContext.TraceID().String(); len(traceparent[0]) < 35 || traceparent[0][3:35] != traceID {
+		t.Errorf("Expected traceparent for trace %s, got %s", traceID, traceparent[0])
+	}
+}
+
+func TestTracingDisabledDoesNotAllocate(t *testing.T) {
+	tracing.Disable()
+	ctx := context.Background()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		spanCtx, span := tracing.Start(ctx, "sql.execute")
+		tracing.SetString(span, "db.query.text", "SELECT 1")
+		tracing.RecordError(span, nil)
+		span.End()
+		_ = tracing.InjectGRPC(spanCtx)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations with tracing disabled, got %v", allocs)
+	}
+}
+
+func spanNames(spans map[string]tracetest.SpanStub) []string {
+	names := make([]string, 0, len(spans))
+	for name := range spans {
+		names = append(names, name)
+	}
+	return names
+}
+
+func attributeValue(attributes []attribute.KeyValue, key string) string {
+	for _, kv := range attributes {
+		if string(kv.Key) == key {
+			return kv.Value.AsString()
+		}
+	}
+	return ""
+}
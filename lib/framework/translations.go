@@ -0,0 +1,33 @@
+package framework
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"fulcrum/lib/i18n"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+// LoadTranslations builds the translation catalog from the built-in auth strings, the
+// project's locales directory and then each domain's, later files overriding earlier
+func LoadTranslations(appConfig *parser.AppConfig) (*i18n.Catalog, error) {
+	catalog := i18n.NewCatalog(appConfig.I18n.DefaultLocale)
+	if err := catalog.LoadFS(views.AuthFiles(), i18n.LocaleDir); err != nil {
+		return nil, fmt.Errorf("failed to load built-in translations: %w", err)
+	}
+
+	dirs := []string{filepath.Join(appConfig.Path, i18n.LocaleDir)}
+	for _, domain := range appConfig.Domains {
+		dirs = append(dirs, filepath.Join(domain.Path, i18n.LocaleDir))
+	}
+	for _, dir := range dirs {
+		if err := catalog.LoadDir(dir); err != nil {
+			return nil, fmt.Errorf("failed to load translations: %w", err)
+		}
+	}
+
+	log.Printf("🌐 Loaded translations for %v (default %s)", catalog.Locales(), catalog.DefaultLocale())
+	return catalog, nil
+}
@@ -0,0 +1,316 @@
+package framework
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Registers JPEG for image.DecodeConfig
+	_ "image/png"  // Registers PNG for image.DecodeConfig
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	parser "fulcrum/lib/parser"
+
+	_ "golang.org/x/image/webp" // Registers WebP for image.DecodeConfig
+)
+
+// errUploadTooLarge is returned when a multipart request exceeds server.uploads.max_size_mb
+var errUploadTooLarge = errors.New("upload too large")
+
+// UploadedFile is a file posted in a multipart form. Once stored, its SQL value is the
+// path Storage saved it under, so a template can insert :avatar like any other field.
+type UploadedFile struct {
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+	Path        string `json:"path,omitempty"` // Set once the file is stored
+
+	header *multipart.FileHeader
+}
+
+// Open returns a reader for the file's contents
+func (f *UploadedFile) Open() (multipart.File, error) {
+	return f.header.Open()
+}
+
+// Value implements driver.Valuer so SQL parameters store the file's path
+func (f *UploadedFile) Value() (driver.Value, error) {
+	return f.Path, nil
+}
+
+// String returns the file's path, which is what templates render for it
+func (f *UploadedFile) String() string {
+	return f.Path
+}
+
+// Storage persists uploaded files, returning the path a file can be found under later
+type Storage interface {
+	Save(ctx context.Context, file *UploadedFile) (string, error)
+}
+
+// LocalStorage saves uploads in a directory on disk under generated names
+type LocalStorage struct {
+	Dir string
+}
+
+// Save copies the file into Dir, keeping only its extension from the client's name
+func (s LocalStorage) Save(ctx context.Context, file *UploadedFile) (string, error) {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to name upload: %w", err)
+	}
+	name := hex.EncodeToString(suffix) + strings.ToLower(filepath.Ext(filepath.Base(file.Filename)))
+
+	src, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open upload %s: %w", file.Filename, err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(filepath.Join(s.Dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to store upload %s: %w", file.Filename, err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return "", fmt.Errorf("failed to store upload %s: %w", file.Filename, err)
+	}
+	if err := dst.Close(); err != nil {
+		return "", fmt.Errorf("failed to store upload %s: %w", file.Filename, err)
+	}
+	return name, nil
+}
+
+// uploadsContextKey holds a request's uploadSettings
+type uploadsContextKey struct{}
+
+// uploadSettings are the upload limits and storage a route's requests use
+type uploadSettings struct {
+	maxMemory int64
+	storage   Storage
+}
+
+// withUploads caps the body of multipart requests at server.uploads.max_size_mb and
+// passes the upload settings on to extractRequestData
+func withUploads(next http.HandlerFunc, appConfig *parser.AppConfig) http.HandlerFunc {
+	config := appConfig.Server.Uploads
+	dir := config.Directory()
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(appConfig.Path, dir)
+	}
+	settings := uploadSettings{maxMemory: config.MaxMemory(), storage: LocalStorage{Dir: dir}}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isMultipartRequest(r) {
+			r.Body = http.MaxBytesReader(w, r.Body, config.MaxSize())
+			r = r.WithContext(context.WithValue(r.Context(), uploadsContextKey{}, settings))
+		}
+		next(w, r)
+	}
+}
+
+// isMultipartRequest reports whether r's body is multipart/form-data
+func isMultipartRequest(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "multipart/form-data"
+}
+
+// decodeMultipartBody adds the values and files of r's multipart body to params
+func decodeMultipartBody(r *http.Request, params map[string]any) error {
+	settings, ok := r.Context().Value(uploadsContextKey{}).(uploadSettings)
+	if !ok {
+		settings.maxMemory = parser.UploadsConfig{}.MaxMemory()
+	}
+
+	if err := r.ParseMultipartForm(settings.maxMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return fmt.Errorf("%w: the limit is %d bytes", errUploadTooLarge, tooLarge.Limit)
+		}
+		return fmt.Errorf("invalid multipart body: %w", err)
+	}
+
+	for k, v := range r.MultipartForm.Value {
+		if reservedRequestKeys[k] {
+			continue
+		}
+		if len(v) == 1 {
+			params[k] = v[0]
+		} else {
+			params[k] = v
+		}
+	}
+
+	for k, headers := range r.MultipartForm.File {
+		if reservedRequestKeys[k] {
+			continue
+		}
+		files := make([]*UploadedFile, 0, len(headers))
+		for _, header := range headers {
+			files = append(files, &UploadedFile{
+				Filename:    filepath.Base(header.Filename),
+				Size:        header.Size,
+				ContentType: header.Header.Get("Content-Type"),
+				header:      header,
+			})
+		}
+		if len(files) == 1 {
+			params[k] = files[0]
+		} else {
+			params[k] = files
+		}
+	}
+	return nil
+}
+
+// storeUploads saves the uploaded files left in params once they are bound, when
+// withUploads set up storage for the request
+func storeUploads(r *http.Request, params map[string]any) error {
+	settings, ok := r.Context().Value(uploadsContextKey{}).(uploadSettings)
+	if !ok || settings.storage == nil {
+		return nil
+	}
+
+	for _, value := range params {
+		files, _ := value.([]*UploadedFile)
+		if file, ok := value.(*UploadedFile); ok {
+			files = []*UploadedFile{file}
+		}
+		for _, file := range files {
+			path, err := settings.storage.Save(r.Context(), file)
+			if err != nil {
+				return err
+			}
+			file.Path = path
+		}
+	}
+	return nil
+}
+
+// imageTypes are the image formats image fields accept, with the names used in errors
+var imageTypes = map[string]string{"image/png": "PNG", "image/jpeg": "JPEG", "image/webp": "WebP"}
+
+// ImageRules limit the images an image field accepts; zero values don't limit
+type ImageRules struct {
+	MaxWidth  int
+	MaxHeight int
+	MaxSize   int64 // In bytes
+}
+
+// imageRules reads an image field's max_width, max_height and max_size_kb validations
+func imageRules(field parser.Field) ImageRules {
+	number := func(name string) int {
+		value, _ := field.GetValidation(name)
+		n, _ := value.(int)
+		return n
+	}
+	return ImageRules{
+		MaxWidth:  number(parser.ValidateMaxWidth),
+		MaxHeight: number(parser.ValidateMaxHeight),
+		MaxSize:   int64(number(parser.ValidateMaxSizeKB)) << 10,
+	}
+}
+
+// ValidateImage checks that file is a PNG, JPEG or WebP image whose content matches the
+// content type it was sent with, and that it fits rules
+func ValidateImage(file *UploadedFile, rules ImageRules) error {
+	if rules.MaxSize > 0 && file.Size > rules.MaxSize {
+		return fmt.Errorf("must be at most %d KB", rules.MaxSize>>10)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("could not be read: %w", err)
+	}
+	defer src.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("could not be read: %w", err)
+	}
+	sniffed := http.DetectContentType(head[:n])
+	if _, ok := imageTypes[sniffed]; !ok {
+		return fmt.Errorf("must be a PNG, JPEG or WebP image")
+	}
+
+	declared, _, _ := mime.ParseMediaType(file.ContentType)
+	if declared == "image/jpg" || declared == "image/pjpeg" {
+		declared = "image/jpeg"
+	}
+	if declared != sniffed {
+		return fmt.Errorf("is a %s image but was sent as %q", imageTypes[sniffed], file.ContentType)
+	}
+
+	if rules.MaxWidth > 0 || rules.MaxHeight > 0 {
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("could not be read: %w", err)
+		}
+		config, _, err := image.DecodeConfig(src)
+		if err != nil {
+			return fmt.Errorf("is not a valid %s image", imageTypes[sniffed])
+		}
+		if (rules.MaxWidth > 0 && config.Width > rules.MaxWidth) || (rules.MaxHeight > 0 && config.Height > rules.MaxHeight) {
+			return fmt.Errorf("must be at most %dx%d pixels, got %dx%d", rules.MaxWidth, rules.MaxHeight, config.Width, config.Height)
+		}
+	}
+	return nil
+}
+
+// SaveImage validates an uploaded image and stores it, returning the path to keep in
+// the database
+func SaveImage(ctx context.Context, file *UploadedFile, storage Storage, rules ImageRules) (string, error) {
+	if err := ValidateImage(file, rules); err != nil {
+		return "", fmt.Errorf("%s %w", file.Filename, err)
+	}
+	path, err := storage.Save(ctx, file)
+	if err != nil {
+		return "", err
+	}
+	file.Path = path
+	return path, nil
+}
+
+// bindImage binds the value of an image field: an upload that passes ValidateImage,
+// or nil when no file was chosen
+func bindImage(value any, field parser.Field) (any, error) {
+	if values, ok := value.([]string); ok && len(values) == 1 {
+		value = values[0]
+	}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		// A file input left empty submits an empty value
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+	case *UploadedFile:
+		if err := ValidateImage(v, imageRules(field)); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("must be an uploaded image")
+}
+
+// requestDataStatus is the status for an error from extractRequestData
+func requestDataStatus(err error) int {
+	if errors.Is(err, errUploadTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,205 @@
+package framework
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	parser "fulcrum/lib/parser"
+)
+
+// multipartRequest builds a POST with a name field and an avatar file sent as contentType
+func multipartRequest(t *testing.T, link, contentType string, content []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("name", "Ada"); err != nil {
+		t.Fatal(err)
+	}
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="avatar"; filename="../../Portrait.PNG"`)
+	header.Set("Content-Type", contentType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	form.Close()
+
+	r := httptest.NewRequest(http.MethodPost, link, &body)
+	r.Header.Set("Content-Type", form.FormDataContentType())
+	return r
+}
+
+func TestMultipartUpload(t *testing.T) {
+	appConfig := &parser.AppConfig{Path: t.TempDir()}
+	domain := &parser.DomainConfig{
+		Name:   "users",
+		Models: []parser.ModelDefinition{{"user": parser.Model{"name": {Type: "string"}, "avatar": {Type: "string"}}}},
+	}
+	content := []byte("\x89PNG fake image")
+
+	tests := []struct {
+		name  string
+		route parser.Route
+	}{
+		{name: "plain route", route: parser.Route{Link: "/users/avatar", Method: "POST"}},
+		{name: "create route binds the file to the model", route: parser.Route{Link: "/users/create", Method: "POST"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data map[string]any
+			handler := withUploads(func(w http.ResponseWriter, r *http.Request) {
+				var err error
+				data, err = extractRequestData(r, tt.route, domain)
+				if err != nil {
+					http.Error(w, err.Error(), requestDataStatus(err))
+				}
+			}, appConfig)
+
+			w := httptest.NewRecorder()
+			handler(w, multipartRequest(t, tt.route.Link, "image/png", content))
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			if data["name"] != "Ada" {
+				t.Errorf("Expected the form field, got %#v", data["name"])
+			}
+			file, ok := data["avatar"].(*UploadedFile)
+			if !ok {
+				t.Fatalf("Expected an *UploadedFile, got %#v", data["avatar"])
+			}
+			if file.Filename != "Portrait.PNG" || file.Size != int64(len(content)) || file.ContentType != "image/png" {
+				t.Errorf("Unexpected file metadata: %+v", file)
+			}
+			if filepath.Ext(file.Path) != ".png" || filepath.Base(file.Path) != file.Path {
+				t.Errorf("Expected a generated .png name, got %q", file.Path)
+			}
+
+			stored, err := os.ReadFile(filepath.Join(appConfig.Path, "uploads", file.Path))
+			if err != nil {
+				t.Fatalf("Expected the file to be stored: %v", err)
+			}
+			if !bytes.Equal(stored, content) {
+				t.Errorf("Expected stored content %q, got %q", content, stored)
+			}
+
+			reader, err := file.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			if read, _ := io.ReadAll(reader); !bytes.Equal(read, content) {
+				t.Errorf("Expected Open to read %q, got %q", content, read)
+			}
+			if value, _ := file.Value(); value != file.Path {
+				t.Errorf("Expected the SQL value to be the stored path, got %#v", value)
+			}
+		})
+	}
+}
+
+func TestMultipartUploadTooLarge(t *testing.T) {
+	dir := t.TempDir()
+	appConfig := &parser.AppConfig{Path: dir, Server: parser.ServerConfig{Uploads: parser.UploadsConfig{MaxSizeMB: 1}}}
+	route := parser.Route{Link: "/users/avatar", Method: "POST"}
+
+	handler := withUploads(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := extractRequestData(r, route, nil); err != nil {
+			http.Error(w, err.Error(), requestDataStatus(err))
+		}
+	}, appConfig)
+
+	w := httptest.NewRecorder()
+	handler(w, multipartRequest(t, route.Link, "image/png", bytes.Repeat([]byte("x"), 2<<20)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected 413, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "uploads")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be stored, got %v", err)
+	}
+}
+
+// pngImage encodes a blank width x height PNG
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageUpload(t *testing.T) {
+	domain := &parser.DomainConfig{
+		Name: "users",
+		Models: []parser.ModelDefinition{{"user": parser.Model{
+			"name": {Type: "string"},
+			"avatar": {Type: "image", Validations: []parser.Validation{
+				{parser.ValidateMaxWidth: 64, parser.ValidateMaxHeight: 64},
+				{parser.ValidateMaxSizeKB: 4},
+			}},
+		}}},
+	}
+	route := parser.Route{Link: "/users/create", Method: "POST"}
+
+	tests := []struct {
+		name        string
+		contentType string
+		content     []byte
+		err         string
+	}{
+		{name: "allowed png", contentType: "image/png", content: pngImage(t, 32, 32)},
+		{name: "gif sent as png", contentType: "image/png", content: []byte("GIF89a\x01\x00\x01\x00"), err: "avatar must be a PNG, JPEG or WebP image"},
+		{name: "html sent as png", contentType: "image/png", content: []byte("<html><script>alert(1)</script></html>"), err: "avatar must be a PNG, JPEG or WebP image"},
+		{name: "png sent as jpeg", contentType: "image/jpeg", content: pngImage(t, 32, 32), err: `avatar is a PNG image but was sent as "image/jpeg"`},
+		{name: "too wide", contentType: "image/png", content: pngImage(t, 128, 32), err: "avatar must be at most 64x64 pixels, got 128x32"},
+		{name: "too large", contentType: "image/png", content: append(pngImage(t, 32, 32), make([]byte, 5<<10)...), err: "avatar must be at most 4 KB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appConfig := &parser.AppConfig{Path: t.TempDir()}
+			var data map[string]any
+			var err error
+			handler := withUploads(func(w http.ResponseWriter, r *http.Request) {
+				data, err = extractRequestData(r, route, domain)
+			}, appConfig)
+			handler(httptest.NewRecorder(), multipartRequest(t, route.Link, tt.contentType, tt.content))
+
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Expected error %q, got %v", tt.err, err)
+				}
+				if _, statErr := os.Stat(filepath.Join(appConfig.Path, "uploads")); !os.IsNotExist(statErr) {
+					t.Errorf("Expected a rejected image not to be stored, got %v", statErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			file, ok := data["avatar"].(*UploadedFile)
+			if !ok {
+				t.Fatalf("Expected an *UploadedFile, got %#v", data["avatar"])
+			}
+			if _, err := os.Stat(filepath.Join(appConfig.Path, "uploads", file.Path)); err != nil {
+				t.Errorf("Expected the image to be stored: %v", err)
+			}
+			if file.String() != file.Path {
+				t.Errorf("Expected templates to render the stored path, got %q", file.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package i18n
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultLocale is the locale used when fulcrum.yml does not set i18n.default_locale
+const DefaultLocale = "en"
+
+// LocaleDir is the directory holding <lang>.yml files, in the project and in each domain
+const LocaleDir = "locales"
+
+// LocaleCookie is the cookie that remembers a visitor's language
+const LocaleCookie = "lang"
+
+// LocalePattern matches the locale tags translation files and requests may use, e.g. en or pt-BR
+var LocalePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// paramPattern matches %{name} placeholders in translations
+var paramPattern = regexp.MustCompile(`%\{(\w+)\}`)
+
+// Catalog holds translations per locale, keyed by dotted path such as "users.index.title"
+type Catalog struct {
+	defaultLocale string
+	messages      map[string]map[string]string
+
+	mu      sync.Mutex
+	missing map[string]bool
+}
+
+// NewCatalog returns an empty catalog falling back to defaultLocale; "" means en
+func NewCatalog(defaultLocale string) *Catalog {
+	if defaultLocale == "" {
+		defaultLocale = DefaultLocale
+	}
+	return &Catalog{
+		defaultLocale: defaultLocale,
+		messages:      make(map[string]map[string]string),
+		missing:       make(map[string]bool),
+	}
+}
+
+// DefaultLocale returns the locale every lookup falls back to
+func (c *Catalog) DefaultLocale() string {
+	return c.defaultLocale
+}
+
+// Locales returns the locales with at least one translation, sorted
+func (c *Catalog) Locales() []string {
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Add merges translations for locale, overriding keys already loaded. Nested maps
+// become dotted keys.
+func (c *Catalog) Add(locale string, translations map[string]any) {
+	messages, ok := c.messages[locale]
+	if !ok {
+		messages = make(map[string]string)
+		c.messages[locale] = messages
+	}
+	flatten("", translations, messages)
+}
+
+// flatten writes value's leaves to messages under dotted keys
+func flatten(prefix string, value any, messages map[string]string) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			flatten(join(key), child, messages)
+		}
+	case map[any]any:
+		for key, child := range v {
+			flatten(join(fmt.Sprint(key)), child, messages)
+		}
+	case nil:
+	default:
+		messages[prefix] = fmt.Sprint(v)
+	}
+}
+
+// LoadFS loads every <lang>.yml in dir of fsys; a missing dir loads nothing
+func (c *Catalog) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+		locale := strings.TrimSuffix(entry.Name(), ext)
+		if !LocalePattern.MatchString(locale) {
+			log.Printf("⚠️ Skipping translation file %s: %q is not a locale", entry.Name(), locale)
+			continue
+		}
+
+		source, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		var translations map[string]any
+		if err := yaml.Unmarshal(source, &translations); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path.Join(dir, entry.Name()), err)
+		}
+		c.Add(locale, translations)
+	}
+	return nil
+}
+
+// LoadDir loads every <lang>.yml in a directory on disk
+func (c *Catalog) LoadDir(dir string) error {
+	return c.LoadFS(os.DirFS(filepath.Dir(dir)), filepath.Base(dir))
+}
+
+// Translate looks key up for locale, falling back to its base language (pt-BR to pt),
+// then the default locale. %{name} placeholders are filled from params. A key found
+// nowhere renders as itself and is logged the first time it is missed.
+func (c *Catalog) Translate(locale, key string, params map[string]any) string {
+	for _, candidate := range c.fallbacks(locale) {
+		if message, ok := c.messages[candidate][key]; ok {
+			return interpolate(message, params)
+		}
+	}
+
+	c.mu.Lock()
+	logged := c.missing[key]
+	c.missing[key] = true
+	c.mu.Unlock()
+	if !logged {
+		log.Printf("⚠️ Missing translation %q (locale %s)", key, locale)
+	}
+	return key
+}
+
+// fallbacks returns the locales Translate tries for locale, in order
+func (c *Catalog) fallbacks(locale string) []string {
+	var chain []string
+	add := func(candidate string) {
+		for _, existing := range chain {
+			if strings.EqualFold(existing, candidate) {
+				return
+			}
+		}
+		chain = append(chain, candidate)
+	}
+
+	if locale != "" {
+		add(c.match(locale))
+		if base, _, found := strings.Cut(locale, "-"); found {
+			add(c.match(base))
+		}
+	}
+	add(c.defaultLocale)
+	if base, _, found := strings.Cut(c.defaultLocale, "-"); found {
+		add(base)
+	}
+	return chain
+}
+
+// match returns the loaded locale equal to locale ignoring case, or locale itself
+func (c *Catalog) match(locale string) string {
+	if _, ok := c.messages[locale]; ok {
+		return locale
+	}
+	for loaded := range c.messages {
+		if strings.EqualFold(loaded, locale) {
+			return loaded
+		}
+	}
+	return locale
+}
+
+// supports reports whether locale or its base language has translations
+func (c *Catalog) supports(locale string) bool {
+	if _, ok := c.messages[c.match(locale)]; ok {
+		return true
+	}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		_, ok := c.messages[c.match(base)]
+		return ok
+	}
+	return false
+}
+
+// interpolate replaces %{name} with params[name]; unknown names are left as written
+func interpolate(message string, params map[string]any) string {
+	if len(params) == 0 {
+		return message
+	}
+	return paramPattern.ReplaceAllStringFunc(message, func(placeholder string) string {
+		value, ok := params[placeholder[2:len(placeholder)-1]]
+		if !ok || value == nil {
+			return placeholder
+		}
+		return fmt.Sprint(value)
+	})
+}
+
+// ResolveLocale picks the request's locale from ?lang=, then the lang cookie, then
+// Accept-Language, taking the first the catalog has translations for. Requests
+// matching none get the default locale.
+func (c *Catalog) ResolveLocale(r *http.Request) string {
+	var candidates []string
+	candidates = append(candidates, r.URL.Query().Get("lang"))
+	if cookie, err := r.Cookie(LocaleCookie); err == nil {
+		candidates = append(candidates, cookie.Value)
+	}
+	candidates = append(candidates, acceptedLanguages(r.Header.Get("Accept-Language"))...)
+
+	for _, candidate := range candidates {
+		if LocalePattern.MatchString(candidate) && c.supports(candidate) {
+			return candidate
+		}
+	}
+	return c.defaultLocale
+}
+
+// acceptedLanguages returns an Accept-Language header's tags, most preferred first
+func acceptedLanguages(header string) []string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if tag == "" || tag == "*" || quality <= 0 {
+			continue
+		}
+		languages = append(languages, language{tag, quality})
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].quality > languages[j].quality })
+	tags := make([]string, len(languages))
+	for i, l := range languages {
+		tags[i] = l.tag
+	}
+	return tags
+}
+
+// catalog is the catalog the t helper and request locale resolution use
+var catalog = NewCatalog(DefaultLocale)
+
+// SetCatalog replaces the catalog loaded at startup
+func SetCatalog(c *Catalog) {
+	catalog = c
+}
+
+// T translates key for locale with the startup catalog
+func T(locale, key string, params map[string]any) string {
+	return catalog.Translate(locale, key, params)
+}
+
+// ResolveLocale picks the request's locale with the startup catalog
+func ResolveLocale(r *http.Request) string {
+	return catalog.ResolveLocale(r)
+}
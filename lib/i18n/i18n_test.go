@@ -0,0 +1,190 @@
+package i18n
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// newTestCatalog has en, fr and pt translations with pt-BR overriding one key
+func newTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	catalog := NewCatalog("en")
+	files := fstest.MapFS{
+		"locales/en.yml":    {Data: []byte("users:\n  index:\n    title: Users\n  greeting: Hello, %{name}!\nonly_en: English only\n")},
+		"locales/fr.yml":    {Data: []byte("users:\n  index:\n    title: Utilisateurs\n")},
+		"locales/pt.yml":    {Data: []byte("users:\n  index:\n    title: Usuários\n  greeting: Olá, %{name}!\n")},
+		"locales/pt-BR.yml": {Data: []byte("users:\n  greeting: Oi, %{name}!\n")},
+		"locales/notes.txt": {Data: []byte("ignored")},
+	}
+	if err := catalog.LoadFS(files, LocaleDir); err != nil {
+		t.Fatal(err)
+	}
+	return catalog
+}
+
+func TestTranslateFallbacks(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	tests := []struct {
+		name     string
+		locale   string
+		key      string
+		expected string
+	}{
+		{"exact locale", "fr", "users.index.title", "Utilisateurs"},
+		{"regional locale", "pt-BR", "users.greeting", "Oi, %{name}!"},
+		{"regional falls back to base language", "pt-BR", "users.index.title", "Usuários"},
+		{"base language falls back to default", "fr", "only_en", "English only"},
+		{"unknown locale uses default", "de", "users.index.title", "Users"},
+		{"empty locale uses default", "", "users.index.title", "Users"},
+		{"locale case is ignored", "PT-br", "users.greeting", "Oi, %{name}!"},
+		{"missing key renders the key", "fr", "users.missing", "users.missing"},
+		{"branch key is not a message", "en", "users.index", "users.index"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := catalog.Translate(tt.locale, tt.key, nil); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTranslateInterpolation(t *testing.T) {
+	catalog := NewCatalog("en")
+	catalog.Add("en", map[string]any{
+		"greeting": "Hello, %{name}!",
+		"summary":  "%{count} posts by %{name}, %{count} in total",
+		"percent":  "100% done",
+	})
+
+	tests := []struct {
+		name     string
+		key      string
+		params   map[string]any
+		expected string
+	}{
+		{"named parameter", "greeting", map[string]any{"name": "Ada"}, "Hello, Ada!"},
+		{"repeated and non-string parameters", "summary", map[string]any{"name": "Ada", "count": 3}, "3 posts by Ada, 3 in total"},
+		{"missing parameter is left as written", "greeting", map[string]any{"other": "x"}, "Hello, %{name}!"},
+		{"nil parameter is left as written", "greeting", map[string]any{"name": nil}, "Hello, %{name}!"},
+		{"no parameters", "greeting", nil, "Hello, %{name}!"},
+		{"plain percent sign", "percent", map[string]any{"name": "Ada"}, "100% done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := catalog.Translate("en", tt.key, tt.params); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMissingKeyLoggedOnce(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	var logs bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&logs)
+	catalog.Translate("fr", "users.missing", nil)
+	catalog.Translate("en", "users.missing", nil)
+	catalog.Translate("en", "users.other", nil)
+	log.SetOutput(previous)
+
+	if count := strings.Count(logs.String(), `"users.missing"`); count != 1 {
+		t.Errorf("Expected users.missing to be logged once, got %d times:\n%s", count, logs.String())
+	}
+	if !strings.Contains(logs.String(), `"users.other"`) {
+		t.Errorf("Expected users.other to be logged, got:\n%s", logs.String())
+	}
+}
+
+func TestLaterFilesOverride(t *testing.T) {
+	project := filepath.Join(t.TempDir(), LocaleDir)
+	domain := filepath.Join(t.TempDir(), LocaleDir)
+	for dir, content := range map[string]string{
+		project: "users:\n  index:\n    title: People\n  new:\n    title: New person\n",
+		domain:  "users:\n  index:\n    title: Members\n",
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "en.yml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	catalog := newTestCatalog(t)
+	for _, dir := range []string{project, domain, filepath.Join(t.TempDir(), "missing")} {
+		if err := catalog.LoadDir(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := catalog.Translate("en", "users.index.title", nil); got != "Members" {
+		t.Errorf("Expected the domain override, got %q", got)
+	}
+	if got := catalog.Translate("en", "users.new.title", nil); got != "New person" {
+		t.Errorf("Expected the project translation, got %q", got)
+	}
+	if got := catalog.Translate("en", "only_en", nil); got != "English only" {
+		t.Errorf("Expected earlier keys to survive, got %q", got)
+	}
+}
+
+func TestLoadRejectsInvalidYAML(t *testing.T) {
+	catalog := NewCatalog("")
+	files := fstest.MapFS{"locales/en.yml": {Data: []byte("users: [unclosed\n")}}
+	if err := catalog.LoadFS(files, LocaleDir); err == nil {
+		t.Error("Expected invalid YAML to fail loading")
+	}
+	if catalog.DefaultLocale() != DefaultLocale {
+		t.Errorf("Expected the default locale to be %s, got %s", DefaultLocale, catalog.DefaultLocale())
+	}
+}
+
+func TestResolveLocale(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	tests := []struct {
+		name           string
+		query          string
+		cookie         string
+		acceptLanguage string
+		expected       string
+	}{
+		{name: "nothing requested", expected: "en"},
+		{name: "query param", query: "?lang=fr", cookie: "pt", acceptLanguage: "pt", expected: "fr"},
+		{name: "cookie beats header", cookie: "pt", acceptLanguage: "fr", expected: "pt"},
+		{name: "header", acceptLanguage: "fr-CA,fr;q=0.9,en;q=0.8", expected: "fr-CA"},
+		{name: "header quality order", acceptLanguage: "en;q=0.5, pt-BR;q=0.9, de", expected: "pt-BR"},
+		{name: "unsupported query falls through", query: "?lang=de", cookie: "fr", expected: "fr"},
+		{name: "invalid query falls through", query: "?lang=..%2Fetc", acceptLanguage: "pt", expected: "pt"},
+		{name: "zero quality is refused", acceptLanguage: "fr;q=0, de", expected: "en"},
+		{name: "wildcard header", acceptLanguage: "*", expected: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/users"+tt.query, nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: LocaleCookie, Value: tt.cookie})
+			}
+			if tt.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			if got := catalog.ResolveLocale(r); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
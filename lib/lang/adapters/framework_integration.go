@@ -63,6 +63,45 @@ func (fs *FrameworkServer) shouldStartHandlerService(handlersPath string) bool {
 	return hasHandlers
 }
 
+// CheckForRedirect reports whether handler result data carries a "_redirect"
+// instruction and returns its URL and status (303 See Other when unspecified)
+func CheckForRedirect(data any) (string, int, bool) {
+	dataMap, ok := data.(map[string]any)
+	if !ok {
+		return "", 0, false
+	}
+
+	redirect, ok := dataMap["_redirect"].(map[string]any)
+	if !ok {
+		return "", 0, false
+	}
+
+	url, _ := redirect["url"].(string)
+	if url == "" {
+		return "", 0, false
+	}
+
+	status := 0
+	switch v := redirect["status"].(type) {
+	case int:
+		status = v
+	case int32:
+		status = int(v)
+	case int64:
+		status = int(v)
+	case float64:
+		status = int(v)
+	case string:
+		status = parseStatusCode(v)
+	}
+
+	if status < 300 || status > 399 {
+		status = http.StatusSeeOther
+	}
+
+	return url, status, true
+}
+
 // Parse status code from string
 func parseStatusCode(s string) int {
 	switch s {
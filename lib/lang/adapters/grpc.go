@@ -1,6 +1,7 @@
 package lang_adapters
 
 import (
+	"container/heap"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -8,7 +9,9 @@ import (
 	"io"
 	"log"
 	"net"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	interfaces "fulcrum/lib/database/interfaces"
@@ -35,6 +38,37 @@ type PendingRequest struct {
 	RequestID string
 	Response  chan *RuntimeMessage
 	Timeout   time.Time
+
+	index int // Position in the expiry queue, -1 once removed
+}
+
+// requestTimeout is how long SendMessage waits for a domain to respond
+const requestTimeout = 30 * time.Second
+
+// Defaults for messages queued while a domain is reconnecting
+const (
+	DefaultOutboundQueueSize = 100
+	DefaultOutboundQueueTTL  = 10 * time.Second
+)
+
+// queuedState is how far a queued message got, guarded by StreamMutex. Flushing and
+// dropping each claim a waiting message before acting on it, so only one of them does.
+type queuedState int
+
+const (
+	queuedWaiting queuedState = iota // in the outbound queue
+	queuedSending                    // claimed by a flush, being written to a stream
+	queuedSent                       // written to a stream
+	queuedDropped                    // its caller gave up
+)
+
+// queuedMessage is an outbound message waiting for its domain to reconnect
+type queuedMessage struct {
+	message *RuntimeMessage
+	expires time.Time
+	state   queuedState
+	gaveUp  bool          // the caller gave up while a flush was sending it
+	settled chan struct{} // closed once the message is sent or dropped
 }
 
 type FrameworkServer struct {
@@ -47,10 +81,30 @@ type FrameworkServer struct {
 	StreamMutex     sync.RWMutex
 	RequestMutex    sync.RWMutex
 	ProcessManager  *ProcessManager
+	Router          *MessageRouter // Routes message types to domains; nil passes everything through
+
+	// OutboundQueueSize caps messages held per disconnected domain; 0 uses the default
+	OutboundQueueSize int
+	// OutboundQueueTTL is how long a queued message waits for its domain; 0 uses the default
+	OutboundQueueTTL time.Duration
+
+	outboundQueues map[string][]*queuedMessage
+	streamSeqs     map[string]uint64
+	inFlight       map[string]map[string]*RuntimeMessage
+	connectionSeq  atomic.Uint64
+	sendMutexes    sync.Map // Stream to the *sync.Mutex serializing its Sends
+
+	// domainRegistrationAttempts counts each domain's connections, telling a domain that
+	// is still starting (or misconfigured) from one that dropped
+	domainRegistrationAttempts map[string]int
+
+	expiries    pendingQueue  // PendingRequests ordered by Timeout, guarded by RequestMutex
+	cleanupWake chan struct{} // Wakes the cleanup routine when the earliest expiry changes
 }
 
 func (s *FrameworkServer) DomainCommunication(stream FrameworkService_DomainCommunicationServer) error {
 	log.Println("Domain connected to bidirectional stream")
+	defer s.sendMutexes.Delete(stream)
 
 	var domainName string
 
@@ -58,13 +112,12 @@ func (s *FrameworkServer) DomainCommunication(stream FrameworkService_DomainComm
 		// Receive message from domain
 		domainMsg, err := stream.Recv()
 		if err == io.EOF {
-			log.Printf("Domain %s disconnected", domainName)
-			s.removeDomainStream(domainName)
+			s.removeDomainStream(domainName, stream)
 			return nil
 		}
 		if err != nil {
 			log.Printf("Error receiving message: %v", err)
-			s.removeDomainStream(domainName)
+			s.removeDomainStream(domainName, stream)
 			return err
 		}
 
@@ -72,7 +125,6 @@ func (s *FrameworkServer) DomainCommunication(stream FrameworkService_DomainComm
 		if domainName == "" {
 			domainName = domainMsg.Domain
 			s.addDomainStream(domainName, stream)
-			log.Printf("Domain %s registered", domainName)
 		}
 
 		log.Printf("Received from domain %s: %s", domainMsg.Domain, domainMsg.Type)
@@ -83,8 +135,9 @@ func (s *FrameworkServer) DomainCommunication(stream FrameworkService_DomainComm
 		} else {
 			// Handle requests from domains (if any)
 			response := s.processMessage(domainMsg)
-			if err := stream.Send(response); err != nil {
+			if err := s.sendToStream(stream, response); err != nil {
 				log.Printf("Error sending response: %v", err)
+				s.removeDomainStream(domainName, stream)
 				return err
 			}
 		}
@@ -94,53 +147,35 @@ func (s *FrameworkServer) DomainCommunication(stream FrameworkService_DomainComm
 func (s *FrameworkServer) SendMessage(ctx context.Context, req *DomainMessage) (*RuntimeMessage, error) {
 	log.Printf("Received HTTP request: %s for domain: %s", req.Type, req.Domain)
 
-	// Map HTTP route to correct domain message type
-	var targetDomain string
-	var messageType string
-
-	switch req.Type {
-	case "create_user_request":
-		targetDomain = "users"              // Your JS domain name
-		messageType = "user_create_request" // What your JS domain listens for
-	default:
-		targetDomain = req.Domain
-		messageType = req.Type
-	}
-
-	// Check if we have a connected domain for this request
-	stream := s.getDomainStream(targetDomain)
-	if stream == nil {
-		log.Printf("No domain stream found for: %s", targetDomain)
-		return &RuntimeMessage{
-			Type:      "error",
-			RequestId: req.RequestId,
-			Success:   false,
-			Error:     fmt.Sprintf("Domain %s not connected", targetDomain),
-		}, nil
-	}
+	// Map the message type to the domain that handles it
+	targetDomain, messageType := s.Router.Resolve(req.Type, req.Domain)
 
 	// Create a pending request to wait for the response
 	pendingReq := &PendingRequest{
 		RequestID: req.RequestId,
 		Response:  make(chan *RuntimeMessage, 1),
-		Timeout:   time.Now().Add(30 * time.Second),
+		Timeout:   time.Now().Add(requestTimeout),
 	}
 
 	s.addPendingRequest(req.RequestId, pendingReq)
 	defer s.removePendingRequest(req.RequestId)
+	defer s.untrackInFlight(targetDomain, req.RequestId)
 
-	if err := stream.Send(&RuntimeMessage{
+	outbound := &RuntimeMessage{
 		Type:      messageType,
 		Payload:   req.Payload,
 		RequestId: req.RequestId,
 		Success:   true,
-	}); err != nil {
-		log.Printf("Error sending to domain: %v", err)
+	}
+
+	// Queue the message if the domain is reconnecting; it is delivered on re-registration
+	if err := s.deliver(ctx, targetDomain, outbound); err != nil {
+		log.Printf("Could not deliver %s to domain %s: %v", messageType, targetDomain, err)
 		return &RuntimeMessage{
 			Type:      "error",
 			RequestId: req.RequestId,
 			Success:   false,
-			Error:     "Failed to send to domain",
+			Error:     err.Error(),
 		}, nil
 	}
 
@@ -148,10 +183,20 @@ func (s *FrameworkServer) SendMessage(ctx context.Context, req *DomainMessage) (
 
 	// Wait for response with timeout
 	select {
-	case response := <-pendingReq.Response:
+	case response, ok := <-pendingReq.Response:
+		if !ok {
+			// The cleanup routine expired the request
+			log.Printf("Timeout waiting for response to request %s", req.RequestId)
+			return &RuntimeMessage{
+				Type:      "error",
+				RequestId: req.RequestId,
+				Success:   false,
+				Error:     "Request timeout",
+			}, nil
+		}
 		log.Printf("Received response for request %s: success=%t", req.RequestId, response.Success)
 		return response, nil
-	case <-time.After(30 * time.Second):
+	case <-time.After(requestTimeout):
 		log.Printf("Timeout waiting for response to request %s", req.RequestId)
 		return &RuntimeMessage{
 			Type:      "error",
@@ -170,20 +215,295 @@ func (s *FrameworkServer) SendMessage(ctx context.Context, req *DomainMessage) (
 	}
 }
 
+// deliver sends a message to the domain's stream, or queues it until the domain
+// reconnects. It fails when the queue is full or nobody reconnects before ctx ends.
+func (s *FrameworkServer) deliver(ctx context.Context, domain string, msg *RuntimeMessage) error {
+	for {
+		stream, queued, err := s.streamOrEnqueue(domain, msg)
+		if err != nil {
+			return err
+		}
+
+		if stream != nil {
+			s.trackInFlight(domain, msg)
+			if err := s.sendToStream(stream, msg); err != nil {
+				// The stream is gone; drop it and fall back to queueing
+				log.Printf("Error sending to domain %s: %v", domain, err)
+				s.untrackInFlight(domain, msg.RequestId)
+				s.removeDomainStream(domain, stream)
+				continue
+			}
+			return nil
+		}
+
+		if s.registrationAttempts(domain) == 0 {
+			log.Printf("Domain %s has not registered yet, queued %s until it connects", domain, msg.Type)
+		} else {
+			log.Printf("Domain %s not connected, queued %s until it reconnects", domain, msg.Type)
+		}
+
+		select {
+		case <-queued.settled:
+		case <-time.After(time.Until(queued.expires)):
+		case <-ctx.Done():
+		}
+
+		if !s.dropQueuedMessage(domain, queued) {
+			// A flush claimed the message first; its send decides the outcome
+			<-queued.settled
+		}
+		if queued.state != queuedSent {
+			return s.notConnectedError(domain)
+		}
+		return nil
+	}
+}
+
+// notConnectedError explains a domain without a stream: one that never registered is
+// most likely not running, while one that did has dropped and not come back
+func (s *FrameworkServer) notConnectedError(domain string) error {
+	if s.registrationAttempts(domain) == 0 {
+		return fmt.Errorf("Domain %s not connected: it has never registered; check that the Node.js handler process is running and has called domain_register", domain)
+	}
+	return fmt.Errorf("Domain %s not connected: it disconnected and has not reconnected", domain)
+}
+
+// registrationAttempts returns how many times the domain has connected
+func (s *FrameworkServer) registrationAttempts(domain string) int {
+	s.StreamMutex.RLock()
+	defer s.StreamMutex.RUnlock()
+	return s.domainRegistrationAttempts[domain]
+}
+
+// sendToStream serializes writes to each stream, since gRPC streams are not safe for
+// concurrent Send
+func (s *FrameworkServer) sendToStream(stream FrameworkService_DomainCommunicationServer, msg *RuntimeMessage) error {
+	mutex, _ := s.sendMutexes.LoadOrStore(stream, &sync.Mutex{})
+	mutex.(*sync.Mutex).Lock()
+	defer mutex.(*sync.Mutex).Unlock()
+	return stream.Send(msg)
+}
+
+// streamOrEnqueue returns the domain's stream, or queues the message if the domain is disconnected
+func (s *FrameworkServer) streamOrEnqueue(domain string, msg *RuntimeMessage) (FrameworkService_DomainCommunicationServer, *queuedMessage, error) {
+	s.StreamMutex.Lock()
+	defer s.StreamMutex.Unlock()
+
+	if stream := s.DomainStreams[domain]; stream != nil {
+		return stream, nil, nil
+	}
+
+	queued, err := s.enqueueLocked(domain, msg)
+	return nil, queued, err
+}
+
+// enqueueLocked holds a message for a disconnected domain; StreamMutex must be held
+func (s *FrameworkServer) enqueueLocked(domain string, msg *RuntimeMessage) (*queuedMessage, error) {
+	limit := s.OutboundQueueSize
+	if limit <= 0 {
+		limit = DefaultOutboundQueueSize
+	}
+	ttl := s.OutboundQueueTTL
+	if ttl <= 0 {
+		ttl = DefaultOutboundQueueTTL
+	}
+
+	if s.outboundQueues == nil {
+		s.outboundQueues = make(map[string][]*queuedMessage)
+	}
+	if len(s.outboundQueues[domain]) >= limit {
+		return nil, fmt.Errorf("Domain %s not connected and outbound queue is full", domain)
+	}
+
+	queued := &queuedMessage{
+		message: msg,
+		expires: time.Now().Add(ttl),
+		settled: make(chan struct{}),
+	}
+	s.outboundQueues[domain] = append(s.outboundQueues[domain], queued)
+	return queued, nil
+}
+
+// dropQueuedMessage removes a message whose caller gave up waiting for its domain. It
+// reports false when a flush claimed the message first, leaving that flush to settle it.
+func (s *FrameworkServer) dropQueuedMessage(domain string, queued *queuedMessage) bool {
+	s.StreamMutex.Lock()
+	defer s.StreamMutex.Unlock()
+
+	switch queued.state {
+	case queuedWaiting:
+		queue := s.outboundQueues[domain]
+		for i, candidate := range queue {
+			if candidate == queued {
+				s.outboundQueues[domain] = append(queue[:i], queue[i+1:]...)
+				break
+			}
+		}
+		queued.state = queuedDropped
+		close(queued.settled)
+		return true
+	case queuedSending:
+		queued.gaveUp = true
+	}
+	return false
+}
+
+// claimQueuedMessage marks a waiting, unexpired message as being sent by a flush
+func (s *FrameworkServer) claimQueuedMessage(queued *queuedMessage, now time.Time) bool {
+	s.StreamMutex.Lock()
+	defer s.StreamMutex.Unlock()
+
+	if queued.state != queuedWaiting || now.After(queued.expires) {
+		return false
+	}
+	queued.state = queuedSending
+	return true
+}
+
+// flushOutbound delivers messages queued while the domain was disconnected
+func (s *FrameworkServer) flushOutbound(domain string, stream FrameworkService_DomainCommunicationServer) {
+	s.StreamMutex.Lock()
+	queue := s.outboundQueues[domain]
+	delete(s.outboundQueues, domain)
+	s.StreamMutex.Unlock()
+
+	delivered := 0
+	now := time.Now()
+	for i, queued := range queue {
+		if !s.claimQueuedMessage(queued, now) {
+			continue
+		}
+
+		s.trackInFlight(domain, queued.message)
+		if err := s.sendToStream(stream, queued.message); err != nil {
+			log.Printf("Error flushing queued messages to domain %s: %v", domain, err)
+			s.untrackInFlight(domain, queued.message.RequestId)
+			s.requeueUnsent(domain, queued, queue[i+1:])
+			return
+		}
+
+		s.StreamMutex.Lock()
+		queued.state = queuedSent
+		close(queued.settled)
+		s.StreamMutex.Unlock()
+		delivered++
+	}
+
+	if delivered > 0 {
+		log.Printf("Flushed %d queued message(s) to domain %s", delivered, domain)
+	}
+}
+
+// requeueUnsent puts back the message a flush failed to send, with the rest of its
+// queue, for the next connection. Messages whose callers gave up are dropped instead.
+func (s *FrameworkServer) requeueUnsent(domain string, failed *queuedMessage, rest []*queuedMessage) {
+	s.StreamMutex.Lock()
+	defer s.StreamMutex.Unlock()
+
+	var requeued []*queuedMessage
+	if failed.gaveUp {
+		failed.state = queuedDropped
+		close(failed.settled)
+	} else {
+		failed.state = queuedWaiting
+		requeued = append(requeued, failed)
+	}
+	for _, queued := range rest {
+		if queued.state == queuedWaiting {
+			requeued = append(requeued, queued)
+		}
+	}
+
+	if s.outboundQueues == nil {
+		s.outboundQueues = make(map[string][]*queuedMessage)
+	}
+	s.outboundQueues[domain] = append(requeued, s.outboundQueues[domain]...)
+}
+
+// trackInFlight remembers a message sent to a domain until its response arrives
+func (s *FrameworkServer) trackInFlight(domain string, msg *RuntimeMessage) {
+	s.StreamMutex.Lock()
+	defer s.StreamMutex.Unlock()
+
+	if s.inFlight == nil {
+		s.inFlight = make(map[string]map[string]*RuntimeMessage)
+	}
+	if s.inFlight[domain] == nil {
+		s.inFlight[domain] = make(map[string]*RuntimeMessage)
+	}
+	s.inFlight[domain][msg.RequestId] = msg
+}
+
+func (s *FrameworkServer) untrackInFlight(domain, requestID string) {
+	s.StreamMutex.Lock()
+	defer s.StreamMutex.Unlock()
+	delete(s.inFlight[domain], requestID)
+}
+
 // Helper methods for managing domain streams
 func (s *FrameworkServer) addDomainStream(domain string, stream FrameworkService_DomainCommunicationServer) {
+	seq := s.connectionSeq.Add(1)
+
 	s.StreamMutex.Lock()
-	defer s.StreamMutex.Unlock()
 	if s.DomainStreams == nil {
 		s.DomainStreams = make(map[string]FrameworkService_DomainCommunicationServer)
 	}
+	if s.streamSeqs == nil {
+		s.streamSeqs = make(map[string]uint64)
+	}
+	if s.domainRegistrationAttempts == nil {
+		s.domainRegistrationAttempts = make(map[string]int)
+	}
+	previous, replaced := s.streamSeqs[domain]
 	s.DomainStreams[domain] = stream
+	s.streamSeqs[domain] = seq
+	s.domainRegistrationAttempts[domain]++
+	attempts := s.domainRegistrationAttempts[domain]
+	s.StreamMutex.Unlock()
+
+	switch {
+	case attempts == 1:
+		log.Printf("🔌 Domain %s connected (seq %d)", domain, seq)
+	case replaced:
+		log.Printf("🔌 Domain %s reconnected (seq %d, replaces seq %d, connection %d)", domain, seq, previous, attempts)
+	default:
+		log.Printf("🔌 Domain %s reconnected (seq %d, connection %d)", domain, seq, attempts)
+	}
+
+	s.flushOutbound(domain, stream)
 }
 
-func (s *FrameworkServer) removeDomainStream(domain string) {
+// removeDomainStream forgets a stream, unless the domain has already reconnected on a newer one.
+// Requests still waiting on the dropped stream are queued again for the next connection.
+func (s *FrameworkServer) removeDomainStream(domain string, stream FrameworkService_DomainCommunicationServer) {
+	if domain == "" {
+		return
+	}
+
 	s.StreamMutex.Lock()
 	defer s.StreamMutex.Unlock()
+
+	if s.DomainStreams[domain] != stream {
+		return
+	}
+
+	log.Printf("🔌 Domain %s disconnected (seq %d)", domain, s.streamSeqs[domain])
 	delete(s.DomainStreams, domain)
+	delete(s.streamSeqs, domain)
+
+	requeued := 0
+	for requestID, msg := range s.inFlight[domain] {
+		if _, err := s.enqueueLocked(domain, msg); err != nil {
+			log.Printf("Dropping in-flight request %s for domain %s: %v", requestID, domain, err)
+			continue
+		}
+		requeued++
+	}
+	delete(s.inFlight, domain)
+
+	if requeued > 0 {
+		log.Printf("Requeued %d in-flight message(s) for domain %s", requeued, domain)
+	}
 }
 
 func (s *FrameworkServer) getDomainStream(domain string) FrameworkService_DomainCommunicationServer {
@@ -199,21 +519,59 @@ func (s *FrameworkServer) addPendingRequest(requestID string, req *PendingReques
 	if s.PendingRequests == nil {
 		s.PendingRequests = make(map[string]*PendingRequest)
 	}
+	s.removePendingRequestLocked(requestID)
 	s.PendingRequests[requestID] = req
+	heap.Push(&s.expiries, req)
+
+	// A new earliest expiry means the cleanup routine is sleeping too long
+	if req.index == 0 {
+		select {
+		case s.cleanupSignal() <- struct{}{}:
+		default:
+		}
+	}
 }
 
 func (s *FrameworkServer) removePendingRequest(requestID string) {
 	s.RequestMutex.Lock()
 	defer s.RequestMutex.Unlock()
+	s.removePendingRequestLocked(requestID)
+}
+
+// removePendingRequestLocked removes a request from the map and the expiry queue;
+// the caller holds RequestMutex
+func (s *FrameworkServer) removePendingRequestLocked(requestID string) {
+	req, ok := s.PendingRequests[requestID]
+	if !ok {
+		return
+	}
+	if req.index >= 0 && req.index < len(s.expiries) && s.expiries[req.index] == req {
+		heap.Remove(&s.expiries, req.index)
+	}
 	delete(s.PendingRequests, requestID)
 }
 
+// PendingCount returns the number of requests waiting for a domain to respond
+func (s *FrameworkServer) PendingCount() int {
+	s.RequestMutex.RLock()
+	defer s.RequestMutex.RUnlock()
+	return len(s.PendingRequests)
+}
+
 func (s *FrameworkServer) getPendingRequest(requestID string) *PendingRequest {
 	s.RequestMutex.RLock()
 	defer s.RequestMutex.RUnlock()
 	return s.PendingRequests[requestID]
 }
 
+// cleanupSignal returns the channel that wakes the cleanup routine; the caller holds RequestMutex
+func (s *FrameworkServer) cleanupSignal() chan struct{} {
+	if s.cleanupWake == nil {
+		s.cleanupWake = make(chan struct{}, 1)
+	}
+	return s.cleanupWake
+}
+
 // Check if a message type is a response (ends with "_response")
 func (s *FrameworkServer) isResponseMessage(msgType string) bool {
 	return len(msgType) > 9 && msgType[len(msgType)-9:] == "_response"
@@ -221,9 +579,7 @@ func (s *FrameworkServer) isResponseMessage(msgType string) bool {
 
 // Handle responses from domains
 func (s *FrameworkServer) handleDomainResponse(msg *DomainMessage) {
-	// Find the pending request
-	pendingReq := s.getPendingRequest(msg.RequestId)
-	if pendingReq == nil {
+	if s.getPendingRequest(msg.RequestId) == nil {
 		log.Printf("No pending request found for response: %s", msg.RequestId)
 		return
 	}
@@ -251,7 +607,15 @@ func (s *FrameworkServer) handleDomainResponse(msg *DomainMessage) {
 		Error:     errorMsg,
 	}
 
-	// Send response to waiting goroutine
+	// Send response to waiting goroutine, holding the lock so the request cannot
+	// expire and have its channel closed mid-send
+	s.RequestMutex.RLock()
+	defer s.RequestMutex.RUnlock()
+	pendingReq := s.PendingRequests[msg.RequestId]
+	if pendingReq == nil {
+		log.Printf("Request %s expired before its response arrived", msg.RequestId)
+		return
+	}
 	select {
 	case pendingReq.Response <- response:
 		log.Printf("Response sent for request %s", msg.RequestId)
@@ -292,15 +656,16 @@ func (s *FrameworkServer) processMessage(msg *DomainMessage) *RuntimeMessage {
 		}
 	case "db_update":
 		var reqData struct {
-			Table string         `json:"table"`
-			ID    any            `json:"id"`
-			Data  map[string]any `json:"data"`
+			Table           string         `json:"table"`
+			ID              any            `json:"id"`
+			Data            map[string]any `json:"data"`
+			ExpectedVersion any            `json:"expected_version"` // Optional; see UpdateRecordAtVersion
 		}
 		if err := json.Unmarshal([]byte(msg.Payload), &reqData); err != nil {
 			success = false
 			errMsg = fmt.Sprintf("Invalid db_update payload: %v", err)
 		} else {
-			resp, err := s.DbExecutor.UpdateRecord(ctx, reqData.Table, reqData.ID, reqData.Data, &msg.RequestId)
+			resp, err := s.DbExecutor.UpdateRecordAtVersion(ctx, reqData.Table, reqData.ID, reqData.Data, reqData.ExpectedVersion, &msg.RequestId)
 			if err != nil {
 				success = false
 				errMsg = fmt.Sprintf("db_update failed: %v", err)
@@ -327,6 +692,88 @@ func (s *FrameworkServer) processMessage(msg *DomainMessage) *RuntimeMessage {
 				responsePayload = resp
 			}
 		}
+	case "db_delete":
+		// Tables with a deleted_at column are soft deleted unless _hard is set
+		var reqData struct {
+			Table string `json:"table"`
+			ID    any    `json:"id"`
+			Hard  bool   `json:"_hard"`
+		}
+		if err := json.Unmarshal([]byte(msg.Payload), &reqData); err != nil {
+			success = false
+			errMsg = fmt.Sprintf("Invalid db_delete payload: %v", err)
+		} else {
+			deleteRecord := s.DbExecutor.DeleteRecord
+			if reqData.Hard {
+				deleteRecord = s.DbExecutor.HardDeleteRecord
+			}
+			resp, err := deleteRecord(ctx, reqData.Table, reqData.ID, &msg.RequestId)
+			if err != nil {
+				success = false
+				errMsg = fmt.Sprintf("db_delete failed: %v", err)
+			} else {
+				responsePayload = resp
+			}
+		}
+	case "db_restore":
+		var reqData struct {
+			Table string `json:"table"`
+			ID    any    `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(msg.Payload), &reqData); err != nil {
+			success = false
+			errMsg = fmt.Sprintf("Invalid db_restore payload: %v", err)
+		} else {
+			resp, err := s.DbExecutor.RestoreRecord(ctx, reqData.Table, reqData.ID, &msg.RequestId)
+			if err != nil {
+				success = false
+				errMsg = fmt.Sprintf("db_restore failed: %v", err)
+			} else {
+				responsePayload = resp
+			}
+		}
+	case "db_delete_where":
+		var reqData struct {
+			Table string         `json:"table"`
+			Query map[string]any `json:"query"`
+		}
+		if err := json.Unmarshal([]byte(msg.Payload), &reqData); err != nil {
+			success = false
+			errMsg = fmt.Sprintf("Invalid db_delete_where payload: %v", err)
+		} else {
+			resp, err := s.DbExecutor.DeleteWhere(ctx, reqData.Table, reqData.Query, &msg.RequestId)
+			if err != nil {
+				success = false
+				errMsg = fmt.Sprintf("db_delete_where failed: %v", err)
+			} else {
+				responsePayload = resp
+			}
+		}
+	case "db_execute":
+		// Raw SQL for statements that don't fit the record helpers; params bind to
+		// :name or {{name}} placeholders
+		var reqData struct {
+			SQL    string         `json:"sql"`
+			Params map[string]any `json:"params"`
+		}
+		if err := json.Unmarshal([]byte(msg.Payload), &reqData); err != nil {
+			success = false
+			errMsg = fmt.Sprintf("Invalid db_execute payload: %v", err)
+		} else if strings.TrimSpace(reqData.SQL) == "" {
+			success = false
+			errMsg = "Invalid db_execute payload: sql is required"
+		} else if s.DbExecutor == nil {
+			success = false
+			errMsg = "db_execute failed: no database connection"
+		} else {
+			resp, err := s.DbExecutor.ExecuteSQL(ctx, reqData.SQL, reqData.Params, &msg.RequestId)
+			if err != nil {
+				success = false
+				errMsg = fmt.Sprintf("db_execute failed: %v", err)
+			} else {
+				responsePayload = resp
+			}
+		}
 	case "email_send":
 		log.Printf("Sending email for domain %s", msg.Domain)
 		responsePayload = []byte(`{"status": "sent"}`)
@@ -348,27 +795,52 @@ func (s *FrameworkServer) processMessage(msg *DomainMessage) *RuntimeMessage {
 	}
 }
 
-// Cleanup routine to remove expired pending requests
+// StartCleanupRoutine expires pending requests as their timeouts pass, sleeping until
+// the next expiry rather than polling
 func (s *FrameworkServer) StartCleanupRoutine() {
+	s.RequestMutex.Lock()
+	wake := s.cleanupSignal()
+	s.RequestMutex.Unlock()
+
 	go func() {
-		ticker := time.NewTicker(30 * time.Second)
-		defer ticker.Stop()
-
-		for range ticker.C {
-			s.RequestMutex.Lock()
-			now := time.Now()
-			for requestID, req := range s.PendingRequests {
-				if now.After(req.Timeout) {
-					log.Printf("Cleaning up expired request: %s", requestID)
-					close(req.Response)
-					delete(s.PendingRequests, requestID)
-				}
+		timer := time.NewTimer(0)
+		timer.Stop()
+
+		for {
+			if next, ok := s.expirePendingRequests(time.Now()); ok {
+				timer.Reset(time.Until(next))
+			}
+
+			select {
+			case <-timer.C:
+			case <-wake:
 			}
-			s.RequestMutex.Unlock()
+			timer.Stop()
 		}
 	}()
 }
 
+// expirePendingRequests closes and removes requests whose timeout is not after now,
+// returning the next timeout if any requests remain
+func (s *FrameworkServer) expirePendingRequests(now time.Time) (time.Time, bool) {
+	s.RequestMutex.Lock()
+	defer s.RequestMutex.Unlock()
+
+	for len(s.expiries) > 0 && !s.expiries[0].Timeout.After(now) {
+		req := heap.Pop(&s.expiries).(*PendingRequest)
+		log.Printf("Cleaning up expired request: %s", req.RequestID)
+		close(req.Response)
+		if s.PendingRequests[req.RequestID] == req {
+			delete(s.PendingRequests, req.RequestID)
+		}
+	}
+
+	if len(s.expiries) == 0 {
+		return time.Time{}, false
+	}
+	return s.expiries[0].Timeout, true
+}
+
 func Listen(db interfaces.Database) *FrameworkServer {
 	// Create listener
 	listener, err := net.Listen("tcp", ":50051")
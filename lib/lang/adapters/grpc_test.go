@@ -0,0 +1,537 @@
+package lang_adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fulcrum/lib/database"
+	"fulcrum/lib/database/drivers"
+	interfaces "fulcrum/lib/database/interfaces"
+	"fulcrum/lib/metrics"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startTestFrameworkServer runs a FrameworkServer on an in-memory listener and returns a client for it
+func startTestFrameworkServer(t *testing.T, fs *FrameworkServer) FrameworkServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	RegisterFrameworkServiceServer(server, fs)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial test server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return NewFrameworkServiceClient(conn)
+}
+
+// connectDomain opens a domain stream and registers it under the given name
+func connectDomain(t *testing.T, client FrameworkServiceClient, domain string) (FrameworkService_DomainCommunicationClient, context.CancelFunc) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.DomainCommunication(ctx)
+	if err != nil {
+		cancel()
+		t.Fatalf("Failed to open domain stream: %v", err)
+	}
+	if err := stream.Send(&DomainMessage{Domain: domain, Type: "domain_register"}); err != nil {
+		cancel()
+		t.Fatalf("Failed to register domain: %v", err)
+	}
+	return stream, cancel
+}
+
+// recvRequest skips registration acks and returns the next request sent to the domain
+func recvRequest(t *testing.T, stream FrameworkService_DomainCommunicationClient) *RuntimeMessage {
+	t.Helper()
+
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Domain stream closed before a request arrived: %v", err)
+		}
+		if msg.Type != "domain_register" {
+			return msg
+		}
+	}
+}
+
+// waitForStream polls until the domain's registration matches the wanted state
+func waitForStream(t *testing.T, fs *FrameworkServer, domain string, connected bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if (fs.getDomainStream(domain) != nil) == connected {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Domain %s never reached connected=%t", domain, connected)
+}
+
+// sendAsync calls SendMessage in the background and returns a channel with the result
+func sendAsync(ctx context.Context, client FrameworkServiceClient, req *DomainMessage) <-chan *RuntimeMessage {
+	result := make(chan *RuntimeMessage, 1)
+	go func() {
+		resp, err := client.SendMessage(ctx, req)
+		if err != nil {
+			resp = &RuntimeMessage{Type: "error", Error: err.Error()}
+		}
+		result <- resp
+	}()
+	return result
+}
+
+func TestSendMessageDeliversAfterDomainReconnects(t *testing.T) {
+	fs := &FrameworkServer{}
+	client := startTestFrameworkServer(t, fs)
+
+	_, disconnect := connectDomain(t, client, "users")
+	waitForStream(t, fs, "users", true)
+	disconnect()
+	waitForStream(t, fs, "users", false)
+
+	result := sendAsync(context.Background(), client, &DomainMessage{
+		Domain: "users", Type: "user_lookup", Payload: `{"id":1}`, RequestId: "req-1",
+	})
+
+	// Give the request time to be queued before the domain comes back
+	time.Sleep(50 * time.Millisecond)
+
+	stream, disconnect := connectDomain(t, client, "users")
+	defer disconnect()
+
+	msg := recvRequest(t, stream)
+	if msg.Type != "user_lookup" || msg.RequestId != "req-1" {
+		t.Fatalf("Expected queued user_lookup req-1, got %s %s", msg.Type, msg.RequestId)
+	}
+	if err := stream.Send(&DomainMessage{Domain: "users", Type: "user_lookup_response", RequestId: "req-1", Payload: `{"success":true}`}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case resp := <-result:
+		if !resp.Success {
+			t.Errorf("Expected success after reconnect, got error %q", resp.Error)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("SendMessage never returned")
+	}
+}
+
+func TestSendMessageRedeliversWhenDomainDropsMidRequest(t *testing.T) {
+	fs := &FrameworkServer{}
+	client := startTestFrameworkServer(t, fs)
+
+	first, disconnect := connectDomain(t, client, "users")
+	waitForStream(t, fs, "users", true)
+
+	result := sendAsync(context.Background(), client, &DomainMessage{
+		Domain: "users", Type: "user_lookup", RequestId: "req-2",
+	})
+
+	// The domain receives the request, then drops before answering
+	if msg := recvRequest(t, first); msg.RequestId != "req-2" {
+		t.Fatalf("Expected req-2, got %s", msg.RequestId)
+	}
+	disconnect()
+	waitForStream(t, fs, "users", false)
+
+	second, disconnect := connectDomain(t, client, "users")
+	defer disconnect()
+
+	if msg := recvRequest(t, second); msg.RequestId != "req-2" {
+		t.Fatalf("Expected req-2 to be redelivered, got %s", msg.RequestId)
+	}
+	if err := second.Send(&DomainMessage{Domain: "users", Type: "user_lookup_response", RequestId: "req-2", Payload: `{"success":true}`}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case resp := <-result:
+		if !resp.Success {
+			t.Errorf("Expected success after redelivery, got error %q", resp.Error)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("SendMessage never returned")
+	}
+}
+
+func TestSendMessageFailsWhenDomainMissesDeadline(t *testing.T) {
+	fs := &FrameworkServer{}
+	client := startTestFrameworkServer(t, fs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := client.SendMessage(ctx, &DomainMessage{Domain: "users", Type: "user_lookup", RequestId: "req-3"})
+	if err == nil && resp.Success {
+		t.Fatal("Expected failure when the domain never connects")
+	}
+	if err == nil && !strings.Contains(resp.Error, "not connected") {
+		t.Errorf("Expected not connected error, got %q", resp.Error)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected failure at the request deadline, took %s", elapsed)
+	}
+
+	// The server notices the cancelled call shortly after the client gives up
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		fs.StreamMutex.RLock()
+		queued := len(fs.outboundQueues["users"])
+		fs.StreamMutex.RUnlock()
+		if queued == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected abandoned message to leave the queue, %d remain", queued)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// blockingStream holds each Send until the test releases it with the send's result
+type blockingStream struct {
+	grpc.GenericServerStream[DomainMessage, RuntimeMessage]
+	sending chan struct{}
+	release chan error
+}
+
+func (b *blockingStream) Send(*RuntimeMessage) error {
+	b.sending <- struct{}{}
+	return <-b.release
+}
+
+func TestDeliverSettlesMessageClaimedByFlush(t *testing.T) {
+	tests := []struct {
+		name      string
+		sendErr   error
+		delivered bool
+	}{
+		{name: "flush sends it", delivered: true},
+		{name: "flush fails to send it", sendErr: errors.New("stream closed")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := &FrameworkServer{OutboundQueueTTL: time.Minute}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			result := make(chan error, 1)
+			go func() {
+				result <- fs.deliver(ctx, "users", &RuntimeMessage{Type: "user_lookup", RequestId: "req"})
+			}()
+
+			var queued *queuedMessage
+			for queued == nil {
+				fs.StreamMutex.RLock()
+				if queue := fs.outboundQueues["users"]; len(queue) == 1 {
+					queued = queue[0]
+				}
+				fs.StreamMutex.RUnlock()
+				time.Sleep(time.Millisecond)
+			}
+
+			// The caller gives up while the reconnecting domain's flush is sending
+			stream := &blockingStream{sending: make(chan struct{}), release: make(chan error)}
+			go fs.addDomainStream("users", stream)
+			<-stream.sending
+			cancel()
+			for gaveUp := false; !gaveUp; time.Sleep(time.Millisecond) {
+				fs.StreamMutex.RLock()
+				gaveUp = queued.gaveUp
+				fs.StreamMutex.RUnlock()
+			}
+			stream.release <- tt.sendErr
+
+			if err := <-result; (err == nil) != tt.delivered {
+				t.Errorf("Expected delivered=%t, got error %v", tt.delivered, err)
+			}
+			fs.StreamMutex.RLock()
+			defer fs.StreamMutex.RUnlock()
+			if queue := fs.outboundQueues["users"]; len(queue) != 0 {
+				t.Errorf("Expected a message whose caller gave up not to be queued again, %d queued", len(queue))
+			}
+		})
+	}
+}
+
+func TestSendMessageExplainsMissingDomain(t *testing.T) {
+	tests := []struct {
+		name      string
+		connected bool
+		expected  string
+	}{
+		{name: "never registered", expected: "check that the Node.js handler process is running and has called domain_register"},
+		{name: "dropped", connected: true, expected: "disconnected and has not reconnected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := &FrameworkServer{OutboundQueueTTL: 20 * time.Millisecond}
+			if tt.connected {
+				stream := &grpc.GenericServerStream[DomainMessage, RuntimeMessage]{}
+				fs.addDomainStream("users", stream)
+				fs.removeDomainStream("users", stream)
+			}
+
+			resp, err := fs.SendMessage(context.Background(), &DomainMessage{Domain: "users", Type: "user_lookup", RequestId: "req"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Success || !strings.Contains(resp.Error, "Domain users not connected") || !strings.Contains(resp.Error, tt.expected) {
+				t.Errorf("Expected an error explaining %q, got %q", tt.expected, resp.Error)
+			}
+		})
+	}
+}
+
+func TestSendMessageFailsWhenQueueIsFull(t *testing.T) {
+	fs := &FrameworkServer{OutboundQueueSize: 1, OutboundQueueTTL: time.Second}
+
+	waiting := sendAsync(context.Background(), startTestFrameworkServer(t, fs), &DomainMessage{Domain: "users", Type: "user_lookup", RequestId: "a"})
+	time.Sleep(50 * time.Millisecond)
+
+	resp, err := fs.SendMessage(context.Background(), &DomainMessage{Domain: "users", Type: "user_lookup", RequestId: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Success || !strings.Contains(resp.Error, "queue is full") {
+		t.Errorf("Expected queue full error, got success=%t error=%q", resp.Success, resp.Error)
+	}
+
+	<-waiting
+}
+
+func TestRemoveDomainStreamIgnoresReplacedStream(t *testing.T) {
+	fs := &FrameworkServer{}
+	oldStream := &grpc.GenericServerStream[DomainMessage, RuntimeMessage]{}
+	newStream := &grpc.GenericServerStream[DomainMessage, RuntimeMessage]{}
+
+	fs.addDomainStream("users", oldStream)
+	fs.addDomainStream("users", newStream)
+
+	// The old stream's receive loop exits after the domain has already re-registered
+	fs.removeDomainStream("users", oldStream)
+
+	if got := fs.getDomainStream("users"); got != newStream {
+		t.Fatal("Expected the reconnected stream to remain registered")
+	}
+
+	fs.removeDomainStream("users", newStream)
+	if fs.getDomainStream("users") != nil {
+		t.Error("Expected the domain to be disconnected")
+	}
+}
+
+func TestExpirePendingRequests(t *testing.T) {
+	fs := &FrameworkServer{}
+	now := time.Now()
+
+	requests := map[string]*PendingRequest{}
+	for _, tc := range []struct {
+		id    string
+		after time.Duration
+	}{
+		{"late", 3 * time.Second},
+		{"first", 1 * time.Second},
+		{"removed", 500 * time.Millisecond},
+		{"second", 2 * time.Second},
+	} {
+		req := &PendingRequest{RequestID: tc.id, Response: make(chan *RuntimeMessage, 1), Timeout: now.Add(tc.after)}
+		requests[tc.id] = req
+		fs.addPendingRequest(tc.id, req)
+	}
+	fs.removePendingRequest("removed")
+
+	next, ok := fs.expirePendingRequests(now.Add(2 * time.Second))
+	if !ok || !next.Equal(requests["late"].Timeout) {
+		t.Errorf("Expected the next expiry to be the late request's timeout, got %v (%v)", next, ok)
+	}
+
+	for _, tc := range []struct {
+		id          string
+		wantClosed  bool
+		wantPending bool
+	}{
+		{id: "first", wantClosed: true},
+		{id: "second", wantClosed: true},
+		{id: "late", wantPending: true},
+		{id: "removed"},
+	} {
+		closed := false
+		select {
+		case _, open := <-requests[tc.id].Response:
+			closed = !open
+		default:
+		}
+		if closed != tc.wantClosed {
+			t.Errorf("Expected %s closed: %v, got %v", tc.id, tc.wantClosed, closed)
+		}
+		if _, pending := fs.PendingRequests[tc.id]; pending != tc.wantPending {
+			t.Errorf("Expected %s pending: %v, got %v", tc.id, tc.wantPending, pending)
+		}
+	}
+
+	if _, ok := fs.expirePendingRequests(now.Add(time.Hour)); ok {
+		t.Error("Expected no pending requests once all have expired")
+	}
+}
+
+func TestCleanupRoutineExpiresRequestsOnTime(t *testing.T) {
+	fs := &FrameworkServer{}
+	fs.StartCleanupRoutine()
+
+	req := &PendingRequest{RequestID: "req-1", Response: make(chan *RuntimeMessage, 1), Timeout: time.Now().Add(50 * time.Millisecond)}
+	fs.addPendingRequest(req.RequestID, req)
+
+	select {
+	case _, open := <-req.Response:
+		if open {
+			t.Fatal("Expected the response channel to be closed, got a message")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the request to expire shortly after its timeout")
+	}
+	if fs.getPendingRequest(req.RequestID) != nil {
+		t.Error("Expected the expired request to be removed")
+	}
+}
+
+func TestPendingRequestsGauge(t *testing.T) {
+	fs := &FrameworkServer{}
+	metrics.RegisterPendingRequests(fs.PendingCount)
+
+	for _, id := range []string{"req-1", "req-2"} {
+		fs.addPendingRequest(id, &PendingRequest{RequestID: id, Response: make(chan *RuntimeMessage, 1), Timeout: time.Now().Add(time.Minute)})
+	}
+	fs.removePendingRequest("req-1")
+
+	var out strings.Builder
+	metrics.Default.WriteText(&out)
+	if expected := "fulcrum_grpc_pending_requests 1\n"; !strings.Contains(out.String(), expected) {
+		t.Errorf("Expected %q in scrape:\n%s", expected, out.String())
+	}
+}
+
+func TestProcessMessageDbExecute(t *testing.T) {
+	fs := &FrameworkServer{DbExecutor: newTestExecutor(t)}
+
+	tests := []struct {
+		name      string
+		payload   string
+		success   bool
+		wantCount int
+		wantData  bool
+		wantError string
+	}{
+		{
+			name:      "update returns affected count",
+			payload:   `{"sql": "UPDATE users SET last_seen = :seen WHERE id = :id", "params": {"seen": "2024-01-01", "id": 1}}`,
+			success:   true,
+			wantCount: 1,
+		},
+		{
+			name:      "select returns rows",
+			payload:   `{"sql": "SELECT id, name FROM users WHERE name <> :name ORDER BY id", "params": {"name": "nobody"}}`,
+			success:   true,
+			wantCount: 2,
+			wantData:  true,
+		},
+		{
+			name:      "params are optional",
+			payload:   `{"sql": "SELECT id FROM users"}`,
+			success:   true,
+			wantCount: 2,
+			wantData:  true,
+		},
+		{
+			name:      "missing sql",
+			payload:   `{"params": {"id": 1}}`,
+			wantError: "sql is required",
+		},
+		{
+			name:      "malformed payload",
+			payload:   `{"sql": `,
+			wantError: "Invalid db_execute payload",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := fs.processMessage(&DomainMessage{Domain: "users", Type: "db_execute", Payload: tt.payload, RequestId: "req-1"})
+
+			if tt.wantError != "" {
+				if resp.Success || !strings.Contains(resp.Error, tt.wantError) {
+					t.Fatalf("Expected error containing %q, got success=%t error=%q", tt.wantError, resp.Success, resp.Error)
+				}
+				return
+			}
+
+			var result database.OperationResponse
+			if err := json.Unmarshal([]byte(resp.Payload), &result); err != nil {
+				t.Fatalf("Invalid response payload %q: %v", resp.Payload, err)
+			}
+			if result.Success != tt.success || result.Count != tt.wantCount {
+				t.Errorf("Expected success=%t count=%d, got %s", tt.success, tt.wantCount, resp.Payload)
+			}
+			if (len(result.Data) > 0) != tt.wantData {
+				t.Errorf("Expected data=%t, got %s", tt.wantData, resp.Payload)
+			}
+		})
+	}
+}
+
+// newSQLiteDB connects to a fresh SQLite database, closed when the test ends, and runs
+// statements on it
+func newSQLiteDB(t *testing.T, statements ...string) interfaces.Database {
+	t.Helper()
+	db, err := drivers.NewSQLiteDB(interfaces.Config{FilePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, statement := range statements {
+		if _, err := db.Exec(context.Background(), statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// newTestExecutor returns an executor over a SQLite database with two users
+func newTestExecutor(t *testing.T) *database.DatabaseExecutor {
+	t.Helper()
+	db := newSQLiteDB(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, last_seen TEXT)",
+		"INSERT INTO users (name) VALUES ('ada'), ('grace')",
+	)
+	return database.NewDatabaseExecutor(db)
+}
@@ -0,0 +1,146 @@
+package lang_adapters
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// handlerFileExtensions are the file types that trigger a handler reload
+var handlerFileExtensions = map[string]bool{
+	".js":  true,
+	".mjs": true,
+	".cjs": true,
+	".ts":  true,
+}
+
+// fileState captures what we compare between scans
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// HandlerWatcher polls the handlers directory and restarts the handler service
+// once changes settle, so a burst of writes results in a single restart
+type HandlerWatcher struct {
+	path     string
+	interval time.Duration
+	debounce time.Duration
+	restart  func() error
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewHandlerWatcher creates a watcher for the handlers path that calls restart on changes
+func NewHandlerWatcher(path string, restart func() error) *HandlerWatcher {
+	return &HandlerWatcher{
+		path:     path,
+		interval: 500 * time.Millisecond,
+		debounce: 300 * time.Millisecond,
+		restart:  restart,
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start begins watching in the background
+func (hw *HandlerWatcher) Start() {
+	log.Printf("👀 Watching handlers for changes: %s", hw.path)
+	go hw.run()
+}
+
+// Stop stops watching and waits for the watcher to exit
+func (hw *HandlerWatcher) Stop() {
+	hw.stopOnce.Do(func() {
+		close(hw.stopChan)
+	})
+	<-hw.done
+}
+
+func (hw *HandlerWatcher) run() {
+	defer close(hw.done)
+
+	ticker := time.NewTicker(hw.interval)
+	defer ticker.Stop()
+
+	snapshot := hw.scan()
+	pending := false
+	var lastChange time.Time
+
+	for {
+		select {
+		case <-hw.stopChan:
+			return
+		case now := <-ticker.C:
+			current := hw.scan()
+			if !sameSnapshot(snapshot, current) {
+				snapshot = current
+				pending = true
+				lastChange = now
+				continue
+			}
+
+			if pending && now.Sub(lastChange) >= hw.debounce {
+				pending = false
+				hw.reload()
+			}
+		}
+	}
+}
+
+// reload calls the restart function and logs how long it took
+func (hw *HandlerWatcher) reload() {
+	start := time.Now()
+	log.Printf("🔄 Handler change detected, reloading...")
+
+	if err := hw.restart(); err != nil {
+		log.Printf("❌ Handler reload failed: %v", err)
+		return
+	}
+
+	log.Printf("✅ Handlers reloaded in %s", time.Since(start).Round(time.Millisecond))
+}
+
+// scan records the state of every handler file under the watched path
+func (hw *HandlerWatcher) scan() map[string]fileState {
+	files := make(map[string]fileState)
+
+	filepath.Walk(hw.path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if info.IsDir() {
+			if info.Name() == "node_modules" || (strings.HasPrefix(info.Name(), ".") && path != hw.path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if handlerFileExtensions[filepath.Ext(path)] {
+			files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+		return nil
+	})
+
+	return files
+}
+
+// sameSnapshot reports whether two scans saw the same files in the same state
+func sameSnapshot(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		other, exists := b[path]
+		if !exists || !other.modTime.Equal(state.modTime) || other.size != state.size {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package lang_adapters
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestWatcher(path string, restarts *atomic.Int32) *HandlerWatcher {
+	hw := NewHandlerWatcher(path, func() error {
+		restarts.Add(1)
+		return nil
+	})
+	hw.interval = 10 * time.Millisecond
+	hw.debounce = 100 * time.Millisecond
+	return hw
+}
+
+func TestHandlerWatcherRestartsOnceForRapidWrites(t *testing.T) {
+	dir := t.TempDir()
+	handlerPath := filepath.Join(dir, "users", "handler.js")
+	if err := os.MkdirAll(filepath.Dir(handlerPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(handlerPath, []byte("export default {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var restarts atomic.Int32
+	hw := newTestWatcher(dir, &restarts)
+	hw.Start()
+	defer hw.Stop()
+
+	// Let the watcher take its initial snapshot
+	time.Sleep(30 * time.Millisecond)
+
+	for i := 1; i <= 5; i++ {
+		content := "export default {}" + strings.Repeat(" ", i)
+		if err := os.WriteFile(handlerPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(15 * time.Millisecond)
+	}
+
+	time.Sleep(400 * time.Millisecond)
+
+	if got := restarts.Load(); got != 1 {
+		t.Errorf("Expected handler service to be cycled once, got %d", got)
+	}
+}
+
+func TestHandlerWatcherIgnoresNonHandlerFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	var restarts atomic.Int32
+	hw := newTestWatcher(dir, &restarts)
+	hw.Start()
+	defer hw.Stop()
+
+	time.Sleep(30 * time.Millisecond)
+
+	if err := os.WriteFile(filepath.Join(dir, "get.html.hbs"), []byte("<p>hi</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+
+	if got := restarts.Load(); got != 0 {
+		t.Errorf("Expected no restart for template changes, got %d", got)
+	}
+}
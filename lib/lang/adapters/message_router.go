@@ -0,0 +1,68 @@
+package lang_adapters
+
+import (
+	"log"
+	"sync"
+
+	parser "fulcrum/lib/parser"
+)
+
+// MessageRouter resolves incoming message types to the domain and message type that handle them.
+// Types without a configured route pass through unchanged.
+type MessageRouter struct {
+	routes  map[string]parser.MessageRoute
+	domains map[string]bool
+	warned  sync.Map
+}
+
+// NewMessageRouter builds a router from the messages table and the discovered domain names
+func NewMessageRouter(routes []parser.MessageRoute, domains []string) *MessageRouter {
+	mr := &MessageRouter{
+		routes:  make(map[string]parser.MessageRoute, len(routes)),
+		domains: make(map[string]bool, len(domains)),
+	}
+	for _, route := range routes {
+		mr.routes[route.HTTPType] = route
+	}
+	for _, domain := range domains {
+		mr.domains[domain] = true
+	}
+	return mr
+}
+
+// NewMessageRouterFromConfig validates the messages table and builds a router for it
+func NewMessageRouterFromConfig(appConfig *parser.AppConfig) (*MessageRouter, error) {
+	if err := appConfig.ValidateMessageRoutes(); err != nil {
+		return nil, err
+	}
+	return NewMessageRouter(appConfig.Messages, appConfig.DomainNames()), nil
+}
+
+// Lookup returns the configured route for a message type, if any
+func (mr *MessageRouter) Lookup(msgType string) (parser.MessageRoute, bool) {
+	if mr == nil {
+		return parser.MessageRoute{}, false
+	}
+	route, exists := mr.routes[msgType]
+	return route, exists
+}
+
+// Resolve returns the target domain and message type for a message, falling back to passthrough
+func (mr *MessageRouter) Resolve(msgType, domain string) (string, string) {
+	if route, exists := mr.Lookup(msgType); exists {
+		domainType := route.DomainType
+		if domainType == "" {
+			domainType = msgType
+		}
+		return route.Domain, domainType
+	}
+
+	if mr != nil && len(mr.domains) > 0 && !mr.domains[domain] {
+		// Warn once per type so a chatty client doesn't flood the log
+		if _, seen := mr.warned.LoadOrStore(msgType+"@"+domain, true); !seen {
+			log.Printf("⚠️  No message route for %s; passing through to unknown domain %q", msgType, domain)
+		}
+	}
+
+	return domain, msgType
+}
@@ -0,0 +1,125 @@
+package lang_adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	parser "fulcrum/lib/parser"
+)
+
+func TestMessageRouterResolve(t *testing.T) {
+	router := NewMessageRouter([]parser.MessageRoute{
+		{HTTPType: "create_user_request", Domain: "users", DomainType: "user_create_request"},
+		{HTTPType: "list_posts", Domain: "posts"},
+	}, []string{"users", "posts"})
+
+	tests := []struct {
+		name           string
+		msgType        string
+		domain         string
+		expectedDomain string
+		expectedType   string
+	}{
+		{
+			name:           "configured mapping",
+			msgType:        "create_user_request",
+			domain:         "",
+			expectedDomain: "users",
+			expectedType:   "user_create_request",
+		},
+		{
+			name:           "mapping without domain_type keeps the type",
+			msgType:        "list_posts",
+			domain:         "",
+			expectedDomain: "posts",
+			expectedType:   "list_posts",
+		},
+		{
+			name:           "passthrough to known domain",
+			msgType:        "post_publish_request",
+			domain:         "posts",
+			expectedDomain: "posts",
+			expectedType:   "post_publish_request",
+		},
+		{
+			name:           "passthrough to unknown domain",
+			msgType:        "invoice_request",
+			domain:         "billing",
+			expectedDomain: "billing",
+			expectedType:   "invoice_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain, msgType := router.Resolve(tt.msgType, tt.domain)
+			if domain != tt.expectedDomain || msgType != tt.expectedType {
+				t.Errorf("Expected %s/%s, got %s/%s", tt.expectedDomain, tt.expectedType, domain, msgType)
+			}
+		})
+	}
+}
+
+func TestMessageRouterLookup(t *testing.T) {
+	router := NewMessageRouter([]parser.MessageRoute{
+		{HTTPType: "create_user_request", Domain: "users", DomainType: "user_create_request"},
+	}, []string{"users"})
+
+	if route, exists := router.Lookup("create_user_request"); !exists || route.Domain != "users" {
+		t.Errorf("Expected configured route, got %+v (exists=%t)", route, exists)
+	}
+	if _, exists := router.Lookup("delete_user_request"); exists {
+		t.Error("Expected no route for an unconfigured type")
+	}
+
+	var nilRouter *MessageRouter
+	if domain, msgType := nilRouter.Resolve("ping", "users"); domain != "users" || msgType != "ping" {
+		t.Errorf("Expected nil router to pass through, got %s/%s", domain, msgType)
+	}
+}
+
+func TestNewMessageRouterFromConfigRejectsUnknownDomain(t *testing.T) {
+	appConfig := &parser.AppConfig{
+		Domains: []parser.DomainConfig{{Name: "users"}},
+		Messages: []parser.MessageRoute{
+			{HTTPType: "create_user_request", Domain: "userz", DomainType: "user_create_request"},
+		},
+	}
+
+	if _, err := NewMessageRouterFromConfig(appConfig); err == nil {
+		t.Fatal("Expected a typo in the domain name to fail validation")
+	}
+}
+
+func TestSendMessageUsesRoutingTable(t *testing.T) {
+	fs := &FrameworkServer{
+		Router: NewMessageRouter([]parser.MessageRoute{
+			{HTTPType: "create_user_request", Domain: "users", DomainType: "user_create_request"},
+		}, []string{"users"}),
+	}
+	client := startTestFrameworkServer(t, fs)
+
+	stream, disconnect := connectDomain(t, client, "users")
+	defer disconnect()
+	waitForStream(t, fs, "users", true)
+
+	result := sendAsync(context.Background(), client, &DomainMessage{Type: "create_user_request", RequestId: "req-route"})
+
+	msg := recvRequest(t, stream)
+	if msg.Type != "user_create_request" {
+		t.Fatalf("Expected user_create_request, got %s", msg.Type)
+	}
+	if err := stream.Send(&DomainMessage{Domain: "users", Type: "user_create_response", RequestId: "req-route", Payload: `{"success":true}`}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case resp := <-result:
+		if !resp.Success {
+			t.Errorf("Expected routed request to succeed, got %q", resp.Error)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("SendMessage never returned")
+	}
+}
@@ -0,0 +1,35 @@
+package lang_adapters
+
+import "container/heap"
+
+// pendingQueue is a min-heap of pending requests ordered by Timeout, so the cleanup
+// routine can sleep until the next request expires
+type pendingQueue []*PendingRequest
+
+var _ heap.Interface = (*pendingQueue)(nil)
+
+func (q pendingQueue) Len() int { return len(q) }
+
+func (q pendingQueue) Less(i, j int) bool { return q[i].Timeout.Before(q[j].Timeout) }
+
+func (q pendingQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *pendingQueue) Push(x any) {
+	req := x.(*PendingRequest)
+	req.index = len(*q)
+	*q = append(*q, req)
+}
+
+func (q *pendingQueue) Pop() any {
+	old := *q
+	n := len(old)
+	req := old[n-1]
+	old[n-1] = nil
+	req.index = -1
+	*q = old[:n-1]
+	return req
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"fulcrum/handler"
+	"fulcrum/lib/metrics"
+	"fulcrum/lib/tracing"
 	"log"
 	"os"
 	"os/exec"
@@ -26,8 +28,19 @@ type ProcessManager struct {
 	isInitialized bool
 	appRoot       string
 	verbose       bool
+
+	handlerTimeout time.Duration
+	// externalClient is set when the handler client was attached rather than spawned
+	externalClient bool
+	// handlerConfig is the config the handler service was last started with
+	handlerConfig HandlerConfig
+	// restartMutex serializes handler service restarts
+	restartMutex sync.Mutex
 }
 
+// DefaultHandlerTimeout bounds a single handler call when no timeout is configured
+const DefaultHandlerTimeout = 100 * time.Second
+
 // ManagedProcess represents a managed Node.js process
 type ManagedProcess struct {
 	Name      string
@@ -46,9 +59,18 @@ func NewProcessManager(appRoot string, verbose bool) *ProcessManager {
 		appRoot:       appRoot,
 		verbose:       verbose,
 		isInitialized: false,
+
+		handlerTimeout: DefaultHandlerTimeout,
 	}
 }
 
+// SetHandlerTimeout configures how long a single handler call may take
+func (pm *ProcessManager) SetHandlerTimeout(timeout time.Duration) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.handlerTimeout = timeout
+}
+
 // StartHandlerService starts the FulcrumJS handler service for the application
 func (pm *ProcessManager) StartHandlerService(config HandlerConfig) error {
 	pm.mutex.Lock()
@@ -59,6 +81,8 @@ func (pm *ProcessManager) StartHandlerService(config HandlerConfig) error {
 		return fmt.Errorf("handler service is already running")
 	}
 
+	pm.handlerConfig = config
+
 	log.Printf("Starting FulcrumJS handler service...")
 
 	// Determine the command to run
@@ -100,13 +124,13 @@ func (pm *ProcessManager) StartHandlerService(config HandlerConfig) error {
 
 	// Wait for the service to be ready
 	if err := pm.waitForHandlerService(config.Port, 30*time.Second); err != nil {
-		pm.stopProcess("handlers")
+		pm.stopProcessLocked("handlers")
 		return fmt.Errorf("handler service failed to start: %w", err)
 	}
 
 	// Connect gRPC client
 	if err := pm.connectHandlerClient(config.Port); err != nil {
-		pm.stopProcess("handlers")
+		pm.stopProcessLocked("handlers")
 		return fmt.Errorf("failed to connect to handler service: %w", err)
 	}
 
@@ -277,11 +301,26 @@ func (pm *ProcessManager) GetHandlerClient() handler.HandlerServiceClient {
 	return pm.handlerClient
 }
 
+// UseHandlerClient attaches an already running handler service client instead of
+// spawning one, e.g. a handler service managed elsewhere or a stub in tests
+func (pm *ProcessManager) UseHandlerClient(client handler.HandlerServiceClient) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	pm.handlerClient = client
+	pm.isInitialized = client != nil
+	pm.externalClient = client != nil
+}
+
 // IsHandlerServiceRunning checks if the handler service is running
 func (pm *ProcessManager) IsHandlerServiceRunning() bool {
 	pm.mutex.RLock()
 	defer pm.mutex.RUnlock()
 
+	if pm.externalClient {
+		return pm.handlerClient != nil
+	}
+
 	process, exists := pm.processes["handlers"]
 	if !exists {
 		return false
@@ -297,7 +336,11 @@ func (pm *ProcessManager) IsHandlerServiceRunning() bool {
 func (pm *ProcessManager) stopProcess(name string) error {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
+	return pm.stopProcessLocked(name)
+}
 
+// stopProcessLocked stops a managed process; pm.mutex must be held
+func (pm *ProcessManager) stopProcessLocked(name string) error {
 	process, exists := pm.processes[name]
 	if !exists {
 		return fmt.Errorf("process %s not found", name)
@@ -330,6 +373,52 @@ func (pm *ProcessManager) stopProcess(name string) error {
 	return nil
 }
 
+// GetHandlerConfig returns the configuration the handler service was started with
+func (pm *ProcessManager) GetHandlerConfig() HandlerConfig {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+	return pm.handlerConfig
+}
+
+// RestartHandlerService cycles only the Node handler process, leaving the HTTP
+// and gRPC servers, database connections and domain streams untouched
+func (pm *ProcessManager) RestartHandlerService() error {
+	pm.restartMutex.Lock()
+	defer pm.restartMutex.Unlock()
+
+	start := time.Now()
+
+	pm.mutex.Lock()
+	if pm.externalClient {
+		pm.mutex.Unlock()
+		return fmt.Errorf("handler service is managed externally and cannot be restarted")
+	}
+	config := pm.handlerConfig
+	if pm.handlerConn != nil {
+		pm.handlerConn.Close()
+		pm.handlerConn = nil
+	}
+	pm.handlerClient = nil
+	pm.isInitialized = false
+	_, running := pm.processes["handlers"]
+	pm.mutex.Unlock()
+
+	log.Printf("🔄 Restarting handler service...")
+
+	if running {
+		if err := pm.stopProcess("handlers"); err != nil {
+			return fmt.Errorf("failed to stop handler service: %w", err)
+		}
+	}
+
+	if err := pm.StartHandlerService(config); err != nil {
+		return fmt.Errorf("failed to restart handler service: %w", err)
+	}
+
+	log.Printf("✅ Handler service restarted in %s", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
 // StopAll stops all managed processes
 func (pm *ProcessManager) StopAll() error {
 	pm.mutex.Lock()
@@ -346,7 +435,7 @@ func (pm *ProcessManager) StopAll() error {
 
 	// Stop all processes
 	for name := range pm.processes {
-		if err := pm.stopProcess(name); err != nil {
+		if err := pm.stopProcessLocked(name); err != nil {
 			errors = append(errors, fmt.Sprintf("failed to stop %s: %v", name, err))
 		}
 	}
@@ -401,6 +490,30 @@ func (pm *ProcessManager) AutoDetectHandlerConfig() HandlerConfig {
 
 // ExecuteHandler calls the handler service to process a request
 func (pm *ProcessManager) ExecuteHandler(domain, action string, sqlData, requestData interface{}) (interface{}, error) {
+	return pm.ExecuteHandlerWithContext(context.Background(), domain, action, sqlData, requestData)
+}
+
+// ExecuteHandlerWithContext executes a handler bounded by both the caller's context and the handler timeout
+func (pm *ProcessManager) ExecuteHandlerWithContext(ctx context.Context, domain, action string, sqlData, requestData interface{}) (interface{}, error) {
+	handlerName := domain + "." + action
+	start := time.Now()
+
+	ctx, span := tracing.Start(ctx, "handler.execute")
+	tracing.SetString(span, "handler", handlerName)
+	defer span.End()
+
+	result, err := pm.executeHandler(ctx, domain, action, sqlData, requestData)
+
+	metrics.ObserveSince(metrics.HandlerDuration, start, handlerName)
+	if err != nil {
+		metrics.HandlerFailures.Inc(handlerName)
+		tracing.RecordError(span, err)
+	}
+	return result, err
+}
+
+// executeHandler makes the handler service call for ExecuteHandlerWithContext
+func (pm *ProcessManager) executeHandler(ctx context.Context, domain, action string, sqlData, requestData interface{}) (interface{}, error) {
 	if !pm.isInitialized {
 		return nil, fmt.Errorf("handler service not initialized")
 	}
@@ -410,7 +523,11 @@ func (pm *ProcessManager) ExecuteHandler(domain, action string, sqlData, request
 		return nil, fmt.Errorf("handler client not available")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	pm.mutex.RLock()
+	timeout := pm.handlerTimeout
+	pm.mutex.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	// Convert data to protobuf structs
@@ -435,8 +552,8 @@ func (pm *ProcessManager) ExecuteHandler(domain, action string, sqlData, request
 		},
 	}
 
-	// Call handler service
-	resp, err := client.ProcessData(ctx, req)
+	// Call handler service, passing the trace context so fulcrum-js can continue it
+	resp, err := client.ProcessData(tracing.InjectGRPC(ctx), req)
 	if err != nil {
 		return nil, fmt.Errorf("handler service call failed: %w", err)
 	}
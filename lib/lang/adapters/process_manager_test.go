@@ -0,0 +1,40 @@
+package lang_adapters
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestStopAll(t *testing.T) {
+	pm := NewProcessManager(t.TempDir(), false)
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sleep is not available: %v", err)
+	}
+	pm.processes["handlers"] = &ManagedProcess{
+		Name:      "handlers",
+		Command:   cmd,
+		isRunning: true,
+		stopChan:  make(chan struct{}),
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- pm.StopAll() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Expected the processes to stop, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		cmd.Process.Kill()
+		t.Fatal("StopAll deadlocked")
+	}
+
+	if len(pm.processes) != 0 {
+		t.Errorf("Expected no processes left, got %v", pm.processes)
+	}
+	if cmd.ProcessState == nil {
+		t.Error("Expected the process to have exited")
+	}
+}
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fulcrum/lib/middleware"
+)
+
+// Default is the registry the framework records into and /metrics serves
+var Default = NewRegistry()
+
+// Framework metrics
+var (
+	HTTPRequests = Default.NewCounterVec("fulcrum_http_requests_total",
+		"HTTP requests by method, route pattern and status.", "method", "route", "status")
+	HTTPDuration = Default.NewHistogramVec("fulcrum_http_request_duration_seconds",
+		"HTTP request latency by method and route pattern.", DefaultBuckets, "method", "route")
+	HTTPTimeouts = Default.NewCounterVec("fulcrum_http_request_timeouts_total",
+		"HTTP requests that ran past the request timeout, by route pattern.", "route")
+
+	SQLDuration = Default.NewHistogramVec("fulcrum_sql_query_duration_seconds",
+		"SQL statement latency by route pattern.", DefaultBuckets, "route")
+	SQLErrors = Default.NewCounterVec("fulcrum_sql_query_errors_total",
+		"Failed SQL statements by route pattern.", "route")
+
+	HandlerDuration = Default.NewHistogramVec("fulcrum_handler_duration_seconds",
+		"Handler service call latency by domain.action.", DefaultBuckets, "handler")
+	HandlerFailures = Default.NewCounterVec("fulcrum_handler_failures_total",
+		"Failed handler service calls by domain.action.", "handler")
+
+	TemplateRenderDuration = Default.NewHistogramVec("fulcrum_template_render_duration_seconds",
+		"Template render latency by template file.", DefaultBuckets, "template")
+	LayoutRenderFailures = Default.NewCounterVec("fulcrum_layout_render_failures_total",
+		"Pages served without their layout because it failed to render.")
+
+	CacheLookups = Default.NewCounterVec("fulcrum_cache_lookups_total",
+		"Cached route result lookups by route pattern and result, hit or miss.", "route", "result")
+)
+
+// Database pool gauges, populated once a database is registered
+var (
+	dbOpenConnections = Default.NewGaugeFunc("fulcrum_db_open_connections", "Open database connections.", nil)
+	dbInUse           = Default.NewGaugeFunc("fulcrum_db_in_use_connections", "Database connections in use.", nil)
+	dbIdle            = Default.NewGaugeFunc("fulcrum_db_idle_connections", "Idle database connections.", nil)
+	dbWaitCount       = Default.NewGaugeFunc("fulcrum_db_wait_count", "Total waits for a database connection.", nil)
+	dbWaitDuration    = Default.NewGaugeFunc("fulcrum_db_wait_duration_seconds", "Total time spent waiting for a database connection.", nil)
+)
+
+// grpcPending reports requests waiting on a domain process, populated by RegisterPendingRequests
+var grpcPending = Default.NewGaugeFunc("fulcrum_grpc_pending_requests",
+	"Requests sent to domain processes that are waiting for a response.", nil)
+
+// RegisterPendingRequests reports the number of pending gRPC requests from count at scrape time
+func RegisterPendingRequests(count func() int) {
+	grpcPending.Set(func() float64 { return float64(count()) })
+}
+
+// RegisterDBStats reports connection pool stats from the given source at scrape time
+func RegisterDBStats(stats func() sql.DBStats) {
+	dbOpenConnections.Set(func() float64 { return float64(stats().OpenConnections) })
+	dbInUse.Set(func() float64 { return float64(stats().InUse) })
+	dbIdle.Set(func() float64 { return float64(stats().Idle) })
+	dbWaitCount.Set(func() float64 { return float64(stats().WaitCount) })
+	dbWaitDuration.Set(func() float64 { return stats().WaitDuration.Seconds() })
+}
+
+type routeKey struct{}
+
+// WithRoute stores the matched route pattern so deeper layers can label their metrics
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// RouteFromContext returns the route pattern stored by WithRoute, or "unknown"
+func RouteFromContext(ctx context.Context) string {
+	if route, ok := ctx.Value(routeKey{}).(string); ok && route != "" {
+		return route
+	}
+	return "unknown"
+}
+
+// InstrumentFunc records request count, status and latency under the route pattern
+func InstrumentFunc(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &middleware.StatusRecorder{ResponseWriter: w}
+
+		defer func() {
+			status := recorder.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			HTTPRequests.Inc(r.Method, route, strconv.Itoa(status))
+			HTTPDuration.Observe(time.Since(start).Seconds(), r.Method, route)
+		}()
+
+		next(recorder, r.WithContext(WithRoute(r.Context(), route)))
+	}
+}
+
+// ObserveSince records the time elapsed since start on a histogram
+func ObserveSince(h *HistogramVec, start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// HandlerOptions controls who may scrape the metrics endpoint
+type HandlerOptions struct {
+	Username string // Require basic auth when set
+	Password string
+	Public   bool // Allow scrapes from non-loopback addresses without basic auth
+}
+
+// Handler serves the Default registry in Prometheus text format
+func Handler(opts HandlerOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if opts.Username != "" {
+			user, pass, ok := r.BasicAuth()
+			if !ok || subtle.ConstantTimeCompare([]byte(user), []byte(opts.Username)) != 1 ||
+				subtle.ConstantTimeCompare([]byte(pass), []byte(opts.Password)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		} else if !opts.Public && !middleware.IsLoopback(r) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Default.WriteText(w)
+	})
+}
@@ -0,0 +1,280 @@
+// Package metrics collects framework metrics and exposes them in the Prometheus
+// text exposition format. Label values must come from bounded sets such as route
+// patterns or handler names, never from raw request paths.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are latency buckets in seconds, suited to web requests
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// collector is anything the registry can write out
+type collector interface {
+	name() string
+	write(w io.Writer)
+}
+
+// Registry holds metrics and writes them in exposition format
+type Registry struct {
+	mutex      sync.RWMutex
+	collectors map[string]collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+func (r *Registry) register(c collector) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.collectors[c.name()] = c
+}
+
+// WriteText writes every metric, sorted by name
+func (r *Registry) WriteText(w io.Writer) {
+	r.mutex.RLock()
+	collectors := make([]collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].name() < collectors[j].name()
+	})
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// CounterVec is a set of counters partitioned by labels
+type CounterVec struct {
+	metricName string
+	help       string
+	labels     []string
+
+	mutex  sync.Mutex
+	values map[string]float64
+}
+
+// NewCounterVec registers a counter with the given label names
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{metricName: name, help: help, labels: labels, values: make(map[string]float64)}
+	r.register(c)
+	return c
+}
+
+// Inc adds one to the counter for the label values
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta to the counter for the label values
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	key := labelKey(labelValues)
+	c.mutex.Lock()
+	c.values[key] += delta
+	c.mutex.Unlock()
+}
+
+// Value returns the current count for the label values
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.values[labelKey(labelValues)]
+}
+
+func (c *CounterVec) name() string { return c.metricName }
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	writeHeader(w, c.metricName, c.help, "counter")
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.metricName, formatLabels(c.labels, splitKey(key), nil), formatValue(c.values[key]))
+	}
+}
+
+// HistogramVec is a set of histograms partitioned by labels
+type HistogramVec struct {
+	metricName string
+	help       string
+	labels     []string
+	buckets    []float64
+
+	mutex  sync.Mutex
+	series map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // cumulative per bucket
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec registers a histogram with the given buckets and label names
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{metricName: name, help: help, labels: labels, buckets: buckets, series: make(map[string]*histogram)}
+	r.register(h)
+	return h
+}
+
+// Observe records a value for the label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := labelKey(labelValues)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	series, exists := h.series[key]
+	if !exists {
+		series = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = series
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			series.counts[i]++
+		}
+	}
+	series.count++
+	series.sum += value
+}
+
+// Count returns how many values were observed for the label values
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if series, exists := h.series[labelKey(labelValues)]; exists {
+		return series.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) name() string { return h.metricName }
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	writeHeader(w, h.metricName, h.help, "histogram")
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		series := h.series[key]
+		values := splitKey(key)
+
+		for i, bound := range h.buckets {
+			le := []string{"le", formatValue(bound)}
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, formatLabels(h.labels, values, le), series.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, formatLabels(h.labels, values, []string{"le", "+Inf"}), series.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.metricName, formatLabels(h.labels, values, nil), formatValue(series.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.metricName, formatLabels(h.labels, values, nil), series.count)
+	}
+}
+
+// GaugeFunc reports a value computed at scrape time
+type GaugeFunc struct {
+	metricName string
+	help       string
+
+	mutex sync.RWMutex
+	fn    func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value comes from fn
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	g := &GaugeFunc{metricName: name, help: help, fn: fn}
+	r.register(g)
+	return g
+}
+
+// Set replaces the function the gauge reads from
+func (g *GaugeFunc) Set(fn func() float64) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.fn = fn
+}
+
+func (g *GaugeFunc) name() string { return g.metricName }
+
+func (g *GaugeFunc) write(w io.Writer) {
+	g.mutex.RLock()
+	fn := g.fn
+	g.mutex.RUnlock()
+
+	if fn == nil {
+		return
+	}
+	writeHeader(w, g.metricName, g.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.metricName, formatValue(fn()))
+}
+
+// labelKey joins label values into a map key
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+func splitKey(key string) []string {
+	return strings.Split(key, "\xff")
+}
+
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+// formatLabels renders {name="value",...}, with an optional extra pair such as le
+func formatLabels(names, values []string, extra []string) string {
+	if len(names) == 0 && extra == nil {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs = append(pairs, name+`="`+labelEscaper.Replace(value)+`"`)
+	}
+	if extra != nil {
+		pairs = append(pairs, extra[0]+`="`+labelEscaper.Replace(extra[1])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// labelEscaper escapes label values as the exposition format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatValue(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWritesExpositionFormat(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("test_requests_total", "Test requests.", "route")
+	latency := r.NewHistogramVec("test_latency_seconds", "Test latency.", []float64{0.1, 1}, "route")
+	r.NewGaugeFunc("test_open", "Open things.", func() float64 { return 3 })
+
+	requests.Inc("/users")
+	requests.Add(2, "/users")
+	latency.Observe(0.05, "/users")
+	latency.Observe(0.5, "/users")
+
+	var out strings.Builder
+	r.WriteText(&out)
+	text := out.String()
+
+	expected := []string{
+		"# TYPE test_requests_total counter",
+		`test_requests_total{route="/users"} 3`,
+		"# TYPE test_latency_seconds histogram",
+		`test_latency_seconds_bucket{route="/users",le="0.1"} 1`,
+		`test_latency_seconds_bucket{route="/users",le="1"} 2`,
+		`test_latency_seconds_bucket{route="/users",le="+Inf"} 2`,
+		`test_latency_seconds_sum{route="/users"} 0.55`,
+		`test_latency_seconds_count{route="/users"} 2`,
+		"# TYPE test_open gauge",
+		"test_open 3",
+	}
+	for _, line := range expected {
+		if !strings.Contains(text, line) {
+			t.Errorf("Expected %q in output:\n%s", line, text)
+		}
+	}
+
+	if strings.Index(text, "test_latency_seconds") > strings.Index(text, "test_requests_total") {
+		t.Error("Expected metrics to be sorted by name")
+	}
+}
+
+func TestLabelValuesAreEscaped(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("test_total", "Test.", "template").Inc("a\"b\\c\nd")
+
+	var out strings.Builder
+	r.WriteText(&out)
+
+	if expected := `test_total{template="a\"b\\c\nd"} 1`; !strings.Contains(out.String(), expected) {
+		t.Errorf("Expected %q in output:\n%s", expected, out.String())
+	}
+}
+
+func TestInstrumentFuncRecordsStatus(t *testing.T) {
+	handler := InstrumentFunc("/test/instrument/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if got := RouteFromContext(r.Context()); got != "/test/instrument/{id}" {
+			t.Errorf("Expected route in context, got %q", got)
+		}
+		http.NotFound(w, r)
+	})
+
+	before := HTTPRequests.Value("GET", "/test/instrument/{id}", "404")
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test/instrument/7", nil))
+
+	if after := HTTPRequests.Value("GET", "/test/instrument/{id}", "404"); after != before+1 {
+		t.Errorf("Expected counter to move from %v to %v, got %v", before, before+1, after)
+	}
+	if HTTPDuration.Count("GET", "/test/instrument/{id}") == 0 {
+		t.Error("Expected a latency observation")
+	}
+}
+
+func TestHandlerAccessControl(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       HandlerOptions
+		remoteAddr string
+		user, pass string
+		expected   int
+	}{
+		{name: "loopback allowed", remoteAddr: "127.0.0.1:5000", expected: http.StatusOK},
+		{name: "remote forbidden", remoteAddr: "10.0.0.5:5000", expected: http.StatusForbidden},
+		{name: "remote allowed when public", opts: HandlerOptions{Public: true}, remoteAddr: "10.0.0.5:5000", expected: http.StatusOK},
+		{name: "basic auth required", opts: HandlerOptions{Username: "prom", Password: "secret"}, remoteAddr: "127.0.0.1:5000", expected: http.StatusUnauthorized},
+		{name: "basic auth wrong password", opts: HandlerOptions{Username: "prom", Password: "secret"}, remoteAddr: "10.0.0.5:5000", user: "prom", pass: "nope", expected: http.StatusUnauthorized},
+		{name: "basic auth accepted", opts: HandlerOptions{Username: "prom", Password: "secret"}, remoteAddr: "10.0.0.5:5000", user: "prom", pass: "secret", expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.user != "" {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+
+			rec := httptest.NewRecorder()
+			Handler(tt.opts).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+)
+
+// IsLoopback reports whether the request came from the local machine, by its connection
+// rather than any forwarding header
+func IsLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		expected   bool
+	}{
+		{name: "ipv4 loopback", remoteAddr: "127.0.0.1:1234", expected: true},
+		{name: "ipv6 loopback", remoteAddr: "[::1]:1234", expected: true},
+		{name: "without port", remoteAddr: "127.0.0.1", expected: true},
+		{name: "remote", remoteAddr: "192.168.1.5:80"},
+		{name: "forwarded header ignored", remoteAddr: "192.168.1.5:80", forwarded: "127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if got := IsLoopback(r); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Middleware wraps a handler with extra behaviour
+type Middleware func(http.Handler) http.Handler
+
+// RateLimitMessage is the body sent when a client is over its rate limit
+const RateLimitMessage = "Too many requests"
+
+// RateLimitStore counts requests per key. Take records a request for key and reports
+// whether it fits in limit requests per window, and if not how long until one would.
+// InMemoryStore suits a single server; a shared store such as Redis can implement
+// it to limit across servers.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// InMemoryStore is a sliding window counter per key: the previous window's count,
+// weighted by how much of it still overlaps the window ending now, plus the current one
+type InMemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]*windowCounter
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// windowCounter holds a key's counts for the current fixed window and the one before
+type windowCounter struct {
+	start    time.Time
+	current  int
+	previous int
+}
+
+// NewInMemoryStore returns an empty in-memory rate limit store
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{counters: make(map[string]*windowCounter), now: time.Now}
+}
+
+// Take implements RateLimitStore
+func (s *InMemoryStore) Take(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now, window)
+
+	counter, ok := s.counters[key]
+	if !ok {
+		counter = &windowCounter{start: now.Truncate(window)}
+		s.counters[key] = counter
+	}
+	counter.advance(now, window)
+
+	elapsed := now.Sub(counter.start)
+	weighted := float64(counter.previous)*float64(window-elapsed)/float64(window) + float64(counter.current)
+	if weighted < float64(limit) {
+		counter.current++
+		return true, 0, nil
+	}
+	return false, counter.retryAfter(limit, window, elapsed), nil
+}
+
+// advance moves the counter to the fixed window holding now
+func (c *windowCounter) advance(now time.Time, window time.Duration) {
+	start := now.Truncate(window)
+	switch {
+	case start.Equal(c.start):
+	case start.Sub(c.start) == window:
+		c.previous, c.current = c.current, 0
+	default:
+		c.previous, c.current = 0, 0
+	}
+	c.start = start
+}
+
+// retryAfter returns how long until the weighted count drops below limit
+func (c *windowCounter) retryAfter(limit int, window, elapsed time.Duration) time.Duration {
+	if c.current < limit {
+		// The previous window's share shrinks as it slides out
+		overlap := float64(limit-c.current) / float64(c.previous) * float64(window)
+		return window - time.Duration(overlap) - elapsed
+	}
+	// Wait for the next window, then for enough of this one to slide out
+	wait := window - elapsed
+	if c.current > limit {
+		wait += window - time.Duration(float64(limit)/float64(c.current)*float64(window))
+	}
+	return wait
+}
+
+// sweep drops keys idle for two windows, at most once per window
+func (s *InMemoryStore) sweep(now time.Time, window time.Duration) {
+	if now.Sub(s.lastSweep) < window {
+		return
+	}
+	s.lastSweep = now
+	for key, counter := range s.counters {
+		if now.Sub(counter.start) >= 2*window {
+			delete(s.counters, key)
+		}
+	}
+}
+
+// ClientIP returns the client address from X-Forwarded-For, then X-Real-IP, then the
+// connection. The headers are only trustworthy behind a proxy that sets them.
+func ClientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		first, _, _ := strings.Cut(forwarded, ",")
+		if ip := strings.TrimSpace(first); ip != "" {
+			return ip
+		}
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// RateLimitMiddleware allows requests per window for each key keyFn returns, answering
+// 429 with Retry-After beyond that. A nil keyFn limits by ClientIP.
+func RateLimitMiddleware(requests int, window time.Duration, keyFn func(*http.Request) string) Middleware {
+	return RateLimitWithStore(NewInMemoryStore(), requests, window, keyFn)
+}
+
+// RateLimitWithStore is RateLimitMiddleware counting in store. Requests are let
+// through when the store fails, so an outage does not take the app down with it.
+func RateLimitWithStore(store RateLimitStore, requests int, window time.Duration, keyFn func(*http.Request) string) Middleware {
+	if keyFn == nil {
+		keyFn = ClientIP
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, retryAfter, err := store.Take(r.Context(), keyFn(r), requests, window)
+			if err != nil {
+				log.Printf("⚠️ Rate limit store failed, allowing request: %v", err)
+			} else if !allowed {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+				http.Error(w, RateLimitMessage, http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestInMemoryStoreSlidingWindow(t *testing.T) {
+	store := NewInMemoryStore()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	window := time.Minute
+
+	take := func() (bool, time.Duration) {
+		allowed, retryAfter, err := store.Take(context.Background(), "client", 4, window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return allowed, retryAfter
+	}
+
+	for i := 0; i < 4; i++ {
+		if allowed, _ := take(); !allowed {
+			t.Fatalf("Expected request %d to be allowed", i+1)
+		}
+	}
+	allowed, retryAfter := take()
+	if allowed {
+		t.Fatal("Expected the fifth request in the window to be refused")
+	}
+	if retryAfter != window {
+		t.Errorf("Expected to retry once the window has slid past, got %v", retryAfter)
+	}
+
+	// A third into the next window two thirds of the previous 4 still count
+	now = now.Add(80 * time.Second)
+	for i := 0; i < 2; i++ {
+		if allowed, _ := take(); !allowed {
+			t.Fatalf("Expected request %d after the window moved to be allowed", i+1)
+		}
+	}
+	allowed, retryAfter = take()
+	if allowed {
+		t.Fatal("Expected the weighted count to refuse a third request")
+	}
+	if retryAfter != 10*time.Second {
+		t.Errorf("Expected to retry when another previous request slides out, got %v", retryAfter)
+	}
+
+	now = now.Add(11 * time.Second)
+	if allowed, _ := take(); !allowed {
+		t.Error("Expected a request to be allowed once the previous window slid out further")
+	}
+
+	// Keys are counted separately
+	if allowed, _, _ := store.Take(context.Background(), "other", 4, window); !allowed {
+		t.Error("Expected another key to have its own limit")
+	}
+
+	// Idle keys are forgotten
+	now = now.Add(3 * window)
+	store.Take(context.Background(), "other", 4, window)
+	if _, ok := store.counters["client"]; ok {
+		t.Error("Expected the idle key to be swept")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected string
+	}{
+		{name: "remote address", expected: "192.0.2.1"},
+		{name: "real ip", headers: map[string]string{"X-Real-IP": "198.51.100.7"}, expected: "198.51.100.7"},
+		{name: "forwarded for wins", headers: map[string]string{"X-Forwarded-For": "203.0.113.9, 10.0.0.1", "X-Real-IP": "198.51.100.7"}, expected: "203.0.113.9"},
+		{name: "blank forwarded for", headers: map[string]string{"X-Forwarded-For": " ,10.0.0.1"}, expected: "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+			if got := ClientIP(r); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	handler := RateLimitMiddleware(2, time.Minute, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	request := func(ip string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Forwarded-For", ip)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := request("203.0.113.9"); rec.Code != http.StatusTeapot {
+			t.Fatalf("Expected request %d to pass through, got %d", i+1, rec.Code)
+		}
+	}
+	rec := request("203.0.113.9")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", rec.Code)
+	}
+	if retryAfter := rec.Header().Get("Retry-After"); retryAfter == "" || retryAfter == "0" {
+		t.Errorf("Expected a Retry-After in seconds, got %q", retryAfter)
+	}
+	if rec := request("198.51.100.7"); rec.Code != http.StatusTeapot {
+		t.Errorf("Expected another client to pass through, got %d", rec.Code)
+	}
+}
+
+// failingStore is a RateLimitStore that is always down
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, int, time.Duration) (bool, time.Duration, error) {
+	return false, 0, errors.New("connection refused")
+}
+
+func TestRateLimitFailsOpen(t *testing.T) {
+	handler := RateLimitWithStore(failingStore{}, 1, time.Minute, func(*http.Request) string { return "user:1" })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("Expected the request through while the store is down, got %d", rec.Code)
+	}
+}
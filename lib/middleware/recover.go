@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicMessage converts a recovered panic value into a readable message
+func PanicMessage(recovered any) string {
+	switch v := recovered.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprintf("%v", recovered)
+	}
+}
+
+// Recover wraps a handler so a panic returns a 500 page instead of killing the connection.
+// In development the page includes the panic message and stack trace.
+func Recover(next http.Handler, devMode bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// Let net/http handle deliberate connection aborts
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			message := PanicMessage(recovered)
+			stack := debug.Stack()
+			log.Printf("💥 Panic serving %s %s: %s\n%s", r.Method, r.URL.Path, message, stack)
+
+			writePanicPage(w, message, stack, devMode)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RecoverFunc is Recover for plain handler functions
+func RecoverFunc(next http.HandlerFunc, devMode bool) http.HandlerFunc {
+	return Recover(next, devMode).ServeHTTP
+}
+
+// writePanicPage writes the 500 response for a recovered panic
+func writePanicPage(w http.ResponseWriter, message string, stack []byte, devMode bool) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+
+	if !devMode {
+		fmt.Fprint(w, `
+			<html>
+			<head><title>Internal Server Error</title></head>
+			<body>
+				<h1>Internal Server Error</h1>
+				<p>Something went wrong while processing your request.</p>
+			</body>
+			</html>
+		`)
+		return
+	}
+
+	fmt.Fprintf(w, `
+		<html>
+		<head><title>Internal Server Error</title></head>
+		<body>
+			<h1>Request Panicked</h1>
+			<p><strong>Error:</strong> %s</p>
+			<pre>%s</pre>
+		</body>
+		</html>
+	`, html.EscapeString(message), html.EscapeString(string(stack)))
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecoverReturns500(t *testing.T) {
+	tests := []struct {
+		name         string
+		devMode      bool
+		expectDetail bool
+	}{
+		{name: "development shows panic detail", devMode: true, expectDetail: true},
+		{name: "production hides panic detail", devMode: false, expectDetail: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic("template helper exploded")
+			}), tt.devMode)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+			}
+
+			hasDetail := strings.Contains(rec.Body.String(), "template helper exploded")
+			if hasDetail != tt.expectDetail {
+				t.Errorf("Expected panic detail in body: %v, got body %q", tt.expectDetail, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRecoverPassesThrough(t *testing.T) {
+	handler := RecoverFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}, false)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("Expected status %d, got %d", http.StatusTeapot, rec.Code)
+	}
+}
+
+func TestPanicMessage(t *testing.T) {
+	tests := []struct {
+		recovered any
+		expected  string
+	}{
+		{"plain string", "plain string"},
+		{errors.New("an error"), "an error"},
+		{42, "42"},
+	}
+
+	for _, tt := range tests {
+		if got := PanicMessage(tt.recovered); got != tt.expected {
+			t.Errorf("PanicMessage(%v) = %q, expected %q", tt.recovered, got, tt.expected)
+		}
+	}
+}
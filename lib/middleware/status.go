@@ -0,0 +1,28 @@
+package middleware
+
+import "net/http"
+
+// StatusRecorder captures the status code written by a handler
+type StatusRecorder struct {
+	http.ResponseWriter
+	Status int // 0 until the handler writes
+}
+
+func (sr *StatusRecorder) WriteHeader(status int) {
+	if sr.Status == 0 {
+		sr.Status = status
+	}
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func (sr *StatusRecorder) Write(b []byte) (int, error) {
+	if sr.Status == 0 {
+		sr.Status = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (sr *StatusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
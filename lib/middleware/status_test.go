@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusRecorder(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		expected int
+	}{
+		{name: "nothing written", handler: func(w http.ResponseWriter, r *http.Request) {}, expected: 0},
+		{name: "body only", handler: func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) }, expected: http.StatusOK},
+		{name: "first status wins", handler: func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.WriteHeader(http.StatusOK)
+		}, expected: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &StatusRecorder{ResponseWriter: httptest.NewRecorder()}
+			tt.handler(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Status != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, recorder.Status)
+			}
+		})
+	}
+}
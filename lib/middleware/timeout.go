@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TimeoutMessage is the body sent when a request runs past its timeout
+const TimeoutMessage = "Request timed out"
+
+// Timeout bounds next by timeout(r), cancelling the request context at the deadline.
+// Like http.TimeoutHandler, next writes to a buffer that is sent once it returns, so a
+// handler that ignores its context cannot hang the client or write after the deadline.
+// onTimeout writes the response to a request that timed out; nil answers 503.
+func Timeout(next http.Handler, timeout func(*http.Request) time.Duration, onTimeout func(http.ResponseWriter, *http.Request)) http.Handler {
+	if onTimeout == nil {
+		onTimeout = func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, TimeoutMessage, http.StatusServiceUnavailable)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout(r))
+		defer cancel()
+		r = r.WithContext(ctx)
+
+		tw := &timeoutWriter{header: make(http.Header)}
+		done := make(chan struct{})
+		panics := make(chan any, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panics <- p
+				}
+			}()
+			next.ServeHTTP(tw, r)
+			close(done)
+		}()
+
+		select {
+		case p := <-panics:
+			panic(p)
+		case <-done:
+			tw.mutex.Lock()
+			defer tw.mutex.Unlock()
+			maps.Copy(w.Header(), tw.header)
+			if tw.status == 0 {
+				tw.status = http.StatusOK
+			}
+			w.WriteHeader(tw.status)
+			w.Write(tw.body.Bytes())
+		case <-ctx.Done():
+			tw.mutex.Lock()
+			defer tw.mutex.Unlock()
+			tw.timedOut = true
+			// A client that went away gets no answer
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				onTimeout(w, r)
+			}
+		}
+	})
+}
+
+// TimeoutFunc is Timeout for plain handler functions
+func TimeoutFunc(next http.HandlerFunc, timeout func(*http.Request) time.Duration, onTimeout func(http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return Timeout(next, timeout, onTimeout).ServeHTTP
+}
+
+// timeoutWriter buffers a bounded handler's response, refusing writes once it timed out
+type timeoutWriter struct {
+	mutex    sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header { return tw.header }
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	return tw.body.Write(b)
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	if tw.timedOut || tw.status != 0 {
+		return
+	}
+	tw.status = status
+}
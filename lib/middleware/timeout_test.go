@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	gatewayTimeout := func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gateway timeout", http.StatusGatewayTimeout)
+	}
+
+	tests := []struct {
+		name       string
+		delay      time.Duration
+		timeout    time.Duration
+		onTimeout  func(http.ResponseWriter, *http.Request)
+		expectCode int
+		expectBody string
+	}{
+		{name: "fast request passes through", delay: 0, timeout: time.Second, expectCode: http.StatusTeapot, expectBody: "brewed"},
+		{name: "slow request answers 503", delay: time.Second, timeout: 20 * time.Millisecond, expectCode: http.StatusServiceUnavailable, expectBody: TimeoutMessage + "\n"},
+		{name: "slow request answered by onTimeout", delay: time.Second, timeout: 20 * time.Millisecond, onTimeout: gatewayTimeout, expectCode: http.StatusGatewayTimeout, expectBody: "gateway timeout\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lateWrite := make(chan error, 1)
+			// The handler ignores its context, as handlers that never check it do
+			handler := TimeoutFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(tt.delay)
+				w.Header().Set("X-Brewed", "yes")
+				w.WriteHeader(http.StatusTeapot)
+				_, err := w.Write([]byte("brewed"))
+				lateWrite <- err
+			}, func(*http.Request) time.Duration { return tt.timeout }, tt.onTimeout)
+
+			start := time.Now()
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+			if elapsed := time.Since(start); elapsed > tt.timeout+500*time.Millisecond {
+				t.Errorf("Expected an answer by the timeout, took %s", elapsed)
+			}
+			if rec.Code != tt.expectCode || rec.Body.String() != tt.expectBody {
+				t.Errorf("Expected %d %q, got %d %q", tt.expectCode, tt.expectBody, rec.Code, rec.Body.String())
+			}
+			if tt.expectCode == http.StatusTeapot && rec.Header().Get("X-Brewed") != "yes" {
+				t.Error("Expected the handler's headers to be sent")
+			}
+			if tt.expectCode != http.StatusTeapot {
+				if err := <-lateWrite; !errors.Is(err, http.ErrHandlerTimeout) {
+					t.Errorf("Expected writes after the timeout to fail, got %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Package naming inflects the names generators turn into table names, URLs and labels.
+// Multi-word names may be snake_case, kebab-case, space separated or CamelCase; only
+// the last word is pluralized or singularized, so "order_item" becomes "order_items".
+package naming
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// rule rewrites a word whose ending matches pattern
+type rule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+func newRules(pairs ...string) []rule {
+	rules := make([]rule, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		rules = append(rules, rule{regexp.MustCompile(pairs[i]), pairs[i+1]})
+	}
+	return rules
+}
+
+// pluralRules are tried in order; the first match wins
+var pluralRules = newRules(
+	`(quiz)$`, "${1}zes",
+	`(matr)ix$`, "${1}ices",
+	`(vert|ind)ex$`, "${1}ices",
+	`(her|potat|tomat|ech|vet)o$`, "${1}oes",
+	`sis$`, "ses",
+	`(x|ch|ss|sh|zz|s|z)$`, "${1}es",
+	`([^aeiouy]|qu)y$`, "${1}ies",
+	`([lr])f$`, "${1}ves",
+	`([^f])fe$`, "${1}ves",
+	`$`, "s",
+)
+
+// singularRules are tried in order; the first match wins
+var singularRules = newRules(
+	`(quiz)zes$`, "${1}",
+	`(matr)ices$`, "${1}ix",
+	`(vert|ind)ices$`, "${1}ex",
+	`(her|potat|tomat|ech|vet)oes$`, "${1}o",
+	`(analy|ba|diagno|parenthe|progno|synop|the|cri)ses$`, "${1}sis",
+	`(x|ch|ss|sh|zz)es$`, "${1}",
+	`(alias|status|bus|campus|virus|gas|lens|plus)es$`, "${1}",
+	`([^aeiouy]|qu)ies$`, "${1}y",
+	`(kni|li|wi)ves$`, "${1}fe",
+	`([lr])ves$`, "${1}f",
+	`(ss|us|is|alias|atlas|bias|canvas|gas|lens|plus)$`, "${1}",
+	`s$`, "",
+)
+
+// irregulars maps singular words to plurals the rules get wrong
+var irregulars = map[string]string{
+	"person": "people",
+	"man":    "men",
+	"woman":  "women",
+	"child":  "children",
+	"tooth":  "teeth",
+	"foot":   "feet",
+	"mouse":  "mice",
+	"goose":  "geese",
+	"ox":     "oxen",
+	"movie":  "movies",
+	"cookie": "cookies",
+	"tie":    "ties",
+	"pie":    "pies",
+	"zombie": "zombies",
+}
+
+// irregularSingulars is irregulars inverted
+var irregularSingulars = func() map[string]string {
+	singulars := make(map[string]string, len(irregulars))
+	for singular, plural := range irregulars {
+		singulars[plural] = singular
+	}
+	return singulars
+}()
+
+// uncountables have the same singular and plural
+var uncountables = map[string]bool{
+	"data":        true,
+	"equipment":   true,
+	"fish":        true,
+	"information": true,
+	"metadata":    true,
+	"money":       true,
+	"news":        true,
+	"rice":        true,
+	"series":      true,
+	"sheep":       true,
+	"species":     true,
+}
+
+// Pluralize returns the plural of a name's last word: "category" -> "categories",
+// "person" -> "people", "order_item" -> "order_items". Plural names such as "users"
+// are returned unchanged.
+func Pluralize(name string) string {
+	if singular := Singularize(name); singular != name && inflectLastWord(singular, irregulars, pluralRules) == name {
+		return name
+	}
+	return inflectLastWord(name, irregulars, pluralRules)
+}
+
+// Singularize returns the singular of a name's last word: "categories" -> "category",
+// "people" -> "person", "order_items" -> "order_item"
+func Singularize(name string) string {
+	return inflectLastWord(name, irregularSingulars, singularRules)
+}
+
+// inflectLastWord rewrites the last word of name, keeping the rest and the word's case
+func inflectLastWord(name string, exceptions map[string]string, rules []rule) string {
+	start := lastWordStart(name)
+	prefix, word := name[:start], name[start:]
+	lower := strings.ToLower(word)
+	if lower == "" || uncountables[lower] {
+		return name
+	}
+
+	inflected, ok := exceptions[lower]
+	if !ok {
+		inflected = lower
+		for _, r := range rules {
+			if r.pattern.MatchString(lower) {
+				inflected = r.pattern.ReplaceAllString(lower, r.replacement)
+				break
+			}
+		}
+	}
+	return prefix + matchCase(word, inflected)
+}
+
+// lastWordStart returns where the last word of name begins, after a separator or at
+// a CamelCase boundary
+func lastWordStart(name string) int {
+	runes := []rune(name)
+	for i := len(runes) - 1; i > 0; i-- {
+		if isSeparator(runes[i-1]) || wordBoundary(runes, i) {
+			return len(string(runes[:i]))
+		}
+	}
+	return 0
+}
+
+// matchCase gives inflected the capitalization of word: "Person" -> "People"
+func matchCase(word, inflected string) string {
+	switch {
+	case word == strings.ToUpper(word) && len(word) > 1:
+		return strings.ToUpper(inflected)
+	case unicode.IsUpper([]rune(word)[0]):
+		return capitalize(inflected)
+	}
+	return inflected
+}
+
+// Words splits a name into lowercase words at separators and CamelCase boundaries:
+// "orderItem", "order_item" and "Order Item" all give ["order", "item"]
+func Words(name string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(name)
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	for i, r := range runes {
+		if isSeparator(r) {
+			flush()
+			continue
+		}
+		if len(current) > 0 && wordBoundary(runes, i) {
+			flush()
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
+
+// Titleize returns a name as space separated capitalized words: "order_item" -> "Order Item"
+func Titleize(name string) string {
+	words := Words(name)
+	for i, word := range words {
+		words[i] = capitalize(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// Camelize returns a name in CamelCase: "order_item" -> "OrderItem"
+func Camelize(name string) string {
+	words := Words(name)
+	for i, word := range words {
+		words[i] = capitalize(word)
+	}
+	return strings.Join(words, "")
+}
+
+// SnakeCase returns a name in snake_case: "OrderItem" -> "order_item"
+func SnakeCase(name string) string {
+	return strings.Join(Words(name), "_")
+}
+
+// wordBoundary reports whether a CamelCase word starts at runes[i]: "orderItem",
+// "HTTPServer"
+func wordBoundary(runes []rune, i int) bool {
+	if i == 0 || !unicode.IsUpper(runes[i]) {
+		return false
+	}
+	nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+	return !unicode.IsUpper(runes[i-1]) || nextIsLower
+}
+
+func isSeparator(r rune) bool {
+	return r == '_' || r == '-' || r == ' ' || r == '.'
+}
+
+func capitalize(word string) string {
+	if word == "" {
+		return word
+	}
+	runes := []rune(word)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
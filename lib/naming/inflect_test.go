@@ -0,0 +1,129 @@
+package naming
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPluralizeAndSingularize(t *testing.T) {
+	tests := []struct {
+		singular string
+		plural   string
+	}{
+		{"user", "users"},
+		{"post", "posts"},
+		{"category", "categories"},
+		{"query", "queries"},
+		{"soliloquy", "soliloquies"},
+		{"boy", "boys"},
+		{"day", "days"},
+		{"key", "keys"},
+		{"status", "statuses"},
+		{"bus", "buses"},
+		{"gas", "gases"},
+		{"campus", "campuses"},
+		{"address", "addresses"},
+		{"class", "classes"},
+		{"box", "boxes"},
+		{"tax", "taxes"},
+		{"church", "churches"},
+		{"dish", "dishes"},
+		{"buzz", "buzzes"},
+		{"quiz", "quizzes"},
+		{"person", "people"},
+		{"child", "children"},
+		{"man", "men"},
+		{"woman", "women"},
+		{"mouse", "mice"},
+		{"ox", "oxen"},
+		{"movie", "movies"},
+		{"cookie", "cookies"},
+		{"analysis", "analyses"},
+		{"basis", "bases"},
+		{"matrix", "matrices"},
+		{"index", "indices"},
+		{"hero", "heroes"},
+		{"potato", "potatoes"},
+		{"photo", "photos"},
+		{"wolf", "wolves"},
+		{"half", "halves"},
+		{"knife", "knives"},
+		{"life", "lives"},
+		{"house", "houses"},
+		{"case", "cases"},
+		{"sheep", "sheep"},
+		{"series", "series"},
+		{"news", "news"},
+		{"data", "data"},
+		{"order_item", "order_items"},
+		{"line-item", "line-items"},
+		{"sales_person", "sales_people"},
+		{"product_category", "product_categories"},
+		{"OrderStatus", "OrderStatuses"},
+		{"Person", "People"},
+		{"HTTPServer", "HTTPServers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.singular, func(t *testing.T) {
+			if got := Pluralize(tt.singular); got != tt.plural {
+				t.Errorf("Pluralize(%q) = %q, want %q", tt.singular, got, tt.plural)
+			}
+			if got := Singularize(tt.plural); got != tt.singular {
+				t.Errorf("Singularize(%q) = %q, want %q", tt.plural, got, tt.singular)
+			}
+		})
+	}
+}
+
+func TestInflectionLeavesInflectedWords(t *testing.T) {
+	for _, word := range []string{"users", "people", "categories", "order_items", "statuses", "sheep"} {
+		if got := Pluralize(word); got != word {
+			t.Errorf("Pluralize(%q) = %q, want it unchanged", word, got)
+		}
+	}
+	for _, word := range []string{"status", "class", "analysis", "user_status", "gas", "quiz", "sheep"} {
+		if got := Singularize(word); got != word {
+			t.Errorf("Singularize(%q) = %q, want it unchanged", word, got)
+		}
+	}
+}
+
+func TestCaseConversions(t *testing.T) {
+	tests := []struct {
+		name     string
+		words    []string
+		titleize string
+		camelize string
+		snake    string
+	}{
+		{"user", []string{"user"}, "User", "User", "user"},
+		{"order_item", []string{"order", "item"}, "Order Item", "OrderItem", "order_item"},
+		{"line-item", []string{"line", "item"}, "Line Item", "LineItem", "line_item"},
+		{"shipping address", []string{"shipping", "address"}, "Shipping Address", "ShippingAddress", "shipping_address"},
+		{"OrderItem", []string{"order", "item"}, "Order Item", "OrderItem", "order_item"},
+		{"orderItem", []string{"order", "item"}, "Order Item", "OrderItem", "order_item"},
+		{"HTTPServer", []string{"http", "server"}, "Http Server", "HttpServer", "http_server"},
+		{"user_id", []string{"user", "id"}, "User Id", "UserId", "user_id"},
+		{"userID", []string{"user", "id"}, "User Id", "UserId", "user_id"},
+		{"__private__", []string{"private"}, "Private", "Private", "private"},
+		{"", nil, "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Words(tt.name); !reflect.DeepEqual(got, tt.words) {
+				t.Errorf("Words(%q) = %q, want %q", tt.name, got, tt.words)
+			}
+			if got := Titleize(tt.name); got != tt.titleize {
+				t.Errorf("Titleize(%q) = %q, want %q", tt.name, got, tt.titleize)
+			}
+			if got := Camelize(tt.name); got != tt.camelize {
+				t.Errorf("Camelize(%q) = %q, want %q", tt.name, got, tt.camelize)
+			}
+			if got := SnakeCase(tt.name); got != tt.snake {
+				t.Errorf("SnakeCase(%q) = %q, want %q", tt.name, got, tt.snake)
+			}
+		})
+	}
+}
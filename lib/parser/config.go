@@ -7,7 +7,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	views "fulcrum/lib/views"
 
@@ -21,11 +24,245 @@ type AppConfig struct {
 	Path    string         `yaml:"path"`
 	Root    string         `yaml:"root"`
 	Mode    string
-	Views   *views.TemplateRenderer
+	Views   views.Renderer `yaml:"-"`
+
+	// Mock serves labeled placeholder rows for SQL routes instead of connecting to the database
+	Mock bool `yaml:"-"`
+
+	// StrictLayout fails the request when the layout cannot be rendered, even in production
+	StrictLayout bool `yaml:"strict_layout"`
+
+	// StrictEscaping refuses to load HTML templates that write user input with {{{ }}}
+	StrictEscaping bool `yaml:"strict_escaping"`
+
+	// ViewsConfig picks the engine the app's HTML templates are written for
+	ViewsConfig ViewsConfig `yaml:"views"`
+
+	// RequestTimeout is the older spelling of server.request_timeout_seconds
+	RequestTimeout int `yaml:"request_timeout_seconds"`
+	// HandlerTimeout bounds each call to the JavaScript handler service in seconds
+	HandlerTimeout int `yaml:"handler_timeout_seconds"`
+
+	// DomainQueueSize caps messages held for a reconnecting domain process; 0 uses the default
+	DomainQueueSize int `yaml:"domain_queue_size"`
+	// DomainQueueTTL is how long, in seconds, a queued message waits for its domain to reconnect
+	DomainQueueTTL int `yaml:"domain_queue_ttl_seconds"`
+
+	// Messages routes message types sent to the framework server to domain handlers
+	Messages []MessageRoute `yaml:"messages"`
+
+	// Server configures HTTP request handling
+	Server ServerConfig `yaml:"server"`
+
+	// Auth configures the login required for routes outside the auth domain
+	Auth AuthConfig `yaml:"auth"`
+
+	// I18n configures translations loaded from locales/<lang>.yml
+	I18n I18nConfig `yaml:"i18n"`
+
+	// Tenancy scopes each request to a tenant; it is off unless resolver is set
+	Tenancy TenancyConfig `yaml:"tenancy"`
+
+	// Metrics configures the opt-in Prometheus /metrics endpoint
+	Metrics MetricsConfig `yaml:"metrics"`
+
+	// Tracing configures OpenTelemetry tracing
+	Tracing TracingConfig `yaml:"tracing"`
+
+	// Audit lists the tables whose changes are recorded in audit_log
+	Audit []string `yaml:"audit"`
+
+	// Warnings lists unknown keys found in fulcrum.yml and its environment overlay
+	Warnings []string `yaml:"-"`
+
+	// sources are the loaded config files, kept to point validation errors at a line
+	sources []configSource
+}
+
+// configSource is the interpolated content of one loaded config file
+type configSource struct {
+	name string
+	data []byte
+}
+
+// ViewsConfig picks the template engine of HTML templates. SQL templates and the
+// templates of other formats are Handlebars whatever the engine.
+type ViewsConfig struct {
+	Engine string `yaml:"engine"` // handlebars (default) or gotemplate, for .html.tmpl files
+}
+
+// TracingConfig enables OpenTelemetry tracing; it is off unless enabled is set
+type TracingConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	OTLPEndpoint string   `yaml:"otlp_endpoint"` // host:port of the OTLP gRPC collector
+	Insecure     bool     `yaml:"insecure"`      // Connect to the collector without TLS
+	SampleRate   *float64 `yaml:"sample_rate"`   // Fraction of traces recorded; defaults to 1
+	ServiceName  string   `yaml:"service_name"`  // Defaults to fulcrum
+}
+
+// Rate returns the configured sample rate, defaulting to sampling every trace
+func (tc TracingConfig) Rate() float64 {
+	if tc.SampleRate == nil {
+		return 1
+	}
+	return *tc.SampleRate
+}
+
+// ServerConfig bounds how long a request (SQL, handlers and rendering) may take, in seconds
+type ServerConfig struct {
+	RequestTimeout     int `yaml:"request_timeout_seconds"`      // Defaults to 30
+	HTMXRequestTimeout int `yaml:"htmx_request_timeout_seconds"` // Defaults to request_timeout_seconds
+
+	// RateLimit caps requests per client; it is off unless requests is set
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+
+	// Uploads limits multipart/form-data requests and sets where their files are stored
+	Uploads UploadsConfig `yaml:"uploads"`
+
+	// Cache bounds the store of routes cached with a cache: block in route.yaml
+	Cache CacheConfig `yaml:"cache"`
+}
+
+// CacheConfig sizes the in-memory store of cached route results
+type CacheConfig struct {
+	MaxEntries int `yaml:"max_entries"` // Defaults to 1000
+}
+
+// RouteCacheConfig caches a GET route's SQL results; read from the cache: block of route.yaml
+type RouteCacheConfig struct {
+	TTL    int      `yaml:"ttl"`     // Seconds results are kept; 0 leaves the route uncached
+	VaryOn []string `yaml:"vary_on"` // Query parameters, or "user", that select separate entries
+}
+
+// CacheVaryUser in vary_on keeps a separate entry per signed-in user
+const CacheVaryUser = "user"
+
+// Enabled reports whether the route's results are cached
+func (rc RouteCacheConfig) Enabled() bool {
+	return rc.TTL > 0
+}
+
+// UploadsConfig limits file uploads and names the directory they are saved in
+type UploadsConfig struct {
+	MaxSizeMB   int    `yaml:"max_size_mb"`   // Largest accepted request body; defaults to 10
+	MaxMemoryMB int    `yaml:"max_memory_mb"` // Held in memory before spilling to temp files; defaults to 8
+	Dir         string `yaml:"dir"`           // Relative to the app root; defaults to uploads
+}
+
+// MaxSize returns the largest accepted upload request in bytes
+func (uc UploadsConfig) MaxSize() int64 {
+	if uc.MaxSizeMB <= 0 {
+		return 10 << 20
+	}
+	return int64(uc.MaxSizeMB) << 20
+}
+
+// MaxMemory returns how much of an upload is held in memory, in bytes
+func (uc UploadsConfig) MaxMemory() int64 {
+	if uc.MaxMemoryMB <= 0 {
+		return 8 << 20
+	}
+	return int64(uc.MaxMemoryMB) << 20
+}
+
+// Directory returns the upload directory, defaulting to uploads
+func (uc UploadsConfig) Directory() string {
+	if uc.Dir == "" {
+		return "uploads"
+	}
+	return uc.Dir
+}
+
+// RateLimitConfig allows requests per window_seconds to each client IP, or with by: user
+// to each signed-in user
+type RateLimitConfig struct {
+	Requests      int    `yaml:"requests"`
+	WindowSeconds int    `yaml:"window_seconds"` // Defaults to 60
+	By            string `yaml:"by"`             // ip (default) or user
+}
+
+// Window returns the configured window, defaulting to a minute
+func (rc RateLimitConfig) Window() time.Duration {
+	if rc.WindowSeconds <= 0 {
+		return time.Minute
+	}
+	return time.Duration(rc.WindowSeconds) * time.Second
+}
+
+// AuthConfig controls login; by default routes outside the auth domain require it
+type AuthConfig struct {
+	Disabled bool   `yaml:"disabled"` // Serve every route without login, e.g. for API-only apps
+	Sessions string `yaml:"sessions"` // Session store: jwt (default) or database
+}
+
+// I18nConfig sets the locale translations fall back to
+type I18nConfig struct {
+	DefaultLocale string `yaml:"default_locale"` // Defaults to en
+}
+
+// Tenant resolvers accepted by tenancy.resolver in fulcrum.yml
+const (
+	TenantBySubdomain = "subdomain"
+	TenantByHeader    = "header"
+	TenantByPath      = "path"
+)
+
+// DefaultTenantHeader is the header the header resolver reads unless tenancy.header is set
+const DefaultTenantHeader = "X-Tenant-ID"
+
+// TenancyConfig names where a request's tenant slug comes from: the first label of the
+// host, a header, or the first path segment
+type TenancyConfig struct {
+	Resolver string `yaml:"resolver"` // subdomain, header or path
+	Header   string `yaml:"header"`   // Defaults to X-Tenant-ID
+}
+
+// Enabled reports whether requests are resolved to tenants
+func (tc TenancyConfig) Enabled() bool {
+	return tc.Resolver != ""
+}
+
+// HeaderName returns the header the header resolver reads
+func (tc TenancyConfig) HeaderName() string {
+	if tc.Header == "" {
+		return DefaultTenantHeader
+	}
+	return tc.Header
+}
+
+// MetricsConfig controls the metrics endpoint; it is off unless enabled is set, and
+// then only answers loopback scrapes
+type MetricsConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Address  string `yaml:"address"`  // Serve on a separate admin listener, e.g. 127.0.0.1:9090
+	Path     string `yaml:"path"`     // Defaults to /metrics
+	Username string `yaml:"username"` // Require basic auth when set
+	Password string `yaml:"password"`
+	Public   bool   `yaml:"public"` // Allow scrapes from other hosts without basic auth
 }
 
+// MetricsPath returns the configured metrics path
+func (mc MetricsConfig) MetricsPath() string {
+	if mc.Path == "" {
+		return "/metrics"
+	}
+	return mc.Path
+}
+
+// MessageRoute maps an incoming message type to the domain and message type that handles it
+type MessageRoute struct {
+	HTTPType   string `yaml:"http_type"`   // Message type sent by the HTTP layer
+	Domain     string `yaml:"domain"`      // Domain that handles it
+	DomainType string `yaml:"domain_type"` // Message type the domain listens for
+}
+
+// DefaultRequestTimeout is used when request_timeout_seconds is not configured
+const DefaultRequestTimeout = 30 * time.Second
+
 // DBConfig holds database configuration
 type DBConfig struct {
+	// URL sets the connection in one string; settings given explicitly take precedence
+	URL             string `yaml:"url"`
 	Driver          string `yaml:"driver"` // postgres, mysql, sqlite
 	Host            string `yaml:"host"`
 	Port            int    `yaml:"port"`
@@ -36,17 +273,54 @@ type DBConfig struct {
 	MaxOpenConns    int    `yaml:"max_open_conns"`
 	MaxIdleConns    int    `yaml:"max_idle_conns"`
 	ConnMaxLifetime int    `yaml:"conn_max_lifetime_minutes"`
+	// StatementCacheSize keeps that many SQL template statements prepared; 0 disables it
+	StatementCacheSize int `yaml:"statement_cache_size"`
+	// MaxRetries retries statements that fail transiently; 0 disables it
+	MaxRetries     int `yaml:"max_retries"`
+	RetryBackoffMs int `yaml:"retry_backoff_ms"` // Wait before the first retry; 0 uses 50ms
+	// SlowQueryMs logs statements slower than this; 0 uses 500ms, negative disables it
+	SlowQueryMs int `yaml:"slow_query_ms"`
 	// SQLite specific
 	FilePath string `yaml:"file_path"`
+	// Replicas serve ExecuteSQL's reads; settings a replica leaves empty are the primary's
+	Replicas []DBConfig `yaml:"replicas"`
 }
 
 // DomainConfig represents a single domain configuration
 type DomainConfig struct {
-	Models   []ModelDefinition `yaml:"models"`
-	Logic    LogicConfig       `yaml:"logic"`
-	Name     string            `yaml:"name"`
-	Path     string            `yaml:"path"`
-	ViewPath string            `yaml:"viewpath"`
+	Models     []ModelDefinition `yaml:"models"`
+	Logic      LogicConfig       `yaml:"logic"`
+	Search     SearchConfig      `yaml:"search"`
+	Sort       SortConfig        `yaml:"sort"`
+	Middleware []string          `yaml:"middleware"` // Wraps the domain's routes, outermost first, e.g. auth or rate_limit:100:60
+	Name       string            `yaml:"name"`
+	Path       string            `yaml:"path"`
+	ViewPath   string            `yaml:"viewpath"`
+
+	// LockColumn holds the version of the domain's records for optimistic locking
+	LockColumn string `yaml:"lock_column"`
+}
+
+// DefaultLockColumn is the column holding a record's version for optimistic locking
+const DefaultLockColumn = "lock_version"
+
+// LockColumnName returns the domain's lock column: an update whose SQL compares it with
+// :lock_column and changes no rows lost to another update
+func (dc *DomainConfig) LockColumnName() string {
+	if dc.LockColumn != "" {
+		return dc.LockColumn
+	}
+	return DefaultLockColumn
+}
+
+// SearchConfig lists the columns ?q= matches on the domain's routes
+type SearchConfig struct {
+	Columns []string `yaml:"columns"`
+}
+
+// SortConfig lists the columns ?sort= accepts; empty allows every model field
+type SortConfig struct {
+	Columns []string `yaml:"columns"`
 }
 
 // ModelDefinition defines data models for a domain
@@ -77,36 +351,155 @@ type HTTPConfig struct {
 
 // RedirectRule represents a redirect configuration
 type RedirectRule struct {
-	To     string `yaml:"to"`     // Target URL pattern
-	Status int    `yaml:"status"` // HTTP status code (default: 303)
-	When   string `yaml:"when"`   // Condition: "success", "error", "always"
+	To     string `yaml:"to" json:"to"`                   // Target URL pattern
+	Status int    `yaml:"status" json:"status,omitempty"` // HTTP status code (default: 303)
+	When   string `yaml:"when" json:"when,omitempty"`     // Condition: "success", "error", "always"
 }
 
 // Route defines a single HTTP route
 type Route struct {
-	Method       string       `yaml:"method"`        // HTTP method: GET, POST, etc.
-	Link         string       `yaml:"link"`          // URL pattern: /users/:id
-	View         string       `yaml:"view"`          // Template filename: get.html.hbs
-	Path         string       `yaml:"path"`          // Unique route identifier
-	ViewPath     string       `yaml:"viewpath"`      // Full path to template file
-	Format       string       `yaml:"format"`        // Response format: html, json, sql
-	Redirect     RedirectRule `yaml:"redirect"`      // Redirect configuration
-	TemplateName string       `yaml:"template_name"` // Preloaded template name
+	Method        string           `yaml:"method"`        // HTTP method: GET, POST, etc.
+	Link          string           `yaml:"link"`          // URL pattern: /users/:id
+	View          string           `yaml:"view"`          // Template filename: get.html.hbs
+	Path          string           `yaml:"path"`          // Unique route identifier
+	ViewPath      string           `yaml:"viewpath"`      // Full path to template file
+	Format        string           `yaml:"format"`        // Response format: html, json, sql
+	Query         string           `yaml:"query"`         // Name of a secondary SQL query, e.g. stats for stats.sql.hbs
+	Redirect      RedirectRule     `yaml:"redirect"`      // Redirect configuration
+	TemplateName  string           `yaml:"template_name"` // Preloaded template name
+	Transactional bool             `yaml:"transactional"` // Run the route's SQL and handler in one transaction
+	Permit        []string         `yaml:"permit"`        // Request fields a create or update route accepts
+	Cache         RouteCacheConfig `yaml:"cache"`         // Caching of the route's SQL results
+	HTMXFragments []string         `yaml:"fragments"`     // Out-of-band fragments of HTMX responses, from htmx.yaml
+}
+
+// HTMXOptions are read from an htmx.yaml file next to a route's templates
+type HTMXOptions struct {
+	// Fragments names the <name>.htmx.hbs files an HTMX response to the route appends,
+	// each swapped out of band
+	Fragments []string `yaml:"fragments"`
+}
+
+// HTMXFragmentPath returns the file of the route's fragment name, e.g. counter.htmx.hbs
+// beside get.html.hbs or counter.htmx.tmpl beside get.html.tmpl
+func (r Route) HTMXFragmentPath(name string) string {
+	ext := filepath.Ext(r.ViewPath)
+	return filepath.Join(filepath.Dir(r.ViewPath), name+".htmx"+ext)
+}
+
+// httpMethods are the methods a route file can be named for, e.g. get.html.hbs
+var httpMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// ReservedQueryNames are the keys a route's own results are served under, which its
+// named queries cannot use
+var ReservedQueryNames = []string{"data", "count", "success"}
+
+// RouteOptions are read from a route.yaml file next to a route's templates
+type RouteOptions struct {
+	Transactional bool             `yaml:"transactional"`
+	Permit        []string         `yaml:"permit"`
+	Cache         RouteCacheConfig `yaml:"cache"`
+}
+
+// IsDevelopment reports whether the app runs in development mode, either via
+// the dev command or the FULCRUM_ENV environment variable
+func (ac *AppConfig) IsDevelopment() bool {
+	if ac.Mode == "develop" {
+		return true
+	}
+	env := strings.ToLower(os.Getenv("FULCRUM_ENV"))
+	return env == "develop" || env == "development"
+}
+
+// StrictEscapingEnabled reports whether templates writing user input unescaped are refused
+func (ac *AppConfig) StrictEscapingEnabled() bool {
+	return ac.StrictEscaping
+}
+
+// TemplateEngine returns the engine the app's HTML templates are written for
+func (ac *AppConfig) TemplateEngine() string {
+	if ac.ViewsConfig.Engine == "" {
+		return views.EngineHandlebars
+	}
+	return ac.ViewsConfig.Engine
+}
+
+// StrictLayoutEnabled reports whether layout render failures should fail the request
+func (ac *AppConfig) StrictLayoutEnabled() bool {
+	return ac.StrictLayout || ac.IsDevelopment()
+}
+
+// RequestTimeoutDuration returns the configured per-request deadline
+func (ac *AppConfig) RequestTimeoutDuration() time.Duration {
+	if ac.Server.RequestTimeout > 0 {
+		return time.Duration(ac.Server.RequestTimeout) * time.Second
+	}
+	if ac.RequestTimeout > 0 {
+		return time.Duration(ac.RequestTimeout) * time.Second
+	}
+	return DefaultRequestTimeout
+}
+
+// HTMXRequestTimeoutDuration returns the deadline for HTMX requests
+func (ac *AppConfig) HTMXRequestTimeoutDuration() time.Duration {
+	if ac.Server.HTMXRequestTimeout > 0 {
+		return time.Duration(ac.Server.HTMXRequestTimeout) * time.Second
+	}
+	return ac.RequestTimeoutDuration()
 }
 
-// GetAppConfig parses the application configuration from the file system
+// GetAppConfig parses the application configuration from the file system, applying
+// the overlay for the environment named by FULCRUM_ENV
 func GetAppConfig(root string) (AppConfig, error) {
+	return GetAppConfigForEnv(root, os.Getenv("FULCRUM_ENV"))
+}
+
+// GetAppConfigForEnv parses the application configuration, merging fulcrum.<env>.yml
+// on top of fulcrum.yml when env is set and the file exists
+func GetAppConfigForEnv(root string, env string) (AppConfig, error) {
+	var appConfig AppConfig
+
 	// Load main config
 	mainConfigPath := filepath.Join(root, DomainConfigFileName)
-	mainConfigFile, err := os.ReadFile(mainConfigPath)
-	if err != nil {
-		return AppConfig{}, fmt.Errorf("failed to read main config file: %w", err)
+	if err := appConfig.loadConfigFile(mainConfigPath); err != nil {
+		return AppConfig{}, fmt.Errorf("failed to load main config file: %w", err)
 	}
 
-	var appConfig AppConfig
-	if err := yaml.Unmarshal(mainConfigFile, &appConfig); err != nil {
-		return AppConfig{}, fmt.Errorf("failed to parse main config file: %w", err)
+	// Merge the environment overlay; keys it sets replace the base values
+	if env != "" {
+		overlayPath := filepath.Join(root, EnvConfigFileName(env))
+		if _, err := os.Stat(overlayPath); err == nil {
+			if err := appConfig.loadConfigFile(overlayPath); err != nil {
+				return AppConfig{}, fmt.Errorf("failed to load %s: %w", EnvConfigFileName(env), err)
+			}
+			log.Printf("🔧 Applied %s overrides", EnvConfigFileName(env))
+		}
+	}
+
+	for _, warning := range appConfig.Warnings {
+		log.Printf("⚠️ %s", warning)
+	}
+
+	if databaseURL := os.Getenv(DatabaseURLEnv); databaseURL != "" {
+		appConfig.DB.URL = databaseURL
 	}
+	db, err := appConfig.DB.WithURL()
+	if err != nil {
+		return AppConfig{}, fmt.Errorf("failed to load database settings: %w", err)
+	}
+	// A relative sqlite file_path belongs to the app, wherever fulcrum runs from
+	if db.FilePath != "" && !filepath.IsAbs(db.FilePath) {
+		db.FilePath = filepath.Join(root, db.FilePath)
+	}
+	for i, replica := range db.Replicas {
+		if db.Replicas[i], err = replica.WithPrimary(db); err != nil {
+			return AppConfig{}, fmt.Errorf("failed to load database replica %d: %w", i+1, err)
+		}
+		if path := db.Replicas[i].FilePath; path != "" && !filepath.IsAbs(path) {
+			db.Replicas[i].FilePath = filepath.Join(root, path)
+		}
+	}
+	appConfig.DB = db
 
 	// Discover and parse domains
 	domains, err := discoverDomains(root)
@@ -122,19 +515,104 @@ func GetAppConfig(root string) (AppConfig, error) {
 		fmt.Printf("Warning: failed to discover redirects: %v\n", err)
 	}
 
+	// Discover per-route options
+	if err := appConfig.DiscoverRouteOptions(); err != nil {
+		return AppConfig{}, err
+	}
+	if err := appConfig.DiscoverHTMXFragments(); err != nil {
+		return AppConfig{}, err
+	}
+
 	// Note: Template preloading will happen later after the renderer is initialized
 
 	return appConfig, nil
 }
 
-// PreloadRouteTemplates loads all route templates at startup
+// loadConfigFile interpolates environment variables in a config file and unmarshals
+// it over the current values
+func (ac *AppConfig) loadConfigFile(path string) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(path)
+	source, unset, err := interpolateEnv(name, source, os.LookupEnv, strictEnv())
+	if err != nil {
+		return err
+	}
+	ac.Warnings = append(ac.Warnings, unset...)
+
+	if err := yaml.Unmarshal(source, ac); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	ac.sources = append(ac.sources, configSource{name: name, data: source})
+	ac.Warnings = append(ac.Warnings, unknownKeyWarnings(name, source)...)
+	return nil
+}
+
+// ValidateMessageRoutes checks the messages routing table against the discovered domains
+func (ac *AppConfig) ValidateMessageRoutes() error {
+	if errors := ac.MessageRouteErrors(); len(errors) > 0 {
+		return fmt.Errorf("message routing errors:\n  - %s", strings.Join(errors, "\n  - "))
+	}
+	return nil
+}
+
+// MessageRouteErrors returns a message per invalid entry in the messages routing table
+func (ac *AppConfig) MessageRouteErrors() []string {
+	var errors []string
+	seen := make(map[string]bool)
+
+	for i, route := range ac.Messages {
+		if route.HTTPType == "" {
+			errors = append(errors, fmt.Sprintf("messages[%d]: http_type is required", i))
+			continue
+		}
+		if seen[route.HTTPType] {
+			errors = append(errors, fmt.Sprintf("messages[%d]: duplicate http_type %q", i, route.HTTPType))
+		}
+		seen[route.HTTPType] = true
+
+		if route.Domain == "" {
+			errors = append(errors, fmt.Sprintf("messages[%d] (%s): domain is required", i, route.HTTPType))
+		} else if _, exists := ac.GetDomain(route.Domain); !exists {
+			errors = append(errors, fmt.Sprintf("messages[%d] (%s): unknown domain %q", i, route.HTTPType, route.Domain))
+		}
+	}
+
+	return errors
+}
+
+// DomainNames returns the names of all discovered domains
+func (ac *AppConfig) DomainNames() []string {
+	names := make([]string, 0, len(ac.Domains))
+	for _, domain := range ac.Domains {
+		names = append(names, domain.Name)
+	}
+	return names
+}
+
+// PreloadRouteTemplates loads all route templates at startup. Every template that
+// fails to load is reported in the returned error; the remaining ones are still loaded.
 func (ac *AppConfig) PreloadRouteTemplates() error {
 	if ac.Views == nil {
 		return fmt.Errorf("template renderer not initialized")
 	}
 
+	if failures := ac.preloadRouteTemplates(); len(failures) > 0 {
+		return fmt.Errorf("template preload errors:\n  - %s", strings.Join(failures, "\n  - "))
+	}
+	return nil
+}
+
+// preloadRouteTemplates loads the route templates and returns a message per failure
+func (ac *AppConfig) preloadRouteTemplates() []string {
 	log.Printf("🔄 Pre-loading route templates...")
 
+	var failures []string
+
 	for domainIndex, domain := range ac.Domains {
 		for routeIndex, route := range domain.Logic.HTTP.Routes {
 			// Create a predictable template name based on the route path
@@ -145,7 +623,13 @@ func (ac *AppConfig) PreloadRouteTemplates() error {
 			// Load the template with the predictable name
 			if err := ac.Views.LoadTemplate(templateName, route.ViewPath); err != nil {
 				log.Printf("⚠️ Failed to preload template %s (%s): %v", templateName, route.ViewPath, err)
-				// Don't fail completely, just log the warning
+				if _, statErr := os.Stat(route.ViewPath); os.IsNotExist(statErr) {
+					failures = append(failures, missingTemplateMessage(route))
+				} else {
+					failures = append(failures,
+						fmt.Sprintf("Failed to load template: %s %s -> %s: %v",
+							route.Method, route.Link, route.ViewPath, err))
+				}
 				continue
 			}
 
@@ -153,13 +637,49 @@ func (ac *AppConfig) PreloadRouteTemplates() error {
 			ac.Domains[domainIndex].Logic.HTTP.Routes[routeIndex].TemplateName = templateName
 
 			log.Printf("✅ Preloaded template: %s -> %s", templateName, route.ViewPath)
+
+			// Fragments are rendered by path like route templates
+			for _, name := range route.HTMXFragments {
+				fragmentPath := route.HTMXFragmentPath(name)
+				fragmentHash := fmt.Sprintf("%x", sha256.Sum256([]byte(fragmentPath)))
+				if err := ac.Views.LoadTemplate(fmt.Sprintf("route_%s", fragmentHash[:16]), fragmentPath); err != nil {
+					failures = append(failures, fmt.Sprintf("Failed to load fragment: %s %s -> %s: %v", route.Method, route.Link, fragmentPath, err))
+				}
+			}
 		}
 	}
 
 	log.Printf("🏁 Route template preloading completed")
+	return failures
+}
+
+// PreloadAndValidateRoutes preloads route templates and validates the routes,
+// combining every problem into one error so all missing templates are reported together
+func (ac *AppConfig) PreloadAndValidateRoutes() error {
+	if ac.Views == nil {
+		return fmt.Errorf("template renderer not initialized")
+	}
+
+	var problems []string
+	seen := make(map[string]bool)
+	for _, problem := range append(ac.preloadRouteTemplates(), ac.RouteValidationErrors()...) {
+		if !seen[problem] {
+			seen[problem] = true
+			problems = append(problems, problem)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("route template errors:\n  - %s", strings.Join(problems, "\n  - "))
+	}
 	return nil
 }
 
+// missingTemplateMessage describes a route whose template file does not exist
+func missingTemplateMessage(route Route) string {
+	return fmt.Sprintf("Missing template: %s %s -> %s", route.Method, route.Link, route.ViewPath)
+}
+
 // DiscoverRedirects scans for redirect.yaml files and applies them to routes
 func (ac *AppConfig) DiscoverRedirects() error {
 	log.Printf("🔍 Starting redirect discovery...")
@@ -210,6 +730,66 @@ func (ac *AppConfig) DiscoverRedirects() error {
 	return nil
 }
 
+// DiscoverRouteOptions applies route.yaml files to the routes whose templates sit next to them
+func (ac *AppConfig) DiscoverRouteOptions() error {
+	for domainIndex, domain := range ac.Domains {
+		for routeIndex, route := range domain.Logic.HTTP.Routes {
+			optionsPath := filepath.Join(filepath.Dir(route.ViewPath), "route.yaml")
+			data, err := os.ReadFile(optionsPath)
+			if os.IsNotExist(err) {
+				continue
+			} else if err != nil {
+				return fmt.Errorf("could not read %s: %w", optionsPath, err)
+			}
+
+			var options RouteOptions
+			if err := yaml.UnmarshalStrict(data, &options); err != nil {
+				return fmt.Errorf("invalid route options in %s: %w", optionsPath, err)
+			}
+
+			ac.Domains[domainIndex].Logic.HTTP.Routes[routeIndex].Transactional = options.Transactional
+			ac.Domains[domainIndex].Logic.HTTP.Routes[routeIndex].Permit = options.Permit
+			ac.Domains[domainIndex].Logic.HTTP.Routes[routeIndex].Cache = options.Cache
+		}
+	}
+	return nil
+}
+
+// DiscoverHTMXFragments applies htmx.yaml files to the HTML routes whose templates sit
+// next to them, checking that each fragment they list exists
+func (ac *AppConfig) DiscoverHTMXFragments() error {
+	for domainIndex, domain := range ac.Domains {
+		for routeIndex, route := range domain.Logic.HTTP.Routes {
+			if route.Format != "html" {
+				continue
+			}
+			optionsPath := filepath.Join(filepath.Dir(route.ViewPath), "htmx.yaml")
+			data, err := os.ReadFile(optionsPath)
+			if os.IsNotExist(err) {
+				continue
+			} else if err != nil {
+				return fmt.Errorf("could not read %s: %w", optionsPath, err)
+			}
+
+			var options HTMXOptions
+			if err := yaml.UnmarshalStrict(data, &options); err != nil {
+				return fmt.Errorf("invalid htmx options in %s: %w", optionsPath, err)
+			}
+			for _, name := range options.Fragments {
+				if !ColumnNamePattern.MatchString(name) {
+					return fmt.Errorf("invalid fragment name %q in %s (letters, digits or '_')", name, optionsPath)
+				}
+				if _, err := os.Stat(route.HTMXFragmentPath(name)); err != nil {
+					return fmt.Errorf("fragment %s listed in %s: %w", name, optionsPath, err)
+				}
+			}
+
+			ac.Domains[domainIndex].Logic.HTTP.Routes[routeIndex].HTMXFragments = options.Fragments
+		}
+	}
+	return nil
+}
+
 // discoverDomains scans the domains directory and builds domain configurations
 func discoverDomains(root string) ([]DomainConfig, error) {
 	domainsDir := filepath.Join(root, "domains")
@@ -303,7 +883,31 @@ func discoverRoutes(root, domainPath, domainName string) ([]Route, error) {
 		return nil
 	})
 
-	return routes, err
+	return withQueryRoutes(routes), err
+}
+
+// withQueryRoutes keeps the named queries of directories with a GET route, which runs
+// them; named SQL files anywhere else are left as partials. Queries named for a key
+// the route's own results are served under, such as data.sql.hbs, are ignored.
+func withQueryRoutes(routes []Route) []Route {
+	pages := make(map[string]bool)
+	for _, route := range routes {
+		if route.Query == "" && route.Method == "GET" {
+			pages[route.Link] = true
+		}
+	}
+
+	kept := routes[:0]
+	for _, route := range routes {
+		if slices.Contains(ReservedQueryNames, route.Query) {
+			log.Printf("⚠️ Ignoring query %s: %s is reserved for the route's own results", route.ViewPath, route.Query)
+			continue
+		}
+		if route.Query == "" || pages[route.Link] {
+			kept = append(kept, route)
+		}
+	}
+	return kept
 }
 
 // isRouteFile determines if a file represents a route handler
@@ -311,9 +915,11 @@ func isRouteFile(path string) bool {
 	// Look for files like: get.html.hbs, post.json.hbs, etc.
 	filename := filepath.Base(path)
 
-	// Pattern: {method}.{format}.hbs or {method}.{format}.handlebars
+	// Pattern: {method}.{format}.hbs or {method}.{format}.handlebars, or {method}.html.tmpl
 	patterns := []string{
 		`^(get|post|put|patch|delete|head|options)\.(html|json|xml|sql|text)\.(hbs|handlebars)$`,
+		`^(get|post|put|patch|delete|head|options)\.html\.tmpl$`, // views.engine: gotemplate
+		`^[a-z][a-z0-9_]*\.sql\.(hbs|handlebars)$`,               // Named queries of a GET route, e.g. stats.sql.hbs
 	}
 
 	for _, pattern := range patterns {
@@ -346,11 +952,21 @@ func parseRouteFromPath(root, domainPath, domainName, filePath string) (Route, e
 	method := strings.ToUpper(parts[0])
 	format := parts[1]
 
+	// Any other name is a query run alongside the GET route, e.g. stats.sql.hbs
+	query := ""
+	if !slices.Contains(httpMethods, method) {
+		query = strings.ToLower(parts[0])
+		method = "GET"
+	}
+
 	// Build the URL path with proper handling
 	urlPath := buildURLPath(domainName, dir)
 
 	// Create a unique identifier for this route that includes format
 	routeID := fmt.Sprintf("%s_%s_%s", method, strings.ReplaceAll(urlPath, "/", "_"), format)
+	if query != "" {
+		routeID += "_" + query
+	}
 
 	// Create the route
 	route := Route{
@@ -360,6 +976,7 @@ func parseRouteFromPath(root, domainPath, domainName, filePath string) (Route, e
 		Path:     routeID, // Use unique ID instead of file path
 		ViewPath: filePath,
 		Format:   format,
+		Query:    query,
 	}
 
 	return route, nil
@@ -386,7 +1003,7 @@ func buildURLPath(domainName, dir string) string {
 			continue
 		}
 
-		// Convert [param] to :param for URL parameters
+		// Convert [param] to :param for URL parameters ([...param] becomes a :...param catch-all)
 		if strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]") {
 			param := strings.Trim(part, "[]")
 			parts = append(parts, ":"+param)
@@ -398,6 +1015,16 @@ func buildURLPath(domainName, dir string) string {
 	return "/" + strings.Join(parts, "/")
 }
 
+// GetDomain returns the domain configuration with the given name
+func (ac *AppConfig) GetDomain(name string) (*DomainConfig, bool) {
+	for i := range ac.Domains {
+		if ac.Domains[i].Name == name {
+			return &ac.Domains[i], true
+		}
+	}
+	return nil, false
+}
+
 // Model helper methods
 func (dc *DomainConfig) GetModel(name string) (Model, bool) {
 	for _, modelDef := range dc.Models {
@@ -408,6 +1035,43 @@ func (dc *DomainConfig) GetModel(name string) (Model, bool) {
 	return nil, false
 }
 
+// HasField reports whether any of the domain's models declares the field; id always exists
+func (dc *DomainConfig) HasField(name string) bool {
+	if name == "id" {
+		return true
+	}
+	for _, modelDef := range dc.Models {
+		for _, model := range modelDef {
+			if _, exists := model.GetField(name); exists {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SortableColumns returns the columns ?sort= accepts: the sort list when given,
+// otherwise id and every model field in name order
+func (dc *DomainConfig) SortableColumns() []string {
+	if len(dc.Sort.Columns) > 0 {
+		return dc.Sort.Columns
+	}
+	columns := []string{"id"}
+	seen := map[string]bool{"id": true}
+	for _, modelDef := range dc.Models {
+		for _, model := range modelDef {
+			for name := range model {
+				if !seen[name] {
+					seen[name] = true
+					columns = append(columns, name)
+				}
+			}
+		}
+	}
+	sort.Strings(columns[1:])
+	return columns
+}
+
 func (m Model) GetField(fieldName string) (Field, bool) {
 	field, exists := m[fieldName]
 	return field, exists
@@ -469,6 +1133,31 @@ func (dc *DomainConfig) GetTemplateDirectories(rootPath string) []string {
 	return dirs
 }
 
+// GetTemplateRoot returns the domain directory, whose templates are named "<domain>/<path>"
+func (dc *DomainConfig) GetTemplateRoot(rootPath string) views.TemplateRoot {
+	return views.TemplateRoot{Prefix: dc.Name, Dir: filepath.Join(rootPath, "domains", dc.Name)}
+}
+
+// GetTemplateRoots returns shared/views as "shared" and every domain directory, so route
+// templates can use partials such as {{> shared/card}} or {{> auth/login/form}}
+func (ac *AppConfig) GetTemplateRoots() []views.TemplateRoot {
+	var roots []views.TemplateRoot
+
+	sharedPath := filepath.Join(ac.Path, "shared", "views")
+	if _, err := os.Stat(sharedPath); err == nil {
+		roots = append(roots, views.TemplateRoot{Prefix: "shared", Dir: sharedPath})
+	}
+
+	for _, domain := range ac.Domains {
+		root := domain.GetTemplateRoot(ac.Path)
+		if _, err := os.Stat(root.Dir); err == nil {
+			roots = append(roots, root)
+		}
+	}
+
+	return roots
+}
+
 // GetAllTemplateDirectories returns all template directories for the app
 func (ac *AppConfig) GetAllTemplateDirectories() []string {
 	var allDirs []string
@@ -527,15 +1216,21 @@ func (ac *AppConfig) PrintYAML() {
 
 // Validation and debugging functions
 func (ac *AppConfig) ValidateRoutes() error {
+	if errors := ac.RouteValidationErrors(); len(errors) > 0 {
+		return fmt.Errorf("route validation errors:\n  - %s", strings.Join(errors, "\n  - "))
+	}
+	return nil
+}
+
+// RouteValidationErrors returns a message per invalid route, including missing templates
+func (ac *AppConfig) RouteValidationErrors() []string {
 	var errors []string
 
 	for _, domain := range ac.Domains {
 		for _, route := range domain.Logic.HTTP.Routes {
 			// Check if template file exists
 			if _, err := os.Stat(route.ViewPath); os.IsNotExist(err) {
-				errors = append(errors,
-					fmt.Sprintf("Missing template: %s %s -> %s",
-						route.Method, route.Link, route.ViewPath))
+				errors = append(errors, missingTemplateMessage(route))
 			}
 
 			// Validate route pattern
@@ -544,15 +1239,17 @@ func (ac *AppConfig) ValidateRoutes() error {
 					fmt.Sprintf("Invalid route pattern (must start with /): %s", route.Link))
 			}
 
-			// Validate HTTP method
-			validMethods := []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
-			valid := false
-			for _, method := range validMethods {
-				if route.Method == method {
-					valid = true
-					break
+			// Catch-all segments ([...name]) are only valid as the final segment
+			segments := strings.Split(strings.Trim(route.Link, "/"), "/")
+			for i, segment := range segments {
+				if strings.HasPrefix(segment, ":...") && i != len(segments)-1 {
+					errors = append(errors,
+						fmt.Sprintf("Invalid route pattern (catch-all must be the last segment): %s", route.Link))
 				}
 			}
+
+			// Validate HTTP method
+			valid := slices.Contains(httpMethods, route.Method)
 			if !valid {
 				errors = append(errors,
 					fmt.Sprintf("Invalid HTTP method: %s", route.Method))
@@ -571,14 +1268,15 @@ func (ac *AppConfig) ValidateRoutes() error {
 				errors = append(errors,
 					fmt.Sprintf("Invalid format: %s", route.Format))
 			}
-		}
-	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("route validation errors:\n  - %s", strings.Join(errors, "\n  - "))
+			if slices.Contains(ReservedQueryNames, route.Query) {
+				errors = append(errors,
+					fmt.Sprintf("Invalid query name (%s is reserved for the route's own results): %s", route.Query, route.ViewPath))
+			}
+		}
 	}
 
-	return nil
+	return errors
 }
 
 // DebugRoutes prints detailed route information for debugging
@@ -0,0 +1,283 @@
+package parser
+
+import (
+	"fulcrum/lib/views"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPreloadAndValidateRoutesReportsAllMissingTemplates(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "get.html.hbs")
+	if err := os.WriteFile(existing, []byte("<p>users</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	appConfig := &AppConfig{
+		Views: views.NewTemplateRenderer(),
+		Domains: []DomainConfig{
+			{
+				Name: "users",
+				Logic: LogicConfig{HTTP: HTTPConfig{Routes: []Route{
+					{Method: "GET", Link: "/users", Format: "html", ViewPath: existing},
+					{Method: "GET", Link: "/users/:users_id", Format: "html", ViewPath: filepath.Join(dir, "show.html.hbs")},
+					{Method: "POST", Link: "/users", Format: "sql", ViewPath: filepath.Join(dir, "post.sql.hbs")},
+				}}},
+			},
+		},
+	}
+
+	err := appConfig.PreloadAndValidateRoutes()
+	if err == nil {
+		t.Fatal("Expected missing templates to be reported")
+	}
+
+	message := err.Error()
+	for _, missing := range []string{"show.html.hbs", "post.sql.hbs"} {
+		if count := strings.Count(message, missing); count != 1 {
+			t.Errorf("Expected %s to be reported once, got %d times in:\n%s", missing, count, message)
+		}
+	}
+
+	routes := appConfig.Domains[0].Logic.HTTP.Routes
+	if routes[0].TemplateName == "" {
+		t.Error("Expected existing template to be preloaded")
+	}
+	if routes[1].TemplateName != "" {
+		t.Error("Expected missing template to have no template name")
+	}
+}
+
+func TestPreloadAndValidateRoutesSucceeds(t *testing.T) {
+	dir := t.TempDir()
+	viewPath := filepath.Join(dir, "get.html.hbs")
+	if err := os.WriteFile(viewPath, []byte("<p>users</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	appConfig := &AppConfig{
+		Views: views.NewTemplateRenderer(),
+		Domains: []DomainConfig{
+			{Name: "users", Logic: LogicConfig{HTTP: HTTPConfig{Routes: []Route{
+				{Method: "GET", Link: "/users", Format: "html", ViewPath: viewPath},
+			}}}},
+		},
+	}
+
+	if err := appConfig.PreloadAndValidateRoutes(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestValidateMessageRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []MessageRoute
+		errors   []string
+	}{
+		{
+			name: "valid routes",
+			messages: []MessageRoute{
+				{HTTPType: "create_user_request", Domain: "users", DomainType: "user_create_request"},
+			},
+		},
+		{
+			name: "unknown domain",
+			messages: []MessageRoute{
+				{HTTPType: "create_user_request", Domain: "usres", DomainType: "user_create_request"},
+			},
+			errors: []string{`unknown domain "usres"`},
+		},
+		{
+			name: "missing fields and duplicates",
+			messages: []MessageRoute{
+				{Domain: "users"},
+				{HTTPType: "create_user_request"},
+				{HTTPType: "create_user_request", Domain: "users"},
+			},
+			errors: []string{"http_type is required", "domain is required", `duplicate http_type "create_user_request"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appConfig := &AppConfig{
+				Domains:  []DomainConfig{{Name: "users"}},
+				Messages: tt.messages,
+			}
+
+			err := appConfig.ValidateMessageRoutes()
+			if len(tt.errors) == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Expected validation error")
+			}
+			for _, expected := range tt.errors {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("Expected %q in:\n%v", expected, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSetupViewsLoadsDomainTemplates(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"shared/views/card.hbs":                  "<div>card</div>",
+		"domains/users/index/get.html.hbs":       "<p>users</p>",
+		"domains/users/views/row.hbs":            "<tr>row</tr>",
+		"domains/orders/:orders_id/get.html.hbs": "<p>order</p>",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	appConfig := &AppConfig{
+		Path:    root,
+		Domains: []DomainConfig{{Name: "users"}, {Name: "orders"}},
+	}
+
+	renderer, err := views.SetupViewsFromConfig(appConfig)
+	if err != nil {
+		t.Fatalf("SetupViewsFromConfig failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "shared/card", expected: "<div>card</div>"},
+		{name: "users/index/get.html", expected: "<p>users</p>"},
+		{name: "users/views/row", expected: "<tr>row</tr>"},
+		{name: "orders/:orders_id/get.html", expected: "<p>order</p>"},
+		// views/ templates keep their unprefixed names
+		{name: "row", expected: "<tr>row</tr>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := renderer.Render(tt.name, nil)
+			if err != nil {
+				t.Fatalf("Expected template %s to be loaded: %v", tt.name, err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSortableColumns(t *testing.T) {
+	models := []ModelDefinition{{"post": Model{"title": Field{Type: "string"}, "body": Field{Type: "text"}}}}
+
+	tests := []struct {
+		name     string
+		domain   DomainConfig
+		expected []string
+	}{
+		{"model fields", DomainConfig{Models: models}, []string{"id", "body", "title"}},
+		{"explicit list", DomainConfig{Models: models, Sort: SortConfig{Columns: []string{"title"}}}, []string{"title"}},
+		{"no models", DomainConfig{}, []string{"id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if columns := tt.domain.SortableColumns(); !reflect.DeepEqual(columns, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, columns)
+			}
+		})
+	}
+}
+
+func TestWithQueryRoutes(t *testing.T) {
+	page := Route{Link: "/posts", Method: "GET", Format: "sql"}
+	query := func(link, name string) Route {
+		return Route{Link: link, Method: "GET", Format: "sql", Query: name, ViewPath: name + ".sql.hbs"}
+	}
+
+	tests := []struct {
+		name     string
+		routes   []Route
+		expected []string
+	}{
+		{name: "query beside a GET route", routes: []Route{page, query("/posts", "stats")}, expected: []string{"", "stats"}},
+		{name: "query without a GET route", routes: []Route{page, query("/posts/new", "values")}, expected: []string{""}},
+		{name: "reserved names", routes: []Route{page, query("/posts", "data"), query("/posts", "success"), query("/posts", "count")}, expected: []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, route := range withQueryRoutes(tt.routes) {
+				names = append(names, route.Query)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected queries %q, got %q", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestDiscoverHTMXFragments(t *testing.T) {
+	tests := []struct {
+		name      string
+		manifest  string
+		files     []string
+		expected  []string
+		wantError bool
+	}{
+		{name: "listed fragments", manifest: "fragments: [row, counter]", files: []string{"row.htmx.hbs", "counter.htmx.hbs"}, expected: []string{"row", "counter"}},
+		{name: "missing fragment", manifest: "fragments: [row, counter]", files: []string{"row.htmx.hbs"}, wantError: true},
+		{name: "path as a name", manifest: "fragments: [../row]", wantError: true},
+		{name: "unknown key", manifest: "fragment: [row]", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range append(tt.files, "get.html.hbs") {
+				if err := os.WriteFile(filepath.Join(dir, file), []byte("<p></p>"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := os.WriteFile(filepath.Join(dir, "htmx.yaml"), []byte(tt.manifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			appConfig := &AppConfig{Domains: []DomainConfig{{
+				Name: "tasks",
+				Logic: LogicConfig{HTTP: HTTPConfig{Routes: []Route{
+					{Method: "GET", Link: "/tasks", Format: "html", ViewPath: filepath.Join(dir, "get.html.hbs")},
+					{Method: "GET", Link: "/tasks", Format: "sql", ViewPath: filepath.Join(dir, "get.sql.hbs")},
+				}}},
+			}}}
+
+			err := appConfig.DiscoverHTMXFragments()
+			if (err != nil) != tt.wantError {
+				t.Fatalf("Expected error %v, got %v", tt.wantError, err)
+			}
+			routes := appConfig.Domains[0].Logic.HTTP.Routes
+			if !tt.wantError && !reflect.DeepEqual(routes[0].HTMXFragments, tt.expected) {
+				t.Errorf("Expected fragments %v, got %v", tt.expected, routes[0].HTMXFragments)
+			}
+			if routes[1].HTMXFragments != nil {
+				t.Errorf("Expected only the HTML route to get fragments, got %v", routes[1].HTMXFragments)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// envReferencePattern matches ${VAR} and ${VAR:-default}
+var envReferencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// EnvConfigFileName returns the overlay file merged on top of fulcrum.yml for an environment
+func EnvConfigFileName(env string) string {
+	return fmt.Sprintf("fulcrum.%s.yml", env)
+}
+
+// StrictEnvEnv names the environment variable that, set to false, leaves references to
+// unset variables in fulcrum.yml as they are instead of failing to load the config
+const StrictEnvEnv = "FULCRUM_STRICT_ENV"
+
+// strictEnv reports whether references to unset variables are errors, which they are
+// unless FULCRUM_STRICT_ENV is false
+func strictEnv() bool {
+	strict, err := strconv.ParseBool(os.Getenv(StrictEnvEnv))
+	return strict || err != nil
+}
+
+// interpolateEnv replaces ${VAR} and ${VAR:-default} references in a YAML source.
+// Like the shell, the default is used when the variable is unset or empty. Every
+// reference to an unset variable without a default is reported in the error, or when
+// strict is false, left as it is and reported in the returned warnings.
+func interpolateEnv(name string, source []byte, lookup func(string) (string, bool), strict bool) ([]byte, []string, error) {
+	lines := strings.Split(string(source), "\n")
+	var missing []string
+
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		lines[i] = envReferencePattern.ReplaceAllStringFunc(line, func(reference string) string {
+			match := envReferencePattern.FindStringSubmatch(reference)
+			variable, hasDefault, fallback := match[1], match[2] != "", match[3]
+
+			value, ok := lookup(variable)
+			if hasDefault && value == "" {
+				return fallback
+			}
+			if !ok {
+				missing = append(missing, fmt.Sprintf("%s:%d (%s): environment variable %s is not set",
+					name, i+1, keyPathAt(lines, i), variable))
+				if !strict {
+					return reference
+				}
+			}
+			return value
+		})
+	}
+
+	if len(missing) > 0 && strict {
+		return nil, nil, fmt.Errorf("config interpolation errors:\n  - %s", strings.Join(missing, "\n  - "))
+	}
+	return []byte(strings.Join(lines, "\n")), missing, nil
+}
+
+// keyPathAt returns the dotted key set on the given line, e.g. db.password
+func keyPathAt(lines []string, index int) string {
+	var keys []string
+	indent := len(lines[index]) + 1
+
+	for i := index; i >= 0; i-- {
+		line := lines[i]
+		trimmed := strings.TrimLeft(line, " ")
+		lineIndent := len(line) - len(trimmed)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || lineIndent >= indent {
+			continue
+		}
+
+		trimmed = strings.TrimPrefix(trimmed, "- ")
+		if key, _, found := strings.Cut(trimmed, ":"); found {
+			keys = append([]string{strings.TrimSpace(key)}, keys...)
+		}
+		indent = lineIndent
+		if indent == 0 {
+			break
+		}
+	}
+
+	return strings.Join(keys, ".")
+}
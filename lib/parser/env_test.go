@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestInterpolateEnv(t *testing.T) {
+	env := map[string]string{"DB_HOST": "db.internal", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+		lenient  bool
+		errors   []string
+		warnings []string
+	}{
+		{name: "plain", source: "host: localhost", expected: "host: localhost"},
+		{name: "set variable", source: "host: ${DB_HOST}", expected: "host: db.internal"},
+		{name: "default ignored when set", source: "host: ${DB_HOST:-localhost}", expected: "host: db.internal"},
+		{name: "default when unset", source: "port: ${DB_PORT:-5432}", expected: "port: 5432"},
+		{name: "default when empty", source: "host: ${EMPTY:-localhost}", expected: "host: localhost"},
+		{name: "empty default", source: "password: ${DB_PASSWORD:-}", expected: "password: "},
+		{name: "set but empty", source: "password: ${EMPTY}", expected: "password: "},
+		{name: "several on one line", source: "url: ${DB_HOST}:${DB_PORT:-5432}", expected: "url: db.internal:5432"},
+		{name: "comments untouched", source: "# ${DB_PASSWORD}\nhost: x", expected: "# ${DB_PASSWORD}\nhost: x"},
+		{
+			name:   "missing variables",
+			source: "db:\n  host: ${DB_HOST}\n  password: ${DB_PASSWORD}\ntracing:\n  otlp_endpoint: ${OTLP}",
+			errors: []string{
+				"fulcrum.yml:3 (db.password): environment variable DB_PASSWORD is not set",
+				"fulcrum.yml:5 (tracing.otlp_endpoint): environment variable OTLP is not set",
+			},
+		},
+		{
+			name:     "missing variable left as is when not strict",
+			source:   "db:\n  host: ${DB_HOST}\n  password: ${DB_PASSWORD}",
+			lenient:  true,
+			expected: "db:\n  host: db.internal\n  password: ${DB_PASSWORD}",
+			warnings: []string{"fulcrum.yml:3 (db.password): environment variable DB_PASSWORD is not set"},
+		},
+		{name: "default used when not strict", source: "port: ${DB_PORT:-5432}", lenient: true, expected: "port: 5432"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, warnings, err := interpolateEnv("fulcrum.yml", []byte(tt.source), lookup, !tt.lenient)
+
+			if len(tt.errors) > 0 {
+				if err == nil {
+					t.Fatalf("Expected an error, got %q", result)
+				}
+				for _, message := range tt.errors {
+					if !strings.Contains(err.Error(), message) {
+						t.Errorf("Expected %q in:\n%v", message, err)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+			if !slices.Equal(warnings, tt.warnings) {
+				t.Errorf("Expected warnings %q, got %q", tt.warnings, warnings)
+			}
+		})
+	}
+}
+
+func TestGetAppConfigForEnvOverlay(t *testing.T) {
+	dir := t.TempDir()
+	base := `db:
+  driver: postgresql
+  host: localhost
+  port: ${TEST_DB_PORT:-5432}
+  database: app_dev
+  password: ${TEST_DB_PASSWORD}
+root: /home
+request_timeout_seconds: 10
+`
+	overlay := `db:
+  host: db.prod.internal
+  database: app
+request_timeout_seconds: 60
+`
+	writeConfig(t, dir, DomainConfigFileName, base)
+	writeConfig(t, dir, EnvConfigFileName("production"), overlay)
+	t.Setenv("TEST_DB_PASSWORD", "s3cret")
+
+	t.Run("base only", func(t *testing.T) {
+		appConfig, err := GetAppConfigForEnv(dir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if appConfig.DB.Host != "localhost" || appConfig.RequestTimeout != 10 {
+			t.Errorf("Expected base values, got host %q and timeout %d", appConfig.DB.Host, appConfig.RequestTimeout)
+		}
+		if appConfig.DB.Port != 5432 || appConfig.DB.Password != "s3cret" {
+			t.Errorf("Expected interpolated values, got port %d and password %q", appConfig.DB.Port, appConfig.DB.Password)
+		}
+	})
+
+	t.Run("overlay wins", func(t *testing.T) {
+		appConfig, err := GetAppConfigForEnv(dir, "production")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if appConfig.DB.Host != "db.prod.internal" || appConfig.DB.Database != "app" || appConfig.RequestTimeout != 60 {
+			t.Errorf("Expected overlay values, got %+v and timeout %d", appConfig.DB, appConfig.RequestTimeout)
+		}
+		if appConfig.DB.Driver != "postgresql" || appConfig.DB.Password != "s3cret" || appConfig.Root != "/home" {
+			t.Errorf("Expected keys missing from the overlay to keep base values, got %+v and root %q", appConfig.DB, appConfig.Root)
+		}
+
+		if message := appConfig.locate(configIssue{"db.host", "is wrong"}); message != "fulcrum.production.yml:2: db.host is wrong" {
+			t.Errorf("Expected overlay location, got %q", message)
+		}
+	})
+
+	t.Run("FULCRUM_ENV selects the overlay", func(t *testing.T) {
+		t.Setenv("FULCRUM_ENV", "production")
+		appConfig, err := GetAppConfig(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if appConfig.DB.Host != "db.prod.internal" {
+			t.Errorf("Expected overlay host, got %q", appConfig.DB.Host)
+		}
+	})
+
+	t.Run("missing overlay file is ignored", func(t *testing.T) {
+		if _, err := GetAppConfigForEnv(dir, "staging"); err != nil {
+			t.Errorf("Expected no error without an overlay, got %v", err)
+		}
+	})
+
+	t.Run("missing variable", func(t *testing.T) {
+		os.Unsetenv("TEST_DB_PASSWORD")
+		_, err := GetAppConfigForEnv(dir, "")
+		if err == nil || !strings.Contains(err.Error(), "fulcrum.yml:6 (db.password): environment variable TEST_DB_PASSWORD is not set") {
+			t.Errorf("Expected a missing variable error, got %v", err)
+		}
+	})
+
+	t.Run("missing variable when not strict", func(t *testing.T) {
+		os.Unsetenv("TEST_DB_PASSWORD")
+		t.Setenv(StrictEnvEnv, "false")
+		appConfig, err := GetAppConfigForEnv(dir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if appConfig.DB.Password != "${TEST_DB_PASSWORD}" {
+			t.Errorf("Expected the reference to be left as is, got %q", appConfig.DB.Password)
+		}
+		if !slices.Contains(appConfig.Warnings, "fulcrum.yml:6 (db.password): environment variable TEST_DB_PASSWORD is not set") {
+			t.Errorf("Expected a warning for the missing variable, got %q", appConfig.Warnings)
+		}
+	})
+}
+
+func writeConfig(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -6,4 +6,7 @@ const (
 	ValidateLength       = "length"
 	ValidateLengthMin    = "min"
 	ValidateLengthMax    = "max"
+	ValidateMaxWidth     = "max_width"
+	ValidateMaxHeight    = "max_height"
+	ValidateMaxSizeKB    = "max_size_kb"
 )
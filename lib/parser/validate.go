@@ -0,0 +1,268 @@
+package parser
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"fulcrum/lib/i18n"
+	"fulcrum/lib/views"
+
+	"gopkg.in/yaml.v2"
+)
+
+// domainNamePattern matches names usable as a single URL path segment
+var domainNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._~-]*$`)
+
+// ColumnNamePattern matches column names that are safe to write into generated SQL
+var ColumnNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// unknownFieldPattern matches the errors yaml.UnmarshalStrict reports for unknown keys
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (\S+) not found in type (\S+)$`)
+
+// configIssue is a problem with the value at a dotted config key such as db.driver
+type configIssue struct {
+	key     string
+	message string
+}
+
+// Validate checks the database settings, reporting every problem at once
+func (db DBConfig) Validate() error {
+	if errors := db.ValidationErrors(); len(errors) > 0 {
+		return fmt.Errorf("database config errors:\n  - %s", strings.Join(errors, "\n  - "))
+	}
+	return nil
+}
+
+// ValidationErrors returns a message per invalid database setting
+func (db DBConfig) ValidationErrors() []string {
+	var errors []string
+	for _, issue := range db.issues() {
+		errors = append(errors, issue.key+": "+issue.message)
+	}
+	return errors
+}
+
+func (db DBConfig) issues() []configIssue {
+	var issues []configIssue
+
+	switch db.Driver {
+	case "":
+		return []configIssue{{"driver", "is required (postgres, mysql or sqlite)"}}
+	case "sqlite":
+		if db.FilePath == "" {
+			issues = append(issues, configIssue{"file_path", "is required for sqlite"})
+		}
+	case "postgres", "postgresql", "mysql":
+		if db.Host == "" {
+			issues = append(issues, configIssue{"host", "is required for " + db.Driver})
+		}
+		if db.Port <= 0 || db.Port > 65535 {
+			issues = append(issues, configIssue{"port", "must be between 1 and 65535 for " + db.Driver})
+		}
+		if db.Database == "" {
+			issues = append(issues, configIssue{"database", "is required for " + db.Driver})
+		}
+	default:
+		return []configIssue{{"driver", fmt.Sprintf("unknown driver %q (use postgres, mysql or sqlite)", db.Driver)}}
+	}
+
+	if db.MaxRetries < 0 {
+		issues = append(issues, configIssue{"max_retries", "must not be negative"})
+	}
+	if db.RetryBackoffMs < 0 {
+		issues = append(issues, configIssue{"retry_backoff_ms", "must not be negative"})
+	}
+
+	for i, replica := range db.Replicas {
+		key := fmt.Sprintf("replicas[%d]", i)
+		if len(replica.Replicas) > 0 {
+			issues = append(issues, configIssue{key + ".replicas", "is only allowed on the primary"})
+		}
+		for _, issue := range replica.issues() {
+			issues = append(issues, configIssue{key + "." + issue.key, issue.message})
+		}
+	}
+
+	return issues
+}
+
+// Validate checks fulcrum.yml against the discovered domains and routes, reporting
+// every problem at once
+func (ac *AppConfig) Validate() error {
+	if errors := ac.ValidationErrors(); len(errors) > 0 {
+		return fmt.Errorf("config errors:\n  - %s", strings.Join(errors, "\n  - "))
+	}
+	return nil
+}
+
+// ValidationErrors returns a message per invalid setting, prefixed with its
+// fulcrum.yml line when known
+func (ac *AppConfig) ValidationErrors() []string {
+	var issues []configIssue
+
+	for _, issue := range ac.DB.issues() {
+		issues = append(issues, configIssue{"db." + issue.key, issue.message})
+	}
+
+	switch ac.Auth.Sessions {
+	case "", "jwt", "database":
+	default:
+		issues = append(issues, configIssue{"auth.sessions", fmt.Sprintf("unknown session store %q (use jwt or database)", ac.Auth.Sessions)})
+	}
+
+	if limit := ac.Server.RateLimit; limit.Requests < 0 || limit.WindowSeconds < 0 {
+		issues = append(issues, configIssue{"server.rate_limit", "requests and window_seconds must not be negative"})
+	}
+	switch ac.Server.RateLimit.By {
+	case "", "ip", "user":
+	default:
+		issues = append(issues, configIssue{"server.rate_limit.by", fmt.Sprintf("unknown rate limit key %q (use ip or user)", ac.Server.RateLimit.By)})
+	}
+	if uploads := ac.Server.Uploads; uploads.MaxSizeMB < 0 || uploads.MaxMemoryMB < 0 {
+		issues = append(issues, configIssue{"server.uploads", "max_size_mb and max_memory_mb must not be negative"})
+	}
+
+	switch ac.ViewsConfig.Engine {
+	case "", views.EngineHandlebars, views.EngineGoTemplate:
+	default:
+		issues = append(issues, configIssue{"views.engine", fmt.Sprintf("unknown template engine %q (use handlebars or gotemplate)", ac.ViewsConfig.Engine)})
+	}
+
+	if locale := ac.I18n.DefaultLocale; locale != "" && !i18n.LocalePattern.MatchString(locale) {
+		issues = append(issues, configIssue{"i18n.default_locale", fmt.Sprintf("%q is not a locale, e.g. en or pt-BR", locale)})
+	}
+
+	switch ac.Tenancy.Resolver {
+	case "", TenantBySubdomain, TenantByHeader, TenantByPath:
+	default:
+		issues = append(issues, configIssue{"tenancy.resolver", fmt.Sprintf("unknown tenant resolver %q (use subdomain, header or path)", ac.Tenancy.Resolver)})
+	}
+
+	if ac.Metrics.Address != "" {
+		if _, _, err := net.SplitHostPort(ac.Metrics.Address); err != nil {
+			issues = append(issues, configIssue{"metrics.address", fmt.Sprintf("%q must be host:port, e.g. 127.0.0.1:9090", ac.Metrics.Address)})
+		}
+	}
+
+	if ac.Root != "" && !ac.hasRoutePattern(ac.Root) {
+		issues = append(issues, configIssue{"root", fmt.Sprintf("%q does not match any route", ac.Root)})
+	}
+
+	var errors []string
+	for _, issue := range issues {
+		errors = append(errors, ac.locate(issue))
+	}
+
+	for _, domain := range ac.Domains {
+		if !domainNamePattern.MatchString(domain.Name) {
+			errors = append(errors, fmt.Sprintf("domains/%s: domain name must be a valid URL segment (letters, digits, '.', '_', '~' or '-')", domain.Name))
+		}
+		errors = append(errors, domain.columnErrors("search", domain.Search.Columns)...)
+		errors = append(errors, domain.columnErrors("sort", domain.Sort.Columns)...)
+
+		// Every template of a route directory shares its route.yaml, so check each list once
+		checked := make(map[string]bool)
+		for _, route := range domain.Logic.HTTP.Routes {
+			if len(route.Permit) == 0 || checked[route.Method+" "+route.Link] {
+				continue
+			}
+			checked[route.Method+" "+route.Link] = true
+			errors = append(errors, domain.columnErrors("permit", route.Permit)...)
+		}
+	}
+
+	return errors
+}
+
+// columnErrors describes the columns of a search or sort list that are not safe column
+// names or, when the domain declares models, not model fields
+func (dc *DomainConfig) columnErrors(list string, columns []string) []string {
+	var errors []string
+	for _, column := range columns {
+		if !ColumnNamePattern.MatchString(column) {
+			errors = append(errors, fmt.Sprintf("domains/%s: %s column %q must be a column name (letters, digits or '_')", dc.Name, list, column))
+		} else if len(dc.Models) > 0 && !dc.HasField(column) {
+			errors = append(errors, fmt.Sprintf("domains/%s: %s column %q is not a field of the domain's models", dc.Name, list, column))
+		}
+	}
+	return errors
+}
+
+// hasRoutePattern reports whether any domain defines a route with the given link
+func (ac *AppConfig) hasRoutePattern(link string) bool {
+	for _, domain := range ac.Domains {
+		for _, route := range domain.Logic.HTTP.Routes {
+			if route.Link == link {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// locate formats an issue with the file and line that set its key, checking the
+// environment overlay before fulcrum.yml
+func (ac *AppConfig) locate(issue configIssue) string {
+	for i := len(ac.sources) - 1; i >= 0; i-- {
+		if line := keyLine(ac.sources[i].data, issue.key); line > 0 {
+			return fmt.Sprintf("%s:%d: %s %s", ac.sources[i].name, line, issue.key, issue.message)
+		}
+	}
+	return fmt.Sprintf("%s: %s %s", DomainConfigFileName, issue.key, issue.message)
+}
+
+// keyLine returns the 1-based line on which a dotted key is set in the YAML
+// source, or 0 when the key is not present
+func keyLine(source []byte, key string) int {
+	parts := strings.Split(key, ".")
+	parentIndent := -1
+
+	for i, line := range strings.Split(string(source), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indent := len(line) - len(trimmed)
+		if indent <= parentIndent {
+			// Left the parent mapping without finding the key
+			return 0
+		}
+		if !strings.HasPrefix(trimmed, parts[0]+":") {
+			continue
+		}
+
+		if len(parts) == 1 {
+			return i + 1
+		}
+		parts = parts[1:]
+		parentIndent = indent
+	}
+
+	return 0
+}
+
+// unknownKeyWarnings strictly unmarshals the config and describes every key that
+// does not map to a setting, usually a typo
+func unknownKeyWarnings(name string, source []byte) []string {
+	var strict AppConfig
+	err := yaml.UnmarshalStrict(source, &strict)
+	typeErr, ok := err.(*yaml.TypeError)
+	if !ok {
+		return nil
+	}
+
+	var warnings []string
+	for _, message := range typeErr.Errors {
+		if match := unknownFieldPattern.FindStringSubmatch(message); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			warnings = append(warnings, fmt.Sprintf("%s:%d: unknown key %q", name, line, match[2]))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", name, message))
+		}
+	}
+	return warnings
+}
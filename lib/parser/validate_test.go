@@ -0,0 +1,208 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDBConfigValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   DBConfig
+		expected []string
+	}{
+		{
+			name:   "valid postgres",
+			config: DBConfig{Driver: "postgresql", Host: "localhost", Port: 5432, Database: "app"},
+		},
+		{
+			name:   "valid sqlite",
+			config: DBConfig{Driver: "sqlite", FilePath: "app.db"},
+		},
+		{
+			name:     "missing driver",
+			config:   DBConfig{},
+			expected: []string{"driver: is required (postgres, mysql or sqlite)"},
+		},
+		{
+			name:     "unknown driver",
+			config:   DBConfig{Driver: "postgre", Host: "localhost", Port: 5432, Database: "app"},
+			expected: []string{`driver: unknown driver "postgre" (use postgres, mysql or sqlite)`},
+		},
+		{
+			name:   "network driver missing everything",
+			config: DBConfig{Driver: "mysql"},
+			expected: []string{
+				"host: is required for mysql",
+				"port: must be between 1 and 65535 for mysql",
+				"database: is required for mysql",
+			},
+		},
+		{
+			name:     "sqlite without file",
+			config:   DBConfig{Driver: "sqlite"},
+			expected: []string{"file_path: is required for sqlite"},
+		},
+		{
+			name:     "negative retries",
+			config:   DBConfig{Driver: "sqlite", FilePath: "app.db", MaxRetries: -1, RetryBackoffMs: -5},
+			expected: []string{"max_retries: must not be negative", "retry_backoff_ms: must not be negative"},
+		},
+		{
+			name: "invalid replicas",
+			config: DBConfig{Driver: "sqlite", FilePath: "app.db", Replicas: []DBConfig{
+				{Driver: "postgres", Port: 5432, Database: "app"},
+				{Driver: "sqlite", FilePath: "replica.db", Replicas: []DBConfig{{Driver: "sqlite", FilePath: "nested.db"}}},
+			}},
+			expected: []string{"replicas[0].host: is required for postgres", "replicas[1].replicas: is only allowed on the primary"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := tt.config.ValidationErrors()
+			if !reflect.DeepEqual(errors, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, errors)
+			}
+			if (tt.config.Validate() == nil) != (len(tt.expected) == 0) {
+				t.Errorf("Expected Validate to agree with ValidationErrors")
+			}
+		})
+	}
+}
+
+func TestGetAppConfigValidation(t *testing.T) {
+	dir := t.TempDir()
+	config := `db:
+  driver: postgre
+  host: localhost
+  port: 5432
+
+root: /users/missing
+request_timeout: 5
+metrics:
+  enabled: true
+  address: "9090"
+auth:
+  sessions: redis
+i18n:
+  default_locale: english_us
+server:
+  rate_limit:
+    by: session
+  uploads:
+    max_size_mb: -1
+tenancy:
+  resolver: cookie
+views:
+  engine: jinja
+`
+	if err := os.WriteFile(filepath.Join(dir, DomainConfigFileName), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	appConfig, err := GetAppConfig(dir)
+	if err != nil {
+		t.Fatalf("Expected config to load, got %v", err)
+	}
+	appConfig.Domains = []DomainConfig{
+		{
+			Name:   "users",
+			Models: []ModelDefinition{{"user": Model{"email": Field{Type: "text"}}}},
+			Search: SearchConfig{Columns: []string{"email"}},
+			Logic:  LogicConfig{HTTP: HTTPConfig{Routes: []Route{{Method: "GET", Link: "/users"}}}},
+		},
+		{Name: "bad name"},
+		{
+			Name:   "posts",
+			Models: []ModelDefinition{{"post": Model{"title": Field{Type: "text"}}}},
+			Search: SearchConfig{Columns: []string{"title) OR 1=1 --", "body"}},
+			Sort:   SortConfig{Columns: []string{"title", "created_at"}},
+			Logic: LogicConfig{HTTP: HTTPConfig{Routes: []Route{
+				{Method: "POST", Link: "/posts/create", Format: "html", Permit: []string{"title", "is_admin"}},
+				{Method: "POST", Link: "/posts/create", Format: "sql", Permit: []string{"title", "is_admin"}},
+			}}},
+		},
+	}
+
+	expectedWarnings := []string{`fulcrum.yml:7: unknown key "request_timeout"`}
+	if !reflect.DeepEqual(appConfig.Warnings, expectedWarnings) {
+		t.Errorf("Expected warnings %q, got %q", expectedWarnings, appConfig.Warnings)
+	}
+
+	expected := []string{
+		`fulcrum.yml:2: db.driver unknown driver "postgre" (use postgres, mysql or sqlite)`,
+		`fulcrum.yml:6: root "/users/missing" does not match any route`,
+		`fulcrum.yml:10: metrics.address "9090" must be host:port`,
+		`fulcrum.yml:12: auth.sessions unknown session store "redis" (use jwt or database)`,
+		`fulcrum.yml:14: i18n.default_locale "english_us" is not a locale, e.g. en or pt-BR`,
+		`fulcrum.yml:17: server.rate_limit.by unknown rate limit key "session" (use ip or user)`,
+		`fulcrum.yml:18: server.uploads max_size_mb and max_memory_mb must not be negative`,
+		`fulcrum.yml:21: tenancy.resolver unknown tenant resolver "cookie" (use subdomain, header or path)`,
+		`fulcrum.yml:23: views.engine unknown template engine "jinja" (use handlebars or gotemplate)`,
+		"domains/bad name: domain name must be a valid URL segment",
+		`domains/posts: search column "title) OR 1=1 --" must be a column name`,
+		`domains/posts: search column "body" is not a field of the domain's models`,
+		`domains/posts: sort column "created_at" is not a field of the domain's models`,
+		`domains/posts: permit column "is_admin" is not a field of the domain's models`,
+	}
+	err = appConfig.Validate()
+	if err == nil {
+		t.Fatal("Expected validation errors")
+	}
+	if count := strings.Count(err.Error(), `permit column "is_admin"`); count != 1 {
+		t.Errorf("Expected the shared permit list to be reported once, got %d times", count)
+	}
+	for _, message := range expected {
+		if !strings.Contains(err.Error(), message) {
+			t.Errorf("Expected %q in:\n%v", message, err)
+		}
+	}
+
+	appConfig.Root = "/users"
+	appConfig.DB = DBConfig{Driver: "sqlite", FilePath: "app.db"}
+	appConfig.Domains = appConfig.Domains[:1]
+	appConfig.Metrics.Address = "127.0.0.1:9090"
+	appConfig.Auth.Sessions = "database"
+	appConfig.I18n.DefaultLocale = "pt-BR"
+	appConfig.Server.RateLimit = RateLimitConfig{Requests: 100, By: "user"}
+	appConfig.Server.Uploads = UploadsConfig{MaxSizeMB: 50}
+	appConfig.Tenancy.Resolver = TenantBySubdomain
+	appConfig.ViewsConfig.Engine = "gotemplate"
+	if err := appConfig.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+}
+
+func TestKeyLine(t *testing.T) {
+	source := []byte(`# comment
+db:
+  host: localhost
+  driver: mysql
+metrics:
+  driver: unused
+root: /
+`)
+
+	tests := []struct {
+		key      string
+		expected int
+	}{
+		{"db", 2},
+		{"db.driver", 4},
+		{"root", 7},
+		{"db.port", 0},
+		{"tracing", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if line := keyLine(source, tt.key); line != tt.expected {
+				t.Errorf("Expected line %d, got %d", tt.expected, line)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+// Package fulcrumtest serves a fulcrum app in-process for tests. NewTestApp loads a
+// project, migrates a fresh in-memory SQLite database and serves the app's routes
+// from an httptest.Server, without postgres, the gRPC server or the node handlers.
+package fulcrumtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fulcrum/lib/auth"
+	"fulcrum/lib/database"
+	"fulcrum/lib/database/migration"
+	"fulcrum/lib/database/seeder"
+	"fulcrum/lib/framework"
+	"fulcrum/lib/i18n"
+	lang_adapters "fulcrum/lib/lang/adapters"
+	parser "fulcrum/lib/parser"
+	"fulcrum/lib/views"
+)
+
+// DefaultEnv is the environment whose fulcrum.<env>.yml NewTestApp applies unless
+// WithEnv names another
+const DefaultEnv = "test"
+
+// Option changes how NewTestApp sets up an app
+type Option func(*options)
+
+type options struct {
+	env   string
+	seeds bool
+}
+
+// WithEnv applies fulcrum.<env>.yml over fulcrum.yml instead of fulcrum.test.yml
+func WithEnv(env string) Option {
+	return func(o *options) { o.env = env }
+}
+
+// WithoutSeeds leaves the database empty after migrating instead of loading the
+// project's seeds
+func WithoutSeeds() Option {
+	return func(o *options) { o.seeds = false }
+}
+
+// App is a fulcrum app served for one test. The session store and translations are
+// process-wide, so tests using an App must not run in parallel.
+type App struct {
+	Server *httptest.Server
+	Config *parser.AppConfig
+	DB     *database.DatabaseExecutor
+
+	t      testing.TB
+	client *http.Client
+	cookie *http.Cookie
+}
+
+// NewTestApp serves the project at projectPath. Whatever database it configures, the
+// app runs on its own in-memory SQLite database with the project's migrations applied
+// and, unless WithoutSeeds is given, its seeds loaded. Handlers only run when faked
+// with RegisterFakeHandler. Everything is torn down when the test ends.
+func NewTestApp(t testing.TB, projectPath string, opts ...Option) *App {
+	t.Helper()
+	settings := options{env: DefaultEnv, seeds: true}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	root, err := filepath.Abs(projectPath)
+	if err != nil {
+		t.Fatalf("fulcrumtest: failed to resolve %s: %v", projectPath, err)
+	}
+	appConfig, err := parser.GetAppConfigForEnv(root, settings.env)
+	if err != nil {
+		t.Fatalf("fulcrumtest: %v", err)
+	}
+	appConfig.DB = parser.DBConfig{Driver: "sqlite", FilePath: ":memory:"}
+
+	ctx := context.Background()
+	dbConfig, err := database.FromParserConfig(appConfig.DB)
+	if err != nil {
+		t.Fatalf("fulcrumtest: %v", err)
+	}
+	manager, err := database.NewManager(dbConfig)
+	if err != nil {
+		t.Fatalf("fulcrumtest: %v", err)
+	}
+	// SQLite keeps one connection open, which holds the in-memory database
+	if err := manager.Connect(ctx); err != nil {
+		t.Fatalf("fulcrumtest: %v", err)
+	}
+	t.Cleanup(func() { manager.Close() })
+	db := manager.GetDatabase()
+
+	runner := migration.NewRunner(db, root)
+	if err := runner.Initialize(ctx); err != nil {
+		t.Fatalf("fulcrumtest: failed to initialize migrations: %v", err)
+	}
+	if err := runner.MigrateUp(ctx); err != nil {
+		t.Fatalf("fulcrumtest: failed to run migrations: %v", err)
+	}
+	if settings.seeds {
+		seeds, err := seeder.LoadAllSeeds(root)
+		if err != nil {
+			t.Fatalf("fulcrumtest: failed to load seeds: %v", err)
+		}
+		if len(seeds) > 0 {
+			if err := seeder.NewSeeder(db, root).SeedAll(ctx); err != nil {
+				t.Fatalf("fulcrumtest: failed to run seeds: %v", err)
+			}
+		}
+	}
+
+	executor := database.NewDatabaseExecutor(db)
+	sessions, err := auth.NewSessionStore(appConfig.Auth.Sessions, executor)
+	if err != nil {
+		t.Fatalf("fulcrumtest: %v", err)
+	}
+	auth.SetSessionStore(sessions)
+
+	catalog, err := framework.LoadTranslations(&appConfig)
+	if err != nil {
+		t.Fatalf("fulcrumtest: %v", err)
+	}
+	i18n.SetCatalog(catalog)
+
+	renderer, err := views.SetupViewsFromConfig(&appConfig)
+	if err != nil {
+		t.Fatalf("fulcrumtest: failed to set up views: %v", err)
+	}
+	appConfig.Views = renderer
+	if err := appConfig.PreloadAndValidateRoutes(); err != nil {
+		t.Fatalf("fulcrumtest: route templates could not be loaded:\n%v", err)
+	}
+
+	// The process manager never starts the handler service, so only fakes run
+	frameworkServer := &lang_adapters.FrameworkServer{
+		Db:              db,
+		DbExecutor:      executor,
+		DomainStreams:   make(map[string]lang_adapters.FrameworkService_DomainCommunicationServer),
+		PendingRequests: make(map[string]*lang_adapters.PendingRequest),
+		ProcessManager:  lang_adapters.NewProcessManager(root, false),
+	}
+	mux := framework.CreateRouteDispatcher(&appConfig, frameworkServer)
+	if !appConfig.Auth.Disabled {
+		auth.AddLoginRoute(mux, frameworkServer)
+	}
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := server.Client()
+	// Redirects are returned so tests can assert on them
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &App{Server: server, Config: &appConfig, DB: executor, t: t, client: client}
+}
+
+// RegisterFakeHandler handles a domain action with fn for the rest of the test, in
+// place of the project's JavaScript handler. Actions are named as for
+// framework.RegisterHandler, e.g. "index" or "{note_id}.edit".
+func (a *App) RegisterFakeHandler(domain, action string, fn framework.HandlerFunc) {
+	framework.RegisterHandler(domain, action, fn)
+	a.t.Cleanup(func() { framework.UnregisterHandler(domain, action) })
+}
+
+// SignIn starts a session for the seeded user with the given email in the users table;
+// later requests carry its auth cookie, which is also returned
+func (a *App) SignIn(email string) *http.Cookie {
+	a.t.Helper()
+	rows := a.Query("SELECT id, email FROM users WHERE email = :email", map[string]any{"email": email})
+	if len(rows) == 0 {
+		a.t.Fatalf("fulcrumtest: no user with email %q; seed one to sign in", email)
+	}
+	id, _ := rows[0]["id"].(float64)
+
+	cookie, err := auth.StartSession(context.Background(), auth.User{Username: email, Id: id})
+	if err != nil {
+		a.t.Fatalf("fulcrumtest: failed to start a session: %v", err)
+	}
+	a.cookie = cookie
+	return cookie
+}
+
+// SignOut makes later requests anonymous again
+func (a *App) SignOut() {
+	a.cookie = nil
+}
+
+// Query runs SQL against the app's database, binding :name parameters, and returns the
+// rows it selects
+func (a *App) Query(query string, params map[string]any) []map[string]any {
+	a.t.Helper()
+	result, err := a.DB.ExecuteSQL(context.Background(), query, params, nil)
+	if err != nil {
+		a.t.Fatalf("fulcrumtest: query failed: %v", err)
+	}
+	var envelope Envelope
+	if err := json.Unmarshal(result, &envelope); err != nil {
+		a.t.Fatalf("fulcrumtest: invalid query result: %v", err)
+	}
+	if !envelope.Success {
+		a.t.Fatalf("fulcrumtest: query failed: %s", envelope.Error.Message)
+	}
+	return envelope.Rows()
+}
+
+// Get requests path as a browser would
+func (a *App) Get(path string) *Response {
+	a.t.Helper()
+	return a.Do(a.NewRequest(http.MethodGet, path, nil))
+}
+
+// GetJSON requests path with Accept: application/json
+func (a *App) GetJSON(path string) *Response {
+	a.t.Helper()
+	r := a.NewRequest(http.MethodGet, path, nil)
+	r.Header.Set("Accept", "application/json")
+	return a.Do(r)
+}
+
+// PostForm posts form values to path
+func (a *App) PostForm(path string, values url.Values) *Response {
+	a.t.Helper()
+	r := a.NewRequest(http.MethodPost, path, strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return a.Do(r)
+}
+
+// PostJSON posts body encoded as JSON to path and asks for a JSON response
+func (a *App) PostJSON(path string, body any) *Response {
+	a.t.Helper()
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		a.t.Fatalf("fulcrumtest: failed to encode body: %v", err)
+	}
+	r := a.NewRequest(http.MethodPost, path, bytes.NewReader(encoded))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept", "application/json")
+	return a.Do(r)
+}
+
+// NewRequest builds a request for path on the app's server
+func (a *App) NewRequest(method, path string, body io.Reader) *http.Request {
+	a.t.Helper()
+	r, err := http.NewRequest(method, a.Server.URL+path, body)
+	if err != nil {
+		a.t.Fatalf("fulcrumtest: %v", err)
+	}
+	return r
+}
+
+// Do sends r, with the signed-in user's cookie, and reads the whole response
+func (a *App) Do(r *http.Request) *Response {
+	a.t.Helper()
+	if a.cookie != nil {
+		r.AddCookie(a.cookie)
+	}
+	resp, err := a.client.Do(r)
+	if err != nil {
+		a.t.Fatalf("fulcrumtest: %s %s failed: %v", r.Method, r.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		a.t.Fatalf("fulcrumtest: failed to read the response to %s %s: %v", r.Method, r.URL.Path, err)
+	}
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       string(body),
+		t:          a.t,
+		request:    fmt.Sprintf("%s %s", r.Method, r.URL.RequestURI()),
+	}
+}
@@ -0,0 +1,49 @@
+package fulcrumtest
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestCreateAndIndexRoundTrip(t *testing.T) {
+	app := NewTestApp(t, "testdata/notes_app")
+
+	// Domain routes need a signed-in user
+	app.Get("/notes").AssertRedirect("/auth/login")
+	app.SignIn("ada@example.com")
+
+	app.Get("/notes/new").
+		AssertStatus(http.StatusOK).
+		AssertSelector(`form[action="/notes/create"] input[name="title"]`, 1)
+
+	app.PostForm("/notes/create", url.Values{"title": {"Buy milk"}}).AssertRedirect("/notes")
+
+	envelope := app.GetJSON("/notes").AssertStatus(http.StatusOK).AssertSuccess()
+	rows := envelope.Rows()
+	if len(rows) != 1 || rows[0]["title"] != "Buy milk" {
+		t.Fatalf("Expected the created note, got %#v", envelope.Data)
+	}
+
+	// The index handler is faked, so it sees the rows the SQL selected
+	var handled []map[string]any
+	app.RegisterFakeHandler("notes", "index", func(ctx context.Context, sqlData any, requestData map[string]any) (any, error) {
+		handled, _ = sqlData.([]map[string]any)
+		return sqlData, nil
+	})
+	app.Get("/notes").
+		AssertStatus(http.StatusOK).
+		AssertText("h1", "Notes").
+		AssertSelector(`a.new-note[href="/notes/new"]`, 1)
+	if len(handled) != 1 || handled[0]["title"] != "Buy milk" {
+		t.Errorf("Expected the fake handler to receive the note, got %#v", handled)
+	}
+}
+
+func TestWithoutSeeds(t *testing.T) {
+	app := NewTestApp(t, "testdata/notes_app", WithoutSeeds())
+	if rows := app.Query("SELECT id FROM users", nil); len(rows) != 0 {
+		t.Fatalf("Expected no seeded users, got %v", rows)
+	}
+}
@@ -0,0 +1,157 @@
+package fulcrumtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+// Response is a response read in full, with assertions that fail the test
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+
+	t       testing.TB
+	request string // e.g. "GET /notes", for failure messages
+}
+
+// Envelope is the {success, data, count, error} body of json routes
+type Envelope struct {
+	Success bool          `json:"success"`
+	Data    any           `json:"data"`
+	Count   int           `json:"count"`
+	Error   EnvelopeError `json:"error"`
+}
+
+// EnvelopeError is the error of a failed envelope: {code, message} from json routes, or
+// just a message from the database executor
+type EnvelopeError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// UnmarshalJSON reads either form of the error
+func (e *EnvelopeError) UnmarshalJSON(data []byte) error {
+	var message string
+	if err := json.Unmarshal(data, &message); err == nil {
+		e.Message = message
+		return nil
+	}
+	type fields EnvelopeError
+	return json.Unmarshal(data, (*fields)(e))
+}
+
+// Rows returns Data as rows, or nil when it is not a list of objects
+func (e Envelope) Rows() []map[string]any {
+	list, _ := e.Data.([]any)
+	rows := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		row, ok := item.(map[string]any)
+		if !ok {
+			return nil
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// AssertStatus fails the test unless the response has the given status
+func (r *Response) AssertStatus(status int) *Response {
+	r.t.Helper()
+	if r.StatusCode != status {
+		r.t.Fatalf("%s: expected status %d, got %d:\n%s", r.request, status, r.StatusCode, r.Body)
+	}
+	return r
+}
+
+// AssertRedirect fails the test unless the response redirects to location
+func (r *Response) AssertRedirect(location string) *Response {
+	r.t.Helper()
+	if r.StatusCode < 300 || r.StatusCode > 399 {
+		r.t.Fatalf("%s: expected a redirect to %s, got status %d:\n%s", r.request, location, r.StatusCode, r.Body)
+	}
+	if got := r.Header.Get("Location"); got != location {
+		r.t.Fatalf("%s: expected a redirect to %s, got %s", r.request, location, got)
+	}
+	return r
+}
+
+// Find returns the elements of an HTML body that match a CSS selector
+func (r *Response) Find(selector string) []*html.Node {
+	r.t.Helper()
+	compiled, err := cascadia.Compile(selector)
+	if err != nil {
+		r.t.Fatalf("invalid selector %q: %v", selector, err)
+	}
+	doc, err := html.Parse(strings.NewReader(r.Body))
+	if err != nil {
+		r.t.Fatalf("%s: invalid HTML: %v", r.request, err)
+	}
+	return cascadia.QueryAll(doc, compiled)
+}
+
+// AssertSelector fails the test unless count elements match selector
+func (r *Response) AssertSelector(selector string, count int) *Response {
+	r.t.Helper()
+	if found := len(r.Find(selector)); found != count {
+		r.t.Fatalf("%s: expected %d elements matching %q, found %d in:\n%s", r.request, count, selector, found, r.Body)
+	}
+	return r
+}
+
+// AssertText fails the test unless the first element matching selector has the given
+// text, ignoring surrounding and repeated whitespace
+func (r *Response) AssertText(selector, text string) *Response {
+	r.t.Helper()
+	nodes := r.Find(selector)
+	if len(nodes) == 0 {
+		r.t.Fatalf("%s: no element matches %q in:\n%s", r.request, selector, r.Body)
+	}
+	if got := nodeText(nodes[0]); got != text {
+		r.t.Fatalf("%s: expected %q to have text %q, got %q", r.request, selector, text, got)
+	}
+	return r
+}
+
+// Envelope decodes the body of a json route
+func (r *Response) Envelope() Envelope {
+	r.t.Helper()
+	var envelope Envelope
+	if err := json.Unmarshal([]byte(r.Body), &envelope); err != nil {
+		r.t.Fatalf("%s: expected a JSON envelope, got %v:\n%s", r.request, err, r.Body)
+	}
+	return envelope
+}
+
+// AssertSuccess fails the test unless the body is a successful JSON envelope, which it
+// returns
+func (r *Response) AssertSuccess() Envelope {
+	r.t.Helper()
+	envelope := r.Envelope()
+	if !envelope.Success {
+		r.t.Fatalf("%s: expected success, got error %q", r.request, envelope.Error.Message)
+	}
+	return envelope
+}
+
+// nodeText joins the text inside n with single spaces
+func nodeText(n *html.Node) string {
+	var text strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			text.WriteString(n.Data)
+			text.WriteString(" ")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(text.String()), " ")
+}
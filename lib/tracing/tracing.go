@@ -0,0 +1,177 @@
+// Package tracing wraps OpenTelemetry for the request path. Until Init or Use turns
+// tracing on, Start and HandlerFunc hand back the caller's context and a no-op span
+// without allocating, so instrumented code costs nothing when tracing is disabled.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"fulcrum/lib/middleware"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// MaxQueryLength caps the SQL recorded on spans
+const MaxQueryLength = 1024
+
+// Config selects the OTLP collector and sampling for Init
+type Config struct {
+	Endpoint    string  // host:port of an OTLP gRPC collector
+	Insecure    bool    // Connect without TLS
+	SampleRate  float64 // Fraction of new traces to record, 0 to 1
+	ServiceName string
+}
+
+var (
+	enabled    atomic.Bool
+	tracer     atomic.Pointer[trace.Tracer]
+	propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+	// noopSpan is returned while tracing is disabled; it is a value type so returning it never allocates
+	noopSpan = trace.SpanFromContext(context.Background())
+)
+
+// Init exports spans to the configured OTLP collector and returns a shutdown func that flushes them
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "fulcrum"
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRate))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	Use(provider)
+
+	return func(ctx context.Context) error {
+		Disable()
+		return provider.Shutdown(ctx)
+	}, nil
+}
+
+// Use records spans with the given provider, e.g. one backed by an in-memory exporter in tests
+func Use(provider trace.TracerProvider) {
+	t := provider.Tracer("fulcrum")
+	tracer.Store(&t)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+	enabled.Store(true)
+}
+
+// Disable stops recording spans
+func Disable() {
+	enabled.Store(false)
+}
+
+// Enabled reports whether spans are being recorded
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Start opens a child of the span in ctx, or returns ctx and a no-op span when disabled
+func Start(ctx context.Context, name string) (context.Context, trace.Span) {
+	if !enabled.Load() {
+		return ctx, noopSpan
+	}
+	return (*tracer.Load()).Start(ctx, name)
+}
+
+// SetString sets a string attribute, skipping the work when the span is not recording
+func SetString(span trace.Span, key, value string) {
+	if span.IsRecording() {
+		span.SetAttributes(attribute.String(key, value))
+	}
+}
+
+// RecordError marks the span as failed
+func RecordError(span trace.Span, err error) {
+	if err == nil || !span.IsRecording() {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Truncate shortens a value to at most limit bytes for use as a span attribute, without
+// splitting a multi-byte character
+func Truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	for limit > 0 && !utf8.RuneStart(value[limit]) {
+		limit--
+	}
+	return value[:limit] + "…"
+}
+
+// InjectGRPC copies the trace context in ctx into outgoing gRPC metadata
+func InjectGRPC(ctx context.Context) context.Context {
+	if !enabled.Load() {
+		return ctx
+	}
+
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	for key, value := range carrier {
+		ctx = metadata.AppendToOutgoingContext(ctx, key, value)
+	}
+	return ctx
+}
+
+// HandlerFunc opens the root span for a request under its route pattern, continuing
+// any traceparent sent by the caller
+func HandlerFunc(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !enabled.Load() {
+			next(w, r)
+			return
+		}
+
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := (*tracer.Load()).Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		recorder := &middleware.StatusRecorder{ResponseWriter: w}
+		next(recorder, r.WithContext(ctx))
+
+		status := recorder.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
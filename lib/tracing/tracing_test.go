@@ -0,0 +1,30 @@
+package tracing
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		limit    int
+		expected string
+	}{
+		{name: "short", value: "SELECT 1", limit: 10, expected: "SELECT 1"},
+		{name: "ascii", value: "SELECT * FROM posts", limit: 6, expected: "SELECT…"},
+		{name: "inside a character", value: "name = 'café'", limit: 12, expected: "name = 'caf…"},
+		{name: "at a character", value: "name = 'café'", limit: 13, expected: "name = 'café…"},
+		{name: "first character", value: "éa", limit: 1, expected: "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.value, tt.limit)
+			if got != tt.expected || !utf8.ValidString(got) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
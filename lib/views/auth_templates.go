@@ -0,0 +1,32 @@
+package views
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed auth
+var authFiles embed.FS
+
+// AuthFiles returns the default auth domain (templates, SQL and migrations) rooted at
+// the domain directory, e.g. "login/get.html.hbs"
+func AuthFiles() fs.FS {
+	files, err := fs.Sub(authFiles, "auth")
+	if err != nil {
+		panic(err) // The embedded directory always exists
+	}
+	return files
+}
+
+// AuthPage is a default auth page template and the variables it is rendered with
+type AuthPage struct {
+	Template  string
+	Variables []string
+}
+
+// AuthPages lists the auth page templates projects can override in domains/auth
+var AuthPages = []AuthPage{
+	{Template: "login/get.html.hbs", Variables: []string{"error", "success"}},
+	{Template: "register/get.html.hbs", Variables: []string{"error", "success"}},
+	{Template: "dashboard/get.html.hbs", Variables: []string{"username"}},
+}
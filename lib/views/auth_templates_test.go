@@ -0,0 +1,46 @@
+package views
+
+import (
+	"io/fs"
+	"regexp"
+	"strings"
+	"testing"
+
+	"fulcrum/lib/i18n"
+)
+
+func TestAuthTemplatesAreTranslated(t *testing.T) {
+	catalog := i18n.NewCatalog(i18n.DefaultLocale)
+	if err := catalog.LoadFS(AuthFiles(), i18n.LocaleDir); err != nil {
+		t.Fatal(err)
+	}
+
+	keyPattern := regexp.MustCompile(`\{\{t "([^"]+)"`)
+	keys := 0
+	err := fs.WalkDir(AuthFiles(), ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !strings.HasSuffix(path, ".html.hbs") {
+			return err
+		}
+		content, err := fs.ReadFile(AuthFiles(), path)
+		if err != nil {
+			return err
+		}
+		for _, match := range keyPattern.FindAllStringSubmatch(string(content), -1) {
+			keys++
+			if got := catalog.Translate("en", match[1], nil); got == match[1] {
+				t.Errorf("Expected %s used by %s to be in locales/en.yml", match[1], path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys == 0 {
+		t.Fatal("Expected the auth templates to use the t helper")
+	}
+
+	if got := catalog.Translate("en", "auth.dashboard.greeting", map[string]any{"name": "ada"}); got != "Hello, ada! You're successfully logged in." {
+		t.Errorf("Expected the dashboard greeting to name the user, got %q", got)
+	}
+}
@@ -0,0 +1,110 @@
+package views
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Raymond escapes {{ }} output but writes {{{ }}} and {{& }} output as is, so a raw
+// output of user input lets it inject HTML into the page. Most of what a template sees
+// comes from the user: everything under vm holds request data, database rows, the
+// search term and the tenant, and an each block's fields are rows too. Only the keys
+// below are trusted raw; any other path in a raw output is treated as user input.
+var trustedRawKeys = map[string]bool{
+	"body":          true, // The rendered page, injected into the layout
+	"additionalCSS": true, // Markup the project's layout data sets, never request data
+	"additionalJS":  true,
+}
+
+// escapingHelpers escape their own output, so it is safe in a raw output whatever they
+// are given: json escapes <, > and & for use inside a <script>
+var escapingHelpers = map[string]bool{
+	"json": true,
+}
+
+// RawOutput is a {{{ }}} or {{& }} expression that writes user input unescaped
+type RawOutput struct {
+	Expression string `json:"expression"`
+	Line       int    `json:"line"`
+}
+
+// rawOutputPattern finds raw outputs, capturing the expression inside
+var rawOutputPattern = regexp.MustCompile(`\{\{\{~?\s*(.*?)\s*~?\}\}\}|\{\{~?&\s*(.*?)\s*~?\}\}`)
+
+// expressionTokenPattern splits an expression into its helper, arguments and hash pairs,
+// keeping quoted strings whole
+var expressionTokenPattern = regexp.MustCompile(`"[^"]*"|'[^']*'|[^\s()]+`)
+
+// UnsafeRawOutputs returns the raw outputs in an HTML template's source that may write
+// user input unescaped. A raw output is safe when it names a trusted key, calls an
+// escaping helper, or calls a helper with literal arguments only.
+func UnsafeRawOutputs(source string) []RawOutput {
+	var unsafe []RawOutput
+	for _, match := range rawOutputPattern.FindAllStringSubmatchIndex(source, -1) {
+		var expression string
+		if match[2] >= 0 {
+			expression = source[match[2]:match[3]]
+		} else {
+			expression = source[match[4]:match[5]]
+		}
+		if rawOutputIsSafe(expression) {
+			continue
+		}
+		unsafe = append(unsafe, RawOutput{
+			Expression: source[match[0]:match[1]],
+			Line:       strings.Count(source[:match[0]], "\n") + 1,
+		})
+	}
+	return unsafe
+}
+
+// rawOutputIsSafe reports whether a raw output's expression cannot hold user input
+func rawOutputIsSafe(expression string) bool {
+	tokens := expressionTokenPattern.FindAllString(expression, -1)
+	if len(tokens) == 0 {
+		return true
+	}
+	if len(tokens) == 1 {
+		return trustedPath(tokens[0])
+	}
+	if escapingHelpers[tokens[0]] {
+		return true
+	}
+	for _, token := range tokens[1:] {
+		if _, value, isHash := strings.Cut(token, "="); isHash {
+			token = value
+		}
+		if !literal(token) && !trustedPath(token) {
+			return false
+		}
+	}
+	return true
+}
+
+// trustedPath reports whether path names a trusted key or data variable such as @index
+func trustedPath(path string) bool {
+	path = strings.TrimPrefix(path, "@root.")
+	if strings.HasPrefix(path, "@") {
+		return true
+	}
+	for _, prefix := range []string{"this.", "./", "../"} {
+		for strings.HasPrefix(path, prefix) {
+			path = strings.TrimPrefix(path, prefix)
+		}
+	}
+	root, _, _ := strings.Cut(path, ".")
+	root, _, _ = strings.Cut(root, "/")
+	return trustedRawKeys[root]
+}
+
+// literal reports whether token is a string, number or keyword rather than a path
+func literal(token string) bool {
+	if strings.HasPrefix(token, `"`) || strings.HasPrefix(token, "'") {
+		return true
+	}
+	switch token {
+	case "true", "false", "null", "undefined":
+		return true
+	}
+	return strings.Trim(token, "-.0123456789") == "" && strings.ContainsAny(token, "0123456789")
+}
@@ -0,0 +1,103 @@
+package views
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnsafeRawOutputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected []RawOutput
+	}{
+		{name: "escaped output", source: "<h1>{{vm.post.title}}</h1>"},
+		{name: "layout body", source: "<main>{{{body}}}</main>"},
+		{name: "layout assets", source: "{{#if additionalCSS}}{{{additionalCSS}}}{{/if}}"},
+		{name: "json helper", source: "<script>const data = {{{json vm.posts}}};</script>"},
+		{name: "helper with literal arguments", source: `{{{t "nav.home"}}}`},
+		{name: "data variable", source: "{{#each vm.posts}}{{{@index}}}{{/each}}"},
+		{
+			name:     "view model field",
+			source:   "<h1>{{vm.post.title}}</h1>\n<div>{{{vm.post.body}}}</div>",
+			expected: []RawOutput{{Expression: "{{{vm.post.body}}}", Line: 2}},
+		},
+		{
+			name:     "row field in each",
+			source:   "{{#each vm.posts}}{{{ content }}}{{/each}}",
+			expected: []RawOutput{{Expression: "{{{ content }}}", Line: 1}},
+		},
+		{
+			name:     "ampersand output",
+			source:   "{{& vm.search}}",
+			expected: []RawOutput{{Expression: "{{& vm.search}}", Line: 1}},
+		},
+		{
+			name:     "helper over user input",
+			source:   "{{{uppercase vm.post.title}}}",
+			expected: []RawOutput{{Expression: "{{{uppercase vm.post.title}}}", Line: 1}},
+		},
+		{
+			name:     "hash argument from user input",
+			source:   `{{{t "greeting" name=vm.user.username}}}`,
+			expected: []RawOutput{{Expression: `{{{t "greeting" name=vm.user.username}}}`, Line: 1}},
+		},
+		{
+			name:     "this",
+			source:   "{{#each vm.tags}}{{{this}}}{{/each}}",
+			expected: []RawOutput{{Expression: "{{{this}}}", Line: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnsafeRawOutputs(tt.source)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStrictEscaping(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"risky.html.hbs": "<div>{{{vm.post.body}}}</div>",
+		"safe.html.hbs":  "<div>{{vm.post.body}}</div>{{{json vm.post}}}",
+		"query.sql.hbs":  "SELECT * FROM posts {{{where \"status\"}}}",
+	}
+	for file, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		strict  bool
+		wantErr bool
+	}{
+		{name: "strict refuses a risky template", file: "risky.html.hbs", strict: true, wantErr: true},
+		{name: "strict loads a safe template", file: "safe.html.hbs", strict: true},
+		{name: "strict leaves sql templates alone", file: "query.sql.hbs", strict: true},
+		{name: "risky template loads when not strict", file: "risky.html.hbs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := NewTemplateRenderer()
+			renderer.SetStrictEscaping(tt.strict)
+			err := renderer.LoadTemplate("page", filepath.Join(dir, tt.file))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package views
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"fulcrum/lib/i18n"
+)
+
+// Template engines an app's HTML templates can be written for, set by views.engine in
+// fulcrum.yml
+const (
+	EngineHandlebars = "handlebars"
+	EngineGoTemplate = "gotemplate"
+)
+
+// goTemplateExt is the extension of templates rendered with html/template
+const goTemplateExt = ".tmpl"
+
+// IsGoTemplate reports whether file is rendered with html/template, e.g. get.html.tmpl
+func IsGoTemplate(file string) bool {
+	return strings.HasSuffix(file, goTemplateExt)
+}
+
+// GoTemplateRenderer renders .tmpl templates with the standard library's html/template,
+// which escapes output for where it appears in the page. Other templates, such as
+// .sql.hbs queries, are left to the Handlebars renderer it wraps.
+type GoTemplateRenderer struct {
+	*TemplateRenderer
+
+	// set holds every .tmpl template so each can include the others with {{template}}.
+	// It is never executed: renders execute a clone, which keeps it open to templates
+	// loaded later.
+	set   *template.Template
+	files map[string]string // Template name to the .tmpl file it was loaded from
+	mutex sync.RWMutex
+}
+
+// NewGoTemplateRenderer creates a renderer for .tmpl templates that leaves the others to
+// handlebars
+func NewGoTemplateRenderer(handlebars *TemplateRenderer) *GoTemplateRenderer {
+	return &GoTemplateRenderer{
+		TemplateRenderer: handlebars,
+		set:              template.New("").Funcs(goTemplateFuncs("")),
+		files:            make(map[string]string),
+	}
+}
+
+// Templates returns the loaded templates' names and the files they were loaded from
+func (gr *GoTemplateRenderer) Templates() map[string]string {
+	templates := gr.TemplateRenderer.Templates()
+	gr.mutex.RLock()
+	defer gr.mutex.RUnlock()
+	maps.Copy(templates, gr.files)
+	return templates
+}
+
+// LoadTemplate loads a .tmpl template from file, or hands any other file to handlebars
+func (gr *GoTemplateRenderer) LoadTemplate(name, filePath string) error {
+	if !IsGoTemplate(filePath) {
+		gr.mutex.Lock()
+		delete(gr.files, name)
+		gr.mutex.Unlock()
+		return gr.TemplateRenderer.LoadTemplate(name, filePath)
+	}
+
+	source, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+
+	gr.mutex.Lock()
+	defer gr.mutex.Unlock()
+	if _, err := gr.set.New(name).Parse(string(source)); err != nil {
+		return fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	gr.files[name] = filePath
+	return nil
+}
+
+// LoadTemplatesRecursiveWithPrefix loads the .tmpl and .hbs files under dir, prefixing
+// their names like the Handlebars renderer does
+func (gr *GoTemplateRenderer) LoadTemplatesRecursiveWithPrefix(dir, prefix string) error {
+	if err := gr.TemplateRenderer.LoadTemplatesRecursiveWithPrefix(dir, prefix); err != nil {
+		return err
+	}
+	return gr.loadGoTemplates(dir, prefix)
+}
+
+// LoadTemplatesRecursive loads the .tmpl and .hbs files under dir
+func (gr *GoTemplateRenderer) LoadTemplatesRecursive(dir string) error {
+	return gr.LoadTemplatesRecursiveWithPrefix(dir, "")
+}
+
+// loadGoTemplates loads the .tmpl files under dir, named by their path without .tmpl,
+// e.g. "layouts/main"
+func (gr *GoTemplateRenderer) loadGoTemplates(dir, prefix string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !IsGoTemplate(path) {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		name := filepath.ToSlash(strings.TrimSuffix(relPath, goTemplateExt))
+		if prefix != "" {
+			name = prefix + "/" + name
+		}
+		return gr.LoadTemplate(name, path)
+	})
+}
+
+// Render renders a .tmpl template with data, or hands any other template to handlebars
+func (gr *GoTemplateRenderer) Render(name string, data any) (string, error) {
+	gr.mutex.RLock()
+	_, isGoTemplate := gr.files[name]
+	var tmpl *template.Template
+	var err error
+	if isGoTemplate {
+		tmpl, err = gr.set.Clone()
+	}
+	gr.mutex.RUnlock()
+
+	if !isGoTemplate {
+		return gr.TemplateRenderer.Render(name, data)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to execute template %s: %w", name, err)
+	}
+
+	locale := ""
+	if dataMap, ok := data.(map[string]any); ok {
+		locale, _ = dataMap[LocaleKey].(string)
+	}
+	tmpl.Funcs(goTemplateFuncs(locale))
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, trustRawKeys(data)); err != nil {
+		return "", fmt.Errorf("failed to execute template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// trustRawKeys marks the trusted keys of a data map, such as the page body a layout
+// wraps, as HTML so html/template writes them as is
+func trustRawKeys(data any) any {
+	dataMap, ok := data.(map[string]any)
+	if !ok {
+		return data
+	}
+	trusted := maps.Clone(dataMap)
+	for key := range trustedRawKeys {
+		if value, ok := trusted[key].(string); ok {
+			trusted[key] = template.HTML(value)
+		}
+	}
+	return trusted
+}
+
+// goTemplateFuncs are the HTML helpers of Go templates, translating into locale. The
+// string helpers take the string last so they can be piped, e.g.
+// {{.vm.post.title | truncate 50 | uppercase}}.
+func goTemplateFuncs(locale string) template.FuncMap {
+	return template.FuncMap{
+		"uppercase":  strings.ToUpper,
+		"lowercase":  strings.ToLower,
+		"capitalize": capitalize,
+		"truncate": func(length int, str string) string {
+			return truncate(str, length)
+		},
+		"replace": func(old, new, str string) string {
+			return strings.ReplaceAll(str, old, new)
+		},
+		"url": urlHelper,
+		"json": func(data any) template.JS {
+			encoded, err := json.Marshal(data)
+			if err != nil {
+				return "{}"
+			}
+			return template.JS(encoded)
+		},
+
+		// {{t "greeting" "name" .vm.user.username}} fills %{name} from the pairs after the key
+		"t": func(key string, pairs ...any) (string, error) {
+			if len(pairs)%2 != 0 {
+				return "", fmt.Errorf("t %q: expected name and value pairs", key)
+			}
+			params := make(map[string]any, len(pairs)/2)
+			for i := 0; i < len(pairs); i += 2 {
+				params[fmt.Sprint(pairs[i])] = pairs[i+1]
+			}
+			return i18n.T(locale, key, params), nil
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package views
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGoTemplateRenderer(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"layouts/main.tmpl":   `<main>{{.body}}</main><footer>{{.siteName}}</footer>`,
+		"posts/get.html.tmpl": `<h1>{{.vm.post.title}}</h1>{{template "shared/card" .vm.post}}`,
+		"shared/card.tmpl":    `<div>{{.title | truncate 5 | uppercase}}</div>`,
+		"posts/data.tmpl":     `<script>const post = {{json .vm.post}};</script>`,
+		"posts/get.sql.hbs":   "SELECT * FROM posts",
+	}
+	for file, source := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	renderer := NewGoTemplateRenderer(NewTemplateRenderer())
+	if err := renderer.LoadTemplatesRecursive(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	post := map[string]any{"vm": map[string]any{"post": map[string]any{"title": "<script>alert(1)</script>"}}}
+	tests := []struct {
+		name     string
+		template string
+		data     any
+		expected string
+	}{
+		{
+			name:     "output is escaped and partials are shared",
+			template: "posts/get.html",
+			data:     post,
+			expected: "<h1>&lt;script&gt;alert(1)&lt;/script&gt;</h1><div>&lt;SCRI</div>",
+		},
+		{
+			name:     "the layout writes the page body as is",
+			template: "layouts/main",
+			data:     map[string]any{"body": "<p>page</p>", "siteName": "<b>"},
+			expected: "<main><p>page</p></main><footer>&lt;b&gt;</footer>",
+		},
+		{
+			name:     "json is safe in a script",
+			template: "posts/data",
+			data:     post,
+			expected: `<script>const post = {"title":"\u003cscript\u003ealert(1)\u003c/script\u003e"};</script>`,
+		},
+		{
+			name:     "handlebars templates are left to handlebars",
+			template: "posts/get.sql",
+			expected: "SELECT * FROM posts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := renderer.Render(tt.template, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("templates load after a render", func(t *testing.T) {
+		path := filepath.Join(dir, "late.tmpl")
+		if err := os.WriteFile(path, []byte(`late {{template "shared/card" .}}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := renderer.LoadTemplate("late", path); err != nil {
+			t.Fatal(err)
+		}
+		result, err := renderer.Render("late", map[string]any{"title": "ok"})
+		if err != nil || result != "late <div>OK</div>" {
+			t.Errorf("Expected the late template to render, got %q, %v", result, err)
+		}
+	})
+
+	t.Run("templates lists both engines", func(t *testing.T) {
+		templates := renderer.Templates()
+		for _, name := range []string{"layouts/main", "posts/get.sql"} {
+			if templates[name] == "" {
+				t.Errorf("Expected %s in %v", name, templates)
+			}
+		}
+	})
+
+	t.Run("handlebars refuses go templates", func(t *testing.T) {
+		if err := NewTemplateRenderer().LoadTemplate("page", filepath.Join(dir, "layouts/main.tmpl")); err == nil {
+			t.Error("Expected an error loading a .tmpl file without the gotemplate engine")
+		}
+	})
+}
@@ -1,26 +1,87 @@
 package views
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"fulcrum/lib/i18n"
+
 	"github.com/aymerick/raymond"
 )
 
+// Renderer loads and renders an app's templates. *TemplateRenderer renders Handlebars;
+// *GoTemplateRenderer renders .tmpl templates with html/template as well.
+type Renderer interface {
+	LoadTemplate(name, filePath string) error
+	Render(name string, data any) (string, error)
+	Templates() map[string]string
+}
+
 // TemplateRenderer handles Handlebars template rendering
 type TemplateRenderer struct {
 	templates map[string]*raymond.Template
+	sources   map[string]string // Template name to the file it was loaded from
+
+	namespaces map[string]string // Template name to NamespaceHTML or NamespaceSQL
+	helpers    map[string]any    // Custom helpers registered on each template loaded
+
+	// strictEscaping refuses HTML templates with raw outputs of user input
+	strictEscaping bool
 }
 
+// Template namespaces. SQL templates (.sql.hbs files) get the SQL helpers and other SQL
+// templates as partials; every other template gets the HTML helpers and partials.
+const (
+	NamespaceHTML = "html"
+	NamespaceSQL  = "sql"
+)
+
 // NewTemplateRenderer creates a new template renderer
 func NewTemplateRenderer() *TemplateRenderer {
 	return &TemplateRenderer{
-		templates: make(map[string]*raymond.Template),
+		templates:  make(map[string]*raymond.Template),
+		sources:    make(map[string]string),
+		namespaces: make(map[string]string),
+		helpers:    make(map[string]any),
+	}
+}
+
+// templateNamespace returns the namespace of the template in file
+func templateNamespace(file string) string {
+	if strings.HasSuffix(file, ".sql.hbs") {
+		return NamespaceSQL
+	}
+	return NamespaceHTML
+}
+
+// namespaceHelpers returns the helpers registered on each template of namespace,
+// including the common ones
+func namespaceHelpers(namespace string) map[string]any {
+	helpers := maps.Clone(commonHelpers)
+	if namespace == NamespaceSQL {
+		maps.Copy(helpers, sqlHelpers)
+	} else {
+		maps.Copy(helpers, htmlHelpers)
 	}
+	return helpers
+}
+
+// AddHTMLHelpers registers the HTML helpers, such as t and json, on a template parsed
+// outside a TemplateRenderer
+func AddHTMLHelpers(tmpl *raymond.Template) {
+	tmpl.RegisterHelpers(namespaceHelpers(NamespaceHTML))
+}
+
+// Templates returns the loaded templates' names and the files they were loaded from
+func (tr *TemplateRenderer) Templates() map[string]string {
+	return maps.Clone(tr.sources)
 }
 
 // LoadTemplate loads a Handlebars template from file
@@ -33,13 +94,33 @@ func (tr *TemplateRenderer) LoadTemplate(name, filePath string) error {
 		return fmt.Errorf("template file does not exist: %s", filePath)
 	}
 
-	tmpl, err := raymond.ParseFile(filePath)
+	if IsGoTemplate(filePath) {
+		return fmt.Errorf("template %s is a Go template; set views.engine: %s in fulcrum.yml to render it", filePath, EngineGoTemplate)
+	}
+
+	source, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+	tmpl, err := raymond.Parse(string(source))
 	if err != nil {
 		log.Printf("LoadTemplate: Failed to parse template %s: %v", name, err)
 		return fmt.Errorf("failed to parse template %s: %v", name, err)
 	}
+	namespace := templateNamespace(filePath)
+	if tr.strictEscaping && namespace == NamespaceHTML {
+		if unsafe := UnsafeRawOutputs(string(source)); len(unsafe) > 0 {
+			return fmt.Errorf("template %s writes user input unescaped with %s on line %d; use {{ }} instead (strict_escaping is on)",
+				name, unsafe[0].Expression, unsafe[0].Line)
+		}
+	}
+	helpers := namespaceHelpers(namespace)
+	maps.Copy(helpers, tr.helpers)
+	tmpl.RegisterHelpers(helpers)
 
 	tr.templates[name] = tmpl
+	tr.sources[name] = filePath
+	tr.namespaces[name] = namespace
 	log.Printf("LoadTemplate: Successfully registered template '%s'", name)
 	return nil
 }
@@ -80,8 +161,20 @@ func (tr *TemplateRenderer) LoadTemplatesFromDir(dir string) error {
 	return nil
 }
 
+// TemplateRoot is a directory whose templates are named "<Prefix>/<relative path>"
+type TemplateRoot struct {
+	Prefix string
+	Dir    string
+}
+
 // LoadTemplatesRecursive loads all .hbs files from a directory recursively
 func (tr *TemplateRenderer) LoadTemplatesRecursive(dir string) error {
+	return tr.LoadTemplatesRecursiveWithPrefix(dir, "")
+}
+
+// LoadTemplatesRecursiveWithPrefix loads all .hbs files under dir, prefixing their names
+// (e.g. "auth" gives "auth/login/get.html") so they can be used as partials
+func (tr *TemplateRenderer) LoadTemplatesRecursiveWithPrefix(dir, prefix string) error {
 	log.Printf("LoadTemplatesRecursive: Starting to load templates from directory: %s", dir)
 
 	// Check if directory exists
@@ -114,7 +207,10 @@ func (tr *TemplateRenderer) LoadTemplatesRecursive(dir string) error {
 			}
 
 			// Remove .hbs extension and use path as name (e.g., "partials/header")
-			name := relPath[:len(relPath)-len(filepath.Ext(relPath))]
+			name := filepath.ToSlash(relPath[:len(relPath)-len(filepath.Ext(relPath))])
+			if prefix != "" {
+				name = prefix + "/" + name
+			}
 
 			log.Printf("LoadTemplatesRecursive: Loading template:")
 			log.Printf("  - File path: %s", path)
@@ -155,7 +251,15 @@ func (tr *TemplateRenderer) Render(name string, data any) (string, error) {
 		return "", fmt.Errorf("template %s not found", name)
 	}
 
-	result, err := tmpl.Exec(data)
+	// Every template loaded in the same namespace can be used as a partial, e.g. {{> shared/card}}
+	if partials := tr.partials(name); len(partials) > 0 {
+		tmpl = tmpl.Clone()
+		for partialName, partial := range partials {
+			tmpl.RegisterPartialTemplate(partialName, partial)
+		}
+	}
+
+	result, err := Exec(tmpl, data)
 	if err != nil {
 		log.Printf("Render: Failed to execute template '%s': %v", name, err)
 		return "", fmt.Errorf("failed to execute template %s: %v", name, err)
@@ -165,6 +269,17 @@ func (tr *TemplateRenderer) Render(name string, data any) (string, error) {
 	return result, nil
 }
 
+// partials returns the templates name can include: the others in its namespace
+func (tr *TemplateRenderer) partials(name string) map[string]*raymond.Template {
+	partials := make(map[string]*raymond.Template)
+	for partialName, partial := range tr.templates {
+		if partialName != name && tr.namespaces[partialName] == tr.namespaces[name] {
+			partials[partialName] = partial
+		}
+	}
+	return partials
+}
+
 // RenderTo renders a template directly to an http.ResponseWriter
 func (tr *TemplateRenderer) RenderTo(w http.ResponseWriter, name string, data any) error {
 	log.Printf("RenderTo: Rendering template '%s' to HTTP response", name)
@@ -234,24 +349,69 @@ func (tr *TemplateRenderer) RenderWithLayoutTo(w http.ResponseWriter, layoutName
 	return nil
 }
 
-// RegisterHelper registers a custom Handlebars helper
+// RegisterHelper registers a custom Handlebars helper on the templates loaded after it,
+// replacing a built-in one of the same name. Custom helpers are available to HTML and SQL
+// templates alike.
 func (tr *TemplateRenderer) RegisterHelper(name string, helper any) {
-	raymond.RegisterHelper(name, helper)
+	tr.helpers[name] = helper
+}
+
+// templateConfig lists where an app's templates live; *parser.AppConfig implements it
+type templateConfig interface {
+	GetAllTemplateDirectories() []string
+	GetTemplateRoots() []TemplateRoot
+	StrictEscapingEnabled() bool
+	TemplateEngine() string
+}
+
+// SetStrictEscaping makes LoadTemplate refuse HTML templates with raw outputs of user
+// input, such as {{{vm.posts.title}}}, so they are never rendered
+func (tr *TemplateRenderer) SetStrictEscaping(strict bool) {
+	tr.strictEscaping = strict
+}
+
+// loadTemplateRoots loads every template root, logging failures so the others still load
+func (tr *TemplateRenderer) loadTemplateRoots(roots []TemplateRoot) {
+	for _, root := range roots {
+		if err := tr.LoadTemplatesRecursiveWithPrefix(root.Dir, root.Prefix); err != nil {
+			log.Printf("Warning: Failed to load templates from %s: %v", root.Dir, err)
+		}
+	}
+}
+
+// withEngine returns renderer, or for apps written for the gotemplate engine a
+// GoTemplateRenderer wrapping it that has also loaded their .tmpl templates
+func withEngine(renderer *TemplateRenderer, appConfig templateConfig) Renderer {
+	if appConfig.TemplateEngine() != EngineGoTemplate {
+		return renderer
+	}
+
+	goRenderer := NewGoTemplateRenderer(renderer)
+	for _, root := range appConfig.GetTemplateRoots() {
+		if err := goRenderer.loadGoTemplates(root.Dir, root.Prefix); err != nil {
+			log.Printf("Warning: Failed to load templates from %s: %v", root.Dir, err)
+		}
+	}
+	for _, dir := range appConfig.GetAllTemplateDirectories() {
+		if err := goRenderer.loadGoTemplates(dir, ""); err != nil {
+			log.Printf("Warning: Failed to load templates from %s: %v", dir, err)
+		}
+	}
+	return goRenderer
 }
 
 // SetupViewsFromConfig initializes the template renderer using the new config system
-func SetupViewsFromConfig(appConfig interface{ GetAllTemplateDirectories() []string }) (*TemplateRenderer, error) {
+func SetupViewsFromConfig(appConfig templateConfig) (Renderer, error) {
 	renderer := NewTemplateRenderer()
-
-	// Register common helpers
-	registerCommonHelpers(renderer)
+	renderer.SetStrictEscaping(appConfig.StrictEscapingEnabled())
 
 	// Load templates from all discovered directories
 	templateDirs := appConfig.GetAllTemplateDirectories()
+	renderer.loadTemplateRoots(appConfig.GetTemplateRoots())
 
 	if len(templateDirs) == 0 {
 		log.Println("Warning: No template directories found")
-		return renderer, nil
+		return withEngine(renderer, appConfig), nil
 	}
 
 	for _, dir := range templateDirs {
@@ -262,13 +422,14 @@ func SetupViewsFromConfig(appConfig interface{ GetAllTemplateDirectories() []str
 		}
 	}
 
-	return renderer, nil
+	return withEngine(renderer, appConfig), nil
 }
 
 // SetupViewsForDevelopment sets up views with hot-reloading capabilities
-func SetupViewsForDevelopment(appConfig interface{ GetAllTemplateDirectories() []string }) (*TemplateRenderer, error) {
+func SetupViewsForDevelopment(appConfig templateConfig) (Renderer, error) {
 	renderer := NewTemplateRenderer()
-	registerCommonHelpers(renderer)
+	renderer.SetStrictEscaping(appConfig.StrictEscapingEnabled())
+	renderer.loadTemplateRoots(appConfig.GetTemplateRoots())
 
 	// In development, we might want to reload templates on each request
 	// For now, just load them once - hot reloading can be added later
@@ -280,37 +441,22 @@ func SetupViewsForDevelopment(appConfig interface{ GetAllTemplateDirectories() [
 		}
 	}
 
-	return renderer, nil
+	return withEngine(renderer, appConfig), nil
 }
 
-// registerCommonHelpers registers commonly used Handlebars helpers
-func registerCommonHelpers(renderer *TemplateRenderer) {
-	// String manipulation helpers
-	renderer.RegisterHelper("uppercase", func(str string) string {
-		return strings.ToUpper(str)
-	})
-
-	renderer.RegisterHelper("lowercase", func(str string) string {
-		return strings.ToLower(str)
-	})
-
-	renderer.RegisterHelper("capitalize", func(str string) string {
-		if len(str) == 0 {
-			return str
-		}
-		return strings.ToUpper(str[:1]) + strings.ToLower(str[1:])
-	})
-
+// commonHelpers are registered on HTML and SQL templates alike: they only compare values
+// and choose what to render
+var commonHelpers = map[string]any{
 	// Comparison helpers
-	renderer.RegisterHelper("eq", func(a, b any) bool {
+	"eq": func(a, b any) bool {
 		return a == b
-	})
+	},
 
-	renderer.RegisterHelper("ne", func(a, b any) bool {
+	"ne": func(a, b any) bool {
 		return a != b
-	})
+	},
 
-	renderer.RegisterHelper("gt", func(a, b any) bool {
+	"gt": func(a, b any) bool {
 		switch aVal := a.(type) {
 		case int:
 			if bVal, ok := b.(int); ok {
@@ -322,9 +468,9 @@ func registerCommonHelpers(renderer *TemplateRenderer) {
 			}
 		}
 		return false
-	})
+	},
 
-	renderer.RegisterHelper("lt", func(a, b any) bool {
+	"lt": func(a, b any) bool {
 		switch aVal := a.(type) {
 		case int:
 			if bVal, ok := b.(int); ok {
@@ -336,43 +482,187 @@ func registerCommonHelpers(renderer *TemplateRenderer) {
 			}
 		}
 		return false
-	})
+	},
 
 	// Logical helpers
-	renderer.RegisterHelper("and", func(a, b bool) bool {
+	"and": func(a, b bool) bool {
 		return a && b
-	})
+	},
 
-	renderer.RegisterHelper("or", func(a, b bool) bool {
+	"or": func(a, b bool) bool {
 		return a || b
-	})
+	},
 
-	renderer.RegisterHelper("not", func(a bool) bool {
+	"not": func(a bool) bool {
 		return !a
-	})
+	},
 
 	// Conditional helpers
-	renderer.RegisterHelper("if_eq", func(a, b any, options *raymond.Options) string {
+	"if_eq": func(a, b any, options *raymond.Options) string {
 		if a == b {
 			return options.Fn()
 		}
 		return options.Inverse()
-	})
+	},
+}
+
+// htmlHelpers are registered on HTML templates only: their output is meant for a page,
+// not a query
+var htmlHelpers = map[string]any{
+	// String manipulation helpers
+	"uppercase":  strings.ToUpper,
+	"lowercase":  strings.ToLower,
+	"capitalize": capitalize,
+	"truncate":   truncate,
+	"replace":    strings.ReplaceAll,
+	"compose":    composeHelper,
 
 	// URL/Path helpers
-	renderer.RegisterHelper("url", func(path string) string {
-		// Basic URL helper - can be enhanced with base URL logic
-		if strings.HasPrefix(path, "/") {
-			return path
-		}
-		return "/" + path
-	})
+	"url": urlHelper,
 
 	// JSON helper for client-side data
-	renderer.RegisterHelper("json", func(data any) string {
-		// This would need proper JSON marshaling
-		return fmt.Sprintf("%+v", data)
-	})
+	"json": jsonHelper,
+
+	// Translations
+	"t": tHelper,
+}
+
+// sqlHelpers are registered on SQL templates only: they write SQL into the query
+var sqlHelpers = map[string]any{
+	"search_where": searchWhereHelper,
+	"order_by":     orderByHelper,
+	"tenant_scope": tenantScopeHelper,
+	"where":        whereHelper,
+	"in_list":      inListHelper,
+	"not_deleted":  notDeletedHelper,
+}
+
+// urlHelper makes path absolute; it can be enhanced with base URL logic
+func urlHelper(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return path
+	}
+	return "/" + path
+}
+
+// LocaleKey is the data key naming the locale a page renders in
+const LocaleKey = "locale"
+
+// Exec renders tmpl with data, exposing data's locale to helpers as @locale so it
+// reaches the t helper inside #each and partials too
+func Exec(tmpl *raymond.Template, data any) (string, error) {
+	frame := raymond.NewDataFrame()
+	if dataMap, ok := data.(map[string]any); ok {
+		if locale, ok := dataMap[LocaleKey].(string); ok {
+			frame.Set(LocaleKey, locale)
+		}
+	}
+	return tmpl.ExecWith(data, frame)
+}
+
+// tHelper translates key into the page's @locale, filling %{name} placeholders from
+// the helper's hash, e.g. {{t "greeting" name=vm.user.username}}
+func tHelper(key string, options *raymond.Options) string {
+	return i18n.T(options.DataStr(LocaleKey), key, options.Hash())
+}
+
+// capitalize upper-cases the first letter of str and lower-cases the rest
+func capitalize(str string) string {
+	if len(str) == 0 {
+		return str
+	}
+	return strings.ToUpper(str[:1]) + strings.ToLower(str[1:])
+}
+
+// truncate cuts str down to at most length characters
+func truncate(str string, length int) string {
+	runes := []rune(str)
+	if length < 0 || len(runes) <= length {
+		return str
+	}
+	return string(runes[:length])
+}
+
+// composableHelpers are the string helpers compose can chain, each given the
+// colon-separated arguments that follow its name, e.g. truncate:50 or replace:_:-
+var composableHelpers = map[string]func(str string, args []string) (string, error){
+	"uppercase": func(str string, args []string) (string, error) {
+		return strings.ToUpper(str), expectArgs(args, 0)
+	},
+	"lowercase": func(str string, args []string) (string, error) {
+		return strings.ToLower(str), expectArgs(args, 0)
+	},
+	"capitalize": func(str string, args []string) (string, error) {
+		return capitalize(str), expectArgs(args, 0)
+	},
+	"truncate": func(str string, args []string) (string, error) {
+		if err := expectArgs(args, 1); err != nil {
+			return "", err
+		}
+		length, err := strconv.Atoi(args[0])
+		if err != nil {
+			return "", fmt.Errorf("invalid length %q", args[0])
+		}
+		return truncate(str, length), nil
+	},
+	"replace": func(str string, args []string) (string, error) {
+		if err := expectArgs(args, 2); err != nil {
+			return "", err
+		}
+		return strings.ReplaceAll(str, args[0], args[1]), nil
+	},
+}
+
+// expectArgs checks a composed helper was given count arguments
+func expectArgs(args []string, count int) error {
+	if len(args) != count {
+		return fmt.Errorf("takes %d arguments, got %d", count, len(args))
+	}
+	return nil
+}
+
+// composeHelper applies a comma-separated list of helpers to value in order, e.g.
+// {{compose helpers="uppercase,truncate:50" value=title}}. Raymond reports an unknown
+// helper or bad arguments as a render error.
+func composeHelper(options *raymond.Options) string {
+	result, err := compose(raymond.Str(options.HashProp("value")), options.HashStr("helpers"))
+	if err != nil {
+		panic(fmt.Errorf("compose: %w", err))
+	}
+	return result
+}
+
+// compose applies the helpers named in spec to value, left to right
+func compose(value, spec string) (string, error) {
+	for _, step := range strings.Split(spec, ",") {
+		step = strings.TrimSpace(step)
+		if step == "" {
+			continue
+		}
+		name, rest, hasArgs := strings.Cut(step, ":")
+		helper, ok := composableHelpers[name]
+		if !ok {
+			return "", fmt.Errorf("unknown helper %q", name)
+		}
+		var args []string
+		if hasArgs {
+			args = strings.Split(rest, ":")
+		}
+		var err error
+		if value, err = helper(value, args); err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return value, nil
+}
+
+// jsonHelper marshals data for client-side scripts, falling back to an empty object
+func jsonHelper(data any) string {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "{}"
+	}
+	return string(encoded)
 }
 
 // LoadTemplateForRoute loads a specific template for a route if not already loaded
@@ -409,7 +699,6 @@ func (tr *TemplateRenderer) RenderRoute(routeName string, data map[string]any, l
 // SetupViews - keep the old function for backward compatibility
 func SetupViews(templateDir string) (*TemplateRenderer, error) {
 	renderer := NewTemplateRenderer()
-	registerCommonHelpers(renderer)
 
 	if err := renderer.LoadTemplatesRecursive(templateDir); err != nil {
 		return nil, fmt.Errorf("failed to load templates: %v", err)
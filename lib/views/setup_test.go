@@ -0,0 +1,147 @@
+package views
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONHelper(t *testing.T) {
+	type user struct {
+		Name  string   `json:"name"`
+		Admin bool     `json:"admin"`
+		Tags  []string `json:"tags"`
+	}
+
+	tests := []struct {
+		name     string
+		data     any
+		expected string
+	}{
+		{name: "struct", data: user{Name: "Ada", Admin: true, Tags: []string{"a"}}, expected: `{"name":"Ada","admin":true,"tags":["a"]}`},
+		{name: "map", data: map[string]any{"id": 7}, expected: `{"id":7}`},
+		{name: "nil", data: nil, expected: "null"},
+		{name: "script close is escaped", data: "</script>", expected: `"\u003c/script\u003e"`},
+		{name: "unmarshalable falls back to empty object", data: map[string]any{"fn": func() {}}, expected: "{}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := jsonHelper(tt.data)
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+			if !json.Valid([]byte(result)) {
+				t.Errorf("Expected valid JSON, got %s", result)
+			}
+		})
+	}
+}
+
+func TestCompose(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		helpers  string
+		expected string
+		wantErr  bool
+	}{
+		{name: "uppercase then truncate", value: "hello world", helpers: "uppercase,truncate:5", expected: "HELLO"},
+		{name: "truncate then uppercase", value: "hello world", helpers: "truncate:5, uppercase", expected: "HELLO"},
+		{name: "lowercase then replace", value: "My Blog Post", helpers: "lowercase,replace: :-", expected: "my-blog-post"},
+		{name: "capitalize", value: "hELLO", helpers: "capitalize", expected: "Hello"},
+		{name: "truncate counts characters", value: "héllo", helpers: "truncate:2", expected: "hé"},
+		{name: "truncate shorter value", value: "hi", helpers: "truncate:50", expected: "hi"},
+		{name: "no helpers", value: "As is", helpers: "", expected: "As is"},
+		{name: "unknown helper", value: "x", helpers: "uppercase,reverse", wantErr: true},
+		{name: "missing length", value: "x", helpers: "truncate", wantErr: true},
+		{name: "invalid length", value: "x", helpers: "truncate:ten", wantErr: true},
+		{name: "unexpected argument", value: "x", helpers: "uppercase:1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compose(tt.value, tt.helpers)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTemplateNamespaces(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		helper    string
+		available bool
+	}{
+		{name: "html template has json", file: "domains/posts/get.html.hbs", helper: "json", available: true},
+		{name: "html template has t", file: "domains/posts/get.html.hbs", helper: "t", available: true},
+		{name: "html template lacks order_by", file: "domains/posts/get.html.hbs", helper: "order_by"},
+		{name: "html template lacks where", file: "domains/posts/get.html.hbs", helper: "where"},
+		{name: "html template has eq", file: "domains/posts/get.html.hbs", helper: "eq", available: true},
+		{name: "xml template has url", file: "domains/feed/get.xml.hbs", helper: "url", available: true},
+		{name: "sql template has order_by", file: "domains/posts/get.sql.hbs", helper: "order_by", available: true},
+		{name: "sql template has in_list", file: "domains/posts/get.sql.hbs", helper: "in_list", available: true},
+		{name: "sql template has if_eq", file: "domains/posts/get.sql.hbs", helper: "if_eq", available: true},
+		{name: "sql template lacks json", file: "domains/posts/get.sql.hbs", helper: "json"},
+		{name: "sql template lacks uppercase", file: "domains/posts/get.sql.hbs", helper: "uppercase"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, available := namespaceHelpers(templateNamespace(tt.file))[tt.helper]
+			if available != tt.available {
+				t.Errorf("Expected %s available to %s to be %v", tt.helper, tt.file, tt.available)
+			}
+		})
+	}
+}
+
+func TestPartialsStayInNamespace(t *testing.T) {
+	dir := t.TempDir()
+	renderer := NewTemplateRenderer()
+	for name, file := range map[string]string{
+		"posts/get":      "get.html.hbs",
+		"shared/card":    "card.html.hbs",
+		"posts/get_sql":  "get.sql.hbs",
+		"shared/filters": "filters.sql.hbs",
+	} {
+		path := filepath.Join(dir, file)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := renderer.LoadTemplate(name, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{name: "html template sees html partials", template: "posts/get", expected: "shared/card"},
+		{name: "sql template sees sql partials", template: "posts/get_sql", expected: "shared/filters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partials := renderer.partials(tt.template)
+			if len(partials) != 1 || partials[tt.expected] == nil {
+				t.Errorf("Expected only %s as a partial, got %v", tt.expected, partials)
+			}
+		})
+	}
+}
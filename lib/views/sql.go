@@ -0,0 +1,193 @@
+package views
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aymerick/raymond"
+)
+
+// Request data keys holding the SQL the search_where, order_by and tenant_scope helpers render
+const (
+	SearchWhereKey = "_search_where"
+	OrderByKey     = "_order_by"
+	TenantScopeKey = "_tenant_scope"
+)
+
+// sqlIdentifierPattern matches the parameter and column names SQL helpers write into a query
+var sqlIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// leadingJoinPattern matches the AND or OR that starts the first condition of a where block
+var leadingJoinPattern = regexp.MustCompile(`(?i)^(AND|OR)\s+`)
+
+// searchWhereHelper renders the request's search and filter conditions as " WHERE ...",
+// or " AND ..." with prefix="AND", and nothing when the request has none. The
+// conditions only hold column names and bind parameters, so they are not escaped.
+func searchWhereHelper(options *raymond.Options) raymond.SafeString {
+	conditions := options.ValueStr(SearchWhereKey)
+	if conditions == "" {
+		return ""
+	}
+	prefix := "WHERE"
+	if strings.EqualFold(options.HashStr("prefix"), "AND") {
+		prefix = "AND"
+	}
+	return raymond.SafeString(" " + prefix + " " + conditions)
+}
+
+// orderByHelper renders the request's sort as " ORDER BY ...", falling back to the
+// default="..." ordering the template gives when the request does not sort. Given a
+// column and direction, e.g. {{order_by sort dir allow="title,created_at"}}, it
+// orders by the column instead, but only when allow lists it.
+func orderByHelper(options *raymond.Options) raymond.SafeString {
+	fallback := options.HashStr("default")
+	if params := options.Params(); len(params) > 0 {
+		var dir string
+		if len(params) > 1 {
+			dir = options.ParamStr(1)
+		}
+		order, err := orderByColumn(options.ParamStr(0), dir, options.HashStr("allow"), fallback)
+		if err != nil {
+			panic(fmt.Errorf("order_by: %w", err))
+		}
+		return raymond.SafeString(order)
+	}
+
+	order := options.ValueStr(OrderByKey)
+	if order == "" {
+		order = fallback
+	}
+	if order == "" {
+		return ""
+	}
+	return raymond.SafeString(" ORDER BY " + order)
+}
+
+// orderByColumn renders " ORDER BY column ASC|DESC" when column is one of the
+// comma-separated allow list, and the fallback ordering otherwise. dir is desc or asc
+// (the default), whatever its case.
+func orderByColumn(column, dir, allow, fallback string) (string, error) {
+	var allowed []string
+	for _, name := range strings.Split(allow, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			if !sqlIdentifierPattern.MatchString(name) {
+				return "", fmt.Errorf("allow lists %q, which is not a column name", name)
+			}
+			allowed = append(allowed, name)
+		}
+	}
+	if len(allowed) == 0 {
+		return "", fmt.Errorf(`allow="..." must list the columns a request may order by`)
+	}
+
+	if !slices.Contains(allowed, column) {
+		if fallback == "" {
+			return "", nil
+		}
+		return " ORDER BY " + fallback, nil
+	}
+	direction := "ASC"
+	if strings.EqualFold(dir, "desc") {
+		direction = "DESC"
+	}
+	return " ORDER BY " + column + " " + direction, nil
+}
+
+// tenantScopeHelper renders the condition limiting a query to the request's tenant
+func tenantScopeHelper(options *raymond.Options) raymond.SafeString {
+	return raymond.SafeString(tenantScope(options.ValueStr(TenantScopeKey), options.HashStr("prefix")))
+}
+
+// tenantScope renders condition, such as "tenant_id = :_tenant_id", or 1=1 when the
+// request is not scoped to a tenant. With prefix="AND" or prefix="WHERE" it renders
+// " AND ..." or " WHERE ...", and nothing when unscoped.
+func tenantScope(condition, prefix string) string {
+	prefix = strings.ToUpper(prefix)
+	if prefix != "AND" && prefix != "WHERE" {
+		if condition == "" {
+			return "1=1"
+		}
+		return condition
+	}
+	if condition == "" {
+		return ""
+	}
+	return " " + prefix + " " + condition
+}
+
+// whereHelper renders its block as " WHERE ..." (or " AND ..." with prefix="AND") when
+// any condition in it applies, and nothing otherwise. Each condition starts with AND
+// or OR so it can sit in its own #if; the first one's is dropped:
+//
+//	SELECT * FROM posts{{#where}}{{#if status}} AND status = :status{{/if}}{{/where}}
+func whereHelper(options *raymond.Options) raymond.SafeString {
+	return raymond.SafeString(whereClause(options.Fn(), options.HashStr("prefix")))
+}
+
+// whereClause joins conditions to the query with prefix, WHERE unless it is AND
+func whereClause(conditions, prefix string) string {
+	conditions = strings.TrimSpace(conditions)
+	conditions = leadingJoinPattern.ReplaceAllString(conditions, "")
+	if conditions == "" {
+		return ""
+	}
+	if !strings.EqualFold(prefix, "AND") {
+		prefix = "WHERE"
+	}
+	return " " + strings.ToUpper(prefix) + " " + conditions
+}
+
+// inListHelper renders IN (:name) for the list in the request data key name, which
+// ExecuteSQL binds with one placeholder per item, e.g. WHERE id {{in_list "ids"}}.
+// An empty or missing list renders IN (NULL), which matches no rows.
+func inListHelper(name string, options *raymond.Options) raymond.SafeString {
+	clause, err := inList(name, options.Value(name))
+	if err != nil {
+		panic(fmt.Errorf("in_list: %w", err))
+	}
+	return raymond.SafeString(clause)
+}
+
+// inList renders the IN clause for the value bound to the parameter name
+func inList(name string, value any) (string, error) {
+	if !sqlIdentifierPattern.MatchString(name) {
+		return "", fmt.Errorf("%q is not a parameter name", name)
+	}
+	if value == nil {
+		return "IN (NULL)", nil
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Slice && v.Len() == 0 {
+		return "IN (NULL)", nil
+	}
+	return "IN (:" + name + ")", nil
+}
+
+// notDeletedHelper renders the condition that leaves out soft deleted rows, for
+// hand-written queries on tables with a deleted_at column. Like tenant_scope it takes
+// prefix="AND" or prefix="WHERE", and table="p" qualifies the column for joins.
+func notDeletedHelper(options *raymond.Options) raymond.SafeString {
+	condition, err := notDeleted(options.HashStr("table"), options.HashStr("prefix"))
+	if err != nil {
+		panic(fmt.Errorf("not_deleted: %w", err))
+	}
+	return raymond.SafeString(condition)
+}
+
+// notDeleted renders "deleted_at IS NULL", qualified by table when given
+func notDeleted(table, prefix string) (string, error) {
+	column := "deleted_at"
+	if table != "" {
+		if !sqlIdentifierPattern.MatchString(table) {
+			return "", fmt.Errorf("%q is not a table name", table)
+		}
+		column = table + "." + column
+	}
+	condition := column + " IS NULL"
+	if prefix = strings.ToUpper(prefix); prefix == "AND" || prefix == "WHERE" {
+		return " " + prefix + " " + condition, nil
+	}
+	return condition, nil
+}
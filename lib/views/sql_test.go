@@ -0,0 +1,152 @@
+package views
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTenantScope(t *testing.T) {
+	const condition = "tenant_id = :_tenant_id"
+
+	tests := []struct {
+		name      string
+		condition string
+		prefix    string
+		expected  string
+	}{
+		{name: "scoped", condition: condition, expected: condition},
+		{name: "scoped with AND", condition: condition, prefix: "AND", expected: " AND " + condition},
+		{name: "scoped with where", condition: condition, prefix: "where", expected: " WHERE " + condition},
+		{name: "unknown prefix is ignored", condition: condition, prefix: "OR", expected: condition},
+		{name: "unscoped", expected: "1=1"},
+		{name: "unscoped with AND", prefix: "AND", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tenantScope(tt.condition, tt.prefix); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWhereClause(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions string
+		prefix     string
+		expected   string
+	}{
+		{name: "no conditions apply", conditions: "\n  ", expected: ""},
+		{name: "first join is dropped", conditions: " AND status = :status AND user_id = :user_id", expected: " WHERE status = :status AND user_id = :user_id"},
+		{name: "or on its own line", conditions: "\n  or\tarchived = :archived", expected: " WHERE archived = :archived"},
+		{name: "without a join", conditions: "id = :id", expected: " WHERE id = :id"},
+		{name: "joined to an existing where", conditions: "AND status = :status", prefix: "and", expected: " AND status = :status"},
+		{name: "column names starting with or are kept", conditions: "order_id = :order_id", expected: " WHERE order_id = :order_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := whereClause(tt.conditions, tt.prefix); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestInList(t *testing.T) {
+	tests := []struct {
+		name     string
+		param    string
+		value    any
+		expected string
+		wantErr  bool
+	}{
+		{name: "list", param: "ids", value: []any{1, 2}, expected: "IN (:ids)"},
+		{name: "single value", param: "id", value: "7", expected: "IN (:id)"},
+		{name: "empty list", param: "ids", value: []string{}, expected: "IN (NULL)"},
+		{name: "missing", param: "ids", expected: "IN (NULL)"},
+		{name: "not a parameter name", param: "ids); DROP TABLE users; --", value: []any{1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inList(tt.param, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestOrderByColumn(t *testing.T) {
+	const allow = "title, created_at"
+
+	tests := []struct {
+		name     string
+		column   string
+		dir      string
+		allow    string
+		fallback string
+		expected string
+		wantErr  string
+	}{
+		{name: "allowed column", column: "title", dir: "DESC", allow: allow, expected: " ORDER BY title DESC"},
+		{name: "ascending by default", column: "created_at", dir: "sideways", allow: allow, expected: " ORDER BY created_at ASC"},
+		{name: "other columns use the fallback", column: "password", allow: allow, fallback: "id DESC", expected: " ORDER BY id DESC"},
+		{name: "injection uses the fallback", column: "title; DROP TABLE posts", allow: allow, fallback: "id", expected: " ORDER BY id"},
+		{name: "no column and no fallback", allow: allow, expected: ""},
+		{name: "allow list is required", column: "title", wantErr: "must list the columns"},
+		{name: "allow list holds column names", column: "title", allow: "title, 1=1", wantErr: "not a column name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := orderByColumn(tt.column, tt.dir, tt.allow, tt.fallback)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNotDeleted(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    string
+		prefix   string
+		expected string
+		wantErr  bool
+	}{
+		{name: "bare", expected: "deleted_at IS NULL"},
+		{name: "with AND", prefix: "AND", expected: " AND deleted_at IS NULL"},
+		{name: "with where", prefix: "where", expected: " WHERE deleted_at IS NULL"},
+		{name: "qualified", table: "p", expected: "p.deleted_at IS NULL"},
+		{name: "not a table name", table: "p; DROP TABLE posts", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := notDeleted(tt.table, tt.prefix)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}